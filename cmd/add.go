@@ -68,6 +68,8 @@ func init() {
 	
 	* Sync paths (sync)
 	* Forwarded ports (port)
+	* Helm packages (package)
+	* Deployments (deployment)
 	#######################################################
 	`,
 		Args: cobra.NoArgs,
@@ -86,6 +88,7 @@ func init() {
 
 	How to use:
 	devspace add sync --local=app --container=/app
+	devspace add sync --local=app --container=/app --selector=app=web
 	#######################################################
 	`,
 		Args: cobra.NoArgs,
@@ -114,6 +117,7 @@ func init() {
 	Add a new port mapping that should be forwarded to
 	the devspace (format is local:remote comma separated):
 	devspace add port 8080:80,3000
+	devspace add port 8080:3000 --selector=app=web
 	#######################################################
 	`,
 		Args: cobra.ExactArgs(1),
@@ -135,12 +139,17 @@ func init() {
 	#######################################################
 	Adds an existing helm chart to the devspace
 	(run 'devspace add package' to display all available 
-	helm charts)
+	helm charts). The argument is a search term for the
+	charts of the stable repository. The package is added
+	to the requirements.yaml of the chart and its values
+	with commonly tuned keys are added to the values.yaml
 	
 	Examples:
 	devspace add package
 	devspace add package mysql
+	devspace add package sql
 	devspace add package mysql --app-version=5.7.14
+	devspace add package redis --version=4.2.1
 	devspace add package mysql --chart-version=0.10.3 -d devspace-default
 	#######################################################
 	`,
@@ -149,8 +158,9 @@ func init() {
 
 	addPackageCmd.Flags().StringVar(&cmd.packageFlags.AppVersion, "app-version", "", "App version")
 	addPackageCmd.Flags().StringVar(&cmd.packageFlags.ChartVersion, "chart-version", "", "Chart version")
+	addPackageCmd.Flags().StringVar(&cmd.packageFlags.ChartVersion, "version", "", "Chart version to add (default: asks for the version, the latest version with --skip-question)")
 	addPackageCmd.Flags().StringVarP(&cmd.packageFlags.Deployment, "deployment", "d", "", "The deployment name to use")
-	addPackageCmd.Flags().BoolVar(&cmd.packageFlags.SkipQuestion, "skip-question", false, "Skips the question to show the readme in a browser")
+	addPackageCmd.Flags().BoolVar(&cmd.packageFlags.SkipQuestion, "skip-question", false, "Skips all questions (adds the latest version and its values and doesn't show the readme)")
 
 	addCmd.AddCommand(addPackageCmd)
 
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"github.com/covexo/devspace/pkg/devspace/analyze"
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/spf13/cobra"
+)
+
+// AnalyzeCmd holds the information needed for the analyze command
+type AnalyzeCmd struct {
+	flags *AnalyzeCmdFlags
+}
+
+// AnalyzeCmdFlags holds the possible flags for the analyze command
+type AnalyzeCmdFlags struct {
+	labelSelector   string
+	namespace       string
+	events          bool
+	switchContext   bool
+	config          string
+	configOverwrite string
+}
+
+func init() {
+	cmd := &AnalyzeCmd{
+		flags: &AnalyzeCmdFlags{},
+	}
+
+	cobraCmd := &cobra.Command{
+		Use:   "analyze",
+		Short: "Analyzes failing pods of your DevSpace",
+		Long: `
+#######################################################
+################## devspace analyze ###################
+#######################################################
+Inspects the pods of your DevSpace and prints a report
+for every pod that does not start: problems like
+OOMKilled, CrashLoopBackOff or image pull errors, the
+warning events of the pod and the last log lines of
+crashed containers:
+
+devspace analyze
+devspace analyze -l app=api -n my-namespace
+#######################################################`,
+		Args: cobra.NoArgs,
+		Run:  cmd.Run,
+	}
+	rootCmd.AddCommand(cobraCmd)
+
+	cobraCmd.Flags().StringVarP(&cmd.flags.labelSelector, "label-selector", "l", "", "Comma separated key=value selector list (e.g. release=test)")
+	cobraCmd.Flags().StringVarP(&cmd.flags.namespace, "namespace", "n", "", "Namespace where to analyze pods")
+	cobraCmd.Flags().BoolVar(&cmd.flags.switchContext, "switch-context", false, "Switch kubectl context to the devspace context")
+	cobraCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	cobraCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+
+	syncTraceCmd := &cobra.Command{
+		Use:   "sync-trace [file]",
+		Short: "Summarizes a recorded sync trace",
+		Long: `
+#######################################################
+########### devspace analyze sync-trace ###############
+#######################################################
+Summarizes a sync trace that was recorded with
+devspace sync --trace or the traceFile option of a sync
+path: transfers, conflicts, errors, local changes that
+were never uploaded and files that were uploaded and
+downloaded again:
+
+devspace analyze sync-trace sync-trace.json
+devspace analyze sync-trace sync-trace.json --events
+#######################################################`,
+		Args: cobra.ExactArgs(1),
+		Run:  cmd.RunSyncTrace,
+	}
+	cobraCmd.AddCommand(syncTraceCmd)
+
+	syncTraceCmd.Flags().BoolVar(&cmd.flags.events, "events", false, "Print every recorded event in order")
+}
+
+// Run executes the analyze command logic
+func (cmd *AnalyzeCmd) Run(cobraCmd *cobra.Command, args []string) {
+	if configutil.ConfigPath != cmd.flags.config {
+		configutil.ConfigPath = cmd.flags.config
+
+		// Don't use overwrite config if we use a different config
+		configutil.OverwriteConfigPath = ""
+	}
+	if configutil.OverwriteConfigPath != cmd.flags.configOverwrite {
+		configutil.OverwriteConfigPath = cmd.flags.configOverwrite
+	}
+
+	log.StartFileLogging()
+
+	client, err := newKubectlClient(cmd.flags.switchContext)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	log.StartWait("Analyzing pods")
+	reports, err := analyze.Pods(client, cmd.flags.namespace, cmd.flags.labelSelector)
+	log.StopWait()
+	if err != nil {
+		log.Fatalf("Error analyzing pods: %v", err)
+	}
+
+	analyze.PrintReports(reports, log.GetInstance())
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/covexo/devspace/pkg/devspace/sync"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/spf13/cobra"
+)
+
+// RunSyncTrace executes the devspace analyze sync-trace command logic
+func (cmd *AnalyzeCmd) RunSyncTrace(cobraCmd *cobra.Command, args []string) {
+	file, err := os.Open(args[0])
+	if err != nil {
+		log.Fatalf("Unable to open sync trace: %v", err)
+	}
+
+	defer file.Close()
+
+	events, err := sync.ReadTrace(file)
+	if err != nil {
+		log.Fatalf("Unable to read sync trace %s: %v", args[0], err)
+	}
+
+	if cmd.flags.events {
+		values := make([][]string, 0, len(events))
+		for _, event := range events {
+			values = append(values, []string{event.Time.Format("15:04:05.000"), event.Type, event.Path, event.Message})
+		}
+
+		log.PrintTable([]string{"Time", "Type", "Path", "Message"}, values)
+		log.Write([]byte("\n"))
+	}
+
+	summary := sync.SummarizeTrace(events)
+	log.Infof("Recorded %d events in %v", len(events), summary.End.Sub(summary.Start))
+
+	eventTypes := make([]string, 0, len(summary.Counts))
+	for eventType := range summary.Counts {
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	sort.Strings(eventTypes)
+
+	values := make([][]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		values = append(values, []string{eventType, strconv.Itoa(summary.Counts[eventType])})
+	}
+
+	log.PrintTable([]string{"Event", "Count"}, values)
+
+	for _, event := range summary.Errors {
+		log.Failf("Error at %s: %s", event.Time.Format("15:04:05.000"), event.Message)
+	}
+	for _, event := range summary.Conflicts {
+		log.Warnf("Conflict on %s at %s: %s", event.Path, event.Time.Format("15:04:05.000"), event.Message)
+	}
+	for _, path := range summary.NotUploaded {
+		log.Warnf("%s changed locally, but was never uploaded (excluded or unchanged)", path)
+	}
+	for _, path := range summary.Bounced {
+		log.Warnf("%s was uploaded and downloaded again", path)
+	}
+
+	if len(summary.Errors) == 0 && len(summary.Conflicts) == 0 && len(summary.NotUploaded) == 0 && len(summary.Bounced) == 0 {
+		log.Done("No problems found in sync trace")
+	}
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/docker"
+	"github.com/covexo/devspace/pkg/devspace/helm"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/devspace/registry"
+	"github.com/covexo/devspace/pkg/devspace/upgrade"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/signalutil"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+// BootstrapCmd holds the information needed for the bootstrap command
+type BootstrapCmd struct {
+	flags *BootstrapCmdFlags
+}
+
+// BootstrapCmdFlags holds the possible flags for the bootstrap command
+type BootstrapCmdFlags struct {
+	switchContext   bool
+	config          string
+	configOverwrite string
+}
+
+func init() {
+	cmd := &BootstrapCmd{
+		flags: &BootstrapCmdFlags{},
+	}
+
+	cobraCmd := &cobra.Command{
+		Use:   "bootstrap",
+		Short: "Prepares the cluster for devspace up and deploy",
+		Long: `
+#######################################################
+################# devspace bootstrap ##################
+#######################################################
+Prepares the cluster once, so that developers only
+need permissions for their namespace:
+
+* Creates the default namespace
+* Creates the cluster role binding (google cloud)
+* Installs tiller or upgrades it to tiller.version
+* Deploys the internal registry
+* Creates the image pull secrets
+
+Afterwards the config map devspace-bootstrap in the
+tiller namespace marks the cluster as bootstrapped and
+devspace up and deploy skip these steps. They fail if
+tiller or the internal registry are missing. Run this
+command again to repair the setup or to refresh pull
+secrets with expiring credentials (e.g. ECR).
+#######################################################`,
+		Args: cobra.NoArgs,
+		Run:  cmd.Run,
+	}
+
+	cobraCmd.Flags().BoolVar(&cmd.flags.switchContext, "switch-context", false, "Switch kubectl context to the devspace context")
+	cobraCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	cobraCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+
+	rootCmd.AddCommand(cobraCmd)
+}
+
+// Run executes the devspace bootstrap command logic
+func (cmd *BootstrapCmd) Run(cobraCmd *cobra.Command, args []string) {
+	if configutil.ConfigPath != cmd.flags.config {
+		configutil.ConfigPath = cmd.flags.config
+
+		// Don't use overwrite config if we use a different config
+		configutil.OverwriteConfigPath = ""
+	}
+	if configutil.OverwriteConfigPath != cmd.flags.configOverwrite {
+		configutil.OverwriteConfigPath = cmd.flags.configOverwrite
+	}
+
+	log.StartFileLogging()
+
+	client, err := newKubectlClient(cmd.flags.switchContext)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	// Ctrl+C cancels waiting for tiller and the internal registry, the second Ctrl+C force quits
+	ctx, stopInterruptHandler := signalutil.InterruptContext(log.GetInstance())
+	defer stopInterruptHandler()
+
+	err = bootstrapCluster(ctx, client)
+	if err != nil {
+		if ctx.Err() != nil {
+			log.Fatal(errCancelled)
+		}
+
+		log.Fatal(err)
+	}
+
+	err = kubectl.MarkBootstrapped(client, upgrade.GetVersion())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Donef("Successfully bootstrapped the cluster. Developers can run devspace up and deploy with namespace permissions now")
+}
+
+// bootstrapCluster creates the namespace, the cluster role binding, tiller, the internal registry and the pull secrets
+func bootstrapCluster(ctx context.Context, client kubernetes.Interface) error {
+	err := kubectl.EnsureDefaultNamespace(client, log.GetInstance())
+	if err != nil {
+		return fmt.Errorf("Unable to create namespace: %v", err)
+	}
+
+	err = kubectl.EnsureGoogleCloudClusterRoleBinding(client, log.GetInstance())
+	if err != nil {
+		return fmt.Errorf("Unable to ensure cluster-admin role binding: %v", err)
+	}
+
+	err = helm.EnsureTiller(ctx, client, true)
+	if err != nil {
+		return fmt.Errorf("Unable to install tiller: %v", err)
+	}
+
+	dockerClient, err := docker.NewClient(false)
+	if err != nil {
+		return err
+	}
+
+	return registry.InitRegistries(ctx, dockerClient, client, log.GetInstance())
+}
+
+// setupCluster prepares the cluster for devspace up and deploy. On a cluster prepared by devspace bootstrap, nothing
+// is created and only the url of the internal registry is retrieved, so that developers don't need cluster wide
+// permissions
+func setupCluster(ctx context.Context, client kubernetes.Interface, initRegistries bool) error {
+	bootstrapped, err := kubectl.IsBootstrapped(client)
+	if err != nil {
+		return err
+	}
+
+	if bootstrapped {
+		log.Info("Cluster was prepared by devspace bootstrap, skipping cluster setup")
+
+		if initRegistries {
+			return registry.CheckRegistries(ctx, client, log.GetInstance())
+		}
+
+		return nil
+	}
+
+	// Create namespace if necessary
+	err = kubectl.EnsureDefaultNamespace(client, log.GetInstance())
+	if err != nil {
+		return fmt.Errorf("Unable to create namespace: %v", err)
+	}
+
+	// Create cluster role binding if necessary
+	err = kubectl.EnsureGoogleCloudClusterRoleBinding(client, log.GetInstance())
+	if err != nil {
+		return fmt.Errorf("Unable to ensure cluster-admin role binding: %v", err)
+	}
+
+	// Init image registries
+	if initRegistries {
+		dockerClient, err := docker.NewClient(false)
+		if err != nil {
+			return err
+		}
+
+		err = registry.InitRegistries(ctx, dockerClient, client, log.GetInstance())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
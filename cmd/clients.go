@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/covexo/devspace/pkg/devspace/deploy"
+	"github.com/covexo/devspace/pkg/devspace/helm"
+	"github.com/covexo/devspace/pkg/devspace/image"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+)
+
+// The commands create their clients and run the build and deployment through these variables, so that tests can
+// replace them with fakes (e.g. the fake clientset of k8s.io/client-go/kubernetes/fake) and don't need a cluster
+
+// newKubectlClient creates the kubernetes client and switches the kubectl context if switchContext is true
+var newKubectlClient = kubectl.NewClientWithContextSwitch
+
+// newHelmClient creates the helm client and deploys tiller if necessary
+var newHelmClient = helm.NewClient
+
+// buildImages builds the images whose Dockerfile or context changed (or all images if forced) and returns true if
+// an image was built
+var buildImages = image.BuildAll
+
+// deployAll deploys the deployments of the config
+var deployAll = deploy.All
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"github.com/covexo/devspace/pkg/devspace/cloud"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/spf13/cobra"
+)
+
+// CloudCmd holds the information needed for the cloud command
+type CloudCmd struct {
+	flags *CloudCmdFlags
+}
+
+// CloudCmdFlags holds the possible flags for the cloud command
+type CloudCmdFlags struct {
+	host string
+}
+
+func init() {
+	cmd := &CloudCmd{
+		flags: &CloudCmdFlags{},
+	}
+
+	cloudCmd := &cobra.Command{
+		Use:   "cloud",
+		Short: "Manages the cloud providers",
+		Long: `
+#######################################################
+#################### devspace cloud ###################
+#######################################################
+Manages the cloud providers in ~/.devspace/clouds.yaml:
+
+* Add a provider or change its host (set-provider)
+#######################################################`,
+		Args: cobra.NoArgs,
+	}
+
+	rootCmd.AddCommand(cloudCmd)
+
+	cloudSetProviderCmd := &cobra.Command{
+		Use:   "set-provider [name]",
+		Short: "Adds a cloud provider or changes its host",
+		Long: `
+#######################################################
+############# devspace cloud set-provider #############
+#######################################################
+Adds a cloud provider to ~/.devspace/clouds.yaml or
+changes the host of an existing provider. The host has
+to be reachable. If the host changes, you have to
+login to the provider again:
+
+devspace cloud set-provider my-cloud --host=https://cli.my-cloud.com
+#######################################################`,
+		Args: cobra.ExactArgs(1),
+		Run:  cmd.RunSetProvider,
+	}
+
+	cloudSetProviderCmd.Flags().StringVar(&cmd.flags.host, "host", "", "The url of the cloud provider")
+	cloudSetProviderCmd.MarkFlagRequired("host")
+
+	cloudCmd.AddCommand(cloudSetProviderCmd)
+}
+
+// RunSetProvider executes the devspace cloud set-provider command logic
+func (cmd *CloudCmd) RunSetProvider(cobraCmd *cobra.Command, args []string) {
+	log.StartWait("Checking host " + cmd.flags.host)
+	err := cloud.SetProvider(args[0], cmd.flags.host)
+	log.StopWait()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Donef("Successfully set cloud provider %s", args[0])
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/stdinutil"
+	ct "github.com/daviddengcn/go-colortext"
+	"github.com/spf13/cobra"
+)
+
+// ConfigCmd holds the information needed for the config command
+type ConfigCmd struct {
+	flags *ConfigCmdFlags
+}
+
+// ConfigCmdFlags holds the possible flags for the config command
+type ConfigCmdFlags struct {
+	config          string
+	configOverwrite string
+}
+
+func init() {
+	cmd := &ConfigCmd{
+		flags: &ConfigCmdFlags{},
+	}
+
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manages the devspace config",
+		Long: `
+#######################################################
+################### devspace config ###################
+#######################################################
+Manages the devspace config:
+
+* Restore a backup of the config (restore)
+* Check the config for errors (validate)
+* Show the changes of the overwrite config (diff)
+#######################################################`,
+		Args: cobra.NoArgs,
+	}
+
+	rootCmd.AddCommand(configCmd)
+
+	configRestoreCmd := &cobra.Command{
+		Use:   "restore [backup number]",
+		Short: "Restores a backup of the config",
+		Long: `
+#######################################################
+############### devspace config restore ###############
+#######################################################
+Restores a backup of .devspace/config.yaml. A backup
+is saved every time devspace changes the config (e.g.
+devspace add or devspace remove) and the last 5
+backups are kept (.bak.1 is the newest one).
+Without a backup number, all backups are listed and
+you can select the backup to restore:
+
+devspace config restore
+devspace config restore 2
+#######################################################`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  cmd.RunRestore,
+	}
+
+	configCmd.AddCommand(configRestoreCmd)
+
+	configValidateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Checks the config for errors",
+		Long: `
+#######################################################
+############### devspace config validate ##############
+#######################################################
+Loads the devspace config and reports all errors
+(e.g. deployments without name, services that don't
+exist or invalid port mappings) without deploying.
+Exits with 1 if the config is invalid, so it can be
+used in a git pre-commit hook or a CI pipeline:
+
+devspace config validate
+devspace config validate --config=.devspace/prod.yaml
+#######################################################`,
+		Args: cobra.NoArgs,
+		Run:  cmd.RunValidate,
+	}
+
+	configValidateCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	configValidateCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+
+	configCmd.AddCommand(configValidateCmd)
+
+	configDiffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Shows the differences between the loaded and the saved config",
+		Long: `
+#######################################################
+################ devspace config diff #################
+#######################################################
+Shows the differences between the saved config file
+and the config devspace works with after merging the
+overwrite config as unified diff (like git diff).
+Comments and formatting of the config file are
+ignored:
+
+devspace config diff
+devspace config diff --config-overwrite=.devspace/prod.yaml
+#######################################################`,
+		Args: cobra.NoArgs,
+		Run:  cmd.RunDiff,
+	}
+
+	configDiffCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	configDiffCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+
+	configCmd.AddCommand(configDiffCmd)
+}
+
+// RunValidate executes the config validate command logic
+func (cmd *ConfigCmd) RunValidate(cobraCmd *cobra.Command, args []string) {
+	validateConfig(cmd.flags.config, cmd.flags.configOverwrite)
+}
+
+// RunDiff executes the config diff command logic
+func (cmd *ConfigCmd) RunDiff(cobraCmd *cobra.Command, args []string) {
+	setConfigPaths(cmd.flags.config, cmd.flags.configOverwrite)
+
+	err := configutil.LoadConfig()
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+
+	// The defaults are never saved, so they aren't part of the diff
+	diff, err := configutil.Diff(configutil.GetConfigWithoutDefaults())
+	if err != nil {
+		log.Fatalf("Error comparing config: %v", err)
+	}
+
+	if diff == "" {
+		log.Infof("No differences between the loaded config and %s", configutil.ConfigPath)
+		return
+	}
+
+	printDiff(diff)
+}
+
+// printDiff prints a unified diff with added lines in green and removed lines in red
+func printDiff(diff string) {
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			log.WriteColored(line, ct.White)
+		case strings.HasPrefix(line, "@@"):
+			log.WriteColored(line, ct.Cyan)
+		case strings.HasPrefix(line, "+"):
+			log.WriteColored(line, ct.Green)
+		case strings.HasPrefix(line, "-"):
+			log.WriteColored(line, ct.Red)
+		default:
+			log.Write([]byte(line))
+		}
+	}
+}
+
+// RunRestore executes the config restore command logic
+func (cmd *ConfigCmd) RunRestore(cobraCmd *cobra.Command, args []string) {
+	backups, err := configutil.GetConfigBackups()
+	if err != nil {
+		log.Fatalf("Error listing config backups: %v", err)
+	}
+
+	if len(backups) == 0 {
+		log.Info("No config backups found")
+		return
+	}
+
+	number := 0
+	if len(args) == 1 {
+		number, err = strconv.Atoi(args[0])
+		if err != nil {
+			log.Fatalf("Invalid backup number %s", args[0])
+		}
+	} else {
+		options := make([]string, len(backups))
+		for index, backup := range backups {
+			options[index] = fmt.Sprintf("%s (%s)", backup.Path, backup.ModTime.Format("2006-01-02 15:04:05"))
+		}
+
+		selected := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
+			Question:     "Which backup do you want to restore?",
+			DefaultValue: options[0],
+			Options:      options,
+		})
+
+		for index, option := range options {
+			if option == selected {
+				number = backups[index].Number
+			}
+		}
+	}
+
+	err = configutil.RestoreConfigBackup(number)
+	if err != nil {
+		log.Fatalf("Error restoring config backup: %v", err)
+	}
+
+	log.Donef("Successfully restored %s. The previous config was saved to %s", configutil.GetConfigBackupPath(number), configutil.GetConfigBackupPath(1))
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/services"
+	"github.com/covexo/devspace/pkg/devspace/sync"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/spf13/cobra"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CpCmd is a struct that defines a command call for "cp"
+type CpCmd struct {
+	flags *CpCmdFlags
+
+	// selected holds the selected pod and container of each target, so that the pod is only selected once
+	selected map[string]*selectedContainer
+}
+
+// selectedContainer is a container that was selected for a target of devspace cp
+type selectedContainer struct {
+	pod           *k8sv1.Pod
+	containerName string
+}
+
+// CpCmdFlags are the flags available for the cp-command
+type CpCmdFlags struct {
+	service         string
+	namespace       string
+	labelSelector   string
+	podName         string
+	container       string
+	allowProtected  bool
+	switchContext   bool
+	config          string
+	configOverwrite string
+}
+
+// cpPath is a source or destination of devspace cp. Container paths are written as [target]:path
+type cpPath struct {
+	path      string
+	container bool
+
+	// target is the service or pod name before the colon (empty for the default container)
+	target string
+}
+
+func init() {
+	cmd := &CpCmd{
+		flags:    &CpCmdFlags{},
+		selected: map[string]*selectedContainer{},
+	}
+
+	cobraCmd := &cobra.Command{
+		Use:   "cp",
+		Short: "Copy files between your computer and a container",
+		Long: `
+#######################################################
+##################### devspace cp #####################
+#######################################################
+Copies files and folders between your computer and a
+container without starting the sync. Container paths
+are prefixed with a colon. The name before the colon
+selects a service (in config) or a pod, otherwise the
+container of devspace enter is used:
+
+devspace cp :/tmp/heap.out ./
+devspace cp app:/tmp/heap.out ./
+devspace cp ./config.json :/app/config.json
+devspace cp ./a.txt ./b.txt :/tmp/
+devspace cp -n my-namespace my-pod:/var/log ./logs
+
+Folders are copied recursively. If a source can't be
+copied, the other sources are copied anyway and the
+command exits with a non-zero exit code.
+#######################################################`,
+		Args: cobra.MinimumNArgs(2),
+		Run:  cmd.Run,
+	}
+	rootCmd.AddCommand(cobraCmd)
+
+	cobraCmd.Flags().StringVarP(&cmd.flags.service, "service", "s", "", "Service name (in config) to select the pod/container for paths without name")
+	cobraCmd.Flags().StringVarP(&cmd.flags.container, "container", "c", "", "Container name within the pod")
+	cobraCmd.Flags().StringVarP(&cmd.flags.labelSelector, "label-selector", "l", "", "Comma separated key=value selector list (e.g. release=test)")
+	cobraCmd.Flags().StringVar(&cmd.flags.podName, "pod", "", "Name of the pod to copy from or to (instead of a label selector)")
+	cobraCmd.Flags().StringVarP(&cmd.flags.namespace, "namespace", "n", "", "Namespace where to select pods")
+	cobraCmd.Flags().BoolVar(&cmd.flags.allowProtected, "allow-protected", false, "Allows to copy from and to pods that are annotated as protected")
+	cobraCmd.Flags().BoolVar(&cmd.flags.switchContext, "switch-context", false, "Switch kubectl context to the devspace context")
+	cobraCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	cobraCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+}
+
+// Run executes the command logic
+func (cmd *CpCmd) Run(cobraCmd *cobra.Command, args []string) {
+	if configutil.ConfigPath != cmd.flags.config {
+		configutil.ConfigPath = cmd.flags.config
+
+		// Don't use overwrite config if we use a different config
+		configutil.OverwriteConfigPath = ""
+	}
+	if configutil.OverwriteConfigPath != cmd.flags.configOverwrite {
+		configutil.OverwriteConfigPath = cmd.flags.configOverwrite
+	}
+
+	sources := make([]*cpPath, 0, len(args)-1)
+	for _, arg := range args[:len(args)-1] {
+		sources = append(sources, parseCpPath(arg))
+	}
+	dest := parseCpPath(args[len(args)-1])
+
+	for _, path := range append(sources, dest) {
+		if path.path == "" {
+			log.Fatal("Please specify a path after the colon (e.g. devspace cp :/tmp/heap.out ./)")
+		}
+	}
+	for _, source := range sources {
+		if source.container == dest.container {
+			log.Fatal("Please copy either from the container to your computer or from your computer to the container (e.g. devspace cp :/tmp/heap.out ./)")
+		}
+	}
+
+	// Multiple sources are copied into a folder like with cp
+	if len(sources) > 1 {
+		if dest.container && strings.HasSuffix(dest.path, "/") == false {
+			dest.path += "/"
+		} else if dest.container == false {
+			stat, err := os.Stat(dest.path)
+			if err != nil || stat.IsDir() == false {
+				log.Fatalf("Target %s is not a folder", dest.path)
+			}
+		}
+	}
+
+	// Without a config we only use the flags and the pod names in the paths
+	podName := cmd.flags.podName
+	if podName == "" {
+		for _, path := range append(sources, dest) {
+			if path.container && path.target != "" {
+				podName = path.target
+				break
+			}
+		}
+	}
+	if initRemoteMode(cmd.flags.labelSelector, podName) == false {
+		log.StartFileLogging()
+		log.Infof("Loading config %s with overwrite config %s", configutil.ConfigPath, configutil.OverwriteConfigPath)
+	}
+
+	client, err := newKubectlClient(cmd.flags.switchContext)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	failed := 0
+	for _, source := range sources {
+		var err error
+		if source.container {
+			pod, containerName, selectErr := cmd.selectContainer(client, source.target)
+			if selectErr != nil {
+				log.Fatal(selectErr)
+			}
+
+			err = sync.DownloadFromContainer(client, pod, containerName, source.path, dest.path, log.GetInstance())
+		} else {
+			pod, containerName, selectErr := cmd.selectContainer(client, dest.target)
+			if selectErr != nil {
+				log.Fatal(selectErr)
+			}
+
+			err = sync.UploadToContainer(client, pod, containerName, source.path, dest.path, log.GetInstance())
+		}
+
+		if err != nil {
+			log.Fail(err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		log.Fatalf("%d of %d sources could not be copied", failed, len(sources))
+	}
+}
+
+// selectContainer returns the pod and container for the target of a container path. The target is used as service
+// name if such a service exists and as pod name otherwise
+func (cmd *CpCmd) selectContainer(client kubernetes.Interface, target string) (*k8sv1.Pod, string, error) {
+	if selected, ok := cmd.selected[target]; ok {
+		return selected.pod, selected.containerName, nil
+	}
+
+	serviceName, podName := cmd.flags.service, cmd.flags.podName
+	if target != "" {
+		if _, err := configutil.GetService(target); err == nil {
+			serviceName = target
+		} else {
+			podName = target
+		}
+	}
+
+	pod, containerName, err := services.SelectContainer(client, serviceName, cmd.flags.container, cmd.flags.labelSelector, cmd.flags.namespace, podName, cmd.flags.allowProtected, log.GetInstance())
+	if err != nil {
+		return nil, "", err
+	}
+
+	cmd.selected[target] = &selectedContainer{pod: pod, containerName: containerName}
+	return pod, containerName, nil
+}
+
+// parseCpPath parses a path of devspace cp. Paths with a colon before the first slash are container paths, except
+// windows drive letters (e.g. C:\project)
+func parseCpPath(arg string) *cpPath {
+	colon := strings.Index(arg, ":")
+	if colon == -1 || strings.ContainsAny(arg[:colon], `/\`) {
+		return &cpPath{path: arg}
+	}
+	if runtime.GOOS == "windows" && colon == 1 {
+		return &cpPath{path: arg}
+	}
+
+	return &cpPath{
+		path:      arg[colon+1:],
+		container: true,
+		target:    arg[:colon],
+	}
+}
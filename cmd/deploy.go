@@ -1,16 +1,18 @@
 package cmd
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/covexo/devspace/pkg/devspace/cloud"
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
 	"github.com/covexo/devspace/pkg/devspace/config/generated"
 	"github.com/covexo/devspace/pkg/devspace/config/v1"
 	"github.com/covexo/devspace/pkg/devspace/deploy"
-	"github.com/covexo/devspace/pkg/devspace/docker"
-	"github.com/covexo/devspace/pkg/devspace/image"
-	"github.com/covexo/devspace/pkg/devspace/kubectl"
-	"github.com/covexo/devspace/pkg/devspace/registry"
+	"github.com/covexo/devspace/pkg/devspace/preview"
 	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/signalutil"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -21,15 +23,30 @@ type DeployCmd struct {
 
 // DeployCmdFlags holds the possible down cmd flags
 type DeployCmdFlags struct {
-	Namespace       string
-	KubeContext     string
-	Config          string
-	ConfigOverwrite string
-	DockerTarget    string
-	CloudTarget     string
-	SwitchContext   bool
-	SkipBuild       bool
-	GitBranch       string
+	Namespace           string
+	KubeContext         string
+	Config              string
+	ConfigOverwrite     string
+	DockerTarget        string
+	CloudTarget         string
+	SwitchContext       bool
+	SkipBuild           bool
+	ValidateBuild       bool
+	DockerBuildKit      bool
+	BuildKitInlineCache bool
+	SkipConflicts       bool
+	Force               bool
+	UpdateDependencies  bool
+	Atomic              bool
+	NoCleanupOnFailure  bool
+	ShowValuesDiff      bool
+	RollbackOnFailure   bool
+	SkipIfUnchanged     bool
+	PrintHash           bool
+	SummaryFile         string
+	Preview             bool
+	PreviewBranch       string
+	GitBranch           string
 }
 
 func init() {
@@ -51,6 +68,11 @@ devspace deploy --namespace=deploy --docker-target=production
 devspace deploy --kube-context=deploy-context
 devspace deploy --config=.devspace/deploy.yaml
 devspace deploy --cloud-target=production
+devspace deploy --skip-if-unchanged
+devspace deploy --print-hash
+devspace deploy --summary-file=deploy-summary.json
+devspace deploy --preview
+devspace deploy --preview --preview-branch=feature/login
 devspace deploy https://github.com/covexo/devspace --branch test
 #######################################################`,
 		Args: cobra.RangeArgs(0, 2),
@@ -65,6 +87,21 @@ devspace deploy https://github.com/covexo/devspace --branch test
 	cobraCmd.Flags().StringVar(&cmd.flags.CloudTarget, "cloud-target", "", "When using a cloud provider, the target to use")
 	cobraCmd.Flags().BoolVar(&cmd.flags.SwitchContext, "switch-context", false, "Switches the kube context to the deploy context")
 	cobraCmd.Flags().BoolVar(&cmd.flags.SkipBuild, "skip-build", false, "Skips the image build & push step")
+	cobraCmd.Flags().BoolVar(&cmd.flags.ValidateBuild, "validate-dockerfiles", false, "Checks the dockerfiles for obvious problems before building")
+	cobraCmd.Flags().BoolVar(&cmd.flags.DockerBuildKit, "docker-buildkit", false, "Builds the images with Docker BuildKit (DOCKER_BUILDKIT=1)")
+	cobraCmd.Flags().BoolVar(&cmd.flags.BuildKitInlineCache, "buildkit-inline-cache", false, "Writes the BuildKit cache metadata into the images (BUILDKIT_INLINE_CACHE=1) to use them as cache source")
+	cobraCmd.Flags().BoolVar(&cmd.flags.SkipConflicts, "skip-conflict-check", false, "Skips the check for existing resources that are not managed by the release")
+	cobraCmd.Flags().BoolVar(&cmd.flags.Force, "force", false, "Deploys even if resources of the chart already exist and are not managed by the release")
+	cobraCmd.Flags().BoolVar(&cmd.flags.UpdateDependencies, "update-dependencies", false, "Resolves the chart dependencies again, even if the downloaded archives are up to date")
+	cobraCmd.Flags().BoolVar(&cmd.flags.Atomic, "atomic", false, "Rolls back failed upgrades and purges failed installations of helm releases")
+	cobraCmd.Flags().BoolVar(&cmd.flags.NoCleanupOnFailure, "no-cleanup-on-failure", false, "Leaves failed helm releases in place for debugging, even with --atomic (default without --atomic)")
+	cobraCmd.Flags().BoolVar(&cmd.flags.RollbackOnFailure, "rollback-on-failure", false, "Rolls helm releases back to the previous revision if their pod doesn't get ready or crashes after the deployment")
+	cobraCmd.Flags().BoolVar(&cmd.flags.ShowValuesDiff, "show-values-diff", true, "Prints the helm values that changed since the last deployment")
+	cobraCmd.Flags().BoolVar(&cmd.flags.SkipIfUnchanged, "skip-if-unchanged", false, "Skips the deployment if nothing changed since the last successful deployment (compares the deploy hash in .devspace/generated.yaml)")
+	cobraCmd.Flags().BoolVar(&cmd.flags.PrintHash, "print-hash", false, "Prints the deploy hash of the current config, build contexts, charts and manifests and exits")
+	cobraCmd.Flags().StringVar(&cmd.flags.SummaryFile, "summary-file", "", "Writes a json summary of the deployed images and releases (or the failure) to this file, e.g. for CI artifacts")
+	cobraCmd.Flags().BoolVar(&cmd.flags.Preview, "preview", false, "Deploys a preview environment of the git branch with its own namespace and release names (see devspace.preview)")
+	cobraCmd.Flags().StringVar(&cmd.flags.PreviewBranch, "preview-branch", "", "The branch of the preview environment (default: the current git branch)")
 	// cobraCmd.Flags().StringVar(&cmd.flags.GitBranch, "branch", "master", "The git branch to checkout")
 
 	rootCmd.AddCommand(cobraCmd)
@@ -98,56 +135,125 @@ func (cmd *DeployCmd) Run(cobraCmd *cobra.Command, args []string) {
 	cloud.UseDeployTarget = true
 	log.StartFileLogging()
 
+	if cmd.flags.Preview && cmd.flags.Namespace != "" {
+		log.Fatal("--namespace can't be used with --preview, configure the namespace of previews with devspace.preview.namespace")
+	}
+
+	// Only the hash should be printed to stdout
+	if cmd.flags.PrintHash {
+		log.SetLevel(logrus.ErrorLevel)
+	}
+
 	// Prepare the config
 	cmd.prepareConfig()
 
 	log.Infof("Loading config %s with overwrite config %s", configutil.ConfigPath, configutil.OverwriteConfigPath)
 
-	// Create kubectl client
-	client, err := kubectl.NewClientWithContextSwitch(cmd.flags.SwitchContext)
+	// Load generated config
+	generatedConfig, err := generated.LoadConfig()
 	if err != nil {
-		log.Fatalf("Unable to create new kubectl client: %v", err)
+		cmd.fatal(nil, deploy.PhaseConfig, fmt.Errorf("Error loading generated.yaml: %v", err))
 	}
 
-	// Create namespace if necessary
-	err = kubectl.EnsureDefaultNamespace(client, log.GetInstance())
-	if err != nil {
-		log.Fatalf("Unable to create namespace: %v", err)
+	// Previews are deployed with their own namespace and release names
+	var previewEnv *preview.Preview
+	var previewValues map[string]string
+	if cmd.flags.Preview {
+		previewEnv, previewValues, err = cmd.preparePreview()
+		if err != nil {
+			cmd.fatal(generatedConfig, deploy.PhaseConfig, err)
+		}
 	}
 
-	// Create cluster binding if necessary
-	err = kubectl.EnsureGoogleCloudClusterRoleBinding(client, log.GetInstance())
+	// The deploy hash is calculated before any kubernetes or registry client is created, so unchanged deployments
+	// can be skipped without cluster access
+	deployHash := ""
+	if cmd.flags.PrintHash || cmd.flags.SkipIfUnchanged {
+		deployHash, err = deploy.Hash()
+		if err != nil {
+			cmd.fatal(generatedConfig, deploy.PhaseConfig, fmt.Errorf("Error calculating deploy hash: %v", err))
+		}
+
+		if cmd.flags.PrintHash {
+			fmt.Println(deployHash)
+			return
+		}
+		if generatedConfig.DeployHash == deployHash {
+			log.Donef("No changes detected since the last deployment, skipping deployment")
+			cmd.writeSummary(deploy.GetSummary(generatedConfig, deploy.SummaryStatusSuccess))
+			return
+		}
+	}
+
+	// Create kubectl client
+	client, err := newKubectlClient(cmd.flags.SwitchContext)
 	if err != nil {
-		log.Fatalf("Unable to ensure cluster-admin role binding: %v", err)
+		cmd.fatal(generatedConfig, deploy.PhaseSetup, fmt.Errorf("Unable to create new kubectl client: %v", err))
 	}
 
-	// Create docker client
-	dockerClient, err := docker.NewClient(false)
+	// Record the preview before anything is deployed, so that it can be purged even if the deployment fails
+	if previewEnv != nil {
+		err = preview.Register(client, *configutil.GetConfig().Tiller.Namespace, previewEnv)
+		if err != nil {
+			cmd.fatal(generatedConfig, deploy.PhaseSetup, err)
+		}
 
-	// Create pull secrets and private registry if necessary
-	err = registry.InitRegistries(dockerClient, client, log.GetInstance())
-	if err != nil {
-		log.Fatal(err)
+		log.Infof("Deploying preview of branch %s to namespace %s", previewEnv.Branch, previewEnv.Namespace)
 	}
 
-	// Load generated config
-	generatedConfig, err := generated.LoadConfig()
+	// Ctrl+C cancels the build and deployment, the second Ctrl+C force quits
+	ctx, stopInterruptHandler := signalutil.InterruptContext(log.GetInstance())
+	defer stopInterruptHandler()
+
+	// Create namespace, cluster role binding, pull secrets and private registry if the cluster wasn't bootstrapped
+	err = setupCluster(ctx, client, true)
 	if err != nil {
-		log.Fatalf("Error loading generated.yaml: %v", err)
+		if ctx.Err() != nil {
+			cmd.fatal(generatedConfig, deploy.PhaseSetup, errCancelled)
+		}
+
+		cmd.fatal(generatedConfig, deploy.PhaseSetup, err)
 	}
 
 	if cmd.flags.SkipBuild == false {
 		// Force image build
-		_, err = image.BuildAll(client, generatedConfig, true, log.GetInstance())
+		_, err = buildImages(ctx, client, generatedConfig, true, cmd.flags.ValidateBuild, cmd.flags.DockerBuildKit, cmd.flags.BuildKitInlineCache, log.GetInstance())
 		if err != nil {
-			log.Fatal(err)
+			if ctx.Err() != nil {
+				cmd.fatal(generatedConfig, deploy.PhaseBuild, saveCancelled(generatedConfig))
+			}
+
+			cmd.fatal(generatedConfig, deploy.PhaseBuild, err)
 		}
 	}
 
 	// Force deployment of all defined deployments
-	err = deploy.All(client, generatedConfig, true, false, log.GetInstance())
+	err = deployAll(ctx, client, generatedConfig, &deploy.Options{
+		ForceDeploy:        true,
+		SkipConflictCheck:  cmd.flags.SkipConflicts,
+		ForceConflicts:     cmd.flags.Force,
+		UpdateDependencies: cmd.flags.UpdateDependencies,
+		CleanupOnFailure:   cmd.flags.Atomic && cmd.flags.NoCleanupOnFailure == false,
+		ShowValuesDiff:     cmd.flags.ShowValuesDiff,
+		RollbackOnFailure:  cmd.flags.RollbackOnFailure,
+		Values:             previewValues,
+	}, log.GetInstance())
 	if err != nil {
-		log.Fatal(err)
+		if ctx.Err() != nil {
+			log.Warn(err)
+			cmd.fatal(generatedConfig, deploy.PhaseDeploy, saveCancelled(generatedConfig))
+		}
+
+		cmd.fatal(generatedConfig, deploy.PhaseDeploy, err)
+	}
+
+	// Remember the deploy hash, so the next deployment can be skipped if nothing changed. Deployments without
+	// --skip-if-unchanged reset the hash, because we don't know what they deployed
+	generatedConfig.DeployHash = deployHash
+
+	err = generated.SaveConfig(generatedConfig)
+	if err != nil {
+		cmd.fatal(generatedConfig, deploy.PhaseSave, fmt.Errorf("Error saving generated config: %v", err))
 	}
 
 	// Print domain name if we use a cloud provider
@@ -157,9 +263,68 @@ func (cmd *DeployCmd) Run(cobraCmd *cobra.Command, args []string) {
 		log.Info("See https://devspace-cloud.com/domain-guide for more information")
 	}
 
+	cmd.writeSummary(deploy.GetSummary(generatedConfig, deploy.SummaryStatusSuccess))
+	if previewEnv != nil {
+		log.Donef("Successfully deployed preview of branch %s to namespace %s", previewEnv.Branch, previewEnv.Namespace)
+		return
+	}
+
 	log.Donef("Successfully deployed!")
 }
 
+// preparePreview changes the namespace and the release names of the config to the preview of the branch and returns
+// the preview and its chart values
+func (cmd *DeployCmd) preparePreview() (*preview.Preview, map[string]string, error) {
+	branch := cmd.flags.PreviewBranch
+	if branch == "" {
+		var err error
+
+		branch, err = preview.GetCurrentBranch()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	config := configutil.GetConfig()
+	previewEnv, err := preview.New(config, branch, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return previewEnv, preview.Apply(config, previewEnv), nil
+}
+
+// fatal writes a failure summary if --summary-file is set and exits with the error. generatedConfig is nil if it
+// couldn't be loaded
+func (cmd *DeployCmd) fatal(generatedConfig *generated.Config, phase string, err error) {
+	if cmd.flags.SummaryFile != "" {
+		if generatedConfig == nil {
+			generatedConfig = &generated.Config{}
+		}
+
+		summary := deploy.GetSummary(generatedConfig, deploy.SummaryStatusFailure)
+		summary.SetFailure(phase, err)
+		cmd.writeSummary(summary)
+	}
+
+	log.Fatal(err)
+}
+
+// writeSummary writes the summary to the file of --summary-file
+func (cmd *DeployCmd) writeSummary(summary *deploy.Summary) {
+	if cmd.flags.SummaryFile == "" {
+		return
+	}
+
+	err := deploy.WriteSummary(summary, cmd.flags.SummaryFile)
+	if err != nil {
+		log.Warnf("Error writing summary file %s: %v", cmd.flags.SummaryFile, err)
+		return
+	}
+
+	log.Infof("Wrote deploy summary to %s", cmd.flags.SummaryFile)
+}
+
 func (cmd *DeployCmd) prepareConfig() {
 	if configutil.ConfigPath != cmd.flags.Config {
 		configutil.ConfigPath = cmd.flags.Config
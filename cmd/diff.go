@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/generated"
+	deployHelm "github.com/covexo/devspace/pkg/devspace/deploy/helm"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/spf13/cobra"
+)
+
+// DiffCmd holds the information needed for the diff command
+type DiffCmd struct {
+	flags *DiffCmdFlags
+}
+
+// DiffCmdFlags holds the possible flags for the diff command
+type DiffCmdFlags struct {
+	devOverwrite    bool
+	config          string
+	configOverwrite string
+}
+
+func init() {
+	cmd := &DiffCmd{
+		flags: &DiffCmdFlags{},
+	}
+
+	cobraCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Shows the differences between the deployed and the configured helm values",
+		Long: `
+#######################################################
+################### devspace diff #####################
+#######################################################
+Compares the values of the deployed helm releases with
+the values the config would deploy. Lines starting
+with - are only deployed, lines starting with + would
+be deployed with the current config:
+
+devspace diff
+devspace diff --dev-overwrite=false
+#######################################################`,
+		Args: cobra.NoArgs,
+		Run:  cmd.Run,
+	}
+	rootCmd.AddCommand(cobraCmd)
+
+	cobraCmd.Flags().BoolVar(&cmd.flags.devOverwrite, "dev-overwrite", true, "Compare with the values of devspace up (including devOverwrite), use false to compare with the values of devspace deploy")
+	cobraCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	cobraCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+}
+
+// Run executes the diff command logic
+func (cmd *DiffCmd) Run(cobraCmd *cobra.Command, args []string) {
+	if configutil.ConfigPath != cmd.flags.config {
+		configutil.ConfigPath = cmd.flags.config
+
+		// Don't use overwrite config if we use a different config
+		configutil.OverwriteConfigPath = ""
+	}
+	if configutil.OverwriteConfigPath != cmd.flags.configOverwrite {
+		configutil.OverwriteConfigPath = cmd.flags.configOverwrite
+	}
+
+	config := configutil.GetConfig()
+	if config.DevSpace.Deployments == nil || len(*config.DevSpace.Deployments) == 0 {
+		log.Info("No deployments are configured")
+		return
+	}
+
+	generatedConfig, err := generated.LoadConfig()
+	if err != nil {
+		log.Fatalf("Error loading generated.yaml: %v", err)
+	}
+
+	client, err := newKubectlClient(false)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	for _, deployConfig := range *config.DevSpace.Deployments {
+		if deployConfig.Helm == nil {
+			log.Infof("Skipping deployment %s (only helm deployments can be compared)", *deployConfig.Name)
+			continue
+		}
+
+		helmClient, err := deployHelm.New(client, deployConfig, cmd.flags.devOverwrite, log.GetInstance())
+		if err != nil {
+			log.Fatalf("Error creating helm client for deployment %s: %v", *deployConfig.Name, err)
+		}
+
+		diff, deployed, err := helmClient.Diff(generatedConfig)
+		if err != nil {
+			log.Fatalf("Error comparing values of deployment %s: %v", *deployConfig.Name, err)
+		}
+
+		if deployed == false {
+			log.Infof("Release %s is not deployed yet", *deployConfig.Name)
+		} else if diff == "" {
+			log.Donef("Release %s: no changes", *deployConfig.Name)
+			continue
+		} else {
+			log.Infof("Release %s:", *deployConfig.Name)
+		}
+
+		fmt.Print(diff)
+	}
+}
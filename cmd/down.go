@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"strings"
+
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
 	"github.com/covexo/devspace/pkg/devspace/deploy"
 	deployHelm "github.com/covexo/devspace/pkg/devspace/deploy/helm"
 	deployKubectl "github.com/covexo/devspace/pkg/devspace/deploy/kubectl"
-	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/devspace/services"
 	"github.com/covexo/devspace/pkg/util/log"
 	"k8s.io/client-go/kubernetes"
 
@@ -19,6 +21,7 @@ type DownCmd struct {
 
 // DownCmdFlags holds the possible down cmd flags
 type DownCmdFlags struct {
+	purge           bool
 	config          string
 	configOverwrite string
 }
@@ -35,14 +38,17 @@ func init() {
 #######################################################
 ################### devspace down #####################
 #######################################################
-Stops your DevSpace by removing the release via helm.
-If you want to remove all DevSpace related data from
-your project, use: devspace reset
+Stops your DevSpace by removing the release via helm
+and stops the port forwarding and sync of a running
+devspace up. Use --purge to remove the release history
+as well. If you want to remove all DevSpace related
+data from your project, use: devspace reset
 #######################################################`,
 		Args: cobra.NoArgs,
 		Run:  cmd.Run,
 	}
 
+	cobraCmd.Flags().BoolVar(&cmd.flags.purge, "purge", false, "Removes the release history, so that the release name can be reused")
 	cobraCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
 	cobraCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
 
@@ -64,16 +70,28 @@ func (cmd *DownCmd) Run(cobraCmd *cobra.Command, args []string) {
 	log.StartFileLogging()
 	log.Infof("Loading config %s with overwrite config %s", configutil.ConfigPath, configutil.OverwriteConfigPath)
 
-	kubectl, err := kubectl.NewClient()
+	kubectl, err := newKubectlClient(false)
 	if err != nil {
 		log.Fatalf("Unable to create new kubectl client: %s", err.Error())
 	}
 
-	deleteDevSpace(kubectl)
+	// Stop port forwarding and sync of a running devspace up
+	pid, err := services.StopPortForwardingProcess()
+	if err != nil {
+		log.Warnf("Unable to stop port forwarding: %v", err)
+	} else if pid != 0 {
+		log.Donef("Stopped port forwarding and sync (process %d)", pid)
+	}
+
+	deleted, skipped, failed := deleteDevSpace(kubectl, cmd.flags.purge)
+	log.Infof("Deleted %d deployment(s), skipped %d, failed %d", deleted, skipped, failed)
 }
 
-func deleteDevSpace(kubectl *kubernetes.Clientset) {
+// deleteDevSpace deletes all deployments of the config and returns how many deployments were deleted, skipped
+// because they don't exist and failed
+func deleteDevSpace(kubectl kubernetes.Interface, purge bool) (int, int, int) {
 	config := configutil.GetConfig()
+	deleted, skipped, failed := 0, 0, 0
 
 	if config.DevSpace.Deployments != nil {
 		for _, deployConfig := range *config.DevSpace.Deployments {
@@ -85,24 +103,40 @@ func deleteDevSpace(kubectl *kubernetes.Clientset) {
 				deployClient, err = deployKubectl.New(kubectl, deployConfig, log.GetInstance())
 				if err != nil {
 					log.Warnf("Unable to create kubectl deploy config: %v", err)
+					failed++
 					continue
 				}
 			} else {
-				deployClient, err = deployHelm.New(kubectl, deployConfig, false, log.GetInstance())
+				helmDeployClient, err := deployHelm.New(kubectl, deployConfig, false, log.GetInstance())
 				if err != nil {
 					log.Warnf("Unable to create helm deploy config: %v", err)
+					failed++
 					continue
 				}
+
+				helmDeployClient.Purge = purge
+				deployClient = helmDeployClient
 			}
 
-			log.StartWait("Deleting deployment %s" + *deployConfig.Name)
+			log.StartWait("Deleting deployment " + *deployConfig.Name)
 			err = deployClient.Delete()
 			log.StopWait()
 			if err != nil {
-				log.Warnf("Error deleting deployment %s: %v", *deployConfig.Name, err)
+				if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "already deleted") {
+					log.Warnf("Deployment %s not found or already deleted, skipping", *deployConfig.Name)
+					skipped++
+				} else {
+					log.Warnf("Error deleting deployment %s: %v", *deployConfig.Name, err)
+					failed++
+				}
+
+				continue
 			}
 
 			log.Donef("Successfully deleted deployment %s", *deployConfig.Name)
+			deleted++
 		}
 	}
+
+	return deleted, skipped, failed
 }
@@ -1,8 +1,9 @@
 package cmd
 
 import (
+	"os"
+
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
-	"github.com/covexo/devspace/pkg/devspace/kubectl"
 	"github.com/covexo/devspace/pkg/devspace/services"
 	"github.com/covexo/devspace/pkg/util/log"
 	"github.com/spf13/cobra"
@@ -18,7 +19,11 @@ type EnterCmdFlags struct {
 	service         string
 	namespace       string
 	labelSelector   string
+	podName         string
 	container       string
+	tty             bool
+	noTTY           bool
+	allowProtected  bool
 	switchContext   bool
 	config          string
 	configOverwrite string
@@ -45,6 +50,10 @@ devspace enter -s my-service
 devspace enter -c my-container
 devspace enter bash -n my-namespace
 devspace enter bash -l release=test
+echo "select 1" | devspace enter -T psql
+
+Without a devspace config, enter works with flags only:
+devspace enter -n my-namespace --pod my-pod
 #######################################################`,
 		Run: cmd.Run,
 	}
@@ -53,7 +62,11 @@ devspace enter bash -l release=test
 	cobraCmd.Flags().StringVarP(&cmd.flags.service, "service", "s", "", "Service name (in config) to select pod/container for terminal")
 	cobraCmd.Flags().StringVarP(&cmd.flags.container, "container", "c", "", "Container name within pod where to execute command")
 	cobraCmd.Flags().StringVarP(&cmd.flags.labelSelector, "label-selector", "l", "", "Comma separated key=value selector list (e.g. release=test)")
+	cobraCmd.Flags().StringVar(&cmd.flags.podName, "pod", "", "Name of the pod to start the terminal in (instead of a label selector)")
 	cobraCmd.Flags().StringVarP(&cmd.flags.namespace, "namespace", "n", "", "Namespace where to select pods")
+	cobraCmd.Flags().BoolVar(&cmd.flags.tty, "tty", true, "Allocates a TTY for the command (only if stdin is a terminal)")
+	cobraCmd.Flags().BoolVarP(&cmd.flags.noTTY, "no-tty", "T", false, "Disables the TTY allocation (same as --tty=false)")
+	cobraCmd.Flags().BoolVar(&cmd.flags.allowProtected, "allow-protected", false, "Allows to open the terminal in a pod that is annotated as protected")
 	cobraCmd.Flags().BoolVar(&cmd.flags.switchContext, "switch-context", false, "Switch kubectl context to the devspace context")
 	cobraCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
 	cobraCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
@@ -71,16 +84,30 @@ func (cmd *EnterCmd) Run(cobraCmd *cobra.Command, args []string) {
 		configutil.OverwriteConfigPath = cmd.flags.configOverwrite
 	}
 
-	log.StartFileLogging()
-	log.Infof("Loading config %s with overwrite config %s", configutil.ConfigPath, configutil.OverwriteConfigPath)
+	// Without a config we only use the flags and don't create a .devspace folder
+	if initRemoteMode(cmd.flags.labelSelector, cmd.flags.podName) == false {
+		log.StartFileLogging()
+		log.Infof("Loading config %s with overwrite config %s", configutil.ConfigPath, configutil.OverwriteConfigPath)
+	}
 
-	kubectl, err := kubectl.NewClientWithContextSwitch(cmd.flags.switchContext)
+	kubectl, err := newKubectlClient(cmd.flags.switchContext)
 	if err != nil {
 		log.Fatalf("Unable to create new kubectl client: %v", err)
 	}
 
-	err = services.StartTerminal(kubectl, cmd.flags.service, cmd.flags.container, cmd.flags.labelSelector, cmd.flags.namespace, args, log.GetInstance())
+	// The tty flags overwrite the tty option of the terminal config only if they are specified
+	var tty *bool
+	if cobraCmd.Flags().Changed("tty") || cmd.flags.noTTY {
+		allocateTTY := cmd.flags.tty && cmd.flags.noTTY == false
+		tty = &allocateTTY
+	}
+
+	exitCode, err := services.StartTerminal(kubectl, cmd.flags.service, cmd.flags.container, cmd.flags.labelSelector, cmd.flags.namespace, cmd.flags.podName, tty, cmd.flags.allowProtected, args, log.GetInstance())
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
 }
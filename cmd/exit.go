@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/util/log"
+)
+
+// Exit codes of devspace up, so that CI pipelines can tell why the command failed. Other errors exit with 1
+const (
+	ExitCodeConfigError        = 2
+	ExitCodeBuildFailure       = 3
+	ExitCodeDeployFailure      = 4
+	ExitCodeSyncError          = 5
+	ExitCodeTimeout            = 6
+	ExitCodeClusterUnreachable = 7
+)
+
+// exitCodeError is an error that ends the command with a specific exit code
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+// withExitCode returns an error that ends the command with the exit code if it is passed to exitOnError
+func withExitCode(code int, err error) error {
+	return &exitCodeError{
+		code: code,
+		err:  err,
+	}
+}
+
+// Exit prints the error message and exits with the exit code
+func Exit(code int, format string, args ...interface{}) {
+	log.Exitf(code, format, args...)
+}
+
+// exitOnError exits with the exit code of the error (see withExitCode) or with defaultCode. Errors caused by a timeout
+// always exit with ExitCodeTimeout
+func exitOnError(err error, defaultCode int) {
+	code := defaultCode
+	if exitErr, ok := err.(*exitCodeError); ok {
+		code = exitErr.code
+	}
+	if isTimeout(err) {
+		code = ExitCodeTimeout
+	}
+
+	Exit(code, "%v", err)
+}
+
+// isTimeout checks if the error was caused by waiting too long, e.g. for tiller, the internal registry or a pod
+func isTimeout(err error) bool {
+	if exitErr, ok := err.(*exitCodeError); ok {
+		err = exitErr.err
+	}
+
+	return err == context.DeadlineExceeded || strings.Contains(strings.ToLower(err.Error()), "timed out")
+}
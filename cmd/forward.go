@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/spf13/cobra"
+)
+
+// ForwardCmd holds the information needed for the forward command
+type ForwardCmd struct {
+	flags *ForwardCmdFlags
+}
+
+// ForwardCmdFlags holds the possible flags for the forward command
+type ForwardCmdFlags struct {
+	namespace       string
+	labelSelector   string
+	service         string
+	podName         string
+	addresses       []string
+	switchContext   bool
+	config          string
+	configOverwrite string
+}
+
+func init() {
+	cmd := &ForwardCmd{
+		flags: &ForwardCmdFlags{},
+	}
+
+	cobraCmd := &cobra.Command{
+		Use:   "forward [local:remote]...",
+		Short: "Forwards local ports to a pod",
+		Long: `
+#######################################################
+################## devspace forward ###################
+#######################################################
+Forwards the given local ports to a pod until you press
+Ctrl+C. Works without a devspace config as well:
+
+devspace forward 8080:80 -l app=api
+devspace forward 8080:80 3000:3000 --pod my-pod -n my-namespace
+devspace forward 8080:80 -l app=api --address=localhost
+devspace forward 8080:80 -s api
+#######################################################`,
+		Args: cobra.MinimumNArgs(1),
+		Run:  cmd.Run,
+	}
+	rootCmd.AddCommand(cobraCmd)
+
+	cobraCmd.Flags().StringVarP(&cmd.flags.namespace, "namespace", "n", "", "Namespace where to select pods")
+	cobraCmd.Flags().StringVarP(&cmd.flags.labelSelector, "label-selector", "l", "", "Comma separated key=value selector list (e.g. release=test)")
+	cobraCmd.Flags().StringVarP(&cmd.flags.service, "service", "s", "", "Service name (in config) to select the pod to forward the ports to (instead of a label selector)")
+	cobraCmd.Flags().StringVar(&cmd.flags.podName, "pod", "", "Name of the pod to forward the ports to (instead of a label selector)")
+	cobraCmd.Flags().StringSliceVar(&cmd.flags.addresses, "address", []string{kubectl.DefaultBindAddress}, "Local addresses to listen on (use localhost to listen on 127.0.0.1 and ::1)")
+	cobraCmd.Flags().BoolVar(&cmd.flags.switchContext, "switch-context", false, "Switch kubectl context to the devspace context")
+	cobraCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	cobraCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+}
+
+// Run executes the forward command logic
+func (cmd *ForwardCmd) Run(cobraCmd *cobra.Command, args []string) {
+	if configutil.ConfigPath != cmd.flags.config {
+		configutil.ConfigPath = cmd.flags.config
+
+		// Don't use overwrite config if we use a different config
+		configutil.OverwriteConfigPath = ""
+	}
+	if configutil.OverwriteConfigPath != cmd.flags.configOverwrite {
+		configutil.OverwriteConfigPath = cmd.flags.configOverwrite
+	}
+
+	// Without a config we only use the flags and don't create a .devspace folder
+	if initRemoteMode(cmd.flags.labelSelector, cmd.flags.podName) == false {
+		log.StartFileLogging()
+	}
+
+	client, err := newKubectlClient(cmd.flags.switchContext)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	labelSelector, namespace, _, err := getRemoteSelector(cmd.flags.service, cmd.flags.labelSelector, cmd.flags.podName, cmd.flags.namespace, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pod, err := getRemotePod(client, cmd.flags.podName, labelSelector, namespace)
+	if err != nil {
+		log.Fatalf("Cannot find running pod: %v", err)
+	}
+
+	stopChan := make(chan struct{})
+	readyChan := make(chan struct{})
+	errorChan := make(chan error, 1)
+
+	go func() {
+		errorChan <- kubectl.ForwardPorts(client, pod, args, cmd.flags.addresses, stopChan, readyChan)
+	}()
+
+	// Wait till forwarding is ready
+	select {
+	case <-readyChan:
+		log.Donef("Port forwarding started on %s (Pod: %s/%s)", strings.Join(args, ", "), pod.Namespace, pod.Name)
+	case err = <-errorChan:
+		log.Fatalf("Error starting port forwarding: %v", err)
+	case <-time.After(20 * time.Second):
+		log.Fatal("Timeout waiting for port forwarding to start")
+	}
+
+	// Forward until the user interrupts the command or the forwarding fails
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case <-signals:
+		close(stopChan)
+	case err = <-errorChan:
+		if err != nil {
+			log.Fatalf("Port forwarding error: %v", err)
+		}
+	}
+}
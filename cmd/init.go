@@ -4,6 +4,7 @@ import (
 	"context"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/covexo/devspace/pkg/devspace/cloud"
@@ -106,7 +107,7 @@ YOUR_PROJECT_PATH/
 	cobraCmd.Flags().BoolVarP(&cmd.flags.skipQuestions, "yes", "y", cmd.flags.skipQuestions, "Answer all questions with their default value")
 	cobraCmd.Flags().StringVar(&cmd.flags.templateRepoURL, "templateRepoUrl", cmd.flags.templateRepoURL, "Git repository for chart templates")
 	cobraCmd.Flags().StringVar(&cmd.flags.templateRepoPath, "templateRepoPath", cmd.flags.templateRepoPath, "Local path for cloning chart template repository (uses temp folder if not specified)")
-	cobraCmd.Flags().StringVarP(&cmd.flags.language, "language", "l", cmd.flags.language, "Programming language of your project")
+	cobraCmd.Flags().StringVarP(&cmd.flags.language, "language", "l", cmd.flags.language, "Programming language of your project (e.g. go, python, node, java or ruby)")
 }
 
 // Run executes the command logic
@@ -228,23 +229,20 @@ func (cmd *InitCmd) useCloudProvider() bool {
 	}
 
 	if len(providerConfig) > 1 {
-		cloudProviderOptions := "("
-
+		cloudProviderOptions := []string{}
 		for name := range providerConfig {
-			if len(cloudProviderOptions) > 1 {
-				cloudProviderOptions += ", "
-			}
-
-			cloudProviderOptions += name
+			cloudProviderOptions = append(cloudProviderOptions, name)
 		}
 
-		cloudProviderOptions += ")"
+		sort.Strings(cloudProviderOptions)
+		cloudProviderOptions = append(cloudProviderOptions, "no")
 		cloudProviderSelected := cmd.flags.cloudProvider
 
 		for _, ok := providerConfig[cloudProviderSelected]; ok == false && cloudProviderSelected != "no"; {
 			cloudProviderSelected = strings.TrimSpace(*stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
-				Question:     "Do you want to use a cloud provider? (no to skip) " + cloudProviderOptions,
+				Question:     "Do you want to use a cloud provider? (no to skip)",
 				DefaultValue: cloud.DevSpaceCloudProviderName,
+				Options:      cloudProviderOptions,
 			}))
 
 			_, ok = providerConfig[cloudProviderSelected]
@@ -462,9 +460,9 @@ func (cmd *InitCmd) determineLanguage() {
 
 		if !cmd.flags.skipQuestions {
 			cmd.chartGenerator.Language = *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
-				Question:               "What is the major programming language of your project?\nSupported languages: " + strings.Join(supportedLanguages, ", "),
-				DefaultValue:           detectedLang,
-				ValidationRegexPattern: "^(" + strings.Join(supportedLanguages, ")|(") + ")$",
+				Question:     "What is the major programming language of your project?",
+				DefaultValue: detectedLang,
+				Options:      supportedLanguages,
 			})
 		}
 	}
@@ -19,7 +19,7 @@ import (
 type InstallCmd struct {
 	flags    *InstallCmdFlags
 	helm     *helmClient.ClientWrapper
-	kubectl  *kubernetes.Clientset
+	kubectl  kubernetes.Interface
 	dsConfig *v1.DevSpaceConfig
 }
 
@@ -1,14 +1,24 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/helm"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/devspace/preview"
+	"github.com/covexo/devspace/pkg/devspace/sync"
 	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/output"
 	"github.com/covexo/devspace/pkg/util/yamlutil"
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // ListCmd holds the information needed for the list command
@@ -18,6 +28,11 @@ type ListCmd struct {
 
 // ListCmdFlags holds the possible flags for the list command
 type ListCmdFlags struct {
+	output        string
+	json          bool
+	yaml          bool
+	allNamespaces bool
+	deployment    string
 }
 
 func init() {
@@ -35,12 +50,27 @@ func init() {
 	Lists the following configurations:
 	
 	* Sync paths (sync)
+	* Sync status (sync-status)
 	* Forwarded ports (port)
+	* Packages (package)
+	* Deployments (deployments)
+	* Services (service)
+	* Namespaces created by devspace (namespaces)
+	* Helm releases (releases)
+	* Preview environments (previews)
+	
+	All lists can be printed as table (default), json or
+	yaml, e.g. devspace list port --output=json or
+	devspace list port --json
 	#######################################################
 	`,
 		Args: cobra.NoArgs,
 	}
 
+	listCmd.PersistentFlags().StringVarP(&cmd.flags.output, "output", "o", output.FormatTable, "The output format ("+strings.Join(output.Formats, ", ")+")")
+	listCmd.PersistentFlags().BoolVar(&cmd.flags.json, "json", false, "Print the list as json (same as --output=json)")
+	listCmd.PersistentFlags().BoolVar(&cmd.flags.yaml, "yaml", false, "Print the list as yaml (same as --output=yaml)")
+
 	rootCmd.AddCommand(listCmd)
 
 	listSyncCmd := &cobra.Command{
@@ -59,6 +89,25 @@ func init() {
 
 	listCmd.AddCommand(listSyncCmd)
 
+	listSyncStatusCmd := &cobra.Command{
+		Use:   "sync-status",
+		Short: "Shows the status of the running sync",
+		Long: `
+	#######################################################
+	############## devspace list sync-status ##############
+	#######################################################
+	Shows per sync path how many files are queued and
+	were transferred, how many errors occurred and when
+	the last sync happened. Requires a running sync
+	(started with devspace up)
+	#######################################################
+	`,
+		Args: cobra.NoArgs,
+		Run:  cmd.RunListSyncStatus,
+	}
+
+	listCmd.AddCommand(listSyncStatusCmd)
+
 	listPortCmd := &cobra.Command{
 		Use:   "port",
 		Short: "Lists port forwarding configuration",
@@ -82,15 +131,37 @@ func init() {
 	#######################################################
 	############### devspace list package #################
 	#######################################################
-	Lists the packages that were added to the DevSpace
+	Lists the packages that were added to the helm charts
+	of the deployments:
+	devspace list package
+	devspace list package -d devspace-default
 	#######################################################
 	`,
 		Args: cobra.NoArgs,
 		Run:  cmd.RunListPackage,
 	}
 
+	listPackageCmd.Flags().StringVarP(&cmd.flags.deployment, "deployment", "d", "", "Only list the packages of this deployment")
+
 	listCmd.AddCommand(listPackageCmd)
 
+	listDeploymentsCmd := &cobra.Command{
+		Use:     "deployments",
+		Aliases: []string{"deployment"},
+		Short:   "Lists all deployments",
+		Long: `
+	#######################################################
+	############ devspace list deployments ################
+	#######################################################
+	Lists the deployments that are defined in the DevSpace
+	#######################################################
+	`,
+		Args: cobra.NoArgs,
+		Run:  cmd.RunListDeployments,
+	}
+
+	listCmd.AddCommand(listDeploymentsCmd)
+
 	listServiceCmd := &cobra.Command{
 		Use:   "service",
 		Short: "Lists all services",
@@ -106,47 +177,190 @@ func init() {
 	}
 
 	listCmd.AddCommand(listServiceCmd)
+
+	listNamespacesCmd := &cobra.Command{
+		Use:   "namespaces",
+		Short: "Lists all namespaces created by devspace",
+		Long: `
+	#######################################################
+	############# devspace list namespaces ################
+	#######################################################
+	Lists all namespaces in the cluster that were created
+	by devspace (labeled with created-by=devspace) and
+	shows which of them are used by the current config
+	#######################################################
+	`,
+		Args: cobra.NoArgs,
+		Run:  cmd.RunListNamespaces,
+	}
+
+	listCmd.AddCommand(listNamespacesCmd)
+
+	listReleasesCmd := &cobra.Command{
+		Use:   "releases",
+		Short: "Lists the helm releases of the devspace",
+		Long: `
+	#######################################################
+	############## devspace list releases #################
+	#######################################################
+	Lists the helm releases in the namespaces of the
+	configured deployments (or in all namespaces with
+	--all-namespaces) without requiring the helm cli
+	#######################################################
+	`,
+		Args: cobra.NoArgs,
+		Run:  cmd.RunListReleases,
+	}
+
+	listReleasesCmd.Flags().BoolVar(&cmd.flags.allNamespaces, "all-namespaces", false, "List the releases of all namespaces")
+
+	listCmd.AddCommand(listReleasesCmd)
+
+	listPreviewsCmd := &cobra.Command{
+		Use:   "previews",
+		Short: "Lists the preview environments",
+		Long: `
+	#######################################################
+	############## devspace list previews #################
+	#######################################################
+	Lists the preview environments that were deployed with
+	devspace deploy --preview and are recorded in the
+	cluster
+	#######################################################
+	`,
+		Args: cobra.NoArgs,
+		Run:  cmd.RunListPreviews,
+	}
+
+	listCmd.AddCommand(listPreviewsCmd)
 }
 
-// RunListPackage runs the list sync command logic
+// RunListPackage runs the list package command logic
 func (cmd *ListCmd) RunListPackage(cobraCmd *cobra.Command, args []string) {
+	config := configutil.GetConfig()
+
 	headerColumnNames := []string{
+		"Deployment",
 		"Name",
 		"Version",
 		"Repository",
 	}
 	values := [][]string{}
 
-	cwd, err := os.Getwd()
+	found := cmd.flags.deployment == ""
+	if config.DevSpace.Deployments != nil {
+		for _, deployConfig := range *config.DevSpace.Deployments {
+			if cmd.flags.deployment != "" && cmd.flags.deployment != *deployConfig.Name {
+				continue
+			}
+
+			found = true
+			if deployConfig.Helm == nil || deployConfig.Helm.ChartPath == nil {
+				continue
+			}
+
+			// This is the requirements.yaml that devspace add package and devspace remove package change
+			requirementsFile := filepath.Join(*deployConfig.Helm.ChartPath, "requirements.yaml")
+			_, err := os.Stat(requirementsFile)
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			yamlContents := map[interface{}]interface{}{}
+			err = yamlutil.ReadYamlFromFile(requirementsFile, yamlContents)
+			if err != nil {
+				log.Fatalf("Error parsing %s: %v", requirementsFile, err)
+			}
+
+			if dependencies, ok := yamlContents["dependencies"]; ok {
+				if dependenciesArr, ok := dependencies.([]interface{}); ok {
+					for _, dependency := range dependenciesArr {
+						if dependencyMap, ok := dependency.(map[interface{}]interface{}); ok {
+							values = append(values, []string{
+								*deployConfig.Name,
+								getDependencyValue(dependencyMap, "name"),
+								getDependencyValue(dependencyMap, "version"),
+								getDependencyValue(dependencyMap, "repository"),
+							})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if found == false {
+		log.Fatalf("Deployment %s not found", cmd.flags.deployment)
+	}
+
+	cmd.printList(headerColumnNames, values, "No packages were added. Run `devspace add package` to add a package\n")
+}
+
+// getDependencyValue returns the value of a dependency in a requirements.yaml as string
+func getDependencyValue(dependency map[interface{}]interface{}, key string) string {
+	value, ok := dependency[key]
+	if ok == false || value == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// RunListDeployments runs the list deployments command logic
+func (cmd *ListCmd) RunListDeployments(cobraCmd *cobra.Command, args []string) {
+	config := configutil.GetConfig()
+
+	if config.DevSpace.Deployments == nil || len(*config.DevSpace.Deployments) == 0 {
+		cmd.printList(nil, nil, "No deployments are configured. Run `devspace add deployment` to add a deployment\n")
+		return
+	}
+
+	headerColumnNames := []string{
+		"Name",
+		"Namespace",
+		"Type",
+		"Chart / Manifests",
+	}
+
+	defaultNamespace, err := configutil.GetDefaultNamespace(config)
 	if err != nil {
-		log.Fatal(err)
+		log.Fatalf("Unable to get default namespace: %v", err)
 	}
 
-	requirementsFile := filepath.Join(cwd, "chart", "requirements.yaml")
-	_, err = os.Stat(requirementsFile)
-	if os.IsNotExist(err) == false {
-		yamlContents := map[interface{}]interface{}{}
-		err = yamlutil.ReadYamlFromFile(requirementsFile, yamlContents)
-		if err != nil {
-			log.Fatalf("Error parsing %s: %v", requirementsFile, err)
+	deployments := make([][]string, 0, len(*config.DevSpace.Deployments))
+	for _, deployConfig := range *config.DevSpace.Deployments {
+		namespace := defaultNamespace
+		if deployConfig.Namespace != nil && *deployConfig.Namespace != "" {
+			namespace = *deployConfig.Namespace
 		}
 
-		if dependencies, ok := yamlContents["dependencies"]; ok {
-			if dependenciesArr, ok := dependencies.([]interface{}); ok {
-				for _, dependency := range dependenciesArr {
-					if dependencyMap, ok := dependency.(map[interface{}]interface{}); ok {
-						values = append(values, []string{
-							dependencyMap["name"].(string),
-							dependencyMap["version"].(string),
-							dependencyMap["repository"].(string),
-						})
-					}
+		deploymentType := "unknown"
+		source := ""
+		if deployConfig.Helm != nil {
+			deploymentType = "helm"
+			source = getStringValue(deployConfig.Helm.ChartPath)
+		} else if deployConfig.Kubectl != nil {
+			deploymentType = "kubectl"
+
+			if deployConfig.Kubectl.Manifests != nil {
+				manifests := make([]string, 0, len(*deployConfig.Kubectl.Manifests))
+				for _, manifest := range *deployConfig.Kubectl.Manifests {
+					manifests = append(manifests, getStringValue(manifest))
 				}
+
+				source = strings.Join(manifests, ", ")
 			}
 		}
+
+		deployments = append(deployments, []string{
+			*deployConfig.Name,
+			namespace,
+			deploymentType,
+			source,
+		})
 	}
 
-	log.PrintTable(headerColumnNames, values)
+	cmd.printList(headerColumnNames, deployments, "")
 }
 
 // RunListService runs the list service command logic
@@ -154,7 +368,7 @@ func (cmd *ListCmd) RunListService(cobraCmd *cobra.Command, args []string) {
 	config := configutil.GetConfig()
 
 	if config.DevSpace.Services == nil || len(*config.DevSpace.Services) == 0 {
-		log.Info("No services are configured. Run `devspace add service` to add new service\n")
+		cmd.printList(nil, nil, "No services are configured. Run `devspace add service` to add new service\n")
 		return
 	}
 
@@ -171,12 +385,8 @@ func (cmd *ListCmd) RunListService(cobraCmd *cobra.Command, args []string) {
 	// Transform values into string arrays
 	for _, value := range *config.DevSpace.Services {
 		selector := ""
-		for k, v := range *value.LabelSelector {
-			if len(selector) > 0 {
-				selector += ", "
-			}
-
-			selector += k + "=" + *v
+		if value.LabelSelector != nil {
+			selector = kubectl.LabelSelectorToString(*value.LabelSelector)
 		}
 
 		resourceType := "pod"
@@ -204,7 +414,7 @@ func (cmd *ListCmd) RunListService(cobraCmd *cobra.Command, args []string) {
 		})
 	}
 
-	log.PrintTable(headerColumnNames, services)
+	cmd.printList(headerColumnNames, services, "")
 }
 
 // RunListSync runs the list sync command logic
@@ -212,7 +422,7 @@ func (cmd *ListCmd) RunListSync(cobraCmd *cobra.Command, args []string) {
 	config := configutil.GetConfig()
 
 	if config.DevSpace.Sync == nil || len(*config.DevSpace.Sync) == 0 {
-		log.Info("No sync paths are configured. Run `devspace add sync` to add new sync path\n")
+		cmd.printList(nil, nil, "No sync paths are configured. Run `devspace add sync` to add new sync path\n")
 		return
 	}
 
@@ -233,37 +443,76 @@ func (cmd *ListCmd) RunListSync(cobraCmd *cobra.Command, args []string) {
 
 		if value.Service != nil {
 			service = *value.Service
-		} else {
-			for k, v := range *value.LabelSelector {
-				if len(selector) > 0 {
-					selector += ", "
-				}
-
-				selector += k + "=" + *v
-			}
+		} else if value.PodName != nil && *value.PodName != "" {
+			selector = "pod/" + *value.PodName
+		} else if value.LabelSelector != nil {
+			selector = kubectl.LabelSelectorToString(*value.LabelSelector)
 		}
-		excludedPaths := ""
 
+		excludedPaths := ""
 		if value.ExcludePaths != nil {
-			for _, v := range *value.ExcludePaths {
-				if len(excludedPaths) > 0 {
-					excludedPaths += ", "
-				}
+			excludedPaths = strings.Join(*value.ExcludePaths, ", ")
+		}
 
-				excludedPaths += v
-			}
+		// The local path defaults to the project folder like in the sync
+		localPath := getStringValue(value.LocalSubPath)
+		if localPath == "" {
+			localPath = "./"
 		}
 
 		syncPaths = append(syncPaths, []string{
 			service,
 			selector,
-			*value.LocalSubPath,
-			*value.ContainerPath,
+			localPath,
+			getStringValue(value.ContainerPath),
 			excludedPaths,
 		})
 	}
 
-	log.PrintTable(headerColumnNames, syncPaths)
+	cmd.printList(headerColumnNames, syncPaths, "")
+}
+
+// RunListSyncStatus runs the list sync-status command logic
+func (cmd *ListCmd) RunListSyncStatus(cobraCmd *cobra.Command, args []string) {
+	metrics, err := sync.GetStatus()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(metrics) == 0 {
+		cmd.printList(nil, nil, "No sync paths are running\n")
+		return
+	}
+
+	headerColumnNames := []string{
+		"Pod",
+		"Local Path",
+		"Container Path",
+		"Queued (Up/Down)",
+		"Transferred (Up/Down)",
+		"Errors",
+		"Last Sync",
+	}
+
+	values := make([][]string, 0, len(metrics))
+	for _, m := range metrics {
+		lastSync := "never"
+		if m.LastSync.IsZero() == false {
+			lastSync = m.LastSync.Format(time.RFC3339)
+		}
+
+		values = append(values, []string{
+			m.Pod,
+			m.LocalPath,
+			m.ContainerPath,
+			strconv.Itoa(m.UploadsQueued) + "/" + strconv.Itoa(m.DownloadsQueued),
+			strconv.Itoa(m.Uploaded) + "/" + strconv.Itoa(m.Downloaded),
+			strconv.Itoa(m.Errors),
+			lastSync,
+		})
+	}
+
+	cmd.printList(headerColumnNames, values, "")
 }
 
 // RunListPort runs the list port command logic
@@ -271,7 +520,7 @@ func (cmd *ListCmd) RunListPort(cobraCmd *cobra.Command, args []string) {
 	config := configutil.GetConfig()
 
 	if config.DevSpace.Ports == nil || len(*config.DevSpace.Ports) == 0 {
-		log.Info("No ports are forwarded. Run `devspace add port` to add a port that should be forwarded\n")
+		cmd.printList(nil, nil, "No ports are forwarded. Run `devspace add port` to add a port that should be forwarded\n")
 		return
 	}
 
@@ -289,31 +538,40 @@ func (cmd *ListCmd) RunListPort(cobraCmd *cobra.Command, args []string) {
 		service := ""
 		selector := ""
 
-		if value.Service != nil {
+		if value.ResourceName != nil && *value.ResourceName != "" && value.ResourceType != nil {
+			selector = *value.ResourceType + "/" + *value.ResourceName
+		} else if value.Service != nil {
 			service = *value.Service
-		} else {
-			for k, v := range *value.LabelSelector {
-				if len(selector) > 0 {
-					selector += ", "
-				}
-
-				selector += k + "=" + *v
-			}
+		} else if value.PodName != nil && *value.PodName != "" {
+			selector = "pod/" + *value.PodName
+		} else if value.LabelSelector != nil {
+			selector = kubectl.LabelSelectorToString(*value.LabelSelector)
 		}
 
 		portMappings := ""
-		for _, v := range *value.PortMappings {
-			if len(portMappings) > 0 {
-				portMappings += ", "
-			}
+		if value.PortMappings != nil {
+			for _, v := range *value.PortMappings {
+				if len(portMappings) > 0 {
+					portMappings += ", "
+				}
+
+				// A free local port is chosen for local ports that are 0 or omitted
+				localPort := "auto"
+				if v.LocalPort != nil && *v.LocalPort != 0 {
+					localPort = strconv.Itoa(*v.LocalPort)
+				}
 
-			portMappings += strconv.Itoa(*v.LocalPort) + ":" + strconv.Itoa(*v.RemotePort)
+				portMappings += localPort + ":" + getPortValue(v.RemotePort)
+			}
 		}
 
 		resourceType := "pod"
 		if value.ResourceType != nil {
 			resourceType = *value.ResourceType
 		}
+		if value.Reverse != nil && *value.Reverse {
+			resourceType += " (reverse)"
+		}
 
 		portForwards = append(portForwards, []string{
 			service,
@@ -323,5 +581,259 @@ func (cmd *ListCmd) RunListPort(cobraCmd *cobra.Command, args []string) {
 		})
 	}
 
-	log.PrintTable(headerColumnNames, portForwards)
+	cmd.printList(headerColumnNames, portForwards, "")
+}
+
+// RunListNamespaces runs the list namespaces command logic
+func (cmd *ListCmd) RunListNamespaces(cobraCmd *cobra.Command, args []string) {
+	client, err := newKubectlClient(false)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	namespaces, err := client.CoreV1().Namespaces().List(metav1.ListOptions{
+		LabelSelector: kubectl.CreatedByLabel + "=" + kubectl.CreatedByLabelValue,
+	})
+	if err != nil {
+		log.Fatalf("Unable to list namespaces: %v", err)
+	}
+
+	usedBy := getConfiguredNamespaces()
+
+	headerColumnNames := []string{
+		"Name",
+		"Created",
+		"Status",
+		"Used By",
+	}
+
+	values := make([][]string, 0, len(namespaces.Items))
+	for _, namespace := range namespaces.Items {
+		values = append(values, []string{
+			namespace.Name,
+			namespace.CreationTimestamp.Format(time.RFC3339),
+			string(namespace.Status.Phase),
+			strings.Join(usedBy[namespace.Name], ", "),
+		})
+	}
+
+	cmd.printList(headerColumnNames, values, "No namespaces created by devspace found\n")
+}
+
+// RunListReleases runs the list releases command logic
+func (cmd *ListCmd) RunListReleases(cobraCmd *cobra.Command, args []string) {
+	client, err := newKubectlClient(false)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	// Don't install tiller only to list the releases
+	if helm.IsTillerDeployed(client) == false {
+		cmd.printList(nil, nil, "No releases found, because tiller is not deployed\n")
+		return
+	}
+
+	helmClient, err := newHelmClient(client, log.GetInstance(), false)
+	if err != nil {
+		log.Fatalf("Error initializing helm client: %v", err)
+	}
+	defer helmClient.Close()
+
+	releases, err := helmClient.ListReleases()
+	if err != nil {
+		log.Fatalf("Unable to list releases: %v", err)
+	}
+
+	namespaces := getDeploymentNamespaces()
+
+	headerColumnNames := []string{
+		"Name",
+		"Namespace",
+		"Chart",
+		"Status",
+		"Last Deployed",
+	}
+
+	values := make([][]string, 0, len(releases))
+	for _, release := range releases {
+		if cmd.flags.allNamespaces == false && namespaces[release.GetNamespace()] == false {
+			continue
+		}
+
+		chart := ""
+		if metadata := release.GetChart().GetMetadata(); metadata != nil {
+			chart = metadata.GetName() + "-" + metadata.GetVersion()
+		}
+
+		lastDeployed := ""
+		if timestamp := release.GetInfo().GetLastDeployed(); timestamp != nil {
+			lastDeployed = time.Unix(timestamp.GetSeconds(), 0).Format(time.RFC3339)
+		}
+
+		values = append(values, []string{
+			release.GetName(),
+			release.GetNamespace(),
+			chart,
+			release.GetInfo().GetStatus().GetCode().String(),
+			lastDeployed,
+		})
+	}
+
+	cmd.printList(headerColumnNames, values, "No releases found\n")
+}
+
+// getDeploymentNamespaces returns the namespaces the configured deployments are deployed to
+func getDeploymentNamespaces() map[string]bool {
+	config := configutil.GetConfig()
+	namespaces := map[string]bool{}
+
+	defaultNamespace, err := configutil.GetDefaultNamespace(config)
+	if err != nil {
+		log.Fatalf("Unable to get default namespace: %v", err)
+	}
+
+	if config.DevSpace != nil && config.DevSpace.Deployments != nil {
+		for _, deployConfig := range *config.DevSpace.Deployments {
+			if deployConfig.Namespace != nil && *deployConfig.Namespace != "" {
+				namespaces[*deployConfig.Namespace] = true
+			} else {
+				namespaces[defaultNamespace] = true
+			}
+		}
+	}
+
+	return namespaces
+}
+
+// getConfiguredNamespaces returns the namespaces that are used by the config and what they are used for
+func getConfiguredNamespaces() map[string][]string {
+	config := configutil.GetConfig()
+	usedBy := map[string][]string{}
+
+	defaultNamespace, err := configutil.GetDefaultNamespace(config)
+	if err == nil {
+		usedBy[defaultNamespace] = append(usedBy[defaultNamespace], "default")
+	}
+
+	if config.DevSpace != nil && config.DevSpace.Deployments != nil {
+		for _, deployConfig := range *config.DevSpace.Deployments {
+			if deployConfig.Namespace != nil && *deployConfig.Namespace != "" {
+				usedBy[*deployConfig.Namespace] = append(usedBy[*deployConfig.Namespace], "deployment "+*deployConfig.Name)
+			}
+		}
+	}
+	if config.Tiller != nil && config.Tiller.Namespace != nil {
+		usedBy[*config.Tiller.Namespace] = append(usedBy[*config.Tiller.Namespace], "tiller")
+	}
+	if config.InternalRegistry != nil && config.InternalRegistry.Namespace != nil {
+		usedBy[*config.InternalRegistry.Namespace] = append(usedBy[*config.InternalRegistry.Namespace], "internal registry")
+	}
+
+	return usedBy
+}
+
+// RunListPreviews runs the list previews command logic
+func (cmd *ListCmd) RunListPreviews(cobraCmd *cobra.Command, args []string) {
+	client, err := newKubectlClient(false)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	previews, err := preview.List(client, *configutil.GetConfig().Tiller.Namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	slugs := make([]string, 0, len(previews))
+	for slug := range previews {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	headerColumnNames := []string{
+		"Branch",
+		"Name",
+		"Namespace",
+		"Releases",
+		"Deployed",
+		"Expires",
+	}
+
+	now := time.Now()
+	values := make([][]string, 0, len(previews))
+	for _, slug := range slugs {
+		previewEnv := previews[slug]
+		expires := previewEnv.ExpiresAt
+		if previewEnv.IsExpired(now) {
+			expires += " (expired)"
+		}
+
+		values = append(values, []string{
+			previewEnv.Branch,
+			previewEnv.Slug,
+			previewEnv.Namespace,
+			strings.Join(previewEnv.Releases, ", "),
+			previewEnv.DeployedAt,
+			expires,
+		})
+	}
+
+	cmd.printList(headerColumnNames, values, "No previews found. Deploy one with `devspace deploy --preview`\n")
+}
+
+// printList prints the values in the output format of the --output flag. If there are no values and the output
+// format is table, emptyMessage is printed instead of an empty table
+func (cmd *ListCmd) printList(headerColumnNames []string, values [][]string, emptyMessage string) {
+	format := cmd.getOutputFormat()
+
+	formatter, err := output.NewFormatter(format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(values) == 0 && emptyMessage != "" && format == output.FormatTable {
+		log.Info(emptyMessage)
+		return
+	}
+	if values == nil {
+		values = [][]string{}
+	}
+
+	err = formatter.Print(values, headerColumnNames)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// getOutputFormat returns the output format of the --output, --json and --yaml flags
+func (cmd *ListCmd) getOutputFormat() string {
+	if cmd.flags.json && cmd.flags.yaml {
+		log.Fatal("Please specify either --json or --yaml")
+	}
+
+	if cmd.flags.json {
+		return output.FormatJSON
+	} else if cmd.flags.yaml {
+		return output.FormatYAML
+	}
+
+	return cmd.flags.output
+}
+
+// getStringValue returns the value of a string pointer or an empty string if it is nil
+func getStringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
+
+// getPortValue returns the port as string or ? if it is not set
+func getPortValue(port *int) string {
+	if port == nil {
+		return "?"
+	}
+
+	return strconv.Itoa(*port)
 }
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/services"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/spf13/cobra"
+)
+
+// LogsCmd holds the information needed for the logs command
+type LogsCmd struct {
+	flags *LogsCmdFlags
+}
+
+// LogsCmdFlags holds the possible flags for the logs command
+type LogsCmdFlags struct {
+	all             bool
+	labelSelector   string
+	namespace       string
+	maxLineWidth    int
+	switchContext   bool
+	config          string
+	configOverwrite string
+}
+
+func init() {
+	cmd := &LogsCmd{
+		flags: &LogsCmdFlags{},
+	}
+
+	cobraCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Streams the logs of your DevSpace",
+		Long: `
+#######################################################
+################### devspace logs #####################
+#######################################################
+Streams the logs of all containers of the selected pods.
+New pods are picked up automatically until you press
+Ctrl+C:
+
+devspace logs --all
+devspace logs -l app=api -n my-namespace
+devspace logs --all --max-line-width=120
+#######################################################`,
+		Args: cobra.NoArgs,
+		Run:  cmd.Run,
+	}
+	rootCmd.AddCommand(cobraCmd)
+
+	cobraCmd.Flags().BoolVar(&cmd.flags.all, "all", false, "Stream the logs of all pods of all deployments")
+	cobraCmd.Flags().StringVarP(&cmd.flags.labelSelector, "label-selector", "l", "", "Comma separated key=value selector list (e.g. release=test)")
+	cobraCmd.Flags().StringVarP(&cmd.flags.namespace, "namespace", "n", "", "Namespace where to select pods")
+	cobraCmd.Flags().IntVar(&cmd.flags.maxLineWidth, "max-line-width", services.DefaultMaxLogLineWidth, "Truncate log lines after this amount of characters (0 disables truncation)")
+	cobraCmd.Flags().BoolVar(&cmd.flags.switchContext, "switch-context", false, "Switch kubectl context to the devspace context")
+	cobraCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	cobraCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+}
+
+// Run executes the logs command logic
+func (cmd *LogsCmd) Run(cobraCmd *cobra.Command, args []string) {
+	if configutil.ConfigPath != cmd.flags.config {
+		configutil.ConfigPath = cmd.flags.config
+
+		// Don't use overwrite config if we use a different config
+		configutil.OverwriteConfigPath = ""
+	}
+	if configutil.OverwriteConfigPath != cmd.flags.configOverwrite {
+		configutil.OverwriteConfigPath = cmd.flags.configOverwrite
+	}
+
+	log.StartFileLogging()
+
+	client, err := newKubectlClient(cmd.flags.switchContext)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	var selectors []*services.LogSelector
+	if cmd.flags.all {
+		selectors = services.GetReleaseLogSelectors()
+	} else {
+		labelSelector := cmd.flags.labelSelector
+		if labelSelector == "" {
+			labelSelector = "release=" + services.GetNameOfFirstHelmDeployment()
+		}
+
+		selectors = []*services.LogSelector{
+			{
+				LabelSelector: labelSelector,
+				Namespace:     cmd.flags.namespace,
+			},
+		}
+	}
+
+	if len(selectors) == 0 {
+		log.Fatal("No deployments found to stream logs from")
+	}
+
+	streamer, err := services.StartLogs(client, selectors, cmd.flags.maxLineWidth, log.GetInstance())
+	if err != nil {
+		log.Fatalf("Unable to start log streaming: %v", err)
+	}
+
+	// Stream until the user interrupts the command
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+
+	streamer.Stop()
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/devspace/configure"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/devspace/services"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/spf13/cobra"
+)
+
+// PortForwardCmd holds the information needed for the port-forward command
+type PortForwardCmd struct {
+	flags *PortForwardCmdFlags
+}
+
+// PortForwardCmdFlags holds the possible flags for the port-forward command
+type PortForwardCmdFlags struct {
+	mapping         []string
+	maxPortForwards int
+	timeout         time.Duration
+	autoPort        bool
+	switchContext   bool
+	config          string
+	configOverwrite string
+}
+
+func init() {
+	cmd := &PortForwardCmd{
+		flags: &PortForwardCmdFlags{},
+	}
+
+	cobraCmd := &cobra.Command{
+		Use:   "port-forward",
+		Short: "Manages the port forwarding of the devspace",
+		Long: `
+#######################################################
+################ devspace port-forward ################
+#######################################################
+Starts the port forwarding of the devspace without
+devspace up:
+
+devspace port-forward start
+#######################################################`,
+		Args: cobra.NoArgs,
+	}
+	rootCmd.AddCommand(cobraCmd)
+
+	startCmd := &cobra.Command{
+		Use:   "start",
+		Short: "Starts the configured port forwardings without devspace up",
+		Long: `
+#######################################################
+############# devspace port-forward start #############
+#######################################################
+Starts all port forwardings of the devspace config
+without building or deploying anything until you press
+Ctrl+C. With --mapping only the given ports are
+forwarded to the pods of the first configured port
+forwarding and config.yaml is not changed:
+
+devspace port-forward start
+devspace port-forward start --mapping=8080:80,9229
+#######################################################`,
+		Args: cobra.NoArgs,
+		Run:  cmd.RunStart,
+	}
+	cobraCmd.AddCommand(startCmd)
+
+	startCmd.Flags().StringSliceVar(&cmd.flags.mapping, "mapping", []string{}, "Comma separated list of local:remote port mappings to forward instead of the configured ports (e.g. 8080:80,9229)")
+	startCmd.Flags().IntVar(&cmd.flags.maxPortForwards, "max-port-forwards", services.DefaultMaxConcurrentPortForwards, "Maximum number of port forwardings that are established at the same time")
+	startCmd.Flags().DurationVar(&cmd.flags.timeout, "timeout", services.DefaultPortForwardingTimeout, "Time to wait for a port forwarding to get ready before continuing without it")
+	startCmd.Flags().BoolVar(&cmd.flags.autoPort, "auto-port", false, "Uses a free local port for port forwardings whose local port is already in use")
+	startCmd.Flags().BoolVar(&cmd.flags.switchContext, "switch-context", false, "Switch kubectl context to the devspace context")
+	startCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	startCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+}
+
+// RunStart executes the devspace port-forward start command logic
+func (cmd *PortForwardCmd) RunStart(cobraCmd *cobra.Command, args []string) {
+	if configutil.ConfigPath != cmd.flags.config {
+		configutil.ConfigPath = cmd.flags.config
+
+		// Don't use overwrite config if we use a different config
+		configutil.OverwriteConfigPath = ""
+	}
+	if configutil.OverwriteConfigPath != cmd.flags.configOverwrite {
+		configutil.OverwriteConfigPath = cmd.flags.configOverwrite
+	}
+
+	configExists, _ := configutil.ConfigExists()
+	if configExists == false {
+		log.Fatal("Couldn't find a devspace config. Run `devspace init` first or use `devspace forward` to forward ports without a config")
+	}
+
+	log.StartFileLogging()
+
+	config := configutil.GetConfig()
+	if len(cmd.flags.mapping) > 0 {
+		portMappings, err := configure.ParsePortMappings(strings.Join(cmd.flags.mapping, ","))
+		if err != nil {
+			log.Fatalf("Error parsing --mapping: %v", err)
+		}
+
+		// The ad-hoc port forwarding is only kept in memory, the config is never saved by this command
+		config.DevSpace.Ports = &[]*v1.PortForwardingConfig{
+			services.GetMappingPortForwarding(portMappings),
+		}
+	} else if config.DevSpace.Ports == nil || len(*config.DevSpace.Ports) == 0 {
+		log.Fatal("No port forwardings configured. Add one with `devspace add port` or use --mapping")
+	}
+
+	client, err := newKubectlClient(cmd.flags.switchContext)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	err = services.StartPortForwarding(client, kubectl.NewPodCache(client), cmd.flags.maxPortForwards, cmd.flags.timeout, cmd.flags.autoPort, log.GetInstance())
+	if err != nil {
+		services.StopPortForwarding()
+		services.RemovePortForwardingPid()
+		log.Fatalf("Unable to start port forwarding: %v", err)
+	}
+
+	// Forward until the user interrupts the command
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+
+	services.StopPortForwarding()
+	services.RemovePortForwardingPid()
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/preview"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/spf13/cobra"
+)
+
+// PurgeCmd holds the required data for the purge cmd
+type PurgeCmd struct {
+	flags *PurgeCmdFlags
+}
+
+// PurgeCmdFlags holds the possible purge cmd flags
+type PurgeCmdFlags struct {
+	preview         string
+	expiredPreviews bool
+	switchContext   bool
+	config          string
+	configOverwrite string
+}
+
+func init() {
+	cmd := &PurgeCmd{
+		flags: &PurgeCmdFlags{},
+	}
+
+	cobraCmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Deletes preview environments",
+		Long: `
+#######################################################
+################### devspace purge ####################
+#######################################################
+Deletes the releases and the namespace of a preview
+environment that was deployed with devspace deploy
+--preview. Previews whose devspace.preview.ttl has
+elapsed are deleted with --expired-previews:
+
+devspace purge --preview feature/login
+devspace purge --expired-previews
+#######################################################`,
+		Args: cobra.NoArgs,
+		Run:  cmd.Run,
+	}
+
+	cobraCmd.Flags().StringVar(&cmd.flags.preview, "preview", "", "The git branch (or name) of the preview to delete")
+	cobraCmd.Flags().BoolVar(&cmd.flags.expiredPreviews, "expired-previews", false, "Deletes all previews whose ttl has elapsed")
+	cobraCmd.Flags().BoolVar(&cmd.flags.switchContext, "switch-context", false, "Switch kubectl context to the devspace context")
+	cobraCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	cobraCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+
+	rootCmd.AddCommand(cobraCmd)
+}
+
+// Run executes the purge command logic
+func (cmd *PurgeCmd) Run(cobraCmd *cobra.Command, args []string) {
+	if configutil.ConfigPath != cmd.flags.config {
+		configutil.ConfigPath = cmd.flags.config
+
+		// Don't use overwrite config if we use a different config
+		configutil.OverwriteConfigPath = ""
+	}
+	if configutil.OverwriteConfigPath != cmd.flags.configOverwrite {
+		configutil.OverwriteConfigPath = cmd.flags.configOverwrite
+	}
+
+	if cmd.flags.preview == "" && cmd.flags.expiredPreviews == false {
+		log.Fatal("Please specify the preview to delete with --preview or use --expired-previews")
+	}
+
+	log.StartFileLogging()
+
+	client, err := newKubectlClient(cmd.flags.switchContext)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	tillerNamespace := *configutil.GetConfig().Tiller.Namespace
+	previews, err := preview.List(client, tillerNamespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	purgePreviews := []*preview.Preview{}
+	if cmd.flags.preview != "" {
+		previewEnv := preview.Find(previews, cmd.flags.preview)
+		if previewEnv == nil {
+			log.Fatalf("No preview of branch %s found. Run `devspace list previews` to show all previews", cmd.flags.preview)
+		}
+
+		purgePreviews = append(purgePreviews, previewEnv)
+	} else {
+		purgePreviews = preview.GetExpired(previews, time.Now())
+		if len(purgePreviews) == 0 {
+			log.Info("No expired previews found")
+			return
+		}
+	}
+
+	for _, previewEnv := range purgePreviews {
+		log.StartWait("Deleting preview of branch " + previewEnv.Branch)
+		err = preview.Purge(client, tillerNamespace, previewEnv, log.GetInstance())
+		log.StopWait()
+		if err != nil {
+			log.Fatalf("Error deleting preview of branch %s: %v", previewEnv.Branch, err)
+		}
+
+		log.Donef("Successfully deleted preview of branch %s", previewEnv.Branch)
+	}
+}
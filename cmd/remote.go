@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/devspace/services"
+	"github.com/covexo/devspace/pkg/util/log"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// devWorkloadLabel marks pods that are safe to sync into without confirmation
+const devWorkloadLabel = "devspace.covexo.com/dev"
+
+// initRemoteMode checks if the command runs without a devspace config. In that case an empty config is used,
+// so that only the flags and the kube config are taken into account and no .devspace folder is created
+func initRemoteMode(labelSelector, podName string) bool {
+	configExists, err := configutil.ConfigExists()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if configExists {
+		return false
+	}
+
+	if labelSelector == "" && podName == "" {
+		log.Fatal("No devspace config found. Please select a pod with --pod or --label-selector or run `devspace init`")
+	}
+
+	configutil.InitConfig()
+
+	// Write the log files (e.g. of the port forwarding) to the temp dir instead of .devspace/logs
+	log.Logdir = filepath.Join(os.TempDir(), "devspace", "logs") + string(filepath.Separator)
+	return true
+}
+
+// getRemoteSelector returns the label selector, namespace and container name that select the pod of the sync and
+// forward commands. A service of the config is replaced by its label selector, namespace and container name, the
+// namespace and container flags take precedence over the ones of the service. Without pod, service and label
+// selector the pods of the first helm deployment are selected
+func getRemoteSelector(serviceName, labelSelector, podName, namespace, containerName string) (string, string, string, error) {
+	if serviceName == "" {
+		if labelSelector == "" && podName == "" {
+			labelSelector = "release=" + services.GetNameOfFirstHelmDeployment()
+		}
+
+		return labelSelector, namespace, containerName, nil
+	}
+
+	if labelSelector != "" || podName != "" {
+		return "", "", "", errors.New("--service cannot be used together with --label-selector or --pod")
+	}
+
+	service, err := configutil.GetService(serviceName)
+	if err != nil {
+		return "", "", "", fmt.Errorf("Error resolving service name: %v", err)
+	}
+	if service.LabelSelector == nil || len(*service.LabelSelector) == 0 {
+		return "", "", "", fmt.Errorf("Service %s has no labelSelector", serviceName)
+	}
+
+	if namespace == "" && service.Namespace != nil {
+		namespace = *service.Namespace
+	}
+	if containerName == "" && service.ContainerName != nil {
+		containerName = *service.ContainerName
+	}
+
+	return kubectl.LabelSelectorToString(*service.LabelSelector), namespace, containerName, nil
+}
+
+// getRemotePod returns the pod with the given name or the newest running pod that matches the label selector
+func getRemotePod(client kubernetes.Interface, podName, labelSelector, namespace string) (*k8sv1.Pod, error) {
+	if podName != "" {
+		return kubectl.GetRunningPodByName(client, podName, namespace)
+	}
+
+	log.StartWait("Waiting for pods to become running")
+	defer log.StopWait()
+
+	return kubectl.GetNewestRunningPod(client, labelSelector, namespace)
+}
+
+// isDevWorkload checks if the pod is labeled as development workload
+func isDevWorkload(pod *k8sv1.Pod) bool {
+	return pod.Labels[devWorkloadLabel] == "true"
+}
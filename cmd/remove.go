@@ -16,6 +16,7 @@ type RemoveCmd struct {
 
 type removeSyncCmdFlags struct {
 	Selector      string
+	Service       string
 	LocalPath     string
 	ContainerPath string
 	RemoveAll     bool
@@ -23,6 +24,7 @@ type removeSyncCmdFlags struct {
 
 type removePortCmdFlags struct {
 	Selector  string
+	Service   string
 	RemoveAll bool
 }
 
@@ -71,12 +73,14 @@ func init() {
 	#######################################################
 	############### devspace remove sync ##################
 	#######################################################
-	Remove sync paths from the devspace
+	Remove sync paths from the devspace. Run devspace list
+	sync to show the configured sync paths
 
 	How to use:
 	devspace remove sync --local=app
 	devspace remove sync --container=/app
 	devspace remove sync --selector=release=test
+	devspace remove sync --service=default
 	devspace remove sync --all
 	#######################################################
 	`,
@@ -87,6 +91,7 @@ func init() {
 	removeCmd.AddCommand(removeSyncCmd)
 
 	removeSyncCmd.Flags().StringVar(&cmd.syncFlags.Selector, "selector", "", "Comma separated key=value selector list (e.g. release=test)")
+	removeSyncCmd.Flags().StringVar(&cmd.syncFlags.Service, "service", "", "Remove the sync paths that use this service")
 	removeSyncCmd.Flags().StringVar(&cmd.syncFlags.LocalPath, "local", "", "Relative local path to remove")
 	removeSyncCmd.Flags().StringVar(&cmd.syncFlags.ContainerPath, "container", "", "Absolute container path to remove")
 	removeSyncCmd.Flags().BoolVar(&cmd.syncFlags.RemoveAll, "all", false, "Remove all configured sync paths")
@@ -98,9 +103,11 @@ func init() {
 	#######################################################
 	############### devspace remove port ##################
 	#######################################################
-	Removes port mappings from the devspace configuration:
+	Removes port mappings from the devspace configuration.
+	Run devspace list port to show the configured ports:
 	devspace remove port 8080,3000
 	devspace remove port --selector=release=test
+	devspace remove port 8080 --service=default
 	devspace remove port --all
 	#######################################################
 	`,
@@ -109,6 +116,7 @@ func init() {
 	}
 
 	removePortCmd.Flags().StringVar(&cmd.portFlags.Selector, "selector", "", "Comma separated key=value selector list (e.g. release=test)")
+	removePortCmd.Flags().StringVar(&cmd.portFlags.Service, "service", "", "Remove the ports of the port forwardings that use this service")
 	removePortCmd.Flags().BoolVar(&cmd.portFlags.RemoveAll, "all", false, "Remove all configured ports")
 
 	removeCmd.AddCommand(removePortCmd)
@@ -175,7 +183,7 @@ func (cmd *RemoveCmd) RunRemovePackage(cobraCmd *cobra.Command, args []string) {
 
 // RunRemoveSync executes the remove sync command logic
 func (cmd *RemoveCmd) RunRemoveSync(cobraCmd *cobra.Command, args []string) {
-	err := configure.RemoveSyncPath(cmd.syncFlags.RemoveAll, cmd.syncFlags.LocalPath, cmd.syncFlags.ContainerPath, cmd.syncFlags.Selector)
+	err := configure.RemoveSyncPath(cmd.syncFlags.RemoveAll, cmd.syncFlags.LocalPath, cmd.syncFlags.ContainerPath, cmd.syncFlags.Selector, cmd.syncFlags.Service)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -183,7 +191,7 @@ func (cmd *RemoveCmd) RunRemoveSync(cobraCmd *cobra.Command, args []string) {
 
 // RunRemovePort executes the remove port command logic
 func (cmd *RemoveCmd) RunRemovePort(cobraCmd *cobra.Command, args []string) {
-	err := configure.RemovePort(cmd.portFlags.RemoveAll, cmd.portFlags.Selector, args)
+	err := configure.RemovePort(cmd.portFlags.RemoveAll, cmd.portFlags.Selector, cmd.portFlags.Service, args)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -21,13 +21,21 @@ import (
 // ResetCmd holds the needed command information
 type ResetCmd struct {
 	flags   *ResetCmdFlags
-	kubectl *kubernetes.Clientset
+	kubectl kubernetes.Interface
 }
 
 // ResetCmdFlags holds the possible reset cmd flags
 type ResetCmdFlags struct {
 	config          string
 	configOverwrite string
+
+	force      bool
+	keepChart  bool
+	keepConfig bool
+
+	namespace     string
+	selector      string
+	switchContext bool
 }
 
 func init() {
@@ -52,6 +60,11 @@ Use the flag --all-data to also remove:
 1. Tiller server (if deployed)
 2. Helm home (if helm is used)
 
+Before anything is deleted, you have to confirm the 
+reset. Use --force to skip all questions (e.g. in CI) 
+and --keep-chart or --keep-config to keep the chart 
+or the .devspace folder.
+
 If you simply want to shutdown your DevSpace, use the 
 command: devspace down
 #######################################################`,
@@ -61,8 +74,42 @@ command: devspace down
 
 	cobraCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
 	cobraCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+	cobraCmd.Flags().BoolVarP(&cmd.flags.force, "force", "f", false, "Skips all questions and removes everything that is not kept")
+	cobraCmd.Flags().BoolVar(&cmd.flags.keepChart, "keep-chart", false, "Keeps the chart of the deployments")
+	cobraCmd.Flags().BoolVar(&cmd.flags.keepConfig, "keep-config", false, "Keeps the .devspace folder with the config files")
 
 	rootCmd.AddCommand(cobraCmd)
+
+	resetNamespaceCmd := &cobra.Command{
+		Use:   "namespace",
+		Short: "Deletes all resources in the devspace namespace",
+		Long: `
+#######################################################
+############## devspace reset namespace ###############
+#######################################################
+Deletes the following resources in the namespace of
+your DevSpace without deleting the namespace itself:
+1. Deployments
+2. Services
+3. ConfigMaps
+4. Secrets
+5. PersistentVolumeClaims
+
+System resources (e.g. service account tokens and
+tiller) are kept. Use --selector to only delete the
+resources with matching labels:
+
+devspace reset namespace --selector=app=devspace
+#######################################################`,
+		Args: cobra.NoArgs,
+		Run:  cmd.RunResetNamespace,
+	}
+
+	resetNamespaceCmd.Flags().StringVarP(&cmd.flags.namespace, "namespace", "n", "", "The namespace to clean (default: the devspace namespace)")
+	resetNamespaceCmd.Flags().StringVarP(&cmd.flags.selector, "selector", "l", "", "Only delete resources that match this label selector (e.g. release=test)")
+	resetNamespaceCmd.Flags().BoolVar(&cmd.flags.switchContext, "switch-context", false, "Switch kubectl context to the devspace context")
+
+	cobraCmd.AddCommand(resetNamespaceCmd)
 }
 
 // Run executes the reset command logic
@@ -80,9 +127,13 @@ func (cmd *ResetCmd) Run(cobraCmd *cobra.Command, args []string) {
 	log.Infof("Loading config %s with overwrite config %s", configutil.ConfigPath, configutil.OverwriteConfigPath)
 	var err error
 
+	if cmd.confirmReset() == false {
+		return
+	}
+
 	// Create kubectl client
 	if cmd.kubectl == nil {
-		cmd.kubectl, err = kubectl.NewClient()
+		cmd.kubectl, err = newKubectlClient(false)
 		if err != nil {
 			log.Failf("Failed to initialize kubectl client: %v", err)
 		}
@@ -99,9 +150,54 @@ func (cmd *ResetCmd) Run(cobraCmd *cobra.Command, args []string) {
 		cmd.deleteClusterRoleBinding()
 	}
 
-	cmd.deleteDeploymentFiles()
+	if cmd.flags.keepChart == false {
+		cmd.deleteDeploymentFiles()
+	}
+
 	cmd.deleteImageFiles()
-	cmd.deleteDevspaceFolder()
+
+	if cmd.flags.keepConfig == false {
+		cmd.deleteDevspaceFolder()
+	}
+}
+
+// confirmReset lists what will be removed and asks the user once before anything is deleted
+func (cmd *ResetCmd) confirmReset() bool {
+	if cmd.flags.force {
+		return true
+	}
+
+	removed := []string{"DevSpace deployments", "Dockerfiles and .dockerignore files"}
+	if cmd.flags.keepChart == false {
+		removed = append(removed, "charts of the deployments")
+	}
+	if cmd.flags.keepConfig == false {
+		removed = append(removed, ".devspace folder (config files)")
+	}
+
+	log.Info("devspace reset will remove the following data (you will be asked for each of them):")
+	for _, item := range removed {
+		log.Info("- " + item)
+	}
+
+	return *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
+		Question:               "\n\nDo you really want to reset your project? (y/n)",
+		DefaultValue:           "n",
+		ValidationRegexPattern: "^(y|n)$",
+	}) == "y"
+}
+
+// shouldRemove asks the question unless --force is set
+func (cmd *ResetCmd) shouldRemove(question string) bool {
+	if cmd.flags.force {
+		return true
+	}
+
+	return *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
+		Question:               question,
+		DefaultValue:           "y",
+		ValidationRegexPattern: "^(y|n)$",
+	}) == "y"
 }
 
 func (cmd *ResetCmd) deleteCloudDevSpace() {
@@ -112,11 +208,7 @@ func (cmd *ResetCmd) deleteCloudDevSpace() {
 		return
 	}
 
-	shouldCloudDevSpaceRemoved := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
-		Question:               "\n\nShould this DevSpace be deleted from DevSpace Cloud (y/n)",
-		DefaultValue:           "y",
-		ValidationRegexPattern: "^(y|n)$",
-	}) == "y"
+	shouldCloudDevSpaceRemoved := cmd.shouldRemove("\n\nShould this DevSpace be deleted from DevSpace Cloud (y/n)")
 
 	if shouldCloudDevSpaceRemoved {
 		// Get selected cloud provider from config
@@ -142,11 +234,7 @@ func (cmd *ResetCmd) deleteCloudDevSpace() {
 
 func (cmd *ResetCmd) deleteCloudKubeContext() {
 	config := configutil.GetConfig()
-	shouldCloudContextRemoved := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
-		Question:               "\n\nShould the cloud kube context be removed (y/n)",
-		DefaultValue:           "y",
-		ValidationRegexPattern: "^(y|n)$",
-	}) == "y"
+	shouldCloudContextRemoved := cmd.shouldRemove("\n\nShould the cloud kube context be removed (y/n)")
 
 	if shouldCloudContextRemoved {
 		err := cloud.DeleteKubeContext(*config.Cluster.Namespace)
@@ -159,18 +247,14 @@ func (cmd *ResetCmd) deleteCloudKubeContext() {
 }
 
 func (cmd *ResetCmd) deleteDevSpaceDeployments() {
-	deleteDevSpace(cmd.kubectl)
+	deleteDevSpace(cmd.kubectl, true)
 }
 
 func (cmd *ResetCmd) deleteInternalRegistry() {
 	config := configutil.GetConfig()
 
 	if config.InternalRegistry != nil {
-		shouldRegistryRemoved := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
-			Question:               "\n\nShould the internal registry be removed? (y/n)",
-			DefaultValue:           "y",
-			ValidationRegexPattern: "^(y|n)$",
-		}) == "y"
+		shouldRegistryRemoved := cmd.shouldRemove("\n\nShould the internal registry be removed? (y/n)")
 
 		if shouldRegistryRemoved {
 			isDeployed := helmClient.IsTillerDeployed(cmd.kubectl)
@@ -182,6 +266,7 @@ func (cmd *ResetCmd) deleteInternalRegistry() {
 			if err != nil {
 				log.Fatalf("Error creating helm client: %v", err)
 			}
+			defer helm.Close()
 
 			_, err = helm.DeleteRelease(registry.InternalRegistryName, true)
 			if err != nil {
@@ -196,12 +281,9 @@ func (cmd *ResetCmd) deleteInternalRegistry() {
 func (cmd *ResetCmd) deleteTiller() {
 	config := configutil.GetConfig()
 
-	if config.Tiller != nil {
-		shouldRemoveTiller := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
-			Question:               "\n\nShould the tiller server be removed? (y/n)",
-			DefaultValue:           "y",
-			ValidationRegexPattern: "^(y|n)$",
-		}) == "y"
+	// Without tiller there is no tiller server to remove
+	if config.Tiller != nil && helmClient.IsTillerless() == false {
+		shouldRemoveTiller := cmd.shouldRemove("\n\nShould the tiller server be removed? (y/n)")
 
 		if shouldRemoveTiller {
 			log.StartWait("Deleting tiller")
@@ -228,11 +310,7 @@ func (cmd *ResetCmd) deleteDeploymentFiles() {
 				if err == nil {
 					_, err := os.Stat(absChartPath)
 					if os.IsNotExist(err) == false {
-						deleteChart := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
-							Question:               "\n\nShould the Chart (" + *deployConfig.Helm.ChartPath + "/*) be removed? (y/n)",
-							DefaultValue:           "y",
-							ValidationRegexPattern: "^(y|n)$",
-						}) == "y"
+						deleteChart := cmd.shouldRemove("\n\nShould the Chart (" + *deployConfig.Helm.ChartPath + "/*) be removed? (y/n)")
 
 						if deleteChart {
 							os.RemoveAll(absChartPath)
@@ -261,11 +339,7 @@ func (cmd *ResetCmd) deleteImageFiles() {
 
 		_, err = os.Stat(absDockerfilePath)
 		if os.IsNotExist(err) == false {
-			deleteDockerfile := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
-				Question:               "\n\nShould " + dockerfilePath + " be removed? (y/n)",
-				DefaultValue:           "y",
-				ValidationRegexPattern: "^(y|n)$",
-			}) == "y"
+			deleteDockerfile := cmd.shouldRemove("\n\nShould " + dockerfilePath + " be removed? (y/n)")
 
 			if deleteDockerfile {
 				os.Remove(absDockerfilePath)
@@ -286,11 +360,7 @@ func (cmd *ResetCmd) deleteImageFiles() {
 		absDockerIgnorePath := filepath.Join(absContextPath, ".dockerignore")
 		_, err = os.Stat(absDockerIgnorePath)
 		if os.IsNotExist(err) == false {
-			deleteDockerIgnore := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
-				Question:               "\n\nShould " + absDockerIgnorePath + " be removed? (y/n)",
-				DefaultValue:           "y",
-				ValidationRegexPattern: "^(y|n)$",
-			}) == "y"
+			deleteDockerIgnore := cmd.shouldRemove("\n\nShould " + absDockerIgnorePath + " be removed? (y/n)")
 
 			if deleteDockerIgnore {
 				os.Remove(absDockerIgnorePath)
@@ -304,11 +374,7 @@ func (cmd *ResetCmd) deleteClusterRoleBinding() {
 	clusterRoleBindingName := kubectl.ClusterRoleBindingName
 	_, err := cmd.kubectl.RbacV1beta1().ClusterRoleBindings().Get(clusterRoleBindingName, metav1.GetOptions{})
 	if err == nil {
-		deleteRoleBinding := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
-			Question:               "\n\nShould the ClusterRoleBinding '" + clusterRoleBindingName + "' be removed? (y/n)",
-			DefaultValue:           "y",
-			ValidationRegexPattern: "^(y|n)$",
-		}) == "y"
+		deleteRoleBinding := cmd.shouldRemove("\n\nShould the ClusterRoleBinding '" + clusterRoleBindingName + "' be removed? (y/n)")
 
 		if deleteRoleBinding {
 			log.StartWait("Deleting cluster role bindings")
@@ -325,11 +391,7 @@ func (cmd *ResetCmd) deleteClusterRoleBinding() {
 }
 
 func (cmd *ResetCmd) deleteDevspaceFolder() {
-	deleteDevspaceFolder := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
-		Question:               "\n\nShould the .devspace folder be removed? (y/n)",
-		DefaultValue:           "y",
-		ValidationRegexPattern: "^(y|n)$",
-	}) == "y"
+	deleteDevspaceFolder := cmd.shouldRemove("\n\nShould the .devspace folder be removed? (y/n)")
 
 	if deleteDevspaceFolder {
 		os.RemoveAll(".devspace")
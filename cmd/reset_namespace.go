@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/helm"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/stdinutil"
+	"github.com/spf13/cobra"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// namespaceResource is a resource that is deleted by devspace reset namespace
+type namespaceResource struct {
+	kind   string
+	name   string
+	delete func() error
+}
+
+// RunResetNamespace executes the devspace reset namespace command logic
+func (cmd *ResetCmd) RunResetNamespace(cobraCmd *cobra.Command, args []string) {
+	log.StartFileLogging()
+
+	client, err := newKubectlClient(cmd.flags.switchContext)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	namespace := cmd.flags.namespace
+	if namespace == "" {
+		namespace, err = configutil.GetDefaultNamespace(configutil.GetConfig())
+		if err != nil {
+			log.Fatalf("Error retrieving default namespace: %v", err)
+		}
+	}
+
+	if strings.HasPrefix(namespace, "kube-") {
+		log.Fatalf("Refusing to clean system namespace %s", namespace)
+	}
+
+	log.StartWait("Collecting resources in namespace " + namespace)
+	resources, err := collectNamespaceResources(client, namespace, cmd.flags.selector)
+	log.StopWait()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(resources) == 0 {
+		log.Infof("No resources to delete in namespace %s", namespace)
+		return
+	}
+
+	values := make([][]string, 0, len(resources))
+	for _, resource := range resources {
+		values = append(values, []string{resource.kind, resource.name})
+	}
+
+	log.PrintTable([]string{"Kind", "Name"}, values)
+
+	shouldDelete := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
+		Question:               "\n\nShould these " + strconv.Itoa(len(resources)) + " resources be deleted from namespace " + namespace + " (y/n)",
+		DefaultValue:           "n",
+		ValidationRegexPattern: "^(y|n)$",
+	}) == "y"
+	if shouldDelete == false {
+		return
+	}
+
+	deleted := 0
+	for _, resource := range resources {
+		err = resource.delete()
+		if err != nil {
+			log.Failf("Error deleting %s %s: %v", resource.kind, resource.name, err)
+			continue
+		}
+
+		deleted++
+	}
+
+	log.Donef("Deleted %d resources in namespace %s", deleted, namespace)
+}
+
+// collectNamespaceResources returns all non-system deployments, services, configmaps, secrets and persistent volume
+// claims in the namespace
+func collectNamespaceResources(client kubernetes.Interface, namespace, selector string) ([]*namespaceResource, error) {
+	propagationPolicy := metav1.DeletePropagationForeground
+	deleteOptions := &metav1.DeleteOptions{PropagationPolicy: &propagationPolicy}
+	listOptions := metav1.ListOptions{LabelSelector: selector}
+	resources := []*namespaceResource{}
+
+	deployments, err := client.ExtensionsV1beta1().Deployments(namespace).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, deployment := range deployments.Items {
+		name := deployment.Name
+		if name == helm.TillerDeploymentName {
+			continue
+		}
+
+		resources = append(resources, &namespaceResource{
+			kind: "Deployment",
+			name: name,
+			delete: func() error {
+				return client.ExtensionsV1beta1().Deployments(namespace).Delete(name, deleteOptions)
+			},
+		})
+	}
+
+	services, err := client.CoreV1().Services(namespace).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, service := range services.Items {
+		name := service.Name
+		if name == helm.TillerDeploymentName || (namespace == metav1.NamespaceDefault && name == "kubernetes") {
+			continue
+		}
+
+		resources = append(resources, &namespaceResource{
+			kind: "Service",
+			name: name,
+			delete: func() error {
+				return client.CoreV1().Services(namespace).Delete(name, deleteOptions)
+			},
+		})
+	}
+
+	configMaps, err := client.CoreV1().ConfigMaps(namespace).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, configMap := range configMaps.Items {
+		name := configMap.Name
+
+		// Keep the release information of tiller and the cluster ca
+		if configMap.Labels["OWNER"] == "TILLER" || name == "kube-root-ca.crt" {
+			continue
+		}
+
+		resources = append(resources, &namespaceResource{
+			kind: "ConfigMap",
+			name: name,
+			delete: func() error {
+				return client.CoreV1().ConfigMaps(namespace).Delete(name, deleteOptions)
+			},
+		})
+	}
+
+	secrets, err := client.CoreV1().Secrets(namespace).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, secret := range secrets.Items {
+		name := secret.Name
+		if secret.Type == k8sv1.SecretTypeServiceAccountToken {
+			continue
+		}
+
+		resources = append(resources, &namespaceResource{
+			kind: "Secret",
+			name: name,
+			delete: func() error {
+				return client.CoreV1().Secrets(namespace).Delete(name, deleteOptions)
+			},
+		})
+	}
+
+	claims, err := client.CoreV1().PersistentVolumeClaims(namespace).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, claim := range claims.Items {
+		name := claim.Name
+
+		resources = append(resources, &namespaceResource{
+			kind: "PersistentVolumeClaim",
+			name: name,
+			delete: func() error {
+				return client.CoreV1().PersistentVolumeClaims(namespace).Delete(name, deleteOptions)
+			},
+		})
+	}
+
+	return resources, nil
+}
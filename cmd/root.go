@@ -3,8 +3,11 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/covexo/devspace/pkg/devspace/helm"
 	"github.com/covexo/devspace/pkg/devspace/upgrade"
 	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/output"
+	"github.com/covexo/devspace/pkg/util/terminal"
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -27,22 +30,50 @@ var rootCmd = &cobra.Command{
 func Execute() {
 	if upgrade.GetVersion() != "" {
 		rootCmd.Version = upgrade.GetVersion()
-		newerVersion, err := upgrade.CheckForNewerVersion()
-
-		if err == nil && newerVersion != "" {
-			log.Warnf("There is a newer version of devspace cli v%s. Run `devspace upgrade` to update the cli.\n", newerVersion)
-		} else if err != nil {
-			log.Warnf("Couldn't check for newest version: %s\n", err.Error())
-		}
+		rootCmd.PersistentPreRun = checkForNewerVersion
 	}
 
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+
+	// Close the tunnels to tiller the command opened
+	helm.CloseClients()
+
+	if err != nil {
 		fmt.Println(err)
 	}
 }
 
+// checkForNewerVersion prints a warning if there is a newer version of the cli. The check runs after the flags were
+// parsed, so we can skip it for commands whose output must not be mixed with warnings
+func checkForNewerVersion(cobraCmd *cobra.Command, args []string) {
+	// Hidden commands (e.g. tunnel) run inside containers, where we don't check for updates
+	if cobraCmd.Hidden {
+		return
+	}
+
+	// There is no point in checking for updates without network access
+	if helm.Offline {
+		return
+	}
+
+	// Machine readable output (e.g. devspace list --output=json) has to stay parseable
+	if outputFlag := cobraCmd.Flags().Lookup("output"); outputFlag != nil && outputFlag.Value.String() != output.FormatTable {
+		return
+	}
+
+	newerVersion, err := upgrade.CheckForNewerVersion()
+	if err == nil && newerVersion != "" {
+		log.Warnf("There is a newer version of devspace cli v%s. Run `devspace upgrade` to update the cli.\n", newerVersion)
+	} else if err != nil {
+		log.Warnf("Couldn't check for newest version: %s\n", err.Error())
+	}
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
+
+	rootCmd.PersistentFlags().BoolVar(&terminal.DisableFancyPrompts, "no-fancy-prompts", false, "Disables colors and animations and shows plain numbered lists in prompts (e.g. for screen readers)")
+	rootCmd.PersistentFlags().BoolVar(&helm.Offline, "offline", false, "Continues with the cached indexes of chart repositories that can't be downloaded and skips the update check")
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/helm"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/spf13/cobra"
+)
+
+// ShowCmd holds the information needed for the show command
+type ShowCmd struct {
+	flags *ShowCmdFlags
+}
+
+// ShowCmdFlags holds the possible flags for the show command
+type ShowCmdFlags struct {
+	deployment string
+}
+
+// maxValueLength is the maximum length of a value that is printed by devspace show values
+const maxValueLength = 40
+
+func init() {
+	cmd := &ShowCmd{
+		flags: &ShowCmdFlags{},
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Shows information about the project",
+		Long: `
+	#######################################################
+	#################### devspace show ####################
+	#######################################################
+	Shows the following information:
+
+	* Chart values and the templates using them (values)
+	#######################################################
+	`,
+		Args: cobra.NoArgs,
+	}
+
+	rootCmd.AddCommand(showCmd)
+
+	showValuesCmd := &cobra.Command{
+		Use:   "values",
+		Short: "Shows the chart values and the templates using them",
+		Long: `
+	#######################################################
+	################ devspace show values #################
+	#######################################################
+	Shows every key of the values.yaml of the helm charts
+	and the templates that use it. Keys that are set by
+	devspace (containers.*, pullSecrets) are marked as
+	managed, keys that no template uses as unused.
+
+	The templates are analyzed statically, so only direct
+	references like .Values.a.b are found:
+
+	devspace show values
+	devspace show values --deployment=devspace-default
+	#######################################################
+	`,
+		Args: cobra.NoArgs,
+		Run:  cmd.RunShowValues,
+	}
+
+	showValuesCmd.Flags().StringVar(&cmd.flags.deployment, "deployment", "", "Only show the values of this deployment")
+
+	showCmd.AddCommand(showValuesCmd)
+}
+
+// RunShowValues executes the devspace show values command logic
+func (cmd *ShowCmd) RunShowValues(cobraCmd *cobra.Command, args []string) {
+	config := configutil.GetConfig()
+	if config.DevSpace.Deployments == nil || len(*config.DevSpace.Deployments) == 0 {
+		log.Info("No deployments are configured")
+		return
+	}
+
+	unconsumedImages := []string{}
+	if config.Images != nil {
+		for imageName := range *config.Images {
+			unconsumedImages = append(unconsumedImages, imageName)
+		}
+	}
+
+	found := false
+	analyzed := false
+	for _, deployConfig := range *config.DevSpace.Deployments {
+		if cmd.flags.deployment != "" && *deployConfig.Name != cmd.flags.deployment {
+			continue
+		}
+
+		found = true
+		if deployConfig.Helm == nil || deployConfig.Helm.ChartPath == nil {
+			log.Infof("Skipping deployment %s (only helm deployments have chart values)", *deployConfig.Name)
+			continue
+		}
+
+		chartPath, err := filepath.Abs(*deployConfig.Helm.ChartPath)
+		if err != nil {
+			log.Fatalf("Error retrieving absolute path from %s: %v", *deployConfig.Helm.ChartPath, err)
+		}
+
+		analysis, err := helm.AnalyzeValues(chartPath)
+		if err != nil {
+			log.Fatalf("Error analyzing values of deployment %s: %v", *deployConfig.Name, err)
+		}
+
+		log.Infof("Values of deployment %s (%s):", *deployConfig.Name, *deployConfig.Helm.ChartPath)
+		printValueUsages(analysis)
+
+		unknownReferences := make([]string, 0, len(analysis.UnknownReferences))
+		for reference := range analysis.UnknownReferences {
+			unknownReferences = append(unknownReferences, reference)
+		}
+		sort.Strings(unknownReferences)
+
+		for _, reference := range unknownReferences {
+			log.Warnf("%s is used in %s, but not defined in values.yaml", reference, strings.Join(analysis.UnknownReferences[reference], ", "))
+		}
+
+		// Images are only reported if none of the shown deployments uses them
+		unconsumedImages = analysis.ImagesWithoutConsumer(unconsumedImages)
+		analyzed = true
+	}
+
+	if found == false {
+		log.Fatalf("Deployment %s not found", cmd.flags.deployment)
+	}
+
+	if analyzed {
+		for _, imageName := range unconsumedImages {
+			log.Warnf("Image %s is not used by any template (containers.%s.image)", imageName, imageName)
+		}
+	}
+}
+
+func printValueUsages(analysis *helm.ValuesAnalysis) {
+	headerColumnNames := []string{
+		"Key",
+		"Value",
+		"Used In",
+		"Note",
+	}
+
+	values := make([][]string, 0, len(analysis.Usages))
+	for _, usage := range analysis.Usages {
+		note := ""
+		if usage.Managed {
+			note = "managed by devspace"
+		} else if usage.Subchart == "global" {
+			note = "passed to all subcharts"
+		} else if usage.Subchart != "" {
+			note = "passed to subchart " + usage.Subchart
+		} else if usage.Unused() {
+			note = "unused"
+		}
+
+		value := fmt.Sprintf("%v", usage.Value)
+		if len(value) > maxValueLength {
+			value = value[:maxValueLength-3] + "..."
+		}
+
+		values = append(values, []string{
+			usage.Key,
+			value,
+			strings.Join(usage.Templates, ", "),
+			note,
+		})
+	}
+
+	log.PrintTable(headerColumnNames, values)
+}
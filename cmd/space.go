@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"github.com/covexo/devspace/pkg/devspace/cloud"
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/stdinutil"
+	"github.com/spf13/cobra"
+)
+
+// SpaceCmd holds the information needed for the space command
+type SpaceCmd struct {
+	flags *SpaceCmdFlags
+}
+
+// SpaceCmdFlags holds the possible flags for the space command
+type SpaceCmdFlags struct {
+	provider string
+	force    bool
+}
+
+func init() {
+	cmd := &SpaceCmd{
+		flags: &SpaceCmdFlags{},
+	}
+
+	spaceCmd := &cobra.Command{
+		Use:   "space",
+		Short: "Manages your spaces in DevSpace Cloud",
+		Long: `
+#######################################################
+#################### devspace space ###################
+#######################################################
+Manages the spaces (DevSpaces) of a cloud provider:
+
+* Delete a space and its kube context (delete)
+#######################################################`,
+		Args: cobra.NoArgs,
+	}
+
+	rootCmd.AddCommand(spaceCmd)
+
+	spaceDeleteCmd := &cobra.Command{
+		Use:   "delete [name]",
+		Short: "Deletes a space from the cloud provider",
+		Long: `
+#######################################################
+################ devspace space delete ################
+#######################################################
+Deletes a space with all its targets from the cloud
+provider and removes its kube context from your kube
+config. This cannot be undone:
+
+devspace space delete my-space
+devspace space delete my-space --force
+#######################################################`,
+		Args: cobra.ExactArgs(1),
+		Run:  cmd.RunDelete,
+	}
+
+	spaceDeleteCmd.Flags().StringVar(&cmd.flags.provider, "provider", "", "The cloud provider of the space (default: the provider in the config or devspace-cloud)")
+	spaceDeleteCmd.Flags().BoolVarP(&cmd.flags.force, "force", "f", false, "Deletes the space without asking for confirmation")
+
+	spaceCmd.AddCommand(spaceDeleteCmd)
+}
+
+// RunDelete executes the devspace space delete command logic
+func (cmd *SpaceCmd) RunDelete(cobraCmd *cobra.Command, args []string) {
+	spaceName := args[0]
+
+	providerConfig, err := cloud.ParseCloudConfig()
+	if err != nil {
+		log.Fatalf("Error loading cloud config: %v", err)
+	}
+
+	providerName := cmd.getProviderName()
+	provider, ok := providerConfig[providerName]
+	if ok == false {
+		log.Fatalf("Config for cloud provider %s couldn't be found", providerName)
+	}
+	if provider.Token == "" {
+		log.Fatalf("You are not logged into %s. Please run `devspace up` with a config that uses this provider to login", providerName)
+	}
+
+	if cmd.flags.force == false {
+		shouldDelete := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
+			Question:               "\n\nDo you really want to delete the space " + spaceName + " from " + providerName + "? All data in the space will be lost (y/n)",
+			DefaultValue:           "n",
+			ValidationRegexPattern: "^(y|n)$",
+		}) == "y"
+		if shouldDelete == false {
+			return
+		}
+	}
+
+	// The cloud provider responds after the space was deleted
+	log.StartWait("Deleting space " + spaceName)
+	err = cloud.DeleteDevSpace(provider, spaceName)
+	log.StopWait()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Donef("Successfully deleted space %s", spaceName)
+
+	err = cloud.DeleteKubeContext(spaceName)
+	if err != nil {
+		log.Fatalf("Error deleting kube context: %v", err)
+	}
+
+	log.Donef("Successfully deleted kube context %s-%s", cloud.DevSpaceKubeContextName, spaceName)
+}
+
+// getProviderName returns the provider of the --provider flag, the provider in the config or devspace-cloud
+func (cmd *SpaceCmd) getProviderName() string {
+	if cmd.flags.provider != "" {
+		return cmd.flags.provider
+	}
+
+	configExists, _ := configutil.ConfigExists()
+	if configExists {
+		config := configutil.GetConfig()
+		if config.Cluster != nil && config.Cluster.CloudProvider != nil && *config.Cluster.CloudProvider != "" {
+			return *config.Cluster.CloudProvider
+		}
+	}
+
+	return cloud.DevSpaceCloudProviderName
+}
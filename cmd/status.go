@@ -5,25 +5,30 @@ import (
 	"fmt"
 
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/generated"
 	"github.com/covexo/devspace/pkg/devspace/deploy"
 	deployHelm "github.com/covexo/devspace/pkg/devspace/deploy/helm"
 	deployKubectl "github.com/covexo/devspace/pkg/devspace/deploy/kubectl"
 	helmClient "github.com/covexo/devspace/pkg/devspace/helm"
 	"github.com/covexo/devspace/pkg/devspace/kubectl"
 	"github.com/covexo/devspace/pkg/devspace/registry"
+	"github.com/covexo/devspace/pkg/devspace/services"
 	"github.com/covexo/devspace/pkg/util/log"
 	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
 	"k8s.io/client-go/kubernetes"
 )
 
 // StatusCmd holds the information needed for the status command
 type StatusCmd struct {
 	flags   *StatusCmdFlags
-	kubectl *kubernetes.Clientset
+	kubectl kubernetes.Interface
 }
 
 // StatusCmdFlags holds the possible flags for the list command
 type StatusCmdFlags struct {
+	values bool
+	json   bool
 }
 
 func init() {
@@ -38,13 +43,20 @@ func init() {
 	#######################################################
 	################## devspace status ####################
 	#######################################################
-	Shows the devspace status
+	Shows the devspace status. Use --values to print the
+	values of the last deployment of each helm release
+	(secrets are masked). Use --json to print the images
+	and releases of the last deployment in the format of
+	devspace deploy --summary-file
 	#######################################################
 	`,
 		Args: cobra.NoArgs,
 		Run:  cmd.RunStatus,
 	}
 
+	statusCmd.Flags().BoolVar(&cmd.flags.values, "values", false, "Prints the values of the last deployment of each helm release instead of the status")
+	statusCmd.Flags().BoolVar(&cmd.flags.json, "json", false, "Prints the images and releases of the last deployment as json (same schema as devspace deploy --summary-file)")
+
 	rootCmd.AddCommand(statusCmd)
 
 	statusSyncCmd := &cobra.Command{
@@ -76,7 +88,24 @@ func (cmd *StatusCmd) RunStatus(cobraCmd *cobra.Command, args []string) {
 	}
 	config := configutil.GetConfig()
 
-	cmd.kubectl, err = kubectl.NewClient()
+	if cmd.flags.values {
+		err = printDeployedValues()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+	if cmd.flags.json {
+		err = printDeploySummary()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	cmd.kubectl, err = newKubectlClient(false)
 	if err != nil {
 		log.Fatalf("Unable to create new kubectl client: %s", err.Error())
 	}
@@ -139,9 +168,81 @@ func (cmd *StatusCmd) RunStatus(cobraCmd *cobra.Command, args []string) {
 		}
 	}
 
+	autoPorts, err := services.GetAutoPorts()
+	if err != nil {
+		log.Warnf("Error reading %s: %v", services.PortForwardingPortsFile, err)
+	}
+
+	for _, autoPort := range autoPorts {
+		info := fmt.Sprintf("%s:%d -> %d (%s", autoPort.BindAddress, autoPort.LocalPort, autoPort.RemotePort, autoPort.Entry)
+		if autoPort.ConfiguredPort != 0 {
+			info += fmt.Sprintf(", local port %d was in use", autoPort.ConfiguredPort)
+		}
+
+		values = append(values, []string{
+			"Port Forwarding",
+			"Auto Port",
+			"",
+			info + ")",
+		})
+	}
+
 	log.PrintTable(headerValues, values)
 }
 
+// printDeployedValues prints the values of the last deployment of each helm release that were recorded in the
+// generated config
+func printDeployedValues() error {
+	config := configutil.GetConfig()
+	if config.DevSpace.Deployments == nil || len(*config.DevSpace.Deployments) == 0 {
+		log.Info("No deployments are configured")
+		return nil
+	}
+
+	generatedConfig, err := generated.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("Error loading generated.yaml: %v", err)
+	}
+
+	for _, deployConfig := range *config.DevSpace.Deployments {
+		if deployConfig.Helm == nil {
+			continue
+		}
+
+		values, ok := generatedConfig.DeployedValues[*deployConfig.Name]
+		if ok == false {
+			log.Infof("No values of release %s were recorded yet", *deployConfig.Name)
+			continue
+		}
+
+		out, err := yaml.Marshal(values)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Values of release %s:", *deployConfig.Name)
+		fmt.Print(string(out))
+	}
+
+	return nil
+}
+
+// printDeploySummary prints the summary of the last deployment that was recorded in the generated config as json
+func printDeploySummary() error {
+	generatedConfig, err := generated.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("Error loading generated.yaml: %v", err)
+	}
+
+	out, err := deploy.MarshalSummary(deploy.GetSummary(generatedConfig, deploy.SummaryStatusDeployed))
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(string(out))
+	return nil
+}
+
 func (cmd *StatusCmd) getTillerStatus() ([]string, error) {
 	config := configutil.GetConfig()
 	tillerNamespace := *config.Tiller.Namespace
@@ -179,17 +280,18 @@ func (cmd *StatusCmd) getRegistryStatus() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer helm.Close()
 
-	releases, err := helm.Client.ListReleases()
+	releases, err := helm.ListReleases()
 	if err != nil {
 		return nil, err
 	}
 
-	if len(releases.Releases) == 0 {
+	if len(releases) == 0 {
 		return nil, errors.New("No release found")
 	}
 
-	for _, release := range releases.Releases {
+	for _, release := range releases {
 		if release.GetName() == registry.InternalRegistryName {
 			if release.Info.Status.Code.String() != "DEPLOYED" {
 				return nil, fmt.Errorf("Registry helm release has bad status: %s", release.Info.Status.Code.String())
@@ -18,6 +18,7 @@ import (
 var syncStopped = regexp.MustCompile(`^\[Sync\] Sync stopped$`)
 var downstreamChanges = regexp.MustCompile(`^\[Downstream\] Successfully processed (\d+) change\(s\)$`)
 var upstreamChanges = regexp.MustCompile(`^\[Upstream\] Successfully processed (\d+) change\(s\)$`)
+var syncConflict = regexp.MustCompile(`^\[Sync\] Conflict on (.+): (.+)$`)
 
 type syncStatus struct {
 	Status    string
@@ -30,6 +31,7 @@ type syncStatus struct {
 	Error            string
 
 	TotalChanges int
+	Conflicts    int
 }
 
 // RunStatusSync executes the devspace status sync commad logic
@@ -82,6 +84,7 @@ func (cmd *StatusCmd) RunStatusSync(cobraCmd *cobra.Command, args []string) {
 		"Container",
 		"Latest Activity",
 		"Total Changes",
+		"Conflicts",
 	}
 
 	values := make([][]string, 0, len(syncMap))
@@ -122,6 +125,7 @@ func (cmd *StatusCmd) RunStatusSync(cobraCmd *cobra.Command, args []string) {
 			status.Container,
 			latestActivity,
 			strconv.Itoa(status.TotalChanges),
+			strconv.Itoa(status.Conflicts),
 		})
 	}
 
@@ -205,6 +209,10 @@ func updateSyncMap(syncMap map[string]*syncStatus, jsonMap map[string]string) er
 
 		changes, _ := strconv.Atoi(matches[1])
 		syncMap[identifier].TotalChanges += changes
+	} else if matches := syncConflict.FindStringSubmatch(message); len(matches) == 3 {
+		syncMap[identifier].LastActivity = "Conflict on " + matches[1]
+		syncMap[identifier].LastActivityTime = time
+		syncMap[identifier].Conflicts++
 	} else if syncStopped.MatchString(message) {
 		syncMap[identifier].Status = "Stopped"
 		syncMap[identifier].LastActivity = "Sync stopped"
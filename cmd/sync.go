@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/devspace/services"
+	"github.com/covexo/devspace/pkg/devspace/sync"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/spf13/cobra"
+	k8sv1 "k8s.io/api/core/v1"
+)
+
+// SyncCmd holds the information needed for the sync command
+type SyncCmd struct {
+	flags *SyncCmdFlags
+}
+
+// SyncCmdFlags holds the possible flags for the sync command
+type SyncCmdFlags struct {
+	namespace        string
+	labelSelector    string
+	service          string
+	podName          string
+	container        string
+	localPath        string
+	containerPath    string
+	exclude          []string
+	trace            string
+	verbose          bool
+	iKnowWhatImDoing bool
+	allowProtected   bool
+	switchContext    bool
+	config           string
+	configOverwrite  string
+	pathFilter       []string
+}
+
+func init() {
+	cmd := &SyncCmd{
+		flags: &SyncCmdFlags{},
+	}
+
+	cobraCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Starts a bi-directional sync between a local folder and a container",
+		Long: `
+#######################################################
+################### devspace sync #####################
+#######################################################
+Starts a bi-directional sync between a local folder
+and a container until you press Ctrl+C. Works without
+a devspace config as well:
+
+devspace sync --container-path=/app -l app=api
+devspace sync --local-path=src --container-path=/app/src --pod my-pod -n my-namespace
+devspace sync --container-path=/app -l app=api --trace=sync-trace.json
+devspace sync --container-path=/app -s api
+
+Without a devspace config, syncing into pods that are
+not labeled with devspace.covexo.com/dev=true requires
+the flag --i-know-what-im-doing
+#######################################################`,
+		Args: cobra.NoArgs,
+		Run:  cmd.Run,
+	}
+	rootCmd.AddCommand(cobraCmd)
+
+	cobraCmd.Flags().StringVarP(&cmd.flags.namespace, "namespace", "n", "", "Namespace where to select pods")
+	cobraCmd.Flags().StringVarP(&cmd.flags.labelSelector, "label-selector", "l", "", "Comma separated key=value selector list (e.g. release=test)")
+	cobraCmd.Flags().StringVarP(&cmd.flags.service, "service", "s", "", "Service name (in config) to select pod/container to sync to (instead of a label selector)")
+	cobraCmd.Flags().StringVar(&cmd.flags.podName, "pod", "", "Name of the pod to sync to (instead of a label selector)")
+	cobraCmd.Flags().StringVarP(&cmd.flags.container, "container", "c", "", "Container name within the pod to sync to (default: the first container)")
+	cobraCmd.Flags().StringVar(&cmd.flags.localPath, "local-path", ".", "Local folder to sync")
+	cobraCmd.Flags().StringVar(&cmd.flags.containerPath, "container-path", "", "Absolute path in the container to sync to")
+	cobraCmd.Flags().StringSliceVarP(&cmd.flags.exclude, "exclude", "e", []string{}, "Paths to exclude from the sync (gitignore syntax)")
+	cobraCmd.Flags().StringVar(&cmd.flags.trace, "trace", "", "Record all file events, transfers and decisions to this file (see devspace analyze sync-trace)")
+	cobraCmd.Flags().BoolVar(&cmd.flags.verbose, "verbose", false, "When enabled the sync will log every file change")
+	cobraCmd.Flags().BoolVar(&cmd.flags.iKnowWhatImDoing, "i-know-what-im-doing", false, "Allow syncing into pods that are not labeled as development workloads")
+	cobraCmd.Flags().BoolVar(&cmd.flags.allowProtected, "allow-protected", false, "Allow syncing into pods that are annotated as protected")
+	cobraCmd.Flags().BoolVar(&cmd.flags.switchContext, "switch-context", false, "Switch kubectl context to the devspace context")
+	cobraCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	cobraCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+
+	startCmd := &cobra.Command{
+		Use:   "start",
+		Short: "Starts the configured sync paths without devspace up",
+		Long: `
+#######################################################
+################ devspace sync start ##################
+#######################################################
+Starts all sync paths of the devspace config without
+building or deploying anything (e.g. if the chart was
+deployed by a CI pipeline) until you press Ctrl+C.
+Syncs are resumed on the new pod if a pod is replaced:
+
+devspace sync start
+devspace sync start --path-filter=./src,./assets
+#######################################################`,
+		Args: cobra.NoArgs,
+		Run:  cmd.RunStart,
+	}
+	cobraCmd.AddCommand(startCmd)
+
+	startCmd.Flags().StringSliceVar(&cmd.flags.pathFilter, "path-filter", []string{}, "Comma separated list of localSubPath values of the sync paths to start (default: all sync paths)")
+	startCmd.Flags().BoolVar(&cmd.flags.verbose, "verbose", false, "When enabled the sync will log every file change")
+	startCmd.Flags().BoolVar(&cmd.flags.allowProtected, "allow-protected", false, "Allow syncing into pods that are annotated as protected")
+	startCmd.Flags().BoolVar(&cmd.flags.switchContext, "switch-context", false, "Switch kubectl context to the devspace context")
+	startCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	startCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+}
+
+// Run executes the sync command logic
+func (cmd *SyncCmd) Run(cobraCmd *cobra.Command, args []string) {
+	if configutil.ConfigPath != cmd.flags.config {
+		configutil.ConfigPath = cmd.flags.config
+
+		// Don't use overwrite config if we use a different config
+		configutil.OverwriteConfigPath = ""
+	}
+	if configutil.OverwriteConfigPath != cmd.flags.configOverwrite {
+		configutil.OverwriteConfigPath = cmd.flags.configOverwrite
+	}
+
+	if cmd.flags.containerPath == "" {
+		log.Fatal("Please specify the path in the container with --container-path")
+	}
+
+	// Without a config we only use the flags and don't create a .devspace folder
+	remoteMode := initRemoteMode(cmd.flags.labelSelector, cmd.flags.podName)
+	if remoteMode {
+		sync.SetSyncLog(log.GetInstance())
+	} else {
+		log.StartFileLogging()
+	}
+
+	client, err := newKubectlClient(cmd.flags.switchContext)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	labelSelector, namespace, containerName, err := getRemoteSelector(cmd.flags.service, cmd.flags.labelSelector, cmd.flags.podName, cmd.flags.namespace, cmd.flags.container)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pod, err := getRemotePod(client, cmd.flags.podName, labelSelector, namespace)
+	if err != nil {
+		log.Fatalf("Cannot find running pod: %v", err)
+	}
+
+	err = kubectl.CheckProtectedPod(pod, cmd.flags.allowProtected)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if remoteMode && isDevWorkload(pod) == false && cmd.flags.iKnowWhatImDoing == false {
+		log.Fatalf("Pod %s/%s is not labeled with %s=true. Syncing might overwrite files in a workload that is not meant for development. Use --i-know-what-im-doing to sync anyway", pod.Namespace, pod.Name, devWorkloadLabel)
+	}
+
+	container, err := getSyncContainer(pod, containerName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	absLocalPath, err := filepath.Abs(cmd.flags.localPath)
+	if err != nil {
+		log.Fatalf("Unable to resolve local path %s: %v", cmd.flags.localPath, err)
+	}
+
+	syncConfig := &sync.SyncConfig{
+		Kubectl:        client,
+		Pod:            pod,
+		Container:      container,
+		WatchPath:      absLocalPath,
+		DestPath:       cmd.flags.containerPath,
+		ExcludePaths:   cmd.flags.exclude,
+		Verbose:        cmd.flags.verbose,
+		TracePath:      cmd.flags.trace,
+		AllowProtected: cmd.flags.allowProtected,
+	}
+
+	err = syncConfig.Start()
+	if err != nil {
+		log.Fatalf("Sync error: %v", err)
+	}
+
+	log.Donef("Sync started on %s <-> %s (Pod: %s/%s)", absLocalPath, cmd.flags.containerPath, pod.Namespace, pod.Name)
+
+	// Sync until the user interrupts the command
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+
+	syncConfig.Stop(nil)
+}
+
+// RunStart executes the devspace sync start command logic
+func (cmd *SyncCmd) RunStart(cobraCmd *cobra.Command, args []string) {
+	if configutil.ConfigPath != cmd.flags.config {
+		configutil.ConfigPath = cmd.flags.config
+
+		// Don't use overwrite config if we use a different config
+		configutil.OverwriteConfigPath = ""
+	}
+	if configutil.OverwriteConfigPath != cmd.flags.configOverwrite {
+		configutil.OverwriteConfigPath = cmd.flags.configOverwrite
+	}
+
+	configExists, _ := configutil.ConfigExists()
+	if configExists == false {
+		log.Fatal("Couldn't find a devspace config. Run `devspace init` first or use `devspace sync --container-path=...` to sync without a config")
+	}
+
+	log.StartFileLogging()
+
+	config := configutil.GetConfig()
+	if config.DevSpace.Sync == nil || len(*config.DevSpace.Sync) == 0 {
+		log.Fatal("No sync paths configured. Add one with `devspace add sync`")
+	}
+
+	client, err := newKubectlClient(cmd.flags.switchContext)
+	if err != nil {
+		log.Fatalf("Unable to create new kubectl client: %v", err)
+	}
+
+	syncConfigs, err := services.StartSyncPaths(client, kubectl.NewPodCache(client), cmd.flags.pathFilter, true, cmd.flags.verbose, cmd.flags.allowProtected, nil, log.GetInstance())
+	if err != nil {
+		log.Fatalf("Unable to start sync: %v", err)
+	}
+	if len(syncConfigs) == 0 {
+		log.Fatal("None of the sync paths could be started")
+	}
+
+	statusServer, err := sync.StartStatusServer(syncConfigs)
+	if err != nil {
+		log.Warnf("Unable to start sync status server: %v", err)
+	} else {
+		defer statusServer.Stop()
+	}
+
+	// Sync until the user interrupts the command
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+
+	for _, syncConfig := range syncConfigs {
+		syncConfig.Stop(nil)
+	}
+}
+
+func getSyncContainer(pod *k8sv1.Pod, containerName string) (*k8sv1.Container, error) {
+	if len(pod.Spec.Containers) == 0 {
+		return nil, fmt.Errorf("Pod %s/%s has no containers", pod.Namespace, pod.Name)
+	}
+
+	if containerName == "" {
+		return &pod.Spec.Containers[0], nil
+	}
+
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == containerName {
+			return &pod.Spec.Containers[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("Container %s wasn't found in pod %s/%s", containerName, pod.Namespace, pod.Name)
+}
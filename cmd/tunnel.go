@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/covexo/devspace/pkg/devspace/tunnel"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/spf13/cobra"
+)
+
+// TunnelCmd holds the information needed for the tunnel command
+type TunnelCmd struct {
+	flags *TunnelCmdFlags
+}
+
+// TunnelCmdFlags holds the possible flags for the tunnel command
+type TunnelCmdFlags struct {
+	port string
+}
+
+func init() {
+	cmd := &TunnelCmd{
+		flags: &TunnelCmdFlags{},
+	}
+
+	cobraCmd := &cobra.Command{
+		Use:   "tunnel",
+		Short: "Runs the container side of a reverse port forwarding",
+		Long: `
+#######################################################
+################### devspace tunnel ###################
+#######################################################
+Listens on the given port and tunnels all connections
+over stdin and stdout. This command is started inside
+the container by devspace up for reverse port
+forwarding and is not meant to be run manually.
+#######################################################`,
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		Run:    cmd.Run,
+	}
+	rootCmd.AddCommand(cobraCmd)
+
+	cobraCmd.Flags().StringVar(&cmd.flags.port, "port", "", "Port to listen on")
+}
+
+// Run executes the command logic
+func (cmd *TunnelCmd) Run(cobraCmd *cobra.Command, args []string) {
+	if cmd.flags.port == "" {
+		log.Fatal("Flag --port is required")
+	}
+
+	// Stdout is the tunnel stream, so errors can only be reported on stderr
+	err := tunnel.Serve(":"+cmd.flags.port, os.Stdin, os.Stdout)
+	if err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
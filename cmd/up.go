@@ -1,18 +1,30 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/covexo/devspace/pkg/devspace/cloud"
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
 	"github.com/covexo/devspace/pkg/devspace/config/generated"
 	"github.com/covexo/devspace/pkg/devspace/deploy"
+	deployHelm "github.com/covexo/devspace/pkg/devspace/deploy/helm"
 	"github.com/covexo/devspace/pkg/devspace/docker"
-	"github.com/covexo/devspace/pkg/devspace/image"
+	"github.com/covexo/devspace/pkg/devspace/helm"
 	"github.com/covexo/devspace/pkg/devspace/kubectl"
 	"github.com/covexo/devspace/pkg/devspace/registry"
 	"github.com/covexo/devspace/pkg/devspace/services"
+	"github.com/covexo/devspace/pkg/devspace/sync"
+	"github.com/covexo/devspace/pkg/util/envutil"
 	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/processutil"
+	"github.com/covexo/devspace/pkg/util/signalutil"
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/kubernetes"
 )
@@ -24,41 +36,91 @@ type UpCmd struct {
 
 // UpCmdFlags are the flags available for the up-command
 type UpCmdFlags struct {
-	tiller          bool
-	open            string
-	initRegistries  bool
-	build           bool
-	sync            bool
-	deploy          bool
-	exitAfterDeploy bool
-	allyes          bool
-	switchContext   bool
-	portforwarding  bool
-	verboseSync     bool
-	service         string
-	container       string
-	labelSelector   string
-	namespace       string
-	config          string
-	configOverwrite string
+	tiller                bool
+	open                  string
+	initRegistries        bool
+	build                 bool
+	skipBuild             bool
+	validateBuild         bool
+	dockerBuildKit        bool
+	buildKitInlineCache   bool
+	skipConflicts         bool
+	force                 bool
+	updateDependencies    bool
+	atomic                bool
+	noCleanupOnFailure    bool
+	showValuesDiff        bool
+	rollbackOnFailure     bool
+	sync                  bool
+	deploy                bool
+	skipDeploy            bool
+	exitAfterDeploy       bool
+	noTerminal            bool
+	allyes                bool
+	switchContext         bool
+	portforwarding        bool
+	maxPortForwards       int
+	portForwardingTimeout time.Duration
+	autoPort              bool
+	verboseSync           bool
+	showLogs              bool
+	maxLogLineWidth       int
+	service               string
+	container             string
+	labelSelector         string
+	namespace             string
+	envFile               string
+	valuesFromSecret      string
+	injectEnv             bool
+	fast                  bool
+	fastFallback          bool
+	allowProtected        bool
+	preDeployHook         string
+	postDeployHook        string
+	config                string
+	configOverwrite       string
+	tillerNamespace       string
 }
 
 //UpFlagsDefault are the default flags for UpCmdFlags
 var UpFlagsDefault = &UpCmdFlags{
-	tiller:          true,
-	open:            "cmd",
-	initRegistries:  true,
-	build:           false,
-	sync:            true,
-	switchContext:   false,
-	exitAfterDeploy: false,
-	allyes:          false,
-	deploy:          false,
-	portforwarding:  true,
-	verboseSync:     false,
-	container:       "",
-	namespace:       "",
-	labelSelector:   "",
+	tiller:                true,
+	open:                  "",
+	initRegistries:        true,
+	build:                 false,
+	skipBuild:             false,
+	validateBuild:         false,
+	dockerBuildKit:        false,
+	buildKitInlineCache:   false,
+	skipConflicts:         false,
+	force:                 false,
+	updateDependencies:    false,
+	atomic:                false,
+	noCleanupOnFailure:    false,
+	showValuesDiff:        true,
+	rollbackOnFailure:     false,
+	sync:                  true,
+	switchContext:         false,
+	exitAfterDeploy:       false,
+	noTerminal:            false,
+	allyes:                false,
+	deploy:                false,
+	skipDeploy:            false,
+	portforwarding:        true,
+	maxPortForwards:       services.DefaultMaxConcurrentPortForwards,
+	portForwardingTimeout: services.DefaultPortForwardingTimeout,
+	verboseSync:           false,
+	showLogs:              false,
+	maxLogLineWidth:       services.DefaultMaxLogLineWidth,
+	container:             "",
+	namespace:             "",
+	labelSelector:         "",
+	fast:                  false,
+	fastFallback:          true,
+	allowProtected:        false,
+	preDeployHook:         "",
+	postDeployHook:        "",
+	tillerNamespace:       "",
 }
 
 func init() {
@@ -85,25 +147,74 @@ Starts and connects your DevSpace:
 	rootCmd.AddCommand(cobraCmd)
 
 	cobraCmd.Flags().BoolVar(&cmd.flags.tiller, "tiller", cmd.flags.tiller, "Install/upgrade tiller")
+	cobraCmd.Flags().StringVar(&cmd.flags.tillerNamespace, "tiller-namespace", cmd.flags.tillerNamespace, "Uses the existing tiller in this namespace instead of tiller.namespace of the config (tiller is not installed or upgraded)")
 	cobraCmd.Flags().BoolVar(&cmd.flags.initRegistries, "init-registries", cmd.flags.initRegistries, "Initialize registries (and install internal one)")
 	cobraCmd.Flags().BoolVarP(&cmd.flags.build, "build", "b", cmd.flags.build, "Force image build")
+	cobraCmd.Flags().BoolVar(&cmd.flags.skipBuild, "skip-build", cmd.flags.skipBuild, "Skips building the images, even if a Dockerfile has changed")
+	cobraCmd.Flags().BoolVar(&cmd.flags.validateBuild, "validate-dockerfiles", cmd.flags.validateBuild, "Checks the dockerfiles for obvious problems before building")
+	cobraCmd.Flags().BoolVar(&cmd.flags.dockerBuildKit, "docker-buildkit", cmd.flags.dockerBuildKit, "Builds the images with Docker BuildKit (DOCKER_BUILDKIT=1)")
+	cobraCmd.Flags().BoolVar(&cmd.flags.buildKitInlineCache, "buildkit-inline-cache", cmd.flags.buildKitInlineCache, "Writes the BuildKit cache metadata into the images (BUILDKIT_INLINE_CACHE=1) to use them as cache source")
+	cobraCmd.Flags().BoolVar(&cmd.flags.skipConflicts, "skip-conflict-check", cmd.flags.skipConflicts, "Skips the check for existing resources that are not managed by the release")
+	cobraCmd.Flags().BoolVar(&cmd.flags.force, "force", cmd.flags.force, "Deploys even if resources of the chart already exist and are not managed by the release")
+	cobraCmd.Flags().BoolVar(&cmd.flags.updateDependencies, "update-dependencies", cmd.flags.updateDependencies, "Resolves the chart dependencies again, even if the downloaded archives are up to date")
+	cobraCmd.Flags().BoolVar(&cmd.flags.atomic, "atomic", cmd.flags.atomic, "Rolls back failed upgrades and purges failed installations of helm releases")
+	cobraCmd.Flags().BoolVar(&cmd.flags.noCleanupOnFailure, "no-cleanup-on-failure", cmd.flags.noCleanupOnFailure, "Leaves failed helm releases in place for debugging, even with --atomic (default without --atomic)")
+	cobraCmd.Flags().BoolVar(&cmd.flags.rollbackOnFailure, "rollback-on-failure", cmd.flags.rollbackOnFailure, "Rolls helm releases back to the previous revision if their pod doesn't get ready or crashes after the deployment")
+	cobraCmd.Flags().BoolVar(&cmd.flags.showValuesDiff, "show-values-diff", cmd.flags.showValuesDiff, "Prints the helm values that changed since the last deployment")
 	cobraCmd.Flags().BoolVar(&cmd.flags.sync, "sync", cmd.flags.sync, "Enable code synchronization")
 	cobraCmd.Flags().BoolVar(&cmd.flags.verboseSync, "verbose-sync", cmd.flags.verboseSync, "When enabled the sync will log every file change")
+	cobraCmd.Flags().BoolVar(&cmd.flags.showLogs, "show-logs", cmd.flags.showLogs, "Stream the logs of all release pods while the terminal is open")
+	cobraCmd.Flags().IntVar(&cmd.flags.maxLogLineWidth, "max-log-line-width", cmd.flags.maxLogLineWidth, "Truncate streamed log lines after this amount of characters (0 disables truncation)")
+	cobraCmd.Flags().StringVar(&cmd.flags.open, "open", cmd.flags.open, "Opens the forwarded url in the browser as soon as it responds: true, false, a local port or an url (default: devspace.open of the config)")
 	cobraCmd.Flags().BoolVar(&cmd.flags.portforwarding, "portforwarding", cmd.flags.portforwarding, "Enable port forwarding")
+	cobraCmd.Flags().IntVar(&cmd.flags.maxPortForwards, "max-port-forwards", cmd.flags.maxPortForwards, "Maximum number of port forwardings that are established at the same time")
+	cobraCmd.Flags().BoolVar(&cmd.flags.autoPort, "auto-port", cmd.flags.autoPort, "Uses a free local port for port forwardings whose local port is already in use")
+	cobraCmd.Flags().DurationVar(&cmd.flags.portForwardingTimeout, "timeout", cmd.flags.portForwardingTimeout, "Time to wait for a port forwarding to get ready before continuing without it")
 	cobraCmd.Flags().BoolVarP(&cmd.flags.deploy, "deploy", "d", cmd.flags.deploy, "Force chart deployment")
+	cobraCmd.Flags().BoolVar(&cmd.flags.skipDeploy, "skip-deploy", cmd.flags.skipDeploy, "Skips the deployment, even if a chart or an image has changed")
 	cobraCmd.Flags().BoolVar(&cmd.flags.switchContext, "switch-context", cmd.flags.switchContext, "Switch kubectl context to the devspace context")
 	cobraCmd.Flags().BoolVar(&cmd.flags.exitAfterDeploy, "exit-after-deploy", cmd.flags.exitAfterDeploy, "Exits the command after building the images and deploying the devspace")
+	cobraCmd.Flags().BoolVar(&cmd.flags.noTerminal, "no-terminal", cmd.flags.noTerminal, "Doesn't open a terminal, but keeps sync and port forwarding running until interrupted (e.g. in CI)")
 	cobraCmd.Flags().BoolVarP(&cmd.flags.allyes, "yes", "y", cmd.flags.allyes, "Answer every questions with the default")
 	cobraCmd.Flags().StringVarP(&cmd.flags.service, "service", "s", "", "Service name (in config) to select pods/container for terminal")
 	cobraCmd.Flags().StringVarP(&cmd.flags.container, "container", "c", cmd.flags.container, "Container name where to open the shell")
 	cobraCmd.Flags().StringVarP(&cmd.flags.labelSelector, "label-selector", "l", "", "Comma separated key=value selector list (e.g. release=test)")
 	cobraCmd.Flags().StringVarP(&cmd.flags.namespace, "namespace", "n", "", "Namespace where to select pods")
 	cobraCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	cobraCmd.Flags().StringVar(&cmd.flags.envFile, "env-file", "", "Loads the environment variables from this .env file before the config is loaded")
+	cobraCmd.Flags().BoolVar(&cmd.flags.injectEnv, "inject-env", cmd.flags.injectEnv, "Injects the variables of --env-file as env into all containers of the helm charts")
+	cobraCmd.Flags().StringVar(&cmd.flags.valuesFromSecret, "values-from-secret", "", "Uses the keys of this kubernetes secret (namespace/secret-name) as helm values (e.g. key database.url sets database.url)")
+	cobraCmd.Flags().BoolVar(&cmd.flags.fast, "fast", cmd.flags.fast, "Skips registry initialization, build and deployment if a devspace pod is already running and only starts the services")
+	cobraCmd.Flags().BoolVar(&cmd.flags.fastFallback, "fast-fallback", cmd.flags.fastFallback, "Runs the full pipeline if --fast doesn't find a running devspace pod (otherwise the command aborts)")
+	cobraCmd.Flags().BoolVar(&cmd.flags.allowProtected, "allow-protected", cmd.flags.allowProtected, "Allows to sync into and open the terminal in pods that are annotated as protected")
+	cobraCmd.Flags().StringVar(&cmd.flags.preDeployHook, "pre-deploy-hook", cmd.flags.preDeployHook, "Local shell command that is executed before the deployments are deployed (aborts if it fails)")
+	cobraCmd.Flags().StringVar(&cmd.flags.postDeployHook, "post-deploy-hook", cmd.flags.postDeployHook, "Local shell command that is executed after the deployments were deployed (aborts if it fails)")
 	cobraCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
 }
 
 // Run executes the command logic
 func (cmd *UpCmd) Run(cobraCmd *cobra.Command, args []string) {
+	var containerEnv map[string]string
+	if cmd.flags.envFile != "" {
+		envVars, err := envutil.LoadEnvFile(cmd.flags.envFile)
+		if err != nil {
+			Exit(ExitCodeConfigError, "Error loading env file %s: %v", cmd.flags.envFile, err)
+		}
+
+		if cmd.flags.injectEnv {
+			containerEnv = envVars
+		}
+	} else if cmd.flags.injectEnv {
+		Exit(ExitCodeConfigError, "Flag --inject-env requires --env-file")
+	}
+
+	if cmd.flags.skipBuild && cmd.flags.build {
+		Exit(ExitCodeConfigError, "Flags --skip-build and --build cannot be used together")
+	}
+	if cmd.flags.skipDeploy && cmd.flags.deploy {
+		Exit(ExitCodeConfigError, "Flags --skip-deploy and --deploy cannot be used together")
+	}
+
 	if configutil.ConfigPath != cmd.flags.config {
 		configutil.ConfigPath = cmd.flags.config
 
@@ -136,105 +247,284 @@ func (cmd *UpCmd) Run(cobraCmd *cobra.Command, args []string) {
 		configutil.SetDefaultsOnce()
 	}
 
-	// Create kubectl client and switch context if specified
-	client, err := kubectl.NewClientWithContextSwitch(cmd.flags.switchContext)
+	err := configutil.LoadConfig()
 	if err != nil {
-		log.Fatalf("Unable to create new kubectl client: %v", err)
+		Exit(ExitCodeConfigError, "%v", err)
 	}
 
-	// Create namespace if necessary
-	err = kubectl.EnsureDefaultNamespace(client, log.GetInstance())
+	// Create kubectl client and switch context if specified
+	client, err := newKubectlClient(cmd.flags.switchContext)
 	if err != nil {
-		log.Fatalf("Unable to create namespace: %v", err)
+		Exit(ExitCodeClusterUnreachable, "Unable to create new kubectl client: %v", err)
 	}
 
-	// Create cluster role binding if necessary
-	err = kubectl.EnsureGoogleCloudClusterRoleBinding(client, log.GetInstance())
-	if err != nil {
-		log.Fatalf("Unable to create ClusterRoleBinding: %v", err)
+	// Use a shared tiller for this run without changing the config
+	if cmd.flags.tillerNamespace != "" {
+		helm.UseSharedTiller(cmd.flags.tillerNamespace)
+		log.Infof("Using tiller in namespace %s", cmd.flags.tillerNamespace)
 	}
 
-	// Init image registries
-	if cmd.flags.initRegistries {
-		dockerClient, err := docker.NewClient(false)
+	// Attach to the running devspace without initializing registries, building and deploying
+	if cmd.flags.fast {
+		pod, err := services.FindRunningDevSpacePod(client, cmd.flags.service, cmd.flags.labelSelector, cmd.flags.namespace)
 		if err != nil {
-			log.Fatal(err)
+			Exit(ExitCodeClusterUnreachable, "Unable to find running devspace pod: %v", err)
 		}
 
-		err = registry.InitRegistries(dockerClient, client, log.GetInstance())
+		if pod != nil {
+			log.Donef("Found running pod %s/%s, skipping build and deployment", pod.Namespace, pod.Name)
+
+			exitCode, err := startServices(cmd.flags, client, args, log.GetInstance())
+			if err != nil {
+				exitOnError(err, ExitCodeSyncError)
+			}
+
+			exitWithCode(exitCode)
+			return
+		}
+
+		if cmd.flags.fastFallback == false {
+			Exit(ExitCodeDeployFailure, "No running devspace pod found. Run `devspace up` without --fast to build and deploy your devspace")
+		}
+
+		log.Warn("No running devspace pod found, building and deploying your devspace")
+	}
+
+	// Read the values from the secret before anything is built
+	var secretValues map[string]string
+	if cmd.flags.valuesFromSecret != "" {
+		secretValues, err = deployHelm.GetSecretValues(client, cmd.flags.valuesFromSecret)
 		if err != nil {
-			log.Fatal(err)
+			exitOnError(err, ExitCodeConfigError)
+		}
+	}
+
+	// Ctrl+C cancels the build and deployment, the second Ctrl+C force quits
+	ctx, stopInterruptHandler := signalutil.InterruptContext(log.GetInstance())
+
+	// Create namespace, cluster role binding and image registries if the cluster wasn't bootstrapped
+	err = setupCluster(ctx, client, cmd.flags.initRegistries)
+	if err != nil {
+		if ctx.Err() != nil {
+			exitOnError(errCancelled, 1)
 		}
+
+		exitOnError(err, ExitCodeClusterUnreachable)
 	}
 
 	// Build and deploy images
-	err = buildAndDeploy(cmd.flags.build, cmd.flags.deploy, client)
+	err = buildAndDeploy(ctx, cmd.flags, client, containerEnv, secretValues)
+	stopInterruptHandler()
 	if err != nil {
-		log.Fatal(err)
+		exitOnError(err, 1)
 	}
 
+	// The services don't need tiller, so the tunnels aren't kept open while they are running
+	helm.CloseClients()
+
 	if cmd.flags.exitAfterDeploy == false {
+		stopECRRefresh := startECRPullSecretRefresh(client, cmd.flags.initRegistries)
+
 		// Start services
-		err = startServices(cmd.flags, client, args, log.GetInstance())
+		exitCode, err := startServices(cmd.flags, client, args, log.GetInstance())
+		stopECRRefresh()
 		if err != nil {
-			log.Fatal(err)
+			exitOnError(err, ExitCodeSyncError)
 		}
+
+		exitWithCode(exitCode)
 	}
 }
 
-func buildAndDeploy(build, shouldDeploy bool, kubectl *kubernetes.Clientset) error {
+// startECRPullSecretRefresh refreshes the pull secrets of ecr registries in the background while the services are
+// running, because ecr tokens expire after 12 hours. The pull secrets of bootstrapped clusters are not created by
+// devspace, so they are not refreshed either
+func startECRPullSecretRefresh(client kubernetes.Interface, initRegistries bool) context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	if initRegistries == false || registry.HasECRPullSecrets() == false {
+		return cancel
+	}
+
+	bootstrapped, err := kubectl.IsBootstrapped(client)
+	if err != nil || bootstrapped {
+		return cancel
+	}
+
+	dockerClient, err := docker.NewClient(false)
+	if err != nil {
+		log.Warnf("Unable to refresh the pull secrets of ecr registries: %v", err)
+		return cancel
+	}
+
+	go registry.RefreshECRPullSecrets(ctx, dockerClient, client, log.GetInstance())
+	return cancel
+}
+
+// exitWithCode exits devspace with the exit code of the terminal command if it failed
+func exitWithCode(exitCode int) {
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
+
+// errCancelled is returned if the user cancelled the build or deployment
+var errCancelled = errors.New("Cancelled by user")
+
+// buildAndDeploy builds the images and deploys the deployments. containerEnv is injected into all containers of
+// helm charts and secretValues overwrite the values of helm charts. If the context is cancelled, the generated config is saved anyway, so that the images built and the
+// releases interrupted before are remembered
+func buildAndDeploy(ctx context.Context, flags *UpCmdFlags, kubectl kubernetes.Interface, containerEnv, secretValues map[string]string) error {
 	config := configutil.GetConfig()
 
 	// Load config
 	generatedConfig, err := generated.LoadConfig()
 	if err != nil {
-		return fmt.Errorf("Error loading generated.yaml: %v", err)
+		return withExitCode(ExitCodeConfigError, fmt.Errorf("Error loading generated.yaml: %v", err))
 	}
 
 	// Build image if necessary
-	mustRedeploy, err := image.BuildAll(kubectl, generatedConfig, build, log.GetInstance())
-	if err != nil {
-		return fmt.Errorf("Error building image: %v", err)
+	mustRedeploy := false
+	if flags.skipBuild {
+		log.Info("Skipping image build")
+	} else {
+		mustRedeploy, err = buildImages(ctx, kubectl, generatedConfig, flags.build, flags.validateBuild, flags.dockerBuildKit, flags.buildKitInlineCache, log.GetInstance())
+		if err != nil {
+			if ctx.Err() != nil {
+				return saveCancelled(generatedConfig)
+			}
+
+			return withExitCode(ExitCodeBuildFailure, fmt.Errorf("Error building image: %v", err))
+		}
 	}
 
 	// Save config if an image was built
 	if mustRedeploy == true {
 		err := generated.SaveConfig(generatedConfig)
 		if err != nil {
-			return fmt.Errorf("Error saving generated config: %v", err)
+			return withExitCode(ExitCodeConfigError, fmt.Errorf("Error saving generated config: %v", err))
 		}
 	}
 
+	// The deploy hooks only run if something is deployed
+	if flags.skipDeploy {
+		log.Info("Skipping deployment")
+		return nil
+	}
+
+	err = runDeployHook("pre-deploy", flags.preDeployHook)
+	if err != nil {
+		return withExitCode(ExitCodeDeployFailure, err)
+	}
+
 	// Deploy all defined deployments
 	if config.DevSpace.Deployments != nil {
 		// Deploy all
-		err = deploy.All(kubectl, generatedConfig, mustRedeploy || shouldDeploy, true, log.GetInstance())
+		err = deployAll(ctx, kubectl, generatedConfig, &deploy.Options{
+			ForceDeploy:        mustRedeploy || flags.deploy,
+			UseDevOverwrite:    true,
+			SkipConflictCheck:  flags.skipConflicts,
+			ForceConflicts:     flags.force,
+			UpdateDependencies: flags.updateDependencies,
+			CleanupOnFailure:   flags.atomic && flags.noCleanupOnFailure == false,
+			ShowValuesDiff:     flags.showValuesDiff,
+			RollbackOnFailure:  flags.rollbackOnFailure,
+			ContainerEnv:       containerEnv,
+			SecretValues:       secretValues,
+		}, log.GetInstance())
 		if err != nil {
-			return fmt.Errorf("Error deploying devspace: %v", err)
+			if ctx.Err() != nil {
+				log.Warn(err)
+				return saveCancelled(generatedConfig)
+			}
+
+			return withExitCode(ExitCodeDeployFailure, fmt.Errorf("Error deploying devspace: %v", err))
 		}
 
 		// Save Config
 		err = generated.SaveConfig(generatedConfig)
 		if err != nil {
-			return fmt.Errorf("Error saving generated config: %v", err)
+			return withExitCode(ExitCodeConfigError, fmt.Errorf("Error saving generated config: %v", err))
 		}
 	}
 
+	err = runDeployHook("post-deploy", flags.postDeployHook)
+	if err != nil {
+		return withExitCode(ExitCodeDeployFailure, err)
+	}
+
 	return nil
 }
 
-func startServices(flags *UpCmdFlags, kubectl *kubernetes.Clientset, args []string, log log.Logger) error {
+// saveCancelled saves the generated config after the user cancelled the build or deployment and returns errCancelled
+func saveCancelled(generatedConfig *generated.Config) error {
+	err := generated.SaveConfig(generatedConfig)
+	if err != nil {
+		return fmt.Errorf("%v. Error saving generated config: %v", errCancelled, err)
+	}
+
+	return errCancelled
+}
+
+// runDeployHook runs the local shell command of a --pre-deploy-hook or --post-deploy-hook flag
+func runDeployHook(name, command string) error {
+	if command == "" {
+		return nil
+	}
+
+	log.Infof("Running %s hook: %s", name, command)
+
+	err := processutil.RunShellCommand(command, os.Stdout, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("Error running %s hook '%s': %v", name, command, err)
+	}
+
+	log.Donef("Successfully ran %s hook", name)
+	return nil
+}
+
+// startServices starts port forwarding, sync and log streaming and opens the terminal. It returns the exit code of the
+// terminal command after all services were stopped. With --no-terminal, it waits for an interrupt instead of opening
+// the terminal and returns right away if no service was started
+func startServices(flags *UpCmdFlags, client kubernetes.Interface, args []string, log log.Logger) (int, error) {
+	// Sync and port forwarding should use the same pod if they use the same label selector
+	podCache := kubectl.NewPodCache(client)
+	servicesStarted := false
+
 	if flags.portforwarding {
-		err := services.StartPortForwarding(kubectl, log)
+		err := services.StartPortForwarding(client, podCache, flags.maxPortForwards, flags.portForwardingTimeout, flags.autoPort, log)
 		if err != nil {
-			return fmt.Errorf("Unable to start portforwarding: %v", err)
+			services.StopPortForwarding()
+			services.RemovePortForwardingPid()
+			return 0, fmt.Errorf("Unable to start portforwarding: %v", err)
 		}
+
+		defer services.RemovePortForwardingPid()
+		defer services.StopPortForwarding()
+
+		config := configutil.GetConfig()
+		servicesStarted = config.DevSpace.Ports != nil && len(*config.DevSpace.Ports) > 0
 	}
 
+	openURL, err := services.GetOpenURL(flags.open)
+	if err != nil {
+		return 0, err
+	}
+	if openURL != "" {
+		// Open the browser in the background, so the terminal doesn't wait for the application to respond
+		go func() {
+			err := services.OpenURL(openURL, services.DefaultOpenTimeout, log)
+			if err != nil {
+				log.Warnf("Unable to open %s: %v", openURL, err)
+			}
+		}()
+	}
+
+	// Errors that stop a sync end devspace up with ExitCodeSyncError
+	syncErrors := make(chan error, 1)
 	if flags.sync {
-		syncConfigs, err := services.StartSync(kubectl, flags.verboseSync, log)
+		syncConfigs, err := services.StartSync(client, podCache, flags.verboseSync, flags.allowProtected, syncErrors, log)
 		if err != nil {
-			return fmt.Errorf("Unable to start sync: %v", err)
+			return 0, fmt.Errorf("Unable to start sync: %v", err)
 		}
 
 		defer func() {
@@ -242,6 +532,14 @@ func startServices(flags *UpCmdFlags, kubectl *kubernetes.Clientset, args []stri
 				v.Stop(nil)
 			}
 		}()
+		servicesStarted = servicesStarted || len(syncConfigs) > 0
+
+		statusServer, err := sync.StartStatusServer(syncConfigs)
+		if err != nil {
+			log.Warnf("Unable to start sync status server: %v", err)
+		} else {
+			defer statusServer.Stop()
+		}
 	}
 
 	// Print domain name if we use a cloud provider
@@ -251,5 +549,56 @@ func startServices(flags *UpCmdFlags, kubectl *kubernetes.Clientset, args []stri
 		log.Info("See https://devspace-cloud.com/domain-guide for more information")
 	}
 
-	return services.StartTerminal(kubectl, flags.service, flags.container, flags.labelSelector, flags.namespace, args, log)
+	if flags.showLogs {
+		streamer, err := services.StartLogs(client, services.GetReleaseLogSelectors(), flags.maxLogLineWidth, log)
+		if err != nil {
+			return 0, fmt.Errorf("Unable to start log streaming: %v", err)
+		}
+
+		defer streamer.Stop()
+		servicesStarted = true
+	}
+
+	if flags.noTerminal {
+		if len(args) > 0 {
+			log.Warnf("The terminal command %s is not executed, because of --no-terminal", strings.Join(args, " "))
+		}
+		if servicesStarted == false {
+			log.Info("Neither sync nor port forwarding is running, exiting without terminal")
+			return 0, nil
+		}
+
+		log.Info("Sync and port forwarding are running without terminal. Press Ctrl+C to stop them")
+
+		// Keep the services running until the user or the pipeline interrupts the command
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+		select {
+		case <-signals:
+			return 0, nil
+		case err := <-syncErrors:
+			return 0, fatalSyncError(err)
+		}
+	}
+
+	exitCode := 0
+	terminalErr := make(chan error, 1)
+	go func() {
+		var err error
+		exitCode, err = services.StartTerminal(client, flags.service, flags.container, flags.labelSelector, flags.namespace, "", nil, flags.allowProtected, args, log)
+		terminalErr <- err
+	}()
+
+	select {
+	case err := <-terminalErr:
+		return exitCode, err
+	case err := <-syncErrors:
+		return 0, fatalSyncError(err)
+	}
+}
+
+// fatalSyncError returns an error that ends devspace up with ExitCodeSyncError
+func fatalSyncError(err error) error {
+	return withExitCode(ExitCodeSyncError, fmt.Errorf("Fatal sync error: %v. For more information check .devspace/logs/sync.log", err))
 }
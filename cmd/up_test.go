@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/generated"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/devspace/deploy"
+	"github.com/covexo/devspace/pkg/util/log"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type buildAndDeployTestCase struct {
+	name  string
+	flags UpCmdFlags
+
+	imageBuilt bool
+	buildErr   error
+	deployErr  error
+
+	expectBuild        bool
+	expectForceRebuild bool
+	expectDeploy       bool
+	expectForceDeploy  bool
+	expectExitCode     int
+}
+
+func TestBuildAndDeploy(t *testing.T) {
+	testCases := []buildAndDeployTestCase{
+		{
+			name:         "Nothing changed",
+			expectBuild:  true,
+			expectDeploy: true,
+		},
+		{
+			name:              "Image built",
+			imageBuilt:        true,
+			expectBuild:       true,
+			expectDeploy:      true,
+			expectForceDeploy: true,
+		},
+		{
+			name:               "Force build",
+			flags:              UpCmdFlags{build: true},
+			imageBuilt:         true,
+			expectBuild:        true,
+			expectForceRebuild: true,
+			expectDeploy:       true,
+			expectForceDeploy:  true,
+		},
+		{
+			name:              "Force deploy",
+			flags:             UpCmdFlags{deploy: true},
+			expectBuild:       true,
+			expectDeploy:      true,
+			expectForceDeploy: true,
+		},
+		{
+			name:         "Skip build",
+			flags:        UpCmdFlags{skipBuild: true},
+			expectDeploy: true,
+		},
+		{
+			name:        "Skip deploy",
+			flags:       UpCmdFlags{skipDeploy: true},
+			imageBuilt:  true,
+			expectBuild: true,
+		},
+		{
+			name:           "Build error",
+			buildErr:       errors.New("build failed"),
+			expectBuild:    true,
+			expectExitCode: ExitCodeBuildFailure,
+		},
+		{
+			name:           "Deploy error",
+			deployErr:      errors.New("deploy failed"),
+			expectBuild:    true,
+			expectDeploy:   true,
+			expectExitCode: ExitCodeDeployFailure,
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "devspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The generated config is saved in the working directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	oldBuildImages, oldDeployAll := buildImages, deployAll
+	defer func() {
+		buildImages = oldBuildImages
+		deployAll = oldDeployAll
+		configutil.ResetConfig()
+	}()
+
+	setTestConfig()
+	client := fake.NewSimpleClientset()
+
+	for _, testCase := range testCases {
+		buildCalled := false
+		forceRebuild := false
+		deployCalled := false
+		forceDeploy := false
+		var deployedSecretValues map[string]string
+
+		buildImages = func(ctx context.Context, client kubernetes.Interface, generatedConfig *generated.Config, force, validateDockerfiles, dockerBuildKit, buildKitInlineCache bool, log log.Logger) (bool, error) {
+			buildCalled = true
+			forceRebuild = force
+			return testCase.imageBuilt, testCase.buildErr
+		}
+		deployAll = func(ctx context.Context, client kubernetes.Interface, generatedConfig *generated.Config, options *deploy.Options, log log.Logger) error {
+			deployCalled = true
+			forceDeploy = options.ForceDeploy
+			deployedSecretValues = options.SecretValues
+			return testCase.deployErr
+		}
+
+		err := buildAndDeploy(context.Background(), &testCase.flags, client, nil, map[string]string{"apiKey": "0123"})
+		if testCase.expectExitCode != 0 {
+			exitErr, ok := err.(*exitCodeError)
+			if ok == false || exitErr.code != testCase.expectExitCode {
+				t.Fatalf("Test case %s: expected exit code %d, got error %v", testCase.name, testCase.expectExitCode, err)
+			}
+		} else if err != nil {
+			t.Fatalf("Test case %s: unexpected error %v", testCase.name, err)
+		}
+
+		if buildCalled != testCase.expectBuild || forceRebuild != testCase.expectForceRebuild {
+			t.Fatalf("Test case %s: expected build %v (force %v), got build %v (force %v)", testCase.name, testCase.expectBuild, testCase.expectForceRebuild, buildCalled, forceRebuild)
+		}
+		if deployCalled != testCase.expectDeploy || forceDeploy != testCase.expectForceDeploy {
+			t.Fatalf("Test case %s: expected deploy %v (force %v), got deploy %v (force %v)", testCase.name, testCase.expectDeploy, testCase.expectForceDeploy, deployCalled, forceDeploy)
+		}
+		if deployCalled && deployedSecretValues["apiKey"] != "0123" {
+			t.Fatalf("Test case %s: secret values weren't passed to the deployment", testCase.name)
+		}
+	}
+}
+
+// setTestConfig replaces the loaded config with a config that has a fixed namespace and a single deployment, so that
+// neither .devspace/config.yaml nor the kube config is read
+func setTestConfig() {
+	configutil.ResetConfig()
+
+	config := configutil.InitConfig()
+	config.Cluster.Namespace = configutil.String("test")
+	config.DevSpace.Deployments = &[]*v1.DeploymentConfig{
+		{
+			Name: configutil.String("devspace-default"),
+		},
+	}
+}
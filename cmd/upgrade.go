@@ -13,6 +13,7 @@ type UpgradeCmd struct {
 
 // UpgradeCmdFlags are the flags available for the upgrade-command
 type UpgradeCmdFlags struct {
+	check bool
 }
 
 func init() {
@@ -21,23 +22,47 @@ func init() {
 	}
 
 	cobraCmd := &cobra.Command{
-		Use:   "upgrade",
-		Short: "Upgrade the devspace cli to the newest version",
+		Use:     "upgrade",
+		Aliases: []string{"update"},
+		Short:   "Upgrade the devspace cli to the newest version",
 		Long: `
 #######################################################
 ################## devspace upgrade ###################
 #######################################################
-Upgrades the devspace cli to the newest version
+Upgrades the devspace cli to the newest version. The
+downloaded binary is verified with the sha256 checksum
+of the release before it replaces the current one:
+
+devspace upgrade
+devspace upgrade --check
 #######################################################`,
 		Args: cobra.NoArgs,
 		Run:  cmd.Run,
 	}
 	rootCmd.AddCommand(cobraCmd)
+
+	cobraCmd.Flags().BoolVar(&cmd.flags.check, "check", false, "Only check if a newer version is available")
 }
 
 // Run executes the command logic
 func (cmd *UpgradeCmd) Run(cobraCmd *cobra.Command, args []string) {
 	log.StartFileLogging()
+
+	if cmd.flags.check {
+		newerVersion, err := upgrade.CheckForNewerVersion()
+		if err != nil {
+			log.Fatalf("Couldn't check for newer version: %v", err)
+		}
+
+		if newerVersion == "" {
+			log.Infof("Current binary is the latest version: %s", upgrade.GetVersion())
+		} else {
+			log.Infof("A newer version is available: %s (current version: %s). Run `devspace upgrade` to update the cli", newerVersion, upgrade.GetVersion())
+		}
+
+		return
+	}
+
 	err := upgrade.Upgrade()
 
 	if err != nil {
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/services"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/spf13/cobra"
+)
+
+// ValidateCmd holds the required data for the validate cmd
+type ValidateCmd struct {
+	flags *ValidateCmdFlags
+}
+
+// ValidateCmdFlags holds the possible validate cmd flags
+type ValidateCmdFlags struct {
+	config          string
+	configOverwrite string
+}
+
+func init() {
+	cmd := &ValidateCmd{
+		flags: &ValidateCmdFlags{},
+	}
+
+	cobraCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validates the devspace config",
+		Long: `
+#######################################################
+################# devspace validate ###################
+#######################################################
+Loads the devspace config and checks it for errors
+that would otherwise only show up during devspace up,
+e.g. local ports that are used by multiple port
+forwardings. Same as devspace config validate
+#######################################################`,
+		Args: cobra.NoArgs,
+		Run:  cmd.Run,
+	}
+
+	cobraCmd.Flags().StringVar(&cmd.flags.config, "config", configutil.ConfigPath, "The devspace config file to load (default: '.devspace/config.yaml'")
+	cobraCmd.Flags().StringVar(&cmd.flags.configOverwrite, "config-overwrite", configutil.OverwriteConfigPath, "The devspace config overwrite file to load (default: '.devspace/overwrite.yaml'")
+
+	rootCmd.AddCommand(cobraCmd)
+}
+
+// Run executes the validate command logic
+func (cmd *ValidateCmd) Run(cobraCmd *cobra.Command, args []string) {
+	validateConfig(cmd.flags.config, cmd.flags.configOverwrite)
+}
+
+// validateConfig loads the config and exits with 1 if it can't be loaded or is invalid. All problems found by
+// configutil.Validate are reported at once
+func validateConfig(configPath, overwriteConfigPath string) {
+	setConfigPaths(configPath, overwriteConfigPath)
+
+	log.Infof("Loading config %s with overwrite config %s", configutil.ConfigPath, configutil.OverwriteConfigPath)
+
+	// Loading the config fails on syntax errors
+	err := configutil.LoadConfig()
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+
+	// The defaults aren't set, because setting them fails on the first unnamed deployment or service
+	config := configutil.GetConfigWithoutDefaults()
+
+	err = configutil.Validate(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if config.DevSpace != nil && config.DevSpace.Ports != nil {
+		err := services.ValidatePortForwarding(*config.DevSpace.Ports, log.GetInstance())
+		if err != nil {
+			log.Fatalf("Invalid port forwarding config: %v", err)
+		}
+	}
+
+	log.Donef("Config %s is valid", configutil.ConfigPath)
+}
+
+// setConfigPaths sets the config and overwrite config to load. A different config is loaded without overwrite config
+// unless one is specified explicitly
+func setConfigPaths(configPath, overwriteConfigPath string) {
+	if configutil.ConfigPath != configPath {
+		configutil.ConfigPath = configPath
+
+		// Don't use overwrite config if we use a different config
+		configutil.OverwriteConfigPath = ""
+	}
+	if configutil.OverwriteConfigPath != overwriteConfigPath {
+		configutil.OverwriteConfigPath = overwriteConfigPath
+	}
+}
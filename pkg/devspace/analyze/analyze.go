@@ -0,0 +1,232 @@
+package analyze
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/util/log"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LogTailLines is the amount of log lines that are shown for a failed container
+const LogTailLines int64 = 50
+
+// Waiting reasons that mean the image of the container cannot be pulled
+var imagePullReasons = map[string]bool{
+	"ErrImagePull":      true,
+	"ImagePullBackOff":  true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// PodReport holds the problems that were found for a pod
+type PodReport struct {
+	Name      string
+	Namespace string
+	Status    string
+	Problems  []string
+	Events    []string
+
+	// Logs holds the last log lines of the failed containers
+	Logs map[string]string
+}
+
+// Pods analyzes all pods in the namespace that match the label selector and returns a report for every pod
+// that has problems. If namespace is empty, the default namespace is used
+func Pods(client kubernetes.Interface, namespace, labelSelector string) ([]*PodReport, error) {
+	if namespace == "" {
+		defaultNamespace, err := configutil.GetDefaultNamespace(configutil.GetConfig())
+		if err != nil {
+			return nil, err
+		}
+
+		namespace = defaultNamespace
+	}
+
+	podList, err := client.Core().Pods(namespace).List(metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing pods: %v", err)
+	}
+
+	reports := []*PodReport{}
+	for i := range podList.Items {
+		report := Pod(client, &podList.Items[i])
+		if report != nil {
+			reports = append(reports, report)
+		}
+	}
+
+	return reports, nil
+}
+
+// Pod inspects the container states, events and logs of a pod and returns nil if the pod looks healthy
+func Pod(client kubernetes.Interface, pod *k8sv1.Pod) *PodReport {
+	report := &PodReport{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		Status:    kubectl.GetPodStatus(pod),
+		Problems:  []string{},
+		Events:    []string{},
+		Logs:      map[string]string{},
+	}
+
+	if pod.Status.Phase == k8sv1.PodPending {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == k8sv1.PodScheduled && condition.Status == k8sv1.ConditionFalse {
+				report.Problems = append(report.Problems, fmt.Sprintf("Pod cannot be scheduled: %s", condition.Message))
+			}
+		}
+	}
+
+	containerStatuses := append([]k8sv1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+	containerStatuses = append(containerStatuses, pod.Status.ContainerStatuses...)
+
+	for _, containerStatus := range containerStatuses {
+		problems, crashed := analyzeContainer(&containerStatus)
+		report.Problems = append(report.Problems, problems...)
+
+		if crashed {
+			logs, err := getContainerLogs(client, pod, containerStatus.Name, LogTailLines)
+			if err != nil {
+				report.Problems = append(report.Problems, fmt.Sprintf("Unable to retrieve logs of container %s: %v", containerStatus.Name, err))
+			} else if logs != "" {
+				report.Logs[containerStatus.Name] = logs
+			}
+		}
+	}
+
+	if len(report.Problems) == 0 && pod.Status.Phase != k8sv1.PodPending {
+		return nil
+	}
+
+	events, err := getPodEvents(client, pod)
+	if err == nil {
+		report.Events = events
+	} else {
+		report.Problems = append(report.Problems, fmt.Sprintf("Unable to retrieve events: %v", err))
+	}
+
+	return report
+}
+
+// getPodEvents returns the warning events of the pod (e.g. the scheduler message of an unschedulable pod)
+func getPodEvents(client kubernetes.Interface, pod *k8sv1.Pod) ([]string, error) {
+	events, err := client.Core().Events(pod.Namespace).List(metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod,involvedObject.name=" + pod.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := []string{}
+	for _, event := range events.Items {
+		if event.Type != k8sv1.EventTypeWarning {
+			continue
+		}
+
+		warnings = append(warnings, fmt.Sprintf("%s: %s (%dx, last seen %s ago)", event.Reason, strings.TrimSpace(event.Message), event.Count, time.Since(event.LastTimestamp.Time).Round(time.Second)))
+	}
+
+	return warnings, nil
+}
+
+// analyzeContainer returns the problems of the container and if the container crashed
+func analyzeContainer(containerStatus *k8sv1.ContainerStatus) ([]string, bool) {
+	problems := []string{}
+	crashed := false
+
+	lastTerminated := containerStatus.LastTerminationState.Terminated
+	terminated := containerStatus.State.Terminated
+	if terminated == nil {
+		terminated = lastTerminated
+	}
+
+	if terminated != nil && terminated.Reason == "OOMKilled" {
+		problems = append(problems, fmt.Sprintf("Container %s was killed, because it ran out of memory (OOMKilled). Increase its memory limit", containerStatus.Name))
+		crashed = true
+	}
+
+	if waiting := containerStatus.State.Waiting; waiting != nil {
+		switch {
+		case imagePullReasons[waiting.Reason]:
+			problems = append(problems, fmt.Sprintf("Container %s cannot pull image %s (%s): %s", containerStatus.Name, containerStatus.Image, waiting.Reason, waiting.Message))
+		case waiting.Reason == "CrashLoopBackOff":
+			problem := fmt.Sprintf("Container %s is crashing repeatedly (CrashLoopBackOff, %d restarts)", containerStatus.Name, containerStatus.RestartCount)
+			if lastTerminated != nil {
+				problem += fmt.Sprintf(", last exit code %d (%s)", lastTerminated.ExitCode, lastTerminated.Reason)
+			}
+
+			problems = append(problems, problem)
+			crashed = true
+		case waiting.Reason == "CreateContainerConfigError" || waiting.Reason == "RunContainerError":
+			problems = append(problems, fmt.Sprintf("Container %s cannot be started (%s): %s", containerStatus.Name, waiting.Reason, waiting.Message))
+		}
+	}
+
+	if containerStatus.State.Terminated != nil && containerStatus.State.Terminated.ExitCode != 0 && containerStatus.State.Terminated.Reason != "OOMKilled" {
+		problems = append(problems, fmt.Sprintf("Container %s terminated with exit code %d (%s)", containerStatus.Name, containerStatus.State.Terminated.ExitCode, containerStatus.State.Terminated.Reason))
+		crashed = true
+	}
+
+	return problems, crashed
+}
+
+// getContainerLogs returns the last log lines of the container. If the container was restarted, the logs of the
+// crashed instance are returned
+func getContainerLogs(client kubernetes.Interface, pod *k8sv1.Pod, containerName string, tailLines int64) (string, error) {
+	logs, err := client.Core().Pods(pod.Namespace).GetLogs(pod.Name, &k8sv1.PodLogOptions{
+		Container: containerName,
+		TailLines: &tailLines,
+		Previous:  true,
+	}).Do().Raw()
+	if err != nil {
+		// There is no previous instance if the container was not restarted yet
+		logs, err = client.Core().Pods(pod.Namespace).GetLogs(pod.Name, &k8sv1.PodLogOptions{
+			Container: containerName,
+			TailLines: &tailLines,
+		}).Do().Raw()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return strings.TrimSpace(string(logs)), nil
+}
+
+// PrintReports prints the reports in a readable format
+func PrintReports(reports []*PodReport, log log.Logger) {
+	if len(reports) == 0 {
+		log.Done("No problems found")
+		return
+	}
+
+	for _, report := range reports {
+		log.Failf("Pod %s/%s (Status: %s)", report.Namespace, report.Name, report.Status)
+
+		if len(report.Problems) > 0 {
+			log.Info("Problems:")
+			for _, problem := range report.Problems {
+				log.Infof("  - %s", problem)
+			}
+		}
+
+		if len(report.Events) > 0 {
+			log.Info("Events:")
+			for _, event := range report.Events {
+				log.Infof("  - %s", event)
+			}
+		}
+
+		for containerName, logs := range report.Logs {
+			log.Infof("Last %d log lines of container %s:", LogTailLines, containerName)
+			log.Write([]byte(logs + "\n\n"))
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package analyze
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DiagnoseLogTailLines is the amount of log lines that are shown for containers that are not ready or restarted
+const DiagnoseLogTailLines int64 = 30
+
+// Diagnose returns a readable description of why a pod is not ready. It contains the container statuses, the
+// problems and warning events of the pod (e.g. why it can't be scheduled) and the last log lines of the containers
+// that are not ready or were restarted
+func Diagnose(client kubernetes.Interface, pod *k8sv1.Pod) string {
+	lines := []string{fmt.Sprintf("Pod %s/%s (Status: %s)", pod.Namespace, pod.Name, kubectl.GetPodStatus(pod))}
+
+	containerStatuses := append([]k8sv1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+	containerStatuses = append(containerStatuses, pod.Status.ContainerStatuses...)
+
+	lines = append(lines, "Containers:")
+	for _, containerStatus := range containerStatuses {
+		line := fmt.Sprintf("  - %s: ready=%v, restarts=%d, %s", containerStatus.Name, containerStatus.Ready, containerStatus.RestartCount, getContainerState(&containerStatus))
+		if lastTerminated := containerStatus.LastTerminationState.Terminated; lastTerminated != nil {
+			line += fmt.Sprintf(", last terminated (%s, exit code %d)", lastTerminated.Reason, lastTerminated.ExitCode)
+		}
+
+		lines = append(lines, line)
+	}
+
+	report := Pod(client, pod)
+	if report == nil {
+		report = &PodReport{}
+	}
+
+	// Pods without problems (e.g. with a failing readiness probe) are not reported, but their events tell why
+	if len(report.Events) == 0 {
+		events, err := getPodEvents(client, pod)
+		if err != nil {
+			report.Problems = append(report.Problems, fmt.Sprintf("Unable to retrieve events: %v", err))
+		} else {
+			report.Events = events
+		}
+	}
+
+	if len(report.Problems) > 0 {
+		lines = append(lines, "Problems:")
+		for _, problem := range report.Problems {
+			lines = append(lines, "  - "+problem)
+		}
+	}
+
+	if len(report.Events) > 0 {
+		lines = append(lines, "Events:")
+		for _, event := range report.Events {
+			lines = append(lines, "  - "+event)
+		}
+	}
+
+	for _, containerStatus := range containerStatuses {
+		// Init containers that completed are ready as well
+		completed := containerStatus.State.Terminated != nil && containerStatus.State.Terminated.ExitCode == 0
+		if (containerStatus.Ready || completed) && containerStatus.RestartCount == 0 {
+			continue
+		}
+
+		logs, err := getContainerLogs(client, pod, containerStatus.Name, DiagnoseLogTailLines)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("Unable to retrieve logs of container %s: %v", containerStatus.Name, err))
+		} else if logs != "" {
+			lines = append(lines, fmt.Sprintf("Last %d log lines of container %s:", DiagnoseLogTailLines, containerStatus.Name), logs)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func getContainerState(containerStatus *k8sv1.ContainerStatus) string {
+	state := containerStatus.State
+
+	switch {
+	case state.Waiting != nil:
+		if state.Waiting.Message != "" {
+			return fmt.Sprintf("waiting (%s: %s)", state.Waiting.Reason, state.Waiting.Message)
+		}
+
+		return fmt.Sprintf("waiting (%s)", state.Waiting.Reason)
+	case state.Terminated != nil:
+		return fmt.Sprintf("terminated (%s, exit code %d)", state.Terminated.Reason, state.Terminated.ExitCode)
+	case state.Running != nil:
+		return fmt.Sprintf("running since %s", state.Running.StartedAt.Format("15:04:05"))
+	}
+
+	return "unknown state"
+}
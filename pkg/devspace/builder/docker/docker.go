@@ -4,6 +4,11 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	dockerclient "github.com/covexo/devspace/pkg/devspace/docker"
 
@@ -35,6 +40,19 @@ type Builder struct {
 	ImageName   string
 	ImageTag    string
 
+	// BuildKit builds the image with the docker cli and DOCKER_BUILDKIT=1, because the docker api client cannot
+	// build with BuildKit
+	BuildKit bool
+
+	// BuildKitInlineCache writes the build cache metadata into the image, so that it can be used with --cache-from
+	BuildKitInlineCache bool
+
+	// Env is the environment of the docker cli (default: the environment of the current process)
+	Env []string
+
+	// PushedDigest is the registry digest of the image after it was pushed
+	PushedDigest string
+
 	imageURL   string
 	authConfig *types.AuthConfig
 	client     client.CommonAPIClient
@@ -72,12 +90,14 @@ func NewBuilder(client client.CommonAPIClient, registryURL, imageName, imageTag
 // BuildImage builds a dockerimage with the docker cli
 // contextPath is the absolute path to the context path
 // dockerfilePath is the absolute path to the dockerfile WITHIN the contextPath
-func (b *Builder) BuildImage(contextPath, dockerfilePath string, options *types.ImageBuildOptions) error {
+func (b *Builder) BuildImage(ctx context.Context, contextPath, dockerfilePath string, options *types.ImageBuildOptions) error {
 	if options == nil {
 		options = &types.ImageBuildOptions{}
 	}
+	if b.BuildKit {
+		return b.buildImageWithBuildKit(ctx, contextPath, dockerfilePath, options)
+	}
 
-	ctx := context.Background()
 	outStream := command.NewOutStream(stdout)
 	contextDir, relDockerfile, err := build.GetContextFromLocalDir(contextPath, dockerfilePath)
 	if err != nil {
@@ -100,6 +120,16 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, options *types.
 		return err
 	}
 
+	// A dockerfile outside of the context (e.g. with rewritten base images) is added to the build context
+	var dockerfileCtx *os.File
+	if strings.HasPrefix(relDockerfile, ".."+string(filepath.Separator)) {
+		dockerfileCtx, err = os.Open(dockerfilePath)
+		if err != nil {
+			return errors.Errorf("Unable to open Dockerfile: %v", err)
+		}
+		defer dockerfileCtx.Close()
+	}
+
 	excludes = build.TrimBuildFilesFromExcludes(excludes, relDockerfile, false)
 	buildCtx, err := archive.TarWithOptions(contextDir, &archive.TarOptions{
 		ExcludePatterns: excludes,
@@ -109,6 +139,13 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, options *types.
 		return err
 	}
 
+	if dockerfileCtx != nil {
+		buildCtx, relDockerfile, err = build.AddDockerfileToBuildContext(dockerfileCtx, buildCtx)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Setup an upload progress bar
 	progressOutput := streamformatter.NewProgressOutput(outStream)
 	body := progress.NewProgressReader(buildCtx, progressOutput, 0, "", "Sending build context to Docker daemon")
@@ -121,18 +158,77 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, options *types.
 		AuthConfigs: authConfigs,
 	})
 	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
 		return err
 	}
 	defer response.Body.Close()
 
 	err = jsonmessage.DisplayJSONMessagesStream(response.Body, outStream, outStream.FD(), outStream.IsTerminal(), nil)
 	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
 		return err
 	}
 
 	return nil
 }
 
+// buildImageWithBuildKit builds the image with the docker cli, because BuildKit needs a session that the
+// docker api client does not support
+func (b *Builder) buildImageWithBuildKit(ctx context.Context, contextPath, dockerfilePath string, options *types.ImageBuildOptions) error {
+	args := []string{"build", "--tag", b.imageURL, "--file", dockerfilePath}
+
+	for key, value := range options.BuildArgs {
+		if value != nil {
+			args = append(args, "--build-arg", key+"="+*value)
+		} else {
+			args = append(args, "--build-arg", key)
+		}
+	}
+	if b.BuildKitInlineCache {
+		args = append(args, "--build-arg", "BUILDKIT_INLINE_CACHE=1")
+	}
+	if options.Target != "" {
+		args = append(args, "--target", options.Target)
+	}
+	if options.NetworkMode != "" {
+		args = append(args, "--network", options.NetworkMode)
+	}
+
+	args = append(args, contextPath)
+
+	env := b.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Env = append(env, "DOCKER_BUILDKIT=1")
+	// BuildKit prints the build errors to stderr, the tail is kept to recognize errors like rate limited pulls
+	stderrTail := &tailWriter{size: 4096}
+	cmd.Stdout = stdout
+	cmd.Stderr = io.MultiWriter(stderr, stderrTail)
+
+	err := cmd.Run()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if dockerclient.IsRateLimitError(string(stderrTail.buf)) {
+			return errors.Errorf("Error running docker build with BuildKit: %v (pull rate limit exceeded)", err)
+		}
+
+		return errors.Errorf("Error running docker build with BuildKit: %v", err)
+	}
+
+	return nil
+}
+
 // Authenticate authenticates the client with a remote registry
 func (b *Builder) Authenticate(user, password string, checkCredentialsStore bool) (*types.AuthConfig, error) {
 	var err error
@@ -146,8 +242,7 @@ func (b *Builder) Authenticate(user, password string, checkCredentialsStore bool
 }
 
 // PushImage pushes an image to the specified registry
-func (b *Builder) PushImage() error {
-	ctx := context.Background()
+func (b *Builder) PushImage(ctx context.Context) error {
 	ref, err := reference.ParseNormalizedNamed(b.imageURL)
 	if err != nil {
 		return err
@@ -162,18 +257,50 @@ func (b *Builder) PushImage() error {
 		RegistryAuth: encodedAuth,
 	})
 	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
 		return err
 	}
+	defer out.Close()
+
+	// The registry reports the digest of the pushed image as aux message
+	pushResult := types.PushResult{}
+	auxCallback := func(aux *json.RawMessage) {
+		if aux != nil && json.Unmarshal(*aux, &pushResult) == nil && pushResult.Digest != "" {
+			b.PushedDigest = pushResult.Digest
+		}
+	}
 
 	outStream := command.NewOutStream(stdout)
-	err = jsonmessage.DisplayJSONMessagesStream(out, outStream, outStream.FD(), outStream.IsTerminal(), nil)
+	err = jsonmessage.DisplayJSONMessagesStream(out, outStream, outStream.FD(), outStream.IsTerminal(), auxCallback)
 	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
 		return err
 	}
 
 	return nil
 }
 
+// tailWriter keeps the last size bytes written to it
+type tailWriter struct {
+	buf  []byte
+	size int
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > w.size {
+		w.buf = w.buf[len(w.buf)-w.size:]
+	}
+
+	return len(p), nil
+}
+
 func encodeAuthToBase64(authConfig types.AuthConfig) (string, error) {
 	buf, err := json.Marshal(authConfig)
 	if err != nil {
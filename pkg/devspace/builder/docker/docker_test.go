@@ -0,0 +1,76 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// cancelObservingClient blocks every image build until the context of the build is cancelled
+type cancelObservingClient struct {
+	client.CommonAPIClient
+
+	buildStarted chan bool
+}
+
+func (c *cancelObservingClient) ImageBuild(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error) {
+	c.buildStarted <- true
+
+	<-ctx.Done()
+	return types.ImageBuildResponse{}, ctx.Err()
+}
+
+func TestBuildImageCancelled(t *testing.T) {
+	contextPath, err := ioutil.TempDir("", "devspace-build-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(contextPath)
+
+	dockerfilePath := filepath.Join(contextPath, "Dockerfile")
+	err = ioutil.WriteFile(dockerfilePath, []byte("FROM alpine\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakeClient := &cancelObservingClient{
+		buildStarted: make(chan bool, 1),
+	}
+
+	builder, err := NewBuilder(fakeClient, "", "test", "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	buildErr := make(chan error, 1)
+	go func() {
+		buildErr <- builder.BuildImage(ctx, contextPath, dockerfilePath, nil)
+	}()
+
+	select {
+	case <-fakeClient.buildStarted:
+	case err := <-buildErr:
+		t.Fatalf("Build returned before the image build was started: %v", err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("Image build wasn't started")
+	}
+
+	cancel()
+
+	select {
+	case err := <-buildErr:
+		if err != context.Canceled {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Build didn't stop after the context was cancelled")
+	}
+}
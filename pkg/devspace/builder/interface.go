@@ -1,10 +1,15 @@
 package builder
 
-import "github.com/docker/docker/api/types"
+import (
+	"context"
 
-// Interface defines methods for builders (e.g. docker, kaniko)
+	"github.com/docker/docker/api/types"
+)
+
+// Interface defines methods for builders (e.g. docker, kaniko). BuildImage and PushImage stop as soon as the context
+// is cancelled
 type Interface interface {
 	Authenticate(username, password string, checkCredentialsStore bool) (*types.AuthConfig, error)
-	BuildImage(contextPath, dockerfilePath string, options *types.ImageBuildOptions) error
-	PushImage() error
+	BuildImage(ctx context.Context, contextPath, dockerfilePath string, options *types.ImageBuildOptions) error
+	PushImage(ctx context.Context) error
 }
@@ -1,6 +1,7 @@
 package kaniko
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -13,14 +14,20 @@ import (
 	"github.com/covexo/devspace/pkg/util/ignoreutil"
 	"github.com/covexo/devspace/pkg/util/log"
 	"github.com/covexo/devspace/pkg/util/randutil"
+	"github.com/covexo/devspace/pkg/util/signalutil"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 	k8sv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/kubernetes/pkg/util/interrupt"
 )
 
+// kanikoImage is the executor image of the build pod
+const kanikoImage = "gcr.io/kaniko-project/executor:debug-5ac29a97734170a0547fea33b348dc7c328e2f8a"
+
+// kanikoMirrorImage is the executor image used with a registry mirror, older executors don't support --registry-mirror
+const kanikoMirrorImage = "gcr.io/kaniko-project/executor:debug-v0.10.0"
+
 // Builder holds the necessary information to build and push docker images
 type Builder struct {
 	RegistryURL      string
@@ -30,13 +37,16 @@ type Builder struct {
 	PreviousImageTag string
 	BuildNamespace   string
 
+	// RegistryMirror is the docker hub mirror kaniko pulls the base images from (empty for docker hub)
+	RegistryMirror string
+
 	allowInsecureRegistry bool
-	kubectl               *kubernetes.Clientset
+	kubectl               kubernetes.Interface
 	dockerClient          client.CommonAPIClient
 }
 
 // NewBuilder creates a new kaniko.Builder instance
-func NewBuilder(registryURL, pullSecretName, imageName, imageTag, lastImageTag, buildNamespace string, dockerClient client.CommonAPIClient, kubectl *kubernetes.Clientset, allowInsecureRegistry bool) (*Builder, error) {
+func NewBuilder(registryURL, pullSecretName, imageName, imageTag, lastImageTag, buildNamespace string, dockerClient client.CommonAPIClient, kubectl kubernetes.Interface, allowInsecureRegistry bool) (*Builder, error) {
 	return &Builder{
 		RegistryURL:           registryURL,
 		PullSecretName:        pullSecretName,
@@ -76,13 +86,19 @@ func (b *Builder) Authenticate(username, password string, checkCredentialsStore
 	return nil, registry.CreatePullSecret(b.kubectl, b.BuildNamespace, b.RegistryURL, username, password, email, log.GetInstance())
 }
 
-// BuildImage builds a dockerimage within a kaniko pod
-func (b *Builder) BuildImage(contextPath, dockerfilePath string, options *types.ImageBuildOptions) error {
+// BuildImage builds a dockerimage within a kaniko pod. The build pod is deleted as soon as the build finishes or the
+// context is cancelled
+func (b *Builder) BuildImage(ctx context.Context, contextPath, dockerfilePath string, options *types.ImageBuildOptions) error {
 	pullSecretName := registry.GetRegistryAuthSecretName(b.RegistryURL)
 	if b.PullSecretName != "" {
 		pullSecretName = b.PullSecretName
 	}
 
+	executorImage := kanikoImage
+	if b.RegistryMirror != "" {
+		executorImage = kanikoMirrorImage
+	}
+
 	randString, _ := randutil.GenerateRandomString(12)
 	buildID := strings.ToLower(randString)
 	buildPod := &k8sv1.Pod{
@@ -96,7 +112,7 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, options *types.
 			Containers: []k8sv1.Container{
 				{
 					Name:            "kaniko",
-					Image:           "gcr.io/kaniko-project/executor:debug-5ac29a97734170a0547fea33b348dc7c328e2f8a",
+					Image:           executorImage,
 					ImagePullPolicy: k8sv1.PullIfNotPresent,
 					Command: []string{
 						"/busybox/sleep",
@@ -144,9 +160,7 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, options *types.
 		}
 	}
 
-	intr := interrupt.New(nil, deleteBuildPod)
-
-	err := intr.Run(func() error {
+	build := func() error {
 		buildPodCreated, buildPodCreateErr := b.kubectl.Core().Pods(b.BuildNamespace).Create(buildPod)
 
 		if buildPodCreateErr != nil {
@@ -167,7 +181,12 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, options *types.
 				break
 			}
 
-			time.Sleep(readyCheckInterval)
+			err := signalutil.Sleep(ctx, readyCheckInterval)
+			if err != nil {
+				log.StopWait()
+				return err
+			}
+
 			readyWaitTime = readyWaitTime - readyCheckInterval
 		}
 
@@ -224,6 +243,10 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, options *types.
 			kanikoBuildCmd = append(kanikoBuildCmd, "--insecure", "--skip-tls-verify")
 		}
 
+		if b.RegistryMirror != "" {
+			kanikoBuildCmd = append(kanikoBuildCmd, "--registry-mirror="+b.RegistryMirror)
+		}
+
 		stdin, stdout, stderr, execErr := kubectl.Exec(b.kubectl, buildPod, buildContainer.Name, kanikoBuildCmd, false, exitChannel)
 		stdin.Close()
 
@@ -231,9 +254,20 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, options *types.
 			return fmt.Errorf("Failed to start image building: %s", execErr.Error())
 		}
 
-		lastKanikoOutput := formatKanikoOutput(stdout, stderr)
-		exitError := <-exitChannel
+		kanikoOutput := make(chan string, 1)
+		go func() {
+			kanikoOutput <- formatKanikoOutput(stdout, stderr)
+		}()
+
+		var exitError error
+		select {
+		case <-ctx.Done():
+			log.StopWait()
+			return ctx.Err()
+		case exitError = <-exitChannel:
+		}
 
+		lastKanikoOutput := <-kanikoOutput
 		log.StopWait()
 
 		if exitError != nil {
@@ -243,16 +277,13 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, options *types.
 		log.Done("Done building image")
 
 		return nil
-	})
-
-	if err != nil {
-		return err
 	}
 
-	return nil
+	defer deleteBuildPod()
+	return build()
 }
 
 // PushImage is required to implement builder.Interface
-func (b *Builder) PushImage() error {
+func (b *Builder) PushImage(ctx context.Context) error {
 	return nil
 }
@@ -0,0 +1,84 @@
+package cloud
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// providerCheckTimeout is the maximum time we wait for the host of a provider to respond
+const providerCheckTimeout = 10 * time.Second
+
+// SetProvider adds the provider to the cloud config or updates its host. The host has to be reachable. The token of an
+// existing provider is kept if the host doesn't change, otherwise the user has to login again
+func SetProvider(name, host string) error {
+	if name == "" {
+		return errors.New("Provider name must not be empty")
+	}
+	if name == DevSpaceCloudProviderName {
+		return fmt.Errorf("The host of %s cannot be changed", DevSpaceCloudProviderName)
+	}
+
+	host, err := normalizeHost(host)
+	if err != nil {
+		return err
+	}
+
+	err = checkHost(host)
+	if err != nil {
+		return err
+	}
+
+	providerConfig, err := ParseCloudConfig()
+	if err != nil {
+		return fmt.Errorf("Error loading cloud config: %v", err)
+	}
+
+	provider, ok := providerConfig[name]
+	if ok == false || provider.Host != host {
+		providerConfig[name] = &Provider{
+			Name: name,
+			Host: host,
+		}
+	}
+
+	err = SaveCloudConfig(providerConfig)
+	if err != nil {
+		return fmt.Errorf("Error saving cloud config: %v", err)
+	}
+
+	return nil
+}
+
+// normalizeHost checks that the host is an absolute http(s) url and removes trailing slashes, because the endpoints
+// are appended to the host
+func normalizeHost(host string) (string, error) {
+	parsedURL, err := url.Parse(host)
+	if err != nil {
+		return "", fmt.Errorf("Invalid host %s: %v", host, err)
+	}
+	if (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return "", fmt.Errorf("Invalid host %s: expected an url like https://my-provider.com", host)
+	}
+
+	return strings.TrimRight(host, "/"), nil
+}
+
+// checkHost returns an error if the host doesn't respond to a GET request in time. Every http response counts as
+// reachable, because providers don't need to serve anything at their root path
+func checkHost(host string) error {
+	client := &http.Client{
+		Timeout: providerCheckTimeout,
+	}
+
+	resp, err := client.Get(host)
+	if err != nil {
+		return fmt.Errorf("Host %s is not reachable: %v", host, err)
+	}
+
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,103 @@
+package configutil
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+)
+
+// MaxConfigBackups is the number of config backups that are kept
+const MaxConfigBackups = 5
+
+// ConfigBackup describes a backup of the config file
+type ConfigBackup struct {
+	Number  int
+	Path    string
+	ModTime time.Time
+}
+
+// GetConfigBackupPath returns the path of the backup with the given number (1 is the newest backup)
+func GetConfigBackupPath(number int) string {
+	return ConfigPath + ".bak." + strconv.Itoa(number)
+}
+
+// backupConfig saves a copy of the current config file before it is overwritten with newConfig. The existing
+// backups are rotated, so that .bak.1 is always the newest backup
+func backupConfig(newConfig []byte) error {
+	oldConfig, err := ioutil.ReadFile(ConfigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	// Nothing changes, so we don't need a backup
+	if bytes.Equal(oldConfig, newConfig) {
+		return nil
+	}
+
+	os.Remove(GetConfigBackupPath(MaxConfigBackups))
+	for number := MaxConfigBackups - 1; number > 0; number-- {
+		err = os.Rename(GetConfigBackupPath(number), GetConfigBackupPath(number+1))
+		if err != nil && os.IsNotExist(err) == false {
+			return err
+		}
+	}
+
+	return ioutil.WriteFile(GetConfigBackupPath(1), oldConfig, os.ModePerm)
+}
+
+// GetConfigBackups returns all existing backups of the config file, the newest backup first
+func GetConfigBackups() ([]*ConfigBackup, error) {
+	backups := []*ConfigBackup{}
+
+	for number := 1; number <= MaxConfigBackups; number++ {
+		path := GetConfigBackupPath(number)
+
+		stat, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		backups = append(backups, &ConfigBackup{
+			Number:  number,
+			Path:    path,
+			ModTime: stat.ModTime(),
+		})
+	}
+
+	return backups, nil
+}
+
+// RestoreConfigBackup overwrites the config file with the backup with the given number. The current config file
+// is backed up as well, so the restore can be undone
+func RestoreConfigBackup(number int) error {
+	if number < 1 || number > MaxConfigBackups {
+		return fmt.Errorf("Backup number has to be between 1 and %d", MaxConfigBackups)
+	}
+
+	backup, err := ioutil.ReadFile(GetConfigBackupPath(number))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("Backup %s does not exist", GetConfigBackupPath(number))
+		}
+
+		return err
+	}
+
+	err = backupConfig(backup)
+	if err != nil {
+		return fmt.Errorf("Error backing up current config: %v", err)
+	}
+
+	return ioutil.WriteFile(ConfigPath, backup, os.ModePerm)
+}
@@ -0,0 +1,168 @@
+package configutil
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// diffContextLines is the number of unchanged lines shown around every change (same as git diff)
+const diffContextLines = 3
+
+// diffLine is a single line of a line diff
+type diffLine struct {
+	operation diffmatchpatch.Operation
+	text      string
+}
+
+// Diff returns a unified diff between the config file at ConfigPath and newConfig. Both configs are converted to
+// yaml the same way, so that only changed values show up and not comments or formatting. A config file that doesn't
+// exist is treated as empty config. It returns an empty string if there are no differences
+func Diff(newConfig *v1.Config) (string, error) {
+	savedConfig, err := loadConfigFile(ConfigPath)
+	if os.IsNotExist(err) {
+		savedConfig = nil
+	} else if err != nil {
+		return "", fmt.Errorf("Error loading %s: %v", ConfigPath, err)
+	}
+
+	savedYaml, err := configToYaml(savedConfig)
+	if err != nil {
+		return "", err
+	}
+
+	newYaml, err := configToYaml(newConfig)
+	if err != nil {
+		return "", err
+	}
+
+	return unifiedDiff(ConfigPath, savedYaml, newYaml), nil
+}
+
+func configToYaml(config *v1.Config) (string, error) {
+	if config == nil {
+		return "", nil
+	}
+
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// unifiedDiff returns the line diff of both texts in the unified format of git diff (a/ is the old, b/ the new text)
+func unifiedDiff(path, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	lines := diffLines(oldText, newText)
+
+	buffer := &bytes.Buffer{}
+	fmt.Fprintf(buffer, "--- a/%s\n+++ b/%s\n", path, path)
+
+	// The line numbers of every diff line in the old and new text
+	oldLines, newLines := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	oldLines[0], newLines[0] = 1, 1
+	for index, line := range lines {
+		oldLines[index+1], newLines[index+1] = oldLines[index], newLines[index]
+		if line.operation != diffmatchpatch.DiffInsert {
+			oldLines[index+1]++
+		}
+		if line.operation != diffmatchpatch.DiffDelete {
+			newLines[index+1]++
+		}
+	}
+
+	hunkEnd := 0
+	for change := nextChange(lines, 0); change < len(lines); change = nextChange(lines, hunkEnd) {
+		hunkStart := change - diffContextLines
+		if hunkStart < hunkEnd {
+			hunkStart = hunkEnd
+		}
+
+		// Changes with at most two times the context lines between them are part of the same hunk
+		lastChange := change
+		for next := nextChange(lines, lastChange+1); next < len(lines) && next-lastChange <= 2*diffContextLines+1; next = nextChange(lines, lastChange+1) {
+			lastChange = next
+		}
+
+		hunkEnd = lastChange + 1 + diffContextLines
+		if hunkEnd > len(lines) {
+			hunkEnd = len(lines)
+		}
+
+		oldCount := oldLines[hunkEnd] - oldLines[hunkStart]
+		newCount := newLines[hunkEnd] - newLines[hunkStart]
+		fmt.Fprintf(buffer, "@@ -%s +%s @@\n", hunkRange(oldLines[hunkStart], oldCount), hunkRange(newLines[hunkStart], newCount))
+
+		for _, line := range lines[hunkStart:hunkEnd] {
+			switch line.operation {
+			case diffmatchpatch.DiffEqual:
+				buffer.WriteString(" " + line.text)
+			case diffmatchpatch.DiffDelete:
+				buffer.WriteString("-" + line.text)
+			case diffmatchpatch.DiffInsert:
+				buffer.WriteString("+" + line.text)
+			}
+		}
+	}
+
+	return buffer.String()
+}
+
+// nextChange returns the index of the first added or removed line at or after start or len(lines) if there is none
+func nextChange(lines []diffLine, start int) int {
+	for index := start; index < len(lines); index++ {
+		if lines[index].operation != diffmatchpatch.DiffEqual {
+			return index
+		}
+	}
+
+	return len(lines)
+}
+
+// diffLines splits the diff of both texts into lines. Every line ends with a newline
+func diffLines(oldText, newText string) []diffLine {
+	dmp := diffmatchpatch.New()
+	oldChars, newChars, lineArray := dmp.DiffLinesToChars(oldText, newText)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(oldChars, newChars, false), lineArray)
+
+	lines := []diffLine{}
+	for _, diff := range diffs {
+		for _, text := range strings.SplitAfter(diff.Text, "\n") {
+			if text == "" {
+				continue
+			}
+			if strings.HasSuffix(text, "\n") == false {
+				text += "\n\\ No newline at end of file\n"
+			}
+
+			lines = append(lines, diffLine{
+				operation: diff.Type,
+				text:      text,
+			})
+		}
+	}
+
+	return lines
+}
+
+// hunkRange returns the start line and the number of lines of a hunk. Empty hunks start at the line before
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+
+	return fmt.Sprintf("%d,%d", start, count)
+}
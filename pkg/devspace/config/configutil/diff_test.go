@@ -0,0 +1,82 @@
+package configutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	oldLines := []string{}
+	for index := 1; index <= 12; index++ {
+		oldLines = append(oldLines, "line"+strings.Repeat("x", index))
+	}
+	newLines := append([]string{}, oldLines...)
+	newLines[1] = "changed"
+	newLines = append(newLines, "added")
+
+	diff := unifiedDiff("config.yaml", strings.Join(oldLines, "\n")+"\n", strings.Join(newLines, "\n")+"\n")
+	expected := `--- a/config.yaml
++++ b/config.yaml
+@@ -1,5 +1,5 @@
+ linex
+-linexx
++changed
+ linexxx
+ linexxxx
+ linexxxxx
+@@ -10,3 +10,4 @@
+ linexxxxxxxxxx
+ linexxxxxxxxxxx
+ linexxxxxxxxxxxx
++added
+`
+	if diff != expected {
+		t.Fatalf("Expected diff:\n%s\nGot:\n%s", expected, diff)
+	}
+
+	if diff := unifiedDiff("config.yaml", "a\n", "a\n"); diff != "" {
+		t.Fatalf("Expected no diff for equal texts, got %s", diff)
+	}
+	if diff := unifiedDiff("config.yaml", "", "a\n"); strings.Contains(diff, "@@ -0,0 +1 @@\n+a\n") == false {
+		t.Fatalf("Unexpected diff for new file: %s", diff)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devspace-config-diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldConfigPath := ConfigPath
+	defer func() { ConfigPath = oldConfigPath }()
+
+	ConfigPath = filepath.Join(dir, "config.yaml")
+	err = ioutil.WriteFile(ConfigPath, []byte("# Comments and formatting are ignored\nversion:   v1alpha1\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newConfig := makeConfig()
+	newConfig.Version = String("v1alpha1")
+	diff, err := Diff(newConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Fatalf("Expected no diff, got %s", diff)
+	}
+
+	newConfig.Cluster.Namespace = String("test")
+	diff, err = Diff(newConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(diff, "+  namespace: test\n") == false {
+		t.Fatalf("Expected added cluster namespace, got %s", diff)
+	}
+}
@@ -1,7 +1,10 @@
 package configutil
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/juju/errors"
@@ -19,6 +22,7 @@ type ConfigInterface interface{}
 const configGitignore = `logs/
 overwrite.yaml
 generated.yaml
+config.yaml.bak.*
 `
 
 // DefaultConfigPath is the default config path to use
@@ -39,6 +43,9 @@ const DefaultDevspaceServiceName = "default"
 // DefaultDevspaceDeploymentName is the name of the initial default deployment
 const DefaultDevspaceDeploymentName = "devspace-default"
 
+// LegacyChartPath is the chart that is deployed if the config doesn't define any deployments
+const LegacyChartPath = "./chart"
+
 // CurrentConfigVersion has the value of the current config version
 const CurrentConfigVersion = "v1alpha1"
 
@@ -77,6 +84,18 @@ func InitConfig() *v1.Config {
 	return config
 }
 
+// ResetConfig discards the loaded config, so that the next call to GetConfig loads it again and sets the defaults
+// again. This is needed to test code that uses the config in isolation
+func ResetConfig() {
+	config = nil
+	configRaw = nil
+	overwriteConfig = nil
+	defaultConfig = nil
+
+	getConfigOnce = sync.Once{}
+	setDefaultsOnce = sync.Once{}
+}
+
 // GetConfig returns the config merged from .devspace/config.yaml and .devspace/overwrite.yaml
 func GetConfig() *v1.Config {
 	GetConfigWithoutDefaults()
@@ -88,29 +107,71 @@ func GetConfig() *v1.Config {
 // GetConfigWithoutDefaults returns the config without setting the default values
 func GetConfigWithoutDefaults() *v1.Config {
 	getConfigOnce.Do(func() {
-		config = makeConfig()
-		overwriteConfig = makeConfig()
-		configRaw = makeConfig()
-		overwriteConfig = makeConfig()
-		defaultConfig = makeConfig()
-
-		err := loadConfig(configRaw, ConfigPath)
+		err := loadConfigs()
 		if err != nil {
-			log.Fatalf("Loading config: %v", err)
+			log.Fatal(err)
 		}
+	})
 
-		if configRaw.Version == nil || *configRaw.Version != CurrentConfigVersion {
-			log.Fatal("Your config is out of date. Please run `devspace init -r` to update your config")
-		}
+	return config
+}
+
+// LoadConfig loads the config like GetConfigWithoutDefaults, but returns the error instead of exiting. It does nothing
+// if the config was loaded already
+func LoadConfig() error {
+	var err error
+	getConfigOnce.Do(func() {
+		err = loadConfigs()
+	})
 
-		//ignore error as overwrite.yaml is optional
-		loadConfig(overwriteConfig, OverwriteConfigPath)
+	return err
+}
+
+// LoadConfigFromJSON loads the config from a json file instead of .devspace/config.yaml. The overwrite config is
+// loaded from OverwriteConfigPath as usual
+func LoadConfigFromJSON(path string) error {
+	if strings.ToLower(filepath.Ext(path)) != ".json" {
+		return fmt.Errorf("Config %s is not a json file", path)
+	}
 
-		Merge(&config, configRaw, false)
-		Merge(&config, overwriteConfig, true)
+	ResetConfig()
+	ConfigPath = path
+
+	var err error
+	getConfigOnce.Do(func() {
+		err = loadConfigs()
 	})
 
-	return config
+	return err
+}
+
+// loadConfigs loads the config from ConfigPath and the overwrite config from OverwriteConfigPath and merges them
+func loadConfigs() error {
+	config = makeConfig()
+	overwriteConfig = makeConfig()
+	configRaw = makeConfig()
+	defaultConfig = makeConfig()
+
+	loadedConfig, err := loadConfigFile(ConfigPath)
+	if err != nil {
+		return fmt.Errorf("Loading config: %v", err)
+	}
+
+	configRaw = loadedConfig
+	if configRaw.Version == nil || *configRaw.Version != CurrentConfigVersion {
+		return errors.New("Your config is out of date. Please run `devspace init -r` to update your config")
+	}
+
+	//ignore error as overwrite.yaml is optional
+	loadedOverwriteConfig, err := loadConfigFile(OverwriteConfigPath)
+	if err == nil {
+		overwriteConfig = loadedOverwriteConfig
+	}
+
+	Merge(&config, configRaw, false)
+	Merge(&config, overwriteConfig, true)
+
+	return nil
 }
 
 // GetOverwriteConfig returns the config retrieved from .devspace/overwrite.yaml
@@ -132,6 +193,25 @@ func SetDefaultsOnce() {
 		if config.DevSpace != nil {
 			needTiller := config.InternalRegistry != nil
 
+			// Configs without deployments deploy the chart/ directory like older versions did
+			if config.DevSpace.Deployments == nil || len(*config.DevSpace.Deployments) == 0 {
+				_, err := os.Stat(filepath.Join(LegacyChartPath, "Chart.yaml"))
+				if err == nil {
+					defaultConfig.DevSpace.Deployments = &[]*v1.DeploymentConfig{
+						{
+							Name:      String(DefaultDevspaceDeploymentName),
+							Namespace: String(""),
+							Helm: &v1.HelmConfig{
+								ChartPath:    String(LegacyChartPath),
+								DevOverwrite: String(filepath.Join(LegacyChartPath, "dev-overwrite.yaml")),
+							},
+						},
+					}
+
+					config.DevSpace.Deployments = defaultConfig.DevSpace.Deployments
+				}
+			}
+
 			if config.DevSpace.Deployments != nil {
 				for index, deployConfig := range *config.DevSpace.Deployments {
 					if deployConfig.Name == nil {
@@ -1,17 +1,75 @@
 package configutil
 
 import (
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"path/filepath"
+	"strings"
 
 	"github.com/covexo/devspace/pkg/devspace/config/v1"
 	yaml "gopkg.in/yaml.v2"
 )
 
-func loadConfig(config *v1.Config, path string) error {
-	yamlFileContent, err := ioutil.ReadFile(path)
+// loadConfigFile loads a yaml (.yaml or .yml) or json (.json) config file depending on the file extension
+func loadConfigFile(path string) (*v1.Config, error) {
+	fileContent, err := ioutil.ReadFile(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return yaml.UnmarshalStrict(yamlFileContent, config)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	case ".json":
+		fileContent, err = jsonToYaml(fileContent)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing %s: %v", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("Config %s has an unsupported file extension (supported: .yaml, .yml, .json)", path)
+	}
+
+	config := makeConfig()
+	err = yaml.UnmarshalStrict(fileContent, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// jsonToYaml converts json to yaml, so that json configs are parsed with the yaml keys of the config structs and
+// unknown keys are rejected like in yaml configs
+func jsonToYaml(jsonContent []byte) ([]byte, error) {
+	var object interface{}
+
+	err := json.Unmarshal(jsonContent, &object)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(object)
+}
+
+// yamlMapToJSON converts the map[interface{}]interface{} values of a yaml map to map[string]interface{}, which can
+// be marshalled with encoding/json
+func yamlMapToJSON(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[interface{}]interface{}:
+		jsonMap := make(map[string]interface{}, len(typedValue))
+		for key, mapValue := range typedValue {
+			jsonMap[fmt.Sprintf("%v", key)] = yamlMapToJSON(mapValue)
+		}
+
+		return jsonMap
+	case []interface{}:
+		jsonSlice := make([]interface{}, len(typedValue))
+		for index, sliceValue := range typedValue {
+			jsonSlice[index] = yamlMapToJSON(sliceValue)
+		}
+
+		return jsonSlice
+	default:
+		return value
+	}
 }
@@ -0,0 +1,112 @@
+package configutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testConfig = `version: v1alpha1
+devSpace:
+  deployments:
+  - name: devspace-default
+    helm:
+      chartPath: ./chart
+  services:
+  - name: default
+    labelSelector:
+      release: devspace-default
+  ports:
+  - service: default
+    portMappings:
+    - localPort: 8080
+      remotePort: 80
+  sync:
+  - service: default
+    localSubPath: ./
+    containerPath: /app
+    excludePaths:
+    - node_modules/
+    bandwidthLimits:
+      download: 1024
+images:
+  default:
+    name: devspace
+    skipPush: true
+    build:
+      dockerfilePath: ./Dockerfile
+`
+
+func TestJSONConfigRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(configPath, overwriteConfigPath string) {
+		ConfigPath = configPath
+		OverwriteConfigPath = overwriteConfigPath
+		ResetConfig()
+	}(ConfigPath, OverwriteConfigPath)
+
+	ConfigPath = filepath.Join(dir, "config.yaml")
+	OverwriteConfigPath = filepath.Join(dir, "overwrite.yaml")
+
+	err = ioutil.WriteFile(ConfigPath, []byte(testConfig), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ResetConfig()
+	GetConfigWithoutDefaults()
+	yamlConfig := configRaw
+
+	err = SaveConfigAsJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsonPath := filepath.Join(dir, "config.json")
+	jsonConfig, err := loadConfigFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(yamlConfig, jsonConfig) == false {
+		t.Fatalf("Config loaded from %s differs from the yaml config", jsonPath)
+	}
+
+	err = LoadConfigFromJSON(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(yamlConfig, configRaw) == false {
+		t.Fatal("Config loaded with LoadConfigFromJSON differs from the yaml config")
+	}
+
+	mappings := *(*GetConfigWithoutDefaults().DevSpace.Ports)[0].PortMappings
+	if *mappings[0].LocalPort != 8080 || *mappings[0].RemotePort != 80 {
+		t.Fatalf("Unexpected port mapping %d:%d", *mappings[0].LocalPort, *mappings[0].RemotePort)
+	}
+}
+
+func TestLoadConfigFileUnknownKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	jsonPath := filepath.Join(dir, "config.json")
+	err = ioutil.WriteFile(jsonPath, []byte(`{"version": "v1alpha1", "devSpaces": {}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = loadConfigFile(jsonPath)
+	if err == nil {
+		t.Fatal("Expected an error for the unknown key devSpaces")
+	}
+}
@@ -1,9 +1,12 @@
 package configutil
 
 import (
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/covexo/devspace/pkg/util/fsutil"
 	yaml "gopkg.in/yaml.v2"
@@ -16,6 +19,49 @@ func SaveConfig() error {
 		return nil
 	}
 
+	configMap, overwriteMap, err := getConfigAndOverwriteMaps()
+	if err != nil {
+		return err
+	}
+
+	configYaml, err := yaml.Marshal(configMap)
+	if err != nil {
+		return err
+	}
+
+	err = writeConfig(ConfigPath, configYaml)
+	if err != nil {
+		return err
+	}
+
+	return saveOverwriteConfig(overwriteMap)
+}
+
+// SaveConfigAsJSON writes the data of a config to a json file. The file is written to ConfigPath with the extension
+// .json (e.g. .devspace/config.json), the overwrite config is written to its yaml file like in SaveConfig
+func SaveConfigAsJSON() error {
+	configMap, overwriteMap, err := getConfigAndOverwriteMaps()
+	if err != nil {
+		return err
+	}
+
+	configJSON, err := json.MarshalIndent(yamlMapToJSON(configMap), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	jsonPath := strings.TrimSuffix(ConfigPath, filepath.Ext(ConfigPath)) + ".json"
+	err = writeConfig(jsonPath, append(configJSON, '\n'))
+	if err != nil {
+		return err
+	}
+
+	return saveOverwriteConfig(overwriteMap)
+}
+
+// getConfigAndOverwriteMaps splits the config into the values that belong to the config file (without default and
+// overwrite values) and the values that belong to the overwrite config file
+func getConfigAndOverwriteMaps() (map[interface{}]interface{}, map[interface{}]interface{}, error) {
 	// default and overwrite values
 	configToIgnore := makeConfig()
 
@@ -24,37 +70,45 @@ func SaveConfig() error {
 
 	// generates config without default and overwrite values
 	configMapRaw, _, err := splitConfigs(config, configRaw, configToIgnore)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// generates overwriteConfig
 	_, overwriteMapRaw, err := splitConfigs(config, configRaw, overwriteConfig)
-
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	configMap, _ := configMapRaw.(map[interface{}]interface{})
 	overwriteMap, _ := overwriteMapRaw.(map[interface{}]interface{})
 
-	configYaml, err := yaml.Marshal(configMap)
-	if err != nil {
-		return err
-	}
+	return configMap, overwriteMap, nil
+}
 
-	configDir := filepath.Dir(ConfigPath)
+// writeConfig writes the config file to path and backs up the previous config if path is the ConfigPath
+func writeConfig(path string, content []byte) error {
+	configDir := filepath.Dir(path)
 	os.MkdirAll(configDir, os.ModePerm)
 
 	// Check if .gitignore exists
-	_, err = os.Stat(filepath.Join(configDir, ".gitignore"))
+	_, err := os.Stat(filepath.Join(configDir, ".gitignore"))
 	if os.IsNotExist(err) {
 		fsutil.WriteToFile([]byte(configGitignore), filepath.Join(configDir, ".gitignore"))
 	}
 
-	writeErr := ioutil.WriteFile(ConfigPath, configYaml, os.ModePerm)
-	if writeErr != nil {
-		return writeErr
+	if path == ConfigPath {
+		err = backupConfig(content)
+		if err != nil {
+			return fmt.Errorf("Error backing up config: %v", err)
+		}
 	}
 
-	if overwriteMap != nil {
+	return ioutil.WriteFile(path, content, os.ModePerm)
+}
+
+func saveOverwriteConfig(overwriteMap map[interface{}]interface{}) error {
+	if overwriteMap != nil && OverwriteConfigPath != "" {
 		overwriteConfigYaml, err := yaml.Marshal(overwriteMap)
 		if err != nil {
 			return err
@@ -0,0 +1,216 @@
+package configutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+)
+
+// ValidationError contains all problems found in the config
+type ValidationError struct {
+	Errors []string
+}
+
+// Error lists all problems, one per line
+func (e *ValidationError) Error() string {
+	return "Invalid config:\n- " + strings.Join(e.Errors, "\n- ")
+}
+
+// configValidator collects the problems of a config, so that all of them are reported at once
+type configValidator struct {
+	config   *v1.Config
+	errors   []string
+	services map[string]bool
+}
+
+// Validate checks the config for errors that would otherwise only show up while deploying or starting the services
+// (e.g. missing names or references to services and registries that don't exist). It returns a *ValidationError
+// that contains all problems or nil if the config is valid
+func Validate(config *v1.Config) error {
+	validator := &configValidator{
+		config:   config,
+		services: map[string]bool{},
+	}
+
+	validator.validateImages()
+	if config.DevSpace != nil {
+		validator.validateDeployments()
+		validator.validateServices()
+		validator.validateSync()
+		validator.validatePorts()
+	}
+
+	if len(validator.errors) > 0 {
+		return &ValidationError{
+			Errors: validator.errors,
+		}
+	}
+
+	return nil
+}
+
+func (v *configValidator) addError(format string, args ...interface{}) {
+	v.errors = append(v.errors, fmt.Sprintf(format, args...))
+}
+
+func (v *configValidator) validateImages() {
+	if v.config.Images == nil {
+		return
+	}
+
+	// Sort the images, so that the errors are always reported in the same order
+	names := make([]string, 0, len(*v.config.Images))
+	for name := range *v.config.Images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		imageConf := (*v.config.Images)[name]
+		if imageConf == nil {
+			v.addError("images.%s is empty", name)
+			continue
+		}
+		if imageConf.Name == nil || *imageConf.Name == "" {
+			v.addError("images.%s.name is missing", name)
+		}
+		if imageConf.Registry != nil {
+			if v.config.Registries == nil || (*v.config.Registries)[*imageConf.Registry] == nil {
+				v.addError("images.%s.registry: registry %s is not defined in registries", name, *imageConf.Registry)
+			}
+		}
+		if imageConf.Build != nil && imageConf.Build.RegistryMirrors != nil {
+			for index, mirrorConf := range *imageConf.Build.RegistryMirrors {
+				if mirrorConf == nil || mirrorConf.URL == nil || *mirrorConf.URL == "" {
+					v.addError("images.%s.build.registryMirrors[%d].url is missing", name, index)
+				}
+			}
+		}
+	}
+}
+
+func (v *configValidator) validateDeployments() {
+	if v.config.DevSpace.Deployments == nil {
+		return
+	}
+
+	names := map[string]bool{}
+	for index, deployConfig := range *v.config.DevSpace.Deployments {
+		if deployConfig == nil {
+			v.addError("devSpace.deployments[%d] is empty", index)
+			continue
+		}
+		if deployConfig.Name == nil || *deployConfig.Name == "" {
+			v.addError("devSpace.deployments[%d].name is missing", index)
+		} else if names[*deployConfig.Name] {
+			v.addError("devSpace.deployments[%d]: deployment %s is defined twice", index, *deployConfig.Name)
+		} else {
+			names[*deployConfig.Name] = true
+		}
+
+		if deployConfig.Helm == nil && deployConfig.Kubectl == nil {
+			v.addError("devSpace.deployments[%d] needs either helm or kubectl", index)
+		} else if deployConfig.Helm != nil && deployConfig.Kubectl != nil {
+			v.addError("devSpace.deployments[%d]: helm and kubectl cannot be used together", index)
+		} else if deployConfig.Helm != nil && (deployConfig.Helm.ChartPath == nil || *deployConfig.Helm.ChartPath == "") {
+			v.addError("devSpace.deployments[%d].helm.chartPath is missing", index)
+		} else if deployConfig.Kubectl != nil && (deployConfig.Kubectl.Manifests == nil || len(*deployConfig.Kubectl.Manifests) == 0) {
+			v.addError("devSpace.deployments[%d].kubectl.manifests is missing", index)
+		}
+	}
+}
+
+func (v *configValidator) validateServices() {
+	if v.config.DevSpace.Services == nil {
+		return
+	}
+
+	for index, serviceConfig := range *v.config.DevSpace.Services {
+		if serviceConfig == nil {
+			v.addError("devSpace.services[%d] is empty", index)
+			continue
+		}
+		if serviceConfig.Name == nil || *serviceConfig.Name == "" {
+			v.addError("devSpace.services[%d].name is missing", index)
+		} else if v.services[*serviceConfig.Name] {
+			v.addError("devSpace.services[%d]: service %s is defined twice", index, *serviceConfig.Name)
+		} else {
+			v.services[*serviceConfig.Name] = true
+		}
+
+		if serviceConfig.LabelSelector == nil || len(*serviceConfig.LabelSelector) == 0 {
+			v.addError("devSpace.services[%d].labelSelector is missing", index)
+		}
+	}
+}
+
+func (v *configValidator) validateSync() {
+	if v.config.DevSpace.Sync == nil {
+		return
+	}
+
+	for index, syncConfig := range *v.config.DevSpace.Sync {
+		if syncConfig == nil {
+			v.addError("devSpace.sync[%d] is empty", index)
+			continue
+		}
+
+		path := fmt.Sprintf("devSpace.sync[%d]", index)
+		if syncConfig.LocalSubPath == nil || *syncConfig.LocalSubPath == "" {
+			v.addError("%s.localSubPath is missing", path)
+		}
+		if syncConfig.ContainerPath == nil || *syncConfig.ContainerPath == "" {
+			v.addError("%s.containerPath is missing", path)
+		}
+
+		v.validateTarget(path, syncConfig.PodName, syncConfig.Service, syncConfig.LabelSelector)
+	}
+}
+
+func (v *configValidator) validatePorts() {
+	if v.config.DevSpace.Ports == nil {
+		return
+	}
+
+	for index, portForwarding := range *v.config.DevSpace.Ports {
+		if portForwarding == nil {
+			v.addError("devSpace.ports[%d] is empty", index)
+			continue
+		}
+
+		path := fmt.Sprintf("devSpace.ports[%d]", index)
+		if portForwarding.ResourceType == nil || *portForwarding.ResourceType == "pod" {
+			v.validateTarget(path, portForwarding.PodName, portForwarding.Service, portForwarding.LabelSelector)
+		} else if *portForwarding.ResourceType == "service" || *portForwarding.ResourceType == "deployment" {
+			if portForwarding.ResourceName == nil || *portForwarding.ResourceName == "" {
+				v.addError("%s.resourceName is missing", path)
+			}
+			if portForwarding.Service != nil && *portForwarding.Service != "" {
+				v.addError("%s: service and resourceType %s cannot be used together", path, *portForwarding.ResourceType)
+			}
+		} else {
+			v.addError("%s.resourceType: %s is not supported (supported: pod, service, deployment)", path, *portForwarding.ResourceType)
+		}
+	}
+}
+
+// validateTarget checks that the pod of a sync path or port forwarding is selected by a pod name, a service or a
+// label selector, that the service exists and that a service isn't combined with a label selector
+func (v *configValidator) validateTarget(path string, podName, service *string, labelSelector *map[string]*string) {
+	if podName != nil && *podName != "" {
+		return
+	}
+
+	if service != nil && *service != "" {
+		if v.services[*service] == false {
+			v.addError("%s.service: service %s is not defined in devSpace.services", path, *service)
+		}
+		if labelSelector != nil && len(*labelSelector) > 0 {
+			v.addError("%s: service and labelSelector cannot be used together", path)
+		}
+	} else if labelSelector == nil || len(*labelSelector) == 0 {
+		v.addError("%s needs a podName, service or labelSelector", path)
+	}
+}
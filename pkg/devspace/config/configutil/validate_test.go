@@ -0,0 +1,90 @@
+package configutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+)
+
+func TestValidate(t *testing.T) {
+	config := &v1.Config{
+		Images: &map[string]*v1.ImageConfig{
+			"default": {
+				Name:     String("devspace"),
+				Registry: String("internal"),
+			},
+		},
+		Registries: &map[string]*v1.RegistryConfig{
+			"internal": {
+				URL: String("registry.internal:5000"),
+			},
+		},
+		DevSpace: &v1.DevSpaceConfig{
+			Deployments: &[]*v1.DeploymentConfig{
+				{
+					Name: String("api"),
+					Helm: &v1.HelmConfig{ChartPath: String("chart/")},
+				},
+			},
+			Services: &[]*v1.ServiceConfig{
+				{
+					Name:          String("default"),
+					LabelSelector: &map[string]*string{"release": String("api")},
+				},
+			},
+			Sync: &[]*v1.SyncConfig{
+				{
+					Service:       String("default"),
+					LocalSubPath:  String("./"),
+					ContainerPath: String("/app"),
+				},
+			},
+			Ports: &[]*v1.PortForwardingConfig{
+				{
+					ResourceType: String("service"),
+					ResourceName: String("api"),
+				},
+			},
+		},
+	}
+
+	err := Validate(config)
+	if err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+
+	(*config.Images)["default"].Registry = String("missing")
+	*config.DevSpace.Deployments = append(*config.DevSpace.Deployments, &v1.DeploymentConfig{
+		Name:    String("api"),
+		Kubectl: &v1.KubectlConfig{},
+	})
+	(*config.DevSpace.Sync)[0].Service = String("backend")
+	*config.DevSpace.Ports = append(*config.DevSpace.Ports, &v1.PortForwardingConfig{}, &v1.PortForwardingConfig{
+		Service:       String("default"),
+		LabelSelector: &map[string]*string{"app": String("api")},
+	}, &v1.PortForwardingConfig{
+		Service:      String("default"),
+		ResourceType: String("deployment"),
+		ResourceName: String("api"),
+	})
+
+	err = Validate(config)
+	validationErr, ok := err.(*ValidationError)
+	if ok == false {
+		t.Fatalf("Expected validation error, got %v", err)
+	}
+
+	expected := []string{
+		"images.default.registry: registry missing is not defined in registries",
+		"devSpace.deployments[1]: deployment api is defined twice",
+		"devSpace.deployments[1].kubectl.manifests is missing",
+		"devSpace.sync[0].service: service backend is not defined in devSpace.services",
+		"devSpace.ports[1] needs a podName, service or labelSelector",
+		"devSpace.ports[2]: service and labelSelector cannot be used together",
+		"devSpace.ports[3]: service and resourceType deployment cannot be used together",
+	}
+	if reflect.DeepEqual(validationErr.Errors, expected) == false {
+		t.Fatalf("Expected errors %v, got %v", expected, validationErr.Errors)
+	}
+}
@@ -10,9 +10,43 @@ import (
 
 // Config specifies the runtime config struct
 type Config struct {
-	ChartHashs             map[string]string `yaml:"chartHashs"`
-	DockerLatestTimestamps map[string]int64  `yaml:"dockerLatestTimestamps"`
-	ImageTags              map[string]string `yaml:"imageTags"`
+	ChartHashs             map[string]string            `yaml:"chartHashs"`
+	ChartDependencies      map[string]map[string]string `yaml:"chartDependencies"`
+	DockerLatestTimestamps map[string]int64             `yaml:"dockerLatestTimestamps"`
+	ImageTags              map[string]string            `yaml:"imageTags"`
+	DeployHash             string                       `yaml:"deployHash,omitempty"`
+	KubectlObjects         map[string][]*KubectlObject  `yaml:"kubectlObjects,omitempty"`
+
+	// InterruptedReleases holds the helm releases whose deployment was cancelled, although tiller might have
+	// completed it
+	InterruptedReleases map[string]bool `yaml:"interruptedReleases,omitempty"`
+
+	// DeployedValues holds the values of the last deployment of each helm release with masked secrets
+	DeployedValues map[string]map[interface{}]interface{} `yaml:"deployedValues,omitempty"`
+
+	// ImageDigests holds the registry digest of the last pushed image of every image name (only known for images
+	// that were built with docker)
+	ImageDigests map[string]string `yaml:"imageDigests,omitempty"`
+
+	// Releases holds the chart and revision of the last successful deployment of each helm release
+	Releases map[string]*Release `yaml:"releases,omitempty"`
+}
+
+// Release describes the last successful deployment of a helm release
+type Release struct {
+	Namespace    string `yaml:"namespace"`
+	Chart        string `yaml:"chart"`
+	ChartVersion string `yaml:"chartVersion"`
+	Revision     int32  `yaml:"revision"`
+	DeployedAt   string `yaml:"deployedAt"`
+}
+
+// KubectlObject identifies a kubernetes object that was applied by a kubectl deployment
+type KubectlObject struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Namespace  string `yaml:"namespace,omitempty"`
+	Name       string `yaml:"name"`
 }
 
 // ConfigPath is the relative generated config path
@@ -28,6 +62,12 @@ func LoadConfig() (*Config, error) {
 			DockerLatestTimestamps: make(map[string]int64),
 			ImageTags:              make(map[string]string),
 			ChartHashs:             make(map[string]string),
+			ChartDependencies:      make(map[string]map[string]string),
+			KubectlObjects:         make(map[string][]*KubectlObject),
+			InterruptedReleases:    make(map[string]bool),
+			DeployedValues:         make(map[string]map[interface{}]interface{}),
+			ImageDigests:           make(map[string]string),
+			Releases:               make(map[string]*Release),
 		}, nil
 	}
 
@@ -40,12 +80,30 @@ func LoadConfig() (*Config, error) {
 	if config.ChartHashs == nil {
 		config.ChartHashs = make(map[string]string)
 	}
+	if config.ChartDependencies == nil {
+		config.ChartDependencies = make(map[string]map[string]string)
+	}
 	if config.DockerLatestTimestamps == nil {
 		config.DockerLatestTimestamps = make(map[string]int64)
 	}
 	if config.ImageTags == nil {
 		config.ImageTags = make(map[string]string)
 	}
+	if config.KubectlObjects == nil {
+		config.KubectlObjects = make(map[string][]*KubectlObject)
+	}
+	if config.InterruptedReleases == nil {
+		config.InterruptedReleases = make(map[string]bool)
+	}
+	if config.DeployedValues == nil {
+		config.DeployedValues = make(map[string]map[interface{}]interface{})
+	}
+	if config.ImageDigests == nil {
+		config.ImageDigests = make(map[string]string)
+	}
+	if config.Releases == nil {
+		config.Releases = make(map[string]*Release)
+	}
 
 	return config, nil
 }
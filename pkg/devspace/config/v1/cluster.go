@@ -9,6 +9,7 @@ type Cluster struct {
 	APIServer                 *string      `yaml:"apiServer,omitempty"`
 	CaCert                    *string      `yaml:"caCert,omitempty"`
 	User                      *ClusterUser `yaml:"user,omitempty"`
+	RegistryMirror            *string      `yaml:"registryMirror,omitempty"`
 }
 
 //ClusterUser is a user with its username and its client certificate
@@ -10,12 +10,15 @@ type DeploymentConfig struct {
 
 // HelmConfig defines the specific helm options used during deployment
 type HelmConfig struct {
-	ChartPath    *string `yaml:"chartPath,omitempty"`
-	DevOverwrite *string `yaml:"devOverwrite,omitempty"`
+	ChartPath         *string `yaml:"chartPath,omitempty"`
+	DevOverwrite      *string `yaml:"devOverwrite,omitempty"`
+	ReuseValues       *bool   `yaml:"reuseValues,omitempty"`
+	RollbackOnFailure *bool   `yaml:"rollbackOnFailure,omitempty"`
 }
 
 // KubectlConfig defines the specific kubectl options used during deployment
 type KubectlConfig struct {
-	CmdPath   *string    `yaml:"cmdPath,omitempty"`
-	Manifests *[]*string `yaml:"manifests,omitempty"`
+	CmdPath         *string    `yaml:"cmdPath,omitempty"`
+	Manifests       *[]*string `yaml:"manifests,omitempty"`
+	ServerSideApply *bool      `yaml:"serverSideApply,omitempty"`
 }
@@ -2,11 +2,27 @@ package v1
 
 //DevSpaceConfig defines the devspace deployment
 type DevSpaceConfig struct {
-	Terminal    *Terminal                `yaml:"terminal"`
-	Services    *[]*ServiceConfig        `yaml:"services,omitempty"`
-	Deployments *[]*DeploymentConfig     `yaml:"deployments,omitempty"`
-	Ports       *[]*PortForwardingConfig `yaml:"ports"`
-	Sync        *[]*SyncConfig           `yaml:"sync"`
+	Terminal       *Terminal                `yaml:"terminal"`
+	Services       *[]*ServiceConfig        `yaml:"services,omitempty"`
+	Deployments    *[]*DeploymentConfig     `yaml:"deployments,omitempty"`
+	Ports          *[]*PortForwardingConfig `yaml:"ports"`
+	Sync           *[]*SyncConfig           `yaml:"sync"`
+	SyncStartDelay *int                     `yaml:"syncStartDelay,omitempty"`
+	Open           *OpenConfig              `yaml:"open,omitempty"`
+	Preview        *PreviewConfig           `yaml:"preview,omitempty"`
+	Release        *ReleaseConfig           `yaml:"release,omitempty"`
+}
+
+// ReleaseConfig defines how devspace prepares the cluster for the deployments
+type ReleaseConfig struct {
+	CreateNamespace *bool `yaml:"createNamespace,omitempty"`
+}
+
+// PreviewConfig defines the naming of the preview environments of devspace deploy --preview
+type PreviewConfig struct {
+	Namespace *string             `yaml:"namespace,omitempty"`
+	Values    *map[string]*string `yaml:"values,omitempty"`
+	TTL       *string             `yaml:"ttl,omitempty"`
 }
 
 // ServiceConfig defines the ports for a port forwarding to a DevSpace
@@ -23,7 +39,11 @@ type PortForwardingConfig struct {
 	Service       *string             `yaml:"service,omitempty"`
 	Namespace     *string             `yaml:"namespace,omitempty"`
 	ResourceType  *string             `yaml:"resourceType,omitempty"`
+	ResourceName  *string             `yaml:"resourceName,omitempty"`
 	LabelSelector *map[string]*string `yaml:"labelSelector"`
+	PodName       *string             `yaml:"podName,omitempty"`
+	BindAddresses *[]string           `yaml:"bindAddresses,omitempty"`
+	Reverse       *bool               `yaml:"reverse,omitempty"`
 	PortMappings  *[]*PortMapping     `yaml:"portMappings"`
 }
 
@@ -33,11 +53,18 @@ type PortMapping struct {
 	RemotePort *int `yaml:"remotePort"`
 }
 
+// OpenConfig defines the url that is opened in the browser after the port forwarding was started
+type OpenConfig struct {
+	URL  *string `yaml:"url,omitempty"`
+	Port *int    `yaml:"port,omitempty"`
+}
+
 // SyncConfig defines the paths for a SyncFolder
 type SyncConfig struct {
 	Service              *string             `yaml:"service,omitempty"`
 	Namespace            *string             `yaml:"namespace,omitempty"`
 	LabelSelector        *map[string]*string `yaml:"labelSelector"`
+	PodName              *string             `yaml:"podName,omitempty"`
 	ContainerName        *string             `yaml:"containerName,omitempty"`
 	LocalSubPath         *string             `yaml:"localSubPath"`
 	ContainerPath        *string             `yaml:"containerPath"`
@@ -45,6 +72,16 @@ type SyncConfig struct {
 	DownloadExcludePaths *[]string           `yaml:"downloadExcludePaths"`
 	UploadExcludePaths   *[]string           `yaml:"uploadExcludePaths"`
 	BandwidthLimits      *BandwidthLimits    `yaml:"bandwidthLimits,omitempty"`
+	ConflictPolicy       *string             `yaml:"conflictPolicy,omitempty"`
+	TraceFile            *string             `yaml:"traceFile,omitempty"`
+	Reconnect            *bool               `yaml:"reconnect,omitempty"`
+	OnUpload             *[]string           `yaml:"onUpload,omitempty"`
+	OnDownload           *[]string           `yaml:"onDownload,omitempty"`
+	OnUploadSignal       *string             `yaml:"onUploadSignal,omitempty"`
+	HookDelay            *int                `yaml:"hookDelay,omitempty"`
+	PollingInterval      *int                `yaml:"pollingInterval,omitempty"`
+	FileFilter           *string             `yaml:"fileFilter,omitempty"`
+	PreservePermissions  *bool               `yaml:"preservePermissions,omitempty"`
 }
 
 // BandwidthLimits defines the struct for specifying the sync bandwidth limits
@@ -18,6 +18,17 @@ type BuildConfig struct {
 	Kaniko         *KanikoConfig `yaml:"kaniko,omitempty"`
 	Docker         *DockerConfig `yaml:"docker,omitempty"`
 	Options        *BuildOptions `yaml:"options,omitempty"`
+
+	// RegistryMirrors are pull-through caches for the base images. RewriteBaseImages replaces the registry of the
+	// base images in the Dockerfile with the mirror
+	RegistryMirrors   *[]*RegistryMirrorConfig `yaml:"registryMirrors,omitempty"`
+	RewriteBaseImages *bool                    `yaml:"rewriteBaseImages,omitempty"`
+}
+
+// RegistryMirrorConfig defines a mirror of an upstream registry (default: docker hub)
+type RegistryMirrorConfig struct {
+	URL      *string `yaml:"url"`
+	Registry *string `yaml:"registry,omitempty"`
 }
 
 // KanikoConfig tells the DevSpace CLI to build with Docker on Minikube or on localhost
@@ -29,7 +40,9 @@ type KanikoConfig struct {
 
 // DockerConfig tells the DevSpace CLI to build with Docker on Minikube or on localhost
 type DockerConfig struct {
-	PreferMinikube *bool `yaml:"preferMinikube,omitempty"`
+	PreferMinikube      *bool `yaml:"preferMinikube,omitempty"`
+	BuildKit            *bool `yaml:"buildKit,omitempty"`
+	BuildKitInlineCache *bool `yaml:"buildKitInlineCache,omitempty"`
 }
 
 //BuildOptions defines options for building Docker images
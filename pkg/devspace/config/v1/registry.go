@@ -2,13 +2,26 @@ package v1
 
 //RegistryConfig defines the registry service
 type RegistryConfig struct {
-	URL      *string       `yaml:"url,omitempty"`
-	Auth     *RegistryAuth `yaml:"auth,omitempty"`
-	Insecure *bool         `yaml:"insecure,omitempty"`
+	URL             *string       `yaml:"url,omitempty"`
+	Auth            *RegistryAuth `yaml:"auth,omitempty"`
+	UseDockerConfig *bool         `yaml:"useDockerConfig,omitempty"`
+	Insecure        *bool         `yaml:"insecure,omitempty"`
+	ECR             *ECRConfig    `yaml:"ecr,omitempty"`
+}
+
+// ECRConfig defines the amazon ecr registry the authorization tokens are requested for. Region and account id are
+// read from the registry url if they are not set
+type ECRConfig struct {
+	Region    *string `yaml:"region,omitempty"`
+	AccountID *string `yaml:"accountId,omitempty"`
 }
 
 //RegistryAuth is a user for the registry
 type RegistryAuth struct {
+	Type     *string `yaml:"type,omitempty"`
 	Username *string `yaml:"username"`
 	Password *string `yaml:"password"`
+
+	// PasswordFile is read every time the credentials are used, so mounted short-lived tokens stay up to date
+	PasswordFile *string `yaml:"passwordFile,omitempty"`
 }
@@ -11,12 +11,32 @@ type Config struct {
 	Registries       *map[string]*RegistryConfig `yaml:"registries,omitempty"`
 	Cluster          *Cluster                    `yaml:"cluster,omitempty"`
 	Tiller           *TillerConfig               `yaml:"tiller,omitempty"`
+	Helm             *HelmClientConfig           `yaml:"helm,omitempty"`
 	InternalRegistry *InternalRegistryConfig     `yaml:"internalRegistry,omitempty"`
 }
 
 // TillerConfig defines the tiller service
 type TillerConfig struct {
-	Namespace *string `yaml:"namespace,omitempty"`
+	Namespace  *string `yaml:"namespace,omitempty"`
+	Tillerless *bool   `yaml:"tillerless,omitempty"`
+	Image      *string `yaml:"image,omitempty"`
+	Version    *string `yaml:"version,omitempty"`
+}
+
+// HelmClientConfig defines the options of the helm client
+type HelmClientConfig struct {
+	Repositories *[]*HelmRepositoryConfig `yaml:"repositories,omitempty"`
+}
+
+// HelmRepositoryConfig defines an additional chart repository. Environment variables (${VAR}) in the url, the
+// credentials and the ca file are resolved when the repository is added, so that they are never saved in the config
+type HelmRepositoryConfig struct {
+	Name     *string `yaml:"name"`
+	URL      *string `yaml:"url"`
+	Username *string `yaml:"username,omitempty"`
+	Password *string `yaml:"password,omitempty"`
+	Token    *string `yaml:"token,omitempty"`
+	CAFile   *string `yaml:"caFile,omitempty"`
 }
 
 // InternalRegistryConfig defines the internal registry config options
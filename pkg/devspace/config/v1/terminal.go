@@ -8,4 +8,5 @@ type Terminal struct {
 	Namespace     *string             `yaml:"namespace"`
 	ContainerName *string             `yaml:"containerName"`
 	Command       *[]*string          `yaml:"command"`
+	TTY           *bool               `yaml:"tty,omitempty"`
 }
@@ -1,6 +1,7 @@
 package configure
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -21,6 +22,7 @@ import (
 	"github.com/covexo/devspace/pkg/util/yamlutil"
 	"github.com/russross/blackfriday"
 	"github.com/skratchdot/open-golang/open"
+	yaml "gopkg.in/yaml.v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/helm/pkg/repo"
@@ -64,8 +66,27 @@ func AddPackage(skipQuestion bool, appVersion, chartVersion, deployment string,
 		os.Exit(0)
 	}
 
+	log.StartWait("Update chart repositories")
+	err = helm.UpdateRepos()
+	log.StopWait()
+	if err != nil {
+		return fmt.Errorf("Error updating chart repositories: %v", err)
+	}
+
+	chartName, err := selectChart(helm, args[0], skipQuestion, log)
+	if err != nil {
+		return err
+	}
+
+	if chartVersion == "" && appVersion == "" && skipQuestion == false {
+		chartVersion, err = selectChartVersion(helm, chartName)
+		if err != nil {
+			return err
+		}
+	}
+
 	log.StartWait("Search Chart")
-	repo, version, err := helm.SearchChart(args[0], chartVersion, appVersion)
+	repo, version, err := helm.SearchChart(chartName, chartVersion, appVersion)
 	log.StopWait()
 
 	if err != nil {
@@ -152,19 +173,37 @@ func AddPackage(skipQuestion bool, appVersion, chartVersion, deployment string,
 	packageDefaults, hasPackageDefaultValues := packageDefaultMap[packageName]
 
 	if _, ok := valuesYamlContents[packageName]; ok == false {
-		f, err := os.OpenFile(valuesYaml, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
-		if err != nil {
-			return err
+		addValues := true
+		if skipQuestion == false {
+			addValues = *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
+				Question:               "Do you want to add the values of package " + packageName + " with commonly tuned keys to values.yaml? (yes|no)",
+				DefaultValue:           "yes",
+				ValidationRegexPattern: "^(yes|no)",
+			}) == "yes"
 		}
-		defer f.Close()
 
-		packageDefaultValues := "{}"
-		if hasPackageDefaultValues && packageDefaults.values != "" {
-			packageDefaultValues = packageDefaults.values
-		}
+		if addValues {
+			f, err := os.OpenFile(valuesYaml, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
 
-		if _, err = f.WriteString(packageComment + packageName + ":" + packageDefaultValues); err != nil {
-			return err
+			packageDefaultValues := "{}"
+			if hasPackageDefaultValues && packageDefaults.values != "" {
+				packageDefaultValues = packageDefaults.values
+			} else {
+				valuesStub, err := getPackageValuesStub(chartPath, version)
+				if err != nil {
+					log.Warnf("Unable to read the values of package %s: %v", packageName, err)
+				} else if valuesStub != "" {
+					packageDefaultValues = valuesStub
+				}
+			}
+
+			if _, err = f.WriteString(packageComment + packageName + ":" + packageDefaultValues); err != nil {
+				return err
+			}
 		}
 	}
 	serviceLabelSelector := map[string]*string{}
@@ -235,7 +274,126 @@ func AddPackage(skipQuestion bool, appVersion, chartVersion, deployment string,
 	return nil
 }
 
-func redeployAferPackageChange(kubectl *kubernetes.Clientset, deploymentConfig *v1.DeploymentConfig, log log.Logger) error {
+// selectChart searches the stable repository for charts whose name contains the query, shows them with their latest
+// versions and lets the user confirm or choose the chart to add. If no stable chart matches, the query is returned as
+// chart name, so that the other repositories are searched for it
+func selectChart(helm *helmClient.ClientWrapper, query string, skipQuestion bool, log log.Logger) (string, error) {
+	charts, err := helm.SearchStableCharts(query)
+	if err != nil {
+		return "", err
+	}
+	if len(charts) == 0 {
+		return query, nil
+	}
+
+	values := [][]string{}
+	chartNames := []string{}
+	for _, chart := range charts {
+		description := chart.GetDescription()
+		if len(description) > 45 {
+			description = description[:45] + "..."
+		}
+
+		values = append(values, []string{chart.GetName(), chart.GetVersion(), chart.GetAppVersion(), description})
+		chartNames = append(chartNames, chart.GetName())
+	}
+
+	log.PrintTable([]string{"NAME", "CHART VERSION", "APP VERSION", "DESCRIPTION"}, values)
+
+	if skipQuestion {
+		if len(charts) == 1 || chartNames[0] == query {
+			return chartNames[0], nil
+		}
+
+		return "", fmt.Errorf("%d charts match %s, please specify the exact chart name", len(charts), query)
+	}
+
+	if len(charts) == 1 {
+		shouldAdd := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
+			Question:               "Do you want to add chart " + chartNames[0] + " (version " + charts[0].GetVersion() + ")? (yes|no)",
+			DefaultValue:           "yes",
+			ValidationRegexPattern: "^(yes|no)",
+		}) == "yes"
+		if shouldAdd == false {
+			return "", errors.New("No package was added")
+		}
+
+		return chartNames[0], nil
+	}
+
+	return *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
+		Question:     "Which chart do you want to add?",
+		DefaultValue: chartNames[0],
+		Options:      chartNames,
+	}), nil
+}
+
+// selectChartVersion lets the user choose one of the newest versions of the chart, the latest version is the default
+func selectChartVersion(helm *helmClient.ClientWrapper, chartName string) (string, error) {
+	_, versions, err := helm.GetChartVersions(chartName)
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 1 {
+		return versions[0].GetVersion(), nil
+	}
+
+	options := []string{}
+	for _, version := range versions {
+		if len(options) == maxChartVersionOptions {
+			break
+		}
+
+		options = append(options, version.GetVersion())
+	}
+
+	return *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
+		Question:     "Which version of chart " + chartName + " do you want to add? (use --version for older versions)",
+		DefaultValue: options[0],
+		Options:      options,
+	}), nil
+}
+
+// getPackageValuesStub returns the commonly tuned values of the downloaded package chart with their defaults
+func getPackageValuesStub(chartPath string, chartVersion *repo.ChartVersion) (string, error) {
+	content, err := tar.ExtractSingleFileToStringTarGz(filepath.Join(chartPath, "charts", chartVersion.GetName()+"-"+chartVersion.GetVersion()+".tgz"), chartVersion.GetName()+"/values.yaml")
+	if err != nil {
+		return "", err
+	}
+
+	return getValuesStub(content)
+}
+
+// getValuesStub returns the commonly tuned keys of the chart values as yaml that is indented for the package key in
+// the values.yaml of the parent chart. It returns an empty string if the chart values contain none of the keys
+func getValuesStub(chartValues string) (string, error) {
+	values := map[interface{}]interface{}{}
+	err := yaml.Unmarshal([]byte(chartValues), values)
+	if err != nil {
+		return "", err
+	}
+
+	stub := ""
+	for _, key := range commonlyTunedValues {
+		value, ok := values[key]
+		if ok == false || value == nil {
+			continue
+		}
+
+		out, err := yaml.Marshal(map[string]interface{}{key: value})
+		if err != nil {
+			return "", err
+		}
+
+		for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+			stub += "\n  " + line
+		}
+	}
+
+	return stub, nil
+}
+
+func redeployAferPackageChange(kubectl kubernetes.Interface, deploymentConfig *v1.DeploymentConfig, log log.Logger) error {
 	config := configutil.GetConfig()
 	listOptions := metav1.ListOptions{}
 	deploymentNamespace := *deploymentConfig.Namespace
@@ -262,7 +420,7 @@ func redeployAferPackageChange(kubectl *kubernetes.Clientset, deploymentConfig *
 		log.Warnf("Unable to list Kubernetes services: %v", clusterServiceErr)
 	}
 
-	err = deploy.All(kubectl, generatedConfig, true, true, log)
+	err = deploy.All(context.Background(), kubectl, generatedConfig, &deploy.Options{ForceDeploy: true, UseDevOverwrite: true}, log)
 	log.StopWait()
 
 	// Save generated config
@@ -0,0 +1,47 @@
+package configure
+
+import "testing"
+
+const testChartValues = `image:
+  repository: redis
+  tag: 4.0.11
+service:
+  type: ClusterIP
+  port: 6379
+persistence:
+  enabled: true
+  size: 8Gi
+resources: {}
+networkPolicy:
+  enabled: false
+`
+
+func TestGetValuesStub(t *testing.T) {
+	stub, err := getValuesStub(testChartValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+  image:
+    repository: redis
+    tag: 4.0.11
+  resources: {}
+  persistence:
+    enabled: true
+    size: 8Gi
+  service:
+    port: 6379
+    type: ClusterIP`
+	if stub != expected {
+		t.Fatalf("Expected stub:%s\nGot:%s", expected, stub)
+	}
+
+	stub, err = getValuesStub("networkPolicy:\n  enabled: false\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stub != "" {
+		t.Fatalf("Expected an empty stub for values without commonly tuned keys, got:%s", stub)
+	}
+}
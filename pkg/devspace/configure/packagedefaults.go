@@ -15,6 +15,19 @@ const defaultPackageResourceReset = `
       cpu: 0
       memory: 0`
 
+// protectedPodAnnotations marks the pods of stateful packages as protected, so that devspace doesn't sync into them or
+// opens a terminal in them if a label selector matches them by accident
+const protectedPodAnnotations = `
+  podAnnotations:
+    devspace.covexo.com/protected: "true"`
+
+// maxChartVersionOptions is the number of the newest chart versions that are offered when a package is added
+const maxChartVersionOptions = 10
+
+// commonlyTunedValues are the chart values that are added with their defaults to the values.yaml of the parent chart
+// when a package without package defaults is added
+var commonlyTunedValues = []string{"replicaCount", "image", "resources", "persistence", "service"}
+
 type packageDefault struct {
 	serviceSelectors map[string]string
 	values           string
@@ -29,7 +42,7 @@ var packageDefaultMap = map[string]packageDefault{
   mysqlPassword: "YOUR_PASSWORD"             # only set when first starting the mysql server
   persistence:
     enabled: true
-    size: 3Gi` + defaultPackageResourceReset,
+    size: 3Gi` + protectedPodAnnotations + defaultPackageResourceReset,
 	},
 	"mariadb": packageDefault{
 		serviceSelectors: map[string]string{
@@ -78,12 +91,18 @@ var packageDefaultMap = map[string]packageDefault{
       password: "YOUR_PASSWORD"
   persistence:
     enabled: true
-    size: 3Gi`,
+    size: 3Gi` + protectedPodAnnotations,
 	},
 	"redis": packageDefault{
 		values: `
   cluster:
     enabled: true
-    slaveCount: 1`,
+    slaveCount: 1
+  master:
+    podAnnotations:
+      devspace.covexo.com/protected: "true"
+  slave:
+    podAnnotations:
+      devspace.covexo.com/protected: "true"`,
 	},
 }
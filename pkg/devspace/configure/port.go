@@ -1,6 +1,7 @@
 package configure
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -20,8 +21,11 @@ func AddPort(namespace, selector string, args []string) error {
 	if err != nil {
 		return fmt.Errorf("Error parsing selectors: %s", err.Error())
 	}
+	if len(labelSelectorMap) == 0 {
+		return errors.New("Please specify a label selector (e.g. --selector=app=web)")
+	}
 
-	portMappings, err := parsePortMappings(args[0])
+	portMappings, err := ParsePortMappings(args[0])
 	if err != nil {
 		return fmt.Errorf("Error parsing port mappings: %s", err.Error())
 	}
@@ -36,8 +40,8 @@ func AddPort(namespace, selector string, args []string) error {
 	return nil
 }
 
-// RemovePort removes a port from the config
-func RemovePort(removeAll bool, selector string, args []string) error {
+// RemovePort removes the given ports from the port forwardings that match the label selector or the service
+func RemovePort(removeAll bool, selector, service string, args []string) error {
 	config := configutil.GetConfig()
 
 	labelSelectorMap, err := parseSelectors(selector)
@@ -50,45 +54,76 @@ func RemovePort(removeAll bool, selector string, args []string) error {
 		argPorts = args[0]
 	}
 
-	if len(labelSelectorMap) == 0 && removeAll == false && argPorts == "" {
+	if len(labelSelectorMap) == 0 && removeAll == false && argPorts == "" && service == "" {
 		return fmt.Errorf("You have to specify at least one of the supported flags")
 	}
+	if len(labelSelectorMap) > 0 && service != "" {
+		return fmt.Errorf("--selector and --service cannot be used together")
+	}
 
-	ports := strings.Split(argPorts, ",")
+	ports := []string{}
+	if argPorts != "" {
+		ports = strings.Split(argPorts, ",")
+	}
 
 	if config.DevSpace.Ports != nil && len(*config.DevSpace.Ports) > 0 {
-		newPortForwards := make([]*v1.PortForwardingConfig, 0, len(*config.DevSpace.Ports)-1)
+		newPortForwards := removePortForwardings(*config.DevSpace.Ports, removeAll, labelSelectorMap, service, ports)
+		config.DevSpace.Ports = &newPortForwards
 
-		for _, v := range *config.DevSpace.Ports {
-			if removeAll {
-				continue
-			}
+		err = configutil.SaveConfig()
+		if err != nil {
+			return fmt.Errorf("Couldn't save config file: %s", err.Error())
+		}
+	}
 
-			newPortMappings := []*v1.PortMapping{}
+	return nil
+}
 
-			for _, pm := range *v.PortMappings {
-				if containsPort(strconv.Itoa(*pm.LocalPort), ports) || containsPort(strconv.Itoa(*pm.RemotePort), ports) {
-					continue
-				}
+// removePortForwardings removes the given ports from the port forwardings that match the label selector or the service
+// (all port forwardings if both are empty). If no ports are given, the matching port forwardings are removed
+// completely. Port forwardings without label selector (e.g. port forwardings that use a service) never match a label
+// selector. Port forwardings without port mappings left are removed
+func removePortForwardings(portForwardings []*v1.PortForwardingConfig, removeAll bool, labelSelectorMap map[string]*string, service string, ports []string) []*v1.PortForwardingConfig {
+	newPortForwards := make([]*v1.PortForwardingConfig, 0, len(portForwardings))
 
-				newPortMappings = append(newPortMappings, pm)
-			}
+	for _, v := range portForwardings {
+		if removeAll {
+			continue
+		}
 
-			if len(newPortMappings) > 0 {
-				v.PortMappings = &newPortMappings
-				newPortForwards = append(newPortForwards, v)
-			}
+		if len(labelSelectorMap) > 0 && (v.LabelSelector == nil || isMapEqual(*v.LabelSelector, labelSelectorMap) == false) {
+			newPortForwards = append(newPortForwards, v)
+			continue
+		}
+		if service != "" && (v.Service == nil || *v.Service != service) {
+			newPortForwards = append(newPortForwards, v)
+			continue
+		}
+		if len(ports) == 0 {
+			continue
+		}
+		if v.PortMappings == nil {
+			newPortForwards = append(newPortForwards, v)
+			continue
 		}
 
-		config.DevSpace.Ports = &newPortForwards
+		newPortMappings := []*v1.PortMapping{}
 
-		err = configutil.SaveConfig()
-		if err != nil {
-			return fmt.Errorf("Couldn't save config file: %s", err.Error())
+		for _, pm := range *v.PortMappings {
+			if (pm.LocalPort != nil && containsPort(strconv.Itoa(*pm.LocalPort), ports)) || (pm.RemotePort != nil && containsPort(strconv.Itoa(*pm.RemotePort), ports)) {
+				continue
+			}
+
+			newPortMappings = append(newPortMappings, pm)
+		}
+
+		if len(newPortMappings) > 0 {
+			v.PortMappings = &newPortMappings
+			newPortForwards = append(newPortForwards, v)
 		}
 	}
 
-	return nil
+	return newPortForwards
 }
 
 func containsPort(port string, ports []string) bool {
@@ -119,7 +154,10 @@ func insertOrReplacePortMapping(namespace string, labelSelectorMap map[string]*s
 		}
 
 		if isMapEqual(selectors, labelSelectorMap) {
-			portMap := append(*v.PortMappings, portMappings...)
+			portMap := portMappings
+			if v.PortMappings != nil {
+				portMap = append(*v.PortMappings, portMappings...)
+			}
 
 			v.PortMappings = &portMap
 
@@ -142,7 +180,8 @@ func isMapEqual(map1 map[string]*string, map2 map[string]*string) bool {
 	}
 
 	for k, v := range map1 {
-		if *map2[k] != *v {
+		value, ok := map2[k]
+		if ok == false || (v == nil) != (value == nil) || (v != nil && *v != *value) {
 			return false
 		}
 	}
@@ -150,41 +189,47 @@ func isMapEqual(map1 map[string]*string, map2 map[string]*string) bool {
 	return true
 }
 
-func parsePortMappings(portMappingsString string) ([]*v1.PortMapping, error) {
+// ParsePortMappings parses a comma separated list of port mappings (e.g. 8080:80,3000). A single port is used as
+// local and remote port
+func ParsePortMappings(portMappingsString string) ([]*v1.PortMapping, error) {
 	portMappings := make([]*v1.PortMapping, 0, 1)
 	portMappingsSplitted := strings.Split(portMappingsString, ",")
 
 	for _, v := range portMappingsSplitted {
-		portMapping := strings.Split(v, ":")
+		portMapping := strings.Split(strings.TrimSpace(v), ":")
 
 		if len(portMapping) != 1 && len(portMapping) != 2 {
-			return nil, fmt.Errorf("Error parsing port mapping: %s", v)
+			return nil, fmt.Errorf("Invalid port mapping %s (format is local:remote, e.g. 8080:80)", v)
 		}
 
-		portMappingStruct := &v1.PortMapping{}
-		firstPort, err := strconv.Atoi(portMapping[0])
-
+		localPort, err := parsePort(portMapping[0])
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("Invalid port mapping %s: %v", v, err)
 		}
 
-		if len(portMapping) == 1 {
-			portMappingStruct.LocalPort = &firstPort
-
-			portMappingStruct.RemotePort = portMappingStruct.LocalPort
-		} else {
-			portMappingStruct.LocalPort = &firstPort
-
-			secondPort, err := strconv.Atoi(portMapping[1])
-
+		remotePort := localPort
+		if len(portMapping) == 2 {
+			remotePort, err = parsePort(portMapping[1])
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("Invalid port mapping %s: %v", v, err)
 			}
-			portMappingStruct.RemotePort = &secondPort
 		}
 
-		portMappings = append(portMappings, portMappingStruct)
+		portMappings = append(portMappings, &v1.PortMapping{
+			LocalPort:  &localPort,
+			RemotePort: &remotePort,
+		})
 	}
 
 	return portMappings, nil
 }
+
+// parsePort parses a port number between 1 and 65535
+func parsePort(portString string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(portString))
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("'%s' is not a port number between 1 and 65535", portString)
+	}
+
+	return port, nil
+}
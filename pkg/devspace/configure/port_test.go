@@ -0,0 +1,84 @@
+package configure
+
+import (
+	"testing"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+)
+
+func TestParsePortMappings(t *testing.T) {
+	portMappings, err := ParsePortMappings("8080:3000, 9229")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]int{{8080, 3000}, {9229, 9229}}
+	if len(portMappings) != len(expected) {
+		t.Fatalf("Expected %d port mappings, got %d", len(expected), len(portMappings))
+	}
+	for index, ports := range expected {
+		if *portMappings[index].LocalPort != ports[0] || *portMappings[index].RemotePort != ports[1] {
+			t.Fatalf("Expected %d:%d, got %d:%d", ports[0], ports[1], *portMappings[index].LocalPort, *portMappings[index].RemotePort)
+		}
+	}
+
+	for _, invalid := range []string{"", "8080:", "http:80", "8080:80:90", "0:80", "8080:70000"} {
+		_, err := ParsePortMappings(invalid)
+		if err == nil {
+			t.Fatalf("Expected an error for port mapping '%s'", invalid)
+		}
+	}
+}
+
+func getMixedPortForwardings() []*v1.PortForwardingConfig {
+	localPort, remotePort := 8080, 80
+	debugPort := 9229
+
+	return []*v1.PortForwardingConfig{
+		{
+			Service: configutil.String("web"),
+		},
+		{
+			LabelSelector: &map[string]*string{"app": configutil.String("api")},
+			PortMappings: &[]*v1.PortMapping{
+				{LocalPort: &localPort, RemotePort: &remotePort},
+				{LocalPort: &debugPort, RemotePort: &debugPort},
+			},
+		},
+	}
+}
+
+func TestRemovePortForwardingsWithoutLabelSelector(t *testing.T) {
+	portForwardings := removePortForwardings(getMixedPortForwardings(), false, map[string]*string{"app": configutil.String("api")}, "", []string{})
+	if len(portForwardings) != 1 || portForwardings[0].Service == nil {
+		t.Fatalf("Expected only the port forwarding with label selector app=api to be removed, got %v", portForwardings)
+	}
+
+	portForwardings = removePortForwardings(getMixedPortForwardings(), false, map[string]*string{}, "", []string{"80"})
+	if len(portForwardings) != 2 || len(*portForwardings[1].PortMappings) != 1 || *(*portForwardings[1].PortMappings)[0].LocalPort != 9229 {
+		t.Fatalf("Expected only the port mapping 8080:80 to be removed, got %v", portForwardings)
+	}
+
+	portForwardings = removePortForwardings(getMixedPortForwardings(), false, map[string]*string{"app": configutil.String("web")}, "", []string{"9229"})
+	if len(portForwardings) != 2 || len(*portForwardings[1].PortMappings) != 2 {
+		t.Fatalf("Expected no port forwarding to be changed, got %v", portForwardings)
+	}
+
+	portForwardings = removePortForwardings(getMixedPortForwardings(), false, map[string]*string{}, "web", []string{})
+	if len(portForwardings) != 1 || portForwardings[0].LabelSelector == nil {
+		t.Fatalf("Expected only the port forwarding with service web to be removed, got %v", portForwardings)
+	}
+}
+
+func TestIsMapEqual(t *testing.T) {
+	if isMapEqual(map[string]*string{"app": configutil.String("api")}, map[string]*string{"release": configutil.String("api")}) {
+		t.Fatal("Expected maps with different keys not to be equal")
+	}
+	if isMapEqual(map[string]*string{"app": nil}, map[string]*string{"app": configutil.String("api")}) {
+		t.Fatal("Expected maps with a nil value not to be equal")
+	}
+	if isMapEqual(map[string]*string{"app": configutil.String("api")}, map[string]*string{"app": configutil.String("api")}) == false {
+		t.Fatal("Expected maps with the same values to be equal")
+	}
+}
@@ -27,6 +27,9 @@ func AddSyncPath(localPath, containerPath, namespace, selector, excludedPathsStr
 	if err != nil {
 		return fmt.Errorf("Error parsing selectors: %s", err.Error())
 	}
+	if len(labelSelectorMap) == 0 {
+		return errors.New("Please specify a label selector (e.g. --selector=app=web)")
+	}
 
 	excludedPaths := make([]string, 0, 0)
 	if excludedPathsString != "" {
@@ -45,7 +48,7 @@ func AddSyncPath(localPath, containerPath, namespace, selector, excludedPathsStr
 
 	localPath = strings.TrimPrefix(localPath, workdir)
 
-	if containerPath[0] != '/' {
+	if strings.HasPrefix(containerPath, "/") == false {
 		return errors.New("ContainerPath (--container) must start with '/'. Info: There is an issue with MINGW based terminals like git bash")
 	}
 
@@ -67,8 +70,9 @@ func AddSyncPath(localPath, containerPath, namespace, selector, excludedPathsStr
 	return nil
 }
 
-// RemoveSyncPath removes a sync path from the config
-func RemoveSyncPath(removeAll bool, localPath, containerPath, selector string) error {
+// RemoveSyncPath removes the sync paths that match the local path, the container path, the label selector or the
+// service from the config
+func RemoveSyncPath(removeAll bool, localPath, containerPath, selector, service string) error {
 	config := configutil.GetConfig()
 	labelSelectorMap, err := parseSelectors(selector)
 
@@ -76,23 +80,12 @@ func RemoveSyncPath(removeAll bool, localPath, containerPath, selector string) e
 		return fmt.Errorf("Error parsing selectors: %v", err)
 	}
 
-	if len(labelSelectorMap) == 0 && removeAll == false && localPath == "" && containerPath == "" {
+	if len(labelSelectorMap) == 0 && removeAll == false && localPath == "" && containerPath == "" && service == "" {
 		return fmt.Errorf("You have to specify at least one of the supported flags")
 	}
 
 	if config.DevSpace.Sync != nil && len(*config.DevSpace.Sync) > 0 {
-		newSyncPaths := make([]*v1.SyncConfig, 0, len(*config.DevSpace.Sync)-1)
-
-		for _, v := range *config.DevSpace.Sync {
-			if removeAll ||
-				localPath == *v.LocalSubPath ||
-				containerPath == *v.ContainerPath {
-				continue
-			}
-
-			newSyncPaths = append(newSyncPaths, v)
-		}
-
+		newSyncPaths := removeSyncPaths(*config.DevSpace.Sync, removeAll, localPath, containerPath, labelSelectorMap, service)
 		config.DevSpace.Sync = &newSyncPaths
 
 		err = configutil.SaveConfig()
@@ -104,6 +97,27 @@ func RemoveSyncPath(removeAll bool, localPath, containerPath, selector string) e
 	return nil
 }
 
+// removeSyncPaths returns the sync paths that don't match the local path, the container path, the label selector or
+// the service. Sync paths without label selector (e.g. sync paths that use a service) never match a label selector
+func removeSyncPaths(syncPaths []*v1.SyncConfig, removeAll bool, localPath, containerPath string, labelSelectorMap map[string]*string, service string) []*v1.SyncConfig {
+	newSyncPaths := make([]*v1.SyncConfig, 0, len(syncPaths))
+
+	for _, v := range syncPaths {
+		if removeAll ||
+			(localPath != "" && v.LocalSubPath != nil && localPath == *v.LocalSubPath) ||
+			(containerPath != "" && v.ContainerPath != nil && containerPath == *v.ContainerPath) ||
+			(len(labelSelectorMap) > 0 && v.LabelSelector != nil && isMapEqual(*v.LabelSelector, labelSelectorMap)) ||
+			(service != "" && v.Service != nil && service == *v.Service) {
+			continue
+		}
+
+		newSyncPaths = append(newSyncPaths, v)
+	}
+
+	return newSyncPaths
+}
+
+// parseSelectors parses a comma separated key=value selector list (e.g. app=web,release=test)
 func parseSelectors(selectorString string) (map[string]*string, error) {
 	selectorMap := make(map[string]*string)
 
@@ -116,8 +130,8 @@ func parseSelectors(selectorString string) (map[string]*string, error) {
 	for _, v := range selectors {
 		keyValue := strings.Split(v, "=")
 
-		if len(keyValue) != 2 {
-			return nil, fmt.Errorf("Wrong selector format: %s", selectorString)
+		if len(keyValue) != 2 || strings.TrimSpace(keyValue[0]) == "" {
+			return nil, fmt.Errorf("Wrong selector format: %s (expected key=value, e.g. app=web)", selectorString)
 		}
 		selector := strings.TrimSpace(keyValue[1])
 		selectorMap[strings.TrimSpace(keyValue[0])] = &selector
@@ -0,0 +1,64 @@
+package configure
+
+import (
+	"testing"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+)
+
+func TestParseSelectors(t *testing.T) {
+	selectors, err := parseSelectors("app=web, release = test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(selectors) != 2 || *selectors["app"] != "web" || *selectors["release"] != "test" {
+		t.Fatalf("Unexpected selectors %v", selectors)
+	}
+
+	for _, invalid := range []string{"app", "=web", "app=web,", "app=web=test"} {
+		_, err := parseSelectors(invalid)
+		if err == nil {
+			t.Fatalf("Expected an error for selector '%s'", invalid)
+		}
+	}
+}
+
+func getMixedSyncPaths() []*v1.SyncConfig {
+	return []*v1.SyncConfig{
+		{
+			Service:       configutil.String("web"),
+			LocalSubPath:  configutil.String("./src"),
+			ContainerPath: configutil.String("/app/src"),
+		},
+		{
+			LabelSelector: &map[string]*string{"app": configutil.String("api")},
+			LocalSubPath:  configutil.String("./api"),
+			ContainerPath: configutil.String("/app/api"),
+		},
+		{},
+	}
+}
+
+func TestRemoveSyncPathsWithoutLabelSelector(t *testing.T) {
+	syncPaths := removeSyncPaths(getMixedSyncPaths(), false, "", "", map[string]*string{"app": configutil.String("api")}, "")
+	if len(syncPaths) != 2 || syncPaths[0].Service == nil || *syncPaths[0].Service != "web" {
+		t.Fatalf("Expected only the sync path with label selector app=api to be removed, got %v", syncPaths)
+	}
+
+	syncPaths = removeSyncPaths(getMixedSyncPaths(), false, "./src", "/app/api", map[string]*string{}, "")
+	if len(syncPaths) != 1 || syncPaths[0].LocalSubPath != nil {
+		t.Fatalf("Expected the sync paths with local path ./src and container path /app/api to be removed, got %v", syncPaths)
+	}
+
+	syncPaths = removeSyncPaths(getMixedSyncPaths(), false, "", "", map[string]*string{}, "web")
+	if len(syncPaths) != 2 || syncPaths[0].LabelSelector == nil {
+		t.Fatalf("Expected only the sync path with service web to be removed, got %v", syncPaths)
+	}
+
+	syncPaths = removeSyncPaths(getMixedSyncPaths(), true, "", "", map[string]*string{}, "")
+	if len(syncPaths) != 0 {
+		t.Fatalf("Expected all sync paths to be removed, got %v", syncPaths)
+	}
+}
@@ -0,0 +1,160 @@
+package deploy
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/devspace/upgrade"
+	"github.com/covexo/devspace/pkg/util/hash"
+	"github.com/docker/cli/cli/command/image/build"
+	"github.com/docker/docker/pkg/archive"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Hash returns a hash of everything that can change the result of a deployment: the loaded config (including the
+// overwrite config and changes by command line flags), the build contexts and dockerfiles of all images, the helm
+// charts and their overwrite values and the kubectl manifests. Only file contents are hashed, so the hash stays the
+// same for fresh checkouts of the same files. Hash doesn't need any connection to the cluster or the registries
+func Hash() (string, error) {
+	config := configutil.GetConfig()
+	deployHash := sha256.New()
+
+	io.WriteString(deployHash, "version:"+upgrade.GetVersion()+"\n")
+
+	configYaml, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("Error hashing config: %v", err)
+	}
+
+	io.WriteString(deployHash, "config:"+hash.String(string(configYaml))+"\n")
+
+	if config.Images != nil {
+		imageNames := make([]string, 0, len(*config.Images))
+		for imageName := range *config.Images {
+			imageNames = append(imageNames, imageName)
+		}
+
+		sort.Strings(imageNames)
+		for _, imageName := range imageNames {
+			imageConf := (*config.Images)[imageName]
+			if imageConf.Build != nil && imageConf.Build.Disabled != nil && *imageConf.Build.Disabled {
+				continue
+			}
+
+			dockerfilePath := "./Dockerfile"
+			contextPath := "./"
+			if imageConf.Build != nil {
+				if imageConf.Build.DockerfilePath != nil {
+					dockerfilePath = *imageConf.Build.DockerfilePath
+				}
+				if imageConf.Build.ContextPath != nil {
+					contextPath = *imageConf.Build.ContextPath
+				}
+			}
+
+			contextHash, err := hashBuildContext(contextPath, dockerfilePath)
+			if err != nil {
+				return "", fmt.Errorf("Error hashing build context of image %s: %v", imageName, err)
+			}
+
+			dockerfileHash, err := hash.File(dockerfilePath)
+			if err != nil {
+				return "", fmt.Errorf("Error hashing dockerfile of image %s: %v", imageName, err)
+			}
+
+			io.WriteString(deployHash, "image:"+imageName+":"+contextHash+":"+dockerfileHash+"\n")
+		}
+	}
+
+	if config.DevSpace != nil && config.DevSpace.Deployments != nil {
+		for _, deployConfig := range *config.DevSpace.Deployments {
+			deploymentHash, err := hashDeployment(deployConfig.Helm, deployConfig.Kubectl)
+			if err != nil {
+				return "", fmt.Errorf("Error hashing deployment %s: %v", *deployConfig.Name, err)
+			}
+
+			io.WriteString(deployHash, "deployment:"+*deployConfig.Name+":"+deploymentHash+"\n")
+		}
+	}
+
+	return fmt.Sprintf("%x", deployHash.Sum(nil)), nil
+}
+
+// hashBuildContext hashes all files of the build context that are sent to docker (respecting the .dockerignore)
+func hashBuildContext(contextPath, dockerfilePath string) (string, error) {
+	contextDir, relDockerfile, err := build.GetContextFromLocalDir(contextPath, dockerfilePath)
+	if err != nil {
+		return "", err
+	}
+
+	excludes, err := build.ReadDockerignore(contextDir)
+	if err != nil {
+		return "", err
+	}
+
+	relDockerfile, err = archive.CanonicalTarNameForPath(relDockerfile)
+	if err != nil {
+		return "", err
+	}
+
+	excludes = build.TrimBuildFilesFromExcludes(excludes, relDockerfile, false)
+	return hash.DirectoryContents(contextDir, excludes)
+}
+
+// hashDeployment hashes the chart and overwrite values of helm deployments and the manifests of kubectl deployments
+func hashDeployment(helmConfig *v1.HelmConfig, kubectlConfig *v1.KubectlConfig) (string, error) {
+	hashes := []string{}
+
+	if helmConfig != nil {
+		if helmConfig.ChartPath != nil {
+			chartHash, err := hash.DirectoryContents(*helmConfig.ChartPath, nil)
+			if err != nil {
+				return "", err
+			}
+
+			hashes = append(hashes, "chart:"+chartHash)
+		}
+
+		if helmConfig.DevOverwrite != nil {
+			// The overwrite values are optional
+			overwriteHash, _ := hash.File(*helmConfig.DevOverwrite)
+			hashes = append(hashes, "overwrite:"+overwriteHash)
+		}
+	}
+
+	if kubectlConfig != nil && kubectlConfig.Manifests != nil {
+		for _, pattern := range *kubectlConfig.Manifests {
+			files, err := filepath.Glob(*pattern)
+			if err != nil {
+				return "", err
+			}
+
+			for _, file := range files {
+				stat, err := os.Stat(file)
+				if err != nil {
+					return "", err
+				}
+
+				fileHash := ""
+				if stat.IsDir() {
+					fileHash, err = hash.DirectoryContents(file, nil)
+				} else {
+					fileHash, err = hash.File(file)
+				}
+				if err != nil {
+					return "", err
+				}
+
+				hashes = append(hashes, "manifest:"+file+":"+fileHash)
+			}
+		}
+	}
+
+	return hash.String(fmt.Sprintf("%v", hashes)), nil
+}
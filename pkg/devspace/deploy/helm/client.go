@@ -1,8 +1,12 @@
 package helm
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
 	"github.com/covexo/devspace/pkg/devspace/config/generated"
@@ -11,21 +15,62 @@ import (
 	"github.com/covexo/devspace/pkg/devspace/registry"
 	"github.com/covexo/devspace/pkg/util/hash"
 	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/stdinutil"
 	"github.com/covexo/devspace/pkg/util/yamlutil"
 	"k8s.io/client-go/kubernetes"
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
 )
 
+// tillerTimeoutMessage is the error message of tiller if the resources of a release didn't get ready in time
+const tillerTimeoutMessage = "timed out waiting for the condition"
+
+// releasePodTimeout is the time to wait for the release pod to get ready if the release is rolled back on failure
+const releasePodTimeout = 5 * time.Minute
+
 // DeployConfig holds the information necessary to deploy via helm
 type DeployConfig struct {
-	KubeClient       *kubernetes.Clientset
+	KubeClient       kubernetes.Interface
 	TillerNamespace  string
 	DeploymentConfig *v1.DeploymentConfig
 	UseDevOverwrite  bool
 	Log              log.Logger
+
+	// SkipConflictCheck disables the check for existing resources that are not managed by the release
+	SkipConflictCheck bool
+
+	// ForceConflicts deploys the chart even if existing resources conflict with the release
+	ForceConflicts bool
+
+	// Purge removes the release history on delete, so that the release name can be reused by other charts
+	Purge bool
+
+	// UpdateDependencies resolves the chart dependencies again even if the downloaded archives are up to date
+	UpdateDependencies bool
+
+	// ContainerEnv holds environment variables that are injected into all containers (containers.<name>.env)
+	ContainerEnv map[string]string
+
+	// Values holds values with dotted keys that overwrite the dev overwrite values (e.g. the values of a preview)
+	Values map[string]string
+
+	// SecretValues holds values with dotted keys that were read from a kubernetes secret. They overwrite the dev
+	// overwrite values and are masked in the stored values
+	SecretValues map[string]string
+
+	// CleanupOnFailure rolls back a failed upgrade and purges a failed installation. By default failed releases are
+	// left in place, so that their resources can be inspected
+	CleanupOnFailure bool
+
+	// ShowValuesDiff prints the values that changed since the last deployment of the release
+	ShowValuesDiff bool
+
+	// RollbackOnFailure waits for the release pod after the deployment and rolls the release back to the previous
+	// revision if the pod doesn't get ready or crashes (also enabled by helm.rollbackOnFailure)
+	RollbackOnFailure bool
 }
 
 // New creates a new helm deployment client
-func New(kubectl *kubernetes.Clientset, deployConfig *v1.DeploymentConfig, useDevOverwrite bool, log log.Logger) (*DeployConfig, error) {
+func New(kubectl kubernetes.Interface, deployConfig *v1.DeploymentConfig, useDevOverwrite bool, log log.Logger) (*DeployConfig, error) {
 	config := configutil.GetConfig()
 	return &DeployConfig{
 		KubeClient:       kubectl,
@@ -50,7 +95,7 @@ func (d *DeployConfig) Delete() error {
 		return err
 	}
 
-	_, err = helmClient.DeleteRelease(*d.DeploymentConfig.Name, true)
+	_, err = helmClient.DeleteRelease(*d.DeploymentConfig.Name, d.Purge)
 	if err != nil {
 		return err
 	}
@@ -68,7 +113,7 @@ func (d *DeployConfig) Status() ([][]string, error) {
 		return nil, err
 	}
 
-	releases, err := helmClient.Client.ListReleases()
+	releases, err := helmClient.ListReleases()
 	if err != nil {
 		values = append(values, []string{
 			*d.DeploymentConfig.Name,
@@ -80,7 +125,7 @@ func (d *DeployConfig) Status() ([][]string, error) {
 		return values, nil
 	}
 
-	if releases == nil || len(releases.Releases) == 0 {
+	if len(releases) == 0 {
 		values = append(values, []string{
 			*d.DeploymentConfig.Name,
 			"Not Found",
@@ -91,7 +136,7 @@ func (d *DeployConfig) Status() ([][]string, error) {
 		return values, nil
 	}
 
-	for _, release := range releases.Releases {
+	for _, release := range releases {
 		if release.GetName() == *d.DeploymentConfig.Name {
 			if release.Info.Status.Code.String() != "DEPLOYED" {
 				values = append(values, []string{
@@ -125,128 +170,555 @@ func (d *DeployConfig) Status() ([][]string, error) {
 	return values, nil
 }
 
-// Deploy deploys the given deployment with helm
-func (d *DeployConfig) Deploy(generatedConfig *generated.Config, forceDeploy bool) error {
-	config := configutil.GetConfig()
-
+// Deploy deploys the given deployment with helm. Tiller can't be stopped once it installs the chart, so if the context
+// is cancelled during the installation, the release is marked as interrupted and its status is shown on the next
+// deployment
+func (d *DeployConfig) Deploy(ctx context.Context, generatedConfig *generated.Config, forceDeploy bool) error {
 	releaseName := *d.DeploymentConfig.Name
 	releaseNamespace := *d.DeploymentConfig.Namespace
 	chartPath := *d.DeploymentConfig.Helm.ChartPath
 
-	// Check if the chart directory has changed
-	hash, err := hash.Directory(chartPath)
+	// Get HelmClient
+	helmClient, err := helm.NewClientWithContext(ctx, d.KubeClient, d.Log, false)
 	if err != nil {
-		return fmt.Errorf("Error hashing chart directory: %v", err)
+		return err
 	}
 
-	// Get HelmClient
-	helmClient, err := helm.NewClient(d.KubeClient, d.Log, false)
+	// The last deployment of the release was cancelled, so it is unknown whether tiller completed it
+	interrupted := generatedConfig.InterruptedReleases[releaseName]
+	if interrupted {
+		err = d.printInterruptedRelease(helmClient, releaseName)
+		if err != nil {
+			return err
+		}
+
+		delete(generatedConfig.InterruptedReleases, releaseName)
+	}
+
+	// Download missing or corrupt chart dependencies
+	dependencyDigests, err := helmClient.EnsureDependencies(chartPath, generatedConfig.ChartDependencies[chartPath], d.UpdateDependencies, d.Log)
 	if err != nil {
 		return err
 	}
+	if dependencyDigests != nil {
+		generatedConfig.ChartDependencies[chartPath] = dependencyDigests
+	}
+
+	// Check if the chart directory has changed
+	chartHash, err := hash.Directory(chartPath)
+	if err != nil {
+		return fmt.Errorf("Error hashing chart directory: %v", err)
+	}
+
+	// Changed environment variables require a redeployment as well
+	if len(d.ContainerEnv) > 0 {
+		chartHash = hash.String(chartHash + ";" + strings.Join(getEnvList(d.ContainerEnv), ";"))
+	}
+	if len(d.Values) > 0 {
+		chartHash = hash.String(chartHash + ";" + strings.Join(getEnvList(d.Values), ";"))
+	}
+	if len(d.SecretValues) > 0 {
+		chartHash = hash.String(chartHash + ";" + strings.Join(getEnvList(d.SecretValues), ";"))
+	}
 
-	// Check if redeploying is necessary
-	reDeploy := forceDeploy || generatedConfig.ChartHashs[chartPath] != hash
+	// Check if redeploying is necessary. The hash is stored per release, because several deployments can use the
+	// same chart
+	reDeploy := forceDeploy || interrupted || generatedConfig.ChartHashs[releaseName] != chartHash
 	if reDeploy == false {
-		releases, err := helmClient.Client.ListReleases()
+		releases, err := helmClient.ListReleases()
 		if err != nil {
 			return err
 		}
 
 		reDeploy = true
-		if releases != nil {
-			for _, release := range releases.Releases {
-				if release.GetName() == releaseName {
-					reDeploy = false
-					break
-				}
+		for _, release := range releases {
+			if release.GetName() == releaseName {
+				reDeploy = false
+				break
 			}
 		}
 	}
 
 	// Check if re-deployment is necessary
 	if reDeploy {
+		// A release whose installation failed can't be upgraded, so it has to be purged and installed again
+		latestRelease, err := helmClient.GetLatestRelease(releaseName)
+		if err != nil {
+			return err
+		}
+		if latestRelease != nil && mustReinstall(latestRelease) {
+			err = d.purgeRelease(helmClient, latestRelease)
+			if err != nil {
+				return err
+			}
+
+			latestRelease = nil
+		}
+
 		d.Log.StartWait("Deploying helm chart")
 		defer d.Log.StopWait()
 
-		values := map[interface{}]interface{}{}
-		overwriteValues := map[interface{}]interface{}{}
-
-		valuesPath := filepath.Join(chartPath, "values.yaml")
-		err := yamlutil.ReadYamlFromFile(valuesPath, values)
+		overwriteValues, err := d.getOverwriteValues(generatedConfig)
 		if err != nil {
-			return fmt.Errorf("Couldn't deploy chart, error reading from chart values %s: %v", valuesPath, err)
+			return err
+		}
+
+		maskedValues := maskValues(overwriteValues)
+		setDottedValues(maskedValues, getMaskedSecretValues(d.SecretValues))
+		if lastValues, ok := generatedConfig.DeployedValues[releaseName]; ok && d.ShowValuesDiff {
+			d.Log.StopWait()
+			printValuesDiff(releaseName, lastValues, maskedValues, d.Log)
+			d.Log.StartWait("Deploying helm chart")
 		}
 
-		if d.UseDevOverwrite && d.DeploymentConfig.Helm.DevOverwrite != nil {
-			overwriteValuesPath, err := filepath.Abs(*d.DeploymentConfig.Helm.DevOverwrite)
+		if d.SkipConflictCheck == false {
+			err = d.checkResourceConflicts(helmClient, releaseName, releaseNamespace, chartPath, &overwriteValues)
 			if err != nil {
-				return fmt.Errorf("Error retrieving absolute path from %s: %v", *d.DeploymentConfig.Helm.DevOverwrite, err)
+				return err
 			}
+		}
+
+		reuseValues := d.DeploymentConfig.Helm.ReuseValues != nil && *d.DeploymentConfig.Helm.ReuseValues
+		appRelease, err := deployChart(ctx, helmClient, latestRelease != nil, reuseValues, releaseName, releaseNamespace, chartPath, &overwriteValues)
+		if err == context.Canceled {
+			generatedConfig.InterruptedReleases[releaseName] = true
+			return fmt.Errorf("Deployment of release %s was cancelled, but it might still complete in the cluster. The status of the release is shown on the next deployment", releaseName)
+		} else if err != nil {
+			// Tiller only tells us that the resources didn't get ready, so we show why
+			if strings.Contains(err.Error(), tillerTimeoutMessage) {
+				d.Log.StopWait()
+				d.Log.Warnf("The pods of release %s didn't get ready in time:\n%s", releaseName, helm.DiagnoseReleasePods(d.KubeClient, releaseName, releaseNamespace))
+			}
+
+			if d.CleanupOnFailure {
+				return d.cleanupFailedRelease(helmClient, releaseName, latestRelease, err)
+			}
+
+			return fmt.Errorf("Unable to deploy helm chart: %v. The failed release and its pods were left in place, inspect them with `kubectl describe pods` and `kubectl logs` (run with --atomic to roll back failed deployments instead)", err)
+		}
 
-			err = yamlutil.ReadYamlFromFile(overwriteValuesPath, overwriteValues)
+		releaseRevision := int(appRelease.Version)
+		d.Log.Donef("Deployed helm chart (Release revision: %d)", releaseRevision)
+
+		if d.shouldRollbackOnFailure() {
+			err = d.waitForReleasePod(ctx, helmClient, releaseName, releaseNamespace, latestRelease, appRelease)
 			if err != nil {
-				d.Log.Warnf("Error reading from chart dev overwrite values %s: %v", overwriteValuesPath, err)
+				return err
 			}
 		}
 
-		overwriteContainerValues := map[interface{}]interface{}{}
-		overwriteContainerValuesFromFile, containerValuesExisting := overwriteValues["containers"]
-		if containerValuesExisting {
-			overwriteContainerValues = overwriteContainerValuesFromFile.(map[interface{}]interface{})
+		generatedConfig.ChartHashs[releaseName] = chartHash
+		generatedConfig.DeployedValues[releaseName] = maskedValues
+		generatedConfig.Releases[releaseName] = getReleaseRecord(appRelease, releaseNamespace)
+	} else {
+		d.Log.Infof("Skipping chart %s of release %s (no changes)", chartPath, releaseName)
+	}
+
+	return nil
+}
+
+// getReleaseRecord returns the chart and revision of the deployed release for the generated config
+func getReleaseRecord(release *hapi_release5.Release, releaseNamespace string) *generated.Release {
+	record := &generated.Release{
+		Namespace:  releaseNamespace,
+		Revision:   release.Version,
+		DeployedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if release.Chart != nil && release.Chart.Metadata != nil {
+		record.Chart = release.Chart.Metadata.Name
+		record.ChartVersion = release.Chart.Metadata.Version
+	}
+
+	return record
+}
+
+// deployChart upgrades the release if it exists or installs it otherwise. The deployment runs in the background and
+// context.Canceled is returned as soon as the context is cancelled. The deployment itself can't be cancelled and
+// continues until devspace exits
+func deployChart(ctx context.Context, helmClient *helm.ClientWrapper, upgrade, reuseValues bool, releaseName, releaseNamespace, chartPath string, values *map[interface{}]interface{}) (*hapi_release5.Release, error) {
+	var release *hapi_release5.Release
+	deployErr := make(chan error, 1)
+
+	go func() {
+		var err error
+		if upgrade {
+			release, err = helmClient.UpgradeChartByPath(releaseName, releaseNamespace, chartPath, values, reuseValues)
+		} else {
+			// Failed installations are cleaned up by cleanupFailedRelease depending on the deploy config
+			release, err = helmClient.InstallChartByPath(releaseName, releaseNamespace, chartPath, values, false)
 		}
 
-		for imageName, imageConf := range *config.Images {
-			container := map[interface{}]interface{}{}
-			existingContainer, containerExists := overwriteContainerValues[imageName]
+		deployErr <- err
+	}()
 
-			if containerExists {
-				container = existingContainer.(map[interface{}]interface{})
-			}
-			container["image"] = registry.GetImageURL(generatedConfig, imageConf, true)
+	select {
+	case <-ctx.Done():
+		return nil, context.Canceled
+	case err := <-deployErr:
+		return release, err
+	}
+}
+
+func (d *DeployConfig) shouldRollbackOnFailure() bool {
+	return d.RollbackOnFailure || (d.DeploymentConfig.Helm.RollbackOnFailure != nil && *d.DeploymentConfig.Helm.RollbackOnFailure)
+}
+
+// waitForReleasePod waits until the pod of the deployed release is ready. If it doesn't get ready or crashes, the
+// release is rolled back to the previous revision and an error with the events and logs of the failed pod is returned
+func (d *DeployConfig) waitForReleasePod(ctx context.Context, helmClient *helm.ClientWrapper, releaseName, releaseNamespace string, previousRelease, deployedRelease *hapi_release5.Release) error {
+	d.Log.StartWait("Waiting for the pod of release " + releaseName + " to get ready")
+	err := d.waitForRevision(ctx, releaseName, releaseNamespace, deployedRelease.Version)
+	d.Log.StopWait()
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	if previousRelease == nil || previousRelease.GetInfo().GetStatus().GetCode() != hapi_release5.Status_DEPLOYED {
+		return fmt.Errorf("Revision %d of release %s failed and there is no previous revision to roll back to: %v", deployedRelease.Version, releaseName, err)
+	}
+
+	d.Log.Warnf("Revision %d of release %s failed, rolling back to revision %d", deployedRelease.Version, releaseName, previousRelease.Version)
+
+	d.Log.StartWait(fmt.Sprintf("Rolling back release %s to revision %d", releaseName, previousRelease.Version))
+	rolledBackRelease, rollbackErr := helmClient.RollbackRelease(releaseName, previousRelease.Version)
+	d.Log.StopWait()
+	if rollbackErr != nil {
+		return fmt.Errorf("Revision %d of release %s failed: %v\nRolling back to revision %d failed: %v", deployedRelease.Version, releaseName, err, previousRelease.Version, rollbackErr)
+	}
+
+	d.Log.StartWait("Waiting for the pod of the rolled back release " + releaseName + " to get ready")
+	waitErr := d.waitForRevision(ctx, releaseName, releaseNamespace, rolledBackRelease.Version)
+	d.Log.StopWait()
+	if waitErr != nil {
+		d.Log.Warnf("The pod of the rolled back release %s didn't get ready: %v", releaseName, waitErr)
+	} else {
+		d.Log.Donef("Rolled back release %s to revision %d (Release revision: %d)", releaseName, previousRelease.Version, rolledBackRelease.Version)
+	}
+
+	return fmt.Errorf("Revision %d of release %s failed and was rolled back: %v", deployedRelease.Version, releaseName, err)
+}
+
+// waitForRevision waits until the pod of the release revision is ready or releasePodTimeout is exceeded
+func (d *DeployConfig) waitForRevision(ctx context.Context, releaseName, releaseNamespace string, revision int32) error {
+	waitCtx, cancel := context.WithTimeout(ctx, releasePodTimeout)
+	defer cancel()
 
-			overwriteContainerValues[imageName] = container
+	_, err := helm.WaitForReleasePodToGetReady(waitCtx, d.KubeClient, releaseName, releaseNamespace, int(revision))
+	return err
+}
+
+// cleanupFailedRelease rolls the release back to the revision before the failed upgrade or purges the release if its
+// installation failed
+func (d *DeployConfig) cleanupFailedRelease(helmClient *helm.ClientWrapper, releaseName string, previousRelease *hapi_release5.Release, deployErr error) error {
+	d.Log.StopWait()
+
+	if previousRelease != nil {
+		d.Log.StartWait(fmt.Sprintf("Rolling back release %s to revision %d", releaseName, previousRelease.Version))
+		_, err := helmClient.RollbackRelease(releaseName, previousRelease.Version)
+		d.Log.StopWait()
+		if err != nil {
+			return fmt.Errorf("Unable to deploy helm chart: %v. Rolling back release %s failed: %v", deployErr, releaseName, err)
 		}
 
-		overwritePullSecrets := []interface{}{}
-		overwritePullSecretsFromFile, overwritePullSecretsExisting := overwriteValues["pullSecrets"]
-		if overwritePullSecretsExisting {
-			overwritePullSecrets = overwritePullSecretsFromFile.([]interface{})
+		return fmt.Errorf("Unable to deploy helm chart: %v. Release %s was rolled back to revision %d (run without --atomic to keep failed deployments for debugging)", deployErr, releaseName, previousRelease.Version)
+	}
+
+	d.Log.StartWait("Purging release " + releaseName)
+	_, err := helmClient.DeleteRelease(releaseName, true)
+	d.Log.StopWait()
+	if err != nil {
+		return fmt.Errorf("Unable to deploy helm chart: %v. Purging release %s failed: %v", deployErr, releaseName, err)
+	}
+
+	return fmt.Errorf("Unable to deploy helm chart: %v. Release %s was purged (run without --atomic to keep failed deployments for debugging)", deployErr, releaseName)
+}
+
+// mustReinstall returns true if the installation of the release failed or never completed. Helm can't upgrade such
+// a release. Failed upgrades are upgraded again, because the release has a deployed revision
+func mustReinstall(release *hapi_release5.Release) bool {
+	status := release.GetInfo().GetStatus().GetCode()
+	return status == hapi_release5.Status_PENDING_INSTALL || (status == hapi_release5.Status_FAILED && release.Version <= 1)
+}
+
+// purgeRelease asks the user to purge the release, so that it can be installed again
+func (d *DeployConfig) purgeRelease(helmClient *helm.ClientWrapper, release *hapi_release5.Release) error {
+	releaseName := release.GetName()
+	status := release.GetInfo().GetStatus().GetCode().String()
+
+	shouldPurge := *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
+		Question:               "\n\nThe installation of release " + releaseName + " did not complete (status " + status + "). Should the release be purged and installed again? (y/n)",
+		DefaultValue:           "n",
+		ValidationRegexPattern: "^(y|n)$",
+	}) == "y"
+	if shouldPurge == false {
+		return fmt.Errorf("Release %s has status %s and can't be upgraded. Delete it with `helm delete --purge %s` or `devspace down` and deploy again", releaseName, status, releaseName)
+	}
+
+	d.Log.StartWait("Purging release " + releaseName)
+	_, err := helmClient.DeleteRelease(releaseName, true)
+	d.Log.StopWait()
+	if err != nil {
+		return fmt.Errorf("Error purging release %s: %v", releaseName, err)
+	}
+
+	d.Log.Donef("Purged release %s", releaseName)
+	return nil
+}
+
+// printInterruptedRelease shows the status of a release whose last deployment was cancelled
+func (d *DeployConfig) printInterruptedRelease(helmClient *helm.ClientWrapper, releaseName string) error {
+	releases, err := helmClient.ListReleases()
+	if err != nil {
+		return err
+	}
+
+	for _, release := range releases {
+		if release.GetName() != releaseName {
+			continue
+		}
+
+		status := release.Info.Status.Code.String()
+		if status != "DEPLOYED" {
+			d.Log.Warnf("The last deployment of release %s was cancelled. Release revision %d has status %s", releaseName, release.Version, status)
+			return nil
+		}
+
+		d.Log.Infof("The last deployment of release %s was cancelled, but it completed anyway (Release revision: %d)", releaseName, release.Version)
+		return nil
+	}
+
+	d.Log.Warnf("The last deployment of release %s was cancelled before the release was created", releaseName)
+	return nil
+}
+
+// getOverwriteValues returns the values that are passed to helm when the chart is deployed. They contain the dev
+// overwrite values, the dotted values, the secret values, the image urls of the built images and the pull secrets of the registries
+func (d *DeployConfig) getOverwriteValues(generatedConfig *generated.Config) (map[interface{}]interface{}, error) {
+	config := configutil.GetConfig()
+	chartPath := *d.DeploymentConfig.Helm.ChartPath
+
+	values := map[interface{}]interface{}{}
+	overwriteValues := map[interface{}]interface{}{}
+
+	valuesPath := filepath.Join(chartPath, "values.yaml")
+	err := yamlutil.ReadYamlFromFile(valuesPath, values)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't deploy chart, error reading from chart values %s: %v", valuesPath, err)
+	}
+
+	if d.UseDevOverwrite && d.DeploymentConfig.Helm.DevOverwrite != nil {
+		overwriteValuesPath, err := filepath.Abs(*d.DeploymentConfig.Helm.DevOverwrite)
+		if err != nil {
+			return nil, fmt.Errorf("Error retrieving absolute path from %s: %v", *d.DeploymentConfig.Helm.DevOverwrite, err)
+		}
+
+		err = yamlutil.ReadYamlFromFile(overwriteValuesPath, overwriteValues)
+		if err != nil {
+			d.Log.Warnf("Error reading from chart dev overwrite values %s: %v", overwriteValuesPath, err)
 		}
+	}
+
+	err = setDottedValues(overwriteValues, d.Values)
+	if err != nil {
+		return nil, fmt.Errorf("Error in values: %v", err)
+	}
 
-		pullSecretsFromFile, pullSecretsExisting := values["pullSecrets"]
+	err = setDottedValues(overwriteValues, d.SecretValues)
+	if err != nil {
+		return nil, fmt.Errorf("Error in values from secret: %v", err)
+	}
 
-		if pullSecretsExisting {
-			existingPullSecrets := pullSecretsFromFile.([]interface{})
-			overwritePullSecrets = append(overwritePullSecrets, existingPullSecrets...)
+	images := map[string]string{}
+	if config.Images != nil {
+		for imageName, imageConf := range *config.Images {
+			images[imageName] = registry.GetImageURL(generatedConfig, imageConf, true)
 		}
+	}
+
+	err = mergeContainerValues(values, overwriteValues, images, d.ContainerEnv)
+	if err != nil {
+		return nil, err
+	}
 
+	pullSecrets := []string{}
+	if config.Registries != nil {
 		for _, registryConf := range *config.Registries {
 			if registryConf.URL != nil {
-				registrySecretName := registry.GetRegistryAuthSecretName(*registryConf.URL)
-				overwritePullSecrets = append(overwritePullSecrets, registrySecretName)
+				pullSecrets = append(pullSecrets, registry.GetRegistryAuthSecretName(*registryConf.URL))
 			}
 		}
+	}
+
+	pullSecrets = append(pullSecrets, registry.GetPullSecretNames()...)
+
+	err = mergePullSecrets(values, overwriteValues, pullSecrets)
+	if err != nil {
+		return nil, err
+	}
+
+	return overwriteValues, nil
+}
+
+func (d *DeployConfig) checkResourceConflicts(helmClient *helm.ClientWrapper, releaseName, releaseNamespace, chartPath string, values *map[interface{}]interface{}) error {
+	conflicts, err := helmClient.FindResourceConflicts(releaseName, releaseNamespace, chartPath, values)
+	if err != nil {
+		return fmt.Errorf("Error checking for resource conflicts: %v", err)
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
 
-		for _, autoGeneratedPullSecret := range registry.GetPullSecretNames() {
-			overwritePullSecrets = append(overwritePullSecrets, autoGeneratedPullSecret)
+	for _, conflict := range conflicts {
+		name := conflict.Name
+		if conflict.Namespace != "" {
+			name = conflict.Namespace + "/" + conflict.Name
 		}
 
-		overwriteValues["containers"] = overwriteContainerValues
-		overwriteValues["pullSecrets"] = overwritePullSecrets
+		d.Log.Warnf("%s %s already exists and is owned by %s", conflict.Kind, name, conflict.Owner)
+	}
 
-		appRelease, err := helmClient.InstallChartByPath(releaseName, releaseNamespace, chartPath, &overwriteValues)
-		if err != nil {
-			return fmt.Errorf("Unable to deploy helm chart: %v", err)
+	if d.ForceConflicts {
+		d.Log.Warnf("Deploying release %s anyway, because --force is set", releaseName)
+		return nil
+	}
+
+	return fmt.Errorf("%d resource(s) of release %s already exist and are not managed by it. Run with --force to deploy anyway or --skip-conflict-check to skip this check", len(conflicts), releaseName)
+}
+
+// mergeContainerValues sets the image urls (and the container env) of the containers in the overwrite values. The
+// containers key is left as it is if there are no images, so that the containers of the chart values are used
+func mergeContainerValues(values, overwriteValues map[interface{}]interface{}, images map[string]string, env map[string]string) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	overwriteContainerValues := map[interface{}]interface{}{}
+	if containers, ok := overwriteValues["containers"]; ok && containers != nil {
+		containersMap, ok := containers.(map[interface{}]interface{})
+		if ok == false {
+			return fmt.Errorf("Error in dev overwrite values: containers has to be a map")
 		}
 
-		releaseRevision := int(appRelease.Version)
-		d.Log.Donef("Deployed helm chart (Release revision: %d)", releaseRevision)
+		overwriteContainerValues = containersMap
+	}
 
-		generatedConfig.ChartHashs[chartPath] = hash
-	} else {
-		d.Log.Infof("Skipping chart %s", chartPath)
+	chartContainerValues, _ := values["containers"].(map[interface{}]interface{})
+
+	for imageName, imageURL := range images {
+		container := map[interface{}]interface{}{}
+		if existingContainer, ok := overwriteContainerValues[imageName]; ok && existingContainer != nil {
+			containerMap, ok := existingContainer.(map[interface{}]interface{})
+			if ok == false {
+				return fmt.Errorf("Error in dev overwrite values: containers.%s has to be a map", imageName)
+			}
+
+			container = containerMap
+		}
+
+		container["image"] = imageURL
+		if len(env) > 0 {
+			// Helm replaces lists instead of merging them, so the env of the chart has to be merged here
+			existingEnv := container["env"]
+			if existingEnv == nil {
+				if chartContainer, ok := chartContainerValues[imageName].(map[interface{}]interface{}); ok {
+					existingEnv = chartContainer["env"]
+				}
+			}
+
+			container["env"] = mergeContainerEnv(existingEnv, env)
+		}
+
+		overwriteContainerValues[imageName] = container
 	}
 
+	overwriteValues["containers"] = overwriteContainerValues
 	return nil
 }
+
+// mergePullSecrets adds the pull secrets to the pull secrets of the dev overwrite values and the chart values. The
+// pullSecrets key is only set if there are pull secrets to add, so that the pull secrets of the chart are used otherwise
+func mergePullSecrets(values, overwriteValues map[interface{}]interface{}, pullSecrets []string) error {
+	overwritePullSecrets, overwritePullSecretsExisting := overwriteValues["pullSecrets"]
+	if len(pullSecrets) == 0 && overwritePullSecretsExisting == false {
+		return nil
+	}
+
+	mergedPullSecrets := []interface{}{}
+	added := map[string]bool{}
+
+	// Helm replaces lists instead of merging them, so the pull secrets of the chart have to be merged here
+	for _, pullSecretList := range []interface{}{overwritePullSecrets, values["pullSecrets"]} {
+		if pullSecretList == nil {
+			continue
+		}
+
+		pullSecretArr, ok := pullSecretList.([]interface{})
+		if ok == false {
+			return fmt.Errorf("Error in values: pullSecrets has to be a list")
+		}
+
+		for _, pullSecret := range pullSecretArr {
+			key := fmt.Sprintf("%v", pullSecret)
+			if added[key] == false {
+				added[key] = true
+				mergedPullSecrets = append(mergedPullSecrets, pullSecret)
+			}
+		}
+	}
+
+	for _, pullSecret := range pullSecrets {
+		if added[pullSecret] == false {
+			added[pullSecret] = true
+			mergedPullSecrets = append(mergedPullSecrets, pullSecret)
+		}
+	}
+
+	overwriteValues["pullSecrets"] = mergedPullSecrets
+	return nil
+}
+
+// mergeContainerEnv adds the environment variables to the env list of a container. Variables with the same name
+// are replaced
+func mergeContainerEnv(existingEnv interface{}, env map[string]string) []interface{} {
+	mergedEnv := []interface{}{}
+
+	if existingList, ok := existingEnv.([]interface{}); ok {
+		for _, existingVar := range existingList {
+			if existingMap, ok := existingVar.(map[interface{}]interface{}); ok {
+				if _, replaced := env[fmt.Sprintf("%v", existingMap["name"])]; replaced {
+					continue
+				}
+			}
+
+			mergedEnv = append(mergedEnv, existingVar)
+		}
+	}
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		mergedEnv = append(mergedEnv, map[interface{}]interface{}{
+			"name":  name,
+			"value": env[name],
+		})
+	}
+
+	return mergedEnv
+}
+
+// getEnvList returns the environment variables as sorted NAME=value list
+func getEnvList(env map[string]string) []string {
+	envList := make([]string, 0, len(env))
+	for name, value := range env {
+		envList = append(envList, name+"="+value)
+	}
+
+	sort.Strings(envList)
+	return envList
+}
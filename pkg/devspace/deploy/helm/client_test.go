@@ -0,0 +1,172 @@
+package helm
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/covexo/devspace/pkg/devspace/helm"
+	"github.com/covexo/devspace/pkg/util/log"
+	k8shelm "k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+func getChartValues() map[interface{}]interface{} {
+	return map[interface{}]interface{}{
+		"containers": map[interface{}]interface{}{
+			"default": map[interface{}]interface{}{
+				"image": "nginx:1.15",
+				"env": []interface{}{
+					map[interface{}]interface{}{"name": "LOG_LEVEL", "value": "info"},
+				},
+			},
+		},
+		"pullSecrets": []interface{}{"chart-secret"},
+	}
+}
+
+func TestMergeContainerValuesWithoutImages(t *testing.T) {
+	values := getChartValues()
+	overwriteValues := map[interface{}]interface{}{}
+
+	err := mergeContainerValues(values, overwriteValues, map[string]string{}, map[string]string{"DEBUG": "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := overwriteValues["containers"]; ok {
+		t.Fatalf("Expected no containers in the overwrite values, got %v", overwriteValues["containers"])
+	}
+	if reflect.DeepEqual(values, getChartValues()) == false {
+		t.Fatalf("Chart values were changed: %v", values)
+	}
+}
+
+func TestMergeContainerValues(t *testing.T) {
+	values := getChartValues()
+	overwriteValues := map[interface{}]interface{}{}
+
+	err := mergeContainerValues(values, overwriteValues, map[string]string{"default": "registry/app:abc"}, map[string]string{"DEBUG": "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[interface{}]interface{}{
+		"default": map[interface{}]interface{}{
+			"image": "registry/app:abc",
+			"env": []interface{}{
+				map[interface{}]interface{}{"name": "LOG_LEVEL", "value": "info"},
+				map[interface{}]interface{}{"name": "DEBUG", "value": "true"},
+			},
+		},
+	}
+	if reflect.DeepEqual(overwriteValues["containers"], expected) == false {
+		t.Fatalf("Expected containers %v, got %v", expected, overwriteValues["containers"])
+	}
+	if reflect.DeepEqual(values, getChartValues()) == false {
+		t.Fatalf("Chart values were changed: %v", values)
+	}
+}
+
+func TestMergePullSecretsWithoutRegistries(t *testing.T) {
+	values := getChartValues()
+	overwriteValues := map[interface{}]interface{}{}
+
+	err := mergePullSecrets(values, overwriteValues, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := overwriteValues["pullSecrets"]; ok {
+		t.Fatalf("Expected no pullSecrets in the overwrite values, got %v", overwriteValues["pullSecrets"])
+	}
+	if reflect.DeepEqual(values, getChartValues()) == false {
+		t.Fatalf("Chart values were changed: %v", values)
+	}
+}
+
+func TestMergePullSecrets(t *testing.T) {
+	values := getChartValues()
+	overwriteValues := map[interface{}]interface{}{
+		"pullSecrets": []interface{}{"overwrite-secret"},
+	}
+
+	err := mergePullSecrets(values, overwriteValues, []string{"devspace-auth-registry", "chart-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []interface{}{"overwrite-secret", "chart-secret", "devspace-auth-registry"}
+	if reflect.DeepEqual(overwriteValues["pullSecrets"], expected) == false {
+		t.Fatalf("Expected pullSecrets %v, got %v", expected, overwriteValues["pullSecrets"])
+	}
+	if reflect.DeepEqual(values, getChartValues()) == false {
+		t.Fatalf("Chart values were changed: %v", values)
+	}
+}
+
+// failingHelmClient fails every installation and counts the deleted releases
+type failingHelmClient struct {
+	k8shelm.FakeClient
+
+	deletes int
+}
+
+func (c *failingHelmClient) InstallReleaseFromChart(chart *chart.Chart, namespace string, opts ...k8shelm.InstallOption) (*rls.InstallReleaseResponse, error) {
+	return nil, errors.New("timed out waiting for the condition")
+}
+
+func (c *failingHelmClient) DeleteRelease(releaseName string, opts ...k8shelm.DeleteOption) (*rls.UninstallReleaseResponse, error) {
+	c.deletes++
+	return &rls.UninstallReleaseResponse{}, nil
+}
+
+func TestFailedInstallIsNotPurged(t *testing.T) {
+	chartPath, err := ioutil.TempDir("", "devspace-chart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(chartPath)
+
+	err = ioutil.WriteFile(filepath.Join(chartPath, "Chart.yaml"), []byte("name: api\nversion: 0.1.0\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	helmClient := &failingHelmClient{}
+	helmClientWrapper := &helm.ClientWrapper{Client: helmClient}
+	values := map[interface{}]interface{}{}
+
+	// Without --atomic the failed release is left in place for debugging
+	_, deployErr := deployChart(context.Background(), helmClientWrapper, false, false, "api", "default", chartPath, &values)
+	if deployErr == nil {
+		t.Fatal("Expected the installation to fail")
+	}
+	if helmClient.deletes != 0 {
+		t.Fatalf("Expected the failed release to be kept, got %d deletes", helmClient.deletes)
+	}
+
+	// With --atomic the deploy config purges the release
+	deployConfig := &DeployConfig{Log: &log.DiscardLogger{}, CleanupOnFailure: true}
+	err = deployConfig.cleanupFailedRelease(helmClientWrapper, "api", nil, deployErr)
+	if err == nil {
+		t.Fatal("Expected the deploy error to be returned")
+	}
+	if helmClient.deletes != 1 {
+		t.Fatalf("Expected the failed release to be purged once, got %d deletes", helmClient.deletes)
+	}
+
+	// Charts that are installed outside of the deploy config (e.g. the internal registry) are purged by the helm client
+	_, err = helmClientWrapper.InstallChartByPath("devspace-registry", "default", chartPath, &values, true)
+	if err == nil {
+		t.Fatal("Expected the installation to fail")
+	}
+	if helmClient.deletes != 2 {
+		t.Fatalf("Expected the failed registry release to be purged, got %d deletes in total", helmClient.deletes)
+	}
+}
@@ -0,0 +1,107 @@
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/devspace/config/generated"
+	"github.com/covexo/devspace/pkg/devspace/helm"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Diff compares the values of the deployed release with the values the config would deploy. It returns a line diff
+// of both values in yaml format ("-" deployed, "+" config) or an empty string if there are no differences.
+// deployed is false if the release doesn't exist
+func (d *DeployConfig) Diff(generatedConfig *generated.Config) (string, bool, error) {
+	releaseName := *d.DeploymentConfig.Name
+
+	values, err := d.getOverwriteValues(generatedConfig)
+	if err != nil {
+		return "", false, err
+	}
+
+	deployedValues := map[interface{}]interface{}{}
+	deployed := false
+
+	// Don't install tiller only to compare the values
+	if helm.IsTillerDeployed(d.KubeClient) {
+		helmClient, err := helm.NewClient(d.KubeClient, d.Log, false)
+		if err != nil {
+			return "", false, err
+		}
+
+		deployed, err = helmClient.ReleaseExists(releaseName)
+		if err != nil {
+			return "", false, err
+		}
+
+		if deployed {
+			deployedValues, err = helmClient.GetValues(releaseName)
+			if err != nil {
+				return "", false, fmt.Errorf("Unable to get values of release %s: %v", releaseName, err)
+			}
+		}
+	}
+
+	diff, err := diffValues(deployedValues, values)
+	if err != nil {
+		return "", false, err
+	}
+
+	return diff, deployed, nil
+}
+
+// diffValues returns a line diff of the yaml representation of both values or an empty string if they are equal
+func diffValues(oldValues, newValues map[interface{}]interface{}) (string, error) {
+	oldYaml, err := valuesToYaml(oldValues)
+	if err != nil {
+		return "", err
+	}
+
+	newYaml, err := valuesToYaml(newValues)
+	if err != nil {
+		return "", err
+	}
+
+	if oldYaml == newYaml {
+		return "", nil
+	}
+
+	dmp := diffmatchpatch.New()
+	oldChars, newChars, lines := dmp.DiffLinesToChars(oldYaml, newYaml)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(oldChars, newChars, false), lines)
+
+	buffer := &bytes.Buffer{}
+	for _, diff := range diffs {
+		prefix := "  "
+		if diff.Type == diffmatchpatch.DiffInsert {
+			prefix = "+ "
+		} else if diff.Type == diffmatchpatch.DiffDelete {
+			prefix = "- "
+		}
+
+		for _, line := range strings.SplitAfter(diff.Text, "\n") {
+			if line != "" {
+				buffer.WriteString(prefix + line)
+			}
+		}
+	}
+
+	return buffer.String(), nil
+}
+
+// valuesToYaml converts the values to yaml with sorted keys. Empty values are converted to an empty string
+func valuesToYaml(values map[interface{}]interface{}) (string, error) {
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	out, err := yaml.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
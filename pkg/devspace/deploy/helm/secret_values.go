@@ -0,0 +1,118 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// lastAppliedConfigKey is written by kubectl apply and never used as value
+const lastAppliedConfigKey = "kubectl.kubernetes.io/last-applied-configuration"
+
+// GetSecretValues reads the kubernetes secret namespace/name (or name in the default namespace) and returns its keys
+// as dotted helm value keys (e.g. database.url)
+func GetSecretValues(client kubernetes.Interface, secretRef string) (map[string]string, error) {
+	namespace, name, err := parseSecretRef(secretRef)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		namespace, err = configutil.GetDefaultNamespace(configutil.GetConfig())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read values from secret %s/%s: %v", namespace, name, err)
+	}
+
+	secretValues := map[string]string{}
+	for key, value := range secret.Data {
+		if key == lastAppliedConfigKey {
+			continue
+		}
+
+		secretValues[key] = string(value)
+	}
+
+	// Check the keys before anything is deployed
+	err = setDottedValues(map[interface{}]interface{}{}, secretValues)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid values in secret %s/%s: %v", namespace, name, err)
+	}
+
+	return secretValues, nil
+}
+
+// parseSecretRef splits namespace/name. The namespace is empty if only the name is given
+func parseSecretRef(secretRef string) (string, string, error) {
+	parts := strings.Split(secretRef, "/")
+	if len(parts) == 1 && parts[0] != "" {
+		return "", parts[0], nil
+	} else if len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+		return parts[0], parts[1], nil
+	}
+
+	return "", "", fmt.Errorf("Invalid secret %s (format is namespace/secret-name)", secretRef)
+}
+
+// setDottedValues sets values with dotted keys in the nested values (e.g. database.url sets url in the database
+// map). The values are always strings and overwrite existing values
+func setDottedValues(values map[interface{}]interface{}, dottedValues map[string]string) error {
+	keys := make([]string, 0, len(dottedValues))
+	for key := range dottedValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := strings.Split(key, ".")
+		parent := values
+
+		for index, name := range path {
+			if name == "" {
+				return fmt.Errorf("Invalid key %s", key)
+			}
+			if index == len(path)-1 {
+				if _, ok := parent[name].(map[interface{}]interface{}); ok {
+					return fmt.Errorf("Key %s would replace a map of values", key)
+				}
+
+				parent[name] = dottedValues[key]
+				break
+			}
+
+			child, ok := parent[name]
+			if ok == false || child == nil {
+				child = map[interface{}]interface{}{}
+				parent[name] = child
+			}
+
+			childMap, ok := child.(map[interface{}]interface{})
+			if ok == false {
+				return fmt.Errorf("Key %s requires %s to be a map", key, strings.Join(path[:index+1], "."))
+			}
+
+			parent = childMap
+		}
+	}
+
+	return nil
+}
+
+// getMaskedSecretValues returns the secret values with masked values, so that they are never stored or printed
+func getMaskedSecretValues(secretValues map[string]string) map[string]string {
+	maskedValues := make(map[string]string, len(secretValues))
+	for key := range secretValues {
+		maskedValues[key] = maskedValue
+	}
+
+	return maskedValues
+}
@@ -0,0 +1,77 @@
+package helm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetDottedValues(t *testing.T) {
+	values := map[interface{}]interface{}{
+		"database": map[interface{}]interface{}{
+			"host": "localhost",
+			"url":  "postgres://localhost",
+		},
+	}
+
+	err := setDottedValues(values, map[string]string{
+		"database.url": "postgres://db.staging",
+		"apiKey":       "0123",
+		"auth.enabled": "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[interface{}]interface{}{
+		"database": map[interface{}]interface{}{
+			"host": "localhost",
+			"url":  "postgres://db.staging",
+		},
+		"apiKey": "0123",
+		"auth": map[interface{}]interface{}{
+			"enabled": "true",
+		},
+	}
+	if reflect.DeepEqual(values, expected) == false {
+		t.Fatalf("Expected values %v, got %v", expected, values)
+	}
+
+	for _, invalid := range []map[string]string{
+		{"database": "postgres://db.staging"},
+		{"database.host.name": "db"},
+		{"database..url": "postgres://db.staging"},
+		{"tls": "true", "tls.cert": "cert"},
+	} {
+		err := setDottedValues(map[interface{}]interface{}{
+			"database": map[interface{}]interface{}{"host": "localhost"},
+		}, invalid)
+		if err == nil {
+			t.Fatalf("Expected an error for secret values %v", invalid)
+		}
+	}
+}
+
+func TestParseSecretRef(t *testing.T) {
+	namespace, name, err := parseSecretRef("staging/app-values")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if namespace != "staging" || name != "app-values" {
+		t.Fatalf("Expected staging/app-values, got %s/%s", namespace, name)
+	}
+
+	namespace, name, err = parseSecretRef("app-values")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if namespace != "" || name != "app-values" {
+		t.Fatalf("Expected app-values in the default namespace, got %s/%s", namespace, name)
+	}
+
+	for _, invalid := range []string{"", "/app-values", "staging/", "a/b/c"} {
+		_, _, err := parseSecretRef(invalid)
+		if err == nil {
+			t.Fatalf("Expected an error for secret %s", invalid)
+		}
+	}
+}
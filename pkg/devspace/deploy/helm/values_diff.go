@@ -0,0 +1,193 @@
+package helm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/daviddengcn/go-colortext"
+)
+
+// maskedValue replaces the values of secret keys in the stored values
+const maskedValue = "*****"
+
+// maxValuesDiffLines is the number of changed keys that are printed before the diff is folded
+const maxValuesDiffLines = 20
+
+// secretKeyRegex matches keys (and names of env variables) whose values are masked
+var secretKeyRegex = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|private_?key|api_?key)`)
+
+// valueChange is a changed key of the flattened values. Old is empty for added keys and New for removed keys
+type valueChange struct {
+	key     string
+	old     string
+	new     string
+	added   bool
+	removed bool
+}
+
+// String returns the change as "+key=value", "-key=value" or "key: old → new"
+func (v *valueChange) String() string {
+	if v.added {
+		return "+" + v.key + "=" + v.new
+	} else if v.removed {
+		return "-" + v.key + "=" + v.old
+	}
+
+	return v.key + ": " + v.old + " → " + v.new
+}
+
+// maskValues returns a copy of the values with masked secrets. Values of keys that look like secrets and values of
+// name/value pairs (e.g. env variables) whose name looks like a secret are replaced
+func maskValues(values map[interface{}]interface{}) map[interface{}]interface{} {
+	return maskValue(values, false).(map[interface{}]interface{})
+}
+
+func maskValue(value interface{}, mask bool) interface{} {
+	switch typedValue := value.(type) {
+	case map[interface{}]interface{}:
+		masked := map[interface{}]interface{}{}
+		name, hasName := typedValue["name"]
+		maskPair := hasName && secretKeyRegex.MatchString(fmt.Sprintf("%v", name))
+
+		for key, subValue := range typedValue {
+			keyString := fmt.Sprintf("%v", key)
+			masked[key] = maskValue(subValue, mask || secretKeyRegex.MatchString(keyString) || (maskPair && keyString == "value"))
+		}
+
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, 0, len(typedValue))
+		for _, subValue := range typedValue {
+			masked = append(masked, maskValue(subValue, mask))
+		}
+
+		return masked
+	}
+
+	if mask && value != nil {
+		return maskedValue
+	}
+
+	return value
+}
+
+// flattenValues returns the values as map of dotted keys (e.g. containers.api.image). List items with a name are
+// keyed by their name (e.g. env.FEATURE_X) and other list items by their index (e.g. pullSecrets[0])
+func flattenValues(values map[interface{}]interface{}) map[string]string {
+	flatValues := map[string]string{}
+	flattenValue("", values, flatValues)
+
+	return flatValues
+}
+
+func flattenValue(key string, value interface{}, flatValues map[string]string) {
+	switch typedValue := value.(type) {
+	case map[interface{}]interface{}:
+		if len(typedValue) == 0 && key != "" {
+			flatValues[key] = "{}"
+		}
+
+		for subKey, subValue := range typedValue {
+			flattenValue(joinValuesKey(key, fmt.Sprintf("%v", subKey)), subValue, flatValues)
+		}
+	case []interface{}:
+		if len(typedValue) == 0 {
+			flatValues[key] = "[]"
+		}
+
+		for index, subValue := range typedValue {
+			subKey := fmt.Sprintf("%s[%d]", key, index)
+
+			if subMap, ok := subValue.(map[interface{}]interface{}); ok && subMap["name"] != nil {
+				subKey = joinValuesKey(key, fmt.Sprintf("%v", subMap["name"]))
+
+				// Name/value pairs are shown as name=value
+				if pairValue, ok := subMap["value"]; ok && len(subMap) == 2 {
+					flattenValue(subKey, pairValue, flatValues)
+					continue
+				}
+
+				subValue = withoutName(subMap)
+			}
+
+			flattenValue(subKey, subValue, flatValues)
+		}
+	default:
+		flatValues[key] = fmt.Sprintf("%v", value)
+	}
+}
+
+func joinValuesKey(key, subKey string) string {
+	if key == "" {
+		return subKey
+	}
+
+	return key + "." + subKey
+}
+
+func withoutName(values map[interface{}]interface{}) map[interface{}]interface{} {
+	copied := map[interface{}]interface{}{}
+	for key, value := range values {
+		if key != "name" {
+			copied[key] = value
+		}
+	}
+
+	return copied
+}
+
+// diffFlatValues returns the changed keys sorted by key
+func diffFlatValues(oldValues, newValues map[string]string) []*valueChange {
+	changes := []*valueChange{}
+
+	for key, newValue := range newValues {
+		oldValue, ok := oldValues[key]
+		if ok == false {
+			changes = append(changes, &valueChange{key: key, new: newValue, added: true})
+		} else if oldValue != newValue {
+			changes = append(changes, &valueChange{key: key, old: oldValue, new: newValue})
+		}
+	}
+
+	for key, oldValue := range oldValues {
+		if _, ok := newValues[key]; ok == false {
+			changes = append(changes, &valueChange{key: key, old: oldValue, removed: true})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].key < changes[j].key
+	})
+
+	return changes
+}
+
+// printValuesDiff prints the changed keys between the values of the last and the current deployment. Only the first
+// maxValuesDiffLines changes are printed
+func printValuesDiff(releaseName string, oldValues, newValues map[interface{}]interface{}, logger log.Logger) {
+	changes := diffFlatValues(flattenValues(oldValues), flattenValues(newValues))
+	if len(changes) == 0 {
+		return
+	}
+
+	logger.Infof("Values of release %s changed since the last deployment:", releaseName)
+
+	for i, change := range changes {
+		if i == maxValuesDiffLines {
+			log.WriteColored(fmt.Sprintf("  ... %d more changes (see `devspace status --values` for all values)\n", len(changes)-maxValuesDiffLines), ct.White)
+			break
+		}
+
+		color := ct.Yellow
+		if change.added {
+			color = ct.Green
+		} else if change.removed {
+			color = ct.Red
+		}
+
+		log.WriteColored("  "+strings.Replace(change.String(), "\n", "\\n", -1)+"\n", color)
+	}
+}
@@ -0,0 +1,83 @@
+package helm
+
+import (
+	"testing"
+)
+
+func TestMaskValues(t *testing.T) {
+	values := map[interface{}]interface{}{
+		"database": map[interface{}]interface{}{
+			"host":     "mysql",
+			"password": "secret123",
+		},
+		"containers": map[interface{}]interface{}{
+			"api": map[interface{}]interface{}{
+				"env": []interface{}{
+					map[interface{}]interface{}{"name": "API_TOKEN", "value": "abc"},
+					map[interface{}]interface{}{"name": "FEATURE_X", "value": "true"},
+				},
+			},
+		},
+	}
+
+	flatValues := flattenValues(maskValues(values))
+	expected := map[string]string{
+		"database.host":                "mysql",
+		"database.password":            maskedValue,
+		"containers.api.env.API_TOKEN": maskedValue,
+		"containers.api.env.FEATURE_X": "true",
+	}
+
+	if len(flatValues) != len(expected) {
+		t.Fatalf("Expected %d flat values, got %v", len(expected), flatValues)
+	}
+	for key, value := range expected {
+		if flatValues[key] != value {
+			t.Fatalf("Expected %s for %s, got %s", value, key, flatValues[key])
+		}
+	}
+
+	// The original values must not be changed
+	if values["database"].(map[interface{}]interface{})["password"] != "secret123" {
+		t.Fatal("maskValues changed the original values")
+	}
+}
+
+func TestDiffFlatValues(t *testing.T) {
+	oldValues := flattenValues(map[interface{}]interface{}{
+		"containers": map[interface{}]interface{}{
+			"api": map[interface{}]interface{}{
+				"image": "app:abc123",
+			},
+		},
+		"pullSecrets": []interface{}{"devspace-auth"},
+		"replicas":    1,
+	})
+	newValues := flattenValues(map[interface{}]interface{}{
+		"containers": map[interface{}]interface{}{
+			"api": map[interface{}]interface{}{
+				"image": "app:def456",
+			},
+		},
+		"env": []interface{}{
+			map[interface{}]interface{}{"name": "FEATURE_X", "value": true},
+		},
+		"replicas": 1,
+	})
+
+	changes := diffFlatValues(oldValues, newValues)
+	expected := []string{
+		"containers.api.image: app:abc123 → app:def456",
+		"+env.FEATURE_X=true",
+		"-pullSecrets[0]=devspace-auth",
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d", len(expected), len(changes))
+	}
+	for i, change := range changes {
+		if change.String() != expected[i] {
+			t.Fatalf("Expected change %s, got %s", expected[i], change.String())
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package deploy
 
 import (
+	"context"
+
 	"github.com/covexo/devspace/pkg/devspace/config/generated"
 )
 
@@ -8,5 +10,5 @@ import (
 type Interface interface {
 	Delete() error
 	Status() ([][]string, error)
-	Deploy(generatedConfig *generated.Config, forceDeploy bool) error
+	Deploy(ctx context.Context, generatedConfig *generated.Config, forceDeploy bool) error
 }
@@ -1,7 +1,9 @@
 package kubectl
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"os/exec"
 	"strings"
 
@@ -11,21 +13,24 @@ import (
 	"github.com/covexo/devspace/pkg/devspace/config/generated"
 
 	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/devspace/registry"
 	"github.com/covexo/devspace/pkg/util/log"
 )
 
 // DeployConfig holds the necessary information for kubectl deployment
 type DeployConfig struct {
-	KubeClient *kubernetes.Clientset // This is not used yet, however the plan is to use it instead of calling kubectl via cmd
-	CmdPath    string
-	Context    string
-	Namespace  string
-	Manifests  []string
-	Log        log.Logger
+	KubeClient      kubernetes.Interface // This is not used yet, however the plan is to use it instead of calling kubectl via cmd
+	Name            string
+	CmdPath         string
+	Context         string
+	Namespace       string
+	Manifests       []string
+	ServerSideApply bool
+	Log             log.Logger
 }
 
 // New creates a new deploy config for kubectl
-func New(kubectl *kubernetes.Clientset, deployConfig *v1.DeploymentConfig, log log.Logger) (*DeployConfig, error) {
+func New(kubectl kubernetes.Interface, deployConfig *v1.DeploymentConfig, log log.Logger) (*DeployConfig, error) {
 	if deployConfig.Kubectl == nil {
 		return nil, errors.New("Error creating kubectl deploy config: kubectl is nil")
 	}
@@ -59,12 +64,14 @@ func New(kubectl *kubernetes.Clientset, deployConfig *v1.DeploymentConfig, log l
 	}
 
 	return &DeployConfig{
-		KubeClient: kubectl,
-		CmdPath:    cmdPath,
-		Context:    context,
-		Namespace:  namespace,
-		Manifests:  manifests,
-		Log:        log,
+		KubeClient:      kubectl,
+		Name:            *deployConfig.Name,
+		CmdPath:         cmdPath,
+		Context:         context,
+		Namespace:       namespace,
+		Manifests:       manifests,
+		ServerSideApply: deployConfig.Kubectl.ServerSideApply != nil && *deployConfig.Kubectl.ServerSideApply,
+		Log:             log,
 	}, nil
 }
 
@@ -74,62 +81,120 @@ func (d *DeployConfig) Status() ([][]string, error) {
 	return [][]string{}, nil
 }
 
-// Delete deletes all matched manifests from kubernetes
+// Delete deletes all objects that were applied by the last deployment. If no applied objects were recorded, all
+// matched manifests are deleted
 func (d *DeployConfig) Delete() error {
-	d.Log.StartWait("Loading manifests")
-	manifests, err := loadManifests(d.Manifests, d.Log)
+	generatedConfig, err := generated.LoadConfig()
 	if err != nil {
 		return err
 	}
 
-	joinedManifests, err := joinManifests(manifests)
+	manifests := []Manifest{}
+	if objects, ok := generatedConfig.KubectlObjects[d.Name]; ok && len(objects) > 0 {
+		for _, object := range objects {
+			manifests = append(manifests, getManifestFromObject(object))
+		}
+	} else {
+		d.Log.StartWait("Loading manifests")
+		manifests, err = loadManifests(d.Manifests, d.Log)
+		d.Log.StopWait()
+		if err != nil {
+			return err
+		}
+	}
+
+	d.Log.StartWait("Deleting manifests with kubectl")
+	err = d.run(context.Background(), manifests, "delete", "--ignore-not-found=true")
+	d.Log.StopWait()
 	if err != nil {
 		return err
 	}
 
-	stringReader := strings.NewReader(joinedManifests)
-	args := d.getCmdArgs("delete", "--ignore-not-found=true")
-
-	cmd := exec.Command(d.CmdPath, args...)
-
-	cmd.Stdin = stringReader
-	cmd.Stdout = d.Log
-	cmd.Stderr = d.Log
-
-	d.Log.StartWait("Deleting manifests with kubectl")
-	defer d.Log.StopWait()
-	return cmd.Run()
+	delete(generatedConfig.KubectlObjects, d.Name)
+	return generated.SaveConfig(generatedConfig)
 }
 
-// Deploy deploys all specified manifests via kubectl apply and adds to the specified image names the corresponding tags
-func (d *DeployConfig) Deploy(generatedConfig *generated.Config, forceDeploy bool) error {
+// Deploy deploys all specified manifests via kubectl apply and replaces the images of the config with the built
+// image urls. Objects that were applied by the last deployment, but were removed from the manifests since then, are
+// deleted. kubectl is killed as soon as the context is cancelled
+func (d *DeployConfig) Deploy(ctx context.Context, generatedConfig *generated.Config, forceDeploy bool) error {
 	d.Log.StartWait("Loading manifests")
 	manifests, err := loadManifests(d.Manifests, d.Log)
+	d.Log.StopWait()
 	if err != nil {
 		return err
 	}
+	if len(manifests) == 0 {
+		return fmt.Errorf("No manifests found for %s", strings.Join(d.Manifests, ", "))
+	}
+
+	imageURLs := getImageURLs(generatedConfig)
+	objects := make([]*generated.KubectlObject, 0, len(manifests))
 
 	for _, manifest := range manifests {
-		replaceManifest(manifest, generatedConfig.ImageTags)
+		object, err := getObject(manifest)
+		if err != nil {
+			return fmt.Errorf("Invalid manifest: %v", err)
+		}
+		if object.Namespace == "" {
+			object.Namespace = d.Namespace
+		}
+
+		objects = append(objects, object)
+		replaceManifest(manifest, imageURLs)
 	}
 
-	joinedManifests, err := joinManifests(manifests)
+	args := []string{"--force"}
+	if d.ServerSideApply {
+		args = []string{"--server-side", "--force-conflicts", "--field-manager=devspace"}
+	}
+
+	d.Log.StartWait("Applying manifests with kubectl")
+	err = d.run(ctx, manifests, "apply", args...)
+	d.Log.StopWait()
 	if err != nil {
 		return err
 	}
 
-	stringReader := strings.NewReader(joinedManifests)
-	args := d.getCmdArgs("apply", "--force")
+	removedObjects := getRemovedObjects(generatedConfig.KubectlObjects[d.Name], objects)
+	if len(removedObjects) > 0 {
+		removedManifests := []Manifest{}
+		for _, object := range removedObjects {
+			d.Log.Infof("Deleting %s %s, because it was removed from the manifests", object.Kind, object.Name)
+			removedManifests = append(removedManifests, getManifestFromObject(object))
+		}
+
+		d.Log.StartWait("Deleting removed manifests with kubectl")
+		err = d.run(ctx, removedManifests, "delete", "--ignore-not-found=true")
+		d.Log.StopWait()
+		if err != nil {
+			return fmt.Errorf("Error deleting removed manifests: %v", err)
+		}
+	}
+
+	generatedConfig.KubectlObjects[d.Name] = objects
+	return nil
+}
 
-	cmd := exec.Command(d.CmdPath, args...)
+// run executes kubectl with the given method and passes the manifests via stdin
+func (d *DeployConfig) run(ctx context.Context, manifests []Manifest, method string, additionalArgs ...string) error {
+	joinedManifests, err := joinManifests(manifests)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, d.CmdPath, d.getCmdArgs(method, additionalArgs...)...)
 
-	cmd.Stdin = stringReader
+	cmd.Stdin = strings.NewReader(joinedManifests)
 	cmd.Stdout = d.Log
 	cmd.Stderr = d.Log
 
-	d.Log.StartWait("Applying manifests with kubectl")
-	defer d.Log.StopWait()
-	return cmd.Run()
+	err = cmd.Run()
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
 }
 
 func (d *DeployConfig) getCmdArgs(method string, additionalArgs ...string) []string {
@@ -153,10 +218,56 @@ func (d *DeployConfig) getCmdArgs(method string, additionalArgs ...string) []str
 	return args
 }
 
-func replaceManifest(manifest Manifest, tags map[string]string) {
+// getImageURLs returns the image url (including the tag) for every image of the config. The url can be referenced
+// in the manifests by the key of the image in the config, the image name or the image name including the registry
+func getImageURLs(generatedConfig *generated.Config) map[string]string {
+	config := configutil.GetConfig()
+	imageURLs := map[string]string{}
+
+	if config.Images != nil {
+		for imageKey, imageConf := range *config.Images {
+			imageURL := registry.GetImageURL(generatedConfig, imageConf, true)
+
+			imageURLs[imageKey] = imageURL
+			imageURLs[*imageConf.Name] = imageURL
+			imageURLs[registry.GetImageURL(generatedConfig, imageConf, false)] = imageURL
+		}
+	}
+
+	return imageURLs
+}
+
+// getRemovedObjects returns the objects of oldObjects that are not in newObjects. The api version is ignored, so
+// changing the api version of an object doesn't delete it
+func getRemovedObjects(oldObjects, newObjects []*generated.KubectlObject) []*generated.KubectlObject {
+	getKey := func(object *generated.KubectlObject) string {
+		group := ""
+		if index := strings.Index(object.APIVersion, "/"); index != -1 {
+			group = object.APIVersion[:index]
+		}
+
+		return group + "/" + object.Kind + "/" + object.Namespace + "/" + object.Name
+	}
+
+	newKeys := map[string]bool{}
+	for _, object := range newObjects {
+		newKeys[getKey(object)] = true
+	}
+
+	removedObjects := []*generated.KubectlObject{}
+	for _, object := range oldObjects {
+		if newKeys[getKey(object)] == false {
+			removedObjects = append(removedObjects, object)
+		}
+	}
+
+	return removedObjects
+}
+
+func replaceManifest(manifest Manifest, imageURLs map[string]string) {
 	match := func(key, value string) bool {
 		if key == "image" {
-			if _, ok := tags[value]; ok {
+			if _, ok := imageURLs[value]; ok {
 				return true
 			}
 		}
@@ -165,7 +276,7 @@ func replaceManifest(manifest Manifest, tags map[string]string) {
 	}
 
 	replace := func(value string) string {
-		return value + ":" + tags[value]
+		return imageURLs[value]
 	}
 
 	Walk(map[interface{}]interface{}(manifest), match, replace)
@@ -1,10 +1,13 @@
 package kubectl
 
 import (
+	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/covexo/devspace/pkg/devspace/config/generated"
 	"github.com/covexo/devspace/pkg/util/log"
 
 	yaml "gopkg.in/yaml.v2"
@@ -19,7 +22,7 @@ func joinManifests(manifests []Manifest) (string, error) {
 	for _, manifest := range manifests {
 		out, err := yaml.Marshal(manifest)
 		if err != nil {
-			return "", nil
+			return "", err
 		}
 
 		if retString != "" {
@@ -42,11 +45,37 @@ func loadManifests(globPatterns []string, log log.Logger) ([]Manifest, error) {
 		}
 
 		for _, file := range files {
-			if isValidFile(file) {
-				loadedManifests, err := getManifestsFromFile(file)
+			stat, err := os.Stat(file)
+			if err != nil {
+				return nil, err
+			}
+
+			// Directories are searched recursively for manifests
+			if stat.IsDir() {
+				err = filepath.Walk(file, func(path string, info os.FileInfo, err error) error {
+					if err != nil {
+						return err
+					}
+					if info.IsDir() || isValidFile(path) == false {
+						return nil
+					}
+
+					loadedManifests, err := getManifestsFromFile(path)
+					if err != nil {
+						return fmt.Errorf("Error loading manifest %s: %v", path, err)
+					}
+
+					manifests = append(manifests, loadedManifests...)
+					return nil
+				})
 				if err != nil {
 					return nil, err
 				}
+			} else if isValidFile(file) {
+				loadedManifests, err := getManifestsFromFile(file)
+				if err != nil {
+					return nil, fmt.Errorf("Error loading manifest %s: %v", file, err)
+				}
 
 				manifests = append(manifests, loadedManifests...)
 			} else {
@@ -82,8 +111,54 @@ func getManifestsFromFile(filepath string) ([]Manifest, error) {
 			return nil, err
 		}
 
+		// Skip empty documents (e.g. a leading --- or documents that only contain comments)
+		if len(manifestData) == 0 {
+			continue
+		}
+
 		retManifests = append(retManifests, manifestData)
 	}
 
 	return retManifests, nil
 }
+
+// getObject returns the identifying fields of a manifest
+func getObject(manifest Manifest) (*generated.KubectlObject, error) {
+	apiVersion, _ := manifest["apiVersion"].(string)
+	kind, _ := manifest["kind"].(string)
+	metadata, _ := manifest["metadata"].(map[interface{}]interface{})
+	if apiVersion == "" || kind == "" || metadata == nil {
+		return nil, fmt.Errorf("Manifest has no apiVersion, kind or metadata")
+	}
+
+	name, _ := metadata["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("%s has no metadata.name", kind)
+	}
+
+	namespace, _ := metadata["namespace"].(string)
+
+	return &generated.KubectlObject{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Namespace:  namespace,
+		Name:       name,
+	}, nil
+}
+
+// getManifestFromObject returns a manifest that only contains the identifying fields of the object, which is enough
+// for kubectl delete
+func getManifestFromObject(object *generated.KubectlObject) Manifest {
+	metadata := map[interface{}]interface{}{
+		"name": object.Name,
+	}
+	if object.Namespace != "" {
+		metadata["namespace"] = object.Namespace
+	}
+
+	return Manifest{
+		"apiVersion": object.APIVersion,
+		"kind":       object.Kind,
+		"metadata":   metadata,
+	}
+}
@@ -0,0 +1,275 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/cloud"
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/generated"
+	"github.com/covexo/devspace/pkg/devspace/registry"
+	"github.com/covexo/devspace/pkg/util/kubeconfig"
+)
+
+// SummarySchemaVersion is the version of the summary schema. Fields may be added within a schema version, renaming
+// or removing a field requires a new schema version
+const SummarySchemaVersion = "v1"
+
+const (
+	// SummaryStatusSuccess is the status of a summary written after a successful deployment
+	SummaryStatusSuccess = "success"
+	// SummaryStatusFailure is the status of a summary written after a failed deployment
+	SummaryStatusFailure = "failure"
+	// SummaryStatusDeployed is the status of a summary of the last successful deployment (devspace status --json)
+	SummaryStatusDeployed = "deployed"
+)
+
+const (
+	// PhaseConfig is the phase of loading the config
+	PhaseConfig = "config"
+	// PhaseSetup is the phase of connecting to and setting up the cluster
+	PhaseSetup = "setup"
+	// PhaseBuild is the phase of building and pushing the images
+	PhaseBuild = "build"
+	// PhaseDeploy is the phase of deploying the deployments
+	PhaseDeploy = "deploy"
+	// PhaseSave is the phase of saving the generated config
+	PhaseSave = "save"
+)
+
+const (
+	// ErrorCategoryCancelled means that the user cancelled the deployment
+	ErrorCategoryCancelled = "cancelled"
+	// ErrorCategoryConfig means that the config is invalid
+	ErrorCategoryConfig = "config"
+	// ErrorCategoryAuthentication means that the cluster or a registry refused the credentials
+	ErrorCategoryAuthentication = "authentication"
+	// ErrorCategoryNetwork means that the cluster or a registry couldn't be reached
+	ErrorCategoryNetwork = "network"
+	// ErrorCategoryTimeout means that resources didn't get ready in time
+	ErrorCategoryTimeout = "timeout"
+	// ErrorCategoryConflict means that resources already exist and are not managed by the release
+	ErrorCategoryConflict = "conflict"
+	// ErrorCategoryUnknown is used for all other errors
+	ErrorCategoryUnknown = "unknown"
+)
+
+// Summary describes what was deployed or why the deployment failed, e.g. to publish it as a CI artifact
+type Summary struct {
+	SchemaVersion string               `json:"schemaVersion"`
+	Status        string               `json:"status"`
+	GeneratedAt   string               `json:"generatedAt"`
+	KubeContext   string               `json:"kubeContext"`
+	Namespace     string               `json:"namespace"`
+	Git           *SummaryGit          `json:"git"`
+	Images        []*SummaryImage      `json:"images"`
+	Deployments   []*SummaryDeployment `json:"deployments"`
+	Links         []string             `json:"links"`
+	Failure       *SummaryFailure      `json:"failure,omitempty"`
+}
+
+// SummaryGit describes the git commit of the working directory
+type SummaryGit struct {
+	Commit string `json:"commit"`
+	Branch string `json:"branch"`
+	Dirty  bool   `json:"dirty"`
+}
+
+// SummaryImage describes an image of the config. The digest is only known for images that were pushed with docker
+type SummaryImage struct {
+	Name   string `json:"name"`
+	Image  string `json:"image"`
+	Tag    string `json:"tag"`
+	Digest string `json:"digest"`
+}
+
+// SummaryDeployment describes a deployment of the config. Chart, chart version and revision are only set for helm
+// releases that were deployed successfully
+type SummaryDeployment struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Namespace    string `json:"namespace"`
+	Chart        string `json:"chart"`
+	ChartVersion string `json:"chartVersion"`
+	Revision     int32  `json:"revision"`
+	DeployedAt   string `json:"deployedAt"`
+}
+
+// SummaryFailure describes why a deployment failed
+type SummaryFailure struct {
+	Phase    string `json:"phase"`
+	Category string `json:"category"`
+	Error    string `json:"error"`
+}
+
+// GetSummary returns the summary of the images and deployments of the config with the tags, digests and release
+// revisions that are recorded in the generated config
+func GetSummary(generatedConfig *generated.Config, status string) *Summary {
+	config := configutil.GetConfig()
+	summary := &Summary{
+		SchemaVersion: SummarySchemaVersion,
+		Status:        status,
+		GeneratedAt:   time.Now().UTC().Format(time.RFC3339),
+		Git:           getGitSummary(),
+		Images:        []*SummaryImage{},
+		Deployments:   []*SummaryDeployment{},
+		Links:         []string{},
+	}
+
+	summary.Namespace, _ = configutil.GetDefaultNamespace(config)
+	if config.Cluster != nil && config.Cluster.KubeContext != nil {
+		summary.KubeContext = *config.Cluster.KubeContext
+	} else {
+		summary.KubeContext, _ = kubeconfig.GetCurrentContext()
+	}
+
+	if config.Images != nil {
+		imageNames := make([]string, 0, len(*config.Images))
+		for imageName := range *config.Images {
+			imageNames = append(imageNames, imageName)
+		}
+		sort.Strings(imageNames)
+
+		for _, imageName := range imageNames {
+			imageConf := (*config.Images)[imageName]
+			image := registry.GetImageURL(generatedConfig, imageConf, false)
+
+			summaryImage := &SummaryImage{
+				Name:  imageName,
+				Image: image,
+				Tag:   strings.TrimPrefix(registry.GetImageURL(generatedConfig, imageConf, true), image+":"),
+			}
+
+			// Digests are recorded with the same key as the image tags
+			name, registryConf, err := registry.GetRegistryConfigFromImageConfig(imageConf)
+			if err == nil {
+				if *registryConf.URL != "" {
+					name = *registryConf.URL + "/" + name
+				}
+
+				summaryImage.Digest = generatedConfig.ImageDigests[name]
+			}
+
+			summary.Images = append(summary.Images, summaryImage)
+		}
+	}
+
+	if config.DevSpace != nil && config.DevSpace.Deployments != nil {
+		for _, deployConfig := range *config.DevSpace.Deployments {
+			deployment := &SummaryDeployment{
+				Name: *deployConfig.Name,
+				Type: "helm",
+			}
+			if deployConfig.Namespace != nil {
+				deployment.Namespace = *deployConfig.Namespace
+			}
+
+			if deployConfig.Kubectl != nil {
+				deployment.Type = "kubectl"
+			} else if release, ok := generatedConfig.Releases[*deployConfig.Name]; ok {
+				deployment.Namespace = release.Namespace
+				deployment.Chart = release.Chart
+				deployment.ChartVersion = release.ChartVersion
+				deployment.Revision = release.Revision
+				deployment.DeployedAt = release.DeployedAt
+			}
+
+			summary.Deployments = append(summary.Deployments, deployment)
+		}
+	}
+
+	if cloud.DevSpaceURL != "" {
+		summary.Links = append(summary.Links, "http://"+cloud.DevSpaceURL)
+	}
+
+	return summary
+}
+
+// SetFailure marks the summary as failed in the given phase
+func (s *Summary) SetFailure(phase string, err error) {
+	s.Status = SummaryStatusFailure
+	s.Failure = &SummaryFailure{
+		Phase:    phase,
+		Category: getErrorCategory(phase, err),
+		Error:    err.Error(),
+	}
+}
+
+// MarshalSummary returns the summary as indented json
+func MarshalSummary(summary *Summary) ([]byte, error) {
+	out, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(out, '\n'), nil
+}
+
+// WriteSummary writes the summary as json to the given path
+func WriteSummary(summary *Summary, path string) error {
+	out, err := MarshalSummary(summary)
+	if err != nil {
+		return fmt.Errorf("Error marshalling summary: %v", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, out, 0644)
+}
+
+// getErrorCategory guesses the category of a deployment error from its message
+func getErrorCategory(phase string, err error) string {
+	message := strings.ToLower(err.Error())
+
+	switch {
+	case err == context.Canceled || strings.Contains(message, "cancelled by user"):
+		return ErrorCategoryCancelled
+	case phase == PhaseConfig:
+		return ErrorCategoryConfig
+	case strings.Contains(message, "unauthorized") || strings.Contains(message, "authentication") || strings.Contains(message, "forbidden") || strings.Contains(message, "denied"):
+		return ErrorCategoryAuthentication
+	case strings.Contains(message, "connection refused") || strings.Contains(message, "no such host") || strings.Contains(message, "i/o timeout") || strings.Contains(message, "network is unreachable"):
+		return ErrorCategoryNetwork
+	case err == context.DeadlineExceeded || strings.Contains(message, "timed out") || strings.Contains(message, "timeout"):
+		return ErrorCategoryTimeout
+	case strings.Contains(message, "conflict") || strings.Contains(message, "already exist"):
+		return ErrorCategoryConflict
+	}
+
+	return ErrorCategoryUnknown
+}
+
+// getGitSummary returns the commit and branch of the working directory. The fields are empty if the working directory
+// is not a git repository or git is not installed
+func getGitSummary() *SummaryGit {
+	summary := &SummaryGit{}
+
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return summary
+	}
+	summary.Commit = strings.TrimSpace(string(out))
+
+	out, err = exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err == nil {
+		summary.Branch = strings.TrimSpace(string(out))
+	}
+
+	out, err = exec.Command("git", "status", "--porcelain").Output()
+	if err == nil {
+		summary.Dirty = len(strings.TrimSpace(string(out))) > 0
+	}
+
+	return summary
+}
@@ -0,0 +1,106 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "Updates the golden files in testdata")
+
+// TestSummaryGolden guards the summary schema that CI pipelines rely on. If this test fails because a field was
+// added, run the test with -update. Renamed or removed fields require a new SummarySchemaVersion
+func TestSummaryGolden(t *testing.T) {
+	summary := &Summary{
+		SchemaVersion: SummarySchemaVersion,
+		Status:        SummaryStatusSuccess,
+		GeneratedAt:   "2019-01-02T15:04:05Z",
+		KubeContext:   "production",
+		Namespace:     "shop",
+		Git: &SummaryGit{
+			Commit: "0123456789abcdef0123456789abcdef01234567",
+			Branch: "master",
+			Dirty:  false,
+		},
+		Images: []*SummaryImage{
+			{
+				Name:   "default",
+				Image:  "registry.example.com/shop/api",
+				Tag:    "a1b2c3d",
+				Digest: "sha256:4d2b3a8c0f2e1d7b6a5c4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b",
+			},
+		},
+		Deployments: []*SummaryDeployment{
+			{
+				Name:         "devspace-default",
+				Type:         "helm",
+				Namespace:    "shop",
+				Chart:        "devspace-app",
+				ChartVersion: "0.1.0",
+				Revision:     4,
+				DeployedAt:   "2019-01-02T15:04:01Z",
+			},
+			{
+				Name:      "database",
+				Type:      "kubectl",
+				Namespace: "shop",
+			},
+		},
+		Links: []string{"http://shop.devspace.host"},
+	}
+
+	compareGolden(t, summary, "summary.golden.json")
+
+	summary.SetFailure(PhaseDeploy, errors.New("Error deploying devspace-default: Unable to deploy helm chart: timed out waiting for the condition"))
+	compareGolden(t, summary, "summary-failure.golden.json")
+}
+
+func compareGolden(t *testing.T, summary *Summary, fileName string) {
+	out, err := MarshalSummary(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	goldenPath := filepath.Join("testdata", fileName)
+	if *updateGolden {
+		err = ioutil.WriteFile(goldenPath, out, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	golden, err := ioutil.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != string(golden) {
+		t.Fatalf("Summary differs from %s. Expected:\n%s\nGot:\n%s", goldenPath, string(golden), string(out))
+	}
+}
+
+func TestGetErrorCategory(t *testing.T) {
+	testCases := []struct {
+		phase    string
+		err      error
+		expected string
+	}{
+		{PhaseBuild, context.Canceled, ErrorCategoryCancelled},
+		{PhaseDeploy, errors.New("Cancelled by user"), ErrorCategoryCancelled},
+		{PhaseConfig, errors.New("Error loading generated.yaml: yaml: line 3: did not find expected key"), ErrorCategoryConfig},
+		{PhaseBuild, errors.New("Error during image registry authentication: unauthorized: incorrect username or password"), ErrorCategoryAuthentication},
+		{PhaseSetup, errors.New("Get https://10.0.0.1:6443/api: dial tcp 10.0.0.1:6443: connect: connection refused"), ErrorCategoryNetwork},
+		{PhaseDeploy, errors.New("Unable to deploy helm chart: timed out waiting for the condition"), ErrorCategoryTimeout},
+		{PhaseDeploy, errors.New("Deployment devspace-default conflicts with existing resources"), ErrorCategoryConflict},
+		{PhaseDeploy, errors.New("Error deploying devspace-default: chart not found"), ErrorCategoryUnknown},
+	}
+
+	for _, testCase := range testCases {
+		category := getErrorCategory(testCase.phase, testCase.err)
+		if category != testCase.expected {
+			t.Fatalf("Expected category %s for %q, got %s", testCase.expected, testCase.err.Error(), category)
+		}
+	}
+}
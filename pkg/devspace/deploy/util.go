@@ -1,6 +1,7 @@
 package deploy
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
@@ -11,12 +12,50 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-// All deploys all deployments in the config
-func All(client *kubernetes.Clientset, generatedConfig *generated.Config, forceDeploy, useDevOverwrite bool, log log.Logger) error {
+// Options holds the options for deploying all deployments
+type Options struct {
+	// ForceDeploy deploys the deployments even if they didn't change since the last deployment
+	ForceDeploy bool
+
+	// UseDevOverwrite applies the devOverwrite values of helm deployments
+	UseDevOverwrite bool
+
+	// SkipConflictCheck skips the check for resources that conflict with helm charts. ForceConflicts deploys helm
+	// charts despite conflicts
+	SkipConflictCheck bool
+	ForceConflicts    bool
+
+	// UpdateDependencies resolves the dependencies of helm charts again
+	UpdateDependencies bool
+
+	// CleanupOnFailure rolls back or purges failed helm releases
+	CleanupOnFailure bool
+
+	// ShowValuesDiff prints the values that changed since the last deployment of a helm release
+	ShowValuesDiff bool
+
+	// RollbackOnFailure rolls back helm releases whose pod doesn't get ready
+	RollbackOnFailure bool
+
+	// ContainerEnv is injected into all containers of helm charts
+	ContainerEnv map[string]string
+
+	// Values and SecretValues (dotted keys) overwrite the values of helm charts
+	Values       map[string]string
+	SecretValues map[string]string
+}
+
+// All deploys all deployments in the config with the given options. Deploying stops as soon as the context is
+// cancelled
+func All(ctx context.Context, client kubernetes.Interface, generatedConfig *generated.Config, options *Options, log log.Logger) error {
 	config := configutil.GetConfig()
 
 	if config.DevSpace.Deployments != nil {
 		for _, deployConfig := range *config.DevSpace.Deployments {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
 			var deployClient Interface
 			var err error
 
@@ -30,15 +69,26 @@ func All(client *kubernetes.Clientset, generatedConfig *generated.Config, forceD
 			} else if deployConfig.Helm != nil {
 				log.Info("Deploying " + *deployConfig.Name + " with helm")
 
-				deployClient, err = helm.New(client, deployConfig, useDevOverwrite, log)
+				helmClient, err := helm.New(client, deployConfig, options.UseDevOverwrite, log)
 				if err != nil {
 					return fmt.Errorf("Error deploying devspace: deployment %s error: %v", *deployConfig.Name, err)
 				}
+
+				helmClient.SkipConflictCheck = options.SkipConflictCheck
+				helmClient.ForceConflicts = options.ForceConflicts
+				helmClient.UpdateDependencies = options.UpdateDependencies
+				helmClient.ContainerEnv = options.ContainerEnv
+				helmClient.Values = options.Values
+				helmClient.SecretValues = options.SecretValues
+				helmClient.CleanupOnFailure = options.CleanupOnFailure
+				helmClient.ShowValuesDiff = options.ShowValuesDiff
+				helmClient.RollbackOnFailure = options.RollbackOnFailure
+				deployClient = helmClient
 			} else {
 				return fmt.Errorf("Error deploying devspace: deployment %s has no deployment method", *deployConfig.Name)
 			}
 
-			err = deployClient.Deploy(generatedConfig, forceDeploy)
+			err = deployClient.Deploy(ctx, generatedConfig, options.ForceDeploy)
 			if err != nil {
 				return fmt.Errorf("Error deploying %s: %v", *deployConfig.Name, err)
 			}
@@ -2,6 +2,8 @@ package docker
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -132,6 +134,25 @@ func getDefaultAuthConfig(checkCredStore bool, serverAddress string, isDefaultRe
 		}
 	}
 
+	if authconfig.Username == "" && authconfig.Password == "" && authconfig.Auth != "" {
+		authconfig.Username, authconfig.Password, err = decodeAuth(authconfig.Auth)
+	}
+
 	authconfig.ServerAddress = serverAddress
 	return &authconfig, err
 }
+
+// decodeAuth decodes the base64 encoded 'user:password' auth field of the docker config
+func decodeAuth(authStr string) (string, string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(authStr)
+	if err != nil {
+		return "", "", fmt.Errorf("Unable to decode auth field: %v", err)
+	}
+
+	splitted := strings.SplitN(string(decoded), ":", 2)
+	if len(splitted) != 2 {
+		return "", "", errors.New("Invalid auth field in docker config")
+	}
+
+	return splitted[0], strings.Trim(splitted[1], "\x00"), nil
+}
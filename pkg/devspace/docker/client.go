@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -87,6 +88,23 @@ func newDockerClientFromMinikube() (client.CommonAPIClient, error) {
 	return client.NewClient(host, version, httpclient, nil)
 }
 
+// GetCliEnvironment returns the environment for docker cli commands, so that they use the same docker daemon as
+// NewClient(preferMinikube)
+func GetCliEnvironment(preferMinikube bool) []string {
+	env := os.Environ()
+
+	if preferMinikube && kubectl.IsMinikube() {
+		minikubeEnv, err := getMinikubeEnvironment()
+		if err == nil {
+			for key, value := range minikubeEnv {
+				env = append(env, key+"="+value)
+			}
+		}
+	}
+
+	return env
+}
+
 func getMinikubeEnvironment() (map[string]string, error) {
 	cmd := exec.Command("minikube", "docker-env", "--shell", "none")
 	out, err := cmd.Output()
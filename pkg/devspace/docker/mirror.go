@@ -0,0 +1,72 @@
+package docker
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/docker/docker/client"
+)
+
+// DockerHubRegistry is the registry of images without registry (e.g. alpine or bitnami/redis)
+const DockerHubRegistry = "docker.io"
+
+// RateLimitHint is added to errors caused by the pull rate limit of a registry
+const RateLimitHint = "The registry rejected the pull because of its rate limit (429 Too Many Requests). Configure a pull-through cache for the base images with images.*.build.registryMirrors (see https://docs.devspace-cloud.com/docs/configuration/config.yaml.html)"
+
+// rateLimitRegex matches the errors of docker, BuildKit and kaniko for pulls that were rate limited
+var rateLimitRegex = regexp.MustCompile(`(?i)toomanyrequests|429 too many requests|pull rate limit`)
+
+// IsRateLimitError checks if the error message (or build output) contains a rate limit error of a registry
+func IsRateLimitError(message string) bool {
+	return rateLimitRegex.MatchString(message)
+}
+
+// NormalizeRegistry returns docker.io for all names of docker hub and an empty registry
+func NormalizeRegistry(registry string) string {
+	switch strings.TrimSuffix(registry, "/") {
+	case "", "docker.io", "index.docker.io", "registry-1.docker.io", "hub.docker.com":
+		return DockerHubRegistry
+	}
+
+	return strings.TrimSuffix(registry, "/")
+}
+
+// NormalizeMirror removes the scheme and trailing slashes of a mirror url (e.g. https://mirror.internal:5000/
+// becomes mirror.internal:5000)
+func NormalizeMirror(mirrorURL string) string {
+	mirrorURL = strings.TrimPrefix(mirrorURL, "https://")
+	mirrorURL = strings.TrimPrefix(mirrorURL, "http://")
+
+	return strings.TrimSuffix(mirrorURL, "/")
+}
+
+// CheckDaemonMirrors warns if the docker daemon doesn't use the docker hub mirrors. The daemon only pulls the base
+// images through registry-mirrors of its own config, devspace can't pass them to a build
+func CheckDaemonMirrors(ctx context.Context, client client.CommonAPIClient, mirrors []string, log log.Logger) {
+	if len(mirrors) == 0 {
+		return
+	}
+
+	info, err := client.Info(ctx)
+	if err != nil {
+		log.Warnf("Unable to check the registry mirrors of the docker daemon: %v", err)
+		return
+	}
+
+	daemonMirrors := map[string]bool{}
+	if info.RegistryConfig != nil {
+		for _, daemonMirror := range info.RegistryConfig.Mirrors {
+			daemonMirrors[NormalizeMirror(daemonMirror)] = true
+		}
+	}
+
+	for _, mirror := range mirrors {
+		if daemonMirrors[NormalizeMirror(mirror)] {
+			continue
+		}
+
+		log.Warnf("The docker daemon doesn't use the registry mirror %s, so base images are pulled from docker hub. Add it to \"registry-mirrors\" in the daemon.json of the docker daemon (e.g. /etc/docker/daemon.json or Docker Desktop > Preferences > Daemon) and restart docker, or set rewriteBaseImages: true to pull the base images from the mirror directly", mirror)
+	}
+}
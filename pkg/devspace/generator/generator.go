@@ -9,12 +9,25 @@ import (
 	"strings"
 	"time"
 
+	"github.com/covexo/devspace/pkg/devspace/generator/templates/dockerfiles"
 	"github.com/covexo/devspace/pkg/util/fsutil"
 
 	"gopkg.in/src-d/enry.v1"
 	"gopkg.in/src-d/go-git.v4"
 )
 
+// projectFiles are the files that identify the language of a project, in the order in which they are checked
+var projectFiles = []struct {
+	fileName string
+	language string
+}{
+	{fileName: "go.mod", language: "go"},
+	{fileName: "package.json", language: "node"},
+	{fileName: "requirements.txt", language: "python"},
+	{fileName: "pom.xml", language: "java"},
+	{fileName: "Gemfile", language: "ruby"},
+}
+
 // ChartGenerator is a type of object that generates a Helm Chart
 type ChartGenerator struct {
 	Path               string
@@ -53,7 +66,8 @@ func (cg *ChartGenerator) IsSupportedLanguage(language string) bool {
 	return false
 }
 
-// GetSupportedLanguages returns all languages that are available in the local Template Rempository
+// GetSupportedLanguages returns all languages that are available in the local Template Rempository or have a
+// Dockerfile template
 func (cg *ChartGenerator) GetSupportedLanguages() ([]string, error) {
 	chartCloneErr := cg.getChartTemplates()
 
@@ -75,6 +89,17 @@ func (cg *ChartGenerator) GetSupportedLanguages() ([]string, error) {
 				cg.supportedLanguages = append(cg.supportedLanguages, fileName)
 			}
 		}
+
+	LANGUAGES:
+		for _, language := range dockerfiles.Languages() {
+			for _, supportedLanguage := range cg.supportedLanguages {
+				if language == supportedLanguage {
+					continue LANGUAGES
+				}
+			}
+
+			cg.supportedLanguages = append(cg.supportedLanguages, language)
+		}
 	}
 	return cg.supportedLanguages, nil
 }
@@ -91,19 +116,35 @@ func (cg *ChartGenerator) CreateChart() error {
 		return langError
 	}
 
+	dockerfile, hasDockerfile := dockerfiles.Get(language)
 	_, languageTemplateNotFound := os.Stat(cg.TemplateRepo.LocalPath + "/" + language)
-	if languageTemplateNotFound != nil {
+	if languageTemplateNotFound != nil && hasDockerfile == false {
 		return errors.New("Language Template not found")
 	}
 
+	// The Dockerfile template is written first, so that it isn't replaced by the Dockerfile of the template repository
+	if hasDockerfile {
+		dockerfilePath := filepath.Join(cg.Path, "Dockerfile")
+
+		_, err := os.Stat(dockerfilePath)
+		if os.IsNotExist(err) {
+			err = fsutil.WriteToFile([]byte(dockerfile), dockerfilePath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	copyBaseError := fsutil.Copy(cg.TemplateRepo.LocalPath+"/_base", cg.Path, false)
 	if copyBaseError != nil {
 		return copyBaseError
 	}
 
-	copyError := fsutil.Copy(cg.TemplateRepo.LocalPath+"/"+cg.Language, cg.Path, false)
-	if copyError != nil {
-		return copyError
+	if languageTemplateNotFound == nil {
+		copyError := fsutil.Copy(cg.TemplateRepo.LocalPath+"/"+cg.Language, cg.Path, false)
+		if copyError != nil {
+			return copyError
+		}
 	}
 
 	return nil
@@ -138,6 +179,13 @@ func (cg *ChartGenerator) getChartTemplates() error {
 }
 
 func (cg *ChartGenerator) detectLanguage() error {
+	// Projects with a dependency file of a known language don't need to be scanned
+	projectLanguage := detectProjectLanguage(cg.Path)
+	if projectLanguage != "" && cg.IsSupportedLanguage(projectLanguage) {
+		cg.Language = projectLanguage
+		return nil
+	}
+
 	contentReadLimit := int64(16 * 1024 * 1024)
 	bytesByLanguage := make(map[string]int64, 0)
 
@@ -235,3 +283,15 @@ func (cg *ChartGenerator) detectLanguage() error {
 	}
 	return nil
 }
+
+// detectProjectLanguage returns the language of the first project file (e.g. go.mod) that exists in path
+func detectProjectLanguage(path string) string {
+	for _, projectFile := range projectFiles {
+		_, err := os.Stat(filepath.Join(path, projectFile.fileName))
+		if err == nil {
+			return projectFile.language
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,32 @@
+package generator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectProjectLanguage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if language := detectProjectLanguage(dir); language != "" {
+		t.Fatalf("Expected no language for an empty project, got %s", language)
+	}
+
+	// requirements.txt is checked before pom.xml
+	for _, fileName := range []string{"pom.xml", "requirements.txt"} {
+		err = ioutil.WriteFile(filepath.Join(dir, fileName), []byte{}, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if language := detectProjectLanguage(dir); language != "python" {
+		t.Fatalf("Expected python, got %s", language)
+	}
+}
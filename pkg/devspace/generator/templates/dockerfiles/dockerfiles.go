@@ -0,0 +1,29 @@
+package dockerfiles
+
+import "sort"
+
+// dockerfiles maps the languages to their Dockerfile templates
+var dockerfiles = map[string]string{
+	"go":     goDockerfile,
+	"java":   javaDockerfile,
+	"node":   nodeDockerfile,
+	"python": pythonDockerfile,
+	"ruby":   rubyDockerfile,
+}
+
+// Get returns the Dockerfile template for the language
+func Get(language string) (string, bool) {
+	dockerfile, ok := dockerfiles[language]
+	return dockerfile, ok
+}
+
+// Languages returns the languages that have a Dockerfile template
+func Languages() []string {
+	languages := make([]string, 0, len(dockerfiles))
+	for language := range dockerfiles {
+		languages = append(languages, language)
+	}
+
+	sort.Strings(languages)
+	return languages
+}
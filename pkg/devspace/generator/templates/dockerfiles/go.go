@@ -0,0 +1,22 @@
+package dockerfiles
+
+const goDockerfile = `FROM golang:1.21-alpine AS builder
+
+WORKDIR /app
+
+# Download the dependencies in a separate layer, so that they are only downloaded again if go.mod changes
+COPY go.mod go.sum* ./
+RUN go mod download
+
+COPY . .
+RUN CGO_ENABLED=0 go build -o /main .
+
+FROM scratch
+
+COPY --from=builder /etc/ssl/certs/ca-certificates.crt /etc/ssl/certs/
+COPY --from=builder /main /main
+
+EXPOSE 8080
+
+ENTRYPOINT ["/main"]
+`
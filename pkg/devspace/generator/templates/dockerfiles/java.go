@@ -0,0 +1,22 @@
+package dockerfiles
+
+const javaDockerfile = `FROM maven:3.9-eclipse-temurin-21 AS builder
+
+WORKDIR /app
+
+# Download the dependencies in a separate layer, so that they are only downloaded again if pom.xml changes
+COPY pom.xml .
+RUN mvn -B dependency:go-offline
+
+COPY src ./src
+RUN mvn -B package -DskipTests && cp target/*.jar /app.jar
+
+FROM eclipse-temurin:21-jre
+
+WORKDIR /app
+COPY --from=builder /app.jar app.jar
+
+EXPOSE 8080
+
+CMD ["java", "-jar", "app.jar"]
+`
@@ -0,0 +1,20 @@
+package dockerfiles
+
+const nodeDockerfile = `FROM node:20-alpine AS dependencies
+
+WORKDIR /app
+
+# Install the dependencies in a separate layer, so that they are only installed again if package.json changes
+COPY package.json package-lock.json* ./
+RUN npm install
+
+FROM node:20-alpine
+
+WORKDIR /app
+COPY --from=dependencies /app/node_modules ./node_modules
+COPY . .
+
+EXPOSE 3000
+
+CMD ["npm", "start"]
+`
@@ -0,0 +1,20 @@
+package dockerfiles
+
+const pythonDockerfile = `FROM python:3.12-slim AS builder
+
+WORKDIR /app
+
+# Install the dependencies in a separate layer, so that they are only installed again if requirements.txt changes
+COPY requirements.txt .
+RUN pip install --no-cache-dir --prefix=/install -r requirements.txt
+
+FROM python:3.12-slim
+
+WORKDIR /app
+COPY --from=builder /install /usr/local
+COPY . .
+
+EXPOSE 8000
+
+CMD ["python", "main.py"]
+`
@@ -0,0 +1,22 @@
+package dockerfiles
+
+const rubyDockerfile = `FROM ruby:3.3-alpine AS builder
+
+RUN apk add --no-cache build-base
+
+WORKDIR /app
+
+# Install the gems in a separate layer, so that they are only installed again if the Gemfile changes
+COPY Gemfile Gemfile.lock* ./
+RUN bundle install
+
+FROM ruby:3.3-alpine
+
+WORKDIR /app
+COPY --from=builder /usr/local/bundle /usr/local/bundle
+COPY . .
+
+EXPOSE 3000
+
+CMD ["bundle", "exec", "ruby", "app.rb"]
+`
@@ -1,22 +1,24 @@
 package helm
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/covexo/devspace/pkg/util/fsutil"
 	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/signalutil"
 
 	"k8s.io/helm/pkg/getter"
-	"k8s.io/helm/pkg/kube"
 	"k8s.io/helm/pkg/repo"
 
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
 	"github.com/covexo/devspace/pkg/devspace/kubectl"
@@ -25,98 +27,165 @@ import (
 	helmenvironment "k8s.io/helm/pkg/helm/environment"
 	"k8s.io/helm/pkg/helm/helmpath"
 	"k8s.io/helm/pkg/helm/portforwarder"
+	"k8s.io/helm/pkg/kube"
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
 	rls "k8s.io/helm/pkg/proto/hapi/services"
-	helmstoragedriver "k8s.io/helm/pkg/storage/driver"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	yaml "gopkg.in/yaml.v2"
 )
 
-// Get Client only once
-var helmClient *ClientWrapper
-var getOnce sync.Once
+// clients caches the helm clients by tiller namespace, so that repeated calls within one command reuse the tunnel
+// to tiller
+var clients = map[string]*ClientWrapper{}
+var clientsMutex sync.Mutex
 
 // ClientWrapper holds the necessary information for helm
 type ClientWrapper struct {
-	Client    *k8shelm.Client
+	Client    k8shelm.Interface
 	Settings  *helmenvironment.EnvSettings
 	Namespace string
-	kubectl   *kubernetes.Clientset
-}
+	kubectl   kubernetes.Interface
 
-// NewClient creates a new helm client
-func NewClient(kubectlClient *kubernetes.Clientset, log log.Logger, upgradeTiller bool) (*ClientWrapper, error) {
-	var outerError error
+	// Tillerless is true if charts are rendered locally and applied without tiller. Client is nil in this case
+	Tillerless bool
 
-	getOnce.Do(func() {
-		helmClient, outerError = createNewClient(kubectlClient, log, upgradeTiller)
-	})
+	// tunnel is the port forwarding to tiller (nil if tillerless)
+	tunnel *kube.Tunnel
+
+	// redial opens a new tunnel to tiller and returns a client that uses it (nil if tillerless)
+	redial func() (k8shelm.Interface, *kube.Tunnel, error)
 
-	return helmClient, outerError
+	// resources replaces the rest client of kubectl for requests to arbitrary resources (only set in tests)
+	resources resourceClient
 }
 
-func createNewClient(kubectlClient *kubernetes.Clientset, log log.Logger, upgradeTiller bool) (*ClientWrapper, error) {
+// tillerConnectTimeout is the time in seconds to wait for a connection to tiller before a call fails
+const tillerConnectTimeout = int64(5)
+
+// NewClient creates a new helm client for the tiller namespace of the config
+func NewClient(kubectlClient kubernetes.Interface, log log.Logger, upgradeTiller bool) (*ClientWrapper, error) {
+	return NewClientWithContext(context.Background(), kubectlClient, log, upgradeTiller)
+}
+
+// NewClientWithContext creates a new helm client for the tiller namespace of the config and stops waiting for tiller
+// as soon as the context is cancelled
+func NewClientWithContext(ctx context.Context, kubectlClient kubernetes.Interface, log log.Logger, upgradeTiller bool) (*ClientWrapper, error) {
 	config := configutil.GetConfig()
 	if config.Tiller == nil || config.Tiller.Namespace == nil {
 		return nil, errors.New("No tiller namespace specified")
 	}
 
-	tillerNamespace := *config.Tiller.Namespace
-	kubeconfig, err := kubectl.GetClientConfig()
-	if err != nil {
-		return nil, err
+	return NewClientForNamespace(ctx, kubectlClient, *config.Tiller.Namespace, log, upgradeTiller)
+}
+
+// NewClientForNamespace returns the helm client for tiller in tillerNamespace. The client is created (and tiller is
+// deployed if necessary) on the first call, later calls return the same client until it is closed
+func NewClientForNamespace(ctx context.Context, kubectlClient kubernetes.Interface, tillerNamespace string, log log.Logger, upgradeTiller bool) (*ClientWrapper, error) {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+
+	if client, ok := clients[tillerNamespace]; ok {
+		return client, nil
 	}
 
-	err = ensureTiller(kubectlClient, config, upgradeTiller)
+	client, err := createNewClient(ctx, kubectlClient, tillerNamespace, log, upgradeTiller)
 	if err != nil {
 		return nil, err
 	}
 
-	var tunnel *kube.Tunnel
-	var client *k8shelm.Client
+	clients[tillerNamespace] = client
+	return client, nil
+}
 
-	tunnelWaitTime := 2 * 60 * time.Second
-	tunnelCheckInterval := 5 * time.Second
+// Close closes the tunnel to tiller and removes the client from the cache, so that the next call of NewClient creates
+// a new one. The client must not be used afterwards
+func (helmClientWrapper *ClientWrapper) Close() {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
 
-	log.StartWait("Waiting for " + tillerNamespace + "/tiller-deploy to become ready")
-	defer log.StopWait()
+	if clients[helmClientWrapper.Namespace] == helmClientWrapper {
+		delete(clients, helmClientWrapper.Namespace)
+	}
 
-	for true {
-		// Next we wait till we can establish a tunnel to the running pod
-		for true {
-			tunnel, err = portforwarder.New(tillerNamespace, kubectlClient, kubeconfig)
-			if err == nil && tunnel != nil {
-				break
-			}
+	helmClientWrapper.closeTunnel()
+}
 
-			if tunnelWaitTime <= 0 {
-				return nil, err
-			}
+// CloseClients closes all cached helm clients, e.g. before devspace up starts the long running services
+func CloseClients() {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
 
-			tunnelWaitTime = tunnelWaitTime - tunnelCheckInterval
-			time.Sleep(tunnelCheckInterval)
-		}
+	for namespace, client := range clients {
+		client.closeTunnel()
+		delete(clients, namespace)
+	}
+}
 
-		helmOptions := []k8shelm.Option{
-			k8shelm.Host("127.0.0.1:" + strconv.Itoa(tunnel.Local)),
-			k8shelm.ConnectTimeout(int64(5 * time.Second)),
-		}
+func (helmClientWrapper *ClientWrapper) closeTunnel() {
+	if helmClientWrapper.tunnel != nil {
+		helmClientWrapper.tunnel.Close()
+		helmClientWrapper.tunnel = nil
+	}
+}
 
-		client = k8shelm.NewClient(helmOptions...)
+func createNewClient(ctx context.Context, kubectlClient kubernetes.Interface, tillerNamespace string, log log.Logger, upgradeTiller bool) (*ClientWrapper, error) {
+	config := configutil.GetConfig()
+	tillerless := IsTillerless()
+
+	// Tiller of a bootstrapped cluster is only set up by devspace bootstrap
+	bootstrapped, err := kubectl.IsBootstrapped(kubectlClient)
+	if err != nil {
+		return nil, err
+	}
 
-		_, err = client.ListReleases(k8shelm.ReleaseListLimit(1))
-		if err == nil {
-			break
+	var client k8shelm.Interface
+	var tunnel *kube.Tunnel
+	var redial func() (k8shelm.Interface, *kube.Tunnel, error)
+	if tillerless {
+		// The releases are recorded in the tiller namespace, but neither tiller nor its roles or a tunnel are needed.
+		// The namespace of a bootstrapped cluster exists, because it contains the bootstrap config map
+		if bootstrapped == false {
+			err := ensureNamespace(kubectlClient, tillerNamespace)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		kubeconfig, err := kubectl.GetClientConfig()
+		if err != nil {
+			return nil, err
 		}
 
-		tunnel.Close()
+		if bootstrapped {
+			err = checkTiller(ctx, kubectlClient, tillerNamespace)
+		} else if sharedTiller {
+			err = checkSharedTiller(ctx, kubectlClient, tillerNamespace)
+		} else {
+			err = ensureTiller(ctx, kubectlClient, config, tillerNamespace, upgradeTiller)
+		}
+		if err != nil {
+			return nil, err
+		}
 
-		tunnelWaitTime = tunnelWaitTime - tunnelCheckInterval
-		time.Sleep(tunnelCheckInterval)
+		client, tunnel, err = connectToTiller(ctx, kubectlClient, kubeconfig, tillerNamespace, log)
+		if err != nil {
+			return nil, err
+		}
 
-		if tunnelWaitTime < 0 {
-			return nil, errors.New("Waiting for tiller timed out")
+		redial = func() (k8shelm.Interface, *kube.Tunnel, error) {
+			return connectToTiller(context.Background(), kubectlClient, kubeconfig, tillerNamespace, log)
 		}
 	}
 
-	log.StopWait()
+	// The tunnel is only handed over to the wrapper if the client was created successfully
+	success := false
+	defer func() {
+		if success == false && tunnel != nil {
+			tunnel.Close()
+		}
+	}()
 
 	homeDir, err := homedir.Dir()
 	if err != nil {
@@ -145,22 +214,172 @@ func createNewClient(kubectlClient *kubernetes.Clientset, log log.Logger, upgrad
 		Settings: &helmenvironment.EnvSettings{
 			Home: helmpath.Home(helmHomePath),
 		},
-		Namespace: tillerNamespace,
-		kubectl:   kubectlClient,
+		Namespace:  tillerNamespace,
+		kubectl:    kubectlClient,
+		Tillerless: tillerless,
+		tunnel:     tunnel,
+		redial:     redial,
 	}
 
-	_, err = os.Stat(stableRepoCachePathAbs)
+	repoEntries, err := getRepositoryEntries(config, wrapper.Settings.Home)
+	if err != nil {
+		return nil, err
+	}
+
+	reposChanged, err := mergeRepositories(repoFile, repoEntries)
 	if err != nil {
-		err = wrapper.updateRepos()
+		return nil, err
+	}
+
+	_, err = os.Stat(stableRepoCachePathAbs)
+	if err != nil || reposChanged || isRepoCacheMissing(repoEntries) {
+		err = checkRepoUpdateError(wrapper.updateRepos(ctx), log)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	success = true
 	return wrapper, nil
 }
 
-func (helmClientWrapper *ClientWrapper) updateRepos() error {
+// retryOnConnectionError calls tiller and retries the call once with a new tunnel if tiller wasn't reachable, e.g.
+// because the port forwarding broke after a network change. Only calls that don't change a release may be retried,
+// calls that do have to use callOnce
+func (helmClientWrapper *ClientWrapper) retryOnConnectionError(call func() error) error {
+	err := call()
+	if err == nil || helmClientWrapper.redial == nil || isConnectionError(err) == false {
+		return err
+	}
+
+	redialErr := helmClientWrapper.reconnect()
+	if redialErr != nil {
+		return fmt.Errorf("%v (reconnecting to tiller failed: %v)", err, redialErr)
+	}
+
+	return call()
+}
+
+// callOnce calls tiller with a call that changes the release. The call is only retried if the connection to tiller
+// couldn't be established. If the connection broke during the call, tiller might have changed the release already,
+// so the client reconnects and the status of the release is returned instead of calling tiller again
+func (helmClientWrapper *ClientWrapper) callOnce(releaseName string, call func() error) error {
+	err := call()
+	if err == nil || helmClientWrapper.redial == nil || isConnectionError(err) == false {
+		return err
+	}
+
+	redialErr := helmClientWrapper.reconnect()
+	if redialErr != nil {
+		return fmt.Errorf("%v (reconnecting to tiller failed: %v)", err, redialErr)
+	}
+	if isDialError(err) {
+		return call()
+	}
+
+	release, statusErr := helmClientWrapper.GetLatestRelease(releaseName)
+	if statusErr != nil {
+		return fmt.Errorf("Connection to tiller broke while changing release %s: %v. Retrieving the status of the release failed: %v", releaseName, err, statusErr)
+	}
+	if release == nil {
+		return fmt.Errorf("Connection to tiller broke while changing release %s: %v. The release doesn't exist", releaseName, err)
+	}
+
+	return fmt.Errorf("Connection to tiller broke while changing release %s: %v. The release has revision %d with status %s, check it with `helm history %s`", releaseName, err, release.Version, release.GetInfo().GetStatus().GetCode().String(), releaseName)
+}
+
+// reconnect replaces the tunnel to tiller and the client that uses it
+func (helmClientWrapper *ClientWrapper) reconnect() error {
+	client, tunnel, err := helmClientWrapper.redial()
+	if err != nil {
+		return err
+	}
+
+	helmClientWrapper.closeTunnel()
+	helmClientWrapper.Client = client
+	helmClientWrapper.tunnel = tunnel
+
+	return nil
+}
+
+// isConnectionError checks if a call failed, because the connection to tiller couldn't be established or broke
+func isConnectionError(err error) bool {
+	return isDialError(err) || status.Code(err) == codes.Unavailable
+}
+
+// isDialError checks if a call failed, because the connection to tiller couldn't be established. Tiller didn't
+// receive the call in this case
+func isDialError(err error) bool {
+	return err == context.DeadlineExceeded
+}
+
+// connectToTiller opens a tunnel to the tiller pod and waits until tiller is able to serve requests. The first
+// attempt is made immediately, so a running tiller doesn't cause any delay. The tunnel has to be closed by the caller
+func connectToTiller(ctx context.Context, kubectlClient kubernetes.Interface, kubeconfig *rest.Config, tillerNamespace string, log log.Logger) (*k8shelm.Client, *kube.Tunnel, error) {
+	deadline := time.Now().Add(tillerWaitTimeout)
+	waiting := false
+
+	for {
+		tunnel, err := portforwarder.New(tillerNamespace, kubectlClient, kubeconfig)
+		if err == nil && tunnel != nil {
+			helmOptions := []k8shelm.Option{
+				k8shelm.Host("127.0.0.1:" + strconv.Itoa(tunnel.Local)),
+				k8shelm.ConnectTimeout(tillerConnectTimeout),
+			}
+
+			client := k8shelm.NewClient(helmOptions...)
+
+			err = checkTillerHealth(client)
+			if err == nil {
+				return client, tunnel, nil
+			}
+
+			tunnel.Close()
+		}
+
+		if time.Now().After(deadline) {
+			if err != nil {
+				return nil, nil, fmt.Errorf("Waiting for tiller timed out: %v", err)
+			}
+
+			return nil, nil, errors.New("Waiting for tiller timed out")
+		}
+
+		// Only show the wait message if tiller isn't reachable right away
+		if waiting == false {
+			waiting = true
+
+			log.StartWait("Waiting for " + tillerNamespace + "/tiller-deploy to become ready")
+			defer log.StopWait()
+		}
+
+		err = signalutil.Sleep(ctx, tillerRetryInterval)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// checkTillerHealth checks if tiller is able to serve requests. It uses the version rpc, because listing releases
+// can be slow on clusters with a large release history, and only falls back to listing releases for tiller
+// versions that do not support the version rpc
+func checkTillerHealth(client k8shelm.Interface) error {
+	_, err := client.GetVersion()
+	if err == nil {
+		return nil
+	}
+
+	if status.Code(err) != codes.Unimplemented {
+		return err
+	}
+
+	_, err = client.ListReleases(k8shelm.ReleaseListLimit(1))
+	return err
+}
+
+// updateRepos downloads the index files of all repositories. The credentials and the ca file of the repositories
+// file entries are used by the getters of the chart repositories
+func (helmClientWrapper *ClientWrapper) updateRepos(ctx context.Context) error {
 	allRepos, err := repo.LoadRepositoriesFile(helmClientWrapper.Settings.Home.RepositoryFile())
 	if err != nil {
 		return err
@@ -178,6 +397,11 @@ func (helmClientWrapper *ClientWrapper) updateRepos() error {
 	}
 
 	wg := sync.WaitGroup{}
+	errorsMutex := sync.Mutex{}
+	repoErrors := map[string]error{}
+
+	// Limit the number of concurrent downloads, so that many repositories don't open too many connections at once
+	semaphore := make(chan struct{}, maxConcurrentRepoUpdates)
 
 	for _, re := range repos {
 		wg.Add(1)
@@ -185,35 +409,188 @@ func (helmClientWrapper *ClientWrapper) updateRepos() error {
 		go func(re *repo.ChartRepository) {
 			defer wg.Done()
 
-			err := re.DownloadIndexFile(helmClientWrapper.Settings.Home.String())
-			if err != nil {
-				log.With(err).Error("Unable to download repo index")
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
 
-				//TODO
+			err := downloadIndexFile(ctx, re, helmClientWrapper.Settings.Home.String())
+			if err != nil {
+				errorsMutex.Lock()
+				repoErrors[re.Config.Name] = err
+				errorsMutex.Unlock()
 			}
 		}(re)
 	}
 
 	wg.Wait()
 
+	if len(repoErrors) > 0 {
+		return &RepoUpdateError{
+			Errors: repoErrors,
+		}
+	}
+
 	return nil
 }
 
 // ReleaseExists checks if the given release name exists
 func (helmClientWrapper *ClientWrapper) ReleaseExists(releaseName string) (bool, error) {
-	_, err := helmClientWrapper.Client.ReleaseHistory(releaseName, k8shelm.WithMaxHistory(1))
+	release, err := helmClientWrapper.GetLatestRelease(releaseName)
 	if err != nil {
-		if strings.Contains(err.Error(), helmstoragedriver.ErrReleaseNotFound(releaseName).Error()) {
-			return false, nil
+		return false, err
+	}
+
+	return release != nil, nil
+}
+
+// GetLatestRelease returns the latest revision of the release or nil if the release doesn't exist. A release that was
+// deleted without purging (devspace down) can't be upgraded, so it is treated as not existing
+func (helmClientWrapper *ClientWrapper) GetLatestRelease(releaseName string) (*hapi_release5.Release, error) {
+	if helmClientWrapper.Tillerless {
+		release, err := helmClientWrapper.loadTillerlessRelease(releaseName)
+		if err != nil {
+			if isReleaseNotFound(err, releaseName) {
+				return nil, nil
+			}
+
+			return nil, err
 		}
 
-		return false, err
+		return release, nil
 	}
 
-	return true, nil
+	var history *rls.GetHistoryResponse
+	err := helmClientWrapper.retryOnConnectionError(func() error {
+		var err error
+		history, err = helmClientWrapper.Client.ReleaseHistory(releaseName, k8shelm.WithMaxHistory(1))
+		return err
+	})
+	if err != nil {
+		if isReleaseNotFound(err, releaseName) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if len(history.Releases) == 0 || history.Releases[0].GetInfo().GetStatus().GetCode() == hapi_release5.Status_DELETED {
+		return nil, nil
+	}
+
+	return history.Releases[0], nil
 }
 
-// DeleteRelease deletes a helm release and optionally purges it
+// ListReleases returns all releases that are not deleted or superseded by a newer revision
+func (helmClientWrapper *ClientWrapper) ListReleases() ([]*hapi_release5.Release, error) {
+	if helmClientWrapper.Tillerless {
+		return helmClientWrapper.listReleasesTillerless()
+	}
+
+	var response *rls.ListReleasesResponse
+	err := helmClientWrapper.retryOnConnectionError(func() error {
+		var err error
+		response, err = helmClientWrapper.Client.ListReleases(
+			k8shelm.ReleaseListStatuses([]hapi_release5.Status_Code{
+				hapi_release5.Status_UNKNOWN,
+				hapi_release5.Status_DEPLOYED,
+				hapi_release5.Status_FAILED,
+				hapi_release5.Status_DELETING,
+				hapi_release5.Status_PENDING_INSTALL,
+				hapi_release5.Status_PENDING_UPGRADE,
+				hapi_release5.Status_PENDING_ROLLBACK,
+			}),
+			k8shelm.ReleaseListSort(int32(rls.ListSort_NAME)),
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		return []*hapi_release5.Release{}, nil
+	}
+
+	return response.Releases, nil
+}
+
+// GetValues returns the values that were supplied when the release was deployed. The default values of the chart
+// are not included
+func (helmClientWrapper *ClientWrapper) GetValues(releaseName string) (map[interface{}]interface{}, error) {
+	release, err := helmClientWrapper.getRelease(releaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[interface{}]interface{}{}
+
+	raw := release.GetConfig().GetRaw()
+	if raw == "" {
+		return values, nil
+	}
+
+	err = yaml.Unmarshal([]byte(raw), values)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing values of release %s: %v", releaseName, err)
+	}
+
+	return values, nil
+}
+
+// getRelease returns the latest revision of the release
+func (helmClientWrapper *ClientWrapper) getRelease(releaseName string) (*hapi_release5.Release, error) {
+	if helmClientWrapper.Tillerless {
+		return helmClientWrapper.loadTillerlessRelease(releaseName)
+	}
+
+	var response *rls.GetReleaseContentResponse
+	err := helmClientWrapper.retryOnConnectionError(func() error {
+		var err error
+		response, err = helmClientWrapper.Client.ReleaseContent(releaseName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response.GetRelease(), nil
+}
+
+// DeleteRelease deletes a helm release and optionally purges it. Releases deployed without tiller are always purged
 func (helmClientWrapper *ClientWrapper) DeleteRelease(releaseName string, purge bool) (*rls.UninstallReleaseResponse, error) {
-	return helmClientWrapper.Client.DeleteRelease(releaseName, k8shelm.DeletePurge(purge))
+	if helmClientWrapper.Tillerless {
+		return helmClientWrapper.deleteReleaseTillerless(releaseName)
+	}
+
+	var response *rls.UninstallReleaseResponse
+	err := helmClientWrapper.callOnce(releaseName, func() error {
+		var err error
+		response, err = helmClientWrapper.Client.DeleteRelease(releaseName, k8shelm.DeletePurge(purge))
+		return err
+	})
+
+	return response, err
+}
+
+// RollbackRelease rolls the release back to the given revision and waits until its resources are ready. Releases
+// deployed without tiller can't be rolled back, because only their latest revision is stored
+func (helmClientWrapper *ClientWrapper) RollbackRelease(releaseName string, revision int32) (*hapi_release5.Release, error) {
+	if helmClientWrapper.Tillerless {
+		return nil, fmt.Errorf("Release %s can't be rolled back without tiller", releaseName)
+	}
+
+	var response *rls.RollbackReleaseResponse
+	err := helmClientWrapper.callOnce(releaseName, func() error {
+		var err error
+		response, err = helmClientWrapper.Client.RollbackRelease(
+			releaseName,
+			k8shelm.RollbackVersion(revision),
+			k8shelm.RollbackTimeout(deploymentTimeout),
+			k8shelm.RollbackWait(true),
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response.GetRelease(), nil
 }
@@ -0,0 +1,210 @@
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/covexo/devspace/pkg/util/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/client-go/kubernetes/fake"
+	k8shelm "k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/kube"
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+	helmstoragedriver "k8s.io/helm/pkg/storage/driver"
+)
+
+// fakeHelmClient returns the scripted release history. All other calls are handled by the fake client of helm
+type fakeHelmClient struct {
+	k8shelm.FakeClient
+
+	history map[string][]*hapi_release5.Release
+}
+
+func (c *fakeHelmClient) ReleaseHistory(releaseName string, opts ...k8shelm.HistoryOption) (*rls.GetHistoryResponse, error) {
+	releases, ok := c.history[releaseName]
+	if ok == false {
+		return nil, helmstoragedriver.ErrReleaseNotFound(releaseName)
+	}
+
+	return &rls.GetHistoryResponse{Releases: releases}, nil
+}
+
+func TestGetLatestRelease(t *testing.T) {
+	newRelease := func(name string, version int32, statusCode hapi_release5.Status_Code) *hapi_release5.Release {
+		return &hapi_release5.Release{
+			Name:    name,
+			Version: version,
+			Info: &hapi_release5.Info{
+				Status: &hapi_release5.Status{Code: statusCode},
+			},
+		}
+	}
+
+	helmClientWrapper := &ClientWrapper{
+		Client: &fakeHelmClient{
+			history: map[string][]*hapi_release5.Release{
+				"deployed": {newRelease("deployed", 3, hapi_release5.Status_DEPLOYED)},
+				"failed":   {newRelease("failed", 2, hapi_release5.Status_FAILED)},
+				"deleted":  {newRelease("deleted", 1, hapi_release5.Status_DELETED)},
+				"empty":    {},
+			},
+		},
+	}
+
+	testCases := map[string]int32{
+		"deployed": 3,
+		"failed":   2,
+		"deleted":  0,
+		"empty":    0,
+		"missing":  0,
+	}
+
+	for releaseName, expectedVersion := range testCases {
+		release, err := helmClientWrapper.GetLatestRelease(releaseName)
+		if err != nil {
+			t.Fatalf("Unexpected error for release %s: %v", releaseName, err)
+		}
+
+		if expectedVersion == 0 && release != nil {
+			t.Fatalf("Expected release %s to be treated as not existing, got revision %d", releaseName, release.Version)
+		} else if expectedVersion != 0 && (release == nil || release.Version != expectedVersion) {
+			t.Fatalf("Expected revision %d of release %s, got %v", expectedVersion, releaseName, release)
+		}
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	defaultClient := &ClientWrapper{Namespace: "default-tiller", Tillerless: true}
+	workerClient := &ClientWrapper{Namespace: "worker-tiller", Tillerless: true}
+	clients["default-tiller"] = defaultClient
+	clients["worker-tiller"] = workerClient
+	defer CloseClients()
+
+	client, err := NewClientForNamespace(context.Background(), fake.NewSimpleClientset(), "worker-tiller", log.GetInstance(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client != workerClient {
+		t.Fatal("Expected the cached client of the tiller namespace")
+	}
+
+	client.Close()
+	if _, ok := clients["worker-tiller"]; ok {
+		t.Fatal("Expected closed client to be removed from the cache")
+	}
+	if clients["default-tiller"] != defaultClient {
+		t.Fatal("Expected the client of another tiller namespace to be kept")
+	}
+
+	CloseClients()
+	if len(clients) != 0 {
+		t.Fatalf("Expected empty cache, got %d clients", len(clients))
+	}
+}
+
+// brokenHelmClient fails all calls like a client whose tunnel to tiller broke
+type brokenHelmClient struct {
+	k8shelm.FakeClient
+}
+
+func (c *brokenHelmClient) ListReleases(opts ...k8shelm.ReleaseListOption) (*rls.ListReleasesResponse, error) {
+	return nil, status.Error(codes.Unavailable, "transport is closing")
+}
+
+func (c *brokenHelmClient) DeleteRelease(releaseName string, opts ...k8shelm.DeleteOption) (*rls.UninstallReleaseResponse, error) {
+	return nil, status.Error(codes.Unavailable, "transport is closing")
+}
+
+// unreachableHelmClient fails all calls like a client that couldn't open a connection to tiller
+type unreachableHelmClient struct {
+	k8shelm.FakeClient
+}
+
+func (c *unreachableHelmClient) DeleteRelease(releaseName string, opts ...k8shelm.DeleteOption) (*rls.UninstallReleaseResponse, error) {
+	return nil, context.DeadlineExceeded
+}
+
+func TestRetryOnConnectionError(t *testing.T) {
+	redials := 0
+	helmClientWrapper := &ClientWrapper{
+		Client: &brokenHelmClient{},
+		redial: func() (k8shelm.Interface, *kube.Tunnel, error) {
+			redials++
+			return &k8shelm.FakeClient{
+				Rels: []*hapi_release5.Release{{Name: "api"}},
+			}, nil, nil
+		},
+	}
+
+	releases, err := helmClientWrapper.ListReleases()
+	if err != nil {
+		t.Fatalf("Expected the call to succeed after reconnecting, got %v", err)
+	}
+	if redials != 1 || len(releases) != 1 {
+		t.Fatalf("Expected 1 reconnect and 1 release, got %d reconnects and %d releases", redials, len(releases))
+	}
+
+	// Without redial (e.g. a client that was created for a test) the error is returned as is
+	helmClientWrapper = &ClientWrapper{
+		Client: &brokenHelmClient{},
+	}
+	_, err = helmClientWrapper.ListReleases()
+	if isConnectionError(err) == false {
+		t.Fatalf("Expected connection error, got %v", err)
+	}
+}
+
+func TestCallOnceOnConnectionError(t *testing.T) {
+	newClient := func() *k8shelm.FakeClient {
+		return &k8shelm.FakeClient{
+			Rels: []*hapi_release5.Release{
+				{
+					Name:    "api",
+					Version: 2,
+					Info: &hapi_release5.Info{
+						Status: &hapi_release5.Status{Code: hapi_release5.Status_DELETED},
+					},
+				},
+			},
+		}
+	}
+
+	// The connection broke during the call, so tiller might have deleted the release already
+	redialedClient := newClient()
+	helmClientWrapper := &ClientWrapper{
+		Client: &brokenHelmClient{},
+		redial: func() (k8shelm.Interface, *kube.Tunnel, error) {
+			return redialedClient, nil, nil
+		},
+	}
+
+	_, err := helmClientWrapper.DeleteRelease("api", true)
+	if err == nil {
+		t.Fatal("Expected the broken connection to be reported")
+	}
+	if len(redialedClient.Rels) != 1 {
+		t.Fatal("Expected the delete not to be sent again")
+	}
+	if helmClientWrapper.Client != redialedClient {
+		t.Fatal("Expected the client to reconnect")
+	}
+
+	// Tiller wasn't reachable, so the delete is sent again
+	redialedClient = newClient()
+	helmClientWrapper = &ClientWrapper{
+		Client: &unreachableHelmClient{},
+		redial: func() (k8shelm.Interface, *kube.Tunnel, error) {
+			return redialedClient, nil, nil
+		},
+	}
+
+	_, err = helmClientWrapper.DeleteRelease("api", true)
+	if err != nil {
+		t.Fatalf("Expected the delete to succeed after reconnecting, got %v", err)
+	}
+	if len(redialedClient.Rels) != 0 {
+		t.Fatal("Expected the delete to be sent again")
+	}
+}
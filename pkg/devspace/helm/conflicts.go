@@ -0,0 +1,339 @@
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	yaml "gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8shelm "k8s.io/helm/pkg/helm"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+// helmReleaseAnnotation is the annotation helm uses to mark the owning release of a resource
+const helmReleaseAnnotation = "meta.helm.sh/release-name"
+
+// ResourceConflict is a resource the chart would create that already exists and is not managed by the release
+type ResourceConflict struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Owner     string
+}
+
+type manifestResource struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name        string            `yaml:"name"`
+		Namespace   string            `yaml:"namespace"`
+		Annotations map[string]string `yaml:"annotations"`
+	} `yaml:"metadata"`
+}
+
+type resourceList struct {
+	Items []struct {
+		Metadata metav1.ObjectMeta `json:"metadata"`
+	} `json:"items"`
+}
+
+// resourceGroup holds all resources of one kind within a namespace, so they can be fetched with a single request
+type resourceGroup struct {
+	path      string
+	kind      string
+	namespace string
+	names     []string
+
+	// keys are the keys of the resources in the owned map
+	keys []string
+}
+
+// FindResourceConflicts renders the chart and returns all resources that already exist in the cluster, but are not
+// managed by the given release
+func (helmClientWrapper *ClientWrapper) FindResourceConflicts(releaseName, releaseNamespace, chartPath string, values *map[interface{}]interface{}) ([]*ResourceConflict, error) {
+	if releaseNamespace == "" {
+		config := configutil.GetConfig()
+
+		defaultNamespace, err := configutil.GetDefaultNamespace(config)
+		if err != nil {
+			return nil, err
+		}
+
+		releaseNamespace = defaultNamespace
+	}
+
+	manifest, err := helmClientWrapper.renderManifest(releaseName, releaseNamespace, chartPath, values)
+	if err != nil {
+		return nil, fmt.Errorf("Error rendering chart: %v", err)
+	}
+
+	// Resources of the currently deployed revision are owned by the release, even if they are not labeled
+	owned := map[string]bool{}
+	release, err := helmClientWrapper.getRelease(releaseName)
+	if err == nil && release != nil {
+		for _, resource := range parseManifest(release.Manifest) {
+			owned[resourceKey(resource, releaseNamespace)] = true
+		}
+	}
+
+	groups, err := helmClientWrapper.groupResources(parseManifest(manifest), releaseNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []*ResourceConflict{}
+	conflictsLock := sync.Mutex{}
+	errs := make(chan error, len(groups))
+	wg := sync.WaitGroup{}
+
+	for _, group := range groups {
+		wg.Add(1)
+
+		go func(group *resourceGroup) {
+			defer wg.Done()
+
+			groupConflicts, err := helmClientWrapper.findGroupConflicts(group, releaseName, owned)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			conflictsLock.Lock()
+			conflicts = append(conflicts, groupConflicts...)
+			conflictsLock.Unlock()
+		}(group)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	// Receiving from the closed channel returns nil if no error occurred
+	err = <-errs
+	if err != nil {
+		return nil, err
+	}
+
+	return conflicts, nil
+}
+
+// renderManifest renders the chart with a dry run (or locally without tiller) and returns the resulting manifest
+func (helmClientWrapper *ClientWrapper) renderManifest(releaseName, releaseNamespace, chartPath string, values *map[interface{}]interface{}) (string, error) {
+	chart, err := helmClientWrapper.loadChart(chartPath)
+	if err != nil {
+		return "", err
+	}
+
+	overwriteValues := []byte("")
+	if values != nil {
+		overwriteValues, err = yaml.Marshal(values)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	releaseExists, err := helmClientWrapper.ReleaseExists(releaseName)
+	if err != nil {
+		return "", err
+	}
+
+	if helmClientWrapper.Tillerless {
+		documents, _, err := helmClientWrapper.renderChart(chart, releaseName, releaseNamespace, 0, releaseExists, overwriteValues)
+		if err != nil {
+			return "", err
+		}
+
+		return joinManifest(documents), nil
+	}
+
+	if releaseExists {
+		var upgradeResponse *rls.UpdateReleaseResponse
+		err := helmClientWrapper.retryOnConnectionError(func() error {
+			var err error
+			upgradeResponse, err = helmClientWrapper.Client.UpdateReleaseFromChart(
+				releaseName,
+				chart,
+				k8shelm.UpdateValueOverrides(overwriteValues),
+				k8shelm.ReuseValues(false),
+				k8shelm.UpgradeDryRun(true),
+			)
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+
+		return upgradeResponse.GetRelease().GetManifest(), nil
+	}
+
+	var installResponse *rls.InstallReleaseResponse
+	err = helmClientWrapper.retryOnConnectionError(func() error {
+		var err error
+		installResponse, err = helmClientWrapper.Client.InstallReleaseFromChart(
+			chart,
+			releaseNamespace,
+			k8shelm.ValueOverrides(overwriteValues),
+			k8shelm.ReleaseName(releaseName),
+			k8shelm.InstallReuseName(true),
+			k8shelm.InstallDryRun(true),
+		)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return installResponse.GetRelease().GetManifest(), nil
+}
+
+// groupResources groups the resources by api path, so that every kind and namespace is only listed once
+func (helmClientWrapper *ClientWrapper) groupResources(resources []*manifestResource, releaseNamespace string) ([]*resourceGroup, error) {
+	apiResources, err := helmClientWrapper.kubectl.Discovery().ServerResources()
+	if err != nil && len(apiResources) == 0 {
+		return nil, fmt.Errorf("Error retrieving server resources: %v", err)
+	}
+
+	groups := map[string]*resourceGroup{}
+
+	for _, resource := range resources {
+		apiResource, found := findAPIResource(apiResources, resource.APIVersion, resource.Kind)
+		if found == false {
+			// Resources of unknown kinds cannot exist yet
+			continue
+		}
+
+		path, namespace := resourceCollectionPath(apiResource, resource, releaseNamespace)
+		if _, ok := groups[path]; ok == false {
+			groups[path] = &resourceGroup{
+				path:      path,
+				kind:      resource.Kind,
+				namespace: namespace,
+				names:     []string{},
+				keys:      []string{},
+			}
+		}
+
+		groups[path].names = append(groups[path].names, resource.Metadata.Name)
+		groups[path].keys = append(groups[path].keys, resourceKey(resource, releaseNamespace))
+	}
+
+	retGroups := make([]*resourceGroup, 0, len(groups))
+	for _, group := range groups {
+		retGroups = append(retGroups, group)
+	}
+
+	return retGroups, nil
+}
+
+// findGroupConflicts lists all existing resources of the group and checks if they are owned by the release
+func (helmClientWrapper *ClientWrapper) findGroupConflicts(group *resourceGroup, releaseName string, owned map[string]bool) ([]*ResourceConflict, error) {
+	client, err := helmClientWrapper.getResourceClient()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := client.Get(group.path)
+	if err != nil {
+		return nil, fmt.Errorf("Error listing %s: %v", group.path, err)
+	}
+
+	list := &resourceList{}
+	err = json.Unmarshal(raw, list)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing %s: %v", group.path, err)
+	}
+
+	existing := map[string]*metav1.ObjectMeta{}
+	for i := range list.Items {
+		existing[list.Items[i].Metadata.Name] = &list.Items[i].Metadata
+	}
+
+	conflicts := []*ResourceConflict{}
+	for i, name := range group.names {
+		objectMeta, ok := existing[name]
+		if ok == false || owned[group.keys[i]] {
+			continue
+		}
+
+		owner, managed := getResourceOwner(objectMeta, releaseName)
+		if managed {
+			continue
+		}
+
+		conflicts = append(conflicts, &ResourceConflict{
+			Kind:      group.kind,
+			Name:      name,
+			Namespace: group.namespace,
+			Owner:     owner,
+		})
+	}
+
+	return conflicts, nil
+}
+
+// getResourceOwner returns a description of the owner of the resource and if the resource is managed by the release
+func getResourceOwner(objectMeta *metav1.ObjectMeta, releaseName string) (string, bool) {
+	if release, ok := objectMeta.Annotations[helmReleaseAnnotation]; ok {
+		return "helm release " + release, release == releaseName
+	}
+	if release, ok := objectMeta.Labels["release"]; ok {
+		return "helm release " + release, release == releaseName
+	}
+
+	if len(objectMeta.OwnerReferences) > 0 {
+		owners := []string{}
+		for _, ownerReference := range objectMeta.OwnerReferences {
+			owners = append(owners, ownerReference.Kind+"/"+ownerReference.Name)
+		}
+
+		return strings.Join(owners, ", "), false
+	}
+
+	return "unknown (not managed by helm)", false
+}
+
+func findAPIResource(apiResourceLists []*metav1.APIResourceList, apiVersion, kind string) (*metav1.APIResource, bool) {
+	for _, apiResourceList := range apiResourceLists {
+		if apiResourceList == nil || apiResourceList.GroupVersion != apiVersion {
+			continue
+		}
+
+		for i := range apiResourceList.APIResources {
+			apiResource := &apiResourceList.APIResources[i]
+
+			// Skip subresources like deployments/scale
+			if apiResource.Kind == kind && strings.Contains(apiResource.Name, "/") == false {
+				return apiResource, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func parseManifest(manifest string) []*manifestResource {
+	resources := []*manifestResource{}
+
+	for _, document := range strings.Split(manifest, "\n---") {
+		resource := &manifestResource{}
+		err := yaml.Unmarshal([]byte(document), resource)
+		if err != nil || resource.Kind == "" || resource.Metadata.Name == "" {
+			continue
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources
+}
+
+func resourceKey(resource *manifestResource, releaseNamespace string) string {
+	namespace := resource.Metadata.Namespace
+	if namespace == "" {
+		namespace = releaseNamespace
+	}
+
+	return resource.Kind + "/" + namespace + "/" + resource.Metadata.Name
+}
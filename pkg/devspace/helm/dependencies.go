@@ -0,0 +1,159 @@
+package helm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/util/hash"
+	"github.com/covexo/devspace/pkg/util/log"
+
+	helmchartutil "k8s.io/helm/pkg/chartutil"
+	helmdownloader "k8s.io/helm/pkg/downloader"
+	"k8s.io/helm/pkg/getter"
+	"k8s.io/helm/pkg/repo"
+)
+
+// lockDigestKey is the key of the requirements.lock digest in the recorded dependency digests
+const lockDigestKey = "requirements.lock"
+
+// EnsureDependencies makes sure the dependencies of the chart are downloaded and their archives still match the
+// digests that were recorded after the last update. Only missing or corrupt archives are downloaded again, unless
+// forceUpdate is true. Returns the new digests of the chart dependencies or nil if the chart has no dependencies
+func (helmClientWrapper *ClientWrapper) EnsureDependencies(chartPath string, digests map[string]string, forceUpdate bool, log log.Logger) (map[string]string, error) {
+	chart, err := helmchartutil.Load(chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	reqs, err := helmchartutil.LoadRequirements(chart)
+	if err != nil || len(reqs.Dependencies) == 0 {
+		return nil, nil
+	}
+
+	lock, err := helmchartutil.LoadRequirementsLock(chart)
+	if forceUpdate || err != nil {
+		if forceUpdate {
+			log.Infof("Updating dependencies of chart %s", chartPath)
+		} else {
+			log.Infof("Updating dependencies of chart %s, because it has no requirements.lock", chartPath)
+		}
+
+		err = helmClientWrapper.UpdateDependencies(chartPath)
+		if err != nil {
+			return nil, fmt.Errorf("Error updating dependencies of chart %s: %v", chartPath, err)
+		}
+
+		return getDependencyDigests(chartPath)
+	}
+
+	if digests != nil && digests[lockDigestKey] != lock.Digest {
+		log.Infof("Downloading dependencies of chart %s, because requirements.lock has changed since the last update", chartPath)
+
+		err = helmClientWrapper.BuildDependencies(chartPath)
+		if err != nil {
+			log.Warnf("Error building dependencies from requirements.lock: %v. Updating dependencies instead", err)
+
+			err = helmClientWrapper.UpdateDependencies(chartPath)
+			if err != nil {
+				return nil, fmt.Errorf("Error updating dependencies of chart %s: %v", chartPath, err)
+			}
+		}
+
+		return getDependencyDigests(chartPath)
+	}
+
+	for _, dependency := range lock.Dependencies {
+		digest, err := hash.File(getDependencyArchivePath(chartPath, dependency))
+		if err != nil {
+			log.Infof("Downloading dependency %s of chart %s, because its archive is missing", dependency.Name, chartPath)
+		} else if recordedDigest, ok := digests[dependency.Name]; ok && recordedDigest != digest {
+			log.Infof("Downloading dependency %s of chart %s again, because its archive does not match the digest recorded after the last update", dependency.Name, chartPath)
+		} else {
+			continue
+		}
+
+		err = helmClientWrapper.downloadDependency(chartPath, dependency)
+		if err != nil {
+			return nil, fmt.Errorf("Error downloading dependency %s of chart %s: %v", dependency.Name, chartPath, err)
+		}
+	}
+
+	return getDependencyDigests(chartPath)
+}
+
+// downloadDependency downloads the archive of a single locked dependency into the charts/ directory
+func (helmClientWrapper *ClientWrapper) downloadDependency(chartPath string, dependency *helmchartutil.Dependency) error {
+	// Local dependencies and repository aliases can only be resolved by the dependency manager
+	if strings.HasPrefix(dependency.Repository, "http://") == false && strings.HasPrefix(dependency.Repository, "https://") == false {
+		return helmClientWrapper.BuildDependencies(chartPath)
+	}
+
+	getters := getter.All(*helmClientWrapper.Settings)
+	chartURL, err := repo.FindChartInRepoURL(dependency.Repository, dependency.Name, dependency.Version, "", "", "", getters)
+	if err != nil {
+		return err
+	}
+
+	archivePath := getDependencyArchivePath(chartPath, dependency)
+	err = os.MkdirAll(filepath.Dir(archivePath), 0755)
+	if err != nil {
+		return err
+	}
+
+	// Remove the corrupt archive first
+	os.Remove(archivePath)
+
+	downloader := &helmdownloader.ChartDownloader{
+		Out:      ioutil.Discard,
+		Verify:   helmdownloader.VerifyNever,
+		Getters:  getters,
+		HelmHome: helmClientWrapper.Settings.Home,
+	}
+
+	downloadedPath, _, err := downloader.DownloadTo(chartURL, dependency.Version, filepath.Dir(archivePath))
+	if err != nil {
+		return err
+	}
+
+	if downloadedPath != archivePath {
+		return os.Rename(downloadedPath, archivePath)
+	}
+
+	return nil
+}
+
+// getDependencyDigests returns the requirements.lock digest and the digests of all dependency archives of the chart
+func getDependencyDigests(chartPath string) (map[string]string, error) {
+	chart, err := helmchartutil.Load(chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := helmchartutil.LoadRequirementsLock(chart)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading requirements.lock of chart %s: %v", chartPath, err)
+	}
+
+	digests := map[string]string{
+		lockDigestKey: lock.Digest,
+	}
+
+	for _, dependency := range lock.Dependencies {
+		digest, err := hash.File(getDependencyArchivePath(chartPath, dependency))
+		if err != nil {
+			// Unpacked dependencies have no archive
+			continue
+		}
+
+		digests[dependency.Name] = digest
+	}
+
+	return digests, nil
+}
+
+func getDependencyArchivePath(chartPath string, dependency *helmchartutil.Dependency) string {
+	return filepath.Join(chartPath, "charts", dependency.Name+"-"+dependency.Version+".tgz")
+}
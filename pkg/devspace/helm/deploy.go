@@ -1,22 +1,35 @@
 package helm
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	k8sv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/covexo/devspace/pkg/devspace/analyze"
 	"github.com/covexo/devspace/pkg/devspace/kubectl"
 	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/signalutil"
 )
 
-// WaitForReleasePodToGetReady waits for the release pod to get ready
-func WaitForReleasePodToGetReady(client *kubernetes.Clientset, releaseName, releaseNamespace string, releaseRevision int) (*k8sv1.Pod, error) {
+// WaitForReleasePodToGetReady waits for the release pod to get ready. It works for releases deployed without tiller
+// as well, because their templates are rendered with the same release name and revision. Waiting stops as soon as the
+// context is cancelled
+func WaitForReleasePodToGetReady(ctx context.Context, client kubernetes.Interface, releaseName, releaseNamespace string, releaseRevision int) (*k8sv1.Pod, error) {
 	for true {
-		time.Sleep(4 * time.Second)
+		err := signalutil.Sleep(ctx, 4*time.Second)
+		if err != nil {
+			if err == context.DeadlineExceeded {
+				return nil, fmt.Errorf("No pod of release %s (revision %d) got ready in time\n%s", releaseName, releaseRevision, DiagnoseReleasePods(client, releaseName, releaseNamespace))
+			}
+
+			return nil, err
+		}
 
 		podList, err := client.Core().Pods(releaseNamespace).List(metav1.ListOptions{
 			LabelSelector: "release=" + releaseName,
@@ -60,9 +73,9 @@ func WaitForReleasePodToGetReady(client *kubernetes.Clientset, releaseName, rele
 						log.Warn("Found pod without revision. Use annotation 'revision' for your pods to avoid this warning.")
 					}
 
-					err = waitForPodReady(client, selectedPod, 2*60*time.Second, 5*time.Second)
+					err = waitForPodReady(ctx, client, selectedPod, 2*60*time.Second, 5*time.Second)
 					if err != nil {
-						return nil, fmt.Errorf("Error during waiting for pod: %s", err.Error())
+						return nil, err
 					}
 
 					return selectedPod, nil
@@ -78,21 +91,113 @@ func WaitForReleasePodToGetReady(client *kubernetes.Clientset, releaseName, rele
 	return nil, nil
 }
 
-func waitForPodReady(kubectl *kubernetes.Clientset, pod *k8sv1.Pod, maxWaitTime time.Duration, checkInterval time.Duration) error {
-	for maxWaitTime > 0 {
-		pod, err := kubectl.Core().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+// crashLoopRestarts is the number of restarts after which a container that doesn't get ready is considered crashing
+const crashLoopRestarts = 2
 
+// failedWaitingReasons are the reasons of waiting containers that won't get ready without a new deployment
+var failedWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+}
+
+// waitForPodReady waits until the first container of the pod is ready. If the pod doesn't get ready in time or one
+// of its containers is crash looping or can't be started, the returned error contains the container statuses and logs
+// of the pod
+func waitForPodReady(ctx context.Context, client kubernetes.Interface, pod *k8sv1.Pod, maxWaitTime time.Duration, checkInterval time.Duration) error {
+	var initialRestarts map[string]int32
+
+	for maxWaitTime > 0 {
+		currentPod, err := client.Core().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
 		if err != nil {
 			return err
 		}
 
-		if len(pod.Status.ContainerStatuses) > 0 && pod.Status.ContainerStatuses[0].Ready {
+		if len(currentPod.Status.ContainerStatuses) > 0 && currentPod.Status.ContainerStatuses[0].Ready {
 			return nil
 		}
 
-		time.Sleep(checkInterval)
+		if initialRestarts == nil {
+			initialRestarts = map[string]int32{}
+			for _, containerStatus := range currentPod.Status.ContainerStatuses {
+				initialRestarts[containerStatus.Name] = containerStatus.RestartCount
+			}
+		}
+
+		problem := getContainerFailure(currentPod, initialRestarts)
+		if problem != "" {
+			return fmt.Errorf("Release pod won't get ready: %s\n%s", problem, analyze.Diagnose(client, currentPod))
+		}
+
+		pod = currentPod
+		err = signalutil.Sleep(ctx, checkInterval)
+		if err == context.DeadlineExceeded {
+			break
+		} else if err != nil {
+			return err
+		}
+
 		maxWaitTime = maxWaitTime - checkInterval
 	}
 
-	return fmt.Errorf("Max wait time expired")
+	return fmt.Errorf("Release pod didn't get ready in time\n%s", analyze.Diagnose(client, pod))
+}
+
+// getContainerFailure returns why a container of the pod won't get ready or an empty string if the pod might still
+// get ready. Containers that restarted crashLoopRestarts times since the first check are considered crash looping,
+// even if kubernetes didn't back off yet
+func getContainerFailure(pod *k8sv1.Pod, initialRestarts map[string]int32) string {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Ready {
+			continue
+		}
+
+		if waiting := containerStatus.State.Waiting; waiting != nil && failedWaitingReasons[waiting.Reason] {
+			return fmt.Sprintf("Container %s is waiting (%s)", containerStatus.Name, waiting.Reason)
+		}
+
+		if containerStatus.RestartCount-initialRestarts[containerStatus.Name] >= crashLoopRestarts {
+			return fmt.Sprintf("Container %s restarted %d times", containerStatus.Name, containerStatus.RestartCount-initialRestarts[containerStatus.Name])
+		}
+	}
+
+	return ""
+}
+
+// DiagnoseReleasePods returns the diagnosis of all pods of the release that are not ready
+func DiagnoseReleasePods(client kubernetes.Interface, releaseName, releaseNamespace string) string {
+	podList, err := client.Core().Pods(releaseNamespace).List(metav1.ListOptions{
+		LabelSelector: "release=" + releaseName,
+	})
+	if err != nil {
+		return fmt.Sprintf("Unable to list the pods of release %s: %v", releaseName, err)
+	}
+
+	diagnoses := []string{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.DeletionTimestamp != nil || isPodReady(pod) {
+			continue
+		}
+
+		diagnoses = append(diagnoses, analyze.Diagnose(client, pod))
+	}
+
+	if len(diagnoses) == 0 {
+		return fmt.Sprintf("Found no pods of release %s that are not ready (pods need the label release=%s)", releaseName, releaseName)
+	}
+
+	return strings.Join(diagnoses, "\n\n")
+}
+
+func isPodReady(pod *k8sv1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == k8sv1.PodReady {
+			return condition.Status == k8sv1.ConditionTrue
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,51 @@
+package helm
+
+import (
+	"testing"
+
+	k8sv1 "k8s.io/api/core/v1"
+)
+
+func TestGetContainerFailure(t *testing.T) {
+	initialRestarts := map[string]int32{"api": 1}
+
+	testCases := map[string]struct {
+		status  k8sv1.ContainerStatus
+		failure bool
+	}{
+		"starting": {
+			status: k8sv1.ContainerStatus{Name: "api", RestartCount: 1, State: k8sv1.ContainerState{Running: &k8sv1.ContainerStateRunning{}}},
+		},
+		"restarted once": {
+			status: k8sv1.ContainerStatus{Name: "api", RestartCount: 2, State: k8sv1.ContainerState{Running: &k8sv1.ContainerStateRunning{}}},
+		},
+		"restarting": {
+			status:  k8sv1.ContainerStatus{Name: "api", RestartCount: 3, State: k8sv1.ContainerState{Running: &k8sv1.ContainerStateRunning{}}},
+			failure: true,
+		},
+		"crash loop": {
+			status:  k8sv1.ContainerStatus{Name: "api", RestartCount: 1, State: k8sv1.ContainerState{Waiting: &k8sv1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			failure: true,
+		},
+		"bad image": {
+			status:  k8sv1.ContainerStatus{Name: "api", State: k8sv1.ContainerState{Waiting: &k8sv1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			failure: true,
+		},
+		"creating": {
+			status: k8sv1.ContainerStatus{Name: "api", State: k8sv1.ContainerState{Waiting: &k8sv1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+		},
+	}
+
+	for name, testCase := range testCases {
+		pod := &k8sv1.Pod{
+			Status: k8sv1.PodStatus{
+				ContainerStatuses: []k8sv1.ContainerStatus{testCase.status},
+			},
+		}
+
+		failure := getContainerFailure(pod, initialRestarts)
+		if (failure != "") != testCase.failure {
+			t.Fatalf("Unexpected failure for %s: '%s'", name, failure)
+		}
+	}
+}
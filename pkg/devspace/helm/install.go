@@ -16,8 +16,12 @@ import (
 	k8shelm "k8s.io/helm/pkg/helm"
 	"k8s.io/helm/pkg/proto/hapi/chart"
 	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
 )
 
+// deploymentTimeout is the time in seconds tiller waits for the resources of a release to get ready
+const deploymentTimeout = int64(10 * 60)
+
 func checkDependencies(ch *chart.Chart, reqs *helmchartutil.Requirements) error {
 	missing := []string{}
 
@@ -41,20 +45,8 @@ func checkDependencies(ch *chart.Chart, reqs *helmchartutil.Requirements) error
 	return nil
 }
 
-// InstallChartByPath installs the given chartpath und the releasename in the releasenamespace
-func (helmClientWrapper *ClientWrapper) InstallChartByPath(releaseName, releaseNamespace string, chartPath string, values *map[interface{}]interface{}) (*hapi_release5.Release, error) {
-	if releaseNamespace == "" {
-		config := configutil.GetConfig()
-
-		// Use default namespace here
-		defaultNamespace, err := configutil.GetDefaultNamespace(config)
-		if err != nil {
-			return nil, err
-		}
-
-		releaseNamespace = defaultNamespace
-	}
-
+// loadChart loads the chart from the given path and downloads missing dependencies
+func (helmClientWrapper *ClientWrapper) loadChart(chartPath string) (*chart.Chart, error) {
 	chart, err := helmchartutil.Load(chartPath)
 	if err != nil {
 		return nil, err
@@ -83,64 +75,172 @@ func (helmClientWrapper *ClientWrapper) InstallChartByPath(releaseName, releaseN
 		}
 	}
 
+	return chart, nil
+}
+
+// InstallChartByPath installs the given chartpath und the releasename in the releasenamespace. If the release already
+// exists, it is upgraded instead. If cleanupOnFailure is true, a release whose installation fails is purged, because
+// helm can't upgrade a release that was never deployed. The deploy config passes false and cleans up itself
+func (helmClientWrapper *ClientWrapper) InstallChartByPath(releaseName, releaseNamespace string, chartPath string, values *map[interface{}]interface{}, cleanupOnFailure bool) (*hapi_release5.Release, error) {
 	releaseExists, err := helmClientWrapper.ReleaseExists(releaseName)
 	if err != nil {
 		return nil, err
 	}
 
-	deploymentTimeout := int64(10 * 60)
-	overwriteValues := []byte("")
+	if releaseExists {
+		return helmClientWrapper.UpgradeChartByPath(releaseName, releaseNamespace, chartPath, values, false)
+	}
 
-	if values != nil {
-		unmarshalledValues, err := yaml.Marshal(values)
+	return helmClientWrapper.installChartByPath(releaseName, releaseNamespace, chartPath, values, cleanupOnFailure)
+}
 
-		if err != nil {
-			return nil, err
-		}
-		overwriteValues = unmarshalledValues
+// UpgradeChartByPath upgrades the existing release with the given chartpath. If reuseValues is true, the values of the
+// last revision are merged with the given values like helm upgrade --reuse-values does
+func (helmClientWrapper *ClientWrapper) UpgradeChartByPath(releaseName, releaseNamespace string, chartPath string, values *map[interface{}]interface{}, reuseValues bool) (*hapi_release5.Release, error) {
+	releaseNamespace, err := getReleaseNamespace(releaseNamespace)
+	if err != nil {
+		return nil, err
 	}
 
-	var release *hapi_release5.Release
+	chart, err := helmClientWrapper.loadChart(chartPath)
+	if err != nil {
+		return nil, err
+	}
 
-	if releaseExists {
-		upgradeResponse, err := helmClientWrapper.Client.UpdateRelease(
+	overwriteValues, err := marshalValues(values)
+	if err != nil {
+		return nil, err
+	}
+
+	if helmClientWrapper.Tillerless {
+		if reuseValues {
+			overwriteValues, err = helmClientWrapper.reuseTillerlessValues(releaseName, overwriteValues)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return helmClientWrapper.installChartTillerless(chart, releaseName, releaseNamespace, overwriteValues)
+	}
+
+	var upgradeResponse *rls.UpdateReleaseResponse
+	err = helmClientWrapper.callOnce(releaseName, func() error {
+		var err error
+		upgradeResponse, err = helmClientWrapper.Client.UpdateReleaseFromChart(
 			releaseName,
-			chartPath,
+			chart,
 			k8shelm.UpgradeTimeout(deploymentTimeout),
 			k8shelm.UpdateValueOverrides(overwriteValues),
-			k8shelm.ReuseValues(false),
+			k8shelm.ReuseValues(reuseValues),
 			k8shelm.UpgradeWait(true),
-			k8shelm.UpgradeForce(true),
 		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			return nil, err
-		}
+	return upgradeResponse.GetRelease(), nil
+}
+
+// installChartByPath installs a new release with the given chartpath
+func (helmClientWrapper *ClientWrapper) installChartByPath(releaseName, releaseNamespace string, chartPath string, values *map[interface{}]interface{}, cleanupOnFailure bool) (*hapi_release5.Release, error) {
+	releaseNamespace, err := getReleaseNamespace(releaseNamespace)
+	if err != nil {
+		return nil, err
+	}
 
-		release = upgradeResponse.GetRelease()
+	chart, err := helmClientWrapper.loadChart(chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	overwriteValues, err := marshalValues(values)
+	if err != nil {
+		return nil, err
+	}
+
+	var release *hapi_release5.Release
+	if helmClientWrapper.Tillerless {
+		release, err = helmClientWrapper.installChartTillerless(chart, releaseName, releaseNamespace, overwriteValues)
 	} else {
-		installResponse, err := helmClientWrapper.Client.InstallReleaseFromChart(
-			chart,
-			releaseNamespace,
-			k8shelm.InstallTimeout(deploymentTimeout),
-			k8shelm.ValueOverrides(overwriteValues),
-			k8shelm.ReleaseName(releaseName),
-			k8shelm.InstallReuseName(false),
-			k8shelm.InstallWait(true),
-		)
+		err = helmClientWrapper.callOnce(releaseName, func() error {
+			installResponse, err := helmClientWrapper.Client.InstallReleaseFromChart(
+				chart,
+				releaseNamespace,
+				k8shelm.InstallTimeout(deploymentTimeout),
+				k8shelm.ValueOverrides(overwriteValues),
+				k8shelm.ReleaseName(releaseName),
+				k8shelm.InstallReuseName(true),
+				k8shelm.InstallWait(true),
+			)
+			if err != nil {
+				return err
+			}
 
-		if err != nil {
+			release = installResponse.GetRelease()
+			return nil
+		})
+	}
+	if err != nil {
+		if cleanupOnFailure {
 			// Try to delete and ignore errors, because otherwise we have a broken release laying around and always get the no deployed resources error
 			helmClientWrapper.DeleteRelease(releaseName, true)
-
-			return nil, err
 		}
 
-		release = installResponse.GetRelease()
+		return nil, err
 	}
+
 	return release, nil
 }
 
+// reuseTillerlessValues merges the values of the last revision with the given values. The given values take precedence
+func (helmClientWrapper *ClientWrapper) reuseTillerlessValues(releaseName string, values []byte) ([]byte, error) {
+	previousRelease, err := helmClientWrapper.loadTillerlessRelease(releaseName)
+	if err != nil {
+		if isReleaseNotFound(err, releaseName) {
+			return values, nil
+		}
+
+		return nil, err
+	}
+
+	previousValues, err := helmchartutil.ReadValues([]byte(previousRelease.GetConfig().GetRaw()))
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing values of release %s: %v", releaseName, err)
+	}
+
+	newValues, err := helmchartutil.ReadValues(values)
+	if err != nil {
+		return nil, err
+	}
+
+	mergedValues, err := helmchartutil.Values(helmchartutil.CoalesceTables(newValues, previousValues)).YAML()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(mergedValues), nil
+}
+
+// getReleaseNamespace returns the release namespace or the default namespace if it is empty
+func getReleaseNamespace(releaseNamespace string) (string, error) {
+	if releaseNamespace != "" {
+		return releaseNamespace, nil
+	}
+
+	// Use default namespace here
+	return configutil.GetDefaultNamespace(configutil.GetConfig())
+}
+
+func marshalValues(values *map[interface{}]interface{}) ([]byte, error) {
+	if values == nil {
+		return []byte(""), nil
+	}
+
+	return yaml.Marshal(values)
+}
+
 // InstallChartByName installs the given chart by name under the releasename in the releasenamespace
 func (helmClientWrapper *ClientWrapper) InstallChartByName(releaseName string, releaseNamespace string, chartName string, chartVersion string, values *map[interface{}]interface{}) (*hapi_release5.Release, error) {
 	if len(chartVersion) == 0 {
@@ -161,5 +261,6 @@ func (helmClientWrapper *ClientWrapper) InstallChartByName(releaseName string, r
 		return nil, err
 	}
 
-	return helmClientWrapper.InstallChartByPath(releaseName, releaseNamespace, chartPath, values)
+	// Charts installed by name aren't part of the deploy config, so nobody else cleans up a failed installation
+	return helmClientWrapper.InstallChartByPath(releaseName, releaseNamespace, chartPath, values, true)
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
 
 	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
 	"github.com/covexo/devspace/pkg/util/log"
 	k8sv1 "k8s.io/api/core/v1"
 	k8sv1beta1 "k8s.io/api/rbac/v1beta1"
@@ -24,9 +25,8 @@ const TillerRoleManagerName = "tiller-config-manager"
 
 var alreadyExistsRegexp = regexp.MustCompile(".* already exists$")
 
-func createTillerRBAC(kubectlClient *kubernetes.Clientset, dsConfig *v1.Config) error {
+func createTillerRBAC(kubectlClient kubernetes.Interface, dsConfig *v1.Config, tillerNamespace string) error {
 	config := configutil.GetConfig()
-	tillerNamespace := *config.Tiller.Namespace
 
 	// Create service account
 	err := createTillerServiceAccount(kubectlClient, tillerNamespace)
@@ -66,18 +66,9 @@ func createTillerRBAC(kubectlClient *kubernetes.Clientset, dsConfig *v1.Config)
 	for _, appNamespace := range appNamespaces {
 		if *appNamespace != "default" {
 			// Create namespaces if they are not there already
-			_, err := kubectlClient.CoreV1().Namespaces().Get(*appNamespace, metav1.GetOptions{})
+			err := kubectl.EnsureNamespace(kubectlClient, *appNamespace, log.GetInstance())
 			if err != nil {
-				log.Donef("Create namespace %s", *appNamespace)
-
-				_, err = kubectlClient.CoreV1().Namespaces().Create(&k8sv1.Namespace{
-					ObjectMeta: metav1.ObjectMeta{
-						Name: *appNamespace,
-					},
-				})
-				if err != nil {
-					return err
-				}
+				return err
 			}
 		}
 
@@ -90,7 +81,7 @@ func createTillerRBAC(kubectlClient *kubernetes.Clientset, dsConfig *v1.Config)
 	return nil
 }
 
-func createTillerServiceAccount(kubectlClient *kubernetes.Clientset, tillerNamespace string) error {
+func createTillerServiceAccount(kubectlClient kubernetes.Interface, tillerNamespace string) error {
 	_, err := kubectlClient.CoreV1().ServiceAccounts(tillerNamespace).Create(&k8sv1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      TillerServiceAccountName,
@@ -101,7 +92,7 @@ func createTillerServiceAccount(kubectlClient *kubernetes.Clientset, tillerNames
 	return err
 }
 
-func addMinimalAccessToTiller(kubectlClient *kubernetes.Clientset, tillerNamespace string) error {
+func addMinimalAccessToTiller(kubectlClient kubernetes.Interface, tillerNamespace string) error {
 	_, err := kubectlClient.RbacV1beta1().Roles(tillerNamespace).Create(&k8sv1beta1.Role{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      TillerRoleManagerName,
@@ -150,7 +141,7 @@ func addMinimalAccessToTiller(kubectlClient *kubernetes.Clientset, tillerNamespa
 	return nil
 }
 
-func addDeployAccessToTiller(kubectlClient *kubernetes.Clientset, tillerNamespace, namespace string) error {
+func addDeployAccessToTiller(kubectlClient kubernetes.Interface, tillerNamespace, namespace string) error {
 	_, err := kubectlClient.RbacV1beta1().Roles(namespace).Create(&k8sv1beta1.Role{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      TillerRoleName,
@@ -0,0 +1,302 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/signalutil"
+	"k8s.io/helm/pkg/helm/helmpath"
+	"k8s.io/helm/pkg/repo"
+)
+
+// maxConcurrentRepoUpdates is the maximum number of repository indexes that are downloaded at the same time
+const maxConcurrentRepoUpdates = 4
+
+// repoUpdateTimeout is the maximum time a single download of a repository index may take
+const repoUpdateTimeout = 60 * time.Second
+
+// repoUpdateRetries is the number of retries of index downloads that failed with a transient error
+const repoUpdateRetries = 2
+
+// repoUpdateBackoff is the time we wait before the first retry. It is doubled for every further retry
+const repoUpdateBackoff = 2 * time.Second
+
+// transientStatusRegex matches the errors of the helm http getter for responses that are worth retrying
+var transientStatusRegex = regexp.MustCompile(`: (429|5\d\d) `)
+
+// Offline is true if the cached indexes should be used for repositories whose index can't be downloaded
+var Offline = false
+
+// RepoUpdateError contains the errors of the repositories whose index couldn't be downloaded
+type RepoUpdateError struct {
+	Errors map[string]error
+}
+
+// Error lists the failed repositories with their errors
+func (e *RepoUpdateError) Error() string {
+	repositories := e.Repositories()
+	message := "Unable to download the index of the chart repositories " + strings.Join(repositories, ", ")
+
+	for _, name := range repositories {
+		message += fmt.Sprintf("\n- %s: %v", name, e.Errors[name])
+	}
+
+	return message
+}
+
+// Repositories returns the sorted names of the failed repositories
+func (e *RepoUpdateError) Repositories() []string {
+	repositories := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		repositories = append(repositories, name)
+	}
+
+	sort.Strings(repositories)
+	return repositories
+}
+
+// getRepositoryEntries returns the repositories of helm.repositories with resolved environment variables. The config
+// itself is not changed, so that resolved credentials are never saved
+func getRepositoryEntries(config *v1.Config, home helmpath.Home) ([]*repo.Entry, error) {
+	entries := []*repo.Entry{}
+	if config.Helm == nil || config.Helm.Repositories == nil {
+		return entries, nil
+	}
+
+	for index, repoConfig := range *config.Helm.Repositories {
+		if repoConfig == nil || repoConfig.Name == nil || *repoConfig.Name == "" {
+			return nil, fmt.Errorf("helm.repositories[%d].name is missing", index)
+		}
+		if repoConfig.URL == nil || *repoConfig.URL == "" {
+			return nil, fmt.Errorf("helm.repositories[%d].url is missing", index)
+		}
+		if repoConfig.Password != nil && repoConfig.Token != nil {
+			return nil, fmt.Errorf("helm.repositories[%d]: password and token cannot be used together", index)
+		}
+
+		name := *repoConfig.Name
+		entry := &repo.Entry{
+			Name:  name,
+			Cache: home.CacheIndex(name),
+		}
+
+		fields := []struct {
+			key    string
+			value  *string
+			target *string
+		}{
+			{"url", repoConfig.URL, &entry.URL},
+			{"username", repoConfig.Username, &entry.Username},
+			{"password", repoConfig.Password, &entry.Password},
+			// Helm only supports basic auth, so the token is sent as password
+			{"token", repoConfig.Token, &entry.Password},
+			{"caFile", repoConfig.CAFile, &entry.CAFile},
+		}
+
+		for _, field := range fields {
+			if field.value == nil {
+				continue
+			}
+
+			value, err := expandEnv(*field.value)
+			if err != nil {
+				return nil, fmt.Errorf("helm.repositories[%d].%s: %v", index, field.key, err)
+			}
+
+			*field.target = value
+		}
+
+		// The repositories file is shared by all projects, so the ca file must not depend on the working directory
+		if entry.CAFile != "" {
+			caFile, err := filepath.Abs(entry.CAFile)
+			if err != nil {
+				return nil, err
+			}
+
+			entry.CAFile = caFile
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// expandEnv replaces ${VAR} and $VAR with the value of the environment variable. Unset variables are an error,
+// because an empty password or url would only fail later with a confusing error
+func expandEnv(value string) (string, error) {
+	var missing []string
+
+	expanded := os.Expand(value, func(name string) string {
+		envValue, ok := os.LookupEnv(name)
+		if ok == false {
+			missing = append(missing, name)
+		}
+
+		return envValue
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("Environment variable %s is not set", missing[0])
+	}
+
+	return expanded, nil
+}
+
+// mergeRepositories adds the entries to the repositories file or replaces the entries with the same name. It returns
+// true if the file was changed
+func mergeRepositories(repoFilePath string, entries []*repo.Entry) (bool, error) {
+	if len(entries) == 0 {
+		return false, nil
+	}
+
+	repoFile, err := repo.LoadRepositoriesFile(repoFilePath)
+	if err != nil {
+		return false, fmt.Errorf("Error loading %s: %v", repoFilePath, err)
+	}
+
+	changed := false
+	for _, entry := range entries {
+		existing := findRepository(repoFile, entry.Name)
+		if existing == nil || *existing != *entry {
+			repoFile.Update(entry)
+			changed = true
+		}
+	}
+
+	if changed == false {
+		return false, nil
+	}
+
+	// The file contains the resolved credentials, so it is only readable by the user. The mode of an existing file
+	// isn't changed by writing it
+	err = repoFile.WriteFile(repoFilePath, 0600)
+	if err != nil {
+		return false, fmt.Errorf("Error writing %s: %v", repoFilePath, err)
+	}
+
+	err = os.Chmod(repoFilePath, 0600)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// findRepository returns the entry with the given name or nil
+func findRepository(repoFile *repo.RepoFile, name string) *repo.Entry {
+	for _, entry := range repoFile.Repositories {
+		if entry.Name == name {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// isRepoCacheMissing checks if the index of one of the repositories hasn't been downloaded yet
+func isRepoCacheMissing(entries []*repo.Entry) bool {
+	for _, entry := range entries {
+		_, err := os.Stat(entry.Cache)
+		if err != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkRepoUpdateError only prints a warning for repositories whose index couldn't be downloaded if Offline is set
+func checkRepoUpdateError(err error, log log.Logger) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(*RepoUpdateError); ok {
+		if Offline {
+			log.Warnf("%v\nUsing the cached indexes of these repositories, because --offline is set", err)
+			return nil
+		}
+
+		return fmt.Errorf("%v\nUse --offline to continue with the cached indexes", err)
+	}
+
+	return err
+}
+
+// downloadIndexFile downloads the index of the repository and retries transient errors with exponential backoff.
+// Nothing is retried if Offline is set
+func downloadIndexFile(ctx context.Context, chartRepo *repo.ChartRepository, cachePath string) error {
+	backoff := repoUpdateBackoff
+
+	for retry := 0; ; retry++ {
+		err := runWithTimeout(ctx, repoUpdateTimeout, func() error {
+			return chartRepo.DownloadIndexFile(cachePath)
+		})
+		if err == nil || Offline || retry >= repoUpdateRetries || isTransientError(err) == false {
+			return err
+		}
+
+		sleepErr := signalutil.Sleep(ctx, backoff)
+		if sleepErr != nil {
+			return err
+		}
+
+		backoff *= 2
+	}
+}
+
+// timeoutError is returned by runWithTimeout if the function didn't return in time
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("Timed out after %v", e.timeout)
+}
+
+// runWithTimeout returns an error if run doesn't return within the timeout or the context is cancelled. The helm
+// getters can't be cancelled, so run continues in the background in this case
+func runWithTimeout(ctx context.Context, timeout time.Duration, run func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- run()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return &timeoutError{timeout}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isTransientError checks if the download failed because of a network error or a server side error (5xx) or rate
+// limiting (429). Timeouts of runWithTimeout are not retried, because the repository would block us again
+func isTransientError(err error) bool {
+	if _, ok := err.(*timeoutError); ok {
+		return false
+	}
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+
+	return transientStatusRegex.MatchString(err.Error())
+}
@@ -0,0 +1,196 @@
+package helm
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/util/log"
+	"k8s.io/helm/pkg/helm/helmpath"
+	"k8s.io/helm/pkg/repo"
+)
+
+func TestGetRepositoryEntries(t *testing.T) {
+	os.Setenv("DEVSPACE_TEST_REPO_PASSWORD", "secret")
+	defer os.Unsetenv("DEVSPACE_TEST_REPO_PASSWORD")
+
+	home := helmpath.Home("/home/user/.devspace/helm")
+	config := &v1.Config{
+		Helm: &v1.HelmClientConfig{
+			Repositories: &[]*v1.HelmRepositoryConfig{
+				{
+					Name:     configutil.String("internal"),
+					URL:      configutil.String("https://charts.internal"),
+					Username: configutil.String("admin"),
+					Password: configutil.String("${DEVSPACE_TEST_REPO_PASSWORD}"),
+					CAFile:   configutil.String("/etc/ssl/internal-ca.pem"),
+				},
+				{
+					Name:  configutil.String("tokenRepo"),
+					URL:   configutil.String("https://charts.example.com"),
+					Token: configutil.String("$DEVSPACE_TEST_REPO_PASSWORD"),
+				},
+			},
+		},
+	}
+
+	entries, err := getRepositoryEntries(config, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []repo.Entry{
+		{
+			Name:     "internal",
+			Cache:    home.CacheIndex("internal"),
+			URL:      "https://charts.internal",
+			Username: "admin",
+			Password: "secret",
+			CAFile:   "/etc/ssl/internal-ca.pem",
+		},
+		{
+			Name:     "tokenRepo",
+			Cache:    home.CacheIndex("tokenRepo"),
+			URL:      "https://charts.example.com",
+			Password: "secret",
+		},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), len(entries))
+	}
+	for index, entry := range entries {
+		if *entry != expected[index] {
+			t.Fatalf("Expected entry %#v, got %#v", expected[index], *entry)
+		}
+	}
+
+	// The config must keep the environment variable, so that the password is never saved
+	if *(*config.Helm.Repositories)[0].Password != "${DEVSPACE_TEST_REPO_PASSWORD}" {
+		t.Fatalf("Config was changed: %s", *(*config.Helm.Repositories)[0].Password)
+	}
+
+	(*config.Helm.Repositories)[1].Token = configutil.String("${DEVSPACE_TEST_REPO_MISSING}")
+	_, err = getRepositoryEntries(config, home)
+	if err == nil {
+		t.Fatal("Expected error for unset environment variable")
+	}
+}
+
+func TestMergeRepositories(t *testing.T) {
+	helmHome, err := ioutil.TempDir("", "helm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(helmHome)
+
+	repoFilePath := filepath.Join(helmHome, "repositories.yaml")
+	err = ioutil.WriteFile(repoFilePath, []byte(defaultRepositories), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := &repo.Entry{
+		Name:     "internal",
+		URL:      "https://charts.internal",
+		Username: "admin",
+		Password: "secret",
+	}
+
+	changed, err := mergeRepositories(repoFilePath, []*repo.Entry{entry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed == false {
+		t.Fatal("Expected repositories file to change")
+	}
+
+	changed, err = mergeRepositories(repoFilePath, []*repo.Entry{entry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Fatal("Expected unchanged repositories file")
+	}
+
+	repoFile, err := repo.LoadRepositoriesFile(repoFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repoFile.Repositories) != 2 || findRepository(repoFile, "stable") == nil {
+		t.Fatalf("Expected stable and internal repository, got %d repositories", len(repoFile.Repositories))
+	}
+	if merged := findRepository(repoFile, "internal"); merged == nil || *merged != *entry {
+		t.Fatalf("Internal repository wasn't merged correctly: %#v", merged)
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	testCases := map[error]bool{
+		&url.Error{Op: "Get", URL: "https://charts.internal/index.yaml", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}: true,
+		&url.Error{Op: "Get", URL: "https://charts.internal/index.yaml", Err: errors.New("x509: certificate signed by unknown authority")}:     false,
+		errors.New("Failed to fetch https://charts.internal/index.yaml : 503 Service Unavailable"):                                             true,
+		errors.New("Failed to fetch https://charts.internal/index.yaml : 429 Too Many Requests"):                                               true,
+		errors.New("Failed to fetch https://charts.internal/index.yaml : 401 Unauthorized"):                                                    false,
+		&timeoutError{time.Second}: false,
+	}
+
+	for err, expected := range testCases {
+		if isTransientError(err) != expected {
+			t.Fatalf("Expected isTransientError(%v) to be %v", err, expected)
+		}
+	}
+}
+
+func TestRunWithTimeout(t *testing.T) {
+	err := runWithTimeout(context.Background(), time.Second, func() error {
+		return errors.New("download failed")
+	})
+	if err == nil || err.Error() != "download failed" {
+		t.Fatalf("Expected the error of the function, got %v", err)
+	}
+
+	block := make(chan struct{})
+	defer close(block)
+
+	err = runWithTimeout(context.Background(), 10*time.Millisecond, func() error {
+		<-block
+		return nil
+	})
+	if _, ok := err.(*timeoutError); ok == false {
+		t.Fatalf("Expected timeout error, got %v", err)
+	}
+}
+
+func TestCheckRepoUpdateError(t *testing.T) {
+	defer func() { Offline = false }()
+
+	repoErr := &RepoUpdateError{
+		Errors: map[string]error{
+			"stable":   errors.New("connection refused"),
+			"internal": errors.New("401 Unauthorized"),
+		},
+	}
+
+	expected := "Unable to download the index of the chart repositories internal, stable\n- internal: 401 Unauthorized\n- stable: connection refused"
+	if repoErr.Error() != expected {
+		t.Fatalf("Expected error %q, got %q", expected, repoErr.Error())
+	}
+
+	Offline = false
+	if checkRepoUpdateError(repoErr, log.GetInstance()) == nil {
+		t.Fatal("Expected error without --offline")
+	}
+
+	Offline = true
+	if err := checkRepoUpdateError(repoErr, log.GetInstance()); err != nil {
+		t.Fatalf("Expected no error with --offline, got %v", err)
+	}
+}
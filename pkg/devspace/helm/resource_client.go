@@ -0,0 +1,56 @@
+package helm
+
+import (
+	"errors"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+)
+
+// resourceClient sends requests to the api paths of arbitrary resources. Charts can contain any kind, so tillerless
+// deployments and the conflict check can't use the typed clients
+type resourceClient interface {
+	Get(path string) ([]byte, error)
+	Create(collectionPath string, body []byte) error
+	Patch(path string, patchType types.PatchType, patch []byte) error
+	Delete(path string, body []byte) error
+}
+
+// restResourceClient sends the requests with the rest client of the kubernetes api server
+type restResourceClient struct {
+	client rest.Interface
+}
+
+func (c *restResourceClient) Get(path string) ([]byte, error) {
+	return c.client.Get().AbsPath(path).Do().Raw()
+}
+
+func (c *restResourceClient) Create(collectionPath string, body []byte) error {
+	_, err := c.client.Post().AbsPath(collectionPath).SetHeader("Content-Type", "application/json").Body(body).Do().Raw()
+	return err
+}
+
+func (c *restResourceClient) Patch(path string, patchType types.PatchType, patch []byte) error {
+	_, err := c.client.Patch(patchType).AbsPath(path).Body(patch).Do().Raw()
+	return err
+}
+
+func (c *restResourceClient) Delete(path string, body []byte) error {
+	_, err := c.client.Delete().AbsPath(path).SetHeader("Content-Type", "application/json").Body(body).Do().Raw()
+	return err
+}
+
+// getResourceClient returns the resource client of the wrapper or a client that uses the rest client of the
+// kubernetes client. The fake clientset has no rest client, so tests have to set the resource client
+func (helmClientWrapper *ClientWrapper) getResourceClient() (resourceClient, error) {
+	if helmClientWrapper.resources != nil {
+		return helmClientWrapper.resources, nil
+	}
+
+	restClient := helmClientWrapper.kubectl.Discovery().RESTClient()
+	if restClient == nil {
+		return nil, errors.New("Kubernetes client has no rest client")
+	}
+
+	return &restResourceClient{client: restClient}, nil
+}
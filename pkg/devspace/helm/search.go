@@ -1,9 +1,12 @@
 package helm
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/covexo/devspace/pkg/util/log"
 	helmdownloader "k8s.io/helm/pkg/downloader"
@@ -75,6 +78,37 @@ func (helmClientWrapper *ClientWrapper) PrintAllAvailableCharts() {
 
 // SearchChart searches the chart name in all repositories
 func (helmClientWrapper *ClientWrapper) SearchChart(chartName, chartVersion, appVersion string) (*repo.Entry, *repo.ChartVersion, error) {
+	re, versions, err := helmClientWrapper.GetChartVersions(chartName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if chartVersion != "" {
+		for _, version := range versions {
+			if version.GetVersion() == chartVersion {
+				return re, version, nil
+			}
+		}
+
+		return nil, nil, fmt.Errorf("Chart %s with chart version %s not found", chartName, chartVersion)
+	}
+
+	if appVersion != "" {
+		for _, version := range versions {
+			if version.GetAppVersion() == appVersion {
+				return re, version, nil
+			}
+		}
+
+		return nil, nil, fmt.Errorf("Chart %s with app version %s not found", chartName, appVersion)
+	}
+
+	return re, versions[0], nil
+}
+
+// GetChartVersions returns all versions of the chart (newest first) from the first repository that contains the
+// chart. The index files of the repositories are read from the cache, so UpdateRepos should be called before
+func (helmClientWrapper *ClientWrapper) GetChartVersions(chartName string) (*repo.Entry, repo.ChartVersions, error) {
 	allRepos, err := repo.LoadRepositoriesFile(helmClientWrapper.Settings.Home.RepositoryFile())
 	if err != nil {
 		return nil, nil, err
@@ -92,38 +126,55 @@ func (helmClientWrapper *ClientWrapper) SearchChart(chartName, chartVersion, app
 		// Sort versions
 		ind.SortEntries()
 
-		// Check if chart exists
-		if versions, ok := ind.Entries[chartName]; ok {
-			if len(versions) == 0 {
-				// Skip chart names that have zero releases.
-				continue
-			}
+		// Skip chart names that have zero releases
+		if versions, ok := ind.Entries[chartName]; ok && len(versions) > 0 {
+			return re, versions, nil
+		}
+	}
 
-			if chartVersion != "" {
-				for _, version := range versions {
-					if version.GetVersion() == chartVersion {
-						return re, version, nil
-					}
-				}
+	return nil, nil, fmt.Errorf("Chart %s not found", chartName)
+}
 
-				return nil, nil, fmt.Errorf("Chart %s with chart version %s not found", chartName, chartVersion)
-			}
+// UpdateRepos downloads the latest index files of all chart repositories
+func (helmClientWrapper *ClientWrapper) UpdateRepos() error {
+	return checkRepoUpdateError(helmClientWrapper.updateRepos(context.Background()), log.GetInstance())
+}
+
+// SearchStableCharts returns the latest version of all charts in the stable repository whose name contains the query
+// (case insensitive). A chart with exactly this name is returned first, the other charts are sorted by name
+func (helmClientWrapper *ClientWrapper) SearchStableCharts(query string) ([]*repo.ChartVersion, error) {
+	index, err := repo.LoadIndexFile(filepath.Join(helmClientWrapper.Settings.Home.String(), stableRepoCachePath))
+	if err != nil {
+		return nil, fmt.Errorf("Error loading stable repository index: %v", err)
+	}
 
-			if appVersion != "" {
-				for _, version := range versions {
-					if version.GetAppVersion() == appVersion {
-						return re, version, nil
-					}
-				}
+	// Sort versions
+	index.SortEntries()
 
-				return nil, nil, fmt.Errorf("Chart %s with app version %s not found", chartName, appVersion)
-			}
+	query = strings.ToLower(query)
+	charts := []*repo.ChartVersion{}
+
+	for name, versions := range index.Entries {
+		if len(versions) == 0 {
+			continue
+		}
 
-			return re, versions[0], nil
+		if strings.Contains(strings.ToLower(name), query) {
+			charts = append(charts, versions[0])
 		}
 	}
 
-	return nil, nil, fmt.Errorf("Chart %s not found", chartName)
+	sort.Slice(charts, func(a, b int) bool {
+		aExact := strings.ToLower(charts[a].GetName()) == query
+		bExact := strings.ToLower(charts[b].GetName()) == query
+		if aExact != bExact {
+			return aExact
+		}
+
+		return charts[a].GetName() < charts[b].GetName()
+	})
+
+	return charts, nil
 }
 
 // BuildDependencies builds the dependencies
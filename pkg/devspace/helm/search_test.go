@@ -0,0 +1,77 @@
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	helmenvironment "k8s.io/helm/pkg/helm/environment"
+	"k8s.io/helm/pkg/helm/helmpath"
+)
+
+const testStableIndex = `apiVersion: v1
+entries:
+  mysqldump:
+  - name: mysqldump
+    version: 1.0.0
+  mysql:
+  - name: mysql
+    version: 0.10.2
+    appVersion: 5.7.14
+  - name: mysql
+    version: 0.10.3
+    appVersion: 5.7.14
+  postgresql:
+  - name: postgresql
+    version: 2.6.2
+  redis:
+  - name: redis
+    version: 4.2.1
+`
+
+func TestSearchStableCharts(t *testing.T) {
+	helmHome, err := ioutil.TempDir("", "helm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(helmHome)
+
+	indexPath := filepath.Join(helmHome, stableRepoCachePath)
+	err = os.MkdirAll(filepath.Dir(indexPath), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(indexPath, []byte(testStableIndex), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	helmClient := &ClientWrapper{
+		Settings: &helmenvironment.EnvSettings{
+			Home: helmpath.Home(helmHome),
+		},
+	}
+
+	testCases := map[string][]string{
+		"mysql": {"mysql:0.10.3", "mysqldump:1.0.0"},
+		"SQL":   {"mysql:0.10.3", "mysqldump:1.0.0", "postgresql:2.6.2"},
+		"mongo": {},
+	}
+
+	for query, expected := range testCases {
+		charts, err := helmClient.SearchStableCharts(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(charts) != len(expected) {
+			t.Fatalf("Expected %d charts for %s, got %d", len(expected), query, len(charts))
+		}
+		for i, chart := range charts {
+			if chart.GetName()+":"+chart.GetVersion() != expected[i] {
+				t.Fatalf("Expected chart %s at position %d for %s, got %s:%s", expected[i], i, query, chart.GetName(), chart.GetVersion())
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package helm
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -8,15 +9,38 @@ import (
 
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
 	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
 	"github.com/covexo/devspace/pkg/util/log"
-	k8sv1 "k8s.io/api/core/v1"
+	"github.com/covexo/devspace/pkg/util/signalutil"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	helminstaller "k8s.io/helm/cmd/helm/installer"
+	helmversion "k8s.io/helm/pkg/version"
 )
 
 // TillerDeploymentName is the string identifier for the tiller deployment
 const TillerDeploymentName = "tiller-deploy"
+
+// tillerWaitTimeout is the maximum time we wait for tiller to become ready
+const tillerWaitTimeout = 2 * 60 * time.Second
+
+// tillerRetryInterval is the time we wait before retrying a failed request while waiting for tiller
+const tillerRetryInterval = time.Second
+
+// defaultTillerImage is the tiller image that is deployed if tiller.image is not configured
+const defaultTillerImage = "gcr.io/kubernetes-helm/tiller"
+
+// defaultTillerRegistry is replaced by cluster.registryMirror in the default tiller image
+const defaultTillerRegistry = "gcr.io"
+
+// defaultTillerVersion is the tiller version that is deployed if tiller.version is not configured
+const defaultTillerVersion = "v2.11.0"
+
+// sharedTiller is true if the tiller namespace was set with UseSharedTiller
+var sharedTiller = false
+
 const stableRepoCachePath = "repository/cache/stable-index.yaml"
 const defaultRepositories = `apiVersion: v1
 repositories:
@@ -28,31 +52,22 @@ repositories:
   url: https://kubernetes-charts.storage.googleapis.com
 `
 
-func ensureTiller(kubectlClient *kubernetes.Clientset, config *v1.Config, upgrade bool) error {
-	tillerNamespace := *config.Tiller.Namespace
+func ensureTiller(ctx context.Context, kubectlClient kubernetes.Interface, config *v1.Config, tillerNamespace string, upgrade bool) error {
 	tillerOptions := &helminstaller.Options{
 		Namespace:      tillerNamespace,
 		MaxHistory:     10,
-		ImageSpec:      "gcr.io/kubernetes-helm/tiller:v2.11.0",
+		ImageSpec:      getTillerImage(config),
 		ServiceAccount: TillerServiceAccountName,
 	}
 
-	_, err := kubectlClient.CoreV1().Namespaces().Get(tillerNamespace, metav1.GetOptions{})
-	if err != nil {
-		log.Donef("Create namespace %s", tillerNamespace)
+	checkTillerVersion(getTillerVersion(config))
 
-		// Create tiller namespace
-		_, err = kubectlClient.CoreV1().Namespaces().Create(&k8sv1.Namespace{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: tillerNamespace,
-			},
-		})
-		if err != nil {
-			return err
-		}
+	err := ensureNamespace(kubectlClient, tillerNamespace)
+	if err != nil {
+		return err
 	}
 
-	_, err = kubectlClient.ExtensionsV1beta1().Deployments(tillerNamespace).Get(TillerDeploymentName, metav1.GetOptions{})
+	deployment, err := kubectlClient.ExtensionsV1beta1().Deployments(tillerNamespace).Get(TillerDeploymentName, metav1.GetOptions{})
 	if err != nil {
 		// Create tiller server
 		err = createTiller(kubectlClient, config, tillerOptions)
@@ -63,24 +78,132 @@ func ensureTiller(kubectlClient *kubernetes.Clientset, config *v1.Config, upgrad
 		log.Done("Tiller started")
 	} else if upgrade {
 		// Upgrade tiller if necessary
-		tillerOptions.ImageSpec = ""
 		err = upgradeTiller(kubectlClient, tillerOptions)
 		if err != nil {
 			return err
 		}
+	} else if isTillerReady(deployment) {
+		// Tiller is already up and running, so there is nothing to wait for
+		return nil
+	}
+
+	return waitUntilTillerIsStarted(ctx, kubectlClient, tillerNamespace)
+}
+
+// UseSharedTiller uses the tiller in the given namespace for the current run without changing the config file. A
+// shared tiller is only used, devspace neither creates nor upgrades it
+func UseSharedTiller(namespace string) {
+	config := configutil.GetConfig()
+	tillerConfig := &v1.TillerConfig{
+		Namespace: &namespace,
+	}
+
+	if config.Tiller != nil {
+		tillerConfig.Tillerless = config.Tiller.Tillerless
+		tillerConfig.Image = config.Tiller.Image
+		tillerConfig.Version = config.Tiller.Version
+	}
+
+	config.Tiller = tillerConfig
+	sharedTiller = true
+}
+
+// checkSharedTiller waits until the shared tiller is ready without creating or upgrading it
+func checkSharedTiller(ctx context.Context, kubectlClient kubernetes.Interface, tillerNamespace string) error {
+	deployment, err := kubectlClient.ExtensionsV1beta1().Deployments(tillerNamespace).Get(TillerDeploymentName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("Tiller deployment %s/%s not found: %v", tillerNamespace, TillerDeploymentName, err)
+	}
+	if isTillerReady(deployment) {
+		return nil
+	}
+
+	return waitUntilTillerIsStarted(ctx, kubectlClient, tillerNamespace)
+}
+
+// EnsureTiller creates tiller or upgrades it to the configured version if upgrade is true. Without tiller
+// (tiller.tillerless) only the tiller namespace is created
+func EnsureTiller(ctx context.Context, kubectlClient kubernetes.Interface, upgrade bool) error {
+	config := configutil.GetConfig()
+	if config.Tiller == nil || config.Tiller.Namespace == nil {
+		return errors.New("No tiller namespace specified")
+	}
+
+	if IsTillerless() {
+		return ensureNamespace(kubectlClient, *config.Tiller.Namespace)
+	}
+
+	return ensureTiller(ctx, kubectlClient, config, *config.Tiller.Namespace, upgrade)
+}
+
+// checkTiller waits until tiller is ready without creating or upgrading it, because the cluster was bootstrapped
+func checkTiller(ctx context.Context, kubectlClient kubernetes.Interface, tillerNamespace string) error {
+	deployment, err := kubectlClient.ExtensionsV1beta1().Deployments(tillerNamespace).Get(TillerDeploymentName, metav1.GetOptions{})
+	if err != nil {
+		return kubectl.NewNotBootstrappedError("Tiller deployment "+tillerNamespace+"/"+TillerDeploymentName, err)
+	}
+	if isTillerReady(deployment) {
+		return nil
 	}
 
-	return waitUntilTillerIsStarted(kubectlClient)
+	return waitUntilTillerIsStarted(ctx, kubectlClient, tillerNamespace)
 }
 
-func createTiller(kubectlClient *kubernetes.Clientset, dsConfig *v1.Config, tillerOptions *helminstaller.Options) error {
+// getTillerVersion returns the configured tiller version with a leading v, e.g. v2.11.0
+func getTillerVersion(config *v1.Config) string {
+	if config.Tiller != nil && config.Tiller.Version != nil && *config.Tiller.Version != "" {
+		return "v" + strings.TrimPrefix(*config.Tiller.Version, "v")
+	}
+
+	return defaultTillerVersion
+}
+
+// getTillerImage returns the tiller image that is deployed. The tiller version is used as tag, unless tiller.image
+// already contains a tag or digest. If only cluster.registryMirror is configured, the default image is pulled from
+// the mirror
+func getTillerImage(config *v1.Config) string {
+	image := defaultTillerImage
+	if config.Tiller != nil && config.Tiller.Image != nil && *config.Tiller.Image != "" {
+		image = *config.Tiller.Image
+	} else if config.Cluster != nil && config.Cluster.RegistryMirror != nil && *config.Cluster.RegistryMirror != "" {
+		image = strings.TrimSuffix(*config.Cluster.RegistryMirror, "/") + strings.TrimPrefix(defaultTillerImage, defaultTillerRegistry)
+	}
+
+	imageName := image[strings.LastIndex(image, "/")+1:]
+	if strings.Contains(imageName, ":") || strings.Contains(imageName, "@") {
+		return image
+	}
+
+	return image + ":" + getTillerVersion(config)
+}
+
+// checkTillerVersion warns if the tiller version isn't supported by the helm client library devspace uses. Tiller has
+// to have the same major version and at least the minor version of the client
+func checkTillerVersion(tillerVersion string) {
+	clientVersion := strings.TrimPrefix(helmversion.Version, "v")
+	constraint := "^" + clientVersion + ".x"
+	if strings.Count(clientVersion, ".") > 1 {
+		constraint = "^" + clientVersion
+	}
+
+	if helmversion.IsCompatibleRange(constraint, tillerVersion) == false {
+		log.Warnf("Tiller %s might not be compatible with the helm client %s of devspace (expected %s)", tillerVersion, helmversion.Version, constraint)
+	}
+}
+
+// ensureNamespace creates the tiller namespace if it doesn't exist, unless devSpace.release.createNamespace is false
+func ensureNamespace(kubectlClient kubernetes.Interface, tillerNamespace string) error {
+	return kubectl.EnsureNamespace(kubectlClient, tillerNamespace, log.GetInstance())
+}
+
+func createTiller(kubectlClient kubernetes.Interface, dsConfig *v1.Config, tillerOptions *helminstaller.Options) error {
 	log.StartWait("Installing Tiller server")
 	defer log.StopWait()
 
 	// If the service account is already there we do not create it or any roles/rolebindings
-	_, err := kubectlClient.CoreV1().ServiceAccounts(*dsConfig.Tiller.Namespace).Get(TillerServiceAccountName, metav1.GetOptions{})
+	_, err := kubectlClient.CoreV1().ServiceAccounts(tillerOptions.Namespace).Get(TillerServiceAccountName, metav1.GetOptions{})
 	if err != nil {
-		err = createTillerRBAC(kubectlClient, dsConfig)
+		err = createTillerRBAC(kubectlClient, dsConfig, tillerOptions.Namespace)
 		if err != nil {
 			return err
 		}
@@ -90,31 +213,91 @@ func createTiller(kubectlClient *kubernetes.Clientset, dsConfig *v1.Config, till
 	return helminstaller.Install(kubectlClient, tillerOptions)
 }
 
-func waitUntilTillerIsStarted(kubectlClient *kubernetes.Clientset) error {
-	tillerWaitingTime := 2 * 60 * time.Second
-	tillerCheckInterval := 5 * time.Second
-	config := configutil.GetConfig()
+func waitUntilTillerIsStarted(ctx context.Context, kubectlClient kubernetes.Interface, tillerNamespace string) error {
+	deployments := kubectlClient.ExtensionsV1beta1().Deployments(tillerNamespace)
+	deadline := time.Now().Add(tillerWaitTimeout)
 
 	log.StartWait("Waiting for tiller to start")
 	defer log.StopWait()
 
-	for tillerWaitingTime > 0 {
-		tillerDeployment, err := kubectlClient.ExtensionsV1beta1().Deployments(*config.Tiller.Namespace).Get(TillerDeploymentName, metav1.GetOptions{})
+	for time.Now().Before(deadline) {
+		tillerDeployment, err := deployments.Get(TillerDeploymentName, metav1.GetOptions{})
 		if err != nil {
+			err = signalutil.Sleep(ctx, tillerRetryInterval)
+			if err != nil {
+				return err
+			}
+
 			continue
 		}
-		if tillerDeployment.Status.ReadyReplicas == tillerDeployment.Status.Replicas {
+		if isTillerReady(tillerDeployment) {
 			return nil
 		}
 
-		time.Sleep(tillerCheckInterval)
-		tillerWaitingTime = tillerWaitingTime - tillerCheckInterval
+		// Wait for status updates of the deployment instead of polling it
+		watcher, err := deployments.Watch(metav1.ListOptions{
+			FieldSelector:   "metadata.name=" + TillerDeploymentName,
+			ResourceVersion: tillerDeployment.ResourceVersion,
+		})
+		if err != nil {
+			err = signalutil.Sleep(ctx, tillerRetryInterval)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		ready := waitForTillerWatch(ctx, watcher, deadline)
+		watcher.Stop()
+		if ready {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 	}
 
 	return errors.New("Tiller didn't start in time")
 }
 
-func upgradeTiller(kubectlClient *kubernetes.Clientset, tillerOptions *helminstaller.Options) error {
+// waitForTillerWatch returns true as soon as the watched tiller deployment is ready. It returns false if the watch
+// is closed, the deadline is reached or the context is cancelled
+func waitForTillerWatch(ctx context.Context, watcher watch.Interface, deadline time.Time) bool {
+	timeout := time.After(time.Until(deadline))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timeout:
+			return false
+		case event, ok := <-watcher.ResultChan():
+			if ok == false || event.Type == watch.Error || event.Type == watch.Deleted {
+				return false
+			}
+
+			deployment, ok := event.Object.(*extensionsv1beta1.Deployment)
+			if ok && isTillerReady(deployment) {
+				return true
+			}
+		}
+	}
+}
+
+// isTillerReady checks if all desired replicas of the tiller deployment are ready
+func isTillerReady(deployment *extensionsv1beta1.Deployment) bool {
+	desiredReplicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desiredReplicas = *deployment.Spec.Replicas
+	}
+
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.ReadyReplicas >= desiredReplicas &&
+		deployment.Status.UpdatedReplicas >= desiredReplicas
+}
+
+func upgradeTiller(kubectlClient kubernetes.Interface, tillerOptions *helminstaller.Options) error {
 	log.StartWait("Upgrading tiller")
 	err := helminstaller.Upgrade(kubectlClient, tillerOptions)
 	log.StopWait()
@@ -125,8 +308,13 @@ func upgradeTiller(kubectlClient *kubernetes.Clientset, tillerOptions *helminsta
 	return nil
 }
 
-// IsTillerDeployed determines if we could connect to a tiller server
-func IsTillerDeployed(kubectlClient *kubernetes.Clientset) bool {
+// IsTillerDeployed determines if we could connect to a tiller server. Without tiller (tiller.tillerless) releases
+// can always be managed, so it returns true
+func IsTillerDeployed(kubectlClient kubernetes.Interface) bool {
+	if IsTillerless() {
+		return true
+	}
+
 	config := configutil.GetConfig()
 	tillerNamespace := *config.Tiller.Namespace
 	deployment, err := kubectlClient.ExtensionsV1beta1().Deployments(tillerNamespace).Get(TillerDeploymentName, metav1.GetOptions{})
@@ -142,7 +330,7 @@ func IsTillerDeployed(kubectlClient *kubernetes.Clientset) bool {
 }
 
 // DeleteTiller clears the tiller server, the service account and role binding
-func DeleteTiller(kubectlClient *kubernetes.Clientset) error {
+func DeleteTiller(kubectlClient kubernetes.Interface) error {
 	config := configutil.GetConfig()
 
 	tillerNamespace := *config.Tiller.Namespace
@@ -161,6 +349,12 @@ func DeleteTiller(kubectlClient *kubernetes.Clientset) error {
 		errs = append(errs, err)
 	}
 
+	// Without tiller the cluster has to be bootstrapped again
+	err = kubectlClient.CoreV1().ConfigMaps(tillerNamespace).Delete(kubectl.BootstrapConfigMapName, &metav1.DeleteOptions{})
+	if err != nil && strings.HasSuffix(err.Error(), "not found") == false {
+		errs = append(errs, err)
+	}
+
 	// Only delete service accounts and roles in non cloud-provider environments
 	if config.Cluster.CloudProvider == nil || *config.Cluster.CloudProvider == "" {
 		err = kubectlClient.CoreV1().ServiceAccounts(tillerNamespace).Delete(TillerServiceAccountName, &metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
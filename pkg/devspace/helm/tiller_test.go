@@ -0,0 +1,144 @@
+package helm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWaitForTillerWatchCancelled(t *testing.T) {
+	watcher := watch.NewFake()
+	defer watcher.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ready := make(chan bool, 1)
+	go func() {
+		ready <- waitForTillerWatch(ctx, watcher, time.Now().Add(time.Minute))
+	}()
+
+	cancel()
+
+	select {
+	case isReady := <-ready:
+		if isReady {
+			t.Fatal("Tiller reported as ready after the context was cancelled")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Waiting for tiller didn't stop after the context was cancelled")
+	}
+}
+
+func TestGetTillerImage(t *testing.T) {
+	testCases := []struct {
+		config   *v1.Config
+		expected string
+	}{
+		{
+			config:   &v1.Config{},
+			expected: "gcr.io/kubernetes-helm/tiller:v2.11.0",
+		},
+		{
+			config: &v1.Config{
+				Tiller: &v1.TillerConfig{Version: configutil.String("2.12.3")},
+			},
+			expected: "gcr.io/kubernetes-helm/tiller:v2.12.3",
+		},
+		{
+			config: &v1.Config{
+				Tiller: &v1.TillerConfig{Image: configutil.String("registry.internal:5000/tiller"), Version: configutil.String("v2.12.3")},
+			},
+			expected: "registry.internal:5000/tiller:v2.12.3",
+		},
+		{
+			config: &v1.Config{
+				Tiller: &v1.TillerConfig{Image: configutil.String("registry.internal/tiller:custom")},
+			},
+			expected: "registry.internal/tiller:custom",
+		},
+		{
+			config: &v1.Config{
+				Cluster: &v1.Cluster{RegistryMirror: configutil.String("registry.internal/gcr/")},
+			},
+			expected: "registry.internal/gcr/kubernetes-helm/tiller:v2.11.0",
+		},
+		{
+			config: &v1.Config{
+				Cluster: &v1.Cluster{RegistryMirror: configutil.String("registry.internal/gcr")},
+				Tiller:  &v1.TillerConfig{Image: configutil.String("registry.internal/tiller")},
+			},
+			expected: "registry.internal/tiller:v2.11.0",
+		},
+	}
+
+	for _, testCase := range testCases {
+		image := getTillerImage(testCase.config)
+		if image != testCase.expected {
+			t.Fatalf("Expected tiller image %s, got %s", testCase.expected, image)
+		}
+	}
+}
+
+func TestEnsureNamespace(t *testing.T) {
+	configutil.ResetConfig()
+	defer configutil.ResetConfig()
+
+	config := configutil.InitConfig()
+	config.Cluster.Namespace = configutil.String("test")
+
+	existing := &k8sv1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "existing",
+			Labels: map[string]string{"team": "backend"},
+		},
+	}
+	client := fake.NewSimpleClientset(existing)
+
+	for _, namespace := range []string{"tiller", "tiller", "existing"} {
+		err := ensureNamespace(client, namespace)
+		if err != nil {
+			t.Fatalf("Unexpected error for namespace %s: %v", namespace, err)
+		}
+	}
+
+	created, err := client.CoreV1().Namespaces().Get("tiller", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Tiller namespace wasn't created: %v", err)
+	}
+	if created.Labels[kubectl.CreatedByLabel] != kubectl.CreatedByLabelValue {
+		t.Fatalf("Tiller namespace is missing the label %s", kubectl.CreatedByLabel)
+	}
+
+	unchanged, err := client.CoreV1().Namespaces().Get("existing", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unchanged.Labels) != 1 || unchanged.Labels["team"] != "backend" {
+		t.Fatalf("Existing namespace was changed: %v", unchanged.Labels)
+	}
+
+	config.DevSpace.Release = &v1.ReleaseConfig{
+		CreateNamespace: configutil.Bool(false),
+	}
+
+	err = ensureNamespace(client, "existing")
+	if err != nil {
+		t.Fatalf("Unexpected error for existing namespace: %v", err)
+	}
+
+	err = ensureNamespace(client, "missing")
+	if err == nil {
+		t.Fatal("Expected error for missing namespace with createNamespace: false")
+	}
+	_, err = client.CoreV1().Namespaces().Get("missing", metav1.GetOptions{})
+	if err == nil {
+		t.Fatal("Namespace was created although createNamespace is false")
+	}
+}
@@ -0,0 +1,628 @@
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	k8sv1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	helmchartutil "k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/renderutil"
+	helmstoragedriver "k8s.io/helm/pkg/storage/driver"
+)
+
+// releaseRecordLabel is the label of the config maps that hold the releases deployed without tiller
+const releaseRecordLabel = "devspace.covexo.com/release"
+
+// releaseRecordKey is the config map key of the encoded release
+const releaseRecordKey = "release"
+
+// lastAppliedAnnotation is the annotation kubectl apply uses to store the last applied configuration
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// helmHookAnnotation marks resources that tiller creates as hooks instead of as part of the release
+const helmHookAnnotation = "helm.sh/hook"
+
+// installOrder is the order in which tiller creates resources of the given kinds, other kinds are created last
+var installOrder = []string{
+	"Namespace",
+	"ResourceQuota",
+	"LimitRange",
+	"PodSecurityPolicy",
+	"Secret",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"ServiceAccount",
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"Ingress",
+	"APIService",
+}
+
+// manifestDocument is a single resource of a rendered chart
+type manifestDocument struct {
+	resource *manifestResource
+	content  string
+}
+
+// IsTillerless returns true if helm charts are rendered locally and applied without tiller (tiller.tillerless)
+func IsTillerless() bool {
+	config := configutil.GetConfig()
+	return config.Tiller != nil && config.Tiller.Tillerless != nil && *config.Tiller.Tillerless
+}
+
+// installChartTillerless renders the chart locally and applies the resources with the same three-way merge kubectl
+// apply uses. The release is recorded in a config map in the tiller namespace, so it can be upgraded and deleted later
+func (helmClientWrapper *ClientWrapper) installChartTillerless(ch *chart.Chart, releaseName, releaseNamespace string, values []byte) (*hapi_release5.Release, error) {
+	previousRelease, err := helmClientWrapper.loadTillerlessRelease(releaseName)
+	if err != nil && isReleaseNotFound(err, releaseName) == false {
+		return nil, err
+	}
+
+	revision := 1
+	statusCode := hapi_release5.Status_PENDING_INSTALL
+	if previousRelease != nil {
+		revision = int(previousRelease.Version) + 1
+		statusCode = hapi_release5.Status_PENDING_UPGRADE
+	}
+
+	documents, hooks, err := helmClientWrapper.renderChart(ch, releaseName, releaseNamespace, revision, previousRelease != nil, values)
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		log.Warnf("Skipping hook %s %s, because hooks are not supported without tiller", hook.Kind, hook.Metadata.Name)
+	}
+
+	manifest := joinManifest(documents)
+	release := &hapi_release5.Release{
+		Name:      releaseName,
+		Namespace: releaseNamespace,
+		Version:   int32(revision),
+		Chart: &chart.Chart{
+			Metadata: ch.Metadata,
+		},
+		Config: &chart.Config{
+			Raw: string(values),
+		},
+		Manifest: manifest,
+		Info: &hapi_release5.Info{
+			Status: &hapi_release5.Status{
+				Code: statusCode,
+			},
+			FirstDeployed: ptypes.TimestampNow(),
+			LastDeployed:  ptypes.TimestampNow(),
+		},
+	}
+	if previousRelease != nil && previousRelease.Info != nil {
+		release.Info.FirstDeployed = previousRelease.Info.FirstDeployed
+	}
+
+	// Record the release before applying anything, so that the resources can be deleted even if applying fails
+	err = helmClientWrapper.saveTillerlessRelease(release)
+	if err != nil {
+		return nil, err
+	}
+
+	err = helmClientWrapper.applyDocuments(documents, releaseNamespace)
+	if err != nil {
+		// Keep the partially applied release, so that the deploy config can roll it back or purge it
+		release.Info.Status.Code = hapi_release5.Status_FAILED
+		helmClientWrapper.saveTillerlessRelease(release)
+
+		return nil, err
+	}
+
+	// Delete the resources that were removed from the chart since the last revision
+	if previousRelease != nil {
+		applied := map[string]bool{}
+		for _, document := range documents {
+			applied[resourceKey(document.resource, releaseNamespace)] = true
+		}
+
+		removed := []*manifestResource{}
+		for _, resource := range parseManifest(previousRelease.Manifest) {
+			if applied[resourceKey(resource, releaseNamespace)] == false {
+				removed = append(removed, resource)
+			}
+		}
+
+		err = helmClientWrapper.deleteResources(removed, releaseNamespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	release.Info.Status.Code = hapi_release5.Status_DEPLOYED
+	err = helmClientWrapper.saveTillerlessRelease(release)
+	if err != nil {
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// deleteReleaseTillerless deletes all resources of the release and the release record
+func (helmClientWrapper *ClientWrapper) deleteReleaseTillerless(releaseName string) (*rls.UninstallReleaseResponse, error) {
+	release, err := helmClientWrapper.loadTillerlessRelease(releaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := parseManifest(release.Manifest)
+	sortResources(resources)
+
+	// Delete the resources in reverse install order, e.g. deployments before their config maps
+	for i, j := 0, len(resources)-1; i < j; i, j = i+1, j-1 {
+		resources[i], resources[j] = resources[j], resources[i]
+	}
+
+	err = helmClientWrapper.deleteResources(resources, release.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	err = helmClientWrapper.kubectl.CoreV1().ConfigMaps(helmClientWrapper.Namespace).Delete(releaseRecordName(releaseName), &metav1.DeleteOptions{})
+	if err != nil && kerrors.IsNotFound(err) == false {
+		return nil, err
+	}
+
+	release.Info.Status.Code = hapi_release5.Status_DELETED
+	return &rls.UninstallReleaseResponse{
+		Release: release,
+	}, nil
+}
+
+// listReleasesTillerless returns all releases that were deployed without tiller sorted by name
+func (helmClientWrapper *ClientWrapper) listReleasesTillerless() ([]*hapi_release5.Release, error) {
+	configMaps, err := helmClientWrapper.kubectl.CoreV1().ConfigMaps(helmClientWrapper.Namespace).List(metav1.ListOptions{
+		LabelSelector: releaseRecordLabel,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	releases := make([]*hapi_release5.Release, 0, len(configMaps.Items))
+	for _, configMap := range configMaps.Items {
+		release, err := decodeRelease(configMap.Data[releaseRecordKey])
+		if err != nil {
+			return nil, fmt.Errorf("Error decoding release %s: %v", configMap.Name, err)
+		}
+
+		releases = append(releases, release)
+	}
+
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].Name < releases[j].Name
+	})
+
+	return releases, nil
+}
+
+// renderChart renders the templates of the chart locally and returns the resources in install order and the skipped
+// hooks. Hooks and NOTES.txt are skipped, because they are not part of the release
+func (helmClientWrapper *ClientWrapper) renderChart(ch *chart.Chart, releaseName, releaseNamespace string, revision int, isUpgrade bool, values []byte) ([]*manifestDocument, []*manifestResource, error) {
+	kubeVersion := ""
+	if serverVersion, err := helmClientWrapper.kubectl.Discovery().ServerVersion(); err == nil {
+		kubeVersion = serverVersion.GitVersion
+	}
+
+	templates, err := renderutil.Render(ch, &chart.Config{Raw: string(values)}, renderutil.Options{
+		ReleaseOptions: helmchartutil.ReleaseOptions{
+			Name:      releaseName,
+			Namespace: releaseNamespace,
+			Revision:  revision,
+			IsInstall: isUpgrade == false,
+			IsUpgrade: isUpgrade,
+			Time:      ptypes.TimestampNow(),
+		},
+		KubeVersion: kubeVersion,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	templateNames := make([]string, 0, len(templates))
+	for name := range templates {
+		if path.Base(name) != "NOTES.txt" {
+			templateNames = append(templateNames, name)
+		}
+	}
+	sort.Strings(templateNames)
+
+	documents := []*manifestDocument{}
+	hooks := []*manifestResource{}
+	for _, name := range templateNames {
+		for _, content := range strings.Split(templates[name], "\n---") {
+			resources := parseManifest(content)
+			if len(resources) == 0 {
+				continue
+			}
+			if _, ok := resources[0].Metadata.Annotations[helmHookAnnotation]; ok {
+				hooks = append(hooks, resources[0])
+				continue
+			}
+
+			documents = append(documents, &manifestDocument{
+				resource: resources[0],
+				content:  "# Source: " + name + "\n" + strings.TrimPrefix(strings.TrimSpace(content), "---"),
+			})
+		}
+	}
+
+	sort.SliceStable(documents, func(i, j int) bool {
+		return installIndex(documents[i].resource.Kind) < installIndex(documents[j].resource.Kind)
+	})
+
+	return documents, hooks, nil
+}
+
+// applyDocuments creates or updates all resources of the documents
+func (helmClientWrapper *ClientWrapper) applyDocuments(documents []*manifestDocument, releaseNamespace string) error {
+	apiResources, err := helmClientWrapper.kubectl.Discovery().ServerResources()
+	if err != nil && len(apiResources) == 0 {
+		return fmt.Errorf("Error retrieving server resources: %v", err)
+	}
+
+	for _, document := range documents {
+		resource := document.resource
+
+		apiResource, found := findAPIResource(apiResources, resource.APIVersion, resource.Kind)
+		if found == false {
+			// The kind may be defined by a custom resource definition of the chart that was just created
+			apiResources, _ = helmClientWrapper.kubectl.Discovery().ServerResources()
+
+			apiResource, found = findAPIResource(apiResources, resource.APIVersion, resource.Kind)
+			if found == false {
+				return fmt.Errorf("Unknown resource kind %s in api version %s", resource.Kind, resource.APIVersion)
+			}
+		}
+
+		err = helmClientWrapper.applyResource(document, apiResource, releaseNamespace)
+		if err != nil {
+			return fmt.Errorf("Error applying %s %s: %v", resource.Kind, resource.Metadata.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// applyResource creates the resource or patches it with a three-way merge of the last applied, the new and the
+// current configuration, so that changes of other clients are preserved like with kubectl apply
+func (helmClientWrapper *ClientWrapper) applyResource(document *manifestDocument, apiResource *metav1.APIResource, releaseNamespace string) error {
+	resource := document.resource
+	collectionPath, namespace := resourceCollectionPath(apiResource, resource, releaseNamespace)
+	resourcePath := collectionPath + "/" + resource.Metadata.Name
+
+	client, err := helmClientWrapper.getResourceClient()
+	if err != nil {
+		return err
+	}
+
+	modified, err := getModifiedConfiguration(document.content, namespace)
+	if err != nil {
+		return err
+	}
+
+	current, err := client.Get(resourcePath)
+	if err != nil {
+		if kerrors.IsNotFound(err) == false {
+			return err
+		}
+
+		return client.Create(collectionPath, modified)
+	}
+
+	original, err := getLastAppliedConfiguration(current)
+	if err != nil {
+		return err
+	}
+
+	patch, patchType, err := createApplyPatch(original, modified, current, resource)
+	if err != nil {
+		return fmt.Errorf("Error creating patch: %v", err)
+	}
+	if string(patch) == "{}" {
+		return nil
+	}
+
+	return client.Patch(resourcePath, patchType, patch)
+}
+
+// deleteResources deletes the given resources and ignores resources that don't exist anymore
+func (helmClientWrapper *ClientWrapper) deleteResources(resources []*manifestResource, releaseNamespace string) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	apiResources, err := helmClientWrapper.kubectl.Discovery().ServerResources()
+	if err != nil && len(apiResources) == 0 {
+		return fmt.Errorf("Error retrieving server resources: %v", err)
+	}
+
+	client, err := helmClientWrapper.getResourceClient()
+	if err != nil {
+		return err
+	}
+
+	deleteOptions := []byte(`{"kind":"DeleteOptions","apiVersion":"v1","propagationPolicy":"Background"}`)
+
+	for _, resource := range resources {
+		apiResource, found := findAPIResource(apiResources, resource.APIVersion, resource.Kind)
+		if found == false {
+			// The kind doesn't exist anymore, so neither does the resource
+			continue
+		}
+
+		collectionPath, _ := resourceCollectionPath(apiResource, resource, releaseNamespace)
+
+		err := client.Delete(collectionPath+"/"+resource.Metadata.Name, deleteOptions)
+		if err != nil && kerrors.IsNotFound(err) == false {
+			return fmt.Errorf("Error deleting %s %s: %v", resource.Kind, resource.Metadata.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// loadTillerlessRelease loads the release record or returns the same not found error tiller would return
+func (helmClientWrapper *ClientWrapper) loadTillerlessRelease(releaseName string) (*hapi_release5.Release, error) {
+	configMap, err := helmClientWrapper.kubectl.CoreV1().ConfigMaps(helmClientWrapper.Namespace).Get(releaseRecordName(releaseName), metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, helmstoragedriver.ErrReleaseNotFound(releaseName)
+		}
+
+		return nil, err
+	}
+
+	release, err := decodeRelease(configMap.Data[releaseRecordKey])
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding release %s: %v", releaseName, err)
+	}
+
+	return release, nil
+}
+
+// saveTillerlessRelease creates or updates the release record
+func (helmClientWrapper *ClientWrapper) saveTillerlessRelease(release *hapi_release5.Release) error {
+	encodedRelease, err := encodeRelease(release)
+	if err != nil {
+		return err
+	}
+
+	configMaps := helmClientWrapper.kubectl.CoreV1().ConfigMaps(helmClientWrapper.Namespace)
+	configMap := &k8sv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: releaseRecordName(release.Name),
+			Labels: map[string]string{
+				releaseRecordLabel: release.Name,
+				"version":          strconv.Itoa(int(release.Version)),
+			},
+		},
+		Data: map[string]string{
+			releaseRecordKey: encodedRelease,
+		},
+	}
+
+	_, err = configMaps.Update(configMap)
+	if kerrors.IsNotFound(err) {
+		_, err = configMaps.Create(configMap)
+	}
+	if err != nil {
+		return fmt.Errorf("Error saving release %s: %v", release.Name, err)
+	}
+
+	return nil
+}
+
+// encodeRelease encodes the release the same way tiller does (gzipped protobuf in base64)
+func encodeRelease(release *hapi_release5.Release) (string, error) {
+	data, err := proto.Marshal(release)
+	if err != nil {
+		return "", err
+	}
+
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	_, err = writer.Write(data)
+	if err != nil {
+		return "", err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+}
+
+func decodeRelease(encodedRelease string) (*hapi_release5.Release, error) {
+	data, err := base64.StdEncoding.DecodeString(encodedRelease)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err = ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	release := &hapi_release5.Release{}
+	err = proto.Unmarshal(data, release)
+	if err != nil {
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// getModifiedConfiguration converts the document to json and stores the configuration in the last applied
+// annotation, like kubectl apply does
+func getModifiedConfiguration(document, namespace string) ([]byte, error) {
+	jsonDocument, err := k8syaml.ToJSON([]byte(document))
+	if err != nil {
+		return nil, err
+	}
+
+	object := map[string]interface{}{}
+	err = json.Unmarshal(jsonDocument, &object)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, ok := object["metadata"].(map[string]interface{})
+	if ok == false {
+		return nil, fmt.Errorf("Resource has no metadata")
+	}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+
+	annotations, ok := metadata["annotations"].(map[string]interface{})
+	if ok == false {
+		annotations = map[string]interface{}{}
+	}
+	delete(annotations, lastAppliedAnnotation)
+	metadata["annotations"] = annotations
+
+	lastApplied, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations[lastAppliedAnnotation] = string(lastApplied)
+	return json.Marshal(object)
+}
+
+// getLastAppliedConfiguration returns the last applied configuration of the current resource or an empty object
+func getLastAppliedConfiguration(current []byte) ([]byte, error) {
+	object := &struct {
+		Metadata metav1.ObjectMeta `json:"metadata"`
+	}{}
+
+	err := json.Unmarshal(current, object)
+	if err != nil {
+		return nil, err
+	}
+
+	lastApplied, ok := object.Metadata.Annotations[lastAppliedAnnotation]
+	if ok == false || lastApplied == "" {
+		return []byte("{}"), nil
+	}
+
+	return []byte(lastApplied), nil
+}
+
+// createApplyPatch creates a strategic merge patch for built-in kinds and a json merge patch for custom resources,
+// which don't support strategic merge patches
+func createApplyPatch(original, modified, current []byte, resource *manifestResource) ([]byte, types.PatchType, error) {
+	versionedObject, err := scheme.Scheme.New(schema.FromAPIVersionAndKind(resource.APIVersion, resource.Kind))
+	if err == nil {
+		patchMeta, err := strategicpatch.NewPatchMetaFromStruct(versionedObject)
+		if err == nil {
+			patch, err := strategicpatch.CreateThreeWayMergePatch(original, modified, current, patchMeta, true)
+			return patch, types.StrategicMergePatchType, err
+		}
+	}
+
+	patch, err := jsonmergepatch.CreateThreeWayJSONMergePatch(original, modified, current)
+	return patch, types.MergePatchType, err
+}
+
+// resourceCollectionPath returns the api path of the collection of the resource and the namespace of the resource,
+// which is empty for cluster scoped resources
+func resourceCollectionPath(apiResource *metav1.APIResource, resource *manifestResource, releaseNamespace string) (string, string) {
+	path := "/apis/" + resource.APIVersion
+	if strings.Contains(resource.APIVersion, "/") == false {
+		path = "/api/" + resource.APIVersion
+	}
+
+	namespace := ""
+	if apiResource.Namespaced {
+		namespace = resource.Metadata.Namespace
+		if namespace == "" {
+			namespace = releaseNamespace
+		}
+
+		path += "/namespaces/" + namespace
+	}
+
+	return path + "/" + apiResource.Name, namespace
+}
+
+func sortResources(resources []*manifestResource) {
+	sort.SliceStable(resources, func(i, j int) bool {
+		return installIndex(resources[i].Kind) < installIndex(resources[j].Kind)
+	})
+}
+
+func installIndex(kind string) int {
+	for i, installKind := range installOrder {
+		if installKind == kind {
+			return i
+		}
+	}
+
+	return len(installOrder)
+}
+
+func joinManifest(documents []*manifestDocument) string {
+	contents := make([]string, 0, len(documents))
+	for _, document := range documents {
+		contents = append(contents, document.content)
+	}
+
+	return "---\n" + strings.Join(contents, "\n---\n")
+}
+
+func releaseRecordName(releaseName string) string {
+	return "devspace-release." + releaseName
+}
+
+func isReleaseNotFound(err error, releaseName string) bool {
+	return strings.Contains(err.Error(), helmstoragedriver.ErrReleaseNotFound(releaseName).Error())
+}
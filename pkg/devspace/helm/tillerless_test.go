@@ -0,0 +1,350 @@
+package helm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
+)
+
+// fakeResourceClient stores the resources by api path and remembers the type of the last patch of each resource
+type fakeResourceClient struct {
+	resources map[string][]byte
+	patches   map[string]types.PatchType
+}
+
+func newFakeResourceClient() *fakeResourceClient {
+	return &fakeResourceClient{
+		resources: map[string][]byte{},
+		patches:   map[string]types.PatchType{},
+	}
+}
+
+func (c *fakeResourceClient) Get(path string) ([]byte, error) {
+	resource, ok := c.resources[path]
+	if ok == false {
+		return nil, kerrors.NewNotFound(schema.GroupResource{}, path)
+	}
+
+	return resource, nil
+}
+
+func (c *fakeResourceClient) Create(collectionPath string, body []byte) error {
+	object := &struct {
+		Metadata metav1.ObjectMeta `json:"metadata"`
+	}{}
+
+	err := json.Unmarshal(body, object)
+	if err != nil {
+		return err
+	}
+
+	c.resources[collectionPath+"/"+object.Metadata.Name] = body
+	return nil
+}
+
+func (c *fakeResourceClient) Patch(path string, patchType types.PatchType, patch []byte) error {
+	if _, ok := c.resources[path]; ok == false {
+		return kerrors.NewNotFound(schema.GroupResource{}, path)
+	}
+
+	c.patches[path] = patchType
+	return nil
+}
+
+func (c *fakeResourceClient) Delete(path string, body []byte) error {
+	if _, ok := c.resources[path]; ok == false {
+		return kerrors.NewNotFound(schema.GroupResource{}, path)
+	}
+
+	delete(c.resources, path)
+	return nil
+}
+
+func TestEncodeDecodeRelease(t *testing.T) {
+	release := &hapi_release5.Release{
+		Name:      "api",
+		Namespace: "test",
+		Version:   3,
+		Manifest:  "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config",
+		Config:    &chart.Config{Raw: "replicas: 2\n"},
+		Info: &hapi_release5.Info{
+			Status: &hapi_release5.Status{Code: hapi_release5.Status_DEPLOYED},
+		},
+	}
+
+	encodedRelease, err := encodeRelease(release)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decodedRelease, err := decodeRelease(encodedRelease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proto.Equal(release, decodedRelease) == false {
+		t.Fatalf("Expected %v, got %v", release, decodedRelease)
+	}
+
+	_, err = decodeRelease("not base64")
+	if err == nil {
+		t.Fatal("Expected error for an invalid release record")
+	}
+}
+
+func TestGetModifiedConfiguration(t *testing.T) {
+	testCases := []struct {
+		name              string
+		document          string
+		namespace         string
+		expectedNamespace string
+		expectedErr       bool
+	}{
+		{
+			name:              "Namespace of the chart",
+			document:          "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: chart",
+			expectedNamespace: "chart",
+		},
+		{
+			name:              "Namespace of the release",
+			document:          "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config",
+			namespace:         "release",
+			expectedNamespace: "release",
+		},
+		{
+			name:              "Stale last applied configuration",
+			document:          "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  annotations:\n    kubectl.kubernetes.io/last-applied-configuration: '{}'\n    team: backend",
+			namespace:         "release",
+			expectedNamespace: "release",
+		},
+		{
+			name:        "No metadata",
+			document:    "apiVersion: v1\nkind: ConfigMap",
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		modified, err := getModifiedConfiguration(testCase.document, testCase.namespace)
+		if testCase.expectedErr {
+			if err == nil {
+				t.Fatalf("%s: expected error", testCase.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", testCase.name, err)
+		}
+
+		object := &struct {
+			Metadata metav1.ObjectMeta `json:"metadata"`
+		}{}
+		err = json.Unmarshal(modified, object)
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.name, err)
+		}
+		if object.Metadata.Namespace != testCase.expectedNamespace {
+			t.Fatalf("%s: expected namespace %s, got %s", testCase.name, testCase.expectedNamespace, object.Metadata.Namespace)
+		}
+
+		lastApplied := &struct {
+			Metadata metav1.ObjectMeta `json:"metadata"`
+		}{}
+		err = json.Unmarshal([]byte(object.Metadata.Annotations[lastAppliedAnnotation]), lastApplied)
+		if err != nil {
+			t.Fatalf("%s: invalid last applied configuration: %v", testCase.name, err)
+		}
+		if _, ok := lastApplied.Metadata.Annotations[lastAppliedAnnotation]; ok {
+			t.Fatalf("%s: expected the last applied configuration not to contain itself", testCase.name)
+		}
+		if lastApplied.Metadata.Namespace != testCase.expectedNamespace || lastApplied.Metadata.Name != "config" {
+			t.Fatalf("%s: unexpected last applied configuration %s", testCase.name, object.Metadata.Annotations[lastAppliedAnnotation])
+		}
+	}
+}
+
+func TestResourceCollectionPath(t *testing.T) {
+	testCases := []struct {
+		name              string
+		apiResource       *metav1.APIResource
+		manifest          string
+		expectedPath      string
+		expectedNamespace string
+	}{
+		{
+			name:              "Core kind",
+			apiResource:       &metav1.APIResource{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+			manifest:          "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config",
+			expectedPath:      "/api/v1/namespaces/release/configmaps",
+			expectedNamespace: "release",
+		},
+		{
+			name:              "Group kind",
+			apiResource:       &metav1.APIResource{Name: "deployments", Kind: "Deployment", Namespaced: true},
+			manifest:          "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api\n  namespace: chart",
+			expectedPath:      "/apis/apps/v1/namespaces/chart/deployments",
+			expectedNamespace: "chart",
+		},
+		{
+			name:         "Cluster scoped kind",
+			apiResource:  &metav1.APIResource{Name: "clusterroles", Kind: "ClusterRole"},
+			manifest:     "apiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n  name: reader",
+			expectedPath: "/apis/rbac.authorization.k8s.io/v1/clusterroles",
+		},
+	}
+
+	for _, testCase := range testCases {
+		path, namespace := resourceCollectionPath(testCase.apiResource, parseManifest(testCase.manifest)[0], "release")
+		if path != testCase.expectedPath || namespace != testCase.expectedNamespace {
+			t.Fatalf("%s: expected %s in namespace '%s', got %s in namespace '%s'", testCase.name, testCase.expectedPath, testCase.expectedNamespace, path, namespace)
+		}
+	}
+}
+
+func TestRenderChart(t *testing.T) {
+	kubectl := fake.NewSimpleClientset()
+	kubectl.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.10.0"}
+
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "api", Version: "0.1.0"},
+		Templates: []*chart.Template{
+			{
+				Name: "templates/deployment.yaml",
+				Data: []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: {{ .Release.Name }}\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: {{ .Release.Name }}"),
+			},
+			{
+				Name: "templates/configmap.yaml",
+				Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Release.Name }}-config"),
+			},
+			{
+				Name: "templates/migration.yaml",
+				Data: []byte("apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migration\n  annotations:\n    helm.sh/hook: pre-install"),
+			},
+			{
+				Name: "templates/NOTES.txt",
+				Data: []byte("kind: Note\nmetadata:\n  name: notes"),
+			},
+		},
+	}
+
+	helmClientWrapper := &ClientWrapper{kubectl: kubectl}
+	documents, hooks, err := helmClientWrapper.renderChart(ch, "api", "test", 1, false, []byte(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := []string{}
+	for _, document := range documents {
+		kinds = append(kinds, document.resource.Kind+"/"+document.resource.Metadata.Name)
+
+		if strings.HasPrefix(document.content, "# Source: api/templates/") == false {
+			t.Fatalf("Expected the source of the template in %s", document.content)
+		}
+	}
+	if strings.Join(kinds, ",") != "ConfigMap/api-config,Service/api,Deployment/api" {
+		t.Fatalf("Unexpected documents in install order: %v", kinds)
+	}
+	if len(hooks) != 1 || hooks[0].Kind != "Job" {
+		t.Fatalf("Expected the job to be skipped as hook, got %v", hooks)
+	}
+}
+
+func TestCreateApplyPatch(t *testing.T) {
+	testCases := []struct {
+		name              string
+		manifest          string
+		expectedPatchType types.PatchType
+	}{
+		{
+			name:              "Built-in kind",
+			manifest:          "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api",
+			expectedPatchType: types.StrategicMergePatchType,
+		},
+		{
+			name:              "Custom resource",
+			manifest:          "apiVersion: example.com/v1\nkind: Database\nmetadata:\n  name: api",
+			expectedPatchType: types.MergePatchType,
+		},
+	}
+
+	original := []byte(`{"metadata":{"name":"api"},"spec":{"replicas":1}}`)
+	modified := []byte(`{"metadata":{"name":"api"},"spec":{"replicas":2}}`)
+	current := []byte(`{"metadata":{"name":"api","labels":{"team":"backend"}},"spec":{"replicas":1}}`)
+
+	for _, testCase := range testCases {
+		patch, patchType, err := createApplyPatch(original, modified, current, parseManifest(testCase.manifest)[0])
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.name, err)
+		}
+		if patchType != testCase.expectedPatchType {
+			t.Fatalf("%s: expected patch type %s, got %s", testCase.name, testCase.expectedPatchType, patchType)
+		}
+		if strings.Contains(string(patch), `"replicas":2`) == false || strings.Contains(string(patch), "team") {
+			t.Fatalf("%s: unexpected patch %s", testCase.name, string(patch))
+		}
+	}
+}
+
+func TestApplyAndDeleteResources(t *testing.T) {
+	kubectl := fake.NewSimpleClientset()
+	kubectl.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap", Namespaced: true}},
+		},
+	}
+
+	resources := newFakeResourceClient()
+	helmClientWrapper := &ClientWrapper{kubectl: kubectl, resources: resources}
+
+	content := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\ndata:\n  level: info"
+	documents := []*manifestDocument{{resource: parseManifest(content)[0], content: content}}
+	resourcePath := "/api/v1/namespaces/test/configmaps/config"
+
+	// The config map doesn't exist, so it is created
+	err := helmClientWrapper.applyDocuments(documents, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resources.resources[resourcePath]; ok == false {
+		t.Fatalf("Expected %s to be created", resourcePath)
+	}
+
+	// The config map changed, so it is patched
+	documents[0].content = strings.Replace(content, "info", "debug", 1)
+	err = helmClientWrapper.applyDocuments(documents, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resources.patches[resourcePath] != types.StrategicMergePatchType {
+		t.Fatalf("Expected %s to be patched", resourcePath)
+	}
+
+	// Resources that don't exist anymore are ignored
+	for i := 0; i < 2; i++ {
+		err = helmClientWrapper.deleteResources([]*manifestResource{documents[0].resource}, "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := resources.resources[resourcePath]; ok {
+		t.Fatalf("Expected %s to be deleted", resourcePath)
+	}
+
+	// Without resource client the fake clientset has no rest client
+	helmClientWrapper.resources = nil
+	err = helmClientWrapper.applyDocuments(documents, "test")
+	if err == nil {
+		t.Fatal("Expected error without rest client")
+	}
+}
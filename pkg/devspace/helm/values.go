@@ -0,0 +1,258 @@
+package helm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+	helmchartutil "k8s.io/helm/pkg/chartutil"
+)
+
+// valuesReferenceRegEx matches references like .Values.containers.default.image or $.Values.pullSecrets
+var valuesReferenceRegEx = regexp.MustCompile(`\.Values\b((?:\.[A-Za-z0-9_]+)*)`)
+
+// valuesIndexRegEx matches references like index .Values "my-key" "image"
+var valuesIndexRegEx = regexp.MustCompile(`index\s+\$?\.Values((?:\s+"[^"]*")+)`)
+
+// managedValues are the top level keys of the chart values that are set by devspace during deployment
+var managedValues = []string{"containers", "pullSecrets"}
+
+// ValueUsage describes a key of the chart values and the templates that consume it
+type ValueUsage struct {
+	// Key is the path of the key, e.g. containers.default.image
+	Key   string
+	Value interface{}
+
+	// Templates are the names of the templates that reference the key, one of its parents or one of its children
+	Templates []string
+
+	// Managed is true if the key is overwritten by devspace during deployment (containers.*, pullSecrets)
+	Managed bool
+
+	// Subchart holds the name of the dependency the key is passed to or is empty
+	Subchart string
+}
+
+// Unused returns true if no template consumes the key and the key is neither managed by devspace nor passed to a subchart
+func (v *ValueUsage) Unused() bool {
+	return len(v.Templates) == 0 && v.Managed == false && v.Subchart == ""
+}
+
+// ValuesAnalysis holds the result of the static analysis of the chart values and templates
+type ValuesAnalysis struct {
+	// Usages holds the usage of every key of values.yaml sorted by key
+	Usages []*ValueUsage
+
+	// UnknownReferences maps keys that are referenced in templates, but not defined in values.yaml, to the templates
+	UnknownReferences map[string][]string
+
+	// references maps each referenced key to the templates that reference it
+	references map[string][]string
+}
+
+// AnalyzeValues statically analyzes which keys of the chart values are consumed by which templates. Only direct
+// references (.Values.a.b and index .Values "a" "b") are found, so keys that are accessed via variables count as
+// consumed if their parent is referenced, e.g. range .Values.containers
+func AnalyzeValues(chartPath string) (*ValuesAnalysis, error) {
+	chart, err := helmchartutil.Load(chartPath)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading chart %s: %v", chartPath, err)
+	}
+
+	values := map[interface{}]interface{}{}
+	if chart.Values != nil && chart.Values.Raw != "" {
+		err = yaml.Unmarshal([]byte(chart.Values.Raw), values)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing values.yaml of chart %s: %v", chartPath, err)
+		}
+	}
+
+	templates := map[string]string{}
+	for _, template := range chart.Templates {
+		templates[template.Name] = string(template.Data)
+	}
+
+	subcharts := []string{}
+	for _, dependency := range chart.Dependencies {
+		if dependency.Metadata != nil {
+			subcharts = append(subcharts, dependency.Metadata.Name)
+		}
+	}
+	if reqs, err := helmchartutil.LoadRequirements(chart); err == nil {
+		for _, dependency := range reqs.Dependencies {
+			subcharts = append(subcharts, dependency.Name)
+			if dependency.Alias != "" {
+				subcharts = append(subcharts, dependency.Alias)
+			}
+		}
+	}
+
+	return analyzeValues(templates, values, subcharts), nil
+}
+
+func analyzeValues(templates map[string]string, values map[interface{}]interface{}, subcharts []string) *ValuesAnalysis {
+	analysis := &ValuesAnalysis{
+		Usages:            []*ValueUsage{},
+		UnknownReferences: map[string][]string{},
+		references:        map[string][]string{},
+	}
+
+	templateNames := make([]string, 0, len(templates))
+	for name := range templates {
+		templateNames = append(templateNames, name)
+	}
+	sort.Strings(templateNames)
+
+	for _, name := range templateNames {
+		for _, reference := range findValuesReferences(templates[name]) {
+			if containsString(analysis.references[reference], name) == false {
+				analysis.references[reference] = append(analysis.references[reference], name)
+			}
+		}
+	}
+
+	flattenValues("", values, func(key string, value interface{}) {
+		usage := &ValueUsage{
+			Key:       key,
+			Value:     value,
+			Templates: analysis.consumers(key),
+			Managed:   isManagedValue(key),
+		}
+
+		topLevelKey := strings.SplitN(key, ".", 2)[0]
+		if topLevelKey == "global" || containsString(subcharts, topLevelKey) {
+			usage.Subchart = topLevelKey
+		}
+
+		analysis.Usages = append(analysis.Usages, usage)
+	})
+	sort.Slice(analysis.Usages, func(i, j int) bool {
+		return analysis.Usages[i].Key < analysis.Usages[j].Key
+	})
+
+	for reference, referencingTemplates := range analysis.references {
+		if reference == "" || isManagedValue(reference) || analysis.isDefined(reference) {
+			continue
+		}
+
+		analysis.UnknownReferences[reference] = referencingTemplates
+	}
+
+	return analysis
+}
+
+// IsConsumed returns true if the key, one of its parents or one of its children is referenced in a template
+func (a *ValuesAnalysis) IsConsumed(key string) bool {
+	return len(a.consumers(key)) > 0
+}
+
+// ImagesWithoutConsumer returns the names of the images whose image url (containers.<name>.image) is not consumed
+// by any template, so building and pushing them has no effect on the deployment
+func (a *ValuesAnalysis) ImagesWithoutConsumer(imageNames []string) []string {
+	unconsumed := []string{}
+	for _, imageName := range imageNames {
+		if a.IsConsumed("containers."+imageName+".image") == false {
+			unconsumed = append(unconsumed, imageName)
+		}
+	}
+
+	sort.Strings(unconsumed)
+	return unconsumed
+}
+
+// consumers returns the sorted names of the templates that reference the key, one of its parents or children
+func (a *ValuesAnalysis) consumers(key string) []string {
+	templates := []string{}
+	for reference, referencingTemplates := range a.references {
+		if isRelatedKey(reference, key) == false {
+			continue
+		}
+
+		for _, template := range referencingTemplates {
+			if containsString(templates, template) == false {
+				templates = append(templates, template)
+			}
+		}
+	}
+
+	sort.Strings(templates)
+	return templates
+}
+
+// isDefined returns true if the referenced key, one of its parents or one of its children exists in values.yaml
+func (a *ValuesAnalysis) isDefined(reference string) bool {
+	for _, usage := range a.Usages {
+		if isRelatedKey(reference, usage.Key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findValuesReferences returns the keys that are referenced in the template, an empty key references all values
+func findValuesReferences(template string) []string {
+	references := []string{}
+
+	for _, match := range valuesIndexRegEx.FindAllStringSubmatch(template, -1) {
+		keys := []string{}
+		for _, key := range strings.Fields(match[1]) {
+			keys = append(keys, strings.Trim(key, `"`))
+		}
+
+		references = append(references, strings.Join(keys, "."))
+	}
+
+	// Remove the index references, otherwise their .Values would reference all values
+	template = valuesIndexRegEx.ReplaceAllString(template, "")
+
+	for _, match := range valuesReferenceRegEx.FindAllStringSubmatch(template, -1) {
+		references = append(references, strings.TrimPrefix(match[1], "."))
+	}
+
+	return references
+}
+
+// flattenValues calls fn for every leaf of the values with its dotted key. Lists and empty maps are leafs
+func flattenValues(prefix string, values map[interface{}]interface{}, fn func(key string, value interface{})) {
+	for key, value := range values {
+		fullKey := fmt.Sprintf("%v", key)
+		if prefix != "" {
+			fullKey = prefix + "." + fullKey
+		}
+
+		if nestedValues, ok := value.(map[interface{}]interface{}); ok && len(nestedValues) > 0 {
+			flattenValues(fullKey, nestedValues, fn)
+			continue
+		}
+
+		fn(fullKey, value)
+	}
+}
+
+// isRelatedKey returns true if both keys are equal or one of them is a parent of the other one
+func isRelatedKey(a, b string) bool {
+	return a == "" || b == "" || a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+}
+
+func isManagedValue(key string) bool {
+	for _, managedValue := range managedValues {
+		if key == managedValue || strings.HasPrefix(key, managedValue+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
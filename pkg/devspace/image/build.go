@@ -1,10 +1,12 @@
 package image
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"k8s.io/client-go/kubernetes"
 
@@ -21,18 +23,24 @@ import (
 	"github.com/docker/docker/api/types"
 )
 
-// BuildAll builds all images
-func BuildAll(client *kubernetes.Clientset, generatedConfig *generated.Config, forceRebuild bool, log log.Logger) (bool, error) {
+// BuildAll builds all images. If validateDockerfiles is true, the dockerfiles are checked for obvious problems before building.
+// dockerBuildKit and buildKitInlineCache enable BuildKit for all images that are built with docker. Building stops as soon as
+// the context is cancelled
+func BuildAll(ctx context.Context, client kubernetes.Interface, generatedConfig *generated.Config, forceRebuild, validateDockerfiles, dockerBuildKit, buildKitInlineCache bool, log log.Logger) (bool, error) {
 	config := configutil.GetConfig()
 	re := false
 
 	for imageName, imageConf := range *config.Images {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+
 		if imageConf.Build != nil && imageConf.Build.Disabled != nil && *imageConf.Build.Disabled == true {
 			log.Infof("Skipping building image %s", imageName)
 			continue
 		}
 
-		shouldRebuild, err := Build(client, generatedConfig, imageName, imageConf, forceRebuild, log)
+		shouldRebuild, err := Build(ctx, client, generatedConfig, imageName, imageConf, forceRebuild, validateDockerfiles, dockerBuildKit, buildKitInlineCache, log)
 		if err != nil {
 			return false, err
 		}
@@ -46,7 +54,7 @@ func BuildAll(client *kubernetes.Clientset, generatedConfig *generated.Config, f
 }
 
 // Build builds an image with the specified engine
-func Build(client *kubernetes.Clientset, generatedConfig *generated.Config, imageName string, imageConf *v1.ImageConfig, forceRebuild bool, log log.Logger) (bool, error) {
+func Build(ctx context.Context, client kubernetes.Interface, generatedConfig *generated.Config, imageName string, imageConf *v1.ImageConfig, forceRebuild, validateDockerfile, dockerBuildKit, buildKitInlineCache bool, log log.Logger) (bool, error) {
 	rebuild := false
 	config := configutil.GetConfig()
 	dockerfilePath := "./Dockerfile"
@@ -76,6 +84,18 @@ func Build(client *kubernetes.Clientset, generatedConfig *generated.Config, imag
 		var imageBuilder builder.Interface
 		rebuild = true
 
+		if validateDockerfile {
+			var buildArgs map[string]*string
+			if imageConf.Build != nil && imageConf.Build.Options != nil && imageConf.Build.Options.BuildArgs != nil {
+				buildArgs = *imageConf.Build.Options.BuildArgs
+			}
+
+			err = validate(absoluteDockerfilePath, contextPath, buildArgs, log)
+			if err != nil {
+				return false, err
+			}
+		}
+
 		imageTag, err := randutil.GenerateRandomString(7)
 		if err != nil {
 			return false, fmt.Errorf("Image building failed: %v", err)
@@ -89,6 +109,16 @@ func Build(client *kubernetes.Clientset, generatedConfig *generated.Config, imag
 			return false, fmt.Errorf("GetRegistryConfigFromImageConfig failed: %v", err)
 		}
 
+		mirrors, err := getRegistryMirrors(imageConf)
+		if err != nil {
+			return false, fmt.Errorf("Invalid registry mirrors of image %s: %v", imageName, err)
+		}
+
+		rewriteImages := imageConf.Build != nil && imageConf.Build.RewriteBaseImages != nil && *imageConf.Build.RewriteBaseImages
+		if otherRegistries := mirrors.otherRegistries(); len(otherRegistries) > 0 && rewriteImages == false {
+			log.Warnf("The mirrors of %s are only used with rewriteBaseImages: true", strings.Join(otherRegistries, ", "))
+		}
+
 		engineName := ""
 
 		if imageConf.Build != nil && imageConf.Build.Kaniko != nil {
@@ -117,10 +147,13 @@ func Build(client *kubernetes.Clientset, generatedConfig *generated.Config, imag
 				return false, fmt.Errorf("Error creating docker client: %v", err)
 			}
 
-			imageBuilder, err = kaniko.NewBuilder(*registryConf.URL, pullSecret, imageName, imageTag, (*generatedConfig).ImageTags[imageName], buildNamespace, dockerClient, client, allowInsecurePush)
+			kanikoBuilder, err := kaniko.NewBuilder(*registryConf.URL, pullSecret, imageName, imageTag, (*generatedConfig).ImageTags[imageName], buildNamespace, dockerClient, client, allowInsecurePush)
 			if err != nil {
 				return false, fmt.Errorf("Error creating kaniko builder: %v", err)
 			}
+
+			kanikoBuilder.RegistryMirror = mirrors[dockerclient.DockerHubRegistry]
+			imageBuilder = kanikoBuilder
 		} else {
 			engineName = "docker"
 
@@ -134,24 +167,42 @@ func Build(client *kubernetes.Clientset, generatedConfig *generated.Config, imag
 				return false, fmt.Errorf("Error creating docker client: %v", err)
 			}
 
-			imageBuilder, err = docker.NewBuilder(dockerClient, *registryConf.URL, imageName, imageTag)
+			dockerBuilder, err := docker.NewBuilder(dockerClient, *registryConf.URL, imageName, imageTag)
 			if err != nil {
 				return false, fmt.Errorf("Error creating docker builder: %v", err)
 			}
-		}
 
-		log.Infof("Building image '%s' with engine '%s'", imageName, engineName)
+			// The docker daemon pulls the base images through the mirrors of its own config only
+			if hubMirror, ok := mirrors[dockerclient.DockerHubRegistry]; ok && rewriteImages == false {
+				dockerclient.CheckDaemonMirrors(ctx, dockerClient, []string{hubMirror}, log)
+			}
 
-		username := ""
-		password := ""
-		if registryConf.Auth != nil {
-			if registryConf.Auth.Username != nil {
-				username = *registryConf.Auth.Username
+			if imageConf.Build != nil && imageConf.Build.Docker != nil {
+				if imageConf.Build.Docker.BuildKit != nil && *imageConf.Build.Docker.BuildKit {
+					dockerBuildKit = true
+				}
+				if imageConf.Build.Docker.BuildKitInlineCache != nil && *imageConf.Build.Docker.BuildKitInlineCache {
+					buildKitInlineCache = true
+				}
 			}
 
-			if registryConf.Auth.Password != nil {
-				password = *registryConf.Auth.Password
+			// The inline cache is written by BuildKit only
+			if dockerBuildKit || buildKitInlineCache {
+				engineName = "docker (BuildKit)"
+
+				dockerBuilder.BuildKit = true
+				dockerBuilder.BuildKitInlineCache = buildKitInlineCache
+				dockerBuilder.Env = dockerclient.GetCliEnvironment(preferMinikube)
 			}
+
+			imageBuilder = dockerBuilder
+		}
+
+		log.Infof("Building image '%s' with engine '%s'", imageName, engineName)
+
+		username, password, err := registry.GetRegistryCredentials(registryConf)
+		if err != nil {
+			return false, fmt.Errorf("Error retrieving registry credentials: %v", err)
 		}
 
 		displayRegistryURL := "hub.docker.com"
@@ -183,13 +234,23 @@ func Build(client *kubernetes.Clientset, generatedConfig *generated.Config, imag
 			}
 		}
 
-		err = imageBuilder.BuildImage(contextPath, absoluteDockerfilePath, buildOptions)
+		buildDockerfilePath := absoluteDockerfilePath
+		if rewriteImages && len(mirrors) > 0 {
+			buildDockerfilePath, err = rewriteBaseImages(absoluteDockerfilePath, mirrors, log)
+			if err != nil {
+				return false, fmt.Errorf("Error rewriting the base images of %s: %v", dockerfilePath, err)
+			}
+
+			defer os.RemoveAll(filepath.Dir(buildDockerfilePath))
+		}
+
+		err = imageBuilder.BuildImage(ctx, contextPath, buildDockerfilePath, buildOptions)
 		if err != nil {
-			return false, fmt.Errorf("Error during image build: %v", err)
+			return false, fmt.Errorf("Error during image build: %v", addRateLimitHint(err))
 		}
 
 		if imageConf.SkipPush == nil || *imageConf.SkipPush == false {
-			err = imageBuilder.PushImage()
+			err = imageBuilder.PushImage(ctx)
 			if err != nil {
 				return false, fmt.Errorf("Error during image push: %v", err)
 			}
@@ -206,6 +267,12 @@ func Build(client *kubernetes.Clientset, generatedConfig *generated.Config, imag
 
 		generatedConfig.ImageTags[imageName] = imageTag
 
+		// The digest of the previous tag is outdated now
+		delete(generatedConfig.ImageDigests, imageName)
+		if dockerBuilder, ok := imageBuilder.(*docker.Builder); ok && dockerBuilder.PushedDigest != "" {
+			generatedConfig.ImageDigests[imageName] = dockerBuilder.PushedDigest
+		}
+
 		log.Done("Done building and pushing image '" + imageName + "'")
 
 	} else {
@@ -215,6 +282,29 @@ func Build(client *kubernetes.Clientset, generatedConfig *generated.Config, imag
 	return rebuild, nil
 }
 
+func validate(dockerfilePath, contextPath string, buildArgs map[string]*string, log log.Logger) error {
+	problems, err := ValidateDockerfile(dockerfilePath, contextPath, buildArgs)
+	if err != nil {
+		return fmt.Errorf("Error validating %s: %v", dockerfilePath, err)
+	}
+
+	errorCount := 0
+	for _, problem := range problems {
+		if problem.Warning {
+			log.Warnf("%s: %s", dockerfilePath, problem.String())
+		} else {
+			log.Errorf("%s: %s", dockerfilePath, problem.String())
+			errorCount++
+		}
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("Found %d problem(s) in %s", errorCount, dockerfilePath)
+	}
+
+	return nil
+}
+
 func shouldRebuild(runtimeConfig *generated.Config, imageConf *v1.ImageConfig, dockerfilePath string, forceRebuild bool) bool {
 	mustRebuild := true
 
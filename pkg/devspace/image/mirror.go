@@ -0,0 +1,158 @@
+package image
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	dockerclient "github.com/covexo/devspace/pkg/devspace/docker"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/docker/distribution/reference"
+)
+
+// registryMirrors maps the upstream registries (docker.io for docker hub) to their mirrors
+type registryMirrors map[string]string
+
+// fromRegex matches FROM instructions with optional flags (e.g. --platform) before the image
+var fromRegex = regexp.MustCompile(`(?i)^(\s*FROM\s+(?:--\S+\s+)*)(\S+)(.*)$`)
+
+// stageRegex matches the stage name after the image of a FROM instruction
+var stageRegex = regexp.MustCompile(`(?i)^\s+AS\s+(\S+)`)
+
+// getRegistryMirrors returns the mirrors of build.registryMirrors by upstream registry
+func getRegistryMirrors(imageConf *v1.ImageConfig) (registryMirrors, error) {
+	mirrors := registryMirrors{}
+	if imageConf.Build == nil || imageConf.Build.RegistryMirrors == nil {
+		return mirrors, nil
+	}
+
+	for index, mirrorConf := range *imageConf.Build.RegistryMirrors {
+		if mirrorConf == nil || mirrorConf.URL == nil || *mirrorConf.URL == "" {
+			return nil, fmt.Errorf("build.registryMirrors[%d].url is missing", index)
+		}
+
+		registry := ""
+		if mirrorConf.Registry != nil {
+			registry = *mirrorConf.Registry
+		}
+
+		registry = dockerclient.NormalizeRegistry(registry)
+		if _, ok := mirrors[registry]; ok {
+			return nil, fmt.Errorf("build.registryMirrors[%d]: registry %s has more than one mirror", index, registry)
+		}
+
+		mirrors[registry] = dockerclient.NormalizeMirror(*mirrorConf.URL)
+	}
+
+	return mirrors, nil
+}
+
+// otherRegistries returns the sorted upstream registries except docker hub
+func (m registryMirrors) otherRegistries() []string {
+	registries := []string{}
+	for registry := range m {
+		if registry != dockerclient.DockerHubRegistry {
+			registries = append(registries, registry)
+		}
+	}
+
+	sort.Strings(registries)
+	return registries
+}
+
+// rewriteBaseImages writes a copy of the Dockerfile that pulls the base images of all FROM instructions from the
+// mirrors and returns its path. The copy is named Dockerfile (kaniko expects this name) and lies in a temporary
+// directory that has to be removed by the caller
+func rewriteBaseImages(dockerfilePath string, mirrors registryMirrors, log log.Logger) (string, error) {
+	content, err := ioutil.ReadFile(dockerfilePath)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	stages := map[string]bool{}
+
+	for index, line := range lines {
+		rewritten, image, mirrorImage := rewriteFromInstruction(line, stages, mirrors)
+		if mirrorImage != "" {
+			log.Infof("Pulling base image %s from mirror: %s", image, mirrorImage)
+			lines[index] = rewritten
+		}
+	}
+
+	tempDir, err := ioutil.TempDir("", "devspace-dockerfile-")
+	if err != nil {
+		return "", err
+	}
+
+	rewrittenPath := filepath.Join(tempDir, "Dockerfile")
+	err = ioutil.WriteFile(rewrittenPath, []byte(strings.Join(lines, "\n")), 0644)
+	if err != nil {
+		os.RemoveAll(tempDir)
+		return "", err
+	}
+
+	return rewrittenPath, nil
+}
+
+// rewriteFromInstruction replaces the image of a FROM instruction with the image on the mirror of its registry. It
+// returns the new line, the original image and the mirror image (empty if the line wasn't changed). Earlier stages,
+// scratch and images with variables are never rewritten. The stage name of the instruction is added to stages
+func rewriteFromInstruction(line string, stages map[string]bool, mirrors registryMirrors) (string, string, string) {
+	match := fromRegex.FindStringSubmatch(line)
+	if match == nil {
+		return line, "", ""
+	}
+
+	prefix, image, suffix := match[1], match[2], match[3]
+
+	isStage := stages[strings.ToLower(image)]
+	if stage := stageRegex.FindStringSubmatch(suffix); stage != nil {
+		stages[strings.ToLower(stage[1])] = true
+	}
+	if isStage {
+		return line, image, ""
+	}
+
+	mirrorImage := getMirrorImage(image, mirrors)
+	if mirrorImage == "" {
+		return line, image, ""
+	}
+
+	return prefix + mirrorImage + suffix, image, mirrorImage
+}
+
+// getMirrorImage returns the image on the mirror of its registry or an empty string if there is no mirror
+func getMirrorImage(image string, mirrors registryMirrors) string {
+	if strings.ToLower(image) == "scratch" || strings.Contains(image, "$") {
+		return ""
+	}
+
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return ""
+	}
+
+	domain := reference.Domain(named)
+	mirror, ok := mirrors[dockerclient.NormalizeRegistry(domain)]
+	if ok == false {
+		return ""
+	}
+
+	// Images of docker hub keep the library/ prefix of official images, because mirrors expect the full path
+	return mirror + "/" + strings.TrimPrefix(named.String(), domain+"/")
+}
+
+// addRateLimitHint points to build.registryMirrors if the build failed because of the pull rate limit of a registry
+func addRateLimitHint(err error) error {
+	if err == nil || dockerclient.IsRateLimitError(err.Error()) == false {
+		return err
+	}
+
+	return fmt.Errorf("%v\n%s", err, dockerclient.RateLimitHint)
+}
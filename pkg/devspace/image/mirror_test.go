@@ -0,0 +1,78 @@
+package image
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+)
+
+func TestRewriteFromInstruction(t *testing.T) {
+	mirrors := registryMirrors{
+		"docker.io": "mirror.internal:5000",
+		"gcr.io":    "gcr-mirror.internal",
+	}
+
+	testCases := []struct {
+		line     string
+		expected string
+	}{
+		{"FROM alpine:3.9", "FROM mirror.internal:5000/library/alpine:3.9"},
+		{"from bitnami/redis AS cache", "from mirror.internal:5000/bitnami/redis AS cache"},
+		{"FROM --platform=linux/amd64 gcr.io/distroless/base", "FROM --platform=linux/amd64 gcr-mirror.internal/distroless/base"},
+		{"FROM quay.io/coreos/etcd:v3.3", "FROM quay.io/coreos/etcd:v3.3"},
+		{"FROM cache", "FROM cache"},
+		{"FROM scratch", "FROM scratch"},
+		{"FROM node:${NODE_VERSION}", "FROM node:${NODE_VERSION}"},
+		{"RUN echo FROM alpine", "RUN echo FROM alpine"},
+	}
+
+	stages := map[string]bool{}
+	for _, testCase := range testCases {
+		line, _, _ := rewriteFromInstruction(testCase.line, stages, mirrors)
+		if line != testCase.expected {
+			t.Fatalf("Expected %s to be rewritten to %s, got %s", testCase.line, testCase.expected, line)
+		}
+	}
+}
+
+func TestGetRegistryMirrors(t *testing.T) {
+	imageConf := &v1.ImageConfig{
+		Build: &v1.BuildConfig{
+			RegistryMirrors: &[]*v1.RegistryMirrorConfig{
+				{URL: configutil.String("https://mirror.internal:5000/")},
+				{URL: configutil.String("gcr-mirror.internal"), Registry: configutil.String("gcr.io")},
+			},
+		},
+	}
+
+	mirrors, err := getRegistryMirrors(imageConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mirrors["docker.io"] != "mirror.internal:5000" || mirrors["gcr.io"] != "gcr-mirror.internal" {
+		t.Fatalf("Unexpected mirrors %v", mirrors)
+	}
+
+	*imageConf.Build.RegistryMirrors = append(*imageConf.Build.RegistryMirrors, &v1.RegistryMirrorConfig{
+		URL:      configutil.String("other-mirror.internal"),
+		Registry: configutil.String("index.docker.io"),
+	})
+	_, err = getRegistryMirrors(imageConf)
+	if err == nil {
+		t.Fatal("Expected error for two mirrors of docker hub")
+	}
+}
+
+func TestAddRateLimitHint(t *testing.T) {
+	err := addRateLimitHint(errors.New("toomanyrequests: You have reached your pull rate limit"))
+	if err.Error() == "toomanyrequests: You have reached your pull rate limit" {
+		t.Fatal("Expected rate limit hint")
+	}
+
+	err = addRateLimitHint(errors.New("manifest unknown"))
+	if err.Error() != "manifest unknown" {
+		t.Fatalf("Unexpected hint for %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+package image
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DockerfileProblem is a problem that was found during the validation of a dockerfile
+type DockerfileProblem struct {
+	Line    int
+	Message string
+
+	// Warning is true if the problem might not break the build
+	Warning bool
+}
+
+func (p *DockerfileProblem) String() string {
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+var dockerfileInstructions = map[string]bool{
+	"ADD":         true,
+	"ARG":         true,
+	"CMD":         true,
+	"COPY":        true,
+	"ENTRYPOINT":  true,
+	"ENV":         true,
+	"EXPOSE":      true,
+	"FROM":        true,
+	"HEALTHCHECK": true,
+	"LABEL":       true,
+	"MAINTAINER":  true,
+	"ONBUILD":     true,
+	"RUN":         true,
+	"SHELL":       true,
+	"STOPSIGNAL":  true,
+	"USER":        true,
+	"VOLUME":      true,
+	"WORKDIR":     true,
+}
+
+// Instructions whose arguments are evaluated by the shell inside the container, so variables
+// might come from the base image
+var shellInstructions = map[string]bool{
+	"CMD":         true,
+	"ENTRYPOINT":  true,
+	"HEALTHCHECK": true,
+	"ONBUILD":     true,
+	"RUN":         true,
+	"SHELL":       true,
+}
+
+// Build args that are available without declaring them
+var predefinedArgs = map[string]bool{
+	"HTTP_PROXY":     true,
+	"http_proxy":     true,
+	"HTTPS_PROXY":    true,
+	"https_proxy":    true,
+	"FTP_PROXY":      true,
+	"ftp_proxy":      true,
+	"NO_PROXY":       true,
+	"no_proxy":       true,
+	"TARGETPLATFORM": true,
+	"TARGETOS":       true,
+	"TARGETARCH":     true,
+	"TARGETVARIANT":  true,
+	"BUILDPLATFORM":  true,
+	"BUILDOS":        true,
+	"BUILDARCH":      true,
+	"BUILDVARIANT":   true,
+}
+
+var variableRegEx = regexp.MustCompile(`\$(?:\{([a-zA-Z_][a-zA-Z0-9_]*)[^}]*\}|([a-zA-Z_][a-zA-Z0-9_]*))`)
+
+// ValidateDockerfile does a quick check of the dockerfile for obvious problems like unknown instructions,
+// references to undeclared build args or COPY / ADD sources that do not exist in the context
+func ValidateDockerfile(dockerfilePath, contextPath string, buildArgs map[string]*string) ([]*DockerfileProblem, error) {
+	file, err := os.Open(dockerfilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	problems := []*DockerfileProblem{}
+	declared := map[string]bool{}
+	foundFrom := false
+
+	for name := range buildArgs {
+		declared[name] = true
+	}
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	instructionLine := 0
+	instruction := ""
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip comments and empty lines (they are allowed within continued instructions as well)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if instruction == "" {
+			instructionLine = lineNumber
+		}
+
+		// Join continued lines
+		if strings.HasSuffix(line, "\\") {
+			instruction += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+
+		instruction += line
+
+		problems = append(problems, validateInstruction(instruction, instructionLine, contextPath, declared, &foundFrom)...)
+		instruction = ""
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if instruction != "" {
+		problems = append(problems, validateInstruction(instruction, instructionLine, contextPath, declared, &foundFrom)...)
+	}
+
+	if foundFrom == false {
+		problems = append(problems, &DockerfileProblem{
+			Line:    1,
+			Message: "No FROM instruction found",
+		})
+	}
+
+	return problems, nil
+}
+
+func validateInstruction(instruction string, line int, contextPath string, declared map[string]bool, foundFrom *bool) []*DockerfileProblem {
+	problems := []*DockerfileProblem{}
+	fields := strings.Fields(instruction)
+	if len(fields) == 0 {
+		// A continuation character without any instruction
+		return problems
+	}
+
+	command := strings.ToUpper(fields[0])
+	args := strings.TrimSpace(instruction[len(fields[0]):])
+
+	if dockerfileInstructions[command] == false {
+		return append(problems, &DockerfileProblem{
+			Line:    line,
+			Message: fmt.Sprintf("Unknown instruction %s", fields[0]),
+		})
+	}
+
+	if *foundFrom == false && command != "FROM" && command != "ARG" {
+		problems = append(problems, &DockerfileProblem{
+			Line:    line,
+			Message: fmt.Sprintf("Instruction %s before the first FROM", command),
+		})
+	}
+
+	if command == "FROM" {
+		*foundFrom = true
+		if args == "" {
+			problems = append(problems, &DockerfileProblem{
+				Line:    line,
+				Message: "FROM requires an image",
+			})
+		}
+	}
+
+	if shellInstructions[command] == false {
+		for _, match := range variableRegEx.FindAllStringSubmatch(args, -1) {
+			name := match[1]
+			if name == "" {
+				name = match[2]
+			}
+
+			if declared[name] == false && predefinedArgs[name] == false {
+				problems = append(problems, &DockerfileProblem{
+					Line:    line,
+					Message: fmt.Sprintf("Variable %s is not declared via ARG or ENV", name),
+					Warning: true,
+				})
+			}
+		}
+	}
+
+	switch command {
+	case "ARG":
+		if len(fields) > 1 {
+			declared[strings.SplitN(fields[1], "=", 2)[0]] = true
+		}
+	case "ENV":
+		declareEnv(fields[1:], declared)
+	case "COPY", "ADD":
+		problems = append(problems, validateSources(command, args, line, contextPath)...)
+	}
+
+	return problems
+}
+
+func declareEnv(fields []string, declared map[string]bool) {
+	if len(fields) == 0 {
+		return
+	}
+
+	// Legacy form: ENV key value
+	if strings.Contains(fields[0], "=") == false {
+		declared[fields[0]] = true
+		return
+	}
+
+	for _, field := range fields {
+		if strings.Contains(field, "=") {
+			declared[strings.SplitN(field, "=", 2)[0]] = true
+		}
+	}
+}
+
+func validateSources(command, args string, line int, contextPath string) []*DockerfileProblem {
+	problems := []*DockerfileProblem{}
+	paths := []string{}
+
+	// Strip flags like --chown or --from
+	for strings.HasPrefix(args, "--") {
+		flagEnd := strings.IndexAny(args, " \t")
+		if flagEnd == -1 {
+			return problems
+		}
+
+		// Sources of other stages or images cannot be checked
+		if strings.HasPrefix(args, "--from") {
+			return problems
+		}
+
+		args = strings.TrimSpace(args[flagEnd:])
+	}
+
+	if strings.HasPrefix(args, "[") {
+		err := json.Unmarshal([]byte(args), &paths)
+		if err != nil {
+			return append(problems, &DockerfileProblem{
+				Line:    line,
+				Message: fmt.Sprintf("Invalid JSON array in %s: %v", command, err),
+			})
+		}
+	} else {
+		paths = strings.Fields(args)
+	}
+
+	if len(paths) < 2 {
+		return append(problems, &DockerfileProblem{
+			Line:    line,
+			Message: fmt.Sprintf("%s requires at least a source and a destination", command),
+		})
+	}
+
+	for _, source := range paths[:len(paths)-1] {
+		// Remote sources and sources with variables cannot be checked
+		if strings.Contains(source, "://") || strings.Contains(source, "$") {
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(contextPath, source))
+		if err != nil || len(matches) == 0 {
+			problems = append(problems, &DockerfileProblem{
+				Line:    line,
+				Message: fmt.Sprintf("%s source %s does not exist in context %s", command, source, contextPath),
+			})
+		}
+	}
+
+	return problems
+}
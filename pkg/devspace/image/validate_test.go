@@ -0,0 +1,138 @@
+package image
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateDockerfile(t *testing.T) {
+	contextPath, err := ioutil.TempDir("", "devspace-context")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(contextPath)
+
+	for _, file := range []string{"package.json", "src/index.js", "src/util.js"} {
+		err = os.MkdirAll(filepath.Dir(filepath.Join(contextPath, file)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(contextPath, file), []byte(""), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		dockerfile string
+		buildArgs  map[string]*string
+
+		expectedProblems []string
+	}{
+		{
+			name:       "Valid dockerfile",
+			dockerfile: "FROM node:10\nWORKDIR /app\nCOPY package.json .\nRUN npm install\nCMD [\"npm\", \"start\"]",
+		},
+		{
+			name:             "Unknown instruction",
+			dockerfile:       "FROM node:10\nINSTALL npm",
+			expectedProblems: []string{"line 2: Unknown instruction INSTALL"},
+		},
+		{
+			name:             "Missing FROM",
+			dockerfile:       "WORKDIR /app",
+			expectedProblems: []string{"line 1: Instruction WORKDIR before the first FROM", "line 1: No FROM instruction found"},
+		},
+		{
+			name:       "ARG before FROM",
+			dockerfile: "ARG NODE_VERSION=10\nFROM node:${NODE_VERSION}",
+		},
+		{
+			name:             "Undeclared variable",
+			dockerfile:       "FROM node:10\nWORKDIR $APP_DIR",
+			expectedProblems: []string{"line 2: Variable APP_DIR is not declared via ARG or ENV"},
+		},
+		{
+			name:       "Variable declared by ENV",
+			dockerfile: "FROM node:10\nENV APP_DIR=/app\nWORKDIR ${APP_DIR}",
+		},
+		{
+			name:       "Variable declared by ARG",
+			dockerfile: "FROM node:10\nARG APP_DIR\nWORKDIR $APP_DIR",
+		},
+		{
+			name:       "Variable declared by a build arg",
+			dockerfile: "FROM node:10\nWORKDIR $APP_DIR",
+			buildArgs:  map[string]*string{"APP_DIR": nil},
+		},
+		{
+			name:       "Variable of the base image in RUN",
+			dockerfile: "FROM node:10\nRUN echo $NODE_VERSION",
+		},
+		{
+			name:       "COPY from another stage",
+			dockerfile: "FROM node:10 AS build\nFROM nginx\nCOPY --from=build /app/dist /usr/share/nginx/html",
+		},
+		{
+			name:       "COPY with chown",
+			dockerfile: "FROM node:10\nCOPY --chown=node:node package.json /app/",
+		},
+		{
+			name:       "COPY in JSON form",
+			dockerfile: "FROM node:10\nCOPY [\"package.json\", \"src/index.js\", \"/app/\"]",
+		},
+		{
+			name:             "COPY with invalid JSON",
+			dockerfile:       "FROM node:10\nCOPY [\"package.json\", \"/app/\"",
+			expectedProblems: []string{"line 2: Invalid JSON array in COPY: unexpected end of JSON input"},
+		},
+		{
+			name:       "COPY with glob",
+			dockerfile: "FROM node:10\nCOPY src/*.js /app/",
+		},
+		{
+			name:             "COPY with missing source",
+			dockerfile:       "FROM node:10\nCOPY yarn.lock /app/",
+			expectedProblems: []string{"line 2: COPY source yarn.lock does not exist in context " + contextPath},
+		},
+		{
+			name:             "COPY without destination",
+			dockerfile:       "FROM node:10\nCOPY package.json",
+			expectedProblems: []string{"line 2: COPY requires at least a source and a destination"},
+		},
+		{
+			name:       "Continued instruction",
+			dockerfile: "FROM node:10\nRUN apt-get update && \\\n    # comment\n    apt-get install -y git",
+		},
+		{
+			name:       "Trailing continuation character",
+			dockerfile: "FROM node:10\n\\",
+		},
+	}
+
+	for _, testCase := range testCases {
+		dockerfilePath := filepath.Join(contextPath, "Dockerfile")
+		err = ioutil.WriteFile(dockerfilePath, []byte(testCase.dockerfile), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		problems, err := ValidateDockerfile(dockerfilePath, contextPath, testCase.buildArgs)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", testCase.name, err)
+		}
+
+		if len(problems) != len(testCase.expectedProblems) {
+			t.Fatalf("%s: expected problems %v, got %v", testCase.name, testCase.expectedProblems, problems)
+		}
+		for i, problem := range problems {
+			if problem.String() != testCase.expectedProblems[i] {
+				t.Fatalf("%s: expected problem '%s', got '%s'", testCase.name, testCase.expectedProblems[i], problem.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package kubectl
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	k8sv1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// BootstrapConfigMapName is the name of the config map in the tiller namespace that marks the cluster as bootstrapped
+const BootstrapConfigMapName = "devspace-bootstrap"
+
+// IsBootstrapped returns true if the cluster was prepared with devspace bootstrap. In this case namespaces, tiller,
+// the internal registry and pull secrets are not created by devspace up and deploy
+func IsBootstrapped(client kubernetes.Interface) (bool, error) {
+	tillerNamespace, err := getTillerNamespace()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = client.CoreV1().ConfigMaps(tillerNamespace).Get(BootstrapConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("Unable to check config map %s/%s: %v", tillerNamespace, BootstrapConfigMapName, err)
+	}
+
+	return true, nil
+}
+
+// MarkBootstrapped creates or updates the bootstrap config map with the devspace version that bootstrapped the cluster
+func MarkBootstrapped(client kubernetes.Interface, version string) error {
+	tillerNamespace, err := getTillerNamespace()
+	if err != nil {
+		return err
+	}
+
+	configMaps := client.CoreV1().ConfigMaps(tillerNamespace)
+	configMap := &k8sv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: BootstrapConfigMapName,
+			Labels: map[string]string{
+				CreatedByLabel: CreatedByLabelValue,
+			},
+		},
+		Data: map[string]string{
+			"version":        version,
+			"bootstrappedAt": time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+
+	_, err = configMaps.Update(configMap)
+	if kerrors.IsNotFound(err) {
+		_, err = configMaps.Create(configMap)
+	}
+	if err != nil {
+		return fmt.Errorf("Error saving config map %s/%s: %v", tillerNamespace, BootstrapConfigMapName, err)
+	}
+
+	return nil
+}
+
+// NewNotBootstrappedError returns the error for a missing part of the cluster setup of a bootstrapped cluster
+func NewNotBootstrappedError(missing string, err error) error {
+	return fmt.Errorf("%s not found (%v). The cluster was prepared with `devspace bootstrap`, so devspace doesn't create it. Ask your cluster admin to run `devspace bootstrap` again", missing, err)
+}
+
+func getTillerNamespace() (string, error) {
+	config := configutil.GetConfig()
+	if config.Tiller == nil || config.Tiller.Namespace == nil {
+		return "", errors.New("No tiller namespace specified")
+	}
+
+	return *config.Tiller.Namespace, nil
+}
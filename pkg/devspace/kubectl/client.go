@@ -17,6 +17,7 @@ import (
 	"github.com/covexo/devspace/pkg/devspace/cloud"
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
 	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/devspace/kubectl/portforward"
 	"github.com/covexo/devspace/pkg/util/log"
 	k8sv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,7 +25,6 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
-	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/client-go/transport/spdy"
 	"k8s.io/kubernetes/pkg/api/legacyscheme"
@@ -40,7 +40,7 @@ var isMinikubeVar *bool
 var loadCloudConfigOnce sync.Once
 
 //NewClient creates a new kubernetes client
-func NewClient() (*kubernetes.Clientset, error) {
+func NewClient() (kubernetes.Interface, error) {
 	config, err := getClientConfig(false)
 	if err != nil {
 		return nil, err
@@ -50,7 +50,7 @@ func NewClient() (*kubernetes.Clientset, error) {
 }
 
 // NewClientWithContextSwitch creates a new kubernetes client and switches the kubectl context
-func NewClientWithContextSwitch(switchContext bool) (*kubernetes.Clientset, error) {
+func NewClientWithContextSwitch(switchContext bool) (kubernetes.Interface, error) {
 	config, err := getClientConfig(switchContext)
 	if err != nil {
 		return nil, err
@@ -199,8 +199,9 @@ func IsMinikube() bool {
 	return *isMinikubeVar
 }
 
-// GetNewestRunningPod retrieves the first pod that is found that has the status "Running" using the label selector string
-func GetNewestRunningPod(kubectl *kubernetes.Clientset, labelSelector, namespace string) (*k8sv1.Pod, error) {
+// GetNewestRunningPod retrieves the first pod that is found that has the status "Running" using the label selector string.
+// Pods that are annotated as protected are skipped, unless they are the only pods that match the selector
+func GetNewestRunningPod(kubectl kubernetes.Interface, labelSelector, namespace string) (*k8sv1.Pod, error) {
 	config := configutil.GetConfig()
 
 	if namespace == "" {
@@ -226,19 +227,22 @@ func GetNewestRunningPod(kubectl *kubernetes.Clientset, labelSelector, namespace
 		}
 
 		if podList.Size() > 0 && len(podList.Items) > 0 {
-			// Get Pod with latest creation timestamp
-			var selectedPod *k8sv1.Pod
-
-			for _, pod := range podList.Items {
-				if selectedPod == nil || pod.CreationTimestamp.Time.After(selectedPod.CreationTimestamp.Time) {
-					selectedPod = &pod
-				}
+			pods := make([]*k8sv1.Pod, len(podList.Items))
+			for index := range podList.Items {
+				pods[index] = &podList.Items[index]
 			}
 
+			// Get Pod with latest creation timestamp, protected pods are only selected if there is no other pod
+			selectedPod, skippedPods := SelectNewestPod(pods)
+
 			if selectedPod != nil {
 				podStatus := GetPodStatus(selectedPod)
 
 				if podStatus == "Running" {
+					for _, skippedPod := range skippedPods {
+						log.Warnf("Skipping protected pod %s/%s", skippedPod.Namespace, skippedPod.Name)
+					}
+
 					return selectedPod, nil
 				} else if podStatus == "Error" || podStatus == "ImagePullBackOff" || podStatus == "CrashLoopBackOff" || podStatus == "RunContainerError" || podStatus == "ErrImagePull" || podStatus == "CreateContainerConfigError" {
 					return nil, fmt.Errorf("Selected Pod(s) cannot start (Status: %s)", podStatus)
@@ -347,7 +351,7 @@ func DescribePod(namespace, name string) (string, error) {
 }
 
 // GetPodsFromDeployment retrieves all found pods from a deployment name
-func GetPodsFromDeployment(kubectl *kubernetes.Clientset, deployment, namespace string) (*k8sv1.PodList, error) {
+func GetPodsFromDeployment(kubectl kubernetes.Interface, deployment, namespace string) (*k8sv1.PodList, error) {
 	deploy, err := kubectl.ExtensionsV1beta1().Deployments(namespace).Get(deployment, metav1.GetOptions{})
 
 	// Deployment not there
@@ -376,8 +380,13 @@ func GetPodsFromDeployment(kubectl *kubernetes.Clientset, deployment, namespace
 	})
 }
 
-// ForwardPorts forwards the specified ports from the cluster to the local machine
-func ForwardPorts(kubectlClient *kubernetes.Clientset, pod *k8sv1.Pod, ports []string, stopChan chan struct{}, readyChan chan struct{}) error {
+// DefaultBindAddress is the local address port forwardings listen on by default. We only bind the ipv4 loopback,
+// because binding ::1 fails silently on some systems, which leads to refused connections if localhost resolves to ::1
+const DefaultBindAddress = "127.0.0.1"
+
+// ForwardPorts forwards the specified ports from the cluster to the local machine. The ports are bound on the given
+// addresses ("localhost" binds 127.0.0.1 and ::1) or on DefaultBindAddress if no address is given
+func ForwardPorts(kubectlClient kubernetes.Interface, pod *k8sv1.Pod, ports []string, addresses []string, stopChan chan struct{}, readyChan chan struct{}) error {
 	config, err := GetClientConfig()
 	if err != nil {
 		return err
@@ -396,7 +405,12 @@ func ForwardPorts(kubectlClient *kubernetes.Clientset, pod *k8sv1.Pod, ports []s
 
 	logFile := log.GetFileLogger("portforwarding")
 	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", execRequest.URL())
-	fw, err := portforward.New(dialer, ports, stopChan, readyChan, logFile, logFile)
+
+	if len(addresses) == 0 {
+		addresses = []string{DefaultBindAddress}
+	}
+
+	fw, err := portforward.NewOnAddresses(dialer, addresses, ports, stopChan, readyChan, logFile, logFile)
 
 	if err != nil {
 		return err
@@ -406,7 +420,7 @@ func ForwardPorts(kubectlClient *kubernetes.Clientset, pod *k8sv1.Pod, ports []s
 }
 
 //Exec executes a command for kubectl
-func Exec(kubectlClient *kubernetes.Clientset, pod *k8sv1.Pod, container string, command []string, tty bool, errorChannel chan<- error) (io.WriteCloser, io.ReadCloser, io.ReadCloser, error) {
+func Exec(kubectlClient kubernetes.Interface, pod *k8sv1.Pod, container string, command []string, tty bool, errorChannel chan<- error) (io.WriteCloser, io.ReadCloser, io.ReadCloser, error) {
 	var t term.TTY
 
 	kubeconfig, err := GetClientConfig()
@@ -443,7 +457,8 @@ func Exec(kubectlClient *kubernetes.Clientset, pod *k8sv1.Pod, container string,
 		var sizeQueue remotecommand.TerminalSizeQueue
 
 		if t.Raw {
-			// this call spawns a goroutine to monitor/update the terminal size
+			// this call spawns a goroutine that sends the initial terminal size and every size change (SIGWINCH) to the
+			// remote pty, otherwise the output is garbled after resizing the terminal
 			sizeQueue = t.MonitorSize(t.GetSize())
 		}
 
@@ -483,8 +498,44 @@ func Exec(kubectlClient *kubernetes.Clientset, pod *k8sv1.Pod, container string,
 	return stdinWriter, stdoutReader, stderrReader, nil
 }
 
+// ExecStream executes a command without a TTY and streams stdin, stdout and stderr of the command from and to the
+// given reader and writers until the command exits
+func ExecStream(kubectlClient kubernetes.Interface, pod *k8sv1.Pod, container string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	kubeconfig, err := GetClientConfig()
+	if err != nil {
+		return err
+	}
+
+	execRequest := kubectlClient.Core().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec")
+
+	execRequest.VersionedParams(&k8sapi.PodExecOptions{
+		Container: container,
+		Command:   command,
+		Stdin:     stdin != nil,
+		Stdout:    true,
+		Stderr:    true,
+		TTY:       false,
+	}, legacyscheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(kubeconfig, "POST", execRequest.URL())
+	if err != nil {
+		return err
+	}
+
+	return exec.Stream(remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+		Tty:    false,
+	})
+}
+
 //ExecBuffered executes a command for kubernetes and returns the output and error buffers
-func ExecBuffered(kubectlClient *kubernetes.Clientset, pod *k8sv1.Pod, container string, command []string) ([]byte, []byte, error) {
+func ExecBuffered(kubectlClient kubernetes.Interface, pod *k8sv1.Pod, container string, command []string) ([]byte, []byte, error) {
 	_, stdout, stderr, execErr := Exec(kubectlClient, pod, container, command, false, nil)
 
 	if execErr != nil {
@@ -0,0 +1,110 @@
+package kubectl
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/stdinutil"
+)
+
+// metadataServerEmailURL is the url of the GKE metadata server that returns the email of the service account the
+// workload runs as (e.g. with workload identity)
+var metadataServerEmailURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/email"
+
+// metadataServerTimeout is the time to wait for the metadata server, which doesn't exist outside of google cloud
+const metadataServerTimeout = 2 * time.Second
+
+// lookPath is used to check if the gcloud cli is installed (replaced in tests)
+var lookPath = exec.LookPath
+
+// getGoogleCloudUsername returns the google cloud account the cluster role binding is created for. The account is
+// determined by the following fallback chain:
+//
+// 1. The account gcloud is logged in with (if gcloud is on the PATH)
+// 2. The service account of the GKE metadata server (e.g. in CI with workload identity and without gcloud)
+// 3. The account the user enters
+func getGoogleCloudUsername(log log.Logger) (string, error) {
+	log.StartWait("Checking gcloud account")
+	username := detectGoogleCloudUsername()
+	log.StopWait()
+
+	if username != "" {
+		return username, nil
+	}
+
+	username = *stdinutil.GetFromStdin(&stdinutil.GetFromStdinParams{
+		Question:               "Couldn't determine your google cloud account with gcloud or the GKE metadata server. Which account should get cluster-admin permissions? (e.g. user@example.com)",
+		ValidationRegexPattern: "^([^@\\s]+@[^@\\s]+)?$",
+	})
+	if username == "" {
+		return "", errors.New("Couldn't determine google cloud username. Make sure you are logged in to gcloud")
+	}
+
+	return username, nil
+}
+
+// detectGoogleCloudUsername tries gcloud and the GKE metadata server and returns an empty string if both fail
+func detectGoogleCloudUsername() string {
+	_, err := lookPath("gcloud")
+	if err == nil {
+		gcloudOutput, err := exec.Command("gcloud", "config", "list", "account", "--format", "value(core.account)").Output()
+		if err == nil {
+			gcloudEmail := strings.TrimSpace(string(gcloudOutput))
+			if gcloudEmail != "" {
+				return gcloudEmail
+			}
+		}
+	}
+
+	email, err := getMetadataServerEmail(metadataServerEmailURL, metadataServerTimeout)
+	if err != nil {
+		return ""
+	}
+
+	return email
+}
+
+// getMetadataServerEmail requests the service account email from the google cloud metadata server
+func getMetadataServerEmail(url string, timeout time.Duration) (string, error) {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Metadata-Flavor", "Google")
+
+	client := &http.Client{
+		Timeout: timeout,
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Metadata server returned status %d", response.StatusCode)
+	}
+	if response.Header.Get("Metadata-Flavor") != "Google" {
+		return "", errors.New("Response is not from the google cloud metadata server")
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+
+	email := strings.TrimSpace(string(body))
+	if email == "" {
+		return "", errors.New("Metadata server returned no service account email")
+	}
+
+	return email, nil
+}
@@ -0,0 +1,82 @@
+package kubectl
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestMetadataServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		time.Sleep(delay)
+
+		w.Header().Set("Metadata-Flavor", "Google")
+		w.Write([]byte("ci@project.iam.gserviceaccount.com\n"))
+	}))
+}
+
+func TestGetMetadataServerEmail(t *testing.T) {
+	server := newTestMetadataServer(0)
+	defer server.Close()
+
+	email, err := getMetadataServerEmail(server.URL, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email != "ci@project.iam.gserviceaccount.com" {
+		t.Fatalf("Unexpected email %s", email)
+	}
+
+	// Servers that are not the metadata server are not trusted
+	otherServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("someone@example.com"))
+	}))
+	defer otherServer.Close()
+
+	_, err = getMetadataServerEmail(otherServer.URL, time.Second)
+	if err == nil {
+		t.Fatal("Expected error for response without Metadata-Flavor header")
+	}
+
+	slowServer := newTestMetadataServer(500 * time.Millisecond)
+	defer slowServer.Close()
+
+	_, err = getMetadataServerEmail(slowServer.URL, 50*time.Millisecond)
+	if err == nil {
+		t.Fatal("Expected timeout error")
+	}
+}
+
+func TestDetectGoogleCloudUsernameWithoutGCloud(t *testing.T) {
+	server := newTestMetadataServer(0)
+	defer server.Close()
+
+	oldLookPath, oldURL := lookPath, metadataServerEmailURL
+	defer func() {
+		lookPath, metadataServerEmailURL = oldLookPath, oldURL
+	}()
+
+	lookPath = func(file string) (string, error) {
+		return "", errors.New("not found")
+	}
+
+	metadataServerEmailURL = server.URL
+	username := detectGoogleCloudUsername()
+	if username != "ci@project.iam.gserviceaccount.com" {
+		t.Fatalf("Expected username of the metadata server, got %s", username)
+	}
+
+	// Without gcloud and metadata server the user is asked
+	server.Close()
+	username = detectGoogleCloudUsername()
+	if username != "" {
+		t.Fatalf("Expected empty username, got %s", username)
+	}
+}
@@ -0,0 +1,92 @@
+package kubectl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PodCache resolves every pod only once, so that all services (e.g. sync and port forwarding) that use the same
+// label selector target the same pod, even if the deployment has multiple replicas
+type PodCache struct {
+	client kubernetes.Interface
+	pods   map[string]*k8sv1.Pod
+	mutex  sync.Mutex
+}
+
+// NewPodCache creates a new pod cache
+func NewPodCache(client kubernetes.Interface) *PodCache {
+	return &PodCache{
+		client: client,
+		pods:   make(map[string]*k8sv1.Pod),
+	}
+}
+
+// GetPod returns the pod with the given name if podName is not empty, otherwise the newest running pod that
+// matches the label selector. The result is cached for later calls with the same arguments
+func (c *PodCache) GetPod(podName string, labelSelector map[string]*string, namespace string) (*k8sv1.Pod, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	selector := LabelSelectorToString(labelSelector)
+	key := namespace + "/" + podName + "/" + selector
+	if pod, ok := c.pods[key]; ok {
+		return pod, nil
+	}
+
+	var pod *k8sv1.Pod
+	var err error
+
+	if podName != "" {
+		pod, err = GetRunningPodByName(c.client, podName, namespace)
+	} else {
+		pod, err = GetNewestRunningPod(c.client, selector, namespace)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.pods[key] = pod
+	return pod, nil
+}
+
+// GetRunningPodByName returns the pod with the given name and fails if the pod does not exist or is not running
+func GetRunningPodByName(client kubernetes.Interface, podName, namespace string) (*k8sv1.Pod, error) {
+	if namespace == "" {
+		defaultNamespace, err := configutil.GetDefaultNamespace(configutil.GetConfig())
+		if err != nil {
+			return nil, err
+		}
+
+		namespace = defaultNamespace
+	}
+
+	pod, err := client.Core().Pods(namespace).Get(podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get pod %s in namespace %s: %v", podName, namespace, err)
+	}
+
+	podStatus := GetPodStatus(pod)
+	if podStatus != "Running" {
+		return nil, fmt.Errorf("Pod %s in namespace %s is not running (Status: %s)", podName, namespace, podStatus)
+	}
+
+	return pod, nil
+}
+
+// LabelSelectorToString converts the label selector map into a sorted selector string (e.g. app=test, release=test)
+func LabelSelectorToString(labelSelector map[string]*string) string {
+	labels := make([]string, 0, len(labelSelector))
+	for key, value := range labelSelector {
+		labels = append(labels, key+"="+*value)
+	}
+
+	sort.Strings(labels)
+	return strings.Join(labels, ", ")
+}
@@ -0,0 +1,388 @@
+// Package portforward is adapted from k8s.io/client-go/tools/portforward (kubernetes 1.13), because the vendored
+// client-go version always listens on both 127.0.0.1 and ::1 and cannot be told which addresses to use.
+// It can be replaced with portforward.NewOnAddresses after client-go is updated
+package portforward
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/runtime"
+)
+
+// PortForwardProtocolV1Name is the subprotocol used for port forwarding
+const PortForwardProtocolV1Name = "portforward.k8s.io"
+
+// PortForwarder knows how to listen for local connections and forward them to a remote pod via an upgraded
+// HTTP request
+type PortForwarder struct {
+	addresses []listenAddress
+	ports     []ForwardedPort
+	stopChan  <-chan struct{}
+
+	dialer        httpstream.Dialer
+	streamConn    httpstream.Connection
+	listeners     []io.Closer
+	Ready         chan struct{}
+	requestIDLock sync.Mutex
+	requestID     int
+	out           io.Writer
+	errOut        io.Writer
+}
+
+// ForwardedPort contains a local port and a remote port
+type ForwardedPort struct {
+	Local  uint16
+	Remote uint16
+}
+
+type listenAddress struct {
+	address  string
+	protocol string
+
+	// failureMode is "all" if the address is part of localhost (only fail if all localhost listeners fail) and
+	// "any" if the address was specified explicitly (fail if it cannot be bound)
+	failureMode string
+}
+
+// parsePorts parses the ports in the form "local:remote" (or just "port" if both are the same)
+func parsePorts(ports []string) ([]ForwardedPort, error) {
+	var forwards []ForwardedPort
+
+	for _, portString := range ports {
+		parts := strings.Split(portString, ":")
+
+		var localString, remoteString string
+		if len(parts) == 1 {
+			localString = parts[0]
+			remoteString = parts[0]
+		} else if len(parts) == 2 {
+			localString = parts[0]
+			if localString == "" {
+				// Support :5000
+				localString = "0"
+			}
+
+			remoteString = parts[1]
+		} else {
+			return nil, fmt.Errorf("Invalid port format '%s'", portString)
+		}
+
+		localPort, err := strconv.ParseUint(localString, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing local port '%s': %s", localString, err)
+		}
+
+		remotePort, err := strconv.ParseUint(remoteString, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing remote port '%s': %s", remoteString, err)
+		}
+		if remotePort == 0 {
+			return nil, fmt.Errorf("Remote port must be > 0")
+		}
+
+		forwards = append(forwards, ForwardedPort{uint16(localPort), uint16(remotePort)})
+	}
+
+	return forwards, nil
+}
+
+func parseAddresses(addressesToParse []string) ([]listenAddress, error) {
+	parsed := make(map[string]listenAddress)
+
+	for _, address := range addressesToParse {
+		if address == "localhost" {
+			if _, exists := parsed["127.0.0.1"]; !exists {
+				parsed["127.0.0.1"] = listenAddress{address: "127.0.0.1", protocol: "tcp4", failureMode: "all"}
+			}
+			if _, exists := parsed["::1"]; !exists {
+				parsed["::1"] = listenAddress{address: "::1", protocol: "tcp6", failureMode: "all"}
+			}
+		} else if ip := net.ParseIP(address); ip != nil && ip.To4() != nil {
+			parsed[address] = listenAddress{address: address, protocol: "tcp4", failureMode: "any"}
+		} else if ip != nil {
+			parsed[address] = listenAddress{address: address, protocol: "tcp6", failureMode: "any"}
+		} else {
+			return nil, fmt.Errorf("%s is not a valid IP", address)
+		}
+	}
+
+	addresses := make([]listenAddress, 0, len(parsed))
+	for _, address := range parsed {
+		addresses = append(addresses, address)
+	}
+
+	return addresses, nil
+}
+
+// NewOnAddresses creates a new PortForwarder that listens on the given addresses ("localhost" means 127.0.0.1 and ::1)
+func NewOnAddresses(dialer httpstream.Dialer, addresses []string, ports []string, stopChan <-chan struct{}, readyChan chan struct{}, out, errOut io.Writer) (*PortForwarder, error) {
+	if len(addresses) == 0 {
+		return nil, errors.New("You must specify at least 1 address")
+	}
+
+	parsedAddresses, err := parseAddresses(addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ports) == 0 {
+		return nil, errors.New("You must specify at least 1 port")
+	}
+
+	parsedPorts, err := parsePorts(ports)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PortForwarder{
+		dialer:    dialer,
+		addresses: parsedAddresses,
+		ports:     parsedPorts,
+		stopChan:  stopChan,
+		Ready:     readyChan,
+		out:       out,
+		errOut:    errOut,
+	}, nil
+}
+
+// ForwardPorts formats and executes a port forwarding request. The connection will remain open until stopChan
+// is closed
+func (pf *PortForwarder) ForwardPorts() error {
+	defer pf.Close()
+
+	var err error
+	pf.streamConn, _, err = pf.dialer.Dial(PortForwardProtocolV1Name)
+	if err != nil {
+		return fmt.Errorf("Error upgrading connection: %s", err)
+	}
+	defer pf.streamConn.Close()
+
+	return pf.forward()
+}
+
+// forward dials the remote host specific in req, upgrades the request, starts listeners for each port specified
+// in ports, and forwards local connections to the remote host via streams
+func (pf *PortForwarder) forward() error {
+	listenSuccess := false
+
+	for i := range pf.ports {
+		port := &pf.ports[i]
+
+		err := pf.listenOnPort(port)
+		if err == nil {
+			listenSuccess = true
+		} else if pf.errOut != nil {
+			fmt.Fprintf(pf.errOut, "Unable to listen on port %d: %v\n", port.Local, err)
+		}
+	}
+
+	if !listenSuccess {
+		return fmt.Errorf("Unable to listen on any of the requested ports: %v", pf.ports)
+	}
+
+	if pf.Ready != nil {
+		close(pf.Ready)
+	}
+
+	// Wait for interrupt or conn closure
+	select {
+	case <-pf.stopChan:
+	case <-pf.streamConn.CloseChan():
+		runtime.HandleError(errors.New("Lost connection to pod"))
+	}
+
+	return nil
+}
+
+// listenOnPort delegates listener creation and waits for connections on requested bind addresses
+func (pf *PortForwarder) listenOnPort(port *ForwardedPort) error {
+	var errs []error
+	failCounters := make(map[string]int, 2)
+	successCounters := make(map[string]int, 2)
+
+	for _, address := range pf.addresses {
+		err := pf.listenOnPortAndAddress(port, address.protocol, address.address)
+		if err != nil {
+			errs = append(errs, err)
+			failCounters[address.failureMode]++
+		} else {
+			successCounters[address.failureMode]++
+		}
+	}
+
+	if successCounters["all"] == 0 && failCounters["all"] > 0 {
+		return fmt.Errorf("Listeners failed to create with the following errors: %v", errs)
+	}
+	if failCounters["any"] > 0 {
+		return fmt.Errorf("Listeners failed to create with the following errors: %v", errs)
+	}
+
+	return nil
+}
+
+// listenOnPortAndAddress delegates listener creation and waits for new connections in the background
+func (pf *PortForwarder) listenOnPortAndAddress(port *ForwardedPort, protocol string, address string) error {
+	listener, err := pf.getListener(protocol, address, port)
+	if err != nil {
+		return err
+	}
+
+	pf.listeners = append(pf.listeners, listener)
+	go pf.waitForConnection(listener, *port)
+
+	return nil
+}
+
+// getListener creates a listener on the interface targeted by the given hostname on the given port with the
+// given protocol
+func (pf *PortForwarder) getListener(protocol string, hostname string, port *ForwardedPort) (net.Listener, error) {
+	listener, err := net.Listen(protocol, net.JoinHostPort(hostname, strconv.Itoa(int(port.Local))))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create listener: %v", err)
+	}
+
+	listenerAddress := listener.Addr().String()
+	host, localPort, _ := net.SplitHostPort(listenerAddress)
+	localPortUInt, err := strconv.ParseUint(localPort, 10, 16)
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("Error parsing local port: %v from %s (%s)", err, listenerAddress, host)
+	}
+
+	port.Local = uint16(localPortUInt)
+	if pf.out != nil {
+		fmt.Fprintf(pf.out, "Forwarding from %s -> %d\n", net.JoinHostPort(hostname, strconv.Itoa(int(localPortUInt))), port.Remote)
+	}
+
+	return listener, nil
+}
+
+// waitForConnection waits for new connections to listener and handles them in the background
+func (pf *PortForwarder) waitForConnection(listener net.Listener, port ForwardedPort) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !strings.Contains(strings.ToLower(err.Error()), "use of closed network connection") {
+				runtime.HandleError(fmt.Errorf("Error accepting connection on port %d: %v", port.Local, err))
+			}
+
+			return
+		}
+
+		go pf.handleConnection(conn, port)
+	}
+}
+
+func (pf *PortForwarder) nextRequestID() int {
+	pf.requestIDLock.Lock()
+	defer pf.requestIDLock.Unlock()
+
+	id := pf.requestID
+	pf.requestID++
+
+	return id
+}
+
+// handleConnection copies data between the local connection and the stream to the remote server
+func (pf *PortForwarder) handleConnection(conn net.Conn, port ForwardedPort) {
+	defer conn.Close()
+
+	if pf.out != nil {
+		fmt.Fprintf(pf.out, "Handling connection for %d\n", port.Local)
+	}
+
+	requestID := pf.nextRequestID()
+
+	// Create error stream
+	headers := http.Header{}
+	headers.Set(v1.StreamType, v1.StreamTypeError)
+	headers.Set(v1.PortHeader, fmt.Sprintf("%d", port.Remote))
+	headers.Set(v1.PortForwardRequestIDHeader, strconv.Itoa(requestID))
+
+	errorStream, err := pf.streamConn.CreateStream(headers)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("Error creating error stream for port %d -> %d: %v", port.Local, port.Remote, err))
+		return
+	}
+
+	// We're not writing to this stream
+	errorStream.Close()
+
+	errorChan := make(chan error)
+	go func() {
+		message, err := ioutil.ReadAll(errorStream)
+		switch {
+		case err != nil:
+			errorChan <- fmt.Errorf("Error reading from error stream for port %d -> %d: %v", port.Local, port.Remote, err)
+		case len(message) > 0:
+			errorChan <- fmt.Errorf("An error occurred forwarding %d -> %d: %v", port.Local, port.Remote, string(message))
+		}
+
+		close(errorChan)
+	}()
+
+	// Create data stream
+	headers.Set(v1.StreamType, v1.StreamTypeData)
+	dataStream, err := pf.streamConn.CreateStream(headers)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("Error creating forwarding stream for port %d -> %d: %v", port.Local, port.Remote, err))
+		return
+	}
+
+	localError := make(chan struct{})
+	remoteDone := make(chan struct{})
+
+	go func() {
+		// Copy from the remote side to the local port
+		if _, err := io.Copy(conn, dataStream); err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
+			runtime.HandleError(fmt.Errorf("Error copying from remote stream to local connection: %v", err))
+		}
+
+		// Inform the select below that the remote copy is done
+		close(remoteDone)
+	}()
+
+	go func() {
+		// Inform server we're not sending any more data after copy unblocks
+		defer dataStream.Close()
+
+		// Copy from the local port to the remote side
+		if _, err := io.Copy(dataStream, conn); err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
+			runtime.HandleError(fmt.Errorf("Error copying from local connection to remote stream: %v", err))
+
+			// Break out of the select below without waiting for the other copy to finish
+			close(localError)
+		}
+	}()
+
+	// Wait for either a local->remote error or for copying from remote->local to finish
+	select {
+	case <-remoteDone:
+	case <-localError:
+	}
+
+	// Always expect something on errorChan (it may be nil)
+	err = <-errorChan
+	if err != nil {
+		runtime.HandleError(err)
+	}
+}
+
+// Close stops all listeners of PortForwarder
+func (pf *PortForwarder) Close() {
+	for _, listener := range pf.listeners {
+		if err := listener.Close(); err != nil {
+			runtime.HandleError(fmt.Errorf("Error closing listener: %v", err))
+		}
+	}
+}
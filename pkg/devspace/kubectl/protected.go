@@ -0,0 +1,54 @@
+package kubectl
+
+import (
+	"fmt"
+
+	k8sv1 "k8s.io/api/core/v1"
+)
+
+// ProtectedAnnotation marks pods (e.g. shared databases) that devspace must not sync into or open a terminal in
+const ProtectedAnnotation = "devspace.covexo.com/protected"
+
+// IsProtectedPod checks if the pod is annotated as protected
+func IsProtectedPod(pod *k8sv1.Pod) bool {
+	return pod.Annotations[ProtectedAnnotation] == "true"
+}
+
+// CheckProtectedPod returns an error if the pod is protected and protected pods are not allowed
+func CheckProtectedPod(pod *k8sv1.Pod, allowProtected bool) error {
+	if allowProtected || IsProtectedPod(pod) == false {
+		return nil
+	}
+
+	return fmt.Errorf("Pod %s/%s is protected (annotation %s=true). Use --allow-protected to access it anyway", pod.Namespace, pod.Name, ProtectedAnnotation)
+}
+
+// SelectNewestPod returns the newest of the given pods and the protected pods that were skipped. Protected pods are
+// only selected if there is no other candidate
+func SelectNewestPod(pods []*k8sv1.Pod) (*k8sv1.Pod, []*k8sv1.Pod) {
+	var selectedPod *k8sv1.Pod
+	var newestProtectedPod *k8sv1.Pod
+	protectedPods := []*k8sv1.Pod{}
+
+	for _, pod := range pods {
+		if IsProtectedPod(pod) {
+			protectedPods = append(protectedPods, pod)
+
+			if newestProtectedPod == nil || pod.CreationTimestamp.Time.After(newestProtectedPod.CreationTimestamp.Time) {
+				newestProtectedPod = pod
+			}
+
+			continue
+		}
+
+		if selectedPod == nil || pod.CreationTimestamp.Time.After(selectedPod.CreationTimestamp.Time) {
+			selectedPod = pod
+		}
+	}
+
+	if selectedPod == nil {
+		return newestProtectedPod, []*k8sv1.Pod{}
+	}
+
+	return selectedPod, protectedPods
+}
@@ -0,0 +1,94 @@
+package kubectl
+
+import (
+	"testing"
+	"time"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestPod(name string, age time.Duration, protected bool) *k8sv1.Pod {
+	pod := &k8sv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "test",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		},
+	}
+
+	if protected {
+		pod.Annotations = map[string]string{
+			ProtectedAnnotation: "true",
+		}
+	}
+
+	return pod
+}
+
+func TestSelectNewestPodSkipsProtected(t *testing.T) {
+	protectedPod := newTestPod("database", time.Minute, true)
+	olderPod := newTestPod("app-old", time.Hour, false)
+	newerPod := newTestPod("app-new", 2*time.Minute, false)
+
+	selectedPod, skippedPods := SelectNewestPod([]*k8sv1.Pod{olderPod, protectedPod, newerPod})
+	if selectedPod != newerPod {
+		t.Fatalf("Expected pod %s to be selected, got %v", newerPod.Name, selectedPod)
+	}
+	if len(skippedPods) != 1 || skippedPods[0] != protectedPod {
+		t.Fatalf("Expected protected pod %s to be skipped, got %v", protectedPod.Name, skippedPods)
+	}
+}
+
+func TestSelectNewestPodOnlyProtected(t *testing.T) {
+	olderPod := newTestPod("database-0", time.Hour, true)
+	newerPod := newTestPod("database-1", time.Minute, true)
+
+	selectedPod, skippedPods := SelectNewestPod([]*k8sv1.Pod{olderPod, newerPod})
+	if selectedPod != newerPod {
+		t.Fatalf("Expected pod %s to be selected, got %v", newerPod.Name, selectedPod)
+	}
+	if len(skippedPods) != 0 {
+		t.Fatalf("Expected no skipped pods, got %v", skippedPods)
+	}
+}
+
+func TestSelectNewestPodEmpty(t *testing.T) {
+	selectedPod, _ := SelectNewestPod([]*k8sv1.Pod{})
+	if selectedPod != nil {
+		t.Fatalf("Expected no pod to be selected, got %v", selectedPod)
+	}
+}
+
+func TestCheckProtectedPod(t *testing.T) {
+	testCases := []struct {
+		protected      bool
+		allowProtected bool
+		expectError    bool
+	}{
+		{protected: false, allowProtected: false, expectError: false},
+		{protected: false, allowProtected: true, expectError: false},
+		{protected: true, allowProtected: false, expectError: true},
+		{protected: true, allowProtected: true, expectError: false},
+	}
+
+	for _, testCase := range testCases {
+		pod := newTestPod("test", time.Minute, testCase.protected)
+
+		err := CheckProtectedPod(pod, testCase.allowProtected)
+		if (err != nil) != testCase.expectError {
+			t.Errorf("Protected: %v, allow protected: %v: expected error %v, got %v", testCase.protected, testCase.allowProtected, testCase.expectError, err)
+		}
+	}
+}
+
+func TestIsProtectedPod(t *testing.T) {
+	pod := newTestPod("test", time.Minute, false)
+	pod.Annotations = map[string]string{
+		ProtectedAnnotation: "false",
+	}
+
+	if IsProtectedPod(pod) {
+		t.Fatal("Pod with annotation value false shouldn't be protected")
+	}
+}
@@ -0,0 +1,119 @@
+package kubectl
+
+import (
+	"fmt"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetPodForService returns the service and a running pod that is a ready endpoint of the service
+func GetPodForService(client kubernetes.Interface, serviceName, namespace string) (*k8sv1.Service, *k8sv1.Pod, error) {
+	namespace, err := getNamespace(namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	service, err := client.CoreV1().Services(namespace).Get(serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to get service %s in namespace %s: %v", serviceName, namespace, err)
+	}
+	if len(service.Spec.Selector) == 0 {
+		return nil, nil, fmt.Errorf("Service %s in namespace %s has no selector, therefore it cannot be forwarded to a pod", serviceName, namespace)
+	}
+
+	endpoints, err := client.CoreV1().Endpoints(namespace).Get(serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to get endpoints of service %s in namespace %s: %v", serviceName, namespace, err)
+	}
+
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if address.TargetRef == nil || address.TargetRef.Kind != "Pod" {
+				continue
+			}
+
+			pod, err := client.CoreV1().Pods(namespace).Get(address.TargetRef.Name, metav1.GetOptions{})
+			if err != nil {
+				continue
+			}
+
+			if GetPodStatus(pod) == "Running" {
+				return service, pod, nil
+			}
+		}
+	}
+
+	return nil, nil, fmt.Errorf("Service %s in namespace %s has no ready endpoints", serviceName, namespace)
+}
+
+// GetPodForDeployment returns the newest running pod of the deployment
+func GetPodForDeployment(client kubernetes.Interface, deploymentName, namespace string) (*k8sv1.Pod, error) {
+	namespace, err := getNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := client.ExtensionsV1beta1().Deployments(namespace).Get(deploymentName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get deployment %s in namespace %s: %v", deploymentName, namespace, err)
+	}
+	if len(deployment.Spec.Template.Labels) == 0 {
+		return nil, fmt.Errorf("Pod template of deployment %s in namespace %s has no labels", deploymentName, namespace)
+	}
+
+	labelSelector := make(map[string]*string)
+	for key, value := range deployment.Spec.Template.Labels {
+		labelValue := value
+		labelSelector[key] = &labelValue
+	}
+
+	pod, err := GetNewestRunningPod(client, LabelSelectorToString(labelSelector), namespace)
+	if err != nil {
+		return nil, fmt.Errorf("Deployment %s in namespace %s has no running pods: %v", deploymentName, namespace, err)
+	}
+
+	return pod, nil
+}
+
+// GetServiceTargetPort translates a port of the service into the port of the pod the traffic is sent to
+func GetServiceTargetPort(service *k8sv1.Service, pod *k8sv1.Pod, port int) (int, error) {
+	for _, servicePort := range service.Spec.Ports {
+		if int(servicePort.Port) != port {
+			continue
+		}
+
+		// An unset target port is the same as the service port
+		if servicePort.TargetPort.Type == intstr.Int {
+			if servicePort.TargetPort.IntVal == 0 {
+				return port, nil
+			}
+
+			return int(servicePort.TargetPort.IntVal), nil
+		}
+
+		// Named target ports are resolved with the container ports of the pod
+		for _, container := range pod.Spec.Containers {
+			for _, containerPort := range container.Ports {
+				if containerPort.Name == servicePort.TargetPort.StrVal {
+					return int(containerPort.ContainerPort), nil
+				}
+			}
+		}
+
+		return 0, fmt.Errorf("Pod %s has no container port named %s (target port of service port %d)", pod.Name, servicePort.TargetPort.StrVal, port)
+	}
+
+	return 0, fmt.Errorf("Service %s has no port %d", service.Name, port)
+}
+
+func getNamespace(namespace string) (string, error) {
+	if namespace != "" {
+		return namespace, nil
+	}
+
+	return configutil.GetDefaultNamespace(configutil.GetConfig())
+}
@@ -1,15 +1,13 @@
 package kubectl
 
 import (
-	"errors"
 	"fmt"
-	"os/exec"
-	"strings"
 
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
 	"github.com/covexo/devspace/pkg/util/log"
 	"k8s.io/api/core/v1"
 	"k8s.io/api/rbac/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -17,8 +15,26 @@ import (
 // ClusterRoleBindingName is the name of the cluster role binding that ensures that the user has enough rights
 const ClusterRoleBindingName = "devspace-users"
 
+// CreatedByLabel is the label that marks namespaces that were created by devspace
+const CreatedByLabel = "created-by"
+
+// CreatedByLabelValue is the value of the created by label
+const CreatedByLabelValue = "devspace"
+
+// NewNamespace returns a namespace that is labeled as created by devspace
+func NewNamespace(name string) *v1.Namespace {
+	return &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				CreatedByLabel: CreatedByLabelValue,
+			},
+		},
+	}
+}
+
 // EnsureDefaultNamespace makes sure the default namespace exists or will be created
-func EnsureDefaultNamespace(client *kubernetes.Clientset, log log.Logger) error {
+func EnsureDefaultNamespace(client kubernetes.Interface, log log.Logger) error {
 	config := configutil.GetConfig()
 	defaultNamespace, err := configutil.GetDefaultNamespace(config)
 	if err != nil {
@@ -26,24 +42,49 @@ func EnsureDefaultNamespace(client *kubernetes.Clientset, log log.Logger) error
 	}
 
 	if defaultNamespace != "default" {
-		_, err = client.CoreV1().Namespaces().Get(defaultNamespace, metav1.GetOptions{})
-		if err != nil {
-			log.Donef("Create namespace %s", defaultNamespace)
+		return EnsureNamespace(client, defaultNamespace, log)
+	}
 
-			// Create release namespace
-			_, err = client.CoreV1().Namespaces().Create(&v1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: defaultNamespace,
-				},
-			})
+	return nil
+}
+
+// CreateNamespaces returns false if devSpace.release.createNamespace disables the creation of missing namespaces
+func CreateNamespaces() bool {
+	config := configutil.GetConfig()
+	if config.DevSpace == nil || config.DevSpace.Release == nil || config.DevSpace.Release.CreateNamespace == nil {
+		return true
+	}
+
+	return *config.DevSpace.Release.CreateNamespace
+}
+
+// EnsureNamespace creates the namespace if it doesn't exist. If devSpace.release.createNamespace is false, it fails
+// instead. Users of externally managed namespaces often can't get namespaces, so in this case a forbidden error
+// is ignored
+func EnsureNamespace(client kubernetes.Interface, namespace string, log log.Logger) error {
+	_, err := client.CoreV1().Namespaces().Get(namespace, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+
+	if CreateNamespaces() == false {
+		if kerrors.IsNotFound(err) {
+			return fmt.Errorf("Namespace %s doesn't exist and devSpace.release.createNamespace is false. Please create the namespace or set createNamespace to true", namespace)
+		} else if kerrors.IsForbidden(err) {
+			return nil
 		}
+
+		return fmt.Errorf("Error getting namespace %s: %v", namespace, err)
 	}
 
+	log.Donef("Create namespace %s", namespace)
+
+	_, err = client.CoreV1().Namespaces().Create(NewNamespace(namespace))
 	return err
 }
 
 // EnsureGoogleCloudClusterRoleBinding makes sure the needed cluster role is created in the google cloud or a warning is printed
-func EnsureGoogleCloudClusterRoleBinding(client *kubernetes.Clientset, log log.Logger) error {
+func EnsureGoogleCloudClusterRoleBinding(client kubernetes.Interface, log log.Logger) error {
 	if IsMinikube() {
 		return nil
 	}
@@ -52,22 +93,9 @@ func EnsureGoogleCloudClusterRoleBinding(client *kubernetes.Clientset, log log.L
 	if err != nil {
 		clusterConfig, _ := GetClientConfig()
 		if clusterConfig.AuthProvider != nil && clusterConfig.AuthProvider.Name == "gcp" {
-			username := configutil.String("")
-
-			log.StartWait("Checking gcloud account")
-			gcloudOutput, gcloudErr := exec.Command("gcloud", "config", "list", "account", "--format", "value(core.account)").Output()
-			log.StopWait()
-
-			if gcloudErr == nil {
-				gcloudEmail := strings.TrimSuffix(strings.TrimSuffix(string(gcloudOutput), "\r\n"), "\n")
-
-				if gcloudEmail != "" {
-					username = &gcloudEmail
-				}
-			}
-
-			if *username == "" {
-				return errors.New("Couldn't determine google cloud username. Make sure you are logged in to gcloud")
+			username, err := getGoogleCloudUsername(log)
+			if err != nil {
+				return err
 			}
 
 			rolebinding := &v1beta1.ClusterRoleBinding{
@@ -77,7 +105,7 @@ func EnsureGoogleCloudClusterRoleBinding(client *kubernetes.Clientset, log log.L
 				Subjects: []v1beta1.Subject{
 					{
 						Kind: "User",
-						Name: *username,
+						Name: username,
 					},
 				},
 				RoleRef: v1beta1.RoleRef{
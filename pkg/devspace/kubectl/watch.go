@@ -0,0 +1,55 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WatchPod watches the pod with the given name and sends every new state of the pod to the returned channel. The
+// channel is closed as soon as the pod is deleted, the watch ends or the context is cancelled
+func WatchPod(ctx context.Context, client kubernetes.Interface, namespace, name string) (<-chan *k8sv1.Pod, error) {
+	watcher, err := client.CoreV1().Pods(namespace).Watch(metav1.ListOptions{
+		FieldSelector: "metadata.name=" + name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to watch pod %s/%s: %v", namespace, name, err)
+	}
+
+	podChan := make(chan *k8sv1.Pod)
+	go forwardPodEvents(ctx, watcher, podChan)
+
+	return podChan, nil
+}
+
+// forwardPodEvents sends the pods of the watch events to podChan and closes podChan and the watcher afterwards
+func forwardPodEvents(ctx context.Context, watcher watch.Interface, podChan chan<- *k8sv1.Pod) {
+	defer close(podChan)
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.ResultChan():
+			if ok == false || event.Type == watch.Error || event.Type == watch.Deleted {
+				return
+			}
+
+			pod, ok := event.Object.(*k8sv1.Pod)
+			if ok == false {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case podChan <- pod:
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package kubectl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func TestForwardPodEvents(t *testing.T) {
+	watcher := watch.NewFakeWithChanSize(3, false)
+	podChan := make(chan *k8sv1.Pod)
+
+	watcher.Modify(&k8sv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", ResourceVersion: "1"}})
+	watcher.Delete(&k8sv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", ResourceVersion: "2"}})
+
+	go forwardPodEvents(context.Background(), watcher, podChan)
+
+	select {
+	case pod := <-podChan:
+		if pod == nil || pod.ResourceVersion != "1" {
+			t.Fatalf("Expected the modified pod, got %v", pod)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Modified pod wasn't forwarded")
+	}
+
+	select {
+	case pod, ok := <-podChan:
+		if ok {
+			t.Fatalf("Expected the pod channel to be closed after the pod was deleted, got %v", pod)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Pod channel wasn't closed after the pod was deleted")
+	}
+}
+
+func TestForwardPodEventsCancelled(t *testing.T) {
+	watcher := watch.NewFake()
+	podChan := make(chan *k8sv1.Pod)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go forwardPodEvents(ctx, watcher, podChan)
+
+	cancel()
+
+	select {
+	case _, ok := <-podChan:
+		if ok {
+			t.Fatal("Expected no pod after the context was cancelled")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Pod channel wasn't closed after the context was cancelled")
+	}
+}
@@ -0,0 +1,202 @@
+package preview
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+)
+
+// BranchVariable is replaced with the slug of the branch in the namespace and the values of devspace.preview
+const BranchVariable = "${branch}"
+
+// DefaultNamespace is the namespace template of previews if devspace.preview.namespace is not set
+const DefaultNamespace = "preview-" + BranchVariable
+
+// maxSlugLength leaves enough room for the deployment name in release names
+const maxSlugLength = 30
+
+// maxReleaseNameLength is the maximum length of helm release names
+const maxReleaseNameLength = 53
+
+// maxNamespaceLength is the maximum length of kubernetes namespaces
+const maxNamespaceLength = 63
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+var namespaceRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// Preview is a preview environment of a git branch
+type Preview struct {
+	Branch     string   `json:"branch"`
+	Slug       string   `json:"slug"`
+	Namespace  string   `json:"namespace"`
+	Releases   []string `json:"releases"`
+	DeployedAt string   `json:"deployedAt"`
+	ExpiresAt  string   `json:"expiresAt,omitempty"`
+}
+
+// IsExpired checks if the ttl of the preview has elapsed. Previews without ttl never expire
+func (p *Preview) IsExpired(now time.Time) bool {
+	if p.ExpiresAt == "" {
+		return false
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, p.ExpiresAt)
+	return err == nil && now.After(expiresAt)
+}
+
+// GetCurrentBranch returns the git branch of the working directory
+func GetCurrentBranch() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("Unable to get the git branch: %v", err)
+	}
+
+	branch := strings.TrimSpace(string(out))
+	if branch == "HEAD" {
+		return "", fmt.Errorf("The git HEAD is detached, please specify the branch of the preview")
+	}
+
+	return branch, nil
+}
+
+// GetSlug sanitizes the branch name to a lowercase dns label, e.g. feature/Login-Form becomes feature-login-form.
+// Different branches can have the same slug
+func GetSlug(branch string) string {
+	slug := strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(branch), "-"), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+
+	return slug
+}
+
+// New returns the preview of the branch for the deployments of the config
+func New(config *v1.Config, branch string, now time.Time) (*Preview, error) {
+	slug := GetSlug(branch)
+	if slug == "" {
+		return nil, fmt.Errorf("Branch %s doesn't contain any letters or digits", branch)
+	}
+
+	previewConfig := config.DevSpace.Preview
+	if previewConfig == nil {
+		previewConfig = &v1.PreviewConfig{}
+	}
+
+	namespaceTemplate := DefaultNamespace
+	if previewConfig.Namespace != nil && *previewConfig.Namespace != "" {
+		namespaceTemplate = *previewConfig.Namespace
+	}
+	if strings.Contains(namespaceTemplate, BranchVariable) == false {
+		return nil, fmt.Errorf("devspace.preview.namespace has to contain %s, so that every branch gets its own namespace", BranchVariable)
+	}
+
+	preview := &Preview{
+		Branch:     branch,
+		Slug:       slug,
+		Namespace:  strings.Replace(namespaceTemplate, BranchVariable, slug, -1),
+		Releases:   []string{},
+		DeployedAt: now.UTC().Format(time.RFC3339),
+	}
+	if len(preview.Namespace) > maxNamespaceLength || namespaceRegex.MatchString(preview.Namespace) == false {
+		return nil, fmt.Errorf("Preview namespace %s is not a valid namespace name", preview.Namespace)
+	}
+
+	if previewConfig.TTL != nil && *previewConfig.TTL != "" {
+		ttl, err := time.ParseDuration(*previewConfig.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing devspace.preview.ttl: %v", err)
+		}
+
+		preview.ExpiresAt = now.Add(ttl).UTC().Format(time.RFC3339)
+	}
+
+	if config.DevSpace.Deployments != nil {
+		for _, deployConfig := range *config.DevSpace.Deployments {
+			preview.Releases = append(preview.Releases, getReleaseName(*deployConfig.Name, slug))
+		}
+	}
+
+	return preview, nil
+}
+
+// getReleaseName appends the slug to the deployment name
+func getReleaseName(name, slug string) string {
+	maxNameLength := maxReleaseNameLength - len(slug) - 1
+	if len(name) > maxNameLength {
+		name = strings.TrimRight(name[:maxNameLength], "-")
+	}
+
+	return name + "-" + slug
+}
+
+// Apply changes the namespace and the deployment names of the config to the preview and returns the values of
+// devspace.preview.values with dotted keys
+func Apply(config *v1.Config, preview *Preview) map[string]string {
+	if config.Cluster == nil {
+		config.Cluster = &v1.Cluster{}
+	}
+	config.Cluster.Namespace = &preview.Namespace
+
+	if config.DevSpace.Deployments != nil {
+		for index, deployConfig := range *config.DevSpace.Deployments {
+			releaseName := preview.Releases[index]
+			namespace := preview.Namespace
+
+			deployConfig.Name = &releaseName
+			deployConfig.Namespace = &namespace
+		}
+	}
+
+	values := map[string]string{}
+	if config.DevSpace.Preview != nil && config.DevSpace.Preview.Values != nil {
+		for key, value := range *config.DevSpace.Preview.Values {
+			if value != nil {
+				values[key] = strings.Replace(*value, BranchVariable, preview.Slug, -1)
+			}
+		}
+	}
+
+	return values
+}
+
+// Find returns the preview of the branch. Previews can also be found by their slug
+func Find(previews map[string]*Preview, branch string) *Preview {
+	for _, preview := range previews {
+		if preview.Branch == branch {
+			return preview
+		}
+	}
+
+	return previews[branch]
+}
+
+// GetExpired returns the previews whose ttl has elapsed sorted by slug
+func GetExpired(previews map[string]*Preview, now time.Time) []*Preview {
+	expired := []*Preview{}
+	for _, preview := range previews {
+		if preview.IsExpired(now) {
+			expired = append(expired, preview)
+		}
+	}
+
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].Slug < expired[j].Slug
+	})
+
+	return expired
+}
+
+// checkCollision returns an error if the slug of the preview is already used by a different branch
+func checkCollision(previews map[string]*Preview, preview *Preview) error {
+	existing, ok := previews[preview.Slug]
+	if ok && existing.Branch != preview.Branch {
+		return fmt.Errorf("Branch %s has the same preview name %s as branch %s. Purge the preview of %s with `devspace purge --preview %s` or rename the branch", preview.Branch, preview.Slug, existing.Branch, existing.Branch, existing.Branch)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package preview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+)
+
+func TestGetSlug(t *testing.T) {
+	slugs := map[string]string{
+		"master":                            "master",
+		"feature/Login-Form":                "feature-login-form",
+		"fix/#123__crash":                   "fix-123-crash",
+		"-release/v1.2-":                    "release-v1-2",
+		"feature/a-very-long-branch-name-x": "feature-a-very-long-branch-nam",
+		"feature/a-very-long-branch-nam-y":  "feature-a-very-long-branch-nam",
+	}
+
+	for branch, expected := range slugs {
+		slug := GetSlug(branch)
+		if slug != expected {
+			t.Fatalf("Expected slug %s for branch %s, got %s", expected, branch, slug)
+		}
+	}
+}
+
+func getPreviewConfig() *v1.Config {
+	return &v1.Config{
+		DevSpace: &v1.DevSpaceConfig{
+			Deployments: &[]*v1.DeploymentConfig{
+				{Name: configutil.String("devspace-default"), Namespace: configutil.String("dev")},
+			},
+			Preview: &v1.PreviewConfig{
+				Values: &map[string]*string{
+					"ingress.host": configutil.String("${branch}.preview.example.com"),
+				},
+				TTL: configutil.String("72h"),
+			},
+		},
+	}
+}
+
+func TestNewAndApply(t *testing.T) {
+	config := getPreviewConfig()
+	now := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	preview, err := New(config, "feature/Login", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Namespace != "preview-feature-login" || len(preview.Releases) != 1 || preview.Releases[0] != "devspace-default-feature-login" {
+		t.Fatalf("Unexpected preview %v", preview)
+	}
+	if preview.ExpiresAt != "2019-01-04T12:00:00Z" || preview.IsExpired(now) || preview.IsExpired(now.Add(73*time.Hour)) == false {
+		t.Fatalf("Expected the preview to expire after 72h, got %s", preview.ExpiresAt)
+	}
+
+	values := Apply(config, preview)
+	if values["ingress.host"] != "feature-login.preview.example.com" {
+		t.Fatalf("Unexpected preview values %v", values)
+	}
+
+	deployConfig := (*config.DevSpace.Deployments)[0]
+	if *config.Cluster.Namespace != preview.Namespace || *deployConfig.Namespace != preview.Namespace || *deployConfig.Name != preview.Releases[0] {
+		t.Fatalf("Preview wasn't applied to the config")
+	}
+
+	config = getPreviewConfig()
+	config.DevSpace.Preview.Namespace = configutil.String("preview")
+	_, err = New(config, "feature/login", now)
+	if err == nil {
+		t.Fatal("Expected an error for a namespace without the branch")
+	}
+}
+
+func TestCheckCollision(t *testing.T) {
+	previews := map[string]*Preview{
+		"feature-login": {Branch: "feature/login", Slug: "feature-login"},
+	}
+
+	err := checkCollision(previews, &Preview{Branch: "feature/login", Slug: "feature-login"})
+	if err != nil {
+		t.Fatalf("Expected no collision for the same branch, got %v", err)
+	}
+
+	err = checkCollision(previews, &Preview{Branch: "feature-login", Slug: "feature-login"})
+	if err == nil {
+		t.Fatal("Expected a collision for a different branch with the same slug")
+	}
+}
@@ -0,0 +1,43 @@
+package preview
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/covexo/devspace/pkg/devspace/helm"
+	"github.com/covexo/devspace/pkg/util/log"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Purge deletes the releases and the namespace of the preview and removes it from the records in the config map in
+// tillerNamespace. The namespace is kept if it is the tiller namespace
+func Purge(client kubernetes.Interface, tillerNamespace string, preview *Preview, log log.Logger) error {
+	if len(preview.Releases) > 0 {
+		helmClient, err := helm.NewClient(client, log, false)
+		if err != nil {
+			return fmt.Errorf("Error initializing helm client: %v", err)
+		}
+
+		for _, release := range preview.Releases {
+			_, err = helmClient.DeleteRelease(release, true)
+			if err != nil && strings.Contains(err.Error(), "not found") == false {
+				return fmt.Errorf("Unable to delete release %s: %v", release, err)
+			}
+
+			log.Donef("Deleted release %s", release)
+		}
+	}
+
+	if preview.Namespace != tillerNamespace {
+		err := client.CoreV1().Namespaces().Delete(preview.Namespace, &metav1.DeleteOptions{})
+		if err != nil && kerrors.IsNotFound(err) == false {
+			return fmt.Errorf("Unable to delete namespace %s: %v", preview.Namespace, err)
+		}
+
+		log.Donef("Deleted namespace %s", preview.Namespace)
+	}
+
+	return Unregister(client, tillerNamespace, preview.Slug)
+}
@@ -0,0 +1,125 @@
+package preview
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	k8sv1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ConfigMapName is the name of the config map in the tiller namespace that records the active previews, so that
+// previews can be listed and purged from any machine
+const ConfigMapName = "devspace-previews"
+
+// maxUpdateRetries is the number of attempts to update the config map if it was changed concurrently
+const maxUpdateRetries = 3
+
+// List returns the active previews by slug
+func List(client kubernetes.Interface, namespace string) (map[string]*Preview, error) {
+	configMap, err := client.CoreV1().ConfigMaps(namespace).Get(ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return map[string]*Preview{}, nil
+		}
+
+		return nil, fmt.Errorf("Unable to read config map %s/%s: %v", namespace, ConfigMapName, err)
+	}
+
+	return parsePreviews(configMap)
+}
+
+// Register records the preview. It fails if the slug of the preview is already used by a different branch
+func Register(client kubernetes.Interface, namespace string, preview *Preview) error {
+	return updatePreviews(client, namespace, func(previews map[string]*Preview) error {
+		err := checkCollision(previews, preview)
+		if err != nil {
+			return err
+		}
+
+		previews[preview.Slug] = preview
+		return nil
+	})
+}
+
+// Unregister removes the preview with the given slug from the records
+func Unregister(client kubernetes.Interface, namespace, slug string) error {
+	return updatePreviews(client, namespace, func(previews map[string]*Preview) error {
+		delete(previews, slug)
+		return nil
+	})
+}
+
+// updatePreviews reads the previews, changes them with update and writes them back. The update is retried if the
+// config map was changed by someone else in the meantime
+func updatePreviews(client kubernetes.Interface, namespace string, update func(map[string]*Preview) error) error {
+	configMaps := client.CoreV1().ConfigMaps(namespace)
+
+	for retry := 0; ; retry++ {
+		configMap, err := configMaps.Get(ConfigMapName, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			configMap = &k8sv1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: ConfigMapName,
+					Labels: map[string]string{
+						kubectl.CreatedByLabel: kubectl.CreatedByLabelValue,
+					},
+				},
+			}
+		} else if err != nil {
+			return fmt.Errorf("Unable to read config map %s/%s: %v", namespace, ConfigMapName, err)
+		}
+
+		previews, err := parsePreviews(configMap)
+		if err != nil {
+			return err
+		}
+
+		err = update(previews)
+		if err != nil {
+			return err
+		}
+
+		configMap.Data = map[string]string{}
+		for slug, preview := range previews {
+			data, err := json.Marshal(preview)
+			if err != nil {
+				return err
+			}
+
+			configMap.Data[slug] = string(data)
+		}
+
+		if configMap.ResourceVersion == "" {
+			_, err = configMaps.Create(configMap)
+		} else {
+			_, err = configMaps.Update(configMap)
+		}
+		if err == nil {
+			return nil
+		} else if (kerrors.IsConflict(err) || kerrors.IsAlreadyExists(err)) && retry < maxUpdateRetries {
+			continue
+		}
+
+		return fmt.Errorf("Unable to write config map %s/%s: %v", namespace, ConfigMapName, err)
+	}
+}
+
+// parsePreviews returns the previews that are recorded in the config map
+func parsePreviews(configMap *k8sv1.ConfigMap) (map[string]*Preview, error) {
+	previews := map[string]*Preview{}
+	for slug, data := range configMap.Data {
+		preview := &Preview{}
+		err := json.Unmarshal([]byte(data), preview)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing preview %s in config map %s: %v", slug, ConfigMapName, err)
+		}
+
+		previews[slug] = preview
+	}
+
+	return previews, nil
+}
@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
@@ -10,25 +11,20 @@ import (
 
 	"github.com/covexo/devspace/pkg/devspace/config/v1"
 	"github.com/covexo/devspace/pkg/devspace/helm"
+	devspaceKubectl "github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/signalutil"
 	"github.com/foomo/htpasswd"
 	k8sv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func createRegistry(kubectl *kubernetes.Clientset, helm *helm.ClientWrapper, internalRegistry *v1.InternalRegistryConfig, registryConfig *v1.RegistryConfig) error {
+func createRegistry(kubectl kubernetes.Interface, helm *helm.ClientWrapper, internalRegistry *v1.InternalRegistryConfig, registryConfig *v1.RegistryConfig) error {
 	registryReleaseNamespace := *internalRegistry.Namespace
 	if registryReleaseNamespace != "default" {
-		_, err := kubectl.CoreV1().Namespaces().Get(registryReleaseNamespace, metav1.GetOptions{})
+		err := devspaceKubectl.EnsureNamespace(kubectl, registryReleaseNamespace, log.GetInstance())
 		if err != nil {
-			// Create registryReleaseNamespace
-			_, err = kubectl.CoreV1().Namespaces().Create(&k8sv1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: registryReleaseNamespace,
-				},
-			})
-			if err != nil {
-				return err
-			}
+			return err
 		}
 	}
 
@@ -52,9 +48,8 @@ func createRegistry(kubectl *kubernetes.Clientset, helm *helm.ClientWrapper, int
 	return nil
 }
 
-func createOrUpdateRegistrySecret(kubectl *kubernetes.Clientset, internalRegistry *v1.InternalRegistryConfig, registryConfig *v1.RegistryConfig) error {
+func createOrUpdateRegistrySecret(kubectl kubernetes.Interface, internalRegistry *v1.InternalRegistryConfig, registryConfig *v1.RegistryConfig) error {
 	registryReleaseNamespace := *internalRegistry.Namespace
-	registryAuth := registryConfig.Auth
 
 	htpasswdSecretName := InternalRegistryName + "-docker-registry-secret"
 	htpasswdSecret, err := kubectl.Core().Secrets(registryReleaseNamespace).Get(htpasswdSecretName, metav1.GetOptions{})
@@ -79,7 +74,12 @@ func createOrUpdateRegistrySecret(kubectl *kubernetes.Clientset, internalRegistr
 		newHtpasswdData, _ = htpasswd.ParseHtpasswd(oldHtpasswdDataBytes)
 	}
 
-	err = newHtpasswdData.SetPassword(*registryAuth.Username, *registryAuth.Password, htpasswd.HashBCrypt)
+	username, password, err := GetRegistryCredentials(registryConfig)
+	if err != nil {
+		return err
+	}
+
+	err = newHtpasswdData.SetPassword(username, password, htpasswd.HashBCrypt)
 	if err != nil {
 		return fmt.Errorf("Unable to set password in htpasswd: %s", err.Error())
 	}
@@ -101,7 +101,7 @@ func createOrUpdateRegistrySecret(kubectl *kubernetes.Clientset, internalRegistr
 	return nil
 }
 
-func getRegistryURL(kubectl *kubernetes.Clientset, registryReleaseNamespace, registryServiceName string) (string, error) {
+func getRegistryURL(ctx context.Context, kubectl kubernetes.Interface, registryReleaseNamespace, registryServiceName string) (string, error) {
 	maxServiceWaiting := 60 * time.Second
 	serviceWaitingInterval := 3 * time.Second
 
@@ -115,7 +115,11 @@ func getRegistryURL(kubectl *kubernetes.Clientset, registryReleaseNamespace, reg
 			return registryService.Spec.ClusterIP + ":" + strconv.Itoa(registryPort), nil
 		}
 
-		time.Sleep(serviceWaitingInterval)
+		err = signalutil.Sleep(ctx, serviceWaitingInterval)
+		if err != nil {
+			return "", err
+		}
+
 		maxServiceWaiting = maxServiceWaiting - serviceWaitingInterval
 
 		if maxServiceWaiting <= 0 {
@@ -0,0 +1,119 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/signalutil"
+	"github.com/docker/docker/client"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecr"
+)
+
+// AuthTypeECR is the registry auth type for amazon elastic container registries
+const AuthTypeECR = "ecr"
+
+var ecrRegistryRegEx = regexp.MustCompile(`^(?:https?://)?([0-9]+)\.dkr\.ecr\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?/?$`)
+
+// ecrTokenRefreshInterval is the interval the pull secrets of ecr registries are refreshed in, because ecr
+// authorization tokens expire after 12 hours
+const ecrTokenRefreshInterval = 11 * time.Hour
+
+// IsECRRegistry checks if the authorization tokens of the registry are requested from ecr (an ecr section or auth.type ecr)
+func IsECRRegistry(registryConf *v1.RegistryConfig) bool {
+	if registryConf.ECR != nil {
+		return true
+	}
+
+	return registryConf.Auth != nil && registryConf.Auth.Type != nil && *registryConf.Auth.Type == AuthTypeECR
+}
+
+// getECRCredentials requests an authorization token for the registry with the ambient aws credentials. Region and
+// account id of ecr overwrite the ones of the registry url (e.g. for urls of vpc endpoints)
+func getECRCredentials(registryConf *v1.RegistryConfig) (string, string, error) {
+	registryURL := ""
+	if registryConf.URL != nil {
+		registryURL = *registryConf.URL
+	}
+
+	accountID := ""
+	region := ""
+	if match := ecrRegistryRegEx.FindStringSubmatch(registryURL); match != nil {
+		accountID = match[1]
+		region = match[2]
+	}
+
+	if registryConf.ECR != nil {
+		if registryConf.ECR.AccountID != nil && *registryConf.ECR.AccountID != "" {
+			accountID = *registryConf.ECR.AccountID
+		}
+		if registryConf.ECR.Region != nil && *registryConf.ECR.Region != "" {
+			region = *registryConf.ECR.Region
+		}
+	}
+
+	if accountID == "" || region == "" {
+		return "", "", fmt.Errorf("Registry url %s is not an ecr registry (expected <account>.dkr.ecr.<region>.amazonaws.com), please specify ecr.accountId and ecr.region", registryURL)
+	}
+
+	awsSession, err := session.NewSessionWithOptions(session.Options{
+		Config:            aws.Config{Region: aws.String(region)},
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("Error creating aws session: %v", err)
+	}
+
+	response, err := ecr.New(awsSession).GetAuthorizationToken(&ecr.GetAuthorizationTokenInput{
+		RegistryIds: []*string{aws.String(accountID)},
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("Error retrieving ecr authorization token: %v", err)
+	}
+
+	if len(response.AuthorizationData) == 0 || response.AuthorizationData[0].AuthorizationToken == nil {
+		return "", "", errors.New("Ecr returned no authorization token")
+	}
+
+	// The token is the base64 encoded 'user:password' pair
+	decoded, err := base64.StdEncoding.DecodeString(*response.AuthorizationData[0].AuthorizationToken)
+	if err != nil {
+		return "", "", fmt.Errorf("Error decoding ecr authorization token: %v", err)
+	}
+
+	splitted := strings.SplitN(string(decoded), ":", 2)
+	if len(splitted) != 2 {
+		return "", "", errors.New("Invalid ecr authorization token")
+	}
+
+	return splitted[0], splitted[1], nil
+}
+
+// RefreshECRPullSecrets requests new authorization tokens and updates the pull secrets of all ecr registries every
+// ecrTokenRefreshInterval until the context is cancelled. Errors are only logged, so that a failed refresh doesn't
+// stop a running devspace
+func RefreshECRPullSecrets(ctx context.Context, dockerClient client.CommonAPIClient, client kubernetes.Interface, log log.Logger) {
+	for {
+		err := signalutil.Sleep(ctx, ecrTokenRefreshInterval)
+		if err != nil {
+			return
+		}
+
+		err = createPullSecrets(dockerClient, client, IsECRRegistry, log)
+		if err != nil {
+			log.Warnf("Unable to refresh the pull secrets of ecr registries: %v", err)
+		} else {
+			log.Done("Refreshed the pull secrets of ecr registries")
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+)
+
+func TestIsECRRegistry(t *testing.T) {
+	testCases := map[string]struct {
+		registryConf *v1.RegistryConfig
+		expected     bool
+	}{
+		"auth type ecr": {
+			registryConf: &v1.RegistryConfig{Auth: &v1.RegistryAuth{Type: configutil.String(AuthTypeECR)}},
+			expected:     true,
+		},
+		"ecr config": {
+			registryConf: &v1.RegistryConfig{ECR: &v1.ECRConfig{Region: configutil.String("eu-west-1")}},
+			expected:     true,
+		},
+		"static credentials": {
+			registryConf: &v1.RegistryConfig{Auth: &v1.RegistryAuth{Username: configutil.String("admin")}},
+		},
+	}
+
+	for name, testCase := range testCases {
+		if IsECRRegistry(testCase.registryConf) != testCase.expected {
+			t.Fatalf("Test case %s: expected %v", name, testCase.expected)
+		}
+	}
+}
+
+func TestGetECRCredentialsWithoutAccount(t *testing.T) {
+	registryConf := &v1.RegistryConfig{
+		URL: configutil.String("ecr.vpce.internal"),
+		ECR: &v1.ECRConfig{Region: configutil.String("eu-west-1")},
+	}
+
+	_, _, err := getECRCredentials(registryConf)
+	if err == nil {
+		t.Fatal("Expected error for ecr registry without account id")
+	}
+}
@@ -0,0 +1,35 @@
+package registry
+
+import (
+	"errors"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// AuthTypeGCloud is the registry auth type for google container registries and artifact registries
+const AuthTypeGCloud = "gcloud"
+
+// gcloudTokenUsername is the username google registries expect for access token authentication
+const gcloudTokenUsername = "_token"
+
+var googleRegistryRegEx = regexp.MustCompile(`^(?:https?://)?(?:[a-z]+\.)?gcr\.io/?$|^(?:https?://)?[a-z0-9-]+-docker\.pkg\.dev/?$`)
+
+// IsGoogleRegistry checks if the registry url belongs to the google container registry or artifact registry
+func IsGoogleRegistry(registryURL string) bool {
+	return googleRegistryRegEx.MatchString(registryURL)
+}
+
+// getGCloudCredentials requests a short-lived access token with the ambient gcloud credentials. If the user is not
+// logged in to gcloud, the application default credentials are used
+func getGCloudCredentials() (string, string, error) {
+	token, err := exec.Command("gcloud", "auth", "print-access-token").Output()
+	if err != nil || strings.TrimSpace(string(token)) == "" {
+		token, err = exec.Command("gcloud", "auth", "application-default", "print-access-token").Output()
+		if err != nil || strings.TrimSpace(string(token)) == "" {
+			return "", "", errors.New("Couldn't retrieve google cloud access token. Make sure gcloud is installed and you are logged in")
+		}
+	}
+
+	return gcloudTokenUsername, strings.TrimSpace(string(token)), nil
+}
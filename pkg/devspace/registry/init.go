@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -14,8 +15,8 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-// InitRegistries initializes all registries
-func InitRegistries(dockerClient client.CommonAPIClient, client *kubernetes.Clientset, log log.Logger) error {
+// InitRegistries initializes all registries. Waiting for the internal registry stops as soon as the context is cancelled
+func InitRegistries(ctx context.Context, dockerClient client.CommonAPIClient, client kubernetes.Interface, log log.Logger) error {
 	config := configutil.GetConfig()
 	registryMap := *config.Registries
 
@@ -26,14 +27,14 @@ func InitRegistries(dockerClient client.CommonAPIClient, client *kubernetes.Clie
 		}
 
 		log.StartWait("Initializing helm client")
-		helm, err := helm.NewClient(client, log, false)
+		helm, err := helm.NewClientWithContext(ctx, client, log, false)
 		log.StopWait()
 		if err != nil {
 			return fmt.Errorf("Error initializing helm client: %v", err)
 		}
 
 		log.StartWait("Initializing internal registry")
-		err = InitInternalRegistry(client, helm, config.InternalRegistry, registryConf)
+		err = InitInternalRegistry(ctx, client, helm, config.InternalRegistry, registryConf)
 		log.StopWait()
 		if err != nil {
 			return fmt.Errorf("Internal registry error: %v", err)
@@ -50,8 +51,54 @@ func InitRegistries(dockerClient client.CommonAPIClient, client *kubernetes.Clie
 	return nil
 }
 
+// CheckRegistries gets the url of the internal registry of a bootstrapped cluster without creating the registry or
+// any pull secrets
+func CheckRegistries(ctx context.Context, client kubernetes.Interface, log log.Logger) error {
+	config := configutil.GetConfig()
+	registryMap := *config.Registries
+
+	if config.InternalRegistry != nil && config.InternalRegistry.Deploy != nil && *config.InternalRegistry.Deploy == true {
+		registryConf, regConfExists := registryMap["internal"]
+		if !regConfExists {
+			return errors.New("Registry config not found for internal registry")
+		}
+
+		log.StartWait("Checking internal registry")
+		err := CheckInternalRegistry(ctx, client, config.InternalRegistry, registryConf)
+		log.StopWait()
+		if err != nil {
+			return fmt.Errorf("Internal registry error: %v", err)
+		}
+	}
+
+	return nil
+}
+
 // CreatePullSecrets creates the image pull secrets
-func CreatePullSecrets(dockerClient client.CommonAPIClient, client *kubernetes.Clientset, log log.Logger) error {
+func CreatePullSecrets(dockerClient client.CommonAPIClient, client kubernetes.Interface, log log.Logger) error {
+	return createPullSecrets(dockerClient, client, nil, log)
+}
+
+// HasECRPullSecrets checks if pull secrets are created for ecr registries
+func HasECRPullSecrets() bool {
+	config := configutil.GetConfig()
+
+	if config.Images != nil {
+		for _, imageConf := range *config.Images {
+			if imageConf.CreatePullSecret != nil && *imageConf.CreatePullSecret == true {
+				_, registryConfig, err := GetRegistryConfigFromImageConfig(imageConf)
+				if err == nil && IsECRRegistry(registryConfig) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// createPullSecrets creates the image pull secrets of all registries that match the filter (all registries if nil)
+func createPullSecrets(dockerClient client.CommonAPIClient, client kubernetes.Interface, filter func(*v1.RegistryConfig) bool, log log.Logger) error {
 	config := configutil.GetConfig()
 
 	if config.Images != nil {
@@ -61,6 +108,9 @@ func CreatePullSecrets(dockerClient client.CommonAPIClient, client *kubernetes.C
 				if err != nil {
 					return err
 				}
+				if filter != nil && filter(registryConfig) == false {
+					continue
+				}
 
 				log.StartWait("Creating image pull secret for registry: " + *registryConfig.URL)
 				err = createPullSecretForRegistry(dockerClient, client, registryConfig, log)
@@ -75,7 +125,7 @@ func CreatePullSecrets(dockerClient client.CommonAPIClient, client *kubernetes.C
 	return nil
 }
 
-func createPullSecretForRegistry(dockerClient client.CommonAPIClient, client *kubernetes.Clientset, registryConf *v1.RegistryConfig, log log.Logger) error {
+func createPullSecretForRegistry(dockerClient client.CommonAPIClient, client kubernetes.Interface, registryConf *v1.RegistryConfig, log log.Logger) error {
 	config := configutil.GetConfig()
 
 	defaultNamespace, err := configutil.GetDefaultNamespace(config)
@@ -88,28 +138,43 @@ func createPullSecretForRegistry(dockerClient client.CommonAPIClient, client *ku
 		registryURL = *registryConf.URL
 	}
 
-	username := ""
-	password := ""
+	// Ecr and google registry tokens expire, so we request a fresh one every time
+	username, password, err := GetRegistryCredentials(registryConf)
+	if err != nil {
+		return err
+	}
 
-	if registryConf.Auth == nil || registryConf.Auth.Username == nil || registryConf.Auth.Password == nil {
-		authConfig, _ := docker.GetAuthConfig(dockerClient, registryURL, true)
+	// Use the credentials of the local docker config (including credential helpers) for the missing
+	// credentials if the user did not opt out
+	useDockerConfig := registryConf.UseDockerConfig == nil || *registryConf.UseDockerConfig
+	if useDockerConfig && (username == "" || password == "") {
+		authConfig, err := docker.GetAuthConfig(dockerClient, registryURL, true)
+		if err != nil {
+			log.Warnf("Unable to retrieve credentials for registry %s from docker config: %v", registryURL, err)
+		}
 
 		if authConfig != nil {
-			username = authConfig.Username
-			password = authConfig.Password
-		}
-	}
+			if username == "" {
+				username = authConfig.Username
+			}
 
-	if registryConf.Auth != nil {
-		if registryConf.Auth.Username != nil {
-			username = *registryConf.Auth.Username
-		}
+			if password == "" {
+				password = authConfig.Password
+			}
 
-		if registryConf.Auth.Password != nil {
-			password = *registryConf.Auth.Password
+			// Identity tokens are refresh tokens for the token endpoint of the registry, the kubelet can't pull with them
+			if password == "" && authConfig.IdentityToken != "" {
+				log.Warnf("The docker config only holds an identity token for registry %s, which can't be used in image pull secrets. Skipping image pull secret creation", registryURL)
+				return nil
+			}
 		}
 	}
 
+	if username == "" && password == "" {
+		log.Warnf("No credentials found for registry %s, skipping image pull secret creation", registryURL)
+		return nil
+	}
+
 	if config.DevSpace.Deployments != nil {
 		for _, deployConfig := range *config.DevSpace.Deployments {
 			email := "noreply@devspace-cloud.com"
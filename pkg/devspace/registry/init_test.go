@@ -0,0 +1,64 @@
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/util/log"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestInitRegistries(t *testing.T) {
+	configutil.ResetConfig()
+	defer configutil.ResetConfig()
+
+	config := configutil.InitConfig()
+	config.Cluster.Namespace = configutil.String("test")
+	config.Images = &map[string]*v1.ImageConfig{
+		"default": {
+			Name:             configutil.String("devspace"),
+			Registry:         configutil.String("internal"),
+			CreatePullSecret: configutil.Bool(true),
+		},
+	}
+	config.Registries = &map[string]*v1.RegistryConfig{
+		"internal": {
+			URL: configutil.String("registry.internal:5000"),
+			Auth: &v1.RegistryAuth{
+				Username: configutil.String("admin"),
+				Password: configutil.String("secret"),
+			},
+		},
+	}
+	config.DevSpace.Deployments = &[]*v1.DeploymentConfig{
+		{
+			Name: configutil.String("api"),
+		},
+		{
+			Name:      configutil.String("worker"),
+			Namespace: configutil.String("worker"),
+		},
+	}
+
+	client := fake.NewSimpleClientset()
+
+	err := InitRegistries(context.Background(), nil, client, log.GetInstance())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pullSecretName := GetRegistryAuthSecretName("registry.internal:5000")
+	for _, namespace := range []string{"test", "worker"} {
+		secret, err := client.CoreV1().Secrets(namespace).Get(pullSecretName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Pull secret wasn't created in namespace %s: %v", namespace, err)
+		}
+		if secret.Type != k8sv1.SecretTypeDockerConfigJson {
+			t.Fatalf("Expected pull secret of type %s, got %s", k8sv1.SecretTypeDockerConfigJson, secret.Type)
+		}
+	}
+}
@@ -1,21 +1,25 @@
 package registry
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/covexo/devspace/pkg/devspace/config/generated"
 	"github.com/covexo/devspace/pkg/devspace/config/v1"
 
 	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/signalutil"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
 	"github.com/covexo/devspace/pkg/devspace/helm"
+	devspaceKubectl "github.com/covexo/devspace/pkg/devspace/kubectl"
 
 	k8sv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,8 +36,11 @@ const registryPort = 5000
 
 var pullSecretNames = []string{}
 
+// pullSecretNamesMutex guards pullSecretNames, because the ecr pull secrets are refreshed in the background
+var pullSecretNamesMutex sync.Mutex
+
 // CreatePullSecret creates an image pull secret for a registry
-func CreatePullSecret(kubectl *kubernetes.Clientset, namespace, registryURL, username, passwordOrToken, email string, log log.Logger) error {
+func CreatePullSecret(kubectl kubernetes.Interface, namespace, registryURL, username, passwordOrToken, email string, log log.Logger) error {
 	pullSecretName := GetRegistryAuthSecretName(registryURL)
 	if registryURL == "hub.docker.com" || registryURL == "" {
 		registryURL = "https://index.docker.io/v1/"
@@ -81,8 +88,17 @@ func CreatePullSecret(kubectl *kubernetes.Clientset, namespace, registryURL, use
 		}
 	}
 
-	pullSecretNames = append(pullSecretNames, pullSecretName)
+	// Pull secrets are updated for every namespace and whenever their tokens are refreshed
+	pullSecretNamesMutex.Lock()
+	defer pullSecretNamesMutex.Unlock()
+
+	for _, name := range pullSecretNames {
+		if name == pullSecretName {
+			return nil
+		}
+	}
 
+	pullSecretNames = append(pullSecretNames, pullSecretName)
 	return nil
 }
 
@@ -94,7 +110,7 @@ func GetRegistryAuthSecretName(registryURL string) string {
 }
 
 // InitInternalRegistry deploys and starts a new docker registry if necessary
-func InitInternalRegistry(kubectl *kubernetes.Clientset, helm *helm.ClientWrapper, internalRegistry *v1.InternalRegistryConfig, registryConfig *v1.RegistryConfig) error {
+func InitInternalRegistry(ctx context.Context, kubectl kubernetes.Interface, helm *helm.ClientWrapper, internalRegistry *v1.InternalRegistryConfig, registryConfig *v1.RegistryConfig) error {
 	registryReleaseNamespace := *internalRegistry.Namespace
 
 	// Check if registry already exists
@@ -107,7 +123,7 @@ func InitInternalRegistry(kubectl *kubernetes.Clientset, helm *helm.ClientWrappe
 	}
 
 	// Get the registry url
-	serviceHostname, err := getRegistryURL(kubectl, registryReleaseNamespace, InternalRegistryName+"-docker-registry")
+	serviceHostname, err := getRegistryURL(ctx, kubectl, registryReleaseNamespace, InternalRegistryName+"-docker-registry")
 	if err != nil {
 		return err
 	}
@@ -119,7 +135,7 @@ func InitInternalRegistry(kubectl *kubernetes.Clientset, helm *helm.ClientWrappe
 	// Wait for registry if it is not ready yet
 	if registryDeployment == nil || registryDeployment.Status.Replicas == 0 || registryDeployment.Status.ReadyReplicas != registryDeployment.Status.Replicas {
 		// Wait till registry is started
-		err = waitForRegistry(registryReleaseNamespace, InternalRegistryDeploymentName, kubectl)
+		err = waitForRegistry(ctx, registryReleaseNamespace, InternalRegistryDeploymentName, kubectl)
 		if err != nil {
 			return err
 		}
@@ -128,7 +144,32 @@ func InitInternalRegistry(kubectl *kubernetes.Clientset, helm *helm.ClientWrappe
 	return nil
 }
 
-func waitForRegistry(registryNamespace, registryReleaseDeploymentName string, client *kubernetes.Clientset) error {
+// CheckInternalRegistry sets the url of the internal registry and waits until it is ready. In contrast to
+// InitInternalRegistry, it fails if the registry is not deployed
+func CheckInternalRegistry(ctx context.Context, kubectl kubernetes.Interface, internalRegistry *v1.InternalRegistryConfig, registryConfig *v1.RegistryConfig) error {
+	registryReleaseNamespace := *internalRegistry.Namespace
+
+	registryDeployment, err := kubectl.ExtensionsV1beta1().Deployments(registryReleaseNamespace).Get(InternalRegistryDeploymentName, metav1.GetOptions{})
+	if err != nil {
+		return devspaceKubectl.NewNotBootstrappedError("Internal registry "+registryReleaseNamespace+"/"+InternalRegistryDeploymentName, err)
+	}
+
+	serviceHostname, err := getRegistryURL(ctx, kubectl, registryReleaseNamespace, InternalRegistryName+"-docker-registry")
+	if err != nil {
+		return err
+	}
+
+	registryConfig.URL = configutil.String(serviceHostname)
+	registryConfig.Insecure = configutil.Bool(true)
+
+	if registryDeployment.Status.Replicas == 0 || registryDeployment.Status.ReadyReplicas != registryDeployment.Status.Replicas {
+		return waitForRegistry(ctx, registryReleaseNamespace, InternalRegistryDeploymentName, kubectl)
+	}
+
+	return nil
+}
+
+func waitForRegistry(ctx context.Context, registryNamespace, registryReleaseDeploymentName string, client kubernetes.Interface) error {
 	registryWaitingTime := 2 * 60 * time.Second
 	registryCheckInverval := 5 * time.Second
 
@@ -137,14 +178,15 @@ func waitForRegistry(registryNamespace, registryReleaseDeploymentName string, cl
 
 	for registryWaitingTime > 0 {
 		registryDeployment, err := client.ExtensionsV1beta1().Deployments(registryNamespace).Get(registryReleaseDeploymentName, metav1.GetOptions{})
-		if err != nil {
-			continue
-		}
-		if registryDeployment.Status.ReadyReplicas == registryDeployment.Status.Replicas {
+		if err == nil && registryDeployment.Status.ReadyReplicas == registryDeployment.Status.Replicas {
 			return nil
 		}
 
-		time.Sleep(registryCheckInverval)
+		err = signalutil.Sleep(ctx, registryCheckInverval)
+		if err != nil {
+			return err
+		}
+
 		registryWaitingTime = registryWaitingTime - registryCheckInverval
 	}
 
@@ -199,5 +241,8 @@ func GetRegistryConfig(imageConfig *v1.ImageConfig) (*v1.RegistryConfig, error)
 
 // GetPullSecretNames returns all names of auto-generated image pull secrets
 func GetPullSecretNames() []string {
-	return pullSecretNames
+	pullSecretNamesMutex.Lock()
+	defer pullSecretNamesMutex.Unlock()
+
+	return append([]string{}, pullSecretNames...)
 }
@@ -1,6 +1,11 @@
 package registry
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
 	"github.com/covexo/devspace/pkg/devspace/config/v1"
 	"github.com/docker/distribution/reference"
@@ -30,6 +35,9 @@ func GetRegistryConfigFromImageConfig(imageConf *v1.ImageConfig) (string, *v1.Re
 		if oldRegistryConf.Auth != nil {
 			registryConf.Auth = oldRegistryConf.Auth
 		}
+		if oldRegistryConf.UseDockerConfig != nil {
+			registryConf.UseDockerConfig = oldRegistryConf.UseDockerConfig
+		}
 		if *registryConf.URL == "hub.docker.com" {
 			*registryConf.URL = ""
 		}
@@ -85,3 +93,89 @@ func GetRegistryFromImageName(imageName string) (string, error) {
 
 	return repoInfo.Index.Name, nil
 }
+
+// GetRegistryCredentials returns the username and password that are configured for the registry. For ecr
+// registries a fresh authorization token is requested from aws, because these tokens expire after 12 hours.
+// For google registries a short-lived access token is requested from gcloud, if gcloud credentials are available
+func GetRegistryCredentials(registryConf *v1.RegistryConfig) (string, string, error) {
+	username := ""
+	password := ""
+
+	registryURL := ""
+	if registryConf.URL != nil {
+		registryURL = *registryConf.URL
+	}
+
+	authType := ""
+	if registryConf.Auth != nil && registryConf.Auth.Type != nil {
+		authType = *registryConf.Auth.Type
+	}
+
+	if IsECRRegistry(registryConf) {
+		return getECRCredentials(registryConf)
+	}
+
+	if authType == AuthTypeGCloud || (authType == "" && IsGoogleRegistry(registryURL)) {
+		gcloudUsername, gcloudPassword, err := getGCloudCredentials()
+		if err == nil {
+			return gcloudUsername, gcloudPassword, nil
+		}
+
+		// Only fail if gcloud auth was requested explicitly, otherwise we fall back to the static credentials
+		if authType == AuthTypeGCloud {
+			return "", "", err
+		}
+	}
+
+	if registryConf.Auth == nil {
+		return username, password, nil
+	}
+
+	if registryConf.Auth.Username != nil {
+		username = *registryConf.Auth.Username
+	}
+	if registryConf.Auth.Password != nil {
+		password = *registryConf.Auth.Password
+	}
+	if registryConf.Auth.PasswordFile != nil && *registryConf.Auth.PasswordFile != "" {
+		if password != "" {
+			return "", "", fmt.Errorf("Registry %s: auth.password and auth.passwordFile cannot be used together", registryURL)
+		}
+
+		filePassword, err := readPasswordFile(*registryConf.Auth.PasswordFile)
+		if err != nil {
+			return "", "", fmt.Errorf("Registry %s: %v", registryURL, err)
+		}
+
+		password = filePassword
+	}
+
+	return username, password, nil
+}
+
+// readPasswordFile reads a password or token from a file. The contents are never part of returned errors
+func readPasswordFile(path string) (string, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("Password file %s does not exist", path)
+		}
+
+		return "", fmt.Errorf("Error accessing password file %s: %v", path, err)
+	}
+	if stat.IsDir() {
+		return "", fmt.Errorf("Password file %s is a directory", path)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Error reading password file %s: %v", path, err)
+	}
+
+	password := strings.TrimSpace(string(data))
+	if password == "" {
+		return "", fmt.Errorf("Password file %s is empty", path)
+	}
+
+	return password, nil
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/util/log"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// PortForwardingPortsFile holds the local ports that were chosen automatically by the running port forwarding
+const PortForwardingPortsFile = ".devspace/portforwarding.ports"
+
+// AutoPort is a local port that was chosen automatically, because the configured one was in use, 0 or omitted
+type AutoPort struct {
+	Entry          string `yaml:"entry"`
+	ConfiguredPort int    `yaml:"configuredPort"`
+	LocalPort      int    `yaml:"localPort"`
+	RemotePort     int    `yaml:"remotePort"`
+	BindAddress    string `yaml:"bindAddress"`
+}
+
+// localPorts holds the local ports that replace the configured local ports of port mappings
+var localPorts = map[*v1.PortMapping]int{}
+var localPortsMutex sync.Mutex
+
+// ValidatePortForwarding checks the port mappings of all port forwardings. Multiple local ports that forward to the
+// same remote port are allowed. A local port that is used by more than one port mapping is only forwarded by the
+// first port mapping (see skipDuplicateLocalPorts), so that case is only a warning
+func ValidatePortForwarding(portForwardings []*v1.PortForwardingConfig, log log.Logger) error {
+	remotePortEntries := map[int][]string{}
+	remotePorts := []int{}
+
+	for index, portForwarding := range portForwardings {
+		entry := describePortForwarding(index, portForwarding)
+		if portForwarding.PortMappings == nil || len(*portForwarding.PortMappings) == 0 {
+			return fmt.Errorf("%s has no portMappings", entry)
+		}
+
+		// Reverse port forwardings listen on the remote port inside the container and connect to the local port
+		if portForwarding.Reverse != nil && *portForwarding.Reverse {
+			for _, portMapping := range *portForwarding.PortMappings {
+				if portMapping.LocalPort == nil || *portMapping.LocalPort == 0 || portMapping.RemotePort == nil {
+					return fmt.Errorf("%s is a reverse port forwarding and needs a localPort and remotePort in every port mapping", entry)
+				}
+			}
+
+			continue
+		}
+
+		for _, portMapping := range *portForwarding.PortMappings {
+			if portMapping.RemotePort == nil {
+				return fmt.Errorf("%s has a port mapping without remotePort", entry)
+			}
+
+			remotePort := *portMapping.RemotePort
+			if _, ok := remotePortEntries[remotePort]; ok == false {
+				remotePorts = append(remotePorts, remotePort)
+			}
+
+			remotePortEntries[remotePort] = append(remotePortEntries[remotePort], entry)
+		}
+	}
+
+	for _, remotePort := range remotePorts {
+		if entries := remotePortEntries[remotePort]; len(entries) > 1 {
+			log.Infof("Remote port %d is forwarded by multiple port mappings: %s", remotePort, strings.Join(entries, ", "))
+		}
+	}
+
+	_, conflicts := skipDuplicateLocalPorts(portForwardings)
+	if len(conflicts) > 0 {
+		log.Warnf("Local ports are used by more than one port mapping, only the first port mapping is forwarded and the others are skipped: %s", strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
+
+// skipDuplicateLocalPorts returns copies of the port forwardings without the port mappings whose local port is already
+// used by an earlier port mapping (in the order of the config) and a description of every skipped port mapping. The
+// returned slice has the same length and order, port forwardings without port mappings left have empty port
+// mappings. Reverse port forwardings and local ports that are 0 or omitted are never skipped
+func skipDuplicateLocalPorts(portForwardings []*v1.PortForwardingConfig) ([]*v1.PortForwardingConfig, []string) {
+	usedBy := map[int]string{}
+	conflicts := []string{}
+	filtered := make([]*v1.PortForwardingConfig, 0, len(portForwardings))
+
+	for index, portForwarding := range portForwardings {
+		if portForwarding.PortMappings == nil || (portForwarding.Reverse != nil && *portForwarding.Reverse) {
+			filtered = append(filtered, portForwarding)
+			continue
+		}
+
+		entry := describePortForwarding(index, portForwarding)
+		portMappings := []*v1.PortMapping{}
+
+		for _, portMapping := range *portForwarding.PortMappings {
+			localPort := getConfiguredLocalPort(portMapping)
+			if localPort == 0 {
+				portMappings = append(portMappings, portMapping)
+				continue
+			}
+
+			if otherEntry, ok := usedBy[localPort]; ok {
+				if otherEntry == entry {
+					conflicts = append(conflicts, fmt.Sprintf("local port %d is used twice in %s", localPort, entry))
+				} else {
+					conflicts = append(conflicts, fmt.Sprintf("local port %d of %s is already used by %s", localPort, entry, otherEntry))
+				}
+
+				continue
+			}
+
+			usedBy[localPort] = entry
+			portMappings = append(portMappings, portMapping)
+		}
+
+		if len(portMappings) == len(*portForwarding.PortMappings) {
+			filtered = append(filtered, portForwarding)
+			continue
+		}
+
+		filteredPortForwarding := *portForwarding
+		filteredPortForwarding.PortMappings = &portMappings
+		filtered = append(filtered, &filteredPortForwarding)
+	}
+
+	return filtered, conflicts
+}
+
+// resolveLocalPorts checks that the local ports of all port forwardings are free before any port forwarding is
+// started. Ports that are in use are replaced with free ephemeral ports if autoPort is true, otherwise an error
+// is returned. Local ports that are 0 or omitted are always replaced
+func resolveLocalPorts(portForwardings []*v1.PortForwardingConfig, autoPort bool, log log.Logger) error {
+	localPortsMutex.Lock()
+	defer localPortsMutex.Unlock()
+
+	autoPorts := []*AutoPort{}
+	usedBy := map[int]string{}
+
+	for index, portForwarding := range portForwardings {
+		// Reverse port forwardings connect to the local port instead of listening on it
+		if portForwarding.Reverse != nil && *portForwarding.Reverse {
+			continue
+		}
+		if portForwarding.PortMappings == nil {
+			continue
+		}
+
+		bindAddress := kubectl.DefaultBindAddress
+		if portForwarding.BindAddresses != nil && len(*portForwarding.BindAddresses) > 0 {
+			bindAddress = (*portForwarding.BindAddresses)[0]
+		}
+		if bindAddress == "localhost" {
+			bindAddress = kubectl.DefaultBindAddress
+		}
+
+		entry := describePortForwarding(index, portForwarding)
+		for _, portMapping := range *portForwarding.PortMappings {
+			configuredPort := getConfiguredLocalPort(portMapping)
+
+			if configuredPort != 0 {
+				err := checkLocalPort(configuredPort, bindAddress, usedBy)
+				if err == nil {
+					usedBy[configuredPort] = entry
+					continue
+				}
+
+				if autoPort == false {
+					return fmt.Errorf("Local port %d of %s is already in use: %v. Stop the process that uses the port, change the localPort or run with --auto-port to use a free port instead", configuredPort, entry, err)
+				}
+
+				log.Warnf("Local port %d of %s is already in use: %v", configuredPort, entry, err)
+			}
+
+			localPort, err := getFreeLocalPort(bindAddress)
+			if err != nil {
+				return fmt.Errorf("Unable to find a free local port for %s: %v", entry, err)
+			}
+
+			localPorts[portMapping] = localPort
+			usedBy[localPort] = entry
+
+			autoPorts = append(autoPorts, &AutoPort{
+				Entry:          entry,
+				ConfiguredPort: configuredPort,
+				LocalPort:      localPort,
+				RemotePort:     *portMapping.RemotePort,
+				BindAddress:    bindAddress,
+			})
+
+			if configuredPort == 0 {
+				log.Infof("Using free local port %d for %s (no localPort configured): %s:%d -> %d", localPort, entry, bindAddress, localPort, *portMapping.RemotePort)
+			} else {
+				log.Infof("Using local port %d for %s: %s:%d -> %d", localPort, entry, bindAddress, localPort, *portMapping.RemotePort)
+			}
+		}
+	}
+
+	if len(autoPorts) == 0 {
+		os.Remove(PortForwardingPortsFile)
+		return nil
+	}
+
+	out, err := yaml.Marshal(autoPorts)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(PortForwardingPortsFile, out, 0644)
+	if err != nil {
+		log.Warnf("Unable to write %s: %v", PortForwardingPortsFile, err)
+	}
+
+	return nil
+}
+
+// checkLocalPort checks if we can listen on the local port
+func checkLocalPort(port int, bindAddress string, usedBy map[int]string) error {
+	if entry, ok := usedBy[port]; ok {
+		return fmt.Errorf("used by %s", entry)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(bindAddress, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+
+	return listener.Close()
+}
+
+// getFreeLocalPort returns a local port that is currently not in use
+func getFreeLocalPort(bindAddress string) (int, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(bindAddress, "0"))
+	if err != nil {
+		return 0, err
+	}
+
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// getLocalPort returns the local port that is used for the port mapping
+func getLocalPort(portMapping *v1.PortMapping) int {
+	localPortsMutex.Lock()
+	defer localPortsMutex.Unlock()
+
+	if localPort, ok := localPorts[portMapping]; ok {
+		return localPort
+	}
+
+	return getConfiguredLocalPort(portMapping)
+}
+
+// getConfiguredLocalPort returns the local port of the config. An omitted local port is 0, which means that a free
+// local port is chosen
+func getConfiguredLocalPort(portMapping *v1.PortMapping) int {
+	if portMapping.LocalPort == nil {
+		return 0
+	}
+
+	return *portMapping.LocalPort
+}
+
+// describePortForwarding returns a readable description of a port forwarding config entry
+func describePortForwarding(index int, portForwarding *v1.PortForwardingConfig) string {
+	target := ""
+
+	if portForwarding.ResourceName != nil && *portForwarding.ResourceName != "" && portForwarding.ResourceType != nil {
+		target = *portForwarding.ResourceType + " " + *portForwarding.ResourceName
+	} else if portForwarding.PodName != nil && *portForwarding.PodName != "" {
+		target = "pod " + *portForwarding.PodName
+	} else if portForwarding.Service != nil {
+		target = "service " + *portForwarding.Service
+	} else if portForwarding.LabelSelector != nil {
+		target = "selector " + kubectl.LabelSelectorToString(*portForwarding.LabelSelector)
+	}
+
+	if target == "" {
+		return fmt.Sprintf("devspace.ports[%d]", index)
+	}
+
+	return fmt.Sprintf("devspace.ports[%d] (%s)", index, target)
+}
+
+// GetAutoPorts returns the local ports that were chosen automatically by the running port forwarding
+func GetAutoPorts() ([]*AutoPort, error) {
+	data, err := ioutil.ReadFile(PortForwardingPortsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	autoPorts := []*AutoPort{}
+	err = yaml.Unmarshal(data, &autoPorts)
+	if err != nil {
+		return nil, err
+	}
+
+	return autoPorts, nil
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/daviddengcn/go-colortext"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultMaxLogLineWidth is the default width after which streamed log lines are truncated
+const DefaultMaxLogLineWidth = 200
+
+// logLineBuffer is the amount of lines that are buffered per container before lines are dropped
+const logLineBuffer = 100
+
+// logPodCheckInterval is the interval in which new pods are searched
+const logPodCheckInterval = 2 * time.Second
+
+var logColors = []ct.Color{ct.Cyan, ct.Magenta, ct.Yellow, ct.Blue, ct.Green, ct.Red}
+
+// LogSelector selects the pods whose logs should be streamed
+type LogSelector struct {
+	LabelSelector string
+	Namespace     string
+}
+
+// LogStreamer streams the logs of all containers of the selected pods into a single output
+type LogStreamer struct {
+	client       kubernetes.Interface
+	selectors    []*LogSelector
+	maxLineWidth int
+	log          log.Logger
+
+	// openStream opens the log stream of a container (replaced in tests, because the fake clientset can't stream)
+	openStream func(pod *k8sv1.Pod, options *k8sv1.PodLogOptions) (io.ReadCloser, error)
+
+	// streams holds the open streams by container, the stream is nil while it is being opened
+	streams   map[string]io.ReadCloser
+	lastSeen  map[string]time.Time
+	colors    map[string]ct.Color
+	mutex     sync.Mutex
+	writeLock sync.Mutex
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// GetReleaseLogSelectors returns a log selector for every deployment in the config
+func GetReleaseLogSelectors() []*LogSelector {
+	config := configutil.GetConfig()
+	selectors := []*LogSelector{}
+
+	if config.DevSpace.Deployments != nil {
+		for _, deployConfig := range *config.DevSpace.Deployments {
+			namespace := ""
+			if deployConfig.Namespace != nil {
+				namespace = *deployConfig.Namespace
+			}
+
+			selectors = append(selectors, &LogSelector{
+				LabelSelector: "release=" + *deployConfig.Name,
+				Namespace:     namespace,
+			})
+		}
+	}
+
+	return selectors
+}
+
+// StartLogs starts streaming the logs of all containers that match one of the selectors. Lines longer than
+// maxLineWidth are truncated (0 disables truncation)
+func StartLogs(client kubernetes.Interface, selectors []*LogSelector, maxLineWidth int, log log.Logger) (*LogStreamer, error) {
+	config := configutil.GetConfig()
+
+	for _, selector := range selectors {
+		if selector.Namespace == "" {
+			defaultNamespace, err := configutil.GetDefaultNamespace(config)
+			if err != nil {
+				return nil, fmt.Errorf("Error retrieving default namespace: %v", err)
+			}
+
+			selector.Namespace = defaultNamespace
+		}
+	}
+
+	streamer := newLogStreamer(client, selectors, maxLineWidth, log)
+
+	streamer.wg.Add(1)
+	go streamer.watchPods()
+
+	return streamer, nil
+}
+
+func newLogStreamer(client kubernetes.Interface, selectors []*LogSelector, maxLineWidth int, log log.Logger) *LogStreamer {
+	return &LogStreamer{
+		client:       client,
+		selectors:    selectors,
+		maxLineWidth: maxLineWidth,
+		log:          log,
+		openStream: func(pod *k8sv1.Pod, options *k8sv1.PodLogOptions) (io.ReadCloser, error) {
+			return client.Core().Pods(pod.Namespace).GetLogs(pod.Name, options).Stream()
+		},
+
+		streams:  make(map[string]io.ReadCloser),
+		lastSeen: make(map[string]time.Time),
+		colors:   make(map[string]ct.Color),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Stop stops all log streams and waits until they are closed
+func (l *LogStreamer) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.stopChan)
+
+		l.mutex.Lock()
+		for _, stream := range l.streams {
+			if stream != nil {
+				stream.Close()
+			}
+		}
+		l.mutex.Unlock()
+
+		l.wg.Wait()
+	})
+}
+
+func (l *LogStreamer) watchPods() {
+	defer l.wg.Done()
+
+	for {
+		for _, selector := range l.selectors {
+			podList, err := l.client.Core().Pods(selector.Namespace).List(metav1.ListOptions{
+				LabelSelector: selector.LabelSelector,
+			})
+			if err != nil {
+				l.log.Warnf("Unable to list pods for logs: %v", err)
+				continue
+			}
+
+			for i := range podList.Items {
+				pod := &podList.Items[i]
+				if kubectl.GetPodStatus(pod) != "Running" {
+					continue
+				}
+
+				for _, container := range pod.Spec.Containers {
+					l.startStream(pod, container.Name)
+				}
+			}
+		}
+
+		select {
+		case <-l.stopChan:
+			return
+		case <-time.After(logPodCheckInterval):
+		}
+	}
+}
+
+func (l *LogStreamer) startStream(pod *k8sv1.Pod, containerName string) {
+	key := pod.Namespace + "/" + pod.Name + "/" + containerName
+
+	l.mutex.Lock()
+	if _, ok := l.streams[key]; ok {
+		l.mutex.Unlock()
+		return
+	}
+
+	select {
+	case <-l.stopChan:
+		l.mutex.Unlock()
+		return
+	default:
+	}
+
+	logOptions := &k8sv1.PodLogOptions{
+		Container: containerName,
+		Follow:    true,
+	}
+
+	// Resume a stream where we stopped or only show the latest lines for new streams
+	if lastSeen, ok := l.lastSeen[key]; ok {
+		logOptions.SinceTime = &metav1.Time{Time: lastSeen}
+	} else {
+		tailLines := int64(10)
+		logOptions.TailLines = &tailLines
+	}
+
+	// Reserve the key, the stream is opened without holding the lock, because the request may take a while
+	l.streams[key] = nil
+	l.mutex.Unlock()
+
+	stream, err := l.openStream(pod, logOptions)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if err != nil {
+		// The container might be restarting, so we try again with the next pod check
+		delete(l.streams, key)
+		l.log.Debugf("Unable to stream logs of %s: %v", key, err)
+		return
+	}
+
+	select {
+	case <-l.stopChan:
+		delete(l.streams, key)
+		stream.Close()
+		return
+	default:
+	}
+
+	if _, ok := l.colors[key]; ok == false {
+		l.colors[key] = logColors[len(l.colors)%len(logColors)]
+	}
+
+	l.streams[key] = stream
+
+	// Each stream has its own buffer, so a chatty container cannot block the others
+	lines := make(chan string, logLineBuffer)
+	prefix := "[" + pod.Name + ":" + containerName + "] "
+
+	l.wg.Add(2)
+	go l.readStream(key, stream, lines)
+	go l.printLines(prefix, l.colors[key], lines)
+}
+
+// readStream sends the lines of the stream to the lines channel. Lines are dropped if the channel is full, so that the
+// output of one container can't block the others. A marker with the number of dropped lines is sent as soon as the
+// channel has room again. The last slot of the channel is reserved for the marker at the end of the stream
+func (l *LogStreamer) readStream(key string, stream io.ReadCloser, lines chan<- string) {
+	defer l.wg.Done()
+	defer close(lines)
+
+	dropped := 0
+	reader := bufio.NewReader(stream)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if dropped > 0 && len(lines) < cap(lines)-1 {
+				lines <- droppedLinesMarker(dropped)
+				dropped = 0
+			}
+
+			if dropped == 0 && len(lines) < cap(lines)-1 {
+				lines <- strings.TrimRight(line, "\r\n")
+			} else {
+				dropped++
+			}
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	if dropped > 0 {
+		lines <- droppedLinesMarker(dropped)
+	}
+
+	stream.Close()
+
+	l.mutex.Lock()
+	delete(l.streams, key)
+	l.lastSeen[key] = time.Now()
+	l.mutex.Unlock()
+}
+
+func (l *LogStreamer) printLines(prefix string, color ct.Color, lines <-chan string) {
+	defer l.wg.Done()
+
+	for line := range lines {
+		line = truncateLine(line, l.maxLineWidth)
+
+		l.writeLock.Lock()
+		log.WriteColored(prefix, color)
+
+		// We use \r\n here, because the terminal might be in raw mode
+		log.Write([]byte(line + "\r\n"))
+		l.writeLock.Unlock()
+	}
+}
+
+func droppedLinesMarker(dropped int) string {
+	if dropped == 1 {
+		return "[1 line dropped]"
+	}
+
+	return fmt.Sprintf("[%d lines dropped]", dropped)
+}
+
+func truncateLine(line string, maxLineWidth int) string {
+	if maxLineWidth <= 0 {
+		return line
+	}
+
+	runes := []rune(line)
+	if len(runes) <= maxLineWidth {
+		return line
+	}
+
+	return string(runes[:maxLineWidth]) + "..."
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/covexo/devspace/pkg/util/log"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTruncateLine(t *testing.T) {
+	testCases := []struct {
+		line         string
+		maxLineWidth int
+		expected     string
+	}{
+		{"short line", 20, "short line"},
+		{"exactly ten", 11, "exactly ten"},
+		{"a line that is too long", 6, "a line..."},
+		{"äöü unicode", 3, "äöü..."},
+		{"no truncation", 0, "no truncation"},
+		{"negative width", -1, "negative width"},
+	}
+
+	for _, testCase := range testCases {
+		line := truncateLine(testCase.line, testCase.maxLineWidth)
+		if line != testCase.expected {
+			t.Fatalf("Expected '%s' truncated to %d to be '%s', got '%s'", testCase.line, testCase.maxLineWidth, testCase.expected, line)
+		}
+	}
+}
+
+func TestReadStreamBackpressure(t *testing.T) {
+	streamer := newLogStreamer(fake.NewSimpleClientset(), nil, 0, log.GetInstance())
+
+	// Nobody reads the lines of the chatty container, the stream still has to be read until its end
+	chattyLines := make(chan string, 3)
+	streamer.wg.Add(1)
+	streamer.readStream("test/api/chatty", ioutil.NopCloser(strings.NewReader("1\n2\n3\n4\n5\n")), chattyLines)
+
+	quietLines := make(chan string, 3)
+	streamer.wg.Add(1)
+	streamer.readStream("test/api/quiet", ioutil.NopCloser(strings.NewReader("hello\n")), quietLines)
+
+	received := []string{}
+	for line := range chattyLines {
+		received = append(received, line)
+	}
+	if strings.Join(received, ",") != "1,2,[3 lines dropped]" {
+		t.Fatalf("Unexpected lines of the chatty container: %v", received)
+	}
+
+	received = []string{}
+	for line := range quietLines {
+		received = append(received, line)
+	}
+	if strings.Join(received, ",") != "hello" {
+		t.Fatalf("Unexpected lines of the quiet container: %v", received)
+	}
+
+	if _, ok := streamer.lastSeen["test/api/chatty"]; ok == false {
+		t.Fatal("Expected the end of the stream to be recorded")
+	}
+}
+
+func TestLogStreamerRetriesFailedStreams(t *testing.T) {
+	pod := &k8sv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "api",
+			Namespace: "test",
+			Labels:    map[string]string{"release": "api"},
+		},
+		Spec: k8sv1.PodSpec{
+			Containers: []k8sv1.Container{{Name: "server"}},
+		},
+		Status: k8sv1.PodStatus{
+			Phase: k8sv1.PodRunning,
+		},
+	}
+
+	selectors := []*LogSelector{{LabelSelector: "release=api", Namespace: "test"}}
+	streamer := newLogStreamer(fake.NewSimpleClientset(pod), selectors, 0, log.GetInstance())
+
+	attempts := 0
+	opened := make(chan struct{})
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	streamer.openStream = func(pod *k8sv1.Pod, options *k8sv1.PodLogOptions) (io.ReadCloser, error) {
+		// The stream has to be opened without holding the lock of the streamer
+		locked := make(chan struct{})
+		go func() {
+			streamer.mutex.Lock()
+			streamer.mutex.Unlock()
+			close(locked)
+		}()
+
+		select {
+		case <-locked:
+		case <-time.After(time.Second):
+			t.Error("Expected the stream to be opened without holding the lock")
+		}
+
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("container is restarting")
+		}
+
+		close(opened)
+		return reader, nil
+	}
+
+	streamer.wg.Add(1)
+	go streamer.watchPods()
+
+	select {
+	case <-opened:
+	case <-time.After(3 * logPodCheckInterval):
+		t.Fatal("Expected the failed stream to be opened again")
+	}
+
+	streamer.Stop()
+	if attempts != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestStopLogStreamerWhileOpening(t *testing.T) {
+	streamer := newLogStreamer(fake.NewSimpleClientset(), nil, 0, log.GetInstance())
+
+	closed := false
+	streamer.openStream = func(pod *k8sv1.Pod, options *k8sv1.PodLogOptions) (io.ReadCloser, error) {
+		// Stop the streamer while the request is running
+		close(streamer.stopChan)
+
+		return &closeRecorder{Reader: strings.NewReader(""), onClose: func() { closed = true }}, nil
+	}
+
+	streamer.startStream(&k8sv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test"}}, "server")
+	if closed == false || len(streamer.streams) != 0 {
+		t.Fatal("Expected the stream opened after stopping to be closed")
+	}
+}
+
+// closeRecorder calls onClose when the stream is closed
+type closeRecorder struct {
+	io.Reader
+	onClose func()
+}
+
+func (c *closeRecorder) Close() error {
+	c.onClose()
+	return nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/skratchdot/open-golang/open"
+)
+
+// DefaultOpenTimeout is the default time to wait for the url to respond before it is opened in the browser
+const DefaultOpenTimeout = 2 * time.Minute
+
+// openCheckInterval is the time between two requests while waiting for the url to respond
+const openCheckInterval = time.Second
+
+// GetOpenURL returns the url that should be opened in the browser or an empty string if nothing should be opened.
+// openFlag is the value of the --open flag: "false" disables opening, "true" opens the url of devspace.open (or the
+// first forwarded port), an empty value does the same only if devspace.open is configured and every other value is
+// used as url or forwarded local port
+func GetOpenURL(openFlag string) (string, error) {
+	config := configutil.GetConfig()
+	openConfig := config.DevSpace.Open
+
+	switch openFlag {
+	case "false":
+		return "", nil
+	case "":
+		if openConfig == nil {
+			return "", nil
+		}
+	case "true":
+	default:
+		port, err := strconv.Atoi(openFlag)
+		if err != nil {
+			return openFlag, nil
+		}
+
+		return getLocalURL(port)
+	}
+
+	if openConfig != nil && openConfig.URL != nil && *openConfig.URL != "" {
+		return *openConfig.URL, nil
+	}
+
+	port := 0
+	if openConfig != nil && openConfig.Port != nil {
+		port = *openConfig.Port
+	}
+
+	return getLocalURL(port)
+}
+
+// getLocalURL returns the local url of the forwarded port. If the port is forwarded from another local port (e.g.
+// because of --auto-port), the actual local port is used. If port is 0, the first forwarded port is used
+func getLocalURL(port int) (string, error) {
+	config := configutil.GetConfig()
+
+	if config.DevSpace.Ports != nil {
+		for _, portForwarding := range *config.DevSpace.Ports {
+			if portForwarding.Reverse != nil && *portForwarding.Reverse {
+				continue
+			}
+			if portForwarding.PortMappings == nil {
+				continue
+			}
+
+			for _, portMapping := range *portForwarding.PortMappings {
+				if port == 0 || (portMapping.LocalPort != nil && *portMapping.LocalPort == port) {
+					return "http://localhost:" + strconv.Itoa(getLocalPort(portMapping)), nil
+				}
+			}
+		}
+	}
+
+	if port == 0 {
+		return "", fmt.Errorf("Unable to determine the url to open, because no port forwarding is configured. Please specify devspace.open.url in the config or use --open=URL")
+	}
+
+	return "http://localhost:" + strconv.Itoa(port), nil
+}
+
+// OpenURL waits until the url responds with a status code below 500 and opens it in the default browser, so we
+// don't open a page of an application that is still starting
+func OpenURL(url string, timeout time.Duration, log log.Logger) error {
+	if strings.HasPrefix(url, "http://") == false && strings.HasPrefix(url, "https://") == false {
+		url = "http://" + url
+	}
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+
+			if resp.StatusCode < http.StatusInternalServerError {
+				break
+			}
+
+			err = fmt.Errorf("Status code %d", resp.StatusCode)
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s didn't respond in time: %v", url, err)
+		}
+
+		time.Sleep(openCheckInterval)
+	}
+
+	err := open.Start(url)
+	if err != nil {
+		return fmt.Errorf("Unable to open browser: %v", err)
+	}
+
+	log.Donef("Opened %s in the browser", url)
+	return nil
+}
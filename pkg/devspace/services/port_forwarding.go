@@ -1,85 +1,426 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
 	"github.com/covexo/devspace/pkg/devspace/kubectl"
 	"github.com/covexo/devspace/pkg/util/log"
 )
 
-// StartPortForwarding starts the port forwarding functionality
-func StartPortForwarding(client *kubernetes.Clientset, log log.Logger) error {
+// DefaultMaxConcurrentPortForwards is the default number of port forwardings that are established at the same time
+const DefaultMaxConcurrentPortForwards = 5
+
+// DefaultPortForwardingTimeout is the default time to wait for a port forwarding to get ready
+const DefaultPortForwardingTimeout = 30 * time.Second
+
+// maxPortForwardingJitter is the maximum random delay before a port forwarding is started, so that multiple port
+// forwardings don't hit the api server at exactly the same time
+const maxPortForwardingJitter = 500 * time.Millisecond
+
+// portForwardingStopChan is closed by StopPortForwarding to stop all port forwardings of this process
+var portForwardingStopChan = make(chan struct{})
+var stopPortForwardingOnce sync.Once
+
+// StartPortForwarding starts the port forwarding functionality. At most maxConcurrent port forwardings are
+// established at the same time. Port forwardings that don't get ready within timeout are skipped with a warning.
+// Port mappings whose local port is used by an earlier port mapping are skipped with a warning. Local ports that are
+// already in use by another process are replaced with free ports if autoPort is true
+func StartPortForwarding(client kubernetes.Interface, podCache *kubectl.PodCache, maxConcurrent int, timeout time.Duration, autoPort bool, log log.Logger) error {
 	config := configutil.GetConfig()
 
-	if config.DevSpace.Ports != nil {
-		for _, portForwarding := range *config.DevSpace.Ports {
-			if portForwarding.ResourceType == nil || *portForwarding.ResourceType == "pod" {
-				var labelSelector map[string]*string
-				namespace := ""
-
-				if portForwarding.Service != nil {
-					service, err := configutil.GetService(*portForwarding.Service)
-					if err != nil {
-						log.Fatalf("Error resolving service name: %v", err)
-					}
-
-					labelSelector = *service.LabelSelector
-					if service.Namespace != nil && *service.Namespace != "" {
-						namespace = *service.Namespace
-					}
+	if config.DevSpace.Ports == nil || len(*config.DevSpace.Ports) == 0 {
+		return nil
+	}
+
+	// Validate all port mappings before the first port forwarding is started
+	err := ValidatePortForwarding(*config.DevSpace.Ports, log)
+	if err != nil {
+		return err
+	}
+
+	// Port mappings with a local port of an earlier port mapping would fail to listen, ValidatePortForwarding warned
+	// about them already
+	portForwardings, _ := skipDuplicateLocalPorts(*config.DevSpace.Ports)
+
+	// Check all local ports upfront, otherwise a port in use would only show up as timeout
+	err = resolveLocalPorts(portForwardings, autoPort, log)
+	if err != nil {
+		return err
+	}
+
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+
+	total := 0
+	for _, portForwarding := range portForwardings {
+		if len(*portForwarding.PortMappings) > 0 {
+			total++
+		}
+	}
+
+	started := 0
+	semaphore := make(chan struct{}, maxConcurrent)
+	waitGroup := sync.WaitGroup{}
+	mutex := sync.Mutex{}
+
+	log.StartWait(fmt.Sprintf("Starting port forwarding (0/%d)", total))
+	defer log.StopWait()
+
+	for _, portForwarding := range portForwardings {
+		if len(*portForwarding.PortMappings) == 0 {
+			continue
+		}
+
+		semaphore <- struct{}{}
+
+		// Don't start any more port forwardings if one has failed
+		mutex.Lock()
+		failed := err != nil
+		mutex.Unlock()
+		if failed {
+			<-semaphore
+			break
+		}
+
+		waitGroup.Add(1)
+		go func(portForwarding *v1.PortForwardingConfig) {
+			defer waitGroup.Done()
+
+			if total > 1 {
+				time.Sleep(time.Duration(rand.Int63n(int64(maxPortForwardingJitter))))
+			}
+
+			startErr := startPortForwarding(client, podCache, portForwarding, timeout, log)
+			<-semaphore
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			started++
+			if startErr != nil && err == nil {
+				err = startErr
+			}
+
+			log.StartWait(fmt.Sprintf("Starting port forwarding (%d/%d)", started, total))
+		}(portForwarding)
+	}
+
+	waitGroup.Wait()
+	if err != nil {
+		os.Remove(PortForwardingPortsFile)
+		return err
+	}
+
+	// Remember this process once the port forwarding is running, so that devspace down can stop it
+	err = writePortForwardingPid()
+	if err != nil {
+		log.Warnf("Unable to write %s: %v", PortForwardingPidFile, err)
+	}
+
+	return nil
+}
+
+// StopPortForwarding stops all port forwardings and reverse port forwardings that were started by this process
+func StopPortForwarding() {
+	stopPortForwardingOnce.Do(func() {
+		close(portForwardingStopChan)
+	})
+
+	StopReversePortForwarding()
+}
+
+// waitForRestart waits before a port forwarding is restarted and returns false if the port forwarding was stopped
+// in the meantime
+func waitForRestart() bool {
+	select {
+	case <-portForwardingStopChan:
+		return false
+	case <-time.After(5 * time.Second):
+		return true
+	}
+}
+
+// startPortForwarding establishes a single configured port forwarding
+func startPortForwarding(client kubernetes.Interface, podCache *kubectl.PodCache, portForwarding *v1.PortForwardingConfig, timeout time.Duration, log log.Logger) error {
+	var bindAddresses []string
+	if portForwarding.BindAddresses != nil {
+		bindAddresses = *portForwarding.BindAddresses
+	}
+
+	if portForwarding.ResourceType == nil || *portForwarding.ResourceType == "pod" {
+		target, err := resolvePodTarget(portForwarding.PodName, portForwarding.Service, portForwarding.LabelSelector, portForwarding.Namespace, nil)
+		if err != nil {
+			return err
+		}
+
+		pod, err := podCache.GetPod(target.podName, target.labelSelector, target.namespace)
+		if err != nil {
+			return fmt.Errorf("Unable to list devspace pods: %s", err.Error())
+		} else if pod != nil && portForwarding.Reverse != nil && *portForwarding.Reverse {
+			return startReversePortForwarding(client, pod, target.containerName, *portForwarding.PortMappings, timeout, log)
+		} else if pod != nil {
+			return startPodPortForwarding(client, pod, target.podName, target.labelSelector, *portForwarding.PortMappings, bindAddresses, timeout, log)
+		}
+	} else if portForwarding.Reverse != nil && *portForwarding.Reverse {
+		log.Warnf("Reverse port forwarding is only supported for resource type pod")
+	} else if *portForwarding.ResourceType == "service" || *portForwarding.ResourceType == "deployment" {
+		return startResourcePortForwarding(client, portForwarding, bindAddresses, timeout, log)
+	} else {
+		log.Warnf("Resource type %s is not supported for port forwarding (supported: pod, service, deployment)", *portForwarding.ResourceType)
+	}
+
+	return nil
+}
+
+// startPodPortForwarding forwards the ports to the pod and watches the pod. If the pod is deleted, replaced or stops
+// running, the pod is resolved again by its name or label selector and the port forwarding is restarted
+func startPodPortForwarding(client kubernetes.Interface, pod *k8sv1.Pod, podName string, labelSelector map[string]*string, portMappings []*v1.PortMapping, bindAddresses []string, timeout time.Duration, log log.Logger) error {
+	ports := getPortMappings(portMappings)
+	namespace := pod.Namespace
+	readyChan := make(chan struct{})
+
+	go func(pod *k8sv1.Pod, readyChan chan struct{}) {
+		for {
+			stopChan := make(chan struct{})
+			ctx, cancel := context.WithCancel(context.Background())
+
+			go func(pod *k8sv1.Pod) {
+				waitForPodReplaced(ctx, client, pod, log)
+				close(stopChan)
+			}(pod)
+			go func() {
+				select {
+				case <-portForwardingStopChan:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+
+			err := kubectl.ForwardPorts(client, pod, ports, bindAddresses, stopChan, readyChan)
+			cancel()
+			if err != nil {
+				log.Errorf("Error port forwarding to pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+
+			// The pod was replaced or the connection to it was lost, so we wait for the next running pod
+			warned := false
+			for {
+				if waitForRestart() == false {
+					return
+				}
+
+				var newPod *k8sv1.Pod
+				if podName != "" {
+					newPod, err = kubectl.GetRunningPodByName(client, podName, namespace)
 				} else {
-					labelSelector = *portForwarding.LabelSelector
-					if portForwarding.Namespace != nil && *portForwarding.Namespace != "" {
-						namespace = *portForwarding.Namespace
-					}
+					newPod, err = kubectl.GetNewestRunningPod(client, kubectl.LabelSelectorToString(labelSelector), namespace)
+				}
+				if err == nil {
+					pod = newPod
+					break
 				}
 
-				labels := make([]string, 0, len(labelSelector)-1)
-				for key, value := range labelSelector {
-					labels = append(labels, key+"="+*value)
+				if warned == false {
+					log.Warnf("Unable to restart port forwarding on %s: %v. Retrying until a pod is running again", strings.Join(ports, ", "), err)
+					warned = true
+				}
+			}
+
+			log.Infof("Restarting port forwarding on %s (Pod: %s/%s)", strings.Join(ports, ", "), pod.Namespace, pod.Name)
+			readyChan = make(chan struct{})
+		}
+	}(pod, readyChan)
+
+	// Wait till forwarding is ready
+	select {
+	case <-readyChan:
+		log.Donef("Port forwarding started on %s", strings.Join(ports, ", "))
+	case <-time.After(timeout):
+		log.Warnf("Port forwarding on %s didn't get ready within %v. Continuing without waiting for it", strings.Join(ports, ", "), timeout)
+
+		go func() {
+			<-readyChan
+			log.Donef("Port forwarding started on %s", strings.Join(ports, ", "))
+		}()
+	}
+
+	return nil
+}
+
+// waitForPodReplaced blocks until the pod is deleted, replaced by a pod with the same name or not running anymore.
+// It returns immediately if the context is cancelled
+func waitForPodReplaced(ctx context.Context, client kubernetes.Interface, pod *k8sv1.Pod, log log.Logger) {
+	for ctx.Err() == nil {
+		podChan, err := kubectl.WatchPod(ctx, client, pod.Namespace, pod.Name)
+		if err != nil {
+			log.Warnf("%v. Port forwarding won't be restarted if the pod is replaced", err)
+			<-ctx.Done()
+			return
+		}
+
+		for currentPod := range podChan {
+			if isPodReplaced(pod, currentPod) {
+				return
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// The watch ended, which happens if the pod was deleted or the api server closed the watch
+		currentPod, err := client.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+		if err != nil || isPodReplaced(pod, currentPod) {
+			return
+		}
+	}
+}
+
+// isPodReplaced checks if the current state of the pod is a different pod or a pod that is not running anymore
+func isPodReplaced(pod, currentPod *k8sv1.Pod) bool {
+	return currentPod.UID != pod.UID || currentPod.DeletionTimestamp != nil || kubectl.GetPodStatus(currentPod) != "Running"
+}
+
+// startResourcePortForwarding forwards the ports to a pod of a kubernetes service or deployment. If the pod goes away,
+// the pod is resolved again and the port forwarding is restarted
+func startResourcePortForwarding(client kubernetes.Interface, portForwarding *v1.PortForwardingConfig, bindAddresses []string, timeout time.Duration, log log.Logger) error {
+	resourceType := *portForwarding.ResourceType
+	if portForwarding.ResourceName == nil || *portForwarding.ResourceName == "" {
+		return fmt.Errorf("Port forwarding with resourceType %s requires resourceName", resourceType)
+	}
+
+	resourceName := *portForwarding.ResourceName
+	namespace := ""
+	if portForwarding.Namespace != nil {
+		namespace = *portForwarding.Namespace
+	}
+
+	pod, ports, err := getResourcePodAndPorts(client, resourceType, resourceName, namespace, *portForwarding.PortMappings)
+	if err != nil {
+		return fmt.Errorf("Unable to start port forwarding: %v", err)
+	}
+
+	readyChan := make(chan struct{})
+
+	go func(pod *k8sv1.Pod, ports []string, readyChan chan struct{}) {
+		for {
+			err := kubectl.ForwardPorts(client, pod, ports, bindAddresses, portForwardingStopChan, readyChan)
+			if err != nil {
+				log.Errorf("Error port forwarding to %s %s: %v", resourceType, resourceName, err)
+			}
+
+			// The connection to the pod was lost, so we wait for the next ready pod
+			warned := false
+			for {
+				if waitForRestart() == false {
+					return
+				}
+
+				pod, ports, err = getResourcePodAndPorts(client, resourceType, resourceName, namespace, *portForwarding.PortMappings)
+				if err == nil {
+					break
 				}
 
-				log.StartWait("Waiting for pods to become running")
-				pod, err := kubectl.GetNewestRunningPod(client, strings.Join(labels, ", "), namespace)
-				log.StopWait()
-
-				if err != nil {
-					return fmt.Errorf("Unable to list devspace pods: %s", err.Error())
-				} else if pod != nil {
-					ports := make([]string, len(*portForwarding.PortMappings))
-
-					for index, value := range *portForwarding.PortMappings {
-						ports[index] = strconv.Itoa(*value.LocalPort) + ":" + strconv.Itoa(*value.RemotePort)
-					}
-
-					readyChan := make(chan struct{})
-
-					go func() {
-						err := kubectl.ForwardPorts(client, pod, ports, make(chan struct{}), readyChan)
-						if err != nil {
-							log.Errorf("Error starting port forwarding: %v", err)
-						}
-					}()
-
-					// Wait till forwarding is ready
-					select {
-					case <-readyChan:
-						log.Donef("Port forwarding started on %s", strings.Join(ports, ", "))
-					case <-time.After(20 * time.Second):
-						return fmt.Errorf("Timeout waiting for port forwarding to start")
-					}
+				if warned == false {
+					log.Warnf("Unable to restart port forwarding to %s %s: %v. Retrying until it is ready again", resourceType, resourceName, err)
+					warned = true
 				}
-			} else {
-				log.Warn("Currently only pod resource type is supported for portforwarding")
 			}
+
+			log.Infof("Restarting port forwarding to %s %s (Pod: %s/%s)", resourceType, resourceName, pod.Namespace, pod.Name)
+			readyChan = make(chan struct{})
 		}
+	}(pod, ports, readyChan)
+
+	// Wait till forwarding is ready
+	select {
+	case <-readyChan:
+		log.Donef("Port forwarding to %s %s started on %s (Pod: %s/%s)", resourceType, resourceName, strings.Join(ports, ", "), pod.Namespace, pod.Name)
+	case <-time.After(timeout):
+		log.Warnf("Port forwarding to %s %s didn't get ready within %v. Continuing without waiting for it", resourceType, resourceName, timeout)
 	}
 
 	return nil
 }
+
+// getResourcePodAndPorts returns a pod of the service or deployment and the port mappings for this pod. Remote ports
+// of services are service ports and are translated to the target ports of the pod
+func getResourcePodAndPorts(client kubernetes.Interface, resourceType, resourceName, namespace string, portMappings []*v1.PortMapping) (*k8sv1.Pod, []string, error) {
+	if resourceType == "deployment" {
+		pod, err := kubectl.GetPodForDeployment(client, resourceName, namespace)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return pod, getPortMappings(portMappings), nil
+	}
+
+	service, pod, err := kubectl.GetPodForService(client, resourceName, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ports := make([]string, len(portMappings))
+	for index, value := range portMappings {
+		targetPort, err := kubectl.GetServiceTargetPort(service, pod, *value.RemotePort)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ports[index] = strconv.Itoa(getLocalPort(value)) + ":" + strconv.Itoa(targetPort)
+	}
+
+	return pod, ports, nil
+}
+
+// GetMappingPortForwarding returns a port forwarding with the given port mappings to the target of the first
+// configured port forwarding. Without configured port forwardings the pods of the first helm deployment are targeted
+func GetMappingPortForwarding(portMappings []*v1.PortMapping) *v1.PortForwardingConfig {
+	config := configutil.GetConfig()
+	portForwarding := &v1.PortForwardingConfig{
+		PortMappings: &portMappings,
+	}
+
+	if config.DevSpace.Ports == nil || len(*config.DevSpace.Ports) == 0 {
+		portForwarding.LabelSelector = &map[string]*string{
+			"release": configutil.String(GetNameOfFirstHelmDeployment()),
+		}
+
+		return portForwarding
+	}
+
+	target := (*config.DevSpace.Ports)[0]
+	portForwarding.Service = target.Service
+	portForwarding.Namespace = target.Namespace
+	portForwarding.ResourceType = target.ResourceType
+	portForwarding.ResourceName = target.ResourceName
+	portForwarding.LabelSelector = target.LabelSelector
+	portForwarding.PodName = target.PodName
+	portForwarding.BindAddresses = target.BindAddresses
+
+	return portForwarding
+}
+
+func getPortMappings(portMappings []*v1.PortMapping) []string {
+	ports := make([]string, len(portMappings))
+
+	for index, value := range portMappings {
+		ports[index] = strconv.Itoa(getLocalPort(value)) + ":" + strconv.Itoa(*value.RemotePort)
+	}
+
+	return ports
+}
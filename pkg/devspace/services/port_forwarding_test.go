@@ -0,0 +1,157 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/util/log"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStartPortForwarding(t *testing.T) {
+	pendingPod := &k8sv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pending",
+			Namespace: "test",
+		},
+		Status: k8sv1.PodStatus{
+			Phase: k8sv1.PodPending,
+		},
+	}
+	client := fake.NewSimpleClientset(pendingPod)
+
+	localPort, remotePort := 8080, 80
+	portMappings := &[]*v1.PortMapping{
+		{
+			LocalPort:  &localPort,
+			RemotePort: &remotePort,
+		},
+	}
+
+	testCases := map[string]struct {
+		portForwarding *v1.PortForwardingConfig
+		expectError    bool
+	}{
+		"missing pod": {
+			portForwarding: &v1.PortForwardingConfig{
+				PodName:      configutil.String("missing"),
+				Namespace:    configutil.String("test"),
+				PortMappings: portMappings,
+			},
+			expectError: true,
+		},
+		"pod not running": {
+			portForwarding: &v1.PortForwardingConfig{
+				PodName:      configutil.String("pending"),
+				Namespace:    configutil.String("test"),
+				PortMappings: portMappings,
+			},
+			expectError: true,
+		},
+		"unsupported resource type": {
+			portForwarding: &v1.PortForwardingConfig{
+				ResourceType: configutil.String("statefulset"),
+				ResourceName: configutil.String("database"),
+				PortMappings: portMappings,
+			},
+		},
+		"reverse port forwarding to service": {
+			portForwarding: &v1.PortForwardingConfig{
+				ResourceType: configutil.String("service"),
+				ResourceName: configutil.String("api"),
+				Reverse:      configutil.Bool(true),
+				PortMappings: portMappings,
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		podCache := kubectl.NewPodCache(client)
+
+		err := startPortForwarding(client, podCache, testCase.portForwarding, time.Second, log.GetInstance())
+		if testCase.expectError && err == nil {
+			t.Fatalf("Test case %s: expected error", name)
+		} else if testCase.expectError == false && err != nil {
+			t.Fatalf("Test case %s: unexpected error %v", name, err)
+		}
+	}
+}
+
+func TestValidatePortForwardingWithoutLocalPort(t *testing.T) {
+	remotePort := 80
+	portForwarding := &v1.PortForwardingConfig{
+		LabelSelector: &map[string]*string{"release": configutil.String("api")},
+		PortMappings: &[]*v1.PortMapping{
+			{RemotePort: &remotePort},
+			{LocalPort: new(int), RemotePort: &remotePort},
+		},
+	}
+
+	err := ValidatePortForwarding([]*v1.PortForwardingConfig{portForwarding}, log.GetInstance())
+	if err != nil {
+		t.Fatalf("Expected port mappings without local port to be valid, got %v", err)
+	}
+	if localPort := getLocalPort((*portForwarding.PortMappings)[0]); localPort != 0 {
+		t.Fatalf("Expected unresolved local port 0, got %d", localPort)
+	}
+
+	portForwarding.Reverse = configutil.Bool(true)
+	err = ValidatePortForwarding([]*v1.PortForwardingConfig{portForwarding}, log.GetInstance())
+	if err == nil {
+		t.Fatal("Expected error for reverse port forwarding without local port")
+	}
+}
+
+func TestSkipDuplicateLocalPorts(t *testing.T) {
+	webPort, apiPort, debugPort := 8080, 3000, 9229
+	portForwardings := []*v1.PortForwardingConfig{
+		{
+			Service: configutil.String("web"),
+			PortMappings: &[]*v1.PortMapping{
+				{LocalPort: &webPort, RemotePort: &webPort},
+				{LocalPort: &debugPort, RemotePort: &debugPort},
+			},
+		},
+		{
+			Service: configutil.String("api"),
+			PortMappings: &[]*v1.PortMapping{
+				{LocalPort: &apiPort, RemotePort: &apiPort},
+				{LocalPort: &debugPort, RemotePort: &debugPort},
+			},
+		},
+		{
+			Service: configutil.String("worker"),
+			PortMappings: &[]*v1.PortMapping{
+				{LocalPort: &webPort, RemotePort: &webPort},
+			},
+		},
+	}
+
+	filtered, conflicts := skipDuplicateLocalPorts(portForwardings)
+	expectedConflicts := []string{
+		"local port 9229 of devspace.ports[1] (service api) is already used by devspace.ports[0] (service web)",
+		"local port 8080 of devspace.ports[2] (service worker) is already used by devspace.ports[0] (service web)",
+	}
+	if reflect.DeepEqual(conflicts, expectedConflicts) == false {
+		t.Fatalf("Expected conflicts %v, got %v", expectedConflicts, conflicts)
+	}
+
+	if len(filtered) != 3 || filtered[0] != portForwardings[0] {
+		t.Fatalf("Expected the first port forwarding to be kept, got %v", filtered)
+	}
+	if len(*filtered[1].PortMappings) != 1 || *(*filtered[1].PortMappings)[0].LocalPort != apiPort {
+		t.Fatalf("Expected only local port %d of the second port forwarding, got %v", apiPort, *filtered[1].PortMappings)
+	}
+	if len(*filtered[2].PortMappings) != 0 {
+		t.Fatalf("Expected no port mappings of the third port forwarding, got %v", *filtered[2].PortMappings)
+	}
+	if len(*portForwardings[1].PortMappings) != 2 {
+		t.Fatal("The port mappings of the config were changed")
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// PortForwardingPidFile holds the process id and the executable of the devspace process that forwards the ports (and
+// syncs the files) of the project, so that devspace down can stop it
+const PortForwardingPidFile = ".devspace/portforwarding.pid"
+
+// writePortForwardingPid writes the id and the executable of the current process to the pid file
+func writePortForwardingPid() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(PortForwardingPidFile, []byte(strconv.Itoa(os.Getpid())+"\n"+executable+"\n"), 0644)
+}
+
+// RemovePortForwardingPid removes the pid file if it was written by the current process
+func RemovePortForwardingPid() {
+	pid, _, err := readPortForwardingPid()
+	if err == nil && pid == os.Getpid() {
+		os.Remove(PortForwardingPidFile)
+		os.Remove(PortForwardingPortsFile)
+	}
+}
+
+// StopPortForwardingProcess stops the devspace process that forwards the ports of the project and returns its
+// process id. Returns 0 if no such process is running. A process whose executable differs from the one in the pid
+// file is not stopped, because the pid file is stale and the process id was reused by another process
+func StopPortForwardingProcess() (int, error) {
+	pid, executable, err := readPortForwardingPid()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	// The pid file is stale or belongs to us
+	defer os.Remove(PortForwardingPidFile)
+	defer os.Remove(PortForwardingPortsFile)
+	if pid == os.Getpid() || isProcessExecutable(pid, executable) == false {
+		return 0, nil
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return 0, nil
+	}
+
+	err = process.Signal(os.Interrupt)
+	if err != nil {
+		if strings.Contains(err.Error(), "process already finished") {
+			return 0, nil
+		}
+
+		// Interrupts are not supported on windows
+		err = process.Kill()
+		if err != nil {
+			return 0, nil
+		}
+	}
+
+	return pid, nil
+}
+
+// readPortForwardingPid returns the process id and the executable of the pid file. The executable is empty if the
+// pid file was written by an older version of devspace
+func readPortForwardingPid() (int, string, error) {
+	data, err := ioutil.ReadFile(PortForwardingPidFile)
+	if err != nil {
+		return 0, "", err
+	}
+
+	lines := strings.SplitN(strings.TrimSpace(string(data)), "\n", 2)
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return 0, "", err
+	}
+	if len(lines) < 2 {
+		return pid, "", nil
+	}
+
+	return pid, strings.TrimSpace(lines[1]), nil
+}
+
+// isProcessExecutable checks if the process with the given id is running the executable. Returns false if the
+// process doesn't exist or its executable can't be determined
+func isProcessExecutable(pid int, executable string) bool {
+	if executable == "" {
+		return false
+	}
+
+	processExecutable, err := getProcessExecutable(pid)
+	if err != nil || processExecutable == "" {
+		return false
+	}
+
+	if runtime.GOOS == "linux" {
+		// The executable was replaced while the process was running, e.g. by an update of devspace
+		return strings.TrimSuffix(processExecutable, " (deleted)") == executable
+	}
+
+	// ps and tasklist don't always return the full path
+	return strings.EqualFold(filepath.Base(processExecutable), filepath.Base(executable))
+}
+
+// getProcessExecutable returns the executable of the process with the given id
+func getProcessExecutable(pid int) (string, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return os.Readlink("/proc/" + strconv.Itoa(pid) + "/exe")
+	case "windows":
+		out, err := exec.Command("tasklist", "/FI", "PID eq "+strconv.Itoa(pid), "/FO", "CSV", "/NH").Output()
+		if err != nil {
+			return "", err
+		}
+
+		// The output is "devspace.exe","1234",... or an info message if the process doesn't exist
+		fields := strings.Split(strings.TrimSpace(string(out)), ",")
+		if len(fields) < 2 || strings.Trim(fields[1], "\"") != strconv.Itoa(pid) {
+			return "", errors.New("Process not found")
+		}
+
+		return strings.Trim(fields[0], "\""), nil
+	default:
+		out, err := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "comm=").Output()
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimSpace(string(out)), nil
+	}
+}
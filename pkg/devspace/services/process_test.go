@@ -0,0 +1,66 @@
+package services
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestPortForwardingPid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devspace-pid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	err = os.Chdir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Mkdir(".devspace", 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = writePortForwardingPid()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid, executable, err := readPortForwardingPid()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid != os.Getpid() {
+		t.Fatalf("Expected pid %d, got %d", os.Getpid(), pid)
+	}
+	if isProcessExecutable(pid, executable) == false {
+		t.Fatalf("Expected process %d to run %s", pid, executable)
+	}
+	if isProcessExecutable(pid, "/usr/bin/not-devspace") {
+		t.Fatal("Expected a process with another executable not to match")
+	}
+
+	// Pid files of older versions only contain the pid, the process can't be verified
+	err = ioutil.WriteFile(PortForwardingPidFile, []byte("1"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stoppedPid, err := StopPortForwardingProcess()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stoppedPid != 0 {
+		t.Fatalf("Expected the unverified process %d not to be stopped", stoppedPid)
+	}
+	if _, err := os.Stat(PortForwardingPidFile); os.IsNotExist(err) == false {
+		t.Fatal("Expected the stale pid file to be removed")
+	}
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/devspace/tunnel"
+	"github.com/covexo/devspace/pkg/devspace/upgrade"
+	"github.com/covexo/devspace/pkg/util/hash"
+	"github.com/covexo/devspace/pkg/util/log"
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// tunnelBinaryPath is the path prefix of the devspace binary inside the container that runs the remote side of
+// reverse port forwardings
+const tunnelBinaryPath = "/tmp/devspace-tunnel-"
+
+// reverseTunnels holds the stdin of all running reverse tunnels. Closing it stops the tunnel in the container
+var reverseTunnels = []io.WriteCloser{}
+var reverseTunnelsMutex sync.Mutex
+
+// startReversePortForwarding opens a listener on the remote port of every port mapping inside the container and
+// tunnels the connections to the local port on this machine
+func startReversePortForwarding(client kubernetes.Interface, pod *k8sv1.Pod, containerName string, portMappings []*v1.PortMapping, timeout time.Duration, log log.Logger) error {
+	if containerName == "" {
+		containerName = pod.Spec.Containers[0].Name
+	}
+
+	binaryPath, err := injectTunnelBinary(client, pod, containerName)
+	if err != nil {
+		return fmt.Errorf("Unable to inject tunnel binary: %v", err)
+	}
+
+	ports := make([]string, len(portMappings))
+	for index, value := range portMappings {
+		localPort := strconv.Itoa(*value.LocalPort)
+		remotePort := strconv.Itoa(*value.RemotePort)
+
+		err := startReverseTunnel(client, pod, containerName, binaryPath, localPort, remotePort, timeout, log)
+		if err != nil {
+			return err
+		}
+
+		ports[index] = "remote:" + remotePort + " -> localhost:" + localPort
+	}
+
+	log.Donef("Reverse port forwarding started on %s (Pod: %s/%s)", strings.Join(ports, ", "), pod.Namespace, pod.Name)
+	return nil
+}
+
+// startReverseTunnel starts the tunnel command in the container and waits until it listens on the remote port
+func startReverseTunnel(client kubernetes.Interface, pod *k8sv1.Pod, containerName, binaryPath, localPort, remotePort string, timeout time.Duration, log log.Logger) error {
+	errorChan := make(chan error, 1)
+
+	stdin, stdout, stderr, err := kubectl.Exec(client, pod, containerName, []string{binaryPath, "tunnel", "--port", remotePort}, false, errorChan)
+	if err != nil {
+		return fmt.Errorf("Unable to start reverse port forwarding for port %s: %v", remotePort, err)
+	}
+
+	// The tunnel reports errors (e.g. port already in use) on stderr
+	stderrBuffer := &syncBuffer{}
+	go io.Copy(stderrBuffer, stderr)
+
+	readyChan := make(chan struct{})
+	doneChan := make(chan error, 1)
+
+	go func() {
+		doneChan <- tunnel.Connect("localhost:"+localPort, stdout, stdin, readyChan)
+	}()
+
+	select {
+	case <-readyChan:
+	case err := <-doneChan:
+		stdin.Close()
+		return fmt.Errorf("Unable to start reverse port forwarding for port %s: %v %s", remotePort, err, stderrBuffer.String())
+	case <-time.After(timeout):
+		stdin.Close()
+		return fmt.Errorf("Timeout waiting for reverse port forwarding for port %s to start", remotePort)
+	}
+
+	reverseTunnelsMutex.Lock()
+	reverseTunnels = append(reverseTunnels, stdin)
+	reverseTunnelsMutex.Unlock()
+
+	go func() {
+		err := <-doneChan
+		if err != nil {
+			log.Errorf("Error in reverse port forwarding for port %s: %v %s", remotePort, err, stderrBuffer.String())
+		}
+
+		<-errorChan
+	}()
+
+	return nil
+}
+
+// StopReversePortForwarding stops all reverse tunnels and the listeners in the containers
+func StopReversePortForwarding() {
+	reverseTunnelsMutex.Lock()
+	defer reverseTunnelsMutex.Unlock()
+
+	for _, stdin := range reverseTunnels {
+		stdin.Close()
+	}
+
+	reverseTunnels = []io.WriteCloser{}
+}
+
+// injectTunnelBinary copies a linux devspace binary into the container if it isn't there yet and returns its path
+func injectTunnelBinary(client kubernetes.Interface, pod *k8sv1.Pod, containerName string) (string, error) {
+	stdout, stderr, err := kubectl.ExecBuffered(client, pod, containerName, []string{"uname", "-m"})
+	if err != nil {
+		return "", err
+	}
+
+	arch := strings.TrimSpace(string(stdout))
+	if arch != "x86_64" {
+		return "", fmt.Errorf("Reverse port forwarding is only supported for x86_64 containers (container architecture: %s %s)", arch, string(stderr))
+	}
+
+	localPath, err := getLinuxBinary()
+	if err != nil {
+		return "", err
+	}
+
+	digest, err := hash.File(localPath)
+	if err != nil {
+		return "", err
+	}
+
+	// The binary path contains the digest, so we only upload it once per version
+	remotePath := tunnelBinaryPath + digest[:12]
+
+	stdout, _, err = kubectl.ExecBuffered(client, pod, containerName, []string{"sh", "-c", "test -x " + remotePath + " && echo exists"})
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(string(stdout)) == "exists" {
+		return remotePath, nil
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	errorChan := make(chan error, 1)
+	stdin, stdoutReader, stderrReader, err := kubectl.Exec(client, pod, containerName, []string{"sh", "-c", "cat > " + remotePath + ".tmp && chmod +x " + remotePath + ".tmp && mv " + remotePath + ".tmp " + remotePath}, false, errorChan)
+	if err != nil {
+		return "", err
+	}
+
+	stderrBuffer := &syncBuffer{}
+	go io.Copy(ioutil.Discard, stdoutReader)
+	go io.Copy(stderrBuffer, stderrReader)
+
+	_, err = io.Copy(stdin, file)
+	stdin.Close()
+	if err != nil {
+		return "", fmt.Errorf("Error uploading %s: %v", localPath, err)
+	}
+
+	err = <-errorChan
+	if err != nil {
+		return "", fmt.Errorf("Error uploading %s: %v %s", localPath, err, stderrBuffer.String())
+	}
+
+	return remotePath, nil
+}
+
+// getLinuxBinary returns the path of a linux amd64 devspace binary. If we don't run on linux amd64, the binary of
+// the current version is downloaded once
+func getLinuxBinary() (string, error) {
+	if runtime.GOOS == "linux" && runtime.GOARCH == "amd64" {
+		return os.Executable()
+	}
+
+	version := upgrade.GetVersion()
+	if version == "" {
+		return "", fmt.Errorf("Reverse port forwarding requires a linux amd64 devspace binary, but this binary was built without version information")
+	}
+
+	homedir, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+
+	binaryPath := filepath.Join(homedir, ".devspace", "bin", "devspace-linux-amd64-"+version)
+	_, err = os.Stat(binaryPath)
+	if err == nil {
+		return binaryPath, nil
+	}
+
+	err = upgrade.DownloadReleaseBinary("linux-amd64", binaryPath)
+	if err != nil {
+		return "", fmt.Errorf("Error downloading linux amd64 devspace binary: %v", err)
+	}
+
+	return binaryPath, nil
+}
+
+// syncBuffer is a string buffer that can be written and read concurrently
+type syncBuffer struct {
+	buffer strings.Builder
+	mutex  sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return strings.TrimSpace(b.buffer.String())
+}
@@ -3,81 +3,73 @@ package services
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
+	"time"
 
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
 	"github.com/covexo/devspace/pkg/devspace/kubectl"
 	"github.com/covexo/devspace/pkg/devspace/sync"
 	"github.com/covexo/devspace/pkg/util/log"
 )
 
-// StartSync starts the syncing functionality
-func StartSync(client *kubernetes.Clientset, verboseSync bool, log log.Logger) ([]*sync.SyncConfig, error) {
+// StartSync starts the syncing functionality. Errors that stop a sync are sent to fatalErrors
+func StartSync(client kubernetes.Interface, podCache *kubectl.PodCache, verboseSync, allowProtected bool, fatalErrors chan<- error, log log.Logger) ([]*sync.SyncConfig, error) {
+	return StartSyncPaths(client, podCache, nil, false, verboseSync, allowProtected, fatalErrors, log)
+}
+
+// StartSyncPaths starts the sync of all configured sync paths whose localSubPath is contained in pathFilter (all sync
+// paths if pathFilter is empty). If reconnect is true, every sync is resumed on the new pod when its pod is replaced.
+// Errors that stop a sync are sent to fatalErrors (devspace exits on these errors if fatalErrors is nil)
+func StartSyncPaths(client kubernetes.Interface, podCache *kubectl.PodCache, pathFilter []string, reconnect, verboseSync, allowProtected bool, fatalErrors chan<- error, log log.Logger) ([]*sync.SyncConfig, error) {
 	config := configutil.GetConfig()
 	if config.DevSpace.Sync == nil {
 		return []*sync.SyncConfig{}, nil
 	}
 
-	syncConfigs := make([]*sync.SyncConfig, 0, len(*config.DevSpace.Sync))
-	for _, syncPath := range *config.DevSpace.Sync {
+	syncPaths, err := filterSyncPaths(*config.DevSpace.Sync, pathFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pods that already waited for the sync start delay
+	delayedPods := map[string]bool{}
+
+	syncConfigs := make([]*sync.SyncConfig, 0, len(syncPaths))
+	for _, syncPath := range syncPaths {
 		absLocalPath, err := filepath.Abs(*syncPath.LocalSubPath)
 		if err != nil {
 			return nil, fmt.Errorf("Unable to resolve localSubPath %s: %v", *syncPath.LocalSubPath, err)
 		}
 
-		var labelSelector map[string]*string
-		namespace := ""
-		containerName := ""
-
-		if syncPath.Service != nil {
-			service, err := configutil.GetService(*syncPath.Service)
-			if err != nil {
-				log.Fatalf("Error resolving service name: %v", err)
-			}
-
-			labelSelector = *service.LabelSelector
-			if service.Namespace != nil && *service.Namespace != "" {
-				namespace = *service.Namespace
-			}
-
-			if service.ContainerName != nil && *service.ContainerName != "" {
-				containerName = *service.ContainerName
-			}
-		} else {
-			labelSelector = *syncPath.LabelSelector
-			if syncPath.Namespace != nil && *syncPath.Namespace != "" {
-				namespace = *syncPath.Namespace
-			}
-
-			if syncPath.ContainerName != nil && *syncPath.ContainerName != "" {
-				containerName = *syncPath.ContainerName
-			}
-		}
-
-		labels := make([]string, 0, len(labelSelector)-1)
-		for key, value := range labelSelector {
-			labels = append(labels, key+"="+*value)
+		target, err := resolvePodTarget(syncPath.PodName, syncPath.Service, syncPath.LabelSelector, syncPath.Namespace, syncPath.ContainerName)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to start sync of %s: %v", *syncPath.LocalSubPath, err)
 		}
 
 		log.StartWait("Waiting for pods to become running")
-		pod, err := kubectl.GetNewestRunningPod(client, strings.Join(labels, ", "), namespace)
+		pod, err := podCache.GetPod(target.podName, target.labelSelector, target.namespace)
 		log.StopWait()
 		if err != nil {
 			return nil, fmt.Errorf("Unable to list devspace pods: %v", err)
 		} else if pod != nil {
+			err = kubectl.CheckProtectedPod(pod, allowProtected)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to start sync: %v", err)
+			}
+
 			if len(pod.Spec.Containers) == 0 {
 				log.Warnf("Cannot start sync on pod, because selected pod %s/%s has no containers", pod.Namespace, pod.Name)
 				continue
 			}
 
 			container := &pod.Spec.Containers[0]
-			if containerName != "" {
+			if target.containerName != "" {
 				found := false
 
 				for _, c := range pod.Spec.Containers {
-					if c.Name == containerName {
+					if c.Name == target.containerName {
 						container = &c
 						found = true
 						break
@@ -85,18 +77,29 @@ func StartSync(client *kubernetes.Clientset, verboseSync bool, log log.Logger) (
 				}
 
 				if found == false {
-					log.Warnf("Couldn't start sync, because container %s wasn't found in pod %s/%s", containerName, pod.Namespace, pod.Name)
+					log.Warnf("Couldn't start sync, because container %s wasn't found in pod %s/%s", target.containerName, pod.Namespace, pod.Name)
 					continue
 				}
 			}
 
+			// Give the app time to settle, so the initial sync doesn't race with the file operations of its startup
+			podKey := pod.Namespace + "/" + pod.Name
+			if config.DevSpace.SyncStartDelay != nil && *config.DevSpace.SyncStartDelay > 0 && delayedPods[podKey] == false {
+				log.StartWait(fmt.Sprintf("Waiting %d seconds before starting sync in pod %s", *config.DevSpace.SyncStartDelay, podKey))
+				time.Sleep(time.Duration(*config.DevSpace.SyncStartDelay) * time.Second)
+				log.StopWait()
+
+				delayedPods[podKey] = true
+			}
+
 			syncConfig := &sync.SyncConfig{
-				Kubectl:   client,
-				Pod:       pod,
-				Container: container,
-				WatchPath: absLocalPath,
-				DestPath:  *syncPath.ContainerPath,
-				Verbose:   verboseSync,
+				Kubectl:        client,
+				Pod:            pod,
+				Container:      container,
+				WatchPath:      absLocalPath,
+				DestPath:       *syncPath.ContainerPath,
+				Verbose:        verboseSync,
+				AllowProtected: allowProtected,
 			}
 
 			if syncPath.ExcludePaths != nil {
@@ -111,6 +114,52 @@ func StartSync(client *kubernetes.Clientset, verboseSync bool, log log.Logger) (
 				syncConfig.UploadExcludePaths = *syncPath.UploadExcludePaths
 			}
 
+			if syncPath.ConflictPolicy != nil {
+				syncConfig.ConflictPolicy, err = sync.ParseConflictPolicy(*syncPath.ConflictPolicy)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if syncPath.TraceFile != nil {
+				syncConfig.TracePath = *syncPath.TraceFile
+			}
+
+			if syncPath.OnUpload != nil {
+				syncConfig.OnUpload = *syncPath.OnUpload
+			}
+
+			if syncPath.OnDownload != nil {
+				syncConfig.OnDownload = *syncPath.OnDownload
+			}
+
+			if syncPath.OnUploadSignal != nil {
+				syncConfig.OnUploadSignal = *syncPath.OnUploadSignal
+			}
+
+			if syncPath.HookDelay != nil && *syncPath.HookDelay > 0 {
+				syncConfig.HookDelay = time.Duration(*syncPath.HookDelay) * time.Millisecond
+			}
+
+			if syncPath.PollingInterval != nil && *syncPath.PollingInterval > 0 {
+				syncConfig.PollingInterval = time.Duration(*syncPath.PollingInterval) * time.Millisecond
+			}
+
+			if syncPath.FileFilter != nil {
+				syncConfig.FileFilter = *syncPath.FileFilter
+			}
+
+			// Permissions are synced unless disabled explicitly
+			syncConfig.PreservePermissions = syncPath.PreservePermissions == nil || *syncPath.PreservePermissions
+
+			if reconnect || (syncPath.Reconnect != nil && *syncPath.Reconnect) {
+				syncConfig.Reconnect = true
+
+				if target.labelSelector != nil {
+					syncConfig.LabelSelector = kubectl.LabelSelectorToString(target.labelSelector)
+				}
+			}
+
 			if syncPath.BandwidthLimits != nil {
 				if syncPath.BandwidthLimits.Download != nil {
 					syncConfig.DownstreamLimit = *syncPath.BandwidthLimits.Download * 1024
@@ -121,9 +170,16 @@ func StartSync(client *kubernetes.Clientset, verboseSync bool, log log.Logger) (
 				}
 			}
 
+			syncConfig.FatalErrors = fatalErrors
+
 			err = syncConfig.Start()
 			if err != nil {
-				log.Fatalf("Sync error: %s", err.Error())
+				// Don't leave the syncs that were already started running
+				for _, startedConfig := range syncConfigs {
+					startedConfig.Stop(nil)
+				}
+
+				return nil, fmt.Errorf("Unable to start sync of %s: %v", absLocalPath, err)
 			}
 
 			log.Donef("Sync started on %s <-> %s (Pod: %s/%s)", absLocalPath, *syncPath.ContainerPath, pod.Namespace, pod.Name)
@@ -133,3 +189,37 @@ func StartSync(client *kubernetes.Clientset, verboseSync bool, log log.Logger) (
 
 	return syncConfigs, nil
 }
+
+// filterSyncPaths returns the sync paths whose localSubPath is contained in pathFilter. It fails if a path of the
+// filter doesn't match any sync path
+func filterSyncPaths(syncPaths []*v1.SyncConfig, pathFilter []string) ([]*v1.SyncConfig, error) {
+	if len(pathFilter) == 0 {
+		return syncPaths, nil
+	}
+
+	matchedFilters := map[string]bool{}
+	for _, filterPath := range pathFilter {
+		matchedFilters[filepath.Clean(filterPath)] = false
+	}
+
+	filteredPaths := []*v1.SyncConfig{}
+	for _, syncPath := range syncPaths {
+		if syncPath.LocalSubPath == nil {
+			continue
+		}
+
+		localSubPath := filepath.Clean(*syncPath.LocalSubPath)
+		if _, ok := matchedFilters[localSubPath]; ok {
+			filteredPaths = append(filteredPaths, syncPath)
+			matchedFilters[localSubPath] = true
+		}
+	}
+
+	for _, filterPath := range pathFilter {
+		if matchedFilters[filepath.Clean(filterPath)] == false {
+			return nil, fmt.Errorf("No sync path with localSubPath %s found (check `devspace list sync`)", filterPath)
+		}
+	}
+
+	return filteredPaths, nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+)
+
+func TestFilterSyncPaths(t *testing.T) {
+	syncPaths := []*v1.SyncConfig{
+		{LocalSubPath: configutil.String("./")},
+		{LocalSubPath: configutil.String("./src")},
+		{LocalSubPath: configutil.String("assets/")},
+	}
+
+	filteredPaths, err := filterSyncPaths(syncPaths, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filteredPaths) != 3 {
+		t.Fatalf("Expected all 3 sync paths without filter, got %d", len(filteredPaths))
+	}
+
+	filteredPaths, err = filterSyncPaths(syncPaths, []string{"src", "./assets"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filteredPaths) != 2 || filteredPaths[0] != syncPaths[1] || filteredPaths[1] != syncPaths[2] {
+		t.Fatalf("Expected the sync paths ./src and assets/, got %d sync paths", len(filteredPaths))
+	}
+
+	_, err = filterSyncPaths(syncPaths, []string{"src", "docs"})
+	if err == nil {
+		t.Fatal("Expected an error for the unknown path docs")
+	}
+}
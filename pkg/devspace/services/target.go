@@ -0,0 +1,64 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+)
+
+// podTarget selects the pod and container of a sync path or port forwarding
+type podTarget struct {
+	podName       string
+	labelSelector map[string]*string
+	namespace     string
+	containerName string
+}
+
+// resolvePodTarget returns the pod target of a sync path or port forwarding. A pod name is used as is, a service is
+// replaced by the label selector, namespace and container name of the referenced service of devSpace.services. The
+// namespace and container name of the sync path or port forwarding are only used if the service doesn't define them
+func resolvePodTarget(podName, service *string, labelSelector *map[string]*string, namespace, containerName *string) (*podTarget, error) {
+	target := &podTarget{}
+	if namespace != nil {
+		target.namespace = *namespace
+	}
+	if containerName != nil {
+		target.containerName = *containerName
+	}
+
+	if podName != nil && *podName != "" {
+		target.podName = *podName
+		return target, nil
+	}
+
+	if service != nil && *service != "" {
+		if labelSelector != nil && len(*labelSelector) > 0 {
+			return nil, fmt.Errorf("service %s and labelSelector cannot be used together", *service)
+		}
+
+		serviceConfig, err := configutil.GetService(*service)
+		if err != nil {
+			return nil, fmt.Errorf("Error resolving service name: %v", err)
+		}
+		if serviceConfig.LabelSelector == nil || len(*serviceConfig.LabelSelector) == 0 {
+			return nil, fmt.Errorf("Service %s has no labelSelector", *service)
+		}
+
+		target.labelSelector = *serviceConfig.LabelSelector
+		if serviceConfig.Namespace != nil && *serviceConfig.Namespace != "" {
+			target.namespace = *serviceConfig.Namespace
+		}
+		if serviceConfig.ContainerName != nil && *serviceConfig.ContainerName != "" {
+			target.containerName = *serviceConfig.ContainerName
+		}
+
+		return target, nil
+	}
+
+	if labelSelector == nil || len(*labelSelector) == 0 {
+		return nil, fmt.Errorf("Please specify a podName, service or labelSelector")
+	}
+
+	target.labelSelector = *labelSelector
+	return target, nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/covexo/devspace/pkg/devspace/config/configutil"
+	"github.com/covexo/devspace/pkg/devspace/config/v1"
+)
+
+func TestResolvePodTarget(t *testing.T) {
+	configutil.ResetConfig()
+	defer configutil.ResetConfig()
+
+	config := configutil.InitConfig()
+	config.DevSpace.Services = &[]*v1.ServiceConfig{
+		{
+			Name:          configutil.String("api"),
+			Namespace:     configutil.String("backend"),
+			LabelSelector: &map[string]*string{"app": configutil.String("api")},
+			ContainerName: configutil.String("server"),
+		},
+	}
+
+	target, err := resolvePodTarget(nil, configutil.String("api"), nil, configutil.String("default"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *target.labelSelector["app"] != "api" || target.namespace != "backend" || target.containerName != "server" {
+		t.Fatalf("Unexpected target of service api: %#v", target)
+	}
+
+	selector := &map[string]*string{"app": configutil.String("web")}
+	target, err = resolvePodTarget(nil, nil, selector, configutil.String("default"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *target.labelSelector["app"] != "web" || target.namespace != "default" {
+		t.Fatalf("Unexpected target of label selector: %#v", target)
+	}
+
+	_, err = resolvePodTarget(nil, configutil.String("api"), selector, nil, nil)
+	if err == nil {
+		t.Fatal("Expected error for service and labelSelector")
+	}
+
+	_, err = resolvePodTarget(nil, configutil.String("missing"), nil, nil, nil)
+	if err == nil {
+		t.Fatal("Expected error for missing service")
+	}
+
+	_, err = resolvePodTarget(nil, nil, nil, nil, nil)
+	if err == nil {
+		t.Fatal("Expected error without podName, service and labelSelector")
+	}
+}
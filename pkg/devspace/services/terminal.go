@@ -2,58 +2,177 @@ package services
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/covexo/devspace/pkg/devspace/config/v1"
 
+	"github.com/covexo/devspace/pkg/devspace/analyze"
 	"github.com/covexo/devspace/pkg/devspace/config/configutil"
 	"github.com/covexo/devspace/pkg/devspace/kubectl"
 	"github.com/covexo/devspace/pkg/util/log"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	kubectlExec "k8s.io/client-go/util/exec"
 )
 
-// StartTerminal opens a new terminal
-func StartTerminal(client *kubernetes.Clientset, serviceNameOverride, containerNameOverride, labelSelectorOverride, namespaceOverride string, args []string, log log.Logger) error {
+// StartTerminal opens a new terminal. If args or a command in the terminal config are specified, the command is
+// executed instead of an interactive shell and its exit code is returned. A TTY is allocated as long as stdin is a
+// terminal and it isn't disabled by ttyOverride or the terminal config, otherwise stdin, stdout and stderr are
+// streamed without a TTY (e.g. when piping input into the command). Protected pods are refused unless allowProtected
+// is true
+func StartTerminal(client kubernetes.Interface, serviceNameOverride, containerNameOverride, labelSelectorOverride, namespaceOverride, podNameOverride string, ttyOverride *bool, allowProtected bool, args []string, log log.Logger) (int, error) {
 	var command []string
+	interactiveShell := false
 	config := configutil.GetConfig()
+	terminalConfig := config.DevSpace.Terminal
+	if terminalConfig == nil {
+		terminalConfig = &v1.Terminal{}
+	}
 
-	if len(args) == 0 && (config.DevSpace.Terminal.Command == nil || len(*config.DevSpace.Terminal.Command) == 0) {
+	if len(args) > 0 {
+		command = args
+	} else if terminalConfig.Command != nil && len(*terminalConfig.Command) > 0 {
+		for _, cmd := range *terminalConfig.Command {
+			command = append(command, *cmd)
+		}
+	} else {
+		interactiveShell = true
 		command = []string{
 			"sh",
 			"-c",
 			"command -v bash >/dev/null 2>&1 && exec bash || exec sh",
 		}
+	}
+
+	tty := true
+	if ttyOverride != nil {
+		tty = *ttyOverride
+	} else if terminalConfig.TTY != nil {
+		tty = *terminalConfig.TTY
+	}
+
+	pod, containerName, err := SelectContainer(client, serviceNameOverride, containerNameOverride, labelSelectorOverride, namespaceOverride, podNameOverride, allowProtected, log)
+	if err != nil {
+		return 0, err
+	}
+
+	var terminalErr error
+	if tty {
+		_, _, _, terminalErr = kubectl.Exec(client, pod, containerName, command, true, nil)
 	} else {
-		if len(args) > 0 {
-			command = args
-		} else {
-			for _, cmd := range *config.DevSpace.Terminal.Command {
-				command = append(command, *cmd)
+		terminalErr = kubectl.ExecStream(client, pod, containerName, command, os.Stdin, os.Stdout, os.Stderr)
+	}
+	if terminalErr != nil {
+		if exitErr, ok := terminalErr.(kubectlExec.CodeExitError); ok {
+			// The exit code of an interactive shell is the exit code of the last command the user typed
+			if interactiveShell {
+				return 0, nil
+			}
+
+			return exitErr.Code, nil
+		}
+
+		return 0, fmt.Errorf("Unable to start terminal session: %v", terminalErr)
+	}
+
+	return 0, nil
+}
+
+// SelectContainer returns the pod and the name of the container the terminal is opened in. The overrides replace the
+// service and terminal config if they are not empty. Protected pods are refused unless allowProtected is true
+func SelectContainer(client kubernetes.Interface, serviceNameOverride, containerNameOverride, labelSelectorOverride, namespaceOverride, podNameOverride string, allowProtected bool, log log.Logger) (*k8sv1.Pod, string, error) {
+	config := configutil.GetConfig()
+	terminalConfig := config.DevSpace.Terminal
+	if terminalConfig == nil {
+		terminalConfig = &v1.Terminal{}
+	}
+
+	service, err := getTerminalService(serviceNameOverride)
+	if err != nil {
+		return nil, "", err
+	}
+
+	labelSelector, namespace := getTerminalSelector(service, labelSelectorOverride, namespaceOverride)
+
+	var pod *k8sv1.Pod
+
+	if podNameOverride != "" {
+		pod, err = kubectl.GetRunningPodByName(client, podNameOverride, namespace)
+		if err != nil {
+			return nil, "", fmt.Errorf("Cannot find running pod: %v", err)
+		}
+	} else {
+		// Get first running pod
+		log.StartWait("Waiting for pods to become running")
+		pod, err = kubectl.GetNewestRunningPod(client, labelSelector, namespace)
+		log.StopWait()
+		if err != nil {
+			// Show the user why the pods did not start
+			reports, analyzeErr := analyze.Pods(client, namespace, labelSelector)
+			if analyzeErr == nil {
+				analyze.PrintReports(reports, log)
 			}
+
+			return nil, "", fmt.Errorf("Cannot find running pod: %v", err)
 		}
 	}
 
-	var service *v1.ServiceConfig
+	err = kubectl.CheckProtectedPod(pod, allowProtected)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Get container name
+	containerName := pod.Spec.Containers[0].Name
+	if containerNameOverride == "" {
+		if service != nil && service.ContainerName != nil {
+			containerName = *service.ContainerName
+		} else if terminalConfig.ContainerName != nil {
+			containerName = *terminalConfig.ContainerName
+		}
+	} else {
+		containerName = containerNameOverride
+	}
+
+	return pod, containerName, nil
+}
+
+// getTerminalService returns the service that selects the pod of the terminal. If no service is configured and the
+// default service doesn't exist, nil is returned
+func getTerminalService(serviceNameOverride string) (*v1.ServiceConfig, error) {
+	config := configutil.GetConfig()
 	serviceName := "default"
 
 	if serviceNameOverride == "" {
-		if config.DevSpace.Terminal.Service != nil {
+		if config.DevSpace.Terminal != nil && config.DevSpace.Terminal.Service != nil {
 			serviceName = *config.DevSpace.Terminal.Service
 		}
 	} else {
 		serviceName = serviceNameOverride
 	}
 
-	if serviceName != "" {
-		var err error
+	if serviceName == "" {
+		return nil, nil
+	}
 
-		service, err = configutil.GetService(serviceName)
-		if err != nil && serviceName != "default" {
-			return fmt.Errorf("Error resolving service name: %v", err)
+	service, err := configutil.GetService(serviceName)
+	if err != nil {
+		if serviceName != "default" {
+			return nil, fmt.Errorf("Error resolving service name: %v", err)
 		}
+
+		return nil, nil
 	}
 
+	return service, nil
+}
+
+// getTerminalSelector returns the label selector and namespace that select the pod of the terminal
+func getTerminalSelector(service *v1.ServiceConfig, labelSelectorOverride, namespaceOverride string) (string, string) {
+	config := configutil.GetConfig()
+
 	// Select pods
 	namespace := ""
 	if namespaceOverride == "" {
@@ -94,36 +213,45 @@ func StartTerminal(client *kubernetes.Clientset, serviceNameOverride, containerN
 		labelSelector = labelSelectorOverride
 	}
 
-	// Get first running pod
-	log.StartWait("Waiting for pods to become running")
-	pod, err := kubectl.GetNewestRunningPod(client, labelSelector, namespace)
-	log.StopWait()
+	return labelSelector, namespace
+}
+
+// FindRunningDevSpacePod returns the newest running pod that the terminal would be opened in, without waiting for
+// pods to start. It only uses the kubernetes client, so it works before helm is initialized. If no pod is running,
+// nil is returned. Protected pods are only returned if no other pod is running
+func FindRunningDevSpacePod(client kubernetes.Interface, serviceNameOverride, labelSelectorOverride, namespaceOverride string) (*k8sv1.Pod, error) {
+	service, err := getTerminalService(serviceNameOverride)
 	if err != nil {
-		return fmt.Errorf("Cannot find running pod: %v", err)
+		return nil, err
 	}
 
-	// Get container name
-	containerName := pod.Spec.Containers[0].Name
-	if containerNameOverride == "" {
-		if service != nil && service.ContainerName != nil {
-			containerName = *service.ContainerName
-		} else {
-			if config.DevSpace.Terminal.ContainerName != nil {
-				containerName = *config.DevSpace.Terminal.ContainerName
-			}
+	labelSelector, namespace := getTerminalSelector(service, labelSelectorOverride, namespaceOverride)
+	if namespace == "" {
+		namespace, err = configutil.GetDefaultNamespace(configutil.GetConfig())
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		containerName = containerNameOverride
 	}
 
-	_, _, _, terminalErr := kubectl.Exec(client, pod, containerName, command, true, nil)
-	if terminalErr != nil {
-		if _, ok := terminalErr.(kubectlExec.CodeExitError); ok == false {
-			return fmt.Errorf("Unable to start terminal session: %v", terminalErr)
+	podList, err := client.Core().Pods(namespace).List(metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	runningPods := []*k8sv1.Pod{}
+	for index := range podList.Items {
+		pod := &podList.Items[index]
+		if pod.DeletionTimestamp != nil || kubectl.GetPodStatus(pod) != "Running" {
+			continue
 		}
+
+		runningPods = append(runningPods, pod)
 	}
 
-	return nil
+	selectedPod, _ := kubectl.SelectNewestPod(runningPods)
+	return selectedPod, nil
 }
 
 // GetNameOfFirstHelmDeployment retrieves the first helm deployment name
@@ -0,0 +1,238 @@
+package sync
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/juju/errors"
+)
+
+// ConflictPolicy defines how a file is handled that was changed locally and remotely at the same time
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyNone logs conflicts, but keeps the default behavior where the newer file wins
+	ConflictPolicyNone ConflictPolicy = ""
+
+	// ConflictPolicyPreferLocal keeps the local file and uploads it
+	ConflictPolicyPreferLocal ConflictPolicy = "preferLocal"
+
+	// ConflictPolicyPreferRemote overrides the local file with the remote one
+	ConflictPolicyPreferRemote ConflictPolicy = "preferRemote"
+
+	// ConflictPolicyKeepBoth keeps the local file and saves both versions to the conflict path with a .local and a
+	// .remote suffix
+	ConflictPolicyKeepBoth ConflictPolicy = "keepBoth"
+)
+
+// DefaultConflictPath is the directory where both versions of a conflicting file are saved with keepBoth
+const DefaultConflictPath = ".devspace/conflicts"
+
+// conflictWindow is the maximum time in seconds between a local and a remote change to be treated as conflict
+const conflictWindow int64 = 10
+
+// localCopySuffix and remoteCopySuffix are appended to the saved versions if both versions are kept
+const localCopySuffix = ".local"
+const remoteCopySuffix = ".remote"
+
+// ParseConflictPolicy converts the given string into a conflict policy
+func ParseConflictPolicy(policy string) (ConflictPolicy, error) {
+	switch ConflictPolicy(policy) {
+	case ConflictPolicyNone, ConflictPolicyPreferLocal, ConflictPolicyPreferRemote, ConflictPolicyKeepBoth:
+		return ConflictPolicy(policy), nil
+	}
+
+	return ConflictPolicyNone, fmt.Errorf("Unknown conflict policy %s (supported: %s, %s, %s)", policy, ConflictPolicyPreferLocal, ConflictPolicyPreferRemote, ConflictPolicyKeepBoth)
+}
+
+// s.fileIndex needs to be locked before this function is called
+// A remote change conflicts with the local file if the local file changed since the last sync and both
+// changes happened within the conflict window. Files that were never synced (e.g. during the initial sync) can't
+// have changed since the last sync, so they never conflict
+func hasConflictingLocalChange(relativePath string, stat os.FileInfo, remoteMtime int64, s *SyncConfig) bool {
+	if stat == nil || stat.IsDir() {
+		return false
+	}
+
+	localMtime := roundMtime(stat.ModTime())
+	if localMtime-remoteMtime > conflictWindow || remoteMtime-localMtime > conflictWindow {
+		return false
+	}
+
+	tracked := s.fileIndex.fileMap[relativePath]
+	if tracked == nil {
+		return false
+	}
+
+	return localMtime != tracked.Mtime || stat.Size() != tracked.Size
+}
+
+// logConflict writes the conflict to the sync log and warns the user
+func (s *SyncConfig) logConflict(relativePath, resolution string) {
+	s.Logf("[Sync] Conflict on %s: %s", relativePath, resolution)
+	s.trace(TraceConflict, relativePath, resolution)
+
+	if s.silent == false && s.testing == false {
+		log.Warnf("[Sync] %s was changed locally and in the container: %s", relativePath, resolution)
+	}
+}
+
+// s.fileIndex needs to be locked before this function is called
+// resolveDownstreamConflict handles a downloaded file that conflicts with a local change and returns true if the
+// local file should be overridden
+func (s *SyncConfig) resolveDownstreamConflict(relativePath, outFileName string, stat os.FileInfo, header *tar.Header, tarReader io.Reader) (bool, error) {
+	delete(s.pendingDownloads, relativePath)
+
+	remoteInformation := &fileInformation{
+		Name:  relativePath,
+		Mtime: header.FileInfo().ModTime().Unix(),
+		Size:  header.FileInfo().Size(),
+	}
+
+	switch s.ConflictPolicy {
+	case ConflictPolicyPreferRemote:
+		s.logConflict(relativePath, "using container version")
+		return true, nil
+	case ConflictPolicyPreferLocal:
+		s.logConflict(relativePath, "using local version")
+	case ConflictPolicyKeepBoth:
+		copyPath := filepath.Join(s.ConflictPath, filepath.FromSlash(relativePath))
+		err := writeConflictCopies(copyPath, outFileName, stat, header, tarReader)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+
+		s.logConflict(relativePath, "kept local version, both versions saved as "+copyPath+localCopySuffix+" and "+copyPath+remoteCopySuffix)
+	default:
+		// Keep the old behavior: the newer file wins
+		if roundMtime(stat.ModTime()) > remoteInformation.Mtime {
+			s.logConflict(relativePath, "using newer local version")
+
+			// Update filemap otherwise we download and download again
+			s.fileIndex.fileMap[relativePath] = &fileInformation{
+				Name:  relativePath,
+				Mtime: roundMtime(stat.ModTime()),
+				Size:  stat.Size(),
+			}
+
+			return false, nil
+		}
+
+		s.logConflict(relativePath, "using newer container version")
+		return true, nil
+	}
+
+	// Mark the remote version as synced, so it is not downloaded again and the local version is uploaded
+	s.fileIndex.fileMap[relativePath] = remoteInformation
+
+	// We send the event out of the fileIndex lock, because otherwise this could cause a deadlock
+	go func() {
+		s.upstream.events <- &fileInformation{
+			Name:  relativePath,
+			Mtime: roundMtime(stat.ModTime()),
+			Size:  stat.Size(),
+		}
+	}()
+
+	return false, nil
+}
+
+// filterConflictingUploads removes files from the upload that conflict with a pending download, if
+// the conflict policy is resolved by the downstream. Files that were never synced don't conflict
+func (s *SyncConfig) filterConflictingUploads(files []*fileInformation) []*fileInformation {
+	s.fileIndex.fileMapMutex.Lock()
+	defer s.fileIndex.fileMapMutex.Unlock()
+
+	filtered := make([]*fileInformation, 0, len(files))
+
+	for _, file := range files {
+		remote, pending := s.pendingDownloads[file.Name]
+		tracked := s.fileIndex.fileMap[file.Name] != nil
+		if pending == false || tracked == false || file.IsDirectory || file.Mtime-remote.Mtime > conflictWindow || remote.Mtime-file.Mtime > conflictWindow {
+			filtered = append(filtered, file)
+			continue
+		}
+
+		switch s.ConflictPolicy {
+		case ConflictPolicyPreferRemote, ConflictPolicyKeepBoth:
+			// The downstream resolves the conflict when it downloads the file
+			s.Logf("[Upstream] Postpone upload of %s, because the file was changed in the container", file.Name)
+		default:
+			s.logConflict(file.Name, "using local version")
+			filtered = append(filtered, file)
+		}
+	}
+
+	return filtered
+}
+
+// setupConflictPath resolves the conflict path and excludes it from the sync if it is within the watch path, because
+// the saved versions would be uploaded otherwise
+func (s *SyncConfig) setupConflictPath() error {
+	if s.ConflictPath == "" {
+		s.ConflictPath = DefaultConflictPath
+	}
+
+	conflictPath, err := filepath.Abs(s.ConflictPath)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	s.ConflictPath = conflictPath
+
+	relativePath, err := filepath.Rel(s.WatchPath, conflictPath)
+	if err == nil && strings.HasPrefix(relativePath, "..") == false {
+		s.ExcludePaths = append(s.ExcludePaths, "/"+filepath.ToSlash(relativePath))
+	}
+
+	return nil
+}
+
+// writeConflictCopies saves the local file with the local copy suffix and the remote file with the remote copy suffix
+func writeConflictCopies(copyPath, localFileName string, stat os.FileInfo, header *tar.Header, tarReader io.Reader) error {
+	err := os.MkdirAll(filepath.Dir(copyPath), 0755)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	localFile, err := os.Open(localFileName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	defer localFile.Close()
+
+	err = writeConflictCopy(copyPath+localCopySuffix, localFile, stat.ModTime())
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	return writeConflictCopy(copyPath+remoteCopySuffix, tarReader, header.FileInfo().ModTime())
+}
+
+func writeConflictCopy(outFileName string, reader io.Reader, mtime time.Time) error {
+	outFile, err := os.Create(outFileName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	defer outFile.Close()
+
+	_, err = io.Copy(outFile, reader)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	err = outFile.Close()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	return os.Chtimes(outFileName, time.Now(), mtime)
+}
@@ -0,0 +1,295 @@
+package sync
+
+import (
+	"archive/tar"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rjeczalik/notify"
+)
+
+// createTestConflictClient creates a sync client with a local file that was changed after the last sync
+func createTestConflictClient(t *testing.T, policy ConflictPolicy) (*SyncConfig, os.FileInfo, func()) {
+	remote, local, outside := initTestDirs(t)
+	cleanup := func() {
+		os.RemoveAll(remote)
+		os.RemoveAll(local)
+		os.RemoveAll(outside)
+	}
+
+	syncClient := createTestSyncClient(local, remote)
+	syncClient.ConflictPolicy = policy
+	syncClient.ConflictPath = filepath.Join(outside, "conflicts")
+	syncClient.fileIndex = newFileIndex()
+	syncClient.pendingDownloads = make(map[string]*fileInformation)
+	syncClient.upstream = &upstream{
+		events: make(chan notify.EventInfo, 10),
+		config: syncClient,
+	}
+
+	localFile := filepath.Join(local, "conflict.txt")
+	err := ioutil.WriteFile(localFile, []byte("local"), 0644)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	stat, err := os.Stat(localFile)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	// The file was synced 5 seconds before it was changed locally
+	syncClient.fileIndex.fileMap["/conflict.txt"] = &fileInformation{
+		Name:  "/conflict.txt",
+		Mtime: roundMtime(stat.ModTime()) - 5,
+		Size:  stat.Size(),
+	}
+
+	return syncClient, stat, cleanup
+}
+
+func TestHasConflictingLocalChange(t *testing.T) {
+	syncClient, stat, cleanup := createTestConflictClient(t, ConflictPolicyNone)
+	defer cleanup()
+
+	localMtime := roundMtime(stat.ModTime())
+	testCases := []struct {
+		name        string
+		path        string
+		stat        os.FileInfo
+		remoteMtime int64
+		tracked     *fileInformation
+
+		expectedConflict bool
+	}{
+		{
+			name:             "Changed locally and remotely",
+			path:             "/conflict.txt",
+			stat:             stat,
+			remoteMtime:      localMtime - 2,
+			expectedConflict: true,
+		},
+		{
+			name:        "Remote change outside of the conflict window",
+			path:        "/conflict.txt",
+			stat:        stat,
+			remoteMtime: localMtime - conflictWindow - 5,
+		},
+		{
+			name:        "Unchanged locally",
+			path:        "/conflict.txt",
+			stat:        stat,
+			remoteMtime: localMtime - 2,
+			tracked:     &fileInformation{Name: "/conflict.txt", Mtime: localMtime, Size: stat.Size()},
+		},
+		{
+			name:        "Untracked file",
+			path:        "/untracked.txt",
+			stat:        stat,
+			remoteMtime: localMtime - 2,
+		},
+		{
+			name:        "Missing local file",
+			path:        "/conflict.txt",
+			remoteMtime: localMtime - 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		previous := syncClient.fileIndex.fileMap[testCase.path]
+		if testCase.tracked != nil {
+			syncClient.fileIndex.fileMap[testCase.path] = testCase.tracked
+		}
+
+		conflict := hasConflictingLocalChange(testCase.path, testCase.stat, testCase.remoteMtime, syncClient)
+		if conflict != testCase.expectedConflict {
+			t.Fatalf("%s: expected conflict %t, got %t", testCase.name, testCase.expectedConflict, conflict)
+		}
+
+		if testCase.tracked != nil {
+			syncClient.fileIndex.fileMap[testCase.path] = previous
+		}
+	}
+}
+
+func TestResolveDownstreamConflict(t *testing.T) {
+	testCases := []struct {
+		name         string
+		policy       ConflictPolicy
+		remoteOffset int64
+
+		expectedOverride bool
+		expectedMtime    int64
+		expectedUpload   bool
+		expectedCopies   bool
+	}{
+		{
+			name:             "preferRemote",
+			policy:           ConflictPolicyPreferRemote,
+			remoteOffset:     -2,
+			expectedOverride: true,
+			expectedMtime:    -5,
+		},
+		{
+			name:           "preferLocal",
+			policy:         ConflictPolicyPreferLocal,
+			remoteOffset:   -2,
+			expectedMtime:  -2,
+			expectedUpload: true,
+		},
+		{
+			name:           "keepBoth",
+			policy:         ConflictPolicyKeepBoth,
+			remoteOffset:   -2,
+			expectedMtime:  -2,
+			expectedUpload: true,
+			expectedCopies: true,
+		},
+		{
+			name:          "Default with newer local file",
+			policy:        ConflictPolicyNone,
+			remoteOffset:  -2,
+			expectedMtime: 0,
+		},
+		{
+			name:             "Default with newer remote file",
+			policy:           ConflictPolicyNone,
+			remoteOffset:     2,
+			expectedOverride: true,
+			expectedMtime:    -5,
+		},
+	}
+
+	for _, testCase := range testCases {
+		syncClient, stat, cleanup := createTestConflictClient(t, testCase.policy)
+		defer cleanup()
+
+		localMtime := roundMtime(stat.ModTime())
+		outFileName := filepath.Join(syncClient.WatchPath, "conflict.txt")
+		header := &tar.Header{
+			Name:     "conflict.txt",
+			Mode:     0644,
+			Size:     int64(len("remote")),
+			ModTime:  time.Unix(localMtime+testCase.remoteOffset, 0),
+			Typeflag: tar.TypeReg,
+		}
+		syncClient.pendingDownloads["/conflict.txt"] = &fileInformation{Name: "/conflict.txt", Mtime: header.ModTime.Unix()}
+
+		override, err := syncClient.resolveDownstreamConflict("/conflict.txt", outFileName, stat, header, strings.NewReader("remote"))
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.name, err)
+		}
+		if override != testCase.expectedOverride {
+			t.Fatalf("%s: expected override %t, got %t", testCase.name, testCase.expectedOverride, override)
+		}
+		if _, pending := syncClient.pendingDownloads["/conflict.txt"]; pending {
+			t.Fatalf("%s: expected the pending download to be removed", testCase.name)
+		}
+		if mtime := syncClient.fileIndex.fileMap["/conflict.txt"].Mtime; mtime != localMtime+testCase.expectedMtime {
+			t.Fatalf("%s: expected synced mtime %d, got %d", testCase.name, localMtime+testCase.expectedMtime, mtime)
+		}
+
+		select {
+		case event := <-syncClient.upstream.events:
+			if testCase.expectedUpload == false {
+				t.Fatalf("%s: unexpected upload of %s", testCase.name, event.Path())
+			}
+		case <-time.After(100 * time.Millisecond):
+			if testCase.expectedUpload {
+				t.Fatalf("%s: expected the local file to be uploaded", testCase.name)
+			}
+		}
+
+		copyPath := filepath.Join(syncClient.ConflictPath, "conflict.txt")
+		remoteCopy, err := ioutil.ReadFile(copyPath + remoteCopySuffix)
+		if testCase.expectedCopies && (err != nil || string(remoteCopy) != "remote") {
+			t.Fatalf("%s: expected the container version in %s%s, got %s (%v)", testCase.name, copyPath, remoteCopySuffix, string(remoteCopy), err)
+		} else if testCase.expectedCopies == false && err == nil {
+			t.Fatalf("%s: unexpected copy of the container version", testCase.name)
+		}
+
+		localCopy, err := ioutil.ReadFile(copyPath + localCopySuffix)
+		if testCase.expectedCopies && (err != nil || string(localCopy) != "local") {
+			t.Fatalf("%s: expected the local version in %s%s, got %s (%v)", testCase.name, copyPath, localCopySuffix, string(localCopy), err)
+		}
+
+		// No copy may be written into the watch path, because it would be uploaded
+		if _, err := os.Stat(outFileName + remoteCopySuffix); err == nil {
+			t.Fatalf("%s: unexpected copy in the watch path", testCase.name)
+		}
+	}
+}
+
+func TestFilterConflictingUploads(t *testing.T) {
+	testCases := map[ConflictPolicy][]string{
+		ConflictPolicyPreferRemote: {"/untracked.txt", "/unchanged.txt"},
+		ConflictPolicyKeepBoth:     {"/untracked.txt", "/unchanged.txt"},
+		ConflictPolicyPreferLocal:  {"/conflict.txt", "/untracked.txt", "/unchanged.txt"},
+		ConflictPolicyNone:         {"/conflict.txt", "/untracked.txt", "/unchanged.txt"},
+	}
+
+	for policy, expectedUploads := range testCases {
+		syncClient, stat, cleanup := createTestConflictClient(t, policy)
+		defer cleanup()
+
+		mtime := roundMtime(stat.ModTime())
+		syncClient.pendingDownloads["/conflict.txt"] = &fileInformation{Name: "/conflict.txt", Mtime: mtime - 2}
+		syncClient.pendingDownloads["/untracked.txt"] = &fileInformation{Name: "/untracked.txt", Mtime: mtime - 2}
+
+		uploads := syncClient.filterConflictingUploads([]*fileInformation{
+			{Name: "/conflict.txt", Mtime: mtime},
+			{Name: "/untracked.txt", Mtime: mtime},
+			{Name: "/unchanged.txt", Mtime: mtime},
+		})
+
+		names := []string{}
+		for _, upload := range uploads {
+			names = append(names, upload.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(expectedUploads, ",") {
+			t.Fatalf("Policy '%s': expected uploads %v, got %v", policy, expectedUploads, names)
+		}
+	}
+}
+
+func TestSetupConflictPath(t *testing.T) {
+	testCases := []struct {
+		watchPath    string
+		conflictPath string
+
+		expectedExclude string
+	}{
+		{
+			watchPath:       "/project",
+			conflictPath:    "/project/.devspace/conflicts",
+			expectedExclude: "/.devspace/conflicts",
+		},
+		{
+			watchPath:    "/project/src",
+			conflictPath: "/project/.devspace/conflicts",
+		},
+	}
+
+	for _, testCase := range testCases {
+		syncClient := &SyncConfig{
+			WatchPath:    filepath.FromSlash(testCase.watchPath),
+			ConflictPath: filepath.FromSlash(testCase.conflictPath),
+		}
+
+		err := syncClient.setupConflictPath()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		excludes := strings.Join(syncClient.ExcludePaths, ",")
+		if excludes != testCase.expectedExclude {
+			t.Fatalf("Expected excludes '%s' for conflict path %s and watch path %s, got '%s'", testCase.expectedExclude, testCase.conflictPath, testCase.watchPath, excludes)
+		}
+	}
+}
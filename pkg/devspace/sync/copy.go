@@ -0,0 +1,294 @@
+package sync
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/juju/errors"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// copyProgressInterval is the interval in which the transferred bytes of a copy are updated
+const copyProgressInterval = 500 * time.Millisecond
+
+// DownloadFromContainer downloads a file or folder from the container. Like cp, it is copied into localPath if
+// localPath is an existing folder and copied to localPath otherwise. If some files could not be read in the
+// container, the other files are copied and an error is returned
+func DownloadFromContainer(client kubernetes.Interface, pod *k8sv1.Pod, containerName, containerPath, localPath string, logger log.Logger) error {
+	containerPath = path.Clean(containerPath)
+	if containerPath == "/" || containerPath == "." {
+		return fmt.Errorf("Cannot copy %s, please specify a file or folder in the container", containerPath)
+	}
+
+	absLocalPath, err := filepath.Abs(localPath)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	baseName := path.Base(containerPath)
+	destPath := absLocalPath
+
+	// If we don't copy into an existing folder, we extract to a temporary folder and rename the file or folder
+	stat, statErr := os.Stat(absLocalPath)
+	if statErr != nil || stat.IsDir() == false {
+		destPath, err = ioutil.TempDir(filepath.Dir(absLocalPath), ".devspace-cp-")
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		defer os.RemoveAll(destPath)
+	}
+
+	reader, writer := io.Pipe()
+	stderr := &bytes.Buffer{}
+	execErr := make(chan error, 1)
+
+	go func() {
+		err := kubectl.ExecStream(client, pod, containerName, []string{"tar", "czf", "-", "-C", path.Dir(containerPath), baseName}, nil, writer, stderr)
+		writer.Close()
+		execErr <- err
+	}()
+
+	progress := newCopyProgress(reader, "Downloading "+containerPath, 0, logger)
+	err = untarAll(progress, destPath, "", newCopyConfig(destPath, containerPath))
+	if err == nil {
+		// Read the rest of the stream, so that the exec doesn't fail because of a closed stdout
+		_, err = io.Copy(ioutil.Discard, progress)
+	}
+
+	progress.stop()
+	reader.CloseWithError(err)
+	tarErr := <-execErr
+
+	// Files that were copied are moved even if tar failed for other files
+	if err == nil && destPath != absLocalPath {
+		if statErr == nil {
+			os.Remove(absLocalPath)
+		}
+
+		err = os.Rename(filepath.Join(destPath, baseName), absLocalPath)
+	}
+
+	if tarErr != nil {
+		return fmt.Errorf("Error copying %s: %v %s", containerPath, tarErr, strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return fmt.Errorf("Error extracting %s: %v", containerPath, err)
+	}
+
+	logger.Donef("Downloaded %s to %s (%s)", containerPath, localPath, formatSize(progress.transferred()))
+	return nil
+}
+
+// UploadToContainer copies a local file or folder to the container. Like cp, it is copied into containerPath if
+// containerPath is an existing folder (or ends with a slash) and copied to containerPath otherwise
+func UploadToContainer(client kubernetes.Interface, pod *k8sv1.Pod, containerName, localPath, containerPath string, logger log.Logger) error {
+	absLocalPath, err := filepath.Abs(localPath)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	_, err = os.Stat(absLocalPath)
+	if err != nil {
+		return err
+	}
+
+	baseName := filepath.Base(absLocalPath)
+	s := newCopyConfig(filepath.Dir(absLocalPath), containerPath)
+
+	// Tar the local files first, so that local errors don't leave half copied files in the container
+	archive, err := writeCopyTar(s, baseName)
+	if err != nil {
+		return fmt.Errorf("Error archiving %s: %v", localPath, err)
+	}
+
+	defer os.Remove(archive)
+
+	file, err := os.Open(archive)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	defer file.Close()
+
+	archiveStat, err := file.Stat()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	stderr := &bytes.Buffer{}
+	progress := newCopyProgress(file, "Uploading "+localPath, archiveStat.Size(), logger)
+	err = kubectl.ExecStream(client, pod, containerName, []string{"sh", "-c", getUntarCommand(containerPath, baseName)}, progress, &bytes.Buffer{}, stderr)
+	progress.stop()
+	if err != nil {
+		return fmt.Errorf("Error copying %s: %v %s", localPath, err, strings.TrimSpace(stderr.String()))
+	}
+
+	logger.Donef("Uploaded %s to %s (%s)", localPath, containerPath, formatSize(archiveStat.Size()))
+	return nil
+}
+
+// newCopyConfig returns a sync config that is only used to tar and untar files
+func newCopyConfig(watchPath, destPath string) *SyncConfig {
+	return &SyncConfig{
+		WatchPath:           watchPath,
+		DestPath:            destPath,
+		PreservePermissions: true,
+		fileIndex:           newFileIndex(),
+		overwriteLocal:      true,
+		silent:              true,
+	}
+}
+
+// writeCopyTar writes baseName (relative to the watch path of the config) to a temporary tar archive. In contrast
+// to writeTar, errors are returned instead of retried
+func writeCopyTar(s *SyncConfig, baseName string) (string, error) {
+	f, err := os.Create(filepath.Join(os.TempDir(), fmt.Sprintf("devspace-cp-%d", time.Now().UnixNano())))
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tarWriter := tar.NewWriter(gw)
+
+	err = recursiveTar(s.WatchPath, "/"+baseName, map[string]*fileInformation{}, tarWriter, s)
+	if err == nil {
+		err = tarWriter.Close()
+	}
+	if err == nil {
+		err = gw.Close()
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// getUntarCommand returns the shell command that extracts the uploaded tar to containerPath
+func getUntarCommand(containerPath, baseName string) string {
+	dest := shellQuote(containerPath)
+	command := ""
+
+	// A trailing slash means the folder should be created
+	if strings.HasSuffix(containerPath, "/") {
+		command = "mkdir -p " + dest + " && "
+	}
+
+	tmpDir := "/tmp/devspace-cp-$$"
+	return command + "if [ -d " + dest + " ]; then tar xzpf - -C " + dest + "; else " +
+		"mkdir -p " + tmpDir + " && tar xzpf - -C " + tmpDir + " && rm -rf " + dest + " && mv " + tmpDir + "/" + shellQuote(baseName) + " " + dest + "; " +
+		"exitCode=$?; rm -rf " + tmpDir + "; exit $exitCode; fi"
+}
+
+// shellQuote quotes the value for sh
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+// copyProgress counts the bytes that are read from the reader and shows them as wait message
+type copyProgress struct {
+	reader  io.Reader
+	message string
+	total   int64
+	logger  log.Logger
+
+	bytes     int64
+	mutex     sync.Mutex
+	done      chan bool
+	doneOnce  sync.Once
+	lastShown string
+}
+
+func newCopyProgress(reader io.Reader, message string, total int64, logger log.Logger) *copyProgress {
+	p := &copyProgress{
+		reader:  reader,
+		message: message,
+		total:   total,
+		logger:  logger,
+		done:    make(chan bool),
+	}
+
+	logger.StartWait(message)
+	go p.show()
+
+	return p
+}
+
+func (p *copyProgress) Read(buf []byte) (int, error) {
+	n, err := p.reader.Read(buf)
+
+	p.mutex.Lock()
+	p.bytes += int64(n)
+	p.mutex.Unlock()
+
+	return n, err
+}
+
+func (p *copyProgress) transferred() int64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.bytes
+}
+
+func (p *copyProgress) show() {
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-time.After(copyProgressInterval):
+			status := formatSize(p.transferred())
+			if p.total > 0 {
+				status += " / " + formatSize(p.total)
+			}
+
+			if status != p.lastShown {
+				p.lastShown = status
+				p.logger.StartWait(p.message + " (" + status + ")")
+			}
+		}
+	}
+}
+
+func (p *copyProgress) stop() {
+	p.doneOnce.Do(func() {
+		close(p.done)
+		p.logger.StopWait()
+	})
+}
+
+// formatSize returns the size in B, KB, MB or GB
+func formatSize(size int64) string {
+	units := []string{"B", "KB", "MB", "GB"}
+	value := float64(size)
+	unit := 0
+
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
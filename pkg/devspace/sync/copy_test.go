@@ -0,0 +1,111 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = os.MkdirAll(filepath.Join(dir, "local", "logs", "app"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "local", "logs", "app", "heap.out"), []byte("heap"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := writeCopyTar(newCopyConfig(filepath.Join(dir, "local"), ""), "logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(archive)
+
+	// The container path is an existing folder, so logs is copied into it
+	containerPath := filepath.Join(dir, "container")
+	runUntarCommand(t, archive, containerPath+"/", "logs")
+	expectFileContent(t, filepath.Join(containerPath, "logs", "app", "heap.out"), "heap")
+
+	// The container path doesn't exist, so logs is copied to it
+	runUntarCommand(t, archive, filepath.Join(containerPath, "renamed"), "logs")
+	expectFileContent(t, filepath.Join(containerPath, "renamed", "app", "heap.out"), "heap")
+
+	// Downloaded files override local files, even if they are newer
+	downloadPath := filepath.Join(dir, "download")
+	err = os.MkdirAll(filepath.Join(downloadPath, "logs", "app"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(downloadPath, "logs", "app", "heap.out"), []byte("newer"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chtimes(filepath.Join(downloadPath, "logs", "app", "heap.out"), time.Now().Add(time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	err = untarAll(f, downloadPath, "", newCopyConfig(downloadPath, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectFileContent(t, filepath.Join(downloadPath, "logs", "app", "heap.out"), "heap")
+}
+
+func runUntarCommand(t *testing.T, archive, containerPath, baseName string) {
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cmd := exec.Command("sh", "-c", getUntarCommand(containerPath, baseName))
+	cmd.Stdin = f
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Error extracting to %s: %v %s", containerPath, err, string(out))
+	}
+}
+
+func expectFileContent(t *testing.T, filePath, content string) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Fatalf("Expected %s in %s, got %s", content, filePath, string(data))
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	testCases := map[int64]string{
+		0:                      "0 B",
+		1023:                   "1023 B",
+		1536:                   "1.5 KB",
+		5 * 1024 * 1024:        "5.0 MB",
+		3 * 1024 * 1024 * 1024: "3.0 GB",
+	}
+
+	for size, expected := range testCases {
+		if formatSize(size) != expected {
+			t.Fatalf("Expected %s for %d, got %s", expected, size, formatSize(size))
+		}
+	}
+}
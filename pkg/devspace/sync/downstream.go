@@ -14,7 +14,6 @@ import (
 	"time"
 
 	"github.com/juju/errors"
-	"github.com/juju/ratelimit"
 
 	"github.com/covexo/devspace/pkg/devspace/kubectl"
 )
@@ -41,7 +40,8 @@ func (d *downstream) start() error {
 
 func (d *downstream) startShell() error {
 	if d.config.testing == false {
-		stdinPipe, stdoutPipe, stderrPipe, err := kubectl.Exec(d.config.Kubectl, d.config.Pod, d.config.Container.Name, []string{"sh"}, false, nil)
+		pod, container := d.config.getPod()
+		stdinPipe, stdoutPipe, stderrPipe, err := kubectl.Exec(d.config.Kubectl, pod, container.Name, []string{"sh"}, false, nil)
 
 		if err != nil {
 			return errors.Trace(err)
@@ -109,12 +109,19 @@ func (d *downstream) mainLoop() error {
 			return errors.Trace(err)
 		}
 
+		d.setPendingDownloads(createFiles)
+
 		amountChanges := len(createFiles) + len(removeFiles)
+		d.config.metrics.setDownloadsQueued(amountChanges)
+
 		if lastAmountChanges > 0 && amountChanges == lastAmountChanges {
 			err = d.applyChanges(createFiles, removeFiles)
 			if err != nil {
 				return errors.Trace(err)
 			}
+
+			d.setPendingDownloads(nil)
+			d.config.metrics.addDownloaded(amountChanges)
 		}
 
 		select {
@@ -128,6 +135,19 @@ func (d *downstream) mainLoop() error {
 	}
 }
 
+// setPendingDownloads remembers the remote changes, so that the upstream can detect conflicts
+func (d *downstream) setPendingDownloads(createFiles []*fileInformation) {
+	d.config.fileIndex.fileMapMutex.Lock()
+	defer d.config.fileIndex.fileMapMutex.Unlock()
+
+	d.config.pendingDownloads = make(map[string]*fileInformation)
+	for _, element := range createFiles {
+		if element.IsDirectory == false {
+			d.config.pendingDownloads[element.Name] = element
+		}
+	}
+}
+
 func (d *downstream) cloneFileMap() map[string]*fileInformation {
 	d.config.fileIndex.fileMapMutex.Lock()
 	defer d.config.fileIndex.fileMapMutex.Unlock()
@@ -165,13 +185,9 @@ func (d *downstream) collectChanges(removeFiles map[string]*fileInformation) ([]
 	overlap := ""
 	done := false
 
-	var downloadReader io.Reader = d.stdoutPipe
-	if d.config.DownstreamLimit > 0 {
-		downloadReader = ratelimit.Reader(d.stdoutPipe, ratelimit.NewBucketWithRate(float64(d.config.DownstreamLimit), d.config.DownstreamLimit))
-	}
-
+	// The find output is part of the control protocol and therefore not rate limited
 	for done == false {
-		n, err := downloadReader.Read(buf[:cap(buf)])
+		n, err := d.stdoutPipe.Read(buf[:cap(buf)])
 		buf = buf[:n]
 
 		if n == 0 {
@@ -267,6 +283,9 @@ func (d *downstream) evaluateFile(fileline string, createFiles *[]*fileInformati
 	// File found don't delete it
 	delete(removeFiles, fileInformation.Name)
 
+	// Check before the mode is updated, so that permission changes in the container are downloaded
+	download := shouldDownload(fileInformation, d.config)
+
 	// Update mode, gid & uid if exists
 	if d.config.fileIndex.fileMap[fileInformation.Name] != nil {
 		d.config.fileIndex.fileMap[fileInformation.Name].RemoteMode = fileInformation.RemoteMode
@@ -281,7 +300,7 @@ func (d *downstream) evaluateFile(fileline string, createFiles *[]*fileInformati
 	}
 
 	// Should we download the file / folder?
-	if shouldDownload(fileInformation, d.config) {
+	if download {
 		*createFiles = append(*createFiles, fileInformation)
 	}
 
@@ -332,6 +351,14 @@ func (d *downstream) applyChanges(createFiles []*fileInformation, removeFiles ma
 		if err != nil {
 			return errors.Trace(err)
 		}
+
+		downloadedFiles := make([]string, 0, len(downloadFiles))
+		for _, element := range downloadFiles {
+			d.config.traceFile(TraceDownload, element)
+			downloadedFiles = append(downloadedFiles, element.Name)
+		}
+
+		d.config.downloadHooks.trigger(downloadedFiles)
 	}
 
 	d.config.Logf("[Downstream] Successfully processed %d change(s)", len(createFiles)+len(removeFiles))
@@ -445,10 +472,7 @@ func (d *downstream) downloadArchive(tarSize int64) (string, error) {
 	defer tempFile.Close()
 
 	// Apply rate limit if specified
-	var downloadReader io.Reader = d.stdoutPipe
-	if d.config.DownstreamLimit > 0 {
-		downloadReader = ratelimit.Reader(d.stdoutPipe, ratelimit.NewBucketWithRate(float64(d.config.DownstreamLimit), d.config.DownstreamLimit))
-	}
+	downloadReader := limitReader(d.stdoutPipe, d.config.DownstreamLimit)
 
 	// Write From stdout to temp file
 	bytesRead, err := io.CopyN(tempFile, downloadReader, tarSize)
@@ -479,6 +503,8 @@ func (d *downstream) removeFilesAndFolders(removeFiles map[string]*fileInformati
 		absFilepath := filepath.Join(d.config.WatchPath, key)
 
 		if shouldRemoveLocal(absFilepath, value, d.config) {
+			d.config.trace(TraceRemoveLocal, key, "")
+
 			if numRemoveFiles <= 3 || d.config.Verbose {
 				d.config.Logf("[Downstream] Remove %s", key)
 			}
@@ -493,6 +519,8 @@ func (d *downstream) removeFilesAndFolders(removeFiles map[string]*fileInformati
 					}
 				}
 			}
+		} else {
+			d.config.trace(TraceRemoveSkipped, key, "excluded, changed locally or already removed")
 		}
 
 		delete(fileMap, key)
@@ -517,6 +545,8 @@ func (d *downstream) createFolders(createFolders []*fileInformation) {
 				d.config.Logln("[Downstream] Create folder: " + element.Name)
 			}
 
+			d.config.traceFile(TraceDownload, element)
+
 			err := os.MkdirAll(path.Join(d.config.WatchPath, element.Name), 0755)
 			if err != nil {
 				d.config.Error(err)
@@ -20,6 +20,11 @@ func shouldRemoveRemote(relativePath string, s *SyncConfig) bool {
 		}
 	}
 
+	// Exclude files that were excluded by the file filter before they were removed
+	if s.fileFilter != nil && s.fileFilter.wasExcluded(relativePath) {
+		return false
+	}
+
 	// File / Folder was already deleted from map so event was already processed or should not be processed
 	if s.fileIndex.fileMap[relativePath] == nil {
 		return false
@@ -71,6 +76,13 @@ func shouldUpload(relativePath string, stat os.FileInfo, s *SyncConfig, isInitia
 			return false
 		}
 
+		// Permissions changed locally (e.g. chmod +x), unless the remote file is newer
+		if s.preservesPermissions() && stat.IsDir() == false && s.fileIndex.fileMap[relativePath].RemoteMode != 0 && roundMtime(stat.ModTime()) >= s.fileIndex.fileMap[relativePath].Mtime {
+			if permissionsChanged(stat.Mode(), s.fileIndex.fileMap[relativePath].RemoteMode) {
+				return true
+			}
+		}
+
 		if isInitial {
 			// File is older locally than remote so don't update remote
 			if roundMtime(stat.ModTime()) <= s.fileIndex.fileMap[relativePath].Mtime {
@@ -117,6 +129,11 @@ func shouldDownload(fileInformation *fileInformation, s *SyncConfig) bool {
 				return true
 			}
 
+			// Redownload file if its permissions changed in the container
+			if s.preservesPermissions() && s.fileIndex.fileMap[fileInformation.Name].RemoteMode != 0 && fileInformation.RemoteMode&permissionBits != s.fileIndex.fileMap[fileInformation.Name].RemoteMode&permissionBits {
+				return true
+			}
+
 			// Redownload file if size changed && file is not older than the one in the fileMap
 			// the mTime check is necessary, because otherwise we would override older local files that
 			// are not overridden initially
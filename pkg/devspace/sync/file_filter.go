@@ -0,0 +1,125 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/covexo/devspace/pkg/util/log"
+)
+
+const (
+	fileFilterInclude = "include"
+	fileFilterExclude = "exclude"
+)
+
+// fileFilter runs the FileFilter executable for local files and caches the decision until the file changes
+type fileFilter struct {
+	command string
+
+	mutex   sync.Mutex
+	results map[string]*fileFilterResult
+
+	warnOnce sync.Once
+}
+
+type fileFilterResult struct {
+	mtime   int64
+	size    int64
+	exclude bool
+}
+
+func newFileFilter(command string) *fileFilter {
+	return &fileFilter{
+		command: command,
+		results: map[string]*fileFilterResult{},
+	}
+}
+
+// isExcluded returns true if the filter printed exclude for the file. Directories are not filtered
+func (f *fileFilter) isExcluded(relativePath, fullPath string, stat os.FileInfo) (bool, error) {
+	if stat.IsDir() {
+		return false, nil
+	}
+
+	mtime := roundMtime(stat.ModTime())
+
+	f.mutex.Lock()
+	result := f.results[relativePath]
+	f.mutex.Unlock()
+
+	if result != nil && result.mtime == mtime && result.size == stat.Size() {
+		return result.exclude, nil
+	}
+
+	exclude, err := f.run(fullPath)
+	if err != nil {
+		return false, err
+	}
+
+	f.mutex.Lock()
+	f.results[relativePath] = &fileFilterResult{
+		mtime:   mtime,
+		size:    stat.Size(),
+		exclude: exclude,
+	}
+	f.mutex.Unlock()
+
+	return exclude, nil
+}
+
+// wasExcluded returns the last decision for a file, e.g. after it was removed locally
+func (f *fileFilter) wasExcluded(relativePath string) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	result := f.results[relativePath]
+	return result != nil && result.exclude
+}
+
+func (f *fileFilter) run(fullPath string) (bool, error) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	cmd := exec.Command(f.command)
+	cmd.Stdin = strings.NewReader(fullPath + "\n")
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return false, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	switch strings.TrimSpace(stdout.String()) {
+	case fileFilterInclude:
+		return false, nil
+	case fileFilterExclude:
+		return true, nil
+	}
+
+	return false, fmt.Errorf("Unexpected output '%s', expected %s or %s", strings.TrimSpace(stdout.String()), fileFilterInclude, fileFilterExclude)
+}
+
+// isFileFiltered returns true if the file filter excludes the local file from the upload. If the filter fails, the
+// file is included
+func (s *SyncConfig) isFileFiltered(relativePath, fullPath string, stat os.FileInfo) bool {
+	if s.fileFilter == nil {
+		return false
+	}
+
+	exclude, err := s.fileFilter.isExcluded(relativePath, fullPath, stat)
+	if err != nil {
+		s.Logf("[Sync] File filter %s failed for %s: %v", s.FileFilter, fullPath, err)
+		s.fileFilter.warnOnce.Do(func() {
+			log.Warnf("[Sync] File filter %s failed: %v. Files are included if the filter fails, for more information check .devspace/logs/sync.log", s.FileFilter, err)
+		})
+
+		return false
+	}
+
+	return exclude
+}
@@ -0,0 +1,90 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const testFileFilter = `#!/bin/sh
+read path
+echo "$path" >> "$(dirname "$0")/calls"
+case "$path" in
+  *.log) echo exclude ;;
+  *.broken) echo unknown ;;
+  *) echo include ;;
+esac
+`
+
+func TestFileFilter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("File filter test needs a shell")
+	}
+
+	dir, err := ioutil.TempDir("", "filter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filterPath := filepath.Join(dir, "filter.sh")
+	err = ioutil.WriteFile(filterPath, []byte(testFileFilter), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter := newFileFilter(filterPath)
+	testCases := map[string]bool{
+		"index.js":   false,
+		"app.log":    true,
+		"app.broken": false,
+	}
+
+	for name, expected := range testCases {
+		fullPath := filepath.Join(dir, name)
+		err = ioutil.WriteFile(fullPath, []byte("test"), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stat, err := os.Stat(fullPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 2; i++ {
+			exclude, err := filter.isExcluded("/"+name, fullPath, stat)
+			if name == "app.broken" {
+				if err == nil {
+					t.Fatalf("Expected error for unexpected filter output of %s", name)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if exclude != expected {
+				t.Fatalf("Expected exclude %v for %s, got %v", expected, name, exclude)
+			}
+		}
+	}
+
+	// Results are cached, so only the broken file runs the filter twice
+	calls, err := ioutil.ReadFile(filepath.Join(dir, "calls"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count := strings.Count(string(calls), "\n"); count != 4 {
+		t.Fatalf("Expected 4 filter calls, got %d", count)
+	}
+
+	if filter.wasExcluded("/app.log") == false {
+		t.Fatal("Expected /app.log to be remembered as excluded")
+	}
+	if filter.wasExcluded("/index.js") {
+		t.Fatal("Expected /index.js to be remembered as included")
+	}
+}
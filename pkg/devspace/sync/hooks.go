@@ -0,0 +1,212 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/juju/errors"
+)
+
+// DefaultHookDelay is the time the sync waits for further uploads or downloads before it runs the hooks, so that a
+// batch of files only runs the hooks once
+const DefaultHookDelay = 500 * time.Millisecond
+
+// HookPathEnv is the environment variable that holds the synced paths for the hook commands (separated by newlines)
+const HookPathEnv = "DEVSPACE_SYNC_PATH"
+
+// hookRunner collects synced paths and runs the hook commands once no further path was synced for the debounce
+// interval
+type hookRunner struct {
+	name     string
+	commands []string
+	delay    time.Duration
+	run      func(command string, paths []string) error
+
+	mutex sync.Mutex
+	paths []string
+	timer *time.Timer
+
+	// runMutex prevents that hooks of the next batch run before the hooks of the previous batch finished
+	runMutex sync.Mutex
+}
+
+func newHookRunner(name string, commands []string, delay time.Duration, run func(command string, paths []string) error) *hookRunner {
+	if delay <= 0 {
+		delay = DefaultHookDelay
+	}
+
+	return &hookRunner{
+		name:     name,
+		commands: commands,
+		delay:    delay,
+		run:      run,
+	}
+}
+
+// trigger adds the paths to the next hook run and restarts the debounce timer
+func (h *hookRunner) trigger(paths []string) {
+	if h == nil || len(h.commands) == 0 || len(paths) == 0 {
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.paths = append(h.paths, paths...)
+	if h.timer != nil {
+		h.timer.Stop()
+	}
+
+	h.timer = time.AfterFunc(h.delay, h.fire)
+}
+
+// stop cancels a pending hook run
+func (h *hookRunner) stop() {
+	if h == nil {
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.timer != nil {
+		h.timer.Stop()
+	}
+
+	h.paths = nil
+}
+
+func (h *hookRunner) fire() {
+	h.runMutex.Lock()
+	defer h.runMutex.Unlock()
+
+	h.mutex.Lock()
+	paths := h.paths
+	h.paths = nil
+	h.mutex.Unlock()
+
+	if len(paths) == 0 {
+		return
+	}
+
+	for _, command := range h.commands {
+		err := h.run(command, paths)
+		if err != nil {
+			log.Warnf("[Sync] %s hook '%s' failed: %v. For more information check .devspace/logs/sync.log", h.name, command, err)
+		}
+	}
+}
+
+// initHooks creates the hook runners for the OnUpload and OnDownload commands
+func (s *SyncConfig) initHooks() error {
+	if s.uploadHooks == nil {
+		uploadCommands := append([]string{}, s.OnUpload...)
+		if s.OnUploadSignal != "" {
+			signal, err := normalizeSignal(s.OnUploadSignal)
+			if err != nil {
+				return err
+			}
+
+			uploadCommands = append(uploadCommands, "kill -s "+signal+" 1")
+		}
+
+		if len(uploadCommands) > 0 {
+			s.uploadHooks = newHookRunner("Upload", uploadCommands, s.HookDelay, s.runUploadHook)
+		}
+	}
+	if len(s.OnDownload) > 0 && s.downloadHooks == nil {
+		s.downloadHooks = newHookRunner("Download", s.OnDownload, s.HookDelay, s.runDownloadHook)
+	}
+
+	return nil
+}
+
+// normalizeSignal returns the signal name without SIG prefix (e.g. HUP for SIGHUP), which kill -s understands in all
+// shells
+func normalizeSignal(signal string) (string, error) {
+	name := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(signal)), "SIG")
+	if name == "" {
+		return "", fmt.Errorf("Invalid signal '%s'", signal)
+	}
+
+	for _, c := range name {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return "", fmt.Errorf("Invalid signal '%s'", signal)
+		}
+	}
+
+	return name, nil
+}
+
+// runUploadHook runs the command in the container with the container paths of the uploaded files
+func (s *SyncConfig) runUploadHook(command string, paths []string) error {
+	pod, container := s.getPod()
+
+	containerPaths := make([]string, 0, len(paths))
+	for _, relativePath := range paths {
+		containerPaths = append(containerPaths, path.Join(s.DestPath, relativePath))
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	s.Logf("[Hook] Run upload hook in container: %s", command)
+	err := kubectl.ExecStream(s.Kubectl, pod, container.Name, []string{"env", HookPathEnv + "=" + strings.Join(containerPaths, "\n"), "sh", "-c", command}, nil, stdout, stderr)
+	s.logHookOutput(stdout.Bytes(), stderr.Bytes())
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
+
+// runDownloadHook runs the command locally in the watch path with the local paths of the downloaded files
+func (s *SyncConfig) runDownloadHook(command string, paths []string) error {
+	localPaths := make([]string, 0, len(paths))
+	for _, relativePath := range paths {
+		localPaths = append(localPaths, filepath.Join(s.WatchPath, filepath.FromSlash(relativePath)))
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = s.WatchPath
+	cmd.Env = append(os.Environ(), HookPathEnv+"="+strings.Join(localPaths, "\n"))
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	s.Logf("[Hook] Run download hook locally: %s", command)
+	err := cmd.Run()
+	s.logHookOutput(stdout.Bytes(), stderr.Bytes())
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
+
+// logHookOutput writes the output of a hook to the sync log and, if the sync is verbose, to the terminal
+func (s *SyncConfig) logHookOutput(stdout, stderr []byte) {
+	if len(stdout) > 0 {
+		s.Logf("[Hook] Output: %s", strings.TrimSpace(string(stdout)))
+		if s.Verbose {
+			log.Infof("[Sync] Hook output: %s", strings.TrimSpace(string(stdout)))
+		}
+	}
+	if len(stderr) > 0 {
+		s.Logf("[Hook] Error output: %s", strings.TrimSpace(string(stderr)))
+		if s.Verbose {
+			log.Infof("[Sync] Hook error output: %s", strings.TrimSpace(string(stderr)))
+		}
+	}
+}
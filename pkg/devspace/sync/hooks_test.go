@@ -0,0 +1,92 @@
+package sync
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHookRunnerDebounce(t *testing.T) {
+	runs := make(chan []string, 10)
+	mutex := sync.Mutex{}
+	commands := []string{}
+
+	runner := newHookRunner("Upload", []string{"first", "second"}, 50*time.Millisecond, func(command string, paths []string) error {
+		mutex.Lock()
+		commands = append(commands, command)
+		mutex.Unlock()
+
+		if command == "first" {
+			runs <- paths
+		}
+		return nil
+	})
+
+	runner.trigger([]string{"/a.js"})
+	time.Sleep(10 * time.Millisecond)
+	runner.trigger([]string{"/b.js", "/c.js"})
+
+	select {
+	case paths := <-runs:
+		if len(paths) != 3 || paths[0] != "/a.js" || paths[2] != "/c.js" {
+			t.Fatalf("Expected one hook run for all paths, got %v", paths)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Hooks didn't run")
+	}
+
+	select {
+	case paths := <-runs:
+		t.Fatalf("Hooks ran twice for one batch, second run with %v", paths)
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(commands) != 2 || commands[0] != "first" || commands[1] != "second" {
+		t.Fatalf("Expected both commands to run in order, got %v", commands)
+	}
+}
+
+func TestHookRunnerStop(t *testing.T) {
+	runs := make(chan bool, 1)
+	runner := newHookRunner("Download", []string{"make"}, 20*time.Millisecond, func(command string, paths []string) error {
+		runs <- true
+		return nil
+	})
+
+	runner.trigger([]string{"/a.js"})
+	runner.stop()
+
+	select {
+	case <-runs:
+		t.Fatal("Hook ran after the runner was stopped")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNormalizeSignal(t *testing.T) {
+	testCases := map[string]string{
+		"HUP":      "HUP",
+		"sighup":   "HUP",
+		" SIGUSR2": "USR2",
+		"15":       "15",
+	}
+
+	for signal, expected := range testCases {
+		name, err := normalizeSignal(signal)
+		if err != nil {
+			t.Fatalf("Unexpected error for signal %s: %v", signal, err)
+		}
+		if name != expected {
+			t.Fatalf("Expected signal %s for %s, got %s", expected, signal, name)
+		}
+	}
+
+	for _, signal := range []string{"", "SIG", "HUP; rm -rf /"} {
+		_, err := normalizeSignal(signal)
+		if err == nil {
+			t.Fatalf("Expected an error for signal '%s'", signal)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package sync
+
+import (
+	"sync"
+	"time"
+)
+
+// Metrics holds the transfer statistics of a sync path
+type Metrics struct {
+	LocalPath     string `json:"localPath"`
+	ContainerPath string `json:"containerPath"`
+	Pod           string `json:"pod"`
+
+	UploadsQueued   int `json:"uploadsQueued"`
+	DownloadsQueued int `json:"downloadsQueued"`
+	Uploaded        int `json:"uploaded"`
+	Downloaded      int `json:"downloaded"`
+	Errors          int `json:"errors"`
+
+	// LastSync is the time of the last successful upload or download (zero if nothing was synced yet)
+	LastSync time.Time `json:"lastSync"`
+}
+
+type syncMetrics struct {
+	metrics Metrics
+	mutex   sync.Mutex
+}
+
+func (m *syncMetrics) setUploadsQueued(amount int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.metrics.UploadsQueued = amount
+}
+
+func (m *syncMetrics) setDownloadsQueued(amount int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.metrics.DownloadsQueued = amount
+}
+
+func (m *syncMetrics) addUploaded(amount int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.metrics.UploadsQueued = 0
+	m.metrics.Uploaded += amount
+	m.metrics.LastSync = time.Now()
+}
+
+func (m *syncMetrics) addDownloaded(amount int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.metrics.DownloadsQueued = 0
+	m.metrics.Downloaded += amount
+	m.metrics.LastSync = time.Now()
+}
+
+func (m *syncMetrics) addError() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.metrics.Errors++
+}
+
+// GetMetrics returns a snapshot of the current transfer statistics
+func (s *SyncConfig) GetMetrics() *Metrics {
+	s.metrics.mutex.Lock()
+	defer s.metrics.mutex.Unlock()
+
+	metrics := s.metrics.metrics
+	metrics.LocalPath = s.WatchPath
+	metrics.ContainerPath = s.DestPath
+
+	if pod, _ := s.getPod(); pod != nil {
+		metrics.Pod = pod.Namespace + "/" + pod.Name
+	}
+
+	return &metrics
+}
@@ -0,0 +1,20 @@
+package sync
+
+import (
+	"os"
+	"runtime"
+)
+
+// permissionBits masks the permission bits of the remote mode (without setuid, setgid and sticky bit)
+const permissionBits = 0777
+
+// preservesPermissions returns true if the permissions of files are synced. Windows only knows whether a file is
+// read-only, so the permissions in the container are kept there
+func (s *SyncConfig) preservesPermissions() bool {
+	return s.PreservePermissions && runtime.GOOS != "windows"
+}
+
+// permissionsChanged returns true if the permission bits of the local mode differ from the remote mode
+func permissionsChanged(localMode os.FileMode, remoteMode int64) bool {
+	return int64(localMode.Perm()) != remoteMode&permissionBits
+}
@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestShouldSyncPermissionChanges(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows files have no unix permissions")
+	}
+
+	dir, err := ioutil.TempDir("", "permissions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	scriptPath := filepath.Join(dir, "start.sh")
+	err = ioutil.WriteFile(scriptPath, []byte("#!/bin/sh"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chmod(scriptPath, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stat, err := os.Stat(scriptPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	syncClient := &SyncConfig{
+		WatchPath:           dir,
+		PreservePermissions: true,
+		fileIndex:           newFileIndex(),
+	}
+	syncClient.fileIndex.fileMap["/start.sh"] = &fileInformation{
+		Name:       "/start.sh",
+		Mtime:      roundMtime(stat.ModTime()),
+		Size:       stat.Size(),
+		RemoteMode: 0644,
+	}
+
+	if shouldUpload("/start.sh", stat, syncClient, false) == false {
+		t.Fatal("Expected upload of a file that was made executable")
+	}
+
+	// The remote file was made executable
+	remoteFile := &fileInformation{
+		Name:       "/start.sh",
+		Mtime:      roundMtime(stat.ModTime()),
+		Size:       stat.Size(),
+		RemoteMode: 0755,
+	}
+	if shouldDownload(remoteFile, syncClient) == false {
+		t.Fatal("Expected download of a file that was made executable in the container")
+	}
+
+	syncClient.PreservePermissions = false
+	if shouldUpload("/start.sh", stat, syncClient, false) {
+		t.Fatal("Expected no upload if permissions are not preserved")
+	}
+	if shouldDownload(remoteFile, syncClient) {
+		t.Fatal("Expected no download if permissions are not preserved")
+	}
+}
@@ -0,0 +1,225 @@
+package sync
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/covexo/devspace/pkg/devspace/kubectl"
+	"github.com/covexo/devspace/pkg/util/log"
+	k8sv1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const minWatchBackoff = time.Second
+const maxWatchBackoff = time.Minute
+
+// podWatcher watches the pod of a sync and restarts the sync on the new pod if the pod is deleted or its
+// container is restarted
+type podWatcher struct {
+	config *SyncConfig
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+func newPodWatcher(config *SyncConfig) *podWatcher {
+	return &podWatcher{
+		config:   config,
+		stopChan: make(chan struct{}),
+	}
+}
+
+func (w *podWatcher) stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopChan)
+	})
+}
+
+func (w *podWatcher) run() {
+	backoff := minWatchBackoff
+
+	for {
+		restart, err := w.watch()
+		if err != nil {
+			w.config.Logf("[Sync] Error watching pod: %v. Reconnecting in %v", err, backoff)
+			if w.wait(backoff) == false {
+				return
+			}
+
+			backoff = nextBackoff(backoff)
+			continue
+		}
+
+		backoff = minWatchBackoff
+		if restart {
+			w.reconnect()
+		}
+
+		if w.isStopped() {
+			return
+		}
+	}
+}
+
+// watch blocks until the pod of the sync is deleted or restarted (returns true) or the watch fails
+func (w *podWatcher) watch() (bool, error) {
+	pod, container := w.config.getPod()
+	pods := w.config.Kubectl.CoreV1().Pods(pod.Namespace)
+
+	currentPod, err := pods.Get(pod.Name, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		return false, err
+	}
+	if isPodRestarted(pod, currentPod, container.Name) {
+		return true, nil
+	}
+
+	watcher, err := pods.Watch(metav1.ListOptions{
+		FieldSelector:   "metadata.name=" + pod.Name,
+		ResourceVersion: currentPod.ResourceVersion,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-w.stopChan:
+			return false, nil
+		case event, ok := <-watcher.ResultChan():
+			if ok == false {
+				return false, fmt.Errorf("Watch closed")
+			}
+
+			switch event.Type {
+			case watch.Deleted:
+				return true, nil
+			case watch.Error:
+				return false, kerrors.FromObject(event.Object)
+			case watch.Modified:
+				newPod, ok := event.Object.(*k8sv1.Pod)
+				if ok && (newPod.DeletionTimestamp != nil || isPodRestarted(pod, newPod, container.Name)) {
+					return true, nil
+				}
+			}
+		}
+	}
+}
+
+// reconnect stops the sync and restarts it as soon as the new pod is running
+func (w *podWatcher) reconnect() {
+	s := w.config
+	oldPod, container := s.getPod()
+	containerName := container.Name
+
+	s.stopSession(s.currentSession(), nil)
+	log.Warnf("[Sync] Pod %s/%s was restarted. Waiting for the pod to resume sync of %s", oldPod.Namespace, oldPod.Name, s.WatchPath)
+
+	backoff := minWatchBackoff
+	for attempt := 1; ; attempt++ {
+		var pod *k8sv1.Pod
+		var err error
+
+		if s.LabelSelector != "" {
+			pod, err = kubectl.GetNewestRunningPod(s.Kubectl, s.LabelSelector, oldPod.Namespace)
+		} else {
+			pod, err = kubectl.GetRunningPodByName(s.Kubectl, oldPod.Name, oldPod.Namespace)
+		}
+
+		if w.isStopped() {
+			return
+		}
+
+		if err == nil {
+			err = kubectl.CheckProtectedPod(pod, s.AllowProtected)
+		}
+
+		if err == nil {
+			container := getContainer(pod, containerName)
+			if container == nil {
+				err = fmt.Errorf("Container %s not found in pod %s/%s", containerName, pod.Namespace, pod.Name)
+			} else {
+				err = s.restart(pod, container)
+				if err == nil {
+					log.Donef("[Sync] Sync resumed on %s <-> %s (Pod: %s/%s)", s.WatchPath, s.DestPath, pod.Namespace, pod.Name)
+					return
+				}
+			}
+		}
+
+		s.Logf("[Sync] Reconnect attempt %d failed: %v. Retrying in %v", attempt, err, backoff)
+		if w.wait(backoff) == false {
+			return
+		}
+
+		backoff = nextBackoff(backoff)
+	}
+}
+
+// wait waits for the given duration and returns false if the watcher was stopped meanwhile
+func (w *podWatcher) wait(duration time.Duration) bool {
+	select {
+	case <-w.stopChan:
+		return false
+	case <-time.After(duration):
+		return true
+	}
+}
+
+func (w *podWatcher) isStopped() bool {
+	select {
+	case <-w.stopChan:
+		return true
+	default:
+		return false
+	}
+}
+
+func nextBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > maxWatchBackoff {
+		return maxWatchBackoff
+	}
+
+	return backoff
+}
+
+// isPodRestarted checks if the container of the new pod state was restarted since the old pod state
+func isPodRestarted(oldPod, newPod *k8sv1.Pod, containerName string) bool {
+	if oldPod.UID != newPod.UID {
+		return true
+	}
+
+	for _, oldStatus := range oldPod.Status.ContainerStatuses {
+		if oldStatus.Name != containerName {
+			continue
+		}
+
+		for _, newStatus := range newPod.Status.ContainerStatuses {
+			if newStatus.Name == containerName && newStatus.RestartCount > oldStatus.RestartCount {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func getContainer(pod *k8sv1.Pod, containerName string) *k8sv1.Container {
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == containerName {
+			return &pod.Spec.Containers[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package sync
+
+import (
+	"testing"
+	"time"
+
+	k8sv1 "k8s.io/api/core/v1"
+)
+
+func TestIsPodRestarted(t *testing.T) {
+	oldPod := &k8sv1.Pod{}
+	oldPod.UID = "1"
+	oldPod.Status.ContainerStatuses = []k8sv1.ContainerStatus{{Name: "default", RestartCount: 1}, {Name: "sidecar"}}
+
+	newPod := oldPod.DeepCopy()
+	if isPodRestarted(oldPod, newPod, "default") {
+		t.Fatal("Unchanged pod detected as restarted")
+	}
+
+	newPod.Status.ContainerStatuses[1].RestartCount = 3
+	if isPodRestarted(oldPod, newPod, "default") {
+		t.Fatal("Restart of another container detected as restart")
+	}
+
+	newPod.Status.ContainerStatuses[0].RestartCount = 2
+	if isPodRestarted(oldPod, newPod, "default") == false {
+		t.Fatal("Container restart not detected")
+	}
+
+	newPod = oldPod.DeepCopy()
+	newPod.UID = "2"
+	if isPodRestarted(oldPod, newPod, "default") == false {
+		t.Fatal("Recreated pod not detected")
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	if nextBackoff(minWatchBackoff) != 2*time.Second {
+		t.Fatalf("Expected backoff to double, got %v", nextBackoff(minWatchBackoff))
+	}
+	if nextBackoff(40*time.Second) != maxWatchBackoff {
+		t.Fatalf("Expected backoff to be capped at %v, got %v", maxWatchBackoff, nextBackoff(40*time.Second))
+	}
+}
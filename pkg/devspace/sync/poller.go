@@ -0,0 +1,127 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rjeczalik/notify"
+)
+
+// polledFile holds the state of a local file that is compared between two polls
+type polledFile struct {
+	mtime       int64
+	size        int64
+	mode        os.FileMode
+	isDirectory bool
+}
+
+// pollEvent is a local change that was found by polling. It is handled like a file system event
+type pollEvent struct {
+	path  string
+	event notify.Event
+}
+
+func (p *pollEvent) Event() notify.Event {
+	return p.event
+}
+
+func (p *pollEvent) Path() string {
+	return p.path
+}
+
+func (p *pollEvent) Sys() interface{} {
+	return nil
+}
+
+// pollChanges scans the watch path every PollingInterval and sends an event to the upstream for every file that was
+// created, changed or removed since the last scan. It is used instead of file system events, which are not available
+// on some network and virtualized file systems. lastScan is the state the first scan is compared to
+func (s *SyncConfig) pollChanges(lastScan map[string]*polledFile, events chan<- notify.EventInfo, interrupt <-chan bool) {
+	for {
+		select {
+		case <-interrupt:
+			return
+		case <-time.After(s.PollingInterval):
+		}
+
+		currentScan := s.scanWatchPath()
+		for _, event := range diffScans(lastScan, currentScan) {
+			event.path = filepath.Join(s.WatchPath, filepath.FromSlash(event.path))
+
+			select {
+			case <-interrupt:
+				return
+			case events <- event:
+			}
+		}
+
+		lastScan = currentScan
+	}
+}
+
+// scanWatchPath returns the state of all files in the watch path by their relative path. Excluded directories
+// (excludePaths and uploadExcludePaths) are not walked
+func (s *SyncConfig) scanWatchPath() map[string]*polledFile {
+	files := map[string]*polledFile{}
+
+	filepath.Walk(s.WatchPath, func(fullPath string, info os.FileInfo, err error) error {
+		// Files that were removed during the walk are found on the next scan
+		if err != nil || fullPath == s.WatchPath {
+			return nil
+		}
+
+		relativePath := getRelativeFromFullPath(fullPath, s.WatchPath)
+		if s.isPollingExcluded(relativePath) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		files[relativePath] = &polledFile{
+			mtime:       roundMtime(info.ModTime()),
+			size:        info.Size(),
+			mode:        info.Mode(),
+			isDirectory: info.IsDir(),
+		}
+
+		return nil
+	})
+
+	return files
+}
+
+func (s *SyncConfig) isPollingExcluded(relativePath string) bool {
+	if s.ignoreMatcher != nil && s.ignoreMatcher.MatchesPath(relativePath) {
+		return true
+	}
+	if s.uploadIgnoreMatcher != nil && s.uploadIgnoreMatcher.MatchesPath(relativePath) {
+		return true
+	}
+
+	return false
+}
+
+// diffScans returns an event for every path that was created, changed or removed between both scans
+func diffScans(lastScan, currentScan map[string]*polledFile) []*pollEvent {
+	events := []*pollEvent{}
+
+	for relativePath, current := range currentScan {
+		last, ok := lastScan[relativePath]
+		if ok == false {
+			events = append(events, &pollEvent{path: relativePath, event: notify.Create})
+		} else if last.mtime != current.mtime || last.size != current.size || last.mode != current.mode || last.isDirectory != current.isDirectory {
+			events = append(events, &pollEvent{path: relativePath, event: notify.Write})
+		}
+	}
+
+	for relativePath := range lastScan {
+		if _, ok := currentScan[relativePath]; ok == false {
+			events = append(events, &pollEvent{path: relativePath, event: notify.Remove})
+		}
+	}
+
+	return events
+}
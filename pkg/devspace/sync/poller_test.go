@@ -0,0 +1,80 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rjeczalik/notify"
+)
+
+func TestDiffScans(t *testing.T) {
+	lastScan := map[string]*polledFile{
+		"/unchanged.js": {mtime: 1, size: 10},
+		"/changed.js":   {mtime: 1, size: 10},
+		"/resized.js":   {mtime: 1, size: 10},
+		"/removed.js":   {mtime: 1, size: 10},
+	}
+	currentScan := map[string]*polledFile{
+		"/unchanged.js": {mtime: 1, size: 10},
+		"/changed.js":   {mtime: 2, size: 10},
+		"/resized.js":   {mtime: 1, size: 20},
+		"/created":      {mtime: 1, isDirectory: true},
+	}
+
+	expected := map[string]notify.Event{
+		"/changed.js": notify.Write,
+		"/resized.js": notify.Write,
+		"/removed.js": notify.Remove,
+		"/created":    notify.Create,
+	}
+
+	events := diffScans(lastScan, currentScan)
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(events))
+	}
+
+	for _, event := range events {
+		if expected[event.Path()] != event.Event() {
+			t.Fatalf("Expected event %v for %s, got %v", expected[event.Path()], event.Path(), event.Event())
+		}
+	}
+}
+
+func TestScanWatchPathExcludes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "poller")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, path := range []string{"index.js", "node_modules/test/index.js", "build/app.js"} {
+		fullPath := filepath.Join(dir, filepath.FromSlash(path))
+
+		err = os.MkdirAll(filepath.Dir(fullPath), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(fullPath, []byte("test"), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	syncClient := &SyncConfig{
+		WatchPath:          dir,
+		ExcludePaths:       []string{"node_modules/"},
+		UploadExcludePaths: []string{"/build"},
+	}
+
+	err = syncClient.initIgnoreParsers()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := syncClient.scanWatchPath()
+	if len(files) != 1 || files["/index.js"] == nil {
+		t.Fatalf("Expected only /index.js to be scanned, got %v", files)
+	}
+}
@@ -0,0 +1,120 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/covexo/devspace/pkg/util/log"
+)
+
+// statusPath is the http path where the status server serves the metrics
+const statusPath = "/status"
+
+// StatusServer serves the metrics of the running sync paths on a random local port
+type StatusServer struct {
+	syncConfigs []*SyncConfig
+	listener    net.Listener
+	server      *http.Server
+}
+
+// getStatusAddressFile returns the file where the address of the status server is stored. The file is placed in the
+// log folder, because that folder is excluded from the upstream
+func getStatusAddressFile() string {
+	return log.Logdir + "sync-status"
+}
+
+// StartStatusServer starts an http server on localhost that serves the metrics of the given sync paths and
+// saves its address, so that other devspace commands can query it
+func StartStatusServer(syncConfigs []*SyncConfig) (*StatusServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("Error starting sync status server: %v", err)
+	}
+
+	statusServer := &StatusServer{
+		syncConfigs: syncConfigs,
+		listener:    listener,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(statusPath, statusServer.handleStatus)
+
+	statusServer.server = &http.Server{
+		Handler: mux,
+	}
+
+	err = os.MkdirAll(log.Logdir, 0755)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	err = ioutil.WriteFile(getStatusAddressFile(), []byte(listener.Addr().String()), 0600)
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("Error saving sync status server address: %v", err)
+	}
+
+	go statusServer.server.Serve(listener)
+
+	return statusServer, nil
+}
+
+func (s *StatusServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	metrics := make([]*Metrics, 0, len(s.syncConfigs))
+	for _, syncConfig := range s.syncConfigs {
+		metrics = append(metrics, syncConfig.GetMetrics())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metrics)
+}
+
+// Stop shuts the status server down and removes the saved address
+func (s *StatusServer) Stop() {
+	s.server.Close()
+	os.Remove(getStatusAddressFile())
+}
+
+// GetStatus queries the status server of the running sync and returns the metrics of all sync paths
+func GetStatus() ([]*Metrics, error) {
+	address, err := ioutil.ReadFile(getStatusAddressFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New("Sync is not running. Start it with `devspace up`")
+		}
+
+		return nil, err
+	}
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+	}
+
+	resp, err := client.Get("http://" + strings.TrimSpace(string(address)) + statusPath)
+	if err != nil {
+		// The address file is left behind if devspace up was killed
+		return nil, fmt.Errorf("Sync is not running or not reachable: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Sync status server returned %s", resp.Status)
+	}
+
+	metrics := []*Metrics{}
+	err = json.NewDecoder(resp.Body).Decode(&metrics)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing sync status: %v", err)
+	}
+
+	return metrics, nil
+}
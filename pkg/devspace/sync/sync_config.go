@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -31,7 +32,7 @@ const ErrorAck string = "ERROR"
 
 // SyncConfig holds the necessary information for the syncing process
 type SyncConfig struct {
-	Kubectl              *kubernetes.Clientset
+	Kubectl              kubernetes.Interface
 	Pod                  *k8sv1.Pod
 	Container            *k8sv1.Container
 	WatchPath            string
@@ -42,32 +43,103 @@ type SyncConfig struct {
 	UpstreamLimit        int64
 	DownstreamLimit      int64
 	Verbose              bool
+	ConflictPolicy       ConflictPolicy
+
+	// TracePath is the file where all file events, transfers and decisions are recorded to (disabled if empty)
+	TracePath string
+
+	// ConflictPath is the directory where both versions of conflicting files are saved with the keepBoth conflict
+	// policy (default: DefaultConflictPath)
+	ConflictPath string
+
+	// Reconnect restarts the sync on the new pod if the pod is deleted or its container is restarted
+	Reconnect bool
+
+	// LabelSelector is used to find the new pod on reconnect. If empty, the sync waits for a pod with the same name
+	LabelSelector string
+
+	// AllowProtected allows to reconnect to pods that are annotated as protected
+	AllowProtected bool
+
+	// OnUpload holds shell commands that are run in the container after files were uploaded
+	OnUpload []string
+
+	// OnDownload holds shell commands that are run locally after files were downloaded
+	OnDownload []string
+
+	// OnUploadSignal is sent to the process with PID 1 in the container after files were uploaded (e.g. HUP)
+	OnUploadSignal string
+
+	// HookDelay is the time without further uploads or downloads before the hooks run (default: DefaultHookDelay)
+	HookDelay time.Duration
+
+	// PollingInterval makes the sync scan the watch path for changes in this interval instead of relying on file
+	// system events (disabled if 0)
+	PollingInterval time.Duration
+
+	// PreservePermissions syncs the permission bits (e.g. the executable bit) of files in both directions. Otherwise
+	// uploaded files keep the permissions of the container file and downloaded files the permissions of the local file
+	PreservePermissions bool
+
+	// FileFilter is a local executable that gets the path of a local file on stdin and prints include or exclude.
+	// Excluded files are handled like files on the upload exclude list
+	FileFilter string
+
+	// FatalErrors receives the error that stopped the sync, so that the caller can exit with a proper exit code. If
+	// nil, devspace exits on fatal sync errors. Errors are dropped if the channel is full
+	FatalErrors chan<- error
 
 	fileIndex *fileIndex
+	metrics   syncMetrics
+	tracer    *syncTracer
+
+	// pendingDownloads holds the remote changes that were found, but not downloaded yet (guarded by the fileMapMutex)
+	pendingDownloads map[string]*fileInformation
 
 	ignoreMatcher         gitignore.IgnoreParser
 	downloadIgnoreMatcher gitignore.IgnoreParser
 	uploadIgnoreMatcher   gitignore.IgnoreParser
+	fileFilter            *fileFilter
 
 	log *logrus.Logger
 
 	upstream   *upstream
 	downstream *downstream
 
+	uploadHooks   *hookRunner
+	downloadHooks *hookRunner
+
 	silent   bool
 	stopOnce sync.Once
 
+	// overwriteLocal overwrites local files on download even if they are newer (used by DownloadFromContainer)
+	overwriteLocal bool
+
+	// session is increased on every reconnect, so that stops of the previous session are ignored
+	session      int
+	sessionMutex sync.Mutex
+	watcher      *podWatcher
+
+	// podMutex guards Pod and Container, which are replaced when the sync is restarted on a new pod. It is separate
+	// from sessionMutex, because the sync logs while holding sessionMutex
+	podMutex sync.Mutex
+
 	// Used for testing
 	testing   bool
 	errorChan chan error
 	readyChan chan bool
 }
 
+// SetSyncLog overrides the logger for the sync log (by default the sync logs to .devspace/logs/sync.log)
+func SetSyncLog(logger log.Logger) {
+	syncLog = logger
+}
+
 // Logf prints the given information to the synclog with context data
 func (s *SyncConfig) Logf(format string, args ...interface{}) {
 	if s.silent == false {
-		if s.Pod != nil {
-			syncLog.WithKey("pod", s.Pod.Name).WithKey("local", s.WatchPath).WithKey("container", s.DestPath).Infof(format, args...)
+		if pod, _ := s.getPod(); pod != nil {
+			syncLog.WithKey("pod", pod.Name).WithKey("local", s.WatchPath).WithKey("container", s.DestPath).Infof(format, args...)
 		} else {
 			syncLog.WithKey("local", s.WatchPath).WithKey("container", s.DestPath).Infof(format, args...)
 		}
@@ -77,8 +149,8 @@ func (s *SyncConfig) Logf(format string, args ...interface{}) {
 // Logln prints the given information to the synclog with context data
 func (s *SyncConfig) Logln(line interface{}) {
 	if s.silent == false {
-		if s.Pod != nil {
-			syncLog.WithKey("pod", s.Pod.Name).WithKey("local", s.WatchPath).WithKey("container", s.DestPath).Info(line)
+		if pod, _ := s.getPod(); pod != nil {
+			syncLog.WithKey("pod", pod.Name).WithKey("local", s.WatchPath).WithKey("container", s.DestPath).Info(line)
 		} else {
 			syncLog.
 				WithKey("local",
@@ -91,12 +163,15 @@ func (s *SyncConfig) Logln(line interface{}) {
 
 // Error handles a sync error with context
 func (s *SyncConfig) Error(err error) {
-	if s.Pod != nil {
-		syncLog.WithKey("pod", s.Pod.Name).WithKey("local", s.WatchPath).WithKey("container", s.DestPath).Errorf("Error: %v, Stack: %v", err, errors.ErrorStack(err))
+	if pod, _ := s.getPod(); pod != nil {
+		syncLog.WithKey("pod", pod.Name).WithKey("local", s.WatchPath).WithKey("container", s.DestPath).Errorf("Error: %v, Stack: %v", err, errors.ErrorStack(err))
 	} else {
 		syncLog.WithKey("local", s.WatchPath).WithKey("container", s.DestPath).Errorf("Error: %v, Stack: %v", err, errors.ErrorStack(err))
 	}
 
+	s.metrics.addError()
+	s.trace(TraceError, "", err.Error())
+
 	if s.errorChan != nil {
 		s.errorChan <- err
 	}
@@ -117,6 +192,7 @@ func (s *SyncConfig) setup() error {
 
 	// We exclude the sync log to prevent an endless loop in upstream
 	s.fileIndex = newFileIndex()
+	s.pendingDownloads = make(map[string]*fileInformation)
 	s.ExcludePaths = append(s.ExcludePaths, "/.devspace/logs")
 
 	if syncLog == nil {
@@ -135,11 +211,30 @@ func (s *SyncConfig) setup() error {
 		syncLog.SetLevel(logrus.InfoLevel)
 	}
 
+	if s.TracePath != "" && s.tracer == nil {
+		err = s.setupTracer()
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	if s.ConflictPolicy == ConflictPolicyKeepBoth {
+		err = s.setupConflictPath()
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
 	err = s.initIgnoreParsers()
 	if err != nil {
 		return errors.Trace(err)
 	}
 
+	err = s.initHooks()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	// Init upstream
 	s.upstream = &upstream{
 		config: s,
@@ -165,14 +260,47 @@ func (s *SyncConfig) Start() error {
 		return errors.Trace(err)
 	}
 
+	session := s.currentSession()
+
 	err = s.downstream.start()
 	if err != nil {
-		s.Stop(nil)
+		s.stopSession(session, nil)
+		return errors.Trace(err)
+	}
+
+	go s.mainLoop(session)
+
+	if s.Reconnect && s.watcher == nil {
+		s.watcher = newPodWatcher(s)
+		go s.watcher.run()
+	}
+
+	return nil
+}
+
+func (s *SyncConfig) setupTracer() error {
+	tracePath, err := filepath.Abs(s.TracePath)
+	if err != nil {
 		return errors.Trace(err)
 	}
 
-	go s.mainLoop()
+	// We exclude the trace file if it is within the watch path to prevent an endless loop in upstream
+	relativePath, err := filepath.Rel(s.WatchPath, tracePath)
+	if err == nil && strings.HasPrefix(relativePath, "..") == false {
+		s.ExcludePaths = append(s.ExcludePaths, "/"+filepath.ToSlash(relativePath))
+	}
+
+	s.tracer, err = newSyncTracer(tracePath)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	message := s.WatchPath + " <-> " + s.DestPath
+	if pod, _ := s.getPod(); pod != nil {
+		message += " (Pod: " + pod.Namespace + "/" + pod.Name + ")"
+	}
 
+	s.trace(TraceStart, "", message)
 	return nil
 }
 
@@ -204,58 +332,67 @@ func (s *SyncConfig) initIgnoreParsers() error {
 		s.uploadIgnoreMatcher = ignoreMatcher
 	}
 
+	if s.FileFilter != "" && s.fileFilter == nil {
+		s.fileFilter = newFileFilter(s.FileFilter)
+	}
+
 	return nil
 }
 
-func (s *SyncConfig) mainLoop() {
+func (s *SyncConfig) mainLoop(session int) {
 	s.Logf("[Sync] Start syncing")
 
 	// Start upstream as early as possible
-	go s.startUpstream()
+	go s.startUpstream(session)
 
 	// Start downstream and do initial sync
 	go func() {
-		defer s.Stop(nil)
+		defer s.stopSession(session, nil)
 
 		err := s.initialSync()
 		if err != nil {
-			s.Stop(err)
+			s.stopSession(session, err)
 			return
 		}
 
 		s.Logf("[Sync] Initial sync completed")
-		s.startDownstream()
+		s.startDownstream(session)
 	}()
 }
 
-func (s *SyncConfig) startUpstream() {
-	defer s.Stop(nil)
+func (s *SyncConfig) startUpstream(session int) {
+	defer s.stopSession(session, nil)
 
-	// Set up a watchpoint listening for events within a directory tree rooted at specified directory
-	err := notify.Watch(s.WatchPath+"/...", s.upstream.events, notify.All)
-	if err != nil {
-		s.Stop(err)
-		return
-	}
+	if s.PollingInterval > 0 {
+		// The first scan is done before the sync is ready, so that no change after that is missed
+		go s.pollChanges(s.scanWatchPath(), s.upstream.events, s.upstream.interrupt)
+	} else {
+		// Set up a watchpoint listening for events within a directory tree rooted at specified directory
+		err := notify.Watch(s.WatchPath+"/...", s.upstream.events, notify.All)
+		if err != nil {
+			s.stopSession(session, err)
+			return
+		}
 
-	defer notify.Stop(s.upstream.events)
+		defer notify.Stop(s.upstream.events)
+	}
 
 	if s.readyChan != nil {
 		s.readyChan <- true
 	}
 
-	err = s.upstream.mainLoop()
+	err := s.upstream.mainLoop()
 	if err != nil {
-		s.Stop(err)
+		s.stopSession(session, err)
 	}
 }
 
-func (s *SyncConfig) startDownstream() {
-	defer s.Stop(nil)
+func (s *SyncConfig) startDownstream(session int) {
+	defer s.stopSession(session, nil)
 
 	err := s.downstream.mainLoop()
 	if err != nil {
-		s.Stop(err)
+		s.stopSession(session, err)
 	}
 }
 
@@ -297,6 +434,8 @@ func (s *SyncConfig) initialSync() error {
 		if err != nil {
 			return errors.Trace(err)
 		}
+
+		s.metrics.addDownloaded(len(remoteChanges))
 	}
 
 	return nil
@@ -313,24 +452,22 @@ func (s *SyncConfig) diffServerClient(filepath string, sendChanges *[]*fileInfor
 
 	delete(downloadChanges, relativePath)
 
-	// Exclude changes on the upload exclude list
-	if s.uploadIgnoreMatcher != nil {
-		if s.uploadIgnoreMatcher.MatchesPath(relativePath) {
-			s.fileIndex.fileMapMutex.Lock()
-			// Add to file map and prevent download if local file is newer than the remote one
-			if s.fileIndex.fileMap[relativePath] != nil && s.fileIndex.fileMap[relativePath].Mtime < roundMtime(stat.ModTime()) {
-				// Add it to the fileMap
-				s.fileIndex.fileMap[relativePath] = &fileInformation{
-					Name:        relativePath,
-					Mtime:       roundMtime(stat.ModTime()),
-					Size:        stat.Size(),
-					IsDirectory: stat.IsDir(),
-				}
+	// Exclude changes on the upload exclude list and files that are excluded by the file filter
+	if (s.uploadIgnoreMatcher != nil && s.uploadIgnoreMatcher.MatchesPath(relativePath)) || s.isFileFiltered(relativePath, filepath, stat) {
+		s.fileIndex.fileMapMutex.Lock()
+		// Add to file map and prevent download if local file is newer than the remote one
+		if s.fileIndex.fileMap[relativePath] != nil && s.fileIndex.fileMap[relativePath].Mtime < roundMtime(stat.ModTime()) {
+			// Add it to the fileMap
+			s.fileIndex.fileMap[relativePath] = &fileInformation{
+				Name:        relativePath,
+				Mtime:       roundMtime(stat.ModTime()),
+				Size:        stat.Size(),
+				IsDirectory: stat.IsDir(),
 			}
-			s.fileIndex.fileMapMutex.Unlock()
-
-			dontSend = true
 		}
+		s.fileIndex.fileMapMutex.Unlock()
+
+		dontSend = true
 	}
 
 	if stat.IsDir() {
@@ -418,6 +555,59 @@ func (s *SyncConfig) sendChangesToUpstream(changes []*fileInformation) {
 
 // Stop stops the sync process
 func (s *SyncConfig) Stop(fatalError error) {
+	if s.watcher != nil {
+		s.watcher.stop()
+	}
+
+	s.stopSession(s.currentSession(), fatalError)
+	s.uploadHooks.stop()
+	s.downloadHooks.stop()
+
+	if s.tracer != nil {
+		s.trace(TraceStop, "", "")
+		s.tracer.close()
+	}
+}
+
+func (s *SyncConfig) currentSession() int {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+
+	return s.session
+}
+
+// restart starts the sync again on the given pod after the previous session was stopped
+func (s *SyncConfig) restart(pod *k8sv1.Pod, container *k8sv1.Container) error {
+	s.sessionMutex.Lock()
+	s.session++
+	s.stopOnce = sync.Once{}
+	s.podMutex.Lock()
+	s.Pod = pod
+	s.Container = container
+	s.podMutex.Unlock()
+	s.sessionMutex.Unlock()
+
+	return s.Start()
+}
+
+// getPod returns the pod and the container the sync currently runs on
+func (s *SyncConfig) getPod() (*k8sv1.Pod, *k8sv1.Container) {
+	s.podMutex.Lock()
+	defer s.podMutex.Unlock()
+
+	return s.Pod, s.Container
+}
+
+// stopSession stops the sync process of the given session. Stops of previous sessions are ignored, because their
+// goroutines may still shut down after the sync was restarted on a new pod
+func (s *SyncConfig) stopSession(session int, fatalError error) {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+
+	if session != s.session {
+		return
+	}
+
 	s.stopOnce.Do(func() {
 		if s.upstream != nil && s.upstream.interrupt != nil {
 			close(s.upstream.interrupt)
@@ -457,7 +647,18 @@ func (s *SyncConfig) Stop(fatalError error) {
 
 		if fatalError != nil {
 			s.Error(fatalError)
+		}
+
+		// With reconnect enabled, the pod watcher resumes the sync on the new pod
+		if fatalError != nil && s.Reconnect == false && s.FatalErrors != nil {
+			select {
+			case s.FatalErrors <- fatalError:
+			default:
+			}
+		} else if fatalError != nil && s.Reconnect == false {
 			log.Fatalf("[Sync] Fatal sync error: %v. For more information check .devspace/logs/sync.log", fatalError)
+		} else if fatalError != nil {
+			log.Warnf("[Sync] Sync error: %v. Waiting for the pod to restart", fatalError)
 		}
 	})
 }
@@ -60,8 +60,19 @@ func untarNext(tarReader *tar.Reader, destPath, prefix string, config *SyncConfi
 
 	// Check if newer file is there and then don't override?
 	stat, err := os.Stat(outFileName)
+	override := false
 
-	if err == nil {
+	if err == nil && config.overwriteLocal == false && header.FileInfo().IsDir() == false && hasConflictingLocalChange(relativePath, stat, header.FileInfo().ModTime().Unix(), config) {
+		override, err = config.resolveDownstreamConflict(relativePath, outFileName, stat, header, tarReader)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if override == false {
+			return true, nil
+		}
+	}
+
+	if err == nil && override == false && config.overwriteLocal == false {
 		if roundMtime(stat.ModTime()) > header.FileInfo().ModTime().Unix() {
 			// Update filemap otherwise we download and download again
 			config.fileIndex.fileMap[relativePath] = &fileInformation{
@@ -116,7 +127,10 @@ func untarNext(tarReader *tar.Reader, destPath, prefix string, config *SyncConfi
 		return false, errors.Trace(err)
 	}
 
-	if stat != nil {
+	if config.preservesPermissions() {
+		// Apply the permissions of the container file
+		_ = os.Chmod(outFileName, os.FileMode(header.Mode).Perm())
+	} else if stat != nil {
 		// Set old permissions correctly
 		_ = os.Chmod(outFileName, stat.Mode())
 
@@ -138,6 +152,9 @@ func untarNext(tarReader *tar.Reader, destPath, prefix string, config *SyncConfi
 		Mtime:       header.FileInfo().ModTime().Unix(),
 		Size:        header.FileInfo().Size(),
 		IsDirectory: false,
+		RemoteMode:  header.Mode,
+		RemoteUID:   header.Uid,
+		RemoteGID:   header.Gid,
 	}
 
 	return true, nil
@@ -225,6 +242,11 @@ func recursiveTar(basePath, relativePath string, writtenFiles map[string]*fileIn
 		return nil
 	}
 
+	// Exclude files that are excluded by the file filter
+	if config.isFileFiltered(relativePath, filepath, stat) {
+		return nil
+	}
+
 	fileInformation := createFileInformationFromStat(relativePath, stat, config)
 
 	if stat.IsDir() {
@@ -298,6 +320,12 @@ func tarFile(basePath string, fileInformation *fileInformation, writtenFiles map
 	}
 	config.fileIndex.fileMapMutex.Unlock()
 
+	// Upload the local permissions, the owner of existing files is kept
+	if config.preservesPermissions() {
+		hdr.Mode = int64(stat.Mode().Perm())
+		fileInformation.RemoteMode = hdr.Mode
+	}
+
 	if err := tw.WriteHeader(hdr); err != nil {
 		return errors.Trace(err)
 	}
@@ -0,0 +1,201 @@
+package sync
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Trace event types that are written to a sync trace
+const (
+	TraceStart         = "start"
+	TraceStop          = "stop"
+	TraceLocalEvent    = "localEvent"
+	TraceUploadSkipped = "uploadSkipped"
+	TraceUpload        = "upload"
+	TraceRemoveRemote  = "removeRemote"
+	TraceDownload      = "download"
+	TraceRemoveLocal   = "removeLocal"
+	TraceRemoveSkipped = "removeLocalSkipped"
+	TraceConflict      = "conflict"
+	TraceError         = "error"
+)
+
+// TraceEvent is a single entry of a sync trace. A trace file contains one json encoded event per line
+type TraceEvent struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Path    string    `json:"path,omitempty"`
+	Size    int64     `json:"size,omitempty"`
+	Mtime   int64     `json:"mtime,omitempty"`
+	Message string    `json:"message,omitempty"`
+}
+
+// TraceSummary holds the aggregated information of a sync trace
+type TraceSummary struct {
+	Start  time.Time
+	End    time.Time
+	Counts map[string]int
+
+	// Errors and Conflicts hold the messages of all error and conflict events
+	Errors    []*TraceEvent
+	Conflicts []*TraceEvent
+
+	// NotUploaded holds the paths that changed locally, but were never uploaded (possibly missed syncs)
+	NotUploaded []string
+
+	// Bounced holds the paths that were uploaded and downloaded again (possibly spurious syncs)
+	Bounced []string
+}
+
+type syncTracer struct {
+	file    *os.File
+	encoder *json.Encoder
+	mutex   sync.Mutex
+}
+
+func newSyncTracer(path string) (*syncTracer, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create sync trace %s: %v", path, err)
+	}
+
+	return &syncTracer{
+		file:    file,
+		encoder: json.NewEncoder(file),
+	}, nil
+}
+
+func (t *syncTracer) write(event *TraceEvent) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.file == nil {
+		return
+	}
+
+	event.Time = time.Now()
+
+	// A failing trace should never break the sync itself
+	t.encoder.Encode(event)
+}
+
+func (t *syncTracer) close() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+	}
+}
+
+// trace records the event if a trace path was configured
+func (s *SyncConfig) trace(eventType, path, message string) {
+	if s.tracer != nil {
+		s.tracer.write(&TraceEvent{
+			Type:    eventType,
+			Path:    path,
+			Message: message,
+		})
+	}
+}
+
+// traceFile records the event including the file information if a trace path was configured
+func (s *SyncConfig) traceFile(eventType string, file *fileInformation) {
+	if s.tracer != nil {
+		s.tracer.write(&TraceEvent{
+			Type:  eventType,
+			Path:  file.Name,
+			Size:  file.Size,
+			Mtime: file.Mtime,
+		})
+	}
+}
+
+// ReadTrace parses the events of a sync trace
+func ReadTrace(reader io.Reader) ([]*TraceEvent, error) {
+	events := []*TraceEvent{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		event := &TraceEvent{}
+		err := json.Unmarshal(scanner.Bytes(), event)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing line %d: %v", line, err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// SummarizeTrace aggregates the given trace events
+func SummarizeTrace(events []*TraceEvent) *TraceSummary {
+	summary := &TraceSummary{
+		Counts:      make(map[string]int),
+		Errors:      []*TraceEvent{},
+		Conflicts:   []*TraceEvent{},
+		NotUploaded: []string{},
+		Bounced:     []string{},
+	}
+
+	changedLocally := make(map[string]bool)
+	uploaded := make(map[string]bool)
+	downloaded := make(map[string]bool)
+
+	for _, event := range events {
+		if summary.Start.IsZero() || event.Time.Before(summary.Start) {
+			summary.Start = event.Time
+		}
+		if event.Time.After(summary.End) {
+			summary.End = event.Time
+		}
+
+		summary.Counts[event.Type]++
+
+		switch event.Type {
+		case TraceError:
+			summary.Errors = append(summary.Errors, event)
+		case TraceConflict:
+			summary.Conflicts = append(summary.Conflicts, event)
+		case TraceLocalEvent:
+			changedLocally[event.Path] = true
+		case TraceUpload, TraceRemoveRemote:
+			uploaded[event.Path] = true
+		case TraceDownload, TraceRemoveLocal:
+			if uploaded[event.Path] {
+				downloaded[event.Path] = true
+			}
+		}
+	}
+
+	for path := range changedLocally {
+		if uploaded[path] == false {
+			summary.NotUploaded = append(summary.NotUploaded, path)
+		}
+	}
+	for path := range downloaded {
+		summary.Bounced = append(summary.Bounced, path)
+	}
+
+	sort.Strings(summary.NotUploaded)
+	sort.Strings(summary.Bounced)
+
+	return summary
+}
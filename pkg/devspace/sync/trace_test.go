@@ -0,0 +1,52 @@
+package sync
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummarizeTrace(t *testing.T) {
+	trace := `{"time":"2018-10-01T10:00:00Z","type":"start","message":"/local <-> /app"}
+{"time":"2018-10-01T10:00:01Z","type":"localEvent","path":"/index.js","message":"notify.Write"}
+{"time":"2018-10-01T10:00:02Z","type":"upload","path":"/index.js","size":10,"mtime":1538388001}
+{"time":"2018-10-01T10:00:03Z","type":"download","path":"/index.js","size":10,"mtime":1538388002}
+{"time":"2018-10-01T10:00:04Z","type":"localEvent","path":"/node_modules/test.js","message":"notify.Create"}
+{"time":"2018-10-01T10:00:04Z","type":"uploadSkipped","path":"/node_modules/test.js","message":"excluded or unchanged"}
+
+{"time":"2018-10-01T10:00:05Z","type":"conflict","path":"/app.js","message":"kept local version"}
+{"time":"2018-10-01T10:00:06Z","type":"error","message":"Stream closed unexpectedly"}
+{"time":"2018-10-01T10:00:07Z","type":"stop"}
+`
+
+	events, err := ReadTrace(strings.NewReader(trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 9 {
+		t.Fatalf("Expected 9 events, got %d", len(events))
+	}
+
+	summary := SummarizeTrace(events)
+	if summary.End.Sub(summary.Start).Seconds() != 7 {
+		t.Fatalf("Expected a duration of 7 seconds, got %v", summary.End.Sub(summary.Start))
+	}
+	if summary.Counts[TraceLocalEvent] != 2 || summary.Counts[TraceUpload] != 1 {
+		t.Fatalf("Wrong event counts: %v", summary.Counts)
+	}
+	if len(summary.Errors) != 1 || len(summary.Conflicts) != 1 {
+		t.Fatalf("Expected 1 error and 1 conflict, got %d and %d", len(summary.Errors), len(summary.Conflicts))
+	}
+	if len(summary.NotUploaded) != 1 || summary.NotUploaded[0] != "/node_modules/test.js" {
+		t.Fatalf("Wrong not uploaded paths: %v", summary.NotUploaded)
+	}
+	if len(summary.Bounced) != 1 || summary.Bounced[0] != "/index.js" {
+		t.Fatalf("Wrong bounced paths: %v", summary.Bounced)
+	}
+}
+
+func TestReadTraceInvalidLine(t *testing.T) {
+	_, err := ReadTrace(strings.NewReader("{\"type\":\"start\"}\nnot json\n"))
+	if err == nil || strings.Contains(err.Error(), "line 2") == false {
+		t.Fatalf("Expected parsing error for line 2, got %v", err)
+	}
+}
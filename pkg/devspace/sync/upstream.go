@@ -10,7 +10,6 @@ import (
 	"time"
 
 	"github.com/juju/errors"
-	"github.com/juju/ratelimit"
 
 	"github.com/covexo/devspace/pkg/devspace/kubectl"
 	"github.com/rjeczalik/notify"
@@ -41,7 +40,8 @@ func (u *upstream) start() error {
 
 func (u *upstream) startShell() error {
 	if u.config.testing == false {
-		stdinPipe, stdoutPipe, stderrPipe, err := kubectl.Exec(u.config.Kubectl, u.config.Pod, u.config.Container.Name, []string{"sh"}, false, nil)
+		pod, container := u.config.getPod()
+		stdinPipe, stdoutPipe, stderrPipe, err := kubectl.Exec(u.config.Kubectl, pod, container.Name, []string{"sh"}, false, nil)
 
 		if err != nil {
 			return errors.Trace(err)
@@ -124,6 +124,7 @@ func (u *upstream) mainLoop() error {
 			}
 
 			changeAmount = len(changes)
+			u.config.metrics.setUploadsQueued(changeAmount)
 		}
 
 		err := u.applyChanges(changes)
@@ -131,6 +132,8 @@ func (u *upstream) mainLoop() error {
 		if err != nil {
 			return err
 		}
+
+		u.config.metrics.addUploaded(len(changes))
 	}
 }
 
@@ -152,9 +155,12 @@ func (u *upstream) getfileInformationFromEvent(events []notify.EventInfo) []*fil
 
 			// Determine what kind of change we got (Create or Remove)
 			newChange := evaluateChange(u.config, fileMap, relativePath, fullpath)
+			u.config.trace(TraceLocalEvent, relativePath, event.Event().String())
 
 			if newChange != nil {
 				changes = append(changes, newChange)
+			} else {
+				u.config.trace(TraceUploadSkipped, relativePath, "excluded or unchanged")
 			}
 		}
 	}
@@ -168,22 +174,20 @@ func evaluateChange(s *SyncConfig, fileMap map[string]*fileInformation, relative
 	// File / Folder exist -> Create File or Folder
 	// if File / Folder does not exist, we create a new remove change
 	if err == nil {
-		// Exclude changes on the upload exclude list
-		if s.uploadIgnoreMatcher != nil {
-			if s.uploadIgnoreMatcher.MatchesPath(relativePath) {
-				// Add to file map and prevent download if local file is newer than the remote one
-				if s.fileIndex.fileMap[relativePath] != nil && s.fileIndex.fileMap[relativePath].Mtime < roundMtime(stat.ModTime()) {
-					// Add it to the fileMap
-					s.fileIndex.fileMap[relativePath] = &fileInformation{
-						Name:        relativePath,
-						Mtime:       roundMtime(stat.ModTime()),
-						Size:        stat.Size(),
-						IsDirectory: stat.IsDir(),
-					}
+		// Exclude changes on the upload exclude list and files that are excluded by the file filter
+		if (s.uploadIgnoreMatcher != nil && s.uploadIgnoreMatcher.MatchesPath(relativePath)) || s.isFileFiltered(relativePath, fullpath, stat) {
+			// Add to file map and prevent download if local file is newer than the remote one
+			if s.fileIndex.fileMap[relativePath] != nil && s.fileIndex.fileMap[relativePath].Mtime < roundMtime(stat.ModTime()) {
+				// Add it to the fileMap
+				s.fileIndex.fileMap[relativePath] = &fileInformation{
+					Name:        relativePath,
+					Mtime:       roundMtime(stat.ModTime()),
+					Size:        stat.Size(),
+					IsDirectory: stat.IsDir(),
 				}
-
-				return nil
 			}
+
+			return nil
 		}
 
 		if shouldUpload(relativePath, stat, s, false) {
@@ -247,6 +251,11 @@ func (u *upstream) applyChanges(changes []*fileInformation) error {
 }
 
 func (u *upstream) applyCreates(files []*fileInformation) error {
+	files = u.config.filterConflictingUploads(files)
+	if len(files) == 0 {
+		return nil
+	}
+
 	filename, writtenFiles, err := writeTar(files, u.config)
 	if err != nil {
 		return errors.Trace(err)
@@ -281,7 +290,20 @@ func (u *upstream) applyCreates(files []*fileInformation) error {
 		}
 	}
 
-	return u.uploadArchive(f, strconv.Itoa(int(stat.Size())), writtenFiles)
+	err = u.uploadArchive(f, strconv.Itoa(int(stat.Size())), writtenFiles)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	uploadedFiles := make([]string, 0, len(writtenFiles))
+	for _, file := range writtenFiles {
+		if file.IsDirectory == false {
+			uploadedFiles = append(uploadedFiles, file.Name)
+		}
+	}
+
+	u.config.uploadHooks.trigger(uploadedFiles)
+	return nil
 }
 
 func (u *upstream) uploadArchive(file *os.File, fileSize string, writtenFiles map[string]*fileInformation) error {
@@ -331,10 +353,7 @@ func (u *upstream) uploadArchive(file *os.File, fileSize string, writtenFiles ma
 	}
 
 	// Apply rate limit if specified
-	var uploadWriter io.Writer = u.stdinPipe
-	if u.config.UpstreamLimit > 0 {
-		uploadWriter = ratelimit.Writer(u.stdinPipe, ratelimit.NewBucketWithRate(float64(u.config.UpstreamLimit), u.config.UpstreamLimit))
-	}
+	uploadWriter := limitWriter(u.stdinPipe, u.config.UpstreamLimit)
 
 	// Send file through stdin to remote
 	_, err = io.Copy(uploadWriter, file)
@@ -361,6 +380,7 @@ func (u *upstream) uploadArchive(file *os.File, fileSize string, writtenFiles ma
 	for _, element := range writtenFiles {
 		u.config.fileIndex.CreateDirInFileMap(path.Dir(element.Name))
 		u.config.fileIndex.fileMap[element.Name] = element
+		u.config.traceFile(TraceUpload, element)
 	}
 
 	return nil
@@ -383,6 +403,7 @@ func (u *upstream) applyRemoves(files []*fileInformation) error {
 			relativePath := files[i+j].Name
 
 			if fileMap[relativePath] != nil {
+				u.config.trace(TraceRemoveRemote, relativePath, "")
 				relativePath = strings.Replace(relativePath, "'", "\\'", -1)
 				rmCommand += "'" + u.config.DestPath + relativePath + "' "
 				removeArguments++
@@ -12,17 +12,18 @@ import (
 
 	"github.com/covexo/devspace/pkg/util/log"
 	"github.com/juju/errors"
+	"github.com/juju/ratelimit"
 	gitignore "github.com/sabhiram/go-gitignore"
 	k8sv1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
 // CopyToContainer copies a local folder to a container path
-func CopyToContainer(Kubectl *kubernetes.Clientset, Pod *k8sv1.Pod, Container *k8sv1.Container, LocalPath, ContainerPath string, ExcludePaths []string) error {
+func CopyToContainer(Kubectl kubernetes.Interface, Pod *k8sv1.Pod, Container *k8sv1.Container, LocalPath, ContainerPath string, ExcludePaths []string) error {
 	return copyToContainerTestable(Kubectl, Pod, Container, LocalPath, ContainerPath, ExcludePaths, false)
 }
 
-func copyToContainerTestable(Kubectl *kubernetes.Clientset, Pod *k8sv1.Pod, Container *k8sv1.Container, LocalPath, ContainerPath string, ExcludePaths []string, testing bool) error {
+func copyToContainerTestable(Kubectl kubernetes.Interface, Pod *k8sv1.Pod, Container *k8sv1.Container, LocalPath, ContainerPath string, ExcludePaths []string, testing bool) error {
 	stat, err := os.Stat(LocalPath)
 
 	if err != nil {
@@ -92,6 +93,30 @@ func getRelativeFromFullPath(fullpath string, prefix string) string {
 	return strings.Replace(strings.Replace(fullpath[len(prefix):], "\\", "/", -1), "//", "/", -1)
 }
 
+// limitClock is the clock of the bandwidth limits (the real clock if nil). Tests replace it to check the limits
+// without waiting
+var limitClock ratelimit.Clock
+
+// limitReader wraps the given reader with a token bucket that allows limit bytes per second.
+// A limit of 0 or less means the reader is not limited
+func limitReader(r io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		return r
+	}
+
+	return ratelimit.Reader(r, ratelimit.NewBucketWithRateAndClock(float64(limit), limit, limitClock))
+}
+
+// limitWriter wraps the given writer with a token bucket that allows limit bytes per second.
+// A limit of 0 or less means the writer is not limited
+func limitWriter(w io.Writer, limit int64) io.Writer {
+	if limit <= 0 {
+		return w
+	}
+
+	return ratelimit.Writer(w, ratelimit.NewBucketWithRateAndClock(float64(limit), limit, limitClock))
+}
+
 func pipeStream(w io.Writer, r io.Reader) error {
 	buf := make([]byte, 1024, 1024)
 
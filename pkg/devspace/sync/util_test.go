@@ -1,11 +1,14 @@
 package sync
 
 import (
+	"bytes"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
@@ -295,3 +298,77 @@ Outer:
 		t.Error("unexpected")
 	}
 }
+
+// fakeLimitClock advances the time when the bandwidth limit sleeps instead of waiting
+type fakeLimitClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeLimitClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+func (c *fakeLimitClock) Sleep(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// measurePipeTransfer returns the time the bandwidth limit waited while the payload was transferred
+func measurePipeTransfer(t *testing.T, payloadSize int, limitedWriter bool, limit int64) time.Duration {
+	clock := &fakeLimitClock{now: time.Unix(0, 0)}
+	limitClock = clock
+	defer func() { limitClock = nil }()
+
+	reader, writer := io.Pipe()
+	payload := bytes.Repeat([]byte{'a'}, payloadSize)
+
+	var w io.Writer = writer
+	var r io.Reader = reader
+	if limitedWriter {
+		w = limitWriter(writer, limit)
+	} else {
+		r = limitReader(reader, limit)
+	}
+
+	go func() {
+		w.Write(payload)
+		writer.Close()
+	}()
+
+	received, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != payloadSize {
+		t.Fatalf("Wrong amount of bytes transferred: got %d, expected %d", len(received), payloadSize)
+	}
+
+	return clock.Now().Sub(time.Unix(0, 0))
+}
+
+func TestBandwidthLimits(t *testing.T) {
+	payloadSize := 48 * 1024
+
+	for _, limitedWriter := range []bool{true, false} {
+		// The bucket starts full, so the first limit bytes are transferred immediately
+		unlimited := measurePipeTransfer(t, payloadSize, limitedWriter, 0)
+		slow := measurePipeTransfer(t, payloadSize, limitedWriter, 16*1024)
+		fast := measurePipeTransfer(t, payloadSize, limitedWriter, 24*1024)
+
+		if unlimited != 0 {
+			t.Fatalf("Unlimited transfer waited for %v", unlimited)
+		}
+		if slow < 1950*time.Millisecond || slow > 2050*time.Millisecond {
+			t.Fatalf("Transfer with 16KB/s limit took %v, expected 2s", slow)
+		}
+		if fast < 950*time.Millisecond || fast > 1050*time.Millisecond {
+			t.Fatalf("Transfer with 24KB/s limit took %v, expected 1s", fast)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package tunnel
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// Handshake is written by the remote side of the tunnel as soon as it listens for connections. Everything that is
+// written to the stream before the handshake (e.g. warnings of the binary) is ignored by the local side
+const Handshake = "DEVSPACE-TUNNEL"
+
+// maxPayloadSize is the maximum size of the payload of a single frame
+const maxPayloadSize = 32 * 1024
+
+// Every frame consists of the frame type (1 byte), the connection id (4 bytes), the payload length (4 bytes) and
+// the payload
+const (
+	// frameOpen is sent by the remote side when a new connection was accepted
+	frameOpen byte = iota + 1
+	// frameData carries data of a connection
+	frameData
+	// frameClose signals that the sender won't send any more data for the connection
+	frameClose
+)
+
+// mux multiplexes multiple connections over a single stream
+type mux struct {
+	out      io.Writer
+	outMutex sync.Mutex
+
+	conns      map[uint32]*muxConn
+	connsMutex sync.Mutex
+}
+
+// muxConn is a connection of the mux. The connection is closed and removed as soon as both directions are done
+type muxConn struct {
+	conn      net.Conn
+	readDone  bool
+	writeDone bool
+}
+
+func newMux(out io.Writer) *mux {
+	return &mux{
+		out:   out,
+		conns: make(map[uint32]*muxConn),
+	}
+}
+
+func (m *mux) writeFrame(frameType byte, id uint32, payload []byte) error {
+	header := make([]byte, 9)
+	header[0] = frameType
+	binary.BigEndian.PutUint32(header[1:5], id)
+	binary.BigEndian.PutUint32(header[5:9], uint32(len(payload)))
+
+	m.outMutex.Lock()
+	defer m.outMutex.Unlock()
+
+	_, err := m.out.Write(header)
+	if err != nil {
+		return err
+	}
+
+	if len(payload) > 0 {
+		_, err = m.out.Write(payload)
+	}
+
+	return err
+}
+
+func (m *mux) addConn(id uint32, conn net.Conn) {
+	m.connsMutex.Lock()
+	defer m.connsMutex.Unlock()
+
+	m.conns[id] = &muxConn{
+		conn: conn,
+	}
+}
+
+// pipe sends everything that is read from the connection to the other side of the tunnel
+func (m *mux) pipe(id uint32, conn net.Conn) {
+	buffer := make([]byte, maxPayloadSize)
+
+	for {
+		n, err := conn.Read(buffer)
+		if n > 0 {
+			if writeErr := m.writeFrame(frameData, id, buffer[:n]); writeErr != nil {
+				break
+			}
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	m.writeFrame(frameClose, id, nil)
+	m.done(id, true)
+}
+
+// done marks one direction of the connection as done and closes the connection if both directions are done
+func (m *mux) done(id uint32, read bool) {
+	m.connsMutex.Lock()
+	defer m.connsMutex.Unlock()
+
+	c, ok := m.conns[id]
+	if ok == false {
+		return
+	}
+
+	if read {
+		c.readDone = true
+	} else {
+		c.writeDone = true
+
+		// Signal the end of the stream to the connection, but keep reading from it
+		if tcpConn, ok := c.conn.(*net.TCPConn); ok && c.readDone == false {
+			tcpConn.CloseWrite()
+		}
+	}
+
+	if c.readDone && c.writeDone {
+		c.conn.Close()
+		delete(m.conns, id)
+	}
+}
+
+func (m *mux) getConn(id uint32) net.Conn {
+	m.connsMutex.Lock()
+	defer m.connsMutex.Unlock()
+
+	if c, ok := m.conns[id]; ok && c.writeDone == false {
+		return c.conn
+	}
+
+	return nil
+}
+
+func (m *mux) closeAll() {
+	m.connsMutex.Lock()
+	defer m.connsMutex.Unlock()
+
+	for id, c := range m.conns {
+		c.conn.Close()
+		delete(m.conns, id)
+	}
+}
+
+// readFrames reads frames from the stream until it is closed and calls onOpen for every opened connection
+func (m *mux) readFrames(in io.Reader, onOpen func(id uint32)) error {
+	defer m.closeAll()
+
+	header := make([]byte, 9)
+	for {
+		_, err := io.ReadFull(in, header)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		frameType := header[0]
+		id := binary.BigEndian.Uint32(header[1:5])
+		length := binary.BigEndian.Uint32(header[5:9])
+		if length > maxPayloadSize {
+			return fmt.Errorf("Invalid frame size %d", length)
+		}
+
+		payload := make([]byte, length)
+		_, err = io.ReadFull(in, payload)
+		if err != nil {
+			return err
+		}
+
+		switch frameType {
+		case frameOpen:
+			if onOpen != nil {
+				onOpen(id)
+			}
+		case frameData:
+			conn := m.getConn(id)
+			if conn == nil {
+				continue
+			}
+
+			_, err = conn.Write(payload)
+			if err != nil {
+				// The connection is broken, so we stop reading from it as well
+				conn.Close()
+				m.done(id, false)
+			}
+		case frameClose:
+			m.done(id, false)
+		default:
+			return fmt.Errorf("Invalid frame type %d", frameType)
+		}
+	}
+}
+
+// Serve listens on the given address and tunnels every accepted connection over out. Data for the connections is
+// read from in. Serve returns when in is closed
+func Serve(address string, in io.Reader, out io.Writer) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	return serve(listener, in, out)
+}
+
+func serve(listener net.Listener, in io.Reader, out io.Writer) error {
+	defer listener.Close()
+
+	_, err := fmt.Fprintln(out, Handshake)
+	if err != nil {
+		return err
+	}
+
+	m := newMux(out)
+
+	go func() {
+		for id := uint32(1); ; id++ {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			m.addConn(id, conn)
+			if m.writeFrame(frameOpen, id, nil) != nil {
+				return
+			}
+
+			go m.pipe(id, conn)
+		}
+	}()
+
+	return m.readFrames(in, nil)
+}
+
+// Connect reads the tunnel stream of Serve from in and connects every tunneled connection to the dial address.
+// readyChan is closed as soon as the remote side listens for connections. Connect returns when in is closed
+func Connect(dialAddress string, in io.Reader, out io.Writer, readyChan chan struct{}) error {
+	reader := bufio.NewReader(in)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("Tunnel closed before it was ready: %s", line)
+			}
+
+			return err
+		}
+
+		if line == Handshake+"\n" {
+			break
+		}
+	}
+
+	if readyChan != nil {
+		close(readyChan)
+	}
+
+	m := newMux(out)
+
+	return m.readFrames(reader, func(id uint32) {
+		conn, err := net.Dial("tcp", dialAddress)
+		if err != nil {
+			// Nothing listens locally, so we close the remote connection
+			m.writeFrame(frameClose, id, nil)
+			return
+		}
+
+		m.addConn(id, conn)
+		go m.pipe(id, conn)
+	})
+}
@@ -0,0 +1,155 @@
+package tunnel
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func startEchoServer(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	return listener
+}
+
+func TestTunnel(t *testing.T) {
+	echoServer := startEchoServer(t)
+	defer echoServer.Close()
+
+	remoteListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// remote -> local and local -> remote streams (the exec stdout and stdin)
+	remoteOutReader, remoteOutWriter := io.Pipe()
+	localOutReader, localOutWriter := io.Pipe()
+
+	serveDone := make(chan error)
+	go func() {
+		serveDone <- serve(remoteListener, localOutReader, remoteOutWriter)
+		remoteOutWriter.Close()
+	}()
+
+	readyChan := make(chan struct{})
+	connectDone := make(chan error)
+	go func() {
+		connectDone <- Connect(echoServer.Addr().String(), remoteOutReader, localOutWriter, readyChan)
+	}()
+
+	select {
+	case <-readyChan:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for the tunnel to get ready")
+	}
+
+	waitGroup := sync.WaitGroup{}
+	errors := make(chan error, 3)
+
+	for i := 0; i < 3; i++ {
+		waitGroup.Add(1)
+
+		go func(i int) {
+			defer waitGroup.Done()
+
+			conn, err := net.Dial("tcp", remoteListener.Addr().String())
+			if err != nil {
+				errors <- err
+				return
+			}
+
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+
+			for j := 0; j < 10; j++ {
+				message := fmt.Sprintf("connection %d message %d\n", i, j)
+				_, err = conn.Write([]byte(message))
+				if err != nil {
+					errors <- err
+					return
+				}
+
+				answer, err := reader.ReadString('\n')
+				if err != nil {
+					errors <- err
+					return
+				}
+				if answer != message {
+					errors <- fmt.Errorf("Expected %q, got %q", message, answer)
+					return
+				}
+			}
+		}(i)
+	}
+
+	waitGroup.Wait()
+	close(errors)
+	for err := range errors {
+		t.Error(err)
+	}
+
+	// Closing the stdin of the remote side stops the tunnel
+	localOutWriter.Close()
+
+	select {
+	case err := <-serveDone:
+		if err != nil {
+			t.Fatalf("Serve returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for serve to stop")
+	}
+
+	select {
+	case err := <-connectDone:
+		if err != nil {
+			t.Fatalf("Connect returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for connect to stop")
+	}
+}
+
+func TestConnectIgnoresOutputBeforeHandshake(t *testing.T) {
+	reader, writer := io.Pipe()
+	readyChan := make(chan struct{})
+
+	go func() {
+		fmt.Fprintln(writer, "[WARN] There is a newer version of devspace cli")
+		fmt.Fprintln(writer, Handshake)
+		writer.Close()
+	}()
+
+	err := Connect("127.0.0.1:0", reader, ioutil.Discard, readyChan)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case <-readyChan:
+	default:
+		t.Fatal("Tunnel was not ready after the handshake")
+	}
+}
@@ -1,11 +1,22 @@
 package upgrade
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/blang/semver"
+	update "github.com/inconshreveable/go-update"
 	"github.com/rhysd/go-github-selfupdate/selfupdate"
 )
 
@@ -46,52 +57,173 @@ func SetVersion(verText string) {
 	}
 }
 
-// CheckForNewerVersion checks if there is a newer version on github and returns the newer version
-func CheckForNewerVersion() (string, error) {
+// detectNewerRelease returns the latest release on github if it is newer than the current version
+func detectNewerRelease() (*selfupdate.Release, error) {
+	if version == "" {
+		return nil, errors.New("This binary was built without version information")
+	}
+
 	latest, found, err := selfupdate.DetectLatest(githubSlug)
+	if err != nil {
+		return nil, err
+	}
 
+	v, err := semver.Parse(version)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("Error parsing current version %s: %v", version, err)
 	}
 
-	v := semver.MustParse(version)
+	if !found || latest.Version.LTE(v) {
+		return nil, nil
+	}
+
+	return latest, nil
+}
 
-	if !found || latest.Version.Equals(v) {
+// CheckForNewerVersion checks if there is a newer version on github and returns the newer version
+func CheckForNewerVersion() (string, error) {
+	latest, err := detectNewerRelease()
+	if err != nil {
+		return "", err
+	}
+
+	if latest == nil {
 		return "", nil
 	}
 
 	return latest.Version.String(), nil
 }
 
-// Upgrade downloads the latest release from github and replaces devspace if a new version is found
+// Upgrade downloads the latest release from github, verifies its checksum and replaces devspace if a new version is found
 func Upgrade() error {
-	newerVersion, err := CheckForNewerVersion()
-
+	latest, err := detectNewerRelease()
 	if err != nil {
 		return err
 	}
 
-	if newerVersion == "" {
+	if latest == nil {
 		log.Println("Current binary is the latest version: ", version)
 		return nil
 	}
 
-	v := semver.MustParse(version)
+	// Every release asset has a <asset>.sha256 file that contains its checksum
+	checksum, err := downloadChecksum(latest.AssetURL + ".sha256")
+	if err != nil {
+		return err
+	}
 
 	log.Println("Downloading newest version...")
-	latest, err := selfupdate.UpdateSelf(v, githubSlug)
+	resp, err := http.Get(latest.AssetURL)
+	if err != nil {
+		return fmt.Errorf("Error downloading %s: %v", latest.AssetURL, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Error downloading %s: %s", latest.AssetURL, resp.Status)
+	}
 
+	// Apply verifies the checksum and atomically replaces the running executable
+	err = update.Apply(resp.Body, update.Options{
+		Checksum: checksum,
+	})
+	if err != nil {
+		if rollbackErr := update.RollbackError(err); rollbackErr != nil {
+			return fmt.Errorf("Error replacing binary: %v. Rollback failed: %v", err, rollbackErr)
+		}
+
+		return fmt.Errorf("Error replacing binary: %v", err)
+	}
+
+	log.Println("Successfully updated to version", latest.Version)
+	log.Println("Release note:\n", latest.ReleaseNotes)
+
+	return nil
+}
+
+// DownloadReleaseBinary downloads the devspace binary of the current version for the given platform (e.g.
+// linux-amd64) to target and verifies its checksum
+func DownloadReleaseBinary(platform, target string) error {
+	if version == "" {
+		return errors.New("This binary was built without version information")
+	}
+
+	url := "https://github.com/" + githubSlug + "/releases/download/v" + version + "/devspace-" + platform
+	checksum, err := downloadChecksum(url + ".sha256")
 	if err != nil {
 		return err
 	}
 
-	if latest.Version.Equals(v) {
-		// latest version is the same as current version. It means current binary is up to date.
-		log.Println("Current binary is the latest version: ", version)
-	} else {
-		log.Println("Successfully updated to version", latest.Version)
-		log.Println("Release note:\n", latest.ReleaseNotes)
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("Error downloading %s: %v", url, err)
 	}
 
-	return nil
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Error downloading %s: %s", url, resp.Status)
+	}
+
+	err = os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(target), filepath.Base(target))
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(file.Name())
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), resp.Body)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("Error downloading %s: %v", url, err)
+	}
+
+	if bytes.Equal(hash.Sum(nil), checksum) == false {
+		return fmt.Errorf("Checksum of %s does not match", url)
+	}
+
+	err = os.Chmod(file.Name(), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), target)
+}
+
+func downloadChecksum(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("Error downloading checksum %s: %v", url, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Error downloading checksum %s: %s", url, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// The checksum file has the format '<checksum>  <filename>'
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("Checksum file %s is empty", url)
+	}
+
+	checksum, err := hex.DecodeString(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding checksum %s: %v", url, err)
+	}
+
+	return checksum, nil
 }
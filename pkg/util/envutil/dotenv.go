@@ -0,0 +1,106 @@
+package envutil
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var envKeyRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.]*$`)
+
+// LoadEnvFile reads the variables of a .env file and sets them in the environment of the current process.
+// Variables that are already set in the environment are not overwritten. Returns all variables of the file
+func LoadEnvFile(path string) (map[string]string, error) {
+	vars, err := ReadEnvFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range vars {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+
+		err = os.Setenv(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("Error setting %s: %v", key, err)
+		}
+	}
+
+	return vars, nil
+}
+
+// ReadEnvFile parses a file in dotenv format (KEY=value per line, # comments, optional export prefix and quotes)
+func ReadEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(file)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		key, value, ok, err := parseEnvLine(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing %s line %d: %v", path, lineNumber, err)
+		}
+		if ok {
+			vars[key] = value
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// parseEnvLine parses a single line of a .env file and returns false if the line is empty or a comment
+func parseEnvLine(line string) (string, string, bool, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false, nil
+	}
+
+	line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+	separator := strings.Index(line, "=")
+	if separator == -1 {
+		return "", "", false, fmt.Errorf("Expected KEY=value, got %s", line)
+	}
+
+	key := strings.TrimSpace(line[:separator])
+	if envKeyRegex.MatchString(key) == false {
+		return "", "", false, fmt.Errorf("Invalid variable name %s", key)
+	}
+
+	value := strings.TrimSpace(line[separator+1:])
+	if len(value) > 0 && (value[0] == '"' || value[0] == '\'') {
+		quote := value[0]
+
+		end := strings.LastIndexByte(value, quote)
+		if end == 0 {
+			return "", "", false, fmt.Errorf("Missing closing quote for %s", key)
+		}
+
+		value = value[1:end]
+		if quote == '"' {
+			value = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(value)
+		}
+
+		return key, value, true, nil
+	}
+
+	// Unquoted values can have a trailing comment
+	if comment := strings.Index(value, " #"); comment != -1 {
+		value = strings.TrimSpace(value[:comment])
+	}
+
+	return key, value, true, nil
+}
@@ -0,0 +1,63 @@
+package envutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadEnvFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devspace-dotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	envFile := filepath.Join(dir, ".env")
+	err = ioutil.WriteFile(envFile, []byte(`# Database settings
+DB_HOST=localhost
+export DB_PORT = 5432
+DB_PASSWORD="se#cret \"quoted\"\nline"
+SINGLE='no\nescape'
+TRAILING=value # comment
+EMPTY=
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vars, err := ReadEnvFile(envFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"DB_HOST":     "localhost",
+		"DB_PORT":     "5432",
+		"DB_PASSWORD": "se#cret \"quoted\"\nline",
+		"SINGLE":      `no\nescape`,
+		"TRAILING":    "value",
+		"EMPTY":       "",
+	}
+
+	if len(vars) != len(expected) {
+		t.Fatalf("Expected %d variables, got %d: %v", len(expected), len(vars), vars)
+	}
+
+	for key, value := range expected {
+		if vars[key] != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, vars[key])
+		}
+	}
+}
+
+func TestParseEnvLineErrors(t *testing.T) {
+	for _, line := range []string{"NO_SEPARATOR", "1INVALID=value", `QUOTE="missing`} {
+		_, _, _, err := parseEnvLine(line)
+		if err == nil {
+			t.Errorf("Expected error for line %s", line)
+		}
+	}
+}
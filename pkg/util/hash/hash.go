@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+
+	"github.com/docker/docker/pkg/fileutils"
 )
 
 // Directory creates the hash value of a directory
@@ -31,3 +33,82 @@ func Directory(path string) (string, error) {
 
 	return fmt.Sprintf("%x", hash.Sum(nil)), nil
 }
+
+// File creates the sha256 hash value of the contents of a file
+func File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// DirectoryContents creates the hash value of the paths, modes and contents of all files in a directory. Unlike
+// Directory, the hash doesn't depend on modification times, so it stays the same for fresh checkouts of the same
+// files. Files and directories that match one of the exclude patterns (.dockerignore syntax) are skipped
+func DirectoryContents(path string, excludes []string) (string, error) {
+	patternMatcher, err := fileutils.NewPatternMatcher(excludes)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	err = filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(path, filePath)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		relPath = filepath.ToSlash(relPath)
+		excluded, err := patternMatcher.Matches(relPath)
+		if err != nil {
+			return err
+		}
+		if excluded {
+			// Excluded directories can only be skipped if no exception pattern could include files again
+			if info.IsDir() && patternMatcher.Exclusions() == false {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		io.WriteString(hash, relPath+";"+info.Mode().String()+";")
+		if info.Mode().IsRegular() {
+			fileHash, err := File(filePath)
+			if err != nil {
+				return err
+			}
+
+			io.WriteString(hash, fileHash)
+		}
+
+		io.WriteString(hash, "\n")
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// String creates the sha256 hash value of a string
+func String(value string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(value)))
+}
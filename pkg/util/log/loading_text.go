@@ -18,6 +18,9 @@ type loadingText struct {
 	Stream  io.Writer
 	Message string
 
+	// plain prints the message only once instead of rendering a spinner
+	plain bool
+
 	startTimestamp int64
 	loadingRune    int
 	isShown        bool
@@ -25,6 +28,15 @@ type loadingText struct {
 }
 
 func (l *loadingText) Start() {
+	if l.plain {
+		if l.isShown == false {
+			l.isShown = true
+			l.Stream.Write([]byte("[WAIT] " + l.Message + "\n"))
+		}
+
+		return
+	}
+
 	l.isShown = false
 	l.startTimestamp = time.Now().UnixNano()
 
@@ -77,9 +89,9 @@ func (l *loadingText) render() {
 	}
 	messagePrefix := []byte("[WAIT] ")
 
-	ct.Foreground(ct.Red, false)
+	setColor(ct.Red)
 	l.Stream.Write(messagePrefix)
-	ct.ResetColor()
+	resetColor()
 
 	timeElapsed := fmt.Sprintf("%d", (time.Now().UnixNano()-l.startTimestamp)/int64(time.Second))
 	message := []byte(l.getLoadingChar() + " " + l.Message)
@@ -102,6 +114,10 @@ func (l *loadingText) render() {
 }
 
 func (l *loadingText) Stop() {
+	if l.plain {
+		return
+	}
+
 	l.stopChan <- true
 	l.Stream.Write([]byte("\r"))
 
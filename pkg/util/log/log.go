@@ -1,6 +1,7 @@
 package log
 
 import (
+	"github.com/covexo/devspace/pkg/util/terminal"
 	"github.com/sirupsen/logrus"
 
 	"github.com/daviddengcn/go-colortext"
@@ -73,6 +74,11 @@ func Fatalf(format string, args ...interface{}) {
 	stdoutLog.Fatalf(format, args...)
 }
 
+// Exitf prints formatted fatal error information and exits with the given exit code
+func Exitf(code int, format string, args ...interface{}) {
+	stdoutLog.Exitf(code, format, args...)
+}
+
 // Panic prints panic information
 func Panic(args ...interface{}) {
 	stdoutLog.Panic(args...)
@@ -142,9 +148,23 @@ func GetInstance() Logger {
 
 // WriteColored writes a message in color
 func WriteColored(message string, color ct.Color) {
-	ct.Foreground(color, false)
+	setColor(color)
 	stdoutLog.Write([]byte(message))
-	ct.ResetColor()
+	resetColor()
+}
+
+// setColor changes the foreground color if the terminal supports it
+func setColor(color ct.Color) {
+	if terminal.IsFancy() {
+		ct.Foreground(color, false)
+	}
+}
+
+// resetColor resets the foreground color if the terminal supports it
+func resetColor() {
+	if terminal.IsFancy() {
+		ct.ResetColor()
+	}
 }
 
 // Write writes to the stdout log without formatting the message, but takes care of locking the log and halting a possible wait message
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/covexo/devspace/pkg/util/terminal"
 	"github.com/daviddengcn/go-colortext"
 
 	"github.com/sirupsen/logrus"
@@ -86,9 +87,9 @@ func (s *stdoutLogger) writeMessage(fnType logFunctionType, message string) {
 			s.loadingText.Stop()
 		}
 
-		ct.Foreground(fnInformation.color, false)
+		setColor(fnInformation.color)
 		fnInformation.stream.Write([]byte(fnInformation.tag))
-		ct.ResetColor()
+		resetColor()
 
 		fnInformation.stream.Write([]byte(message))
 
@@ -161,6 +162,7 @@ func (s *stdoutLogger) StartWait(message string) {
 	s.loadingText = &loadingText{
 		Message: message,
 		Stream:  os.Stdout,
+		plain:   terminal.IsFancy() == false,
 	}
 
 	s.loadingText.Start()
@@ -315,6 +317,18 @@ func (s *stdoutLogger) Fatalf(format string, args ...interface{}) {
 	}
 }
 
+// Exitf prints the fatal error like Fatalf, but exits with the given exit code. The message is written to the log
+// file as error, because the file logger would exit with exit code 1 on fatal errors
+func (s *stdoutLogger) Exitf(code int, format string, args ...interface{}) {
+	s.logMutex.Lock()
+	defer s.logMutex.Unlock()
+
+	s.writeMessage(fatalFn, fmt.Sprintf(format, args...)+"\n")
+	s.writeMessageToFileLoggerf(errorFn, format, args...)
+
+	os.Exit(code)
+}
+
 func (s *stdoutLogger) Panic(args ...interface{}) {
 	s.logMutex.Lock()
 	defer s.logMutex.Unlock()
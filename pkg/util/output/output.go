@@ -0,0 +1,129 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/covexo/devspace/pkg/util/log"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// FormatTable prints a human readable table
+	FormatTable = "table"
+	// FormatJSON prints a json array with one object per row
+	FormatJSON = "json"
+	// FormatYAML prints a yaml list with one object per row
+	FormatYAML = "yaml"
+)
+
+// Formats holds all supported output formats
+var Formats = []string{FormatTable, FormatJSON, FormatYAML}
+
+// Formatter prints rows of values in a specific output format
+type Formatter interface {
+	Print(rows [][]string, headers []string) error
+}
+
+// NewFormatter returns the formatter for the given output format
+func NewFormatter(format string) (Formatter, error) {
+	switch format {
+	case FormatTable, "":
+		return &tableFormatter{}, nil
+	case FormatJSON:
+		return &jsonFormatter{out: os.Stdout}, nil
+	case FormatYAML:
+		return &yamlFormatter{out: os.Stdout}, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported output format %s (supported formats: %s)", format, strings.Join(Formats, ", "))
+}
+
+type tableFormatter struct{}
+
+func (f *tableFormatter) Print(rows [][]string, headers []string) error {
+	log.PrintTable(headers, rows)
+	return nil
+}
+
+type jsonFormatter struct {
+	out io.Writer
+}
+
+func (f *jsonFormatter) Print(rows [][]string, headers []string) error {
+	objects := make([]map[string]string, 0, len(rows))
+	for _, row := range rows {
+		object := map[string]string{}
+		for index, header := range headers {
+			object[getKey(header)] = getValue(row, index)
+		}
+
+		objects = append(objects, object)
+	}
+
+	out, err := json.MarshalIndent(objects, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(f.out, string(out))
+	return err
+}
+
+type yamlFormatter struct {
+	out io.Writer
+}
+
+func (f *yamlFormatter) Print(rows [][]string, headers []string) error {
+	// yaml.MapSlice keeps the order of the columns
+	objects := make([]yaml.MapSlice, 0, len(rows))
+	for _, row := range rows {
+		object := yaml.MapSlice{}
+		for index, header := range headers {
+			object = append(object, yaml.MapItem{
+				Key:   getKey(header),
+				Value: getValue(row, index),
+			})
+		}
+
+		objects = append(objects, object)
+	}
+
+	out, err := yaml.Marshal(objects)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.out.Write(out)
+	return err
+}
+
+func getValue(row []string, index int) string {
+	if index < len(row) {
+		return row[index]
+	}
+
+	return ""
+}
+
+// getKey converts a column header into a lower camel case key (e.g. "Ports (Local:Remote)" -> "portsLocalRemote")
+func getKey(header string) string {
+	words := strings.FieldsFunc(header, func(r rune) bool {
+		return unicode.IsLetter(r) == false && unicode.IsDigit(r) == false
+	})
+
+	key := ""
+	for index, word := range words {
+		if index == 0 {
+			key += strings.ToLower(word)
+		} else {
+			key += strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+		}
+	}
+
+	return key
+}
@@ -0,0 +1,75 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+var testHeaders = []string{"Name", "Local Path", "Ports (Local:Remote)"}
+var testRows = [][]string{
+	{"default", "./src", "8080:80"},
+	{"db", "", "5432:5432, 5433:5433"},
+}
+
+func TestJSONFormatter(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	err := (&jsonFormatter{out: out}).Print(testRows, testHeaders)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `[
+  {
+    "localPath": "./src",
+    "name": "default",
+    "portsLocalRemote": "8080:80"
+  },
+  {
+    "localPath": "",
+    "name": "db",
+    "portsLocalRemote": "5432:5432, 5433:5433"
+  }
+]
+`
+	if out.String() != expected {
+		t.Fatalf("Expected:\n%s\nGot:\n%s", expected, out.String())
+	}
+}
+
+func TestJSONFormatterEmpty(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	err := (&jsonFormatter{out: out}).Print([][]string{}, testHeaders)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if out.String() != "[]\n" {
+		t.Fatalf("Expected an empty array, got %s", out.String())
+	}
+}
+
+func TestYAMLFormatter(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	err := (&yamlFormatter{out: out}).Print([][]string{{"default", "./src"}, {"db", ""}}, []string{"Name", "Local Path"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `- name: default
+  localPath: ./src
+- name: db
+  localPath: ""
+`
+	if out.String() != expected {
+		t.Fatalf("Expected:\n%s\nGot:\n%s", expected, out.String())
+	}
+}
+
+func TestNewFormatterUnsupported(t *testing.T) {
+	_, err := NewFormatter("xml")
+	if err == nil {
+		t.Fatal("Expected an error for an unsupported format")
+	}
+}
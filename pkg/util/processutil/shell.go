@@ -0,0 +1,25 @@
+package processutil
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// RunShellCommand runs the command with the shell of the operating system (sh -c or cmd /C on windows) and streams
+// its output to stdout and stderr. A non-zero exit code is returned as *exec.ExitError
+func RunShellCommand(command string, stdout, stderr io.Writer) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	return cmd.Run()
+}
@@ -0,0 +1,71 @@
+package signalutil
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/covexo/devspace/pkg/util/log"
+)
+
+// ForceQuitExitCode is the exit code devspace uses if the user interrupts it twice
+const ForceQuitExitCode = 130
+
+// InterruptContext returns a context that is cancelled as soon as the user presses Ctrl+C (or SIGTERM is received), so
+// that running builds, deployments and wait loops can stop. The second interrupt force-quits devspace. stop restores
+// the default signal handling and must be called when the interruptible work is done
+func InterruptContext(log log.Logger) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	done := make(chan struct{})
+
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go handleInterrupts(signals, done, cancel, func() {
+		os.Exit(ForceQuitExitCode)
+	}, log)
+
+	stop := func() {
+		signal.Stop(signals)
+		close(done)
+		cancel()
+	}
+
+	return ctx, stop
+}
+
+// handleInterrupts cancels the context on the first signal and calls forceQuit on the second one
+func handleInterrupts(signals <-chan os.Signal, done <-chan struct{}, cancel context.CancelFunc, forceQuit func(), log log.Logger) {
+	interrupted := false
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-signals:
+			if interrupted {
+				log.Warn("Force quitting")
+				forceQuit()
+				return
+			}
+
+			interrupted = true
+			log.Warn("Cancelling... Press Ctrl+C again to force quit")
+			cancel()
+		}
+	}
+}
+
+// Sleep pauses for the given duration. It returns the error of the context if the context is done earlier
+func Sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
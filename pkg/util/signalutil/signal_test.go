@@ -0,0 +1,70 @@
+package signalutil
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/covexo/devspace/pkg/util/log"
+)
+
+func TestHandleInterrupts(t *testing.T) {
+	signals := make(chan os.Signal)
+	done := make(chan struct{})
+	defer close(done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	forceQuit := make(chan bool, 1)
+	go handleInterrupts(signals, done, cancel, func() {
+		forceQuit <- true
+	}, &log.DiscardLogger{})
+
+	signals <- os.Interrupt
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Context wasn't cancelled after the first interrupt")
+	}
+
+	select {
+	case <-forceQuit:
+		t.Fatal("First interrupt shouldn't force quit")
+	default:
+	}
+
+	signals <- os.Interrupt
+
+	select {
+	case <-forceQuit:
+	case <-time.After(time.Second):
+		t.Fatal("Second interrupt didn't force quit")
+	}
+}
+
+func TestSleepCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	err := Sleep(ctx, time.Minute)
+	if err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("Sleep didn't return promptly after cancellation")
+	}
+}
+
+func TestSleep(t *testing.T) {
+	err := Sleep(context.Background(), time.Millisecond)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
@@ -3,11 +3,14 @@ package stdinutil
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/covexo/devspace/pkg/util/log"
+	"github.com/covexo/devspace/pkg/util/terminal"
 	"github.com/daviddengcn/go-colortext"
 
 	"github.com/covexo/devspace/pkg/util/paramutil"
@@ -22,6 +25,9 @@ type GetFromStdinParams struct {
 	ValidationRegexPattern string
 	InputTerminationString string
 	IsPassword             bool
+
+	// Options are shown as numbered list. The answer can either be the number or the value of an option
+	Options []string
 }
 
 var defaultParams = &GetFromStdinParams{
@@ -41,6 +47,11 @@ func GetFromStdin(params *GetFromStdinParams) *string {
 	for {
 		fmt.Print(params.Question)
 
+		if len(params.Options) > 0 {
+			fmt.Print("\n")
+			printOptions(os.Stdout, params.Options, params.DefaultValue, terminal.IsFancy())
+		}
+
 		if len(params.DefaultValue) > 0 {
 			fmt.Print("\n")
 			log.WriteColored("Press ENTER to use: "+params.DefaultValue, ct.Green)
@@ -85,7 +96,16 @@ func GetFromStdin(params *GetFromStdinParams) *string {
 		if len(input) == 0 && len(params.DefaultValue) > 0 {
 			input = params.DefaultValue
 		}
-		if validationRegexp.MatchString(input) {
+		if len(params.Options) > 0 {
+			option, ok := resolveOption(input, params.Options)
+			if ok {
+				input = option
+				break
+			}
+
+			fmt.Print("Input must be a number between 1 and " + strconv.Itoa(len(params.Options)) + " or one of the listed values\n")
+			input = ""
+		} else if validationRegexp.MatchString(input) {
 			break
 		} else {
 			fmt.Print("Input must match " + params.ValidationRegexPattern + "\n")
@@ -96,3 +116,48 @@ func GetFromStdin(params *GetFromStdinParams) *string {
 
 	return &input
 }
+
+// printOptions prints the options as numbered list. The default option is marked with (default), so that it can
+// also be recognized without colors
+func printOptions(writer io.Writer, options []string, defaultValue string, fancy bool) {
+	for index, option := range options {
+		number := fmt.Sprintf("%3d) ", index+1)
+
+		if fancy {
+			ct.Foreground(ct.Cyan, false)
+			fmt.Fprint(writer, number)
+			ct.ResetColor()
+		} else {
+			fmt.Fprint(writer, number)
+		}
+
+		fmt.Fprint(writer, option)
+
+		if option == defaultValue {
+			fmt.Fprint(writer, " (default)")
+		}
+
+		fmt.Fprint(writer, "\n")
+	}
+}
+
+// resolveOption returns the option that matches the input. The input can either be the number of the option
+// (starting at 1) or the option value itself
+func resolveOption(input string, options []string) (string, bool) {
+	number, err := strconv.Atoi(input)
+	if err == nil {
+		if number >= 1 && number <= len(options) {
+			return options[number-1], true
+		}
+
+		return "", false
+	}
+
+	for _, option := range options {
+		if strings.EqualFold(option, input) {
+			return option, true
+		}
+	}
+
+	return "", false
+}
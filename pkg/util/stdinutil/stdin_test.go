@@ -0,0 +1,42 @@
+package stdinutil
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintOptionsPlain(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	printOptions(buffer, []string{"go", "javascript", "none"}, "javascript", false)
+
+	expected := "  1) go\n  2) javascript (default)\n  3) none\n"
+	if buffer.String() != expected {
+		t.Fatalf("Wrong plain rendering:\n%q\nExpected:\n%q", buffer.String(), expected)
+	}
+	if bytes.ContainsRune(buffer.Bytes(), '\x1b') {
+		t.Fatal("Plain rendering must not contain escape sequences")
+	}
+}
+
+func TestResolveOption(t *testing.T) {
+	options := []string{"go", "javascript", "none"}
+
+	testCases := map[string]string{
+		"1":          "go",
+		"3":          "none",
+		"javascript": "javascript",
+		"Go":         "go",
+	}
+	for input, expected := range testCases {
+		option, ok := resolveOption(input, options)
+		if ok == false || option != expected {
+			t.Fatalf("Expected %s for input %s, got %s (ok: %v)", expected, input, option, ok)
+		}
+	}
+
+	for _, input := range []string{"0", "4", "-1", "python", ""} {
+		if _, ok := resolveOption(input, options); ok {
+			t.Fatalf("Expected input %s to be rejected", input)
+		}
+	}
+}
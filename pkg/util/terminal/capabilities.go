@@ -0,0 +1,42 @@
+package terminal
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	dockerterm "github.com/docker/docker/pkg/term"
+)
+
+// DisableFancyPrompts disables colors, spinners and other cursor movements (--no-fancy-prompts)
+var DisableFancyPrompts = false
+
+var isCapable bool
+var detectOnce sync.Once
+
+// IsFancy returns true if colors and cursor movements can be used to render prompts and messages. It returns false
+// for dumb terminals, legacy windows consoles, redirected output or if fancy prompts were disabled
+func IsFancy() bool {
+	if DisableFancyPrompts {
+		return false
+	}
+
+	detectOnce.Do(func() {
+		isCapable = dockerterm.IsTerminal(os.Stdout.Fd()) && hasCapabilities(runtime.GOOS, os.Getenv)
+	})
+
+	return isCapable
+}
+
+// hasCapabilities checks the environment of the terminal for color and cursor support
+func hasCapabilities(goos string, getenv func(string) string) bool {
+	term := strings.ToLower(getenv("TERM"))
+
+	if goos == "windows" {
+		// The legacy windows console doesn't set any of these
+		return term != "" && term != "dumb" || getenv("WT_SESSION") != "" || getenv("ANSICON") != "" || strings.ToUpper(getenv("ConEmuANSI")) == "ON"
+	}
+
+	return term != "" && term != "dumb"
+}
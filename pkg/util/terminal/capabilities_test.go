@@ -0,0 +1,29 @@
+package terminal
+
+import "testing"
+
+func TestHasCapabilities(t *testing.T) {
+	testCases := []struct {
+		goos     string
+		env      map[string]string
+		expected bool
+	}{
+		{goos: "linux", env: map[string]string{"TERM": "xterm-256color"}, expected: true},
+		{goos: "linux", env: map[string]string{}, expected: false},
+		{goos: "linux", env: map[string]string{"TERM": "dumb"}, expected: false},
+		{goos: "windows", env: map[string]string{}, expected: false},
+		{goos: "windows", env: map[string]string{"WT_SESSION": "1"}, expected: true},
+		{goos: "windows", env: map[string]string{"ConEmuANSI": "ON"}, expected: true},
+		{goos: "windows", env: map[string]string{"TERM": "cygwin"}, expected: true},
+	}
+
+	for _, testCase := range testCases {
+		getenv := func(key string) string {
+			return testCase.env[key]
+		}
+
+		if hasCapabilities(testCase.goos, getenv) != testCase.expected {
+			t.Fatalf("Expected %v for %s with %v", testCase.expected, testCase.goos, testCase.env)
+		}
+	}
+}